@@ -0,0 +1,715 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// compactedFileSize is the size of the data above which the data file being
+// written by a compaction is flushed and a new one is started.
+const compactedFileSize = 64 << 20
+
+// compactBackupPlanHook implements PlanHookFn for BACKUP COMPACT, which merges
+// a backup and the incremental backups appended to it into a new full backup.
+// It only reads and writes the backup files in external storage: the data in
+// the cluster is neither read nor written. The merge is run by a BACKUP job,
+// so that it can be paused and resumed.
+func compactBackupPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	compactStmt, ok := stmt.(*tree.CompactBackup)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	if err := utilccl.CheckEnterpriseEnabled(
+		p.ExecCfg().Settings, p.ExecCfg().ClusterID(), p.ExecCfg().Organization(), "BACKUP COMPACT",
+	); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	if err := p.RequireAdminRole(ctx, "BACKUP COMPACT"); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fromFn, err := p.TypeAsString(ctx, compactStmt.From, "BACKUP COMPACT")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	toFn, err := p.TypeAsString(ctx, compactStmt.To, "BACKUP COMPACT")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	expected := map[string]sql.KVStringOptValidate{
+		backupOptEncPassphrase: sql.KVStringOptRequireValue,
+		backupOptEncKMS:        sql.KVStringOptRequireValue,
+	}
+	optsFn, err := p.TypeAsStringOpts(ctx, compactStmt.Options, expected)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer tracing.FinishSpan(span)
+
+		if !p.ExtendedEvalContext().TxnImplicit {
+			return errors.Errorf("BACKUP COMPACT cannot be used inside a transaction")
+		}
+
+		from, err := fromFn()
+		if err != nil {
+			return err
+		}
+		to, err := toFn()
+		if err != nil {
+			return err
+		}
+		opts, err := optsFn()
+		if err != nil {
+			return err
+		}
+
+		src, err := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI(ctx, from, p.User())
+		if err != nil {
+			return errors.Wrapf(err, "make storage")
+		}
+		defer src.Close()
+		dest, err := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI(ctx, to, p.User())
+		if err != nil {
+			return errors.Wrapf(err, "make storage")
+		}
+		defer dest.Close()
+
+		encryption, err := backupEncryptionFromOpts(ctx, p, src, opts)
+		if err != nil {
+			return err
+		}
+		// The job details keep an external:// KMS URI, which is resolved each
+		// time the job runs; resolve it here only to read the backups.
+		readEncryption, err := resolveEncryptionKMS(ctx, p.ExecCfg(), p.User(), encryption)
+		if err != nil {
+			return err
+		}
+
+		incPaths, err := findPriorBackupNames(ctx, src)
+		if err != nil {
+			return err
+		}
+		manifests, err := readCompactedBackupManifests(ctx, src, incPaths, readEncryption)
+		if err != nil {
+			return err
+		}
+		if err := checkForPreviousBackup(ctx, dest, to); err != nil {
+			return err
+		}
+		var encInfo *jobspb.EncryptionInfo
+		if encryption != nil {
+			encInfo, err = readEncryptionOptions(ctx, src)
+			if err != nil {
+				return err
+			}
+		}
+		// Lock out other backups from writing to the destination while the job
+		// is being created.
+		if err := createCheckpointIfNotExists(ctx, p.ExecCfg().Settings, dest, readEncryption); err != nil {
+			return err
+		}
+
+		description, err := compactBackupJobDescription(p, compactStmt, from, to, opts)
+		if err != nil {
+			return err
+		}
+		jr := jobs.Record{
+			Description: description,
+			Username:    p.User(),
+			Details: jobspb.BackupDetails{
+				StartTime:               manifests[0].StartTime,
+				EndTime:                 manifests[len(manifests)-1].EndTime,
+				URI:                     to,
+				EncryptionOptions:       encryption,
+				EncryptionInfo:          encInfo,
+				CompactFromURI:          from,
+				CompactIncrementalPaths: incPaths,
+			},
+			Progress: jobspb.BackupProgress{},
+		}
+		var sj *jobs.StartableJob
+		if err := p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) (err error) {
+			sj, err = p.ExecCfg().JobRegistry.CreateStartableJobWithTxn(ctx, jr, txn, resultsCh)
+			return err
+		}); err != nil {
+			if sj != nil {
+				if cleanupErr := sj.CleanupOnRollback(ctx); cleanupErr != nil {
+					log.Warningf(ctx, "failed to cleanup StartableJob: %v", cleanupErr)
+				}
+			}
+			return err
+		}
+		telemetry.Count("backup.compact")
+		return sj.Run(ctx)
+	}
+	return fn, utilccl.BulkJobExecutionResultHeader, nil, false, nil
+}
+
+// compactBackupJobDescription returns the description of the job of a BACKUP
+// COMPACT statement, with the URIs sanitized and the passphrase redacted.
+func compactBackupJobDescription(
+	p sql.PlanHookState, compactStmt *tree.CompactBackup, from, to string, opts map[string]string,
+) (string, error) {
+	sanitizedFrom, err := cloudimpl.SanitizeExternalStorageURI(from, nil /* extraParams */)
+	if err != nil {
+		return "", err
+	}
+	sanitizedTo, err := cloudimpl.SanitizeExternalStorageURI(to, nil /* extraParams */)
+	if err != nil {
+		return "", err
+	}
+	c := &tree.CompactBackup{
+		From: tree.NewDString(sanitizedFrom),
+		To:   tree.NewDString(sanitizedTo),
+	}
+	for _, opt := range compactStmt.Options {
+		switch string(opt.Key) {
+		case backupOptEncPassphrase:
+			opt.Value = tree.NewDString("redacted")
+		case backupOptEncKMS:
+			redactedURI, err := cloudimpl.RedactKMSURI(opts[backupOptEncKMS])
+			if err != nil {
+				return "", err
+			}
+			opt.Value = tree.NewDString(redactedURI)
+		}
+		c.Options = append(c.Options, opt)
+	}
+	return tree.AsStringWithFQNames(c, p.ExtendedEvalContext().Annotations), nil
+}
+
+// readCompactedBackupManifests reads the manifests of the full backup at the
+// root of src and of the incremental backups in incPaths, in order.
+func readCompactedBackupManifests(
+	ctx context.Context,
+	src cloud.ExternalStorage,
+	incPaths []string,
+	encryption *jobspb.BackupEncryptionOptions,
+) ([]BackupManifest, error) {
+	manifests := make([]BackupManifest, len(incPaths)+1)
+	var err error
+	manifests[0], err = readBackupManifestFromStore(ctx, src, encryption)
+	if err != nil {
+		return nil, err
+	}
+	for i := range incPaths {
+		manifests[i+1], err = readBackupManifest(ctx, src, incPaths[i], encryption)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return manifests, nil
+}
+
+// resumeCompaction runs the BACKUP COMPACT job described by details. A
+// checkpoint of the compacted backup is written to the destination once each
+// of its spans is compacted, so that a resumed job only compacts the spans
+// which are not in the checkpoint.
+func (b *backupResumer) resumeCompaction(
+	ctx context.Context,
+	p sql.PlanHookState,
+	details jobspb.BackupDetails,
+	resultsCh chan<- tree.Datums,
+) error {
+	src, err := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI(ctx, details.CompactFromURI, p.User())
+	if err != nil {
+		return errors.Wrapf(err, "make storage")
+	}
+	defer src.Close()
+	dest, err := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI(ctx, details.URI, p.User())
+	if err != nil {
+		return errors.Wrapf(err, "make storage")
+	}
+	defer dest.Close()
+
+	// The job details keep an external:// KMS URI as given by the user, so
+	// resolve it for this run.
+	encryption, err := resolveEncryptionKMS(ctx, p.ExecCfg(), p.User(), details.EncryptionOptions)
+	if err != nil {
+		return err
+	}
+	redactedURI := RedactURIForErrorMessage(details.URI)
+	if details.EncryptionInfo != nil {
+		if err := writeEncryptionInfoIfNotExists(ctx, details.EncryptionInfo, dest); err != nil {
+			return errors.Wrapf(err, "creating encryption info file to %s", redactedURI)
+		}
+	}
+	if err := createCheckpointIfNotExists(ctx, p.ExecCfg().Settings, dest, encryption); err != nil {
+		return errors.Wrapf(err, "creating checkpoint to %s", redactedURI)
+	}
+
+	manifests, err := readCompactedBackupManifests(ctx, src, details.CompactIncrementalPaths, encryption)
+	if err != nil {
+		return err
+	}
+
+	var checkpoint *BackupManifest
+	if desc, err := readBackupManifest(ctx, dest, backupManifestCheckpointName, encryption); err == nil {
+		// The checkpoint written when the job was created, which locks out
+		// other backups, has no ClusterID.
+		if desc.ClusterID.Equal(p.ExecCfg().ClusterID()) {
+			checkpoint = &desc
+		}
+	} else {
+		log.Warningf(ctx, "unable to load backup checkpoint while resuming job %d: %v", *b.job.ID(), err)
+	}
+
+	nodeID, err := p.ExecCfg().NodeID.OptionalNodeIDErr(47970)
+	if err != nil {
+		return err
+	}
+	// The files written by each run of the job are named after a different
+	// unique ID, so that they don't overwrite those in the checkpoint.
+	uniqueID := builtins.GenerateUniqueInt(base.SQLInstanceID(nodeID))
+	compacted, err := compactBackupManifests(
+		ctx, src, dest, details.CompactIncrementalPaths, manifests, encryption, uniqueID, checkpoint,
+		func(ctx context.Context, checkpoint BackupManifest, fraction float32) error {
+			checkpoint.ClusterID = p.ExecCfg().ClusterID()
+			if err := writeBackupManifest(
+				ctx, p.ExecCfg().Settings, dest, backupManifestCheckpointName, encryption, &checkpoint,
+			); err != nil {
+				return errors.Wrapf(err, "writing checkpoint to %s", redactedURI)
+			}
+			if fn := b.testingKnobs.afterCompactSpan; fn != nil {
+				if err := fn(); err != nil {
+					return err
+				}
+			}
+			return b.job.FractionProgressed(ctx, jobs.FractionUpdater(fraction))
+		},
+	)
+	if err != nil {
+		return err
+	}
+	compacted.NodeID = nodeID
+	compacted.Dir = dest.Conf()
+
+	if err := writeBackupManifest(
+		ctx, p.ExecCfg().Settings, dest, backupManifestName, encryption, &compacted,
+	); err != nil {
+		return err
+	}
+	b.deleteCheckpoint(ctx, p.ExecCfg(), p.User())
+
+	resultsCh <- tree.Datums{
+		tree.NewDInt(tree.DInt(*b.job.ID())),
+		tree.NewDString(string(jobs.StatusSucceeded)),
+		tree.NewDFloat(tree.DFloat(1.0)),
+		tree.NewDInt(tree.DInt(compacted.EntryCounts.Rows)),
+		tree.NewDInt(tree.DInt(compacted.EntryCounts.IndexEntries)),
+		tree.NewDInt(tree.DInt(compacted.EntryCounts.DataSize)),
+	}
+	return nil
+}
+
+// compactionCheckpointFn is called by compactBackupManifests once each span
+// of the compacted backup is written, with a checkpoint of the compacted
+// backup whose Spans are the spans compacted so far and whose Files and
+// EntryCounts are those of their data, and with the fraction of the spans
+// compacted so far.
+type compactionCheckpointFn func(ctx context.Context, checkpoint BackupManifest, fraction float32) error
+
+// compactBackupManifests merges the data files of the full backup at the root
+// of src and of the incremental backups in incPaths, whose manifests are
+// passed in order, into new data files in dest, and returns the manifest of a
+// full backup of these files covering the same time as the merged backups.
+// The data files are named using uniqueID. If checkpoint is not nil, the spans
+// in it are not compacted again, and its files are part of the result.
+//
+// Only the latest revision of each key is kept, unless every merged backup has
+// revision history, in which case all revisions are kept.
+func compactBackupManifests(
+	ctx context.Context,
+	src, dest cloud.ExternalStorage,
+	incPaths []string,
+	manifests []BackupManifest,
+	encryption *jobspb.BackupEncryptionOptions,
+	uniqueID tree.DInt,
+	checkpoint *BackupManifest,
+	checkpointFn compactionCheckpointFn,
+) (BackupManifest, error) {
+	last := manifests[len(manifests)-1]
+	compacted := BackupManifest{
+		StartTime:          manifests[0].StartTime,
+		EndTime:            last.EndTime,
+		MVCCFilter:         MVCCFilter_All,
+		Spans:              last.Spans,
+		Descriptors:        last.Descriptors,
+		Tenants:            last.Tenants,
+		CompleteDbs:        last.CompleteDbs,
+		FormatVersion:      BackupFormatDescriptorTrackingVersion,
+		BuildInfo:          build.GetInfo(),
+		ClusterID:          last.ClusterID,
+		ID:                 uuid.MakeV4(),
+		DescriptorCoverage: last.DescriptorCoverage,
+	}
+	// The compacted backup keeps the revision history of the full backup. An
+	// incremental backup's revision start time is the GC threshold of the spans
+	// it exported, so it only limits the times it can be restored to if it is
+	// past the incremental's start time. The compacted backup then rejects all
+	// earlier times too, since it can only have a single revision start time.
+	compacted.RevisionStartTime = manifests[0].RevisionStartTime
+	for i := range manifests {
+		if len(manifests[i].LocalityKVs) > 0 {
+			return BackupManifest{}, errors.New("cannot compact locality-aware backups")
+		}
+		if manifests[i].MVCCFilter != MVCCFilter_All {
+			compacted.MVCCFilter = MVCCFilter_Latest
+		}
+		if rev := manifests[i].RevisionStartTime; manifests[i].StartTime.Less(rev) &&
+			compacted.RevisionStartTime.Less(rev) {
+			compacted.RevisionStartTime = rev
+		}
+	}
+	if compacted.MVCCFilter == MVCCFilter_All {
+		for i := range manifests {
+			compacted.DescriptorChanges = append(compacted.DescriptorChanges, manifests[i].DescriptorChanges...)
+		}
+	}
+
+	var fileEncryption *roachpb.FileEncryptionOptions
+	if encryption != nil {
+		key, err := getEncryptionKey(ctx, encryption, src.Settings(), src.ExternalIOConf())
+		if err != nil {
+			return BackupManifest{}, err
+		}
+		fileEncryption = &roachpb.FileEncryptionOptions{Key: key}
+	}
+
+	pkIDs := make(map[uint64]bool)
+	for i := range compacted.Descriptors {
+		if t := descpb.TableFromDescriptor(&compacted.Descriptors[i], hlc.Timestamp{}); t != nil {
+			pkIDs[roachpb.BulkOpSummaryID(uint64(t.ID), uint64(t.PrimaryIndex.ID))] = true
+		}
+	}
+
+	w := compactedFileWriter{
+		dest:           dest,
+		fileEncryption: fileEncryption,
+		pkIDs:          pkIDs,
+		uniqueID:       uniqueID,
+	}
+	var done int
+	if checkpoint != nil {
+		// The spans are compacted in order, so those in the checkpoint are the
+		// first ones.
+		done = len(checkpoint.Spans)
+		if done > len(compacted.Spans) {
+			return BackupManifest{}, errors.Errorf(
+				"checkpoint has %d spans, compacted backup only has %d", done, len(compacted.Spans))
+		}
+		for i := 0; i < done; i++ {
+			if !checkpoint.Spans[i].Equal(compacted.Spans[i]) {
+				return BackupManifest{}, errors.Errorf(
+					"checkpoint span %s does not match compacted span %s", checkpoint.Spans[i], compacted.Spans[i])
+			}
+		}
+		w.files = checkpoint.Files
+		w.entryCounts = checkpoint.EntryCounts
+	}
+	for i := done; i < len(compacted.Spans); i++ {
+		span := compacted.Spans[i]
+		if err := compactSpan(
+			ctx, src, incPaths, manifests, span, compacted.MVCCFilter, fileEncryption, &w,
+		); err != nil {
+			return BackupManifest{}, errors.Wrapf(err, "compacting span %s", span)
+		}
+		if checkpointFn != nil {
+			if err := checkpointFn(ctx, BackupManifest{
+				Spans:       compacted.Spans[:i+1],
+				Files:       w.files,
+				EntryCounts: w.entryCounts,
+			}, float32(i+1)/float32(len(compacted.Spans))); err != nil {
+				return BackupManifest{}, err
+			}
+		}
+	}
+	compacted.Files = w.files
+	compacted.EntryCounts = w.entryCounts
+
+	// Table statistics are carried over from the last backup.
+	if len(last.StatisticsFilenames) > 0 {
+		var lastDir string
+		if len(incPaths) > 0 {
+			lastDir = path.Dir(incPaths[len(incPaths)-1])
+		}
+		written := make(map[string]struct{})
+		for _, filename := range last.StatisticsFilenames {
+			if _, ok := written[filename]; ok {
+				continue
+			}
+			written[filename] = struct{}{}
+			stats, err := readTableStatistics(ctx, src, path.Join(lastDir, filename), encryption)
+			if err != nil {
+				return BackupManifest{}, err
+			}
+			if err := writeTableStatistics(ctx, dest, filename, encryption, stats); err != nil {
+				return BackupManifest{}, err
+			}
+		}
+		compacted.StatisticsFilenames = last.StatisticsFilenames
+	}
+	return compacted, nil
+}
+
+// compactSpan writes the data of the span in the merged backups to w. The
+// files of the full backup at the root of src and of the incremental backups
+// in incPaths, whose manifests are passed in order, are read in order too, so
+// that the later backups take precedence.
+//
+// Each data file is read into memory to be iterated, so rather than opening
+// every file overlapping the span at once, the span is split at the bounds of
+// these files and each part is compacted with only the files overlapping it
+// open. As the files of a backup don't overlap each other, that is at most one
+// file per merged backup.
+func compactSpan(
+	ctx context.Context,
+	src cloud.ExternalStorage,
+	incPaths []string,
+	manifests []BackupManifest,
+	span roachpb.Span,
+	mvccFilter MVCCFilter,
+	fileEncryption *roachpb.FileEncryptionOptions,
+	w *compactedFileWriter,
+) error {
+	type spanFile struct {
+		span roachpb.Span
+		path string
+	}
+	var files []spanFile
+	var bounds []roachpb.Key
+	for i := range manifests {
+		var dir string
+		if i > 0 {
+			dir = path.Dir(incPaths[i-1])
+		}
+		for _, file := range manifests[i].Files {
+			if !file.Span.Overlaps(span) {
+				continue
+			}
+			if file.LocalityKV != "" {
+				return errors.New("cannot compact locality-aware backups")
+			}
+			f := spanFile{span: file.Span, path: path.Join(dir, file.Path)}
+			if f.span.Key.Compare(span.Key) < 0 {
+				f.span.Key = span.Key
+			}
+			if f.span.EndKey.Compare(span.EndKey) > 0 {
+				f.span.EndKey = span.EndKey
+			}
+			files = append(files, f)
+			bounds = append(bounds, f.span.Key, f.span.EndKey)
+		}
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i].Compare(bounds[j]) < 0 })
+
+	open := make(map[int]storage.SimpleIterator)
+	defer func() {
+		for _, iter := range open {
+			iter.Close()
+		}
+	}()
+	w.fileStart = span.Key
+	for b := 1; b < len(bounds); b++ {
+		part := roachpb.Span{Key: bounds[b-1], EndKey: bounds[b]}
+		if part.Key.Equal(part.EndKey) {
+			continue
+		}
+		var iters []storage.SimpleIterator
+		for i, f := range files {
+			iter, isOpen := open[i]
+			if !f.span.Overlaps(part) {
+				if isOpen && f.span.EndKey.Compare(part.Key) <= 0 {
+					iter.Close()
+					delete(open, i)
+				}
+				continue
+			}
+			if !isOpen {
+				log.VEventf(ctx, 2, "compacting file %s", f.path)
+				var err error
+				iter, err = storageccl.ExternalSSTReader(ctx, src, f.path, fileEncryption)
+				if err != nil {
+					return err
+				}
+				open[i] = iter
+			}
+			iters = append(iters, iter)
+		}
+		if err := compactSpanPart(ctx, iters, part, mvccFilter, w); err != nil {
+			return err
+		}
+	}
+	return w.flush(ctx, span.EndKey)
+}
+
+// compactSpanPart writes the data of the part of a span in the iterators to
+// w, in which the later iterators take precedence.
+func compactSpanPart(
+	ctx context.Context,
+	iters []storage.SimpleIterator,
+	part roachpb.Span,
+	mvccFilter MVCCFilter,
+	w *compactedFileWriter,
+) error {
+	if len(iters) == 0 {
+		return nil
+	}
+	iter := storage.MakeMultiIterator(iters)
+	defer iter.Close()
+	for iter.SeekGE(storage.MVCCKey{Key: part.Key}); ; {
+		ok, err := iter.Valid()
+		if err != nil {
+			return err
+		}
+		if !ok || iter.UnsafeKey().Key.Compare(part.EndKey) >= 0 {
+			return nil
+		}
+		if mvccFilter == MVCCFilter_All {
+			if err := w.put(ctx, iter.UnsafeKey(), iter.UnsafeValue()); err != nil {
+				return err
+			}
+			iter.Next()
+			continue
+		}
+		// Only the latest revision of each key is kept, and keys whose latest
+		// revision is a deletion are dropped altogether.
+		if len(iter.UnsafeValue()) > 0 {
+			if err := w.put(ctx, iter.UnsafeKey(), iter.UnsafeValue()); err != nil {
+				return err
+			}
+		}
+		iter.NextKey()
+	}
+}
+
+// compactedFileWriter writes the data files of a compacted backup.
+type compactedFileWriter struct {
+	dest           cloud.ExternalStorage
+	fileEncryption *roachpb.FileEncryptionOptions
+	pkIDs          map[uint64]bool
+	uniqueID       tree.DInt
+
+	// sst and sstFile are the data file being written, if any, whose span
+	// starts at fileStart.
+	sst       *storage.SSTWriter
+	sstFile   *storage.MemFile
+	fileStart roachpb.Key
+	prevKey   roachpb.Key
+	counter   storage.RowCounter
+
+	files       []BackupManifest_File
+	entryCounts RowCount
+}
+
+// put adds the key to the file being written, first flushing it if it is full
+// and the key is not another revision of the previous one.
+func (w *compactedFileWriter) put(ctx context.Context, key storage.MVCCKey, value []byte) error {
+	if w.sst != nil && w.sst.DataSize >= compactedFileSize && !key.Key.Equal(w.prevKey) {
+		if err := w.flush(ctx, key.Key); err != nil {
+			return err
+		}
+	}
+	if w.sst == nil {
+		w.sstFile = &storage.MemFile{}
+		sst := storage.MakeBackupSSTWriter(w.sstFile)
+		w.sst = &sst
+	}
+	if err := w.sst.Put(key, value); err != nil {
+		return err
+	}
+	w.counter.DataSize += int64(len(key.Key) + len(value))
+	if err := w.counter.Count(key.Key); err != nil {
+		return err
+	}
+	w.prevKey = append(w.prevKey[:0], key.Key...)
+	return nil
+}
+
+// flush writes the file being written, if any, to the destination, covering
+// the span from fileStart to end, and starts the next file at end.
+func (w *compactedFileWriter) flush(ctx context.Context, end roachpb.Key) error {
+	if w.sst == nil {
+		return nil
+	}
+	defer w.sst.Close()
+	if err := w.sst.Finish(); err != nil {
+		return err
+	}
+	data := w.sstFile.Data()
+	checksum, err := storageccl.SHA512ChecksumData(data)
+	if err != nil {
+		return err
+	}
+	if w.fileEncryption != nil {
+		data, err = storageccl.EncryptFile(data, w.fileEncryption.Key)
+		if err != nil {
+			return err
+		}
+	}
+	file := BackupManifest_File{
+		Span:        roachpb.Span{Key: w.fileStart, EndKey: append(roachpb.Key(nil), end...)},
+		Path:        fmt.Sprintf("%d-%d.sst", w.uniqueID, len(w.files)),
+		Sha512:      checksum,
+		EntryCounts: countRows(w.counter.BulkOpSummary, w.pkIDs),
+		FileSize:    int64(len(data)),
+	}
+	if err := w.dest.WriteFile(ctx, file.Path, bytes.NewReader(data)); err != nil {
+		return errors.Wrapf(err, "writing %s", file.Path)
+	}
+	w.files = append(w.files, file)
+	w.entryCounts.add(file.EntryCounts)
+
+	w.sst, w.sstFile = nil, nil
+	w.fileStart = file.Span.EndKey
+	w.counter = storage.RowCounter{}
+	return nil
+}
+
+func init() {
+	sql.AddPlanHook(compactBackupPlanHook)
+}
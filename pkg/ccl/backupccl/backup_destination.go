@@ -46,8 +46,15 @@ func fetchPreviousBackups(
 	if err != nil {
 		return nil, nil, err
 	}
+	readEncryption := encryptionOptions
+	if kmsEnv := encryptionParams.kmsEnv; kmsEnv != nil && kmsEnv.execCfg != nil {
+		readEncryption, err = resolveEncryptionKMS(ctx, kmsEnv.execCfg, user, encryptionOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 	prevBackups, err := getBackupManifests(ctx, user, makeCloudStorage, prevBackupURIs,
-		encryptionOptions)
+		readEncryption)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -219,7 +226,7 @@ func getEncryptionFromBase(
 				Key:  storageccl.GenerateKey(encryptionParams.encryptionPassphrase, opts.Salt),
 			}
 		case kms:
-			defaultKMSInfo, err := validateKMSURIsAgainstFullBackup(encryptionParams.kmsURIs,
+			defaultKMSInfo, err := validateKMSURIsAgainstFullBackup(ctx, encryptionParams.kmsURIs,
 				newEncryptedDataKeyMapFromProtoMap(opts.EncryptedDataKeyByKMSMasterKeyID), encryptionParams.kmsEnv)
 			if err != nil {
 				return nil, err
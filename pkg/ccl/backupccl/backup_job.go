@@ -398,6 +398,7 @@ type backupResumer struct {
 
 	testingKnobs struct {
 		ignoreProtectedTimestamps bool
+		afterCompactSpan          func() error
 	}
 }
 
@@ -408,6 +409,17 @@ func (b *backupResumer) Resume(
 	details := b.job.Details().(jobspb.BackupDetails)
 	p := phs.(sql.PlanHookState)
 
+	if details.CompactFromURI != "" {
+		return b.resumeCompaction(ctx, p, details, resultsCh)
+	}
+
+	// The job details keep an external:// KMS URI as given by the user, so
+	// resolve it for this run.
+	encryption, err := resolveEncryptionKMS(ctx, p.ExecCfg(), p.User(), details.EncryptionOptions)
+	if err != nil {
+		return err
+	}
+
 	// For all backups, partitioned or not, the main BACKUP manifest is stored at
 	// details.URI.
 	defaultConf, err := cloudimpl.ExternalStorageConfFromURI(details.URI, p.User())
@@ -431,7 +443,7 @@ func (b *backupResumer) Resume(
 	}
 
 	if err := createCheckpointIfNotExists(ctx, p.ExecCfg().Settings, defaultStore,
-		details.EncryptionOptions); err != nil {
+		encryption); err != nil {
 		return errors.Wrapf(err, "creating checkpoint to %s", redactedURI)
 	}
 
@@ -473,7 +485,7 @@ func (b *backupResumer) Resume(
 	// representations. We should just preserve whatever representation the
 	// table descriptors were using and leave them alone.
 	if desc, err := readBackupManifest(ctx, defaultStore, backupManifestCheckpointName,
-		details.EncryptionOptions); err == nil {
+		encryption); err == nil {
 		// If the checkpoint is from a different cluster, it's meaningless to us.
 		// More likely though are dummy/lock-out checkpoints with no ClusterID.
 		if desc.ClusterID.Equal(p.ExecCfg().ClusterID()) {
@@ -507,7 +519,7 @@ func (b *backupResumer) Resume(
 		&backupManifest,
 		checkpointDesc,
 		p.ExecCfg().DistSQLSrv.ExternalStorage,
-		details.EncryptionOptions,
+		encryption,
 		statsCache,
 	)
 	if err != nil {
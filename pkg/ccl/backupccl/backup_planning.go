@@ -56,6 +56,7 @@ const (
 	backupOptEncPassphrase   = "encryption_passphrase"
 	backupOptEncKMS          = "kms"
 	backupOptWithPrivileges  = "privileges"
+	backupOptCheckFiles      = "check_files"
 	localityURLParam         = "COCKROACH_LOCALITY"
 	defaultLocalityValue     = "default"
 )
@@ -93,6 +94,10 @@ type tableAndIndex struct {
 type backupKMSEnv struct {
 	settings *cluster.Settings
 	conf     *base.ExternalIODirConfig
+	// execCfg and user, if set, are used to resolve external://<name> KMS URIs
+	// on behalf of user.
+	execCfg *sql.ExecutorConfig
+	user    string
 }
 
 var _ cloud.KMSEnv = &backupKMSEnv{}
@@ -105,6 +110,66 @@ func (p *backupKMSEnv) KMSConfig() *base.ExternalIODirConfig {
 	return p.conf
 }
 
+// resolveKMSURI returns the URI stored in the external connection named by an
+// external://<name> KMS URI, with the path of kmsURI appended to it. Other
+// URIs are returned unchanged.
+func resolveKMSURI(
+	ctx context.Context, execCfg *sql.ExecutorConfig, user string, kmsURI string,
+) (string, error) {
+	u, err := url.Parse(kmsURI)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot parse KMSURI")
+	}
+	if u.Scheme != sql.ExternalConnectionScheme {
+		return kmsURI, nil
+	}
+	base, err := sql.ResolveExternalConnection(ctx, execCfg.InternalExecutor, execCfg.DB, user, u.Host)
+	if err != nil {
+		return "", err
+	}
+	return sql.JoinExternalConnectionPath(base, u.Path)
+}
+
+// kmsFromURI is like cloud.KMSFromURI, but also accepts external://<name> URIs
+// if kmsEnv is able to resolve them.
+func kmsFromURI(ctx context.Context, kmsURI string, kmsEnv cloud.KMSEnv) (cloud.KMS, error) {
+	if env, ok := kmsEnv.(*backupKMSEnv); ok && env.execCfg != nil {
+		var err error
+		if kmsURI, err = resolveKMSURI(ctx, env.execCfg, env.user, kmsURI); err != nil {
+			return nil, err
+		}
+	}
+	return cloud.KMSFromURI(kmsURI, kmsEnv)
+}
+
+// resolveEncryptionKMS returns a copy of encryption in which an
+// external://<name> KMS URI has been resolved to the URI of the connection.
+// The copy is only meant to be used in memory: job details keep the
+// external:// URI, so that the connection is looked up, and the user's USAGE
+// privilege on it checked, every time the job uses it.
+func resolveEncryptionKMS(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	user string,
+	encryption *jobspb.BackupEncryptionOptions,
+) (*jobspb.BackupEncryptionOptions, error) {
+	if encryption == nil || encryption.Mode != jobspb.EncryptionMode_KMS || encryption.KMSInfo == nil {
+		return encryption, nil
+	}
+	uri, err := resolveKMSURI(ctx, execCfg, user, encryption.KMSInfo.Uri)
+	if err != nil {
+		return nil, err
+	}
+	if uri == encryption.KMSInfo.Uri {
+		return encryption, nil
+	}
+	resolved := *encryption
+	kmsInfo := *encryption.KMSInfo
+	kmsInfo.Uri = uri
+	resolved.KMSInfo = &kmsInfo
+	return &resolved, nil
+}
+
 type plaintextMasterKeyID string
 type hashedMasterKeyID string
 type encryptedDataKeyMap struct {
@@ -367,11 +432,14 @@ func backupJobDescription(
 // encryption/decryption operations during this BACKUP. By default it is the
 // first KMS URI passed during the incremental BACKUP.
 func validateKMSURIsAgainstFullBackup(
-	kmsURIs []string, kmsMasterKeyIDToDataKey *encryptedDataKeyMap, kmsEnv cloud.KMSEnv,
+	ctx context.Context,
+	kmsURIs []string,
+	kmsMasterKeyIDToDataKey *encryptedDataKeyMap,
+	kmsEnv cloud.KMSEnv,
 ) (*jobspb.BackupEncryptionOptions_KMSInfo, error) {
 	var defaultKMSInfo *jobspb.BackupEncryptionOptions_KMSInfo
 	for _, kmsURI := range kmsURIs {
-		kms, err := cloud.KMSFromURI(kmsURI, kmsEnv)
+		kms, err := kmsFromURI(ctx, kmsURI, kmsEnv)
 		if err != nil {
 			return nil, err
 		}
@@ -549,6 +617,8 @@ func backupPlanHook(
 				return res, &backupKMSEnv{
 					settings: p.ExecCfg().Settings,
 					conf:     &p.ExecCfg().ExternalIODirConfig,
+					execCfg:  p.ExecCfg(),
+					user:     p.User(),
 				}, nil
 			}
 			return nil, nil, err
@@ -1144,7 +1214,7 @@ func protectTimestampForBackup(
 func getEncryptedDataKeyFromURI(
 	ctx context.Context, plaintextDataKey []byte, kmsURI string, kmsEnv cloud.KMSEnv,
 ) (string, []byte, error) {
-	kms, err := cloud.KMSFromURI(kmsURI, kmsEnv)
+	kms, err := kmsFromURI(ctx, kmsURI, kmsEnv)
 	if err != nil {
 		return "", nil, err
 	}
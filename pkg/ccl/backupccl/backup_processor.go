@@ -243,6 +243,7 @@ func runBackupProcessor(
 						Sha512:      file.Sha512,
 						EntryCounts: countRows(file.Exported, spec.PKIDs),
 						LocalityKV:  file.LocalityKV,
+						FileSize:    file.FileSize,
 					}
 					if span.start != spec.BackupStartTime {
 						f.StartTime = span.start
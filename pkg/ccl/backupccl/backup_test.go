@@ -4364,6 +4364,8 @@ func TestValidateKMSURIsAgainstFullBackup(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
 
+	ctx := context.Background()
+
 	for _, tc := range []struct {
 		name                  string
 		fullBackupURIs        []string
@@ -4412,7 +4414,7 @@ func TestValidateKMSURIsAgainstFullBackup(t *testing.T) {
 		}
 
 		kmsInfo, err := validateKMSURIsAgainstFullBackup(
-			tc.incrementalBackupURIs, masterKeyIDToDataKey,
+			ctx, tc.incrementalBackupURIs, masterKeyIDToDataKey,
 			&testKMSEnv{cluster.NoSettings, &base.ExternalIODirConfig{}})
 		if tc.expectError {
 			require.Error(t, err)
@@ -4666,6 +4668,313 @@ func TestRestoreDatabaseVersusTable(t *testing.T) {
 	})
 }
 
+func TestRestoreDatabaseNewDBName(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 1
+	_, _, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitNone)
+	defer cleanupFn()
+
+	for _, q := range []string{
+		`CREATE DATABASE foo`,
+		`CREATE TYPE foo.greeting AS ENUM ('hello', 'hi')`,
+		`CREATE SCHEMA foo.sc`,
+		`CREATE SEQUENCE foo.sc.seq`,
+		`CREATE TABLE foo.sc.t (
+			id INT PRIMARY KEY DEFAULT nextval('foo.sc.seq'),
+			g foo.greeting,
+			s SERIAL
+		)`,
+		`INSERT INTO foo.sc.t (g) VALUES ('hello')`,
+		`CREATE VIEW foo.v AS SELECT id, g FROM foo.sc.t`,
+	} {
+		sqlDB.Exec(t, q)
+	}
+	sqlDB.Exec(t, `SET serial_normalization = 'sql_sequence'`)
+	sqlDB.Exec(t, `CREATE TABLE foo.sc.t2 (id SERIAL PRIMARY KEY)`)
+	sqlDB.Exec(t, `BACKUP DATABASE foo TO $1`, LocalFoo)
+
+	sqlDB.Exec(t, `RESTORE DATABASE foo FROM $1 WITH new_db_name = 'foo_copy'`, LocalFoo)
+
+	// The copy is independent from the original database.
+	sqlDB.Exec(t, `DROP DATABASE foo CASCADE`)
+	sqlDB.CheckQueryResults(t, `SELECT id, g FROM foo_copy.v`, [][]string{{"1", "hello"}})
+	sqlDB.Exec(t, `INSERT INTO foo_copy.sc.t (g) VALUES ('hi')`)
+	sqlDB.Exec(t, `INSERT INTO foo_copy.sc.t2 DEFAULT VALUES`)
+	sqlDB.CheckQueryResults(t, `SELECT id, g FROM foo_copy.sc.t ORDER BY id`,
+		[][]string{{"1", "hello"}, {"2", "hi"}})
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM foo_copy.sc.t2`, [][]string{{"1"}})
+
+	// View queries and sequence references in defaults refer to the new name.
+	sqlDB.CheckQueryResults(t,
+		`SELECT strpos(create_statement, 'foo_copy.sc.t') > 0 FROM [SHOW CREATE VIEW foo_copy.v]`,
+		[][]string{{"true"}})
+	sqlDB.CheckQueryResults(t,
+		`SELECT strpos(column_default, 'foo_copy.sc.t2_id_seq') > 0
+FROM foo_copy.information_schema.columns WHERE table_name = 't2' AND column_name = 'id'`,
+		[][]string{{"true"}})
+
+	sqlDB.ExpectErr(t, `database "foo_copy" already exists`,
+		`RESTORE DATABASE foo FROM $1 WITH new_db_name = 'foo_copy'`, LocalFoo)
+	sqlDB.ExpectErr(t, `"new_db_name" option can only be used when restoring a single database`,
+		`RESTORE TABLE foo.sc.t FROM $1 WITH new_db_name = 'bar'`, LocalFoo)
+	sqlDB.ExpectErr(t, `"new_db_name" option can only be used when restoring a single database`,
+		`RESTORE DATABASE foo, data FROM $1 WITH new_db_name = 'bar'`, LocalFoo)
+	sqlDB.ExpectErr(t, `"new_db_name" option cannot be empty`,
+		`RESTORE DATABASE foo FROM $1 WITH new_db_name = ''`, LocalFoo)
+}
+
+func TestRewriteSequenceDBNames(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		expr, expected string
+	}{
+		{`nextval('foo.sc.seq':::STRING)`, `nextval('bar.sc.seq':::STRING)`},
+		{`nextval('seq':::STRING)`, `nextval('seq':::STRING)`},
+		{`currval('foo.public.seq':::STRING) + 1:::INT8`, `currval('bar.public.seq':::STRING) + 1:::INT8`},
+		// Calls to functions that are not builtins, e.g. user-defined functions,
+		// are left alone, but their arguments are still rewritten.
+		{`my_func(1:::INT8)`, `my_func(1:::INT8)`},
+		{
+			`sc.my_func(nextval('foo.public.seq':::STRING), 'foo.x.y':::STRING)`,
+			`sc.my_func(nextval('bar.public.seq':::STRING), 'foo.x.y':::STRING)`,
+		},
+	} {
+		res, err := rewriteSequenceDBNames(tc.expr, "bar")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.expr, tc.expected, res)
+		}
+	}
+}
+
+func TestRestoreVerifyBackupTableData(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 10
+	_, _, sqlDB, tempDir, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitNone)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, LocalFoo)
+
+	// Verifying the data reads all of it back but restores nothing, so it
+	// works even though the backed up tables still exist.
+	var unused string
+	var rows, indexEntries, bytes int64
+	sqlDB.QueryRow(t, `RESTORE DATABASE data FROM $1 WITH verify_backup_table_data`, LocalFoo).Scan(
+		&unused, &unused, &unused, &rows, &indexEntries, &bytes,
+	)
+	require.Equal(t, int64(numAccounts), rows)
+	require.Greater(t, bytes, int64(0))
+	sqlDB.CheckQueryResults(t,
+		`SELECT count(*) FROM system.namespace WHERE name = 'bank'`, [][]string{{"1"}})
+
+	sqlDB.ExpectErr(t, `cannot be combined`,
+		`RESTORE data.bank FROM $1 WITH verify_backup_table_data, into_db = 'other'`, LocalFoo)
+
+	// Corrupt one of the data files.
+	dataFiles, err := filepath.Glob(filepath.Join(tempDir, "foo", "*.sst"))
+	require.NoError(t, err)
+	require.NotEmpty(t, dataFiles)
+	require.NoError(t, ioutil.WriteFile(dataFiles[0], []byte("not an sst"), 0644))
+	sqlDB.ExpectErr(t, `verifying backup data`,
+		`RESTORE DATABASE data FROM $1 WITH verify_backup_table_data`, LocalFoo)
+}
+
+func TestBackupCompact(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 100
+	_, _, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitNone)
+	defer cleanupFn()
+
+	for _, withRevisions := range []bool{false, true} {
+		t.Run(fmt.Sprintf("revision_history=%t", withRevisions), func(t *testing.T) {
+			backupQuery := `BACKUP DATABASE data TO $1`
+			if withRevisions {
+				backupQuery += ` WITH revision_history`
+			}
+			from := fmt.Sprintf("%s/%t", LocalFoo, withRevisions)
+			to := from + "-compacted"
+
+			sqlDB.Exec(t, `CREATE DATABASE restored`)
+			defer sqlDB.Exec(t, `DROP DATABASE restored CASCADE`)
+			sqlDB.Exec(t, backupQuery, from)
+
+			sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1 WHERE id < 10`)
+			sqlDB.Exec(t, `DELETE FROM data.bank WHERE id >= 90`)
+			var midTS string
+			sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&midTS)
+			expectedMid := sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`)
+			sqlDB.Exec(t, backupQuery, from)
+
+			sqlDB.Exec(t, `INSERT INTO data.bank VALUES (1000, 1000, 'new')`)
+			sqlDB.Exec(t, `CREATE TABLE data.other (a INT PRIMARY KEY)`)
+			sqlDB.Exec(t, `INSERT INTO data.other VALUES (1), (2)`)
+			sqlDB.Exec(t, backupQuery, from)
+
+			var unused string
+			var rows int
+			sqlDB.QueryRow(t, `BACKUP COMPACT $1 TO $2`, from, to).Scan(
+				&unused, &unused, &unused, &rows, &unused, &unused,
+			)
+			// With revision history, the deleted rows are kept as revisions.
+			expectedRows := numAccounts - 10 + 1 + 2
+			if withRevisions {
+				expectedRows += 10
+			}
+			require.Equal(t, expectedRows, rows)
+
+			// The compacted backup is a single full backup.
+			sqlDB.CheckQueryResults(t, fmt.Sprintf(
+				`SELECT DISTINCT start_time IS NULL FROM [SHOW BACKUP '%s'] WHERE object_type = 'table'`, to),
+				[][]string{{"true"}})
+
+			sqlDB.Exec(t, `RESTORE data.* FROM $1 WITH into_db = 'restored'`, to)
+			sqlDB.CheckQueryResults(t, `SELECT * FROM restored.bank ORDER BY id`,
+				sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`))
+			sqlDB.CheckQueryResults(t, `SELECT * FROM restored.other ORDER BY a`,
+				[][]string{{"1"}, {"2"}})
+
+			if withRevisions {
+				sqlDB.Exec(t, `DROP TABLE restored.bank`)
+				sqlDB.Exec(t, fmt.Sprintf(
+					`RESTORE data.bank FROM $1 AS OF SYSTEM TIME %s WITH into_db = 'restored'`, midTS), to)
+				sqlDB.CheckQueryResults(t, `SELECT * FROM restored.bank ORDER BY id`, expectedMid)
+			}
+
+			sqlDB.Exec(t, `DROP TABLE data.other`)
+			sqlDB.Exec(t, `DELETE FROM data.bank WHERE id = 1000`)
+		})
+	}
+}
+
+// TestBackupCompactRevisionStartTime checks that a compacted backup can be
+// restored as of a time covered by the revision history of its full backup,
+// even though the incremental backups only have revision history since a later
+// GC threshold.
+func TestBackupCompactRevisionStartTime(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 10
+	ctx, tc, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitNone)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+	var fullTS string
+	sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&fullTS)
+	expectedFull := sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+	gcThreshold := tc.Server(0).Clock().Now()
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1 WITH revision_history`, LocalFoo)
+
+	// Bump the GC threshold past fullTS, so the incremental backup only has
+	// revision history from within the full backup's window.
+	gcr := roachpb.GCRequest{
+		// Bogus span to make it a valid request.
+		RequestHeader: roachpb.RequestHeader{
+			Key:    keys.SystemSQLCodec.TablePrefix(keys.MinUserDescID),
+			EndKey: keys.MaxKey,
+		},
+		Threshold: gcThreshold,
+	}
+	if _, err := kv.SendWrapped(
+		ctx, tc.Server(0).DistSenderI().(*kvcoord.DistSender), &gcr,
+	); err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1 WHERE id < 5`)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1 WITH revision_history`, LocalFoo)
+
+	to := LocalFoo + "-compacted"
+	sqlDB.Exec(t, `BACKUP COMPACT $1 TO $2`, LocalFoo, to)
+
+	sqlDB.Exec(t, `CREATE DATABASE restored`)
+	sqlDB.Exec(t, fmt.Sprintf(
+		`RESTORE data.bank FROM $1 AS OF SYSTEM TIME %s WITH into_db = 'restored'`, fullTS), to)
+	sqlDB.CheckQueryResults(t, `SELECT * FROM restored.bank ORDER BY id`, expectedFull)
+}
+
+// TestBackupCompactResume checks that a paused BACKUP COMPACT job only
+// compacts the spans which were not compacted before it was paused once it is
+// resumed.
+func TestBackupCompactResume(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	defer jobs.TestingSetAdoptAndCancelIntervals(100*time.Millisecond, 100*time.Millisecond)()
+
+	const numAccounts = 100
+	_, tc, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitNone)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `CREATE TABLE data.other (a INT PRIMARY KEY)`)
+	sqlDB.Exec(t, `INSERT INTO data.other VALUES (1), (2)`)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, LocalFoo)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1 WHERE id < 10`)
+	sqlDB.Exec(t, `INSERT INTO data.other VALUES (3)`)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, LocalFoo)
+
+	// Pause the job once its first span is compacted.
+	var compactedSpans int32
+	var pause int32 = 1
+	for _, server := range tc.Servers {
+		registry := server.JobRegistry().(*jobs.Registry)
+		registry.TestingResumerCreationKnobs = map[jobspb.Type]func(raw jobs.Resumer) jobs.Resumer{
+			jobspb.TypeBackup: func(raw jobs.Resumer) jobs.Resumer {
+				r := raw.(*backupResumer)
+				r.testingKnobs.afterCompactSpan = func() error {
+					atomic.AddInt32(&compactedSpans, 1)
+					if atomic.CompareAndSwapInt32(&pause, 1, 0) {
+						_, err := sqlDB.DB.ExecContext(context.Background(), `PAUSE JOB $1`, *r.job.ID())
+						return err
+					}
+					return nil
+				}
+				return r
+			},
+		}
+	}
+
+	to := LocalFoo + "-compacted"
+	// The job stops when it fails to update its progress once the pause is
+	// requested.
+	sqlDB.ExpectErr(t, `pause-requested job|job paused`, `BACKUP COMPACT $1 TO $2`, LocalFoo, to)
+	require.Equal(t, int32(1), atomic.LoadInt32(&compactedSpans))
+
+	var jobID int64
+	sqlDB.QueryRow(t,
+		`SELECT job_id FROM [SHOW JOBS] WHERE description LIKE 'BACKUP COMPACT%'`,
+	).Scan(&jobID)
+	testutils.SucceedsSoon(t, func() error {
+		var status string
+		sqlDB.QueryRow(t, `SELECT status FROM [SHOW JOBS] WHERE job_id = $1`, jobID).Scan(&status)
+		if status != string(jobs.StatusPaused) {
+			return errors.Errorf("job %d is %s", jobID, status)
+		}
+		return nil
+	})
+	sqlDB.Exec(t, `RESUME JOB $1`, jobID)
+	jobutils.WaitForJob(t, sqlDB, jobID)
+	// The database has a span for each of its two tables, and only the second
+	// one is compacted when the job is resumed.
+	require.Equal(t, int32(2), atomic.LoadInt32(&compactedSpans))
+
+	sqlDB.Exec(t, `CREATE DATABASE restored`)
+	sqlDB.Exec(t, `RESTORE data.* FROM $1 WITH into_db = 'restored'`, to)
+	sqlDB.CheckQueryResults(t, `SELECT * FROM restored.bank ORDER BY id`,
+		sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`))
+	sqlDB.CheckQueryResults(t, `SELECT * FROM restored.other ORDER BY a`,
+		[][]string{{"1"}, {"2"}, {"3"}})
+}
+
 func TestBackupAzureAccountName(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -6436,3 +6745,117 @@ func flipBitInManifests(t *testing.T, rawDir string) {
 		t.Fatal("found no manifest")
 	}
 }
+
+// TestBackupRestoreExternalConnection tests that BACKUP, SHOW BACKUP and
+// RESTORE resolve external://<name> URIs through system.external_connections,
+// and that the connection can be repointed in one place.
+func TestBackupRestoreExternalConnection(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 10
+	_, tc, sqlDB, rawDir, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitNone)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `CREATE EXTERNAL CONNECTION backups AS 'nodelocal://0/conn1'`)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO 'external://backups/full'`)
+	_, err := os.Stat(filepath.Join(rawDir, "conn1", "full", backupManifestName))
+	require.NoError(t, err)
+
+	sqlDB.CheckQueryResults(t,
+		`SELECT DISTINCT object_name FROM [SHOW BACKUP 'external://backups/full'] WHERE object_type = 'table'`,
+		[][]string{{"bank"}})
+
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM 'external://backups/full' WITH new_db_name = 'data2'`)
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM data2.bank`, [][]string{{"10"}})
+
+	// Repointing the connection redirects subsequent backups.
+	sqlDB.Exec(t, `ALTER EXTERNAL CONNECTION backups AS 'nodelocal://0/conn2'`)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO 'external://backups/full'`)
+	_, err = os.Stat(filepath.Join(rawDir, "conn2", "full", backupManifestName))
+	require.NoError(t, err)
+
+	sqlDB.ExpectErr(t, `external connection "missing" does not exist`,
+		`BACKUP DATABASE data TO 'external://missing/full'`)
+
+	// Users need the USAGE privilege on the connection to reference it.
+	sqlDB.Exec(t, `CREATE USER testuser`)
+	sqlDB.Exec(t, `GRANT SELECT ON data.bank TO testuser`)
+	pgURL, cleanup := sqlutils.PGUrl(t, tc.Server(0).ServingSQLAddr(),
+		"TestBackupRestoreExternalConnection-testuser", url.User("testuser"))
+	defer cleanup()
+	testuserDB, err := gosql.Open("postgres", pgURL.String())
+	require.NoError(t, err)
+	defer testuserDB.Close()
+	testuser := sqlutils.MakeSQLRunner(testuserDB)
+
+	testuser.ExpectErr(t, `user testuser does not have USAGE privilege on external connection backups`,
+		`BACKUP TABLE data.bank TO 'external://backups/testuser'`)
+	sqlDB.Exec(t, `GRANT USAGE ON EXTERNAL CONNECTION backups TO testuser`)
+	testuser.Exec(t, `BACKUP TABLE data.bank TO 'external://backups/testuser'`)
+	_, err = os.Stat(filepath.Join(rawDir, "conn2", "testuser", backupManifestName))
+	require.NoError(t, err)
+
+	// A KMS can be referenced through an external connection as well. The
+	// job records the name of the connection rather than the URI it stores.
+	kmsURI := constructMockKMSURIsWithKeyID([]string{"conn-key"})[0]
+	sqlDB.Exec(t, `CREATE EXTERNAL CONNECTION kms AS $1`, kmsURI)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO 'external://backups/kms' WITH kms = 'external://kms'`)
+	var payloadBytes []byte
+	sqlDB.QueryRow(t, `SELECT payload FROM system.jobs WHERE id = (
+		SELECT job_id FROM [SHOW JOBS] WHERE job_type = 'BACKUP' ORDER BY created DESC LIMIT 1)`,
+	).Scan(&payloadBytes)
+	payload := &jobspb.Payload{}
+	require.NoError(t, protoutil.Unmarshal(payloadBytes, payload))
+	require.Equal(t, "external://kms",
+		payload.Details.(*jobspb.Payload_Backup).Backup.EncryptionOptions.KMSInfo.Uri)
+
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM 'external://backups/kms'
+		WITH kms = 'external://kms', new_db_name = 'data3'`)
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM data3.bank`, [][]string{{"10"}})
+	sqlDB.QueryRow(t, `SELECT payload FROM system.jobs WHERE id = (
+		SELECT job_id FROM [SHOW JOBS] WHERE job_type = 'RESTORE' ORDER BY created DESC LIMIT 1)`,
+	).Scan(&payloadBytes)
+	require.NoError(t, protoutil.Unmarshal(payloadBytes, payload))
+	require.Equal(t, "external://kms",
+		payload.Details.(*jobspb.Payload_Restore).Restore.Encryption.KMSInfo.Uri)
+}
+
+func TestRestoreRowsWhere(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 10
+	_, _, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitNone)
+	defer cleanupFn()
+
+	full, inc := LocalFoo+"/full", LocalFoo+"/inc"
+	sqlDB.Exec(t, `CREATE INDEX balance_idx ON data.bank (balance)`)
+	sqlDB.Exec(t, `BACKUP data.bank TO $1 WITH revision_history`, full)
+
+	var beforeUpdate string
+	sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&beforeUpdate)
+	expected := sqlDB.QueryStr(t,
+		`SELECT id, balance, payload FROM data.bank WHERE id BETWEEN 2 AND 4 OR id = 7 ORDER BY id`)
+
+	// Clobber every row and capture the damage in an incremental backup.
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = -1`)
+	sqlDB.Exec(t, `BACKUP data.bank TO $1 INCREMENTAL FROM $2 WITH revision_history`, inc, full)
+
+	sqlDB.Exec(t, `CREATE DATABASE scratch`)
+	sqlDB.Exec(t, fmt.Sprintf(`RESTORE data.bank FROM $1, $2 AS OF SYSTEM TIME %s
+		WITH into_db = 'scratch', where = 'id BETWEEN 2 AND 4 OR id = 7'`, beforeUpdate), full, inc)
+	sqlDB.CheckQueryResults(t,
+		`SELECT id, balance, payload FROM scratch.bank ORDER BY id`, expected)
+
+	// Only the primary index is restored.
+	sqlDB.CheckQueryResults(t,
+		`SELECT DISTINCT index_name FROM [SHOW INDEXES FROM scratch.bank]`, [][]string{{"primary"}})
+
+	sqlDB.ExpectErr(t, `column "balance" is not part of the primary key`,
+		`RESTORE data.bank FROM $1 WITH into_db = 'scratch2', where = 'balance > 0'`, full)
+	sqlDB.ExpectErr(t, `"where" option can only be used when restoring a single table`,
+		`RESTORE DATABASE data FROM $1 WITH where = 'id = 1'`, full)
+	sqlDB.ExpectErr(t, `"where" option cannot be combined with "verify_backup_table_data"`,
+		`RESTORE data.bank FROM $1 WITH where = 'id = 1', verify_backup_table_data`, full)
+}
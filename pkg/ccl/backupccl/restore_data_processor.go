@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/rowexec"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/errors"
@@ -146,15 +147,25 @@ func (rd *restoreDataProcessor) Next() (rowenc.EncDatumRow, *execinfrapb.Produce
 		Encryption:    rd.spec.Encryption,
 	}
 
-	importRes, pErr := kv.SendWrapped(rd.Ctx, rd.flowCtx.Cfg.DB.NonTransactionalSender(), importRequest)
-	if pErr != nil {
-		rd.MoveToDraining(errors.Wrapf(pErr.GoError(), "importing span %v", importRequest.DataSpan))
-		return nil, rd.DrainHelper()
+	var summary roachpb.BulkOpSummary
+	if rd.spec.ValidateOnly {
+		summary, err = rd.verifyImport(importRequest)
+		if err != nil {
+			rd.MoveToDraining(errors.Wrapf(err, "verifying span %v", importRequest.DataSpan))
+			return nil, rd.DrainHelper()
+		}
+	} else {
+		importRes, pErr := kv.SendWrapped(rd.Ctx, rd.flowCtx.Cfg.DB.NonTransactionalSender(), importRequest)
+		if pErr != nil {
+			rd.MoveToDraining(errors.Wrapf(pErr.GoError(), "importing span %v", importRequest.DataSpan))
+			return nil, rd.DrainHelper()
+		}
+		summary = importRes.(*roachpb.ImportResponse).Imported
 	}
 
 	var prog execinfrapb.RemoteProducerMetadata_BulkProcessorProgress
 	progDetails := RestoreProgress{}
-	progDetails.Summary = countRows(importRes.(*roachpb.ImportResponse).Imported, rd.spec.PKIDs)
+	progDetails.Summary = countRows(summary, rd.spec.PKIDs)
 	progDetails.ProgressIdx = entry.ProgressIdx
 	progDetails.DataSpan = entry.Span
 	details, err := gogotypes.MarshalAny(&progDetails)
@@ -166,6 +177,26 @@ func (rd *restoreDataProcessor) Next() (rowenc.EncDatumRow, *execinfrapb.Produce
 	return nil, &execinfrapb.ProducerMetadata{BulkProcessorProgress: &prog}
 }
 
+// verifyImport reads the data an ImportRequest would import, checking that its
+// files can be read, decrypted and iterated and that their checksums match,
+// without writing anything. It returns a summary of the data like the one an
+// ImportRequest would.
+func (rd *restoreDataProcessor) verifyImport(
+	req *roachpb.ImportRequest,
+) (roachpb.BulkOpSummary, error) {
+	var counter storage.RowCounter
+	if err := storageccl.ReadImportFiles(
+		rd.Ctx, req, rd.flowCtx.Cfg.ExternalStorage, rd.kr,
+		func(key storage.MVCCKey, value roachpb.Value) error {
+			counter.DataSize += int64(len(key.Key) + len(value.RawBytes))
+			return counter.Count(key.Key)
+		},
+	); err != nil {
+		return roachpb.BulkOpSummary{}, err
+	}
+	return counter.BulkOpSummary, nil
+}
+
 // ConsumerClosed is part of the RowSource interface.
 func (rd *restoreDataProcessor) ConsumerClosed() {
 	rd.close()
@@ -490,6 +490,7 @@ func restore(
 	spans []roachpb.Span,
 	job *jobs.Job,
 	encryption *jobspb.BackupEncryptionOptions,
+	validateOnly bool,
 ) (RowCount, error) {
 	user := phs.User()
 	// A note about contexts and spans in this method: the top-level context
@@ -626,6 +627,7 @@ func restore(
 		encryption,
 		rekeys,
 		endTime,
+		validateOnly,
 		progCh,
 	); err != nil {
 		return emptyRowCount, err
@@ -852,10 +854,21 @@ func createImportingDescriptors(
 	// that is, in the 'old' keyspace, before we reassign the table IDs.
 	spans = spansForAllTableIndexes(p.ExecCfg().Codec, tables, nil)
 
+	// A restore of a subset of the rows of a table only restores those rows of
+	// its primary index.
+	if len(details.RowSpans) > 0 {
+		for _, table := range mutableTables {
+			stripTableForRowRestore(table)
+		}
+		spans = details.RowSpans
+	}
+
 	log.Eventf(ctx, "starting restore for %d tables", len(mutableTables))
 
 	// Assign new IDs to the database descriptors.
-	if err := rewriteDatabaseDescs(mutableDatabases, details.DescriptorRewrites); err != nil {
+	if err := rewriteDatabaseDescs(
+		mutableDatabases, details.DescriptorRewrites, details.NewDBName,
+	); err != nil {
 		return nil, nil, nil, err
 	}
 	databaseDescs := make([]*descpb.DatabaseDescriptor, len(mutableDatabases))
@@ -866,7 +879,8 @@ func createImportingDescriptors(
 	// Assign new IDs and privileges to the tables, and update all references to
 	// use the new IDs.
 	if err := RewriteTableDescs(
-		mutableTables, details.DescriptorRewrites, details.OverrideDB,
+		mutableTables, details.DescriptorRewrites,
+		restoreOverrideDB(details.OverrideDB, details.NewDBName),
 		r.versionAtLeast20_2,
 	); err != nil {
 		return nil, nil, nil, err
@@ -944,7 +958,8 @@ func createImportingDescriptors(
 		dbsByID[databases[i].GetID()] = databases[i]
 	}
 
-	if !details.PrepareCompleted {
+	// Restores which only verify the backed up data don't write anything.
+	if !details.PrepareCompleted && !details.VerifyData {
 		err := descs.Txn(
 			ctx, p.ExecCfg().Settings, p.ExecCfg().LeaseManager,
 			p.ExecCfg().InternalExecutor, p.ExecCfg().DB, func(
@@ -1074,8 +1089,15 @@ func (r *restoreResumer) Resume(
 	r.versionAtLeast20_2 = p.ExecCfg().Settings.Version.IsActive(
 		ctx, clusterversion.VersionLeasedDatabaseDescriptors)
 
+	// The job details keep an external:// KMS URI as given by the user, so
+	// resolve it for this run.
+	encryption, err := resolveEncryptionKMS(ctx, p.ExecCfg(), p.User(), details.Encryption)
+	if err != nil {
+		return err
+	}
+
 	backupManifests, latestBackupManifest, sqlDescs, err := loadBackupSQLDescs(
-		ctx, p, details, details.Encryption,
+		ctx, p, details, encryption,
 	)
 	if err != nil {
 		return err
@@ -1101,13 +1123,17 @@ func (r *restoreResumer) Resume(
 	// importing descriptors.
 	details = r.job.Details().(jobspb.RestoreDetails)
 
+	if details.VerifyData {
+		return r.verifyBackupData(ctx, p, backupManifests, tables, oldTableIDs, spans, encryption, resultsCh)
+	}
+
 	if fn := r.testingKnobs.afterOfflineTableCreation; fn != nil {
 		if err := fn(); err != nil {
 			return err
 		}
 	}
 	r.execCfg = p.ExecCfg()
-	backupStats, err := getStatisticsFromBackup(ctx, defaultStore, details.Encryption, latestBackupManifest)
+	backupStats, err := getStatisticsFromBackup(ctx, defaultStore, encryption, latestBackupManifest)
 	if err != nil {
 		return err
 	}
@@ -1163,7 +1189,8 @@ func (r *restoreResumer) Resume(
 		oldTableIDs,
 		spans,
 		r.job,
-		details.Encryption,
+		encryption,
+		false, /* validateOnly */
 	)
 	if err != nil {
 		return err
@@ -1236,6 +1263,60 @@ func (r *restoreResumer) Resume(
 	return nil
 }
 
+// verifyBackupData runs the restore data pipeline over the backed up data of
+// the tables and tenants being restored, reading, decrypting and checksumming
+// every file, without writing anything. The counts of what was read are
+// returned like those of a restore.
+func (r *restoreResumer) verifyBackupData(
+	ctx context.Context,
+	p sql.PlanHookState,
+	backupManifests []BackupManifest,
+	tables []catalog.TableDescriptor,
+	oldTableIDs []descpb.ID,
+	spans []roachpb.Span,
+	encryption *jobspb.BackupEncryptionOptions,
+	resultsCh chan<- tree.Datums,
+) error {
+	details := r.job.Details().(jobspb.RestoreDetails)
+	numClusterNodes, err := clusterNodeCount(p.ExecCfg().Gossip)
+	if err != nil {
+		return err
+	}
+	for _, tenant := range details.Tenants {
+		prefix := keys.MakeTenantPrefix(roachpb.MakeTenantID(tenant.ID))
+		spans = append(spans, roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()})
+	}
+
+	res, err := restore(
+		ctx,
+		p,
+		numClusterNodes,
+		backupManifests,
+		details.BackupLocalityInfo,
+		details.EndTime,
+		tables,
+		oldTableIDs,
+		spans,
+		r.job,
+		encryption,
+		true, /* validateOnly */
+	)
+	if err != nil {
+		return errors.Wrap(err, "verifying backup data")
+	}
+
+	resultsCh <- tree.Datums{
+		tree.NewDInt(tree.DInt(*r.job.ID())),
+		tree.NewDString(string(jobs.StatusSucceeded)),
+		tree.NewDFloat(tree.DFloat(1.0)),
+		tree.NewDInt(tree.DInt(res.Rows)),
+		tree.NewDInt(tree.DInt(res.IndexEntries)),
+		tree.NewDInt(tree.DInt(res.DataSize)),
+	}
+	telemetry.Count("restore.verify-data.succeeded")
+	return nil
+}
+
 // Initiate a run of CREATE STATISTICS. We don't know the actual number of
 // rows affected per table, so we use a large number because we want to make
 // sure that stats always get created/refreshed here.
@@ -1440,6 +1521,11 @@ func (r *restoreResumer) OnFailOrCancel(ctx context.Context, phs interface{}) er
 
 	details := r.job.Details().(jobspb.RestoreDetails)
 
+	// Restores which only verify the backed up data have nothing to clean up.
+	if details.VerifyData {
+		return nil
+	}
+
 	execCfg := phs.(sql.PlanHookState).ExecCfg()
 	return descs.Txn(ctx, execCfg.Settings, execCfg.LeaseManager, execCfg.InternalExecutor,
 		execCfg.DB, func(ctx context.Context, txn *kv.Txn, descsCol *descs.Collection) error {
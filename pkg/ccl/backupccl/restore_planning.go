@@ -40,7 +40,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
@@ -58,10 +60,13 @@ type DescRewriteMap map[descpb.ID]*jobspb.RestoreDetails_DescriptorRewrite
 
 const (
 	restoreOptIntoDB                    = "into_db"
+	restoreOptNewDBName                 = "new_db_name"
 	restoreOptSkipMissingFKs            = "skip_missing_foreign_keys"
 	restoreOptSkipMissingSequences      = "skip_missing_sequences"
 	restoreOptSkipMissingSequenceOwners = "skip_missing_sequence_owners"
 	restoreOptSkipMissingViews          = "skip_missing_views"
+	restoreOptVerifyData                = "verify_backup_table_data"
+	restoreOptWhere                     = "where"
 
 	// The temporary database system tables will be restored into for full
 	// cluster backups.
@@ -94,6 +99,64 @@ func rewriteViewQueryDBNames(table *tabledesc.Mutable, newDB string) error {
 	return nil
 }
 
+// rewriteSequenceDBNames rewrites the database qualifiers of the sequence names
+// passed to sequence builtins in expr, e.g. nextval('db.public.seq'), to newDB.
+// Names without an explicit database are left unchanged, as are the arguments
+// of functions that are not builtins, such as user-defined functions.
+func rewriteSequenceDBNames(expr string, newDB string) (string, error) {
+	parsed, err := parser.ParseExpr(expr)
+	if err != nil {
+		return "", err
+	}
+	newExpr, err := tree.SimpleVisit(parsed, func(e tree.Expr) (bool, tree.Expr, error) {
+		fn, ok := e.(*tree.FuncExpr)
+		if !ok || len(fn.Exprs) == 0 {
+			return true, e, nil
+		}
+		def, err := fn.Func.Resolve(sessiondata.SearchPath{})
+		if err != nil {
+			// The function is not a builtin. Its arguments may still contain
+			// calls to sequence builtins, so keep visiting them.
+			return true, e, nil
+		}
+		if props, _ := builtins.GetBuiltinProperties(def.Name); props == nil || !props.HasSequenceArguments {
+			return true, e, nil
+		}
+		// The sequence name is the first argument of all sequence builtins. It
+		// is usually annotated as a STRING when serialized.
+		arg := fn.Exprs[0]
+		annotated, isAnnotated := arg.(*tree.AnnotateTypeExpr)
+		if isAnnotated {
+			arg = annotated.Expr
+		}
+		str, ok := arg.(*tree.StrVal)
+		if !ok {
+			return true, e, nil
+		}
+		seqName, err := parser.ParseTableName(str.RawString())
+		if err != nil {
+			return false, nil, err
+		}
+		if seqName.NumParts < 3 {
+			return true, e, nil
+		}
+		seqName.Parts[2] = newDB
+		var newArg tree.Expr = tree.NewStrVal(seqName.String())
+		if isAnnotated {
+			newAnnotated := *annotated
+			newAnnotated.Expr = newArg
+			newArg = &newAnnotated
+		}
+		newFn := *fn
+		newFn.Exprs = append(tree.Exprs{newArg}, fn.Exprs[1:]...)
+		return false, &newFn, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return tree.Serialize(newExpr), nil
+}
+
 // rewriteTypesInExpr rewrites all explicit ID type references in the input
 // expression string according to rewrites.
 func rewriteTypesInExpr(expr string, rewrites DescRewriteMap) (string, error) {
@@ -152,6 +215,37 @@ func maybeFilterMissingViews(
 	return filteredTablesByID, nil
 }
 
+// makeVerifyDescriptorRewrites returns a rewrite of every descriptor to itself.
+// Restores which only verify the backed up data neither allocate IDs nor write
+// descriptors, so the data is read back in its original keyspace.
+func makeVerifyDescriptorRewrites(
+	databasesByID map[descpb.ID]*dbdesc.Mutable,
+	schemasByID map[descpb.ID]*schemadesc.Mutable,
+	tablesByID map[descpb.ID]*tabledesc.Mutable,
+	typesByID map[descpb.ID]*typedesc.Mutable,
+) DescRewriteMap {
+	descriptorRewrites := make(DescRewriteMap)
+	for id := range databasesByID {
+		descriptorRewrites[id] = &jobspb.RestoreDetails_DescriptorRewrite{ID: id}
+	}
+	for id, sc := range schemasByID {
+		descriptorRewrites[id] = &jobspb.RestoreDetails_DescriptorRewrite{
+			ID: id, ParentID: sc.GetParentID(),
+		}
+	}
+	for id, table := range tablesByID {
+		descriptorRewrites[id] = &jobspb.RestoreDetails_DescriptorRewrite{
+			ID: id, ParentID: table.GetParentID(),
+		}
+	}
+	for id, typ := range typesByID {
+		descriptorRewrites[id] = &jobspb.RestoreDetails_DescriptorRewrite{
+			ID: id, ParentID: typ.GetParentID(),
+		}
+	}
+	return descriptorRewrites
+}
+
 // allocateDescriptorRewrites determines the new ID and parentID (a "DescriptorRewrite")
 // for each table in sqlDescs and returns a mapping from old ID to said
 // DescriptorRewrite. It first validates that the provided sqlDescs can be restored
@@ -168,6 +262,7 @@ func allocateDescriptorRewrites(
 	descriptorCoverage tree.DescriptorCoverage,
 	opts tree.RestoreOptions,
 	intoDB string,
+	newDBName string,
 ) (DescRewriteMap, error) {
 	descriptorRewrites := make(DescRewriteMap)
 	var overrideDB string
@@ -186,6 +281,16 @@ func allocateDescriptorRewrites(
 		return nil, errors.Errorf("cannot use %q option when restoring database(s)", restoreOptIntoDB)
 	}
 
+	// The names the restored databases will have in the cluster.
+	newDBNames := make(map[string]struct{}, len(restoreDBNames))
+	for name := range restoreDBNames {
+		newDBNames[name] = struct{}{}
+	}
+	if opts.NewDBName != nil {
+		// The plan hook checked that exactly one database is being restored.
+		newDBNames = map[string]struct{}{newDBName: {}}
+	}
+
 	// The logic at the end of this function leaks table IDs, so fail fast if
 	// we can be certain the restore will fail.
 
@@ -313,7 +418,7 @@ func allocateDescriptorRewrites(
 	// incompatible with this restore.
 	if err := p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
 		// Check that any DBs being restored do _not_ exist.
-		for name := range restoreDBNames {
+		for name := range newDBNames {
 			found, _, err := catalogkv.LookupDatabaseID(ctx, txn, p.ExecCfg().Codec, name)
 			if err != nil {
 				return err
@@ -714,15 +819,34 @@ func maybeUpgradeTableDescsInBackupManifests(
 	return nil
 }
 
+// restoreOverrideDB returns the database that everything being restored will
+// be in post-restore, if the restore renames it. Only one of intoDB and
+// newDBName can be set, since into_db cannot be used when restoring databases
+// and new_db_name can only be used when restoring one.
+func restoreOverrideDB(intoDB, newDBName string) string {
+	if newDBName != "" {
+		return newDBName
+	}
+	return intoDB
+}
+
 // rewriteDatabaseDescs rewrites all ID's in the input slice of
-// DatabaseDescriptors using the input ID rewrite mapping.
-func rewriteDatabaseDescs(databases []*dbdesc.Mutable, descriptorRewrites DescRewriteMap) error {
+// DatabaseDescriptors using the input ID rewrite mapping. If newDBName is set,
+// the databases are renamed to it.
+func rewriteDatabaseDescs(
+	databases []*dbdesc.Mutable, descriptorRewrites DescRewriteMap, newDBName string,
+) error {
 	for _, db := range databases {
 		rewrite, ok := descriptorRewrites[db.ID]
 		if !ok {
 			return errors.Errorf("missing rewrite for database %d", db.ID)
 		}
 		db.ID = rewrite.ID
+		// The new_db_name option can only be used when restoring a single
+		// database, so it applies to every database here.
+		if newDBName != "" {
+			db.Name = newDBName
+		}
 
 		db.Version = 1
 		db.ModificationTime = hlc.Timestamp{}
@@ -836,7 +960,8 @@ func maybeRewriteSchemaID(curSchemaID descpb.ID, descriptorRewrites DescRewriteM
 
 // RewriteTableDescs mutates tables to match the ID and privilege specified
 // in descriptorRewrites, as well as adjusting cross-table references to use the
-// new IDs. overrideDB can be specified to set database names in views. The
+// new IDs. overrideDB can be specified to set database names in views and in
+// the sequence names referenced by column defaults. The
 // canResetModTime parameter is set based on the cluster version. It is unsafe
 // to reset the mod time in a mixed version state as 20.1 nodes expect the mod
 // time to be set on all descriptors which are deserialized, even at version 1.
@@ -1001,6 +1126,15 @@ func RewriteTableDescs(
 				}
 			}
 			col.UsesSequenceIds = newUsedSeqRefs
+			// As with views, the sequences referenced by the default expression
+			// will be in the override DB post-restore.
+			if col.DefaultExpr != nil && len(col.UsesSequenceIds) > 0 && overrideDB != "" {
+				newExpr, err := rewriteSequenceDBNames(*col.DefaultExpr, overrideDB)
+				if err != nil {
+					return err
+				}
+				col.DefaultExpr = &newExpr
+			}
 
 			var newOwnedSeqRefs []descpb.ID
 			for _, seqID := range col.OwnsSequenceIds {
@@ -1065,7 +1199,7 @@ func getUserDescriptorNames(
 }
 
 func resolveOptionsForRestoreJobDescription(
-	opts tree.RestoreOptions, intoDB string, kmsURIs []string,
+	opts tree.RestoreOptions, intoDB string, newDBName string, where string, kmsURIs []string,
 ) (tree.RestoreOptions, error) {
 	if opts.IsDefault() {
 		return opts, nil
@@ -1077,6 +1211,7 @@ func resolveOptionsForRestoreJobDescription(
 		SkipMissingSequenceOwners: opts.SkipMissingSequenceOwners,
 		SkipMissingViews:          opts.SkipMissingViews,
 		Detached:                  opts.Detached,
+		VerifyData:                opts.VerifyData,
 	}
 
 	if opts.EncryptionPassphrase != nil {
@@ -1087,6 +1222,14 @@ func resolveOptionsForRestoreJobDescription(
 		newOpts.IntoDB = tree.NewDString(intoDB)
 	}
 
+	if opts.NewDBName != nil {
+		newOpts.NewDBName = tree.NewDString(newDBName)
+	}
+
+	if opts.Where != nil {
+		newOpts.Where = tree.NewDString(where)
+	}
+
 	for _, uri := range kmsURIs {
 		redactedURI, err := cloudimpl.RedactKMSURI(uri)
 		if err != nil {
@@ -1104,6 +1247,8 @@ func restoreJobDescription(
 	from [][]string,
 	opts tree.RestoreOptions,
 	intoDB string,
+	newDBName string,
+	where string,
 	kmsURIs []string,
 ) (string, error) {
 	r := &tree.Restore{
@@ -1115,7 +1260,7 @@ func restoreJobDescription(
 
 	var options tree.RestoreOptions
 	var err error
-	if options, err = resolveOptionsForRestoreJobDescription(opts, intoDB, kmsURIs); err != nil {
+	if options, err = resolveOptionsForRestoreJobDescription(opts, intoDB, newDBName, where, kmsURIs); err != nil {
 		return "", err
 	}
 	r.Options = options
@@ -1182,6 +1327,43 @@ func restorePlanHook(
 		}
 	}
 
+	var newDBNameFn func() (string, error)
+	if restoreStmt.Options.NewDBName != nil {
+		if len(restoreStmt.Targets.Databases) != 1 {
+			return nil, nil, nil, false, errors.Errorf(
+				"%q option can only be used when restoring a single database", restoreOptNewDBName)
+		}
+		newDBNameFn, err = p.TypeAsString(ctx, restoreStmt.Options.NewDBName, "RESTORE")
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+
+	var whereFn func() (string, error)
+	if restoreStmt.Options.Where != nil {
+		if len(restoreStmt.Targets.Databases) != 0 || len(restoreStmt.Targets.Tables) != 1 ||
+			restoreStmt.DescriptorCoverage == tree.AllDescriptors {
+			return nil, nil, nil, false, errors.Errorf(
+				"%q option can only be used when restoring a single table", restoreOptWhere)
+		}
+		if restoreStmt.Options.VerifyData {
+			return nil, nil, nil, false, errors.Errorf(
+				"%q option cannot be combined with %q", restoreOptWhere, restoreOptVerifyData)
+		}
+		whereFn, err = p.TypeAsString(ctx, restoreStmt.Options.Where, "RESTORE")
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+
+	if restoreStmt.Options.VerifyData {
+		if restoreStmt.Options.IntoDB != nil || restoreStmt.Options.NewDBName != nil {
+			return nil, nil, nil, false, errors.Errorf(
+				"%q option cannot be combined with %q or %q",
+				restoreOptVerifyData, restoreOptIntoDB, restoreOptNewDBName)
+		}
+	}
+
 	subdirFn := func() (string, error) { return "", nil }
 	if restoreStmt.Subdir != nil {
 		subdirFn, err = p.TypeAsString(ctx, restoreStmt.Subdir, "RESTORE")
@@ -1262,7 +1444,28 @@ func restorePlanHook(
 			}
 		}
 
-		return doRestorePlan(ctx, restoreStmt, p, from, passphrase, kms, intoDB, endTime, resultsCh)
+		var newDBName string
+		if newDBNameFn != nil {
+			newDBName, err = newDBNameFn()
+			if err != nil {
+				return err
+			}
+			if newDBName == "" {
+				return errors.Errorf("%q option cannot be empty", restoreOptNewDBName)
+			}
+		}
+
+		var where string
+		if whereFn != nil {
+			where, err = whereFn()
+			if err != nil {
+				return err
+			}
+		}
+
+		return doRestorePlan(
+			ctx, restoreStmt, p, from, passphrase, kms, intoDB, newDBName, where, endTime, resultsCh,
+		)
 	}
 
 	if restoreStmt.Options.Detached {
@@ -1336,6 +1539,8 @@ func doRestorePlan(
 	passphrase string,
 	kms []string,
 	intoDB string,
+	newDBName string,
+	where string,
 	endTime hlc.Timestamp,
 	resultsCh chan<- tree.Datums,
 ) error {
@@ -1367,10 +1572,12 @@ func doRestorePlan(
 			return err
 		}
 		ioConf := baseStores[0].ExternalIOConf()
-		defaultKMSInfo, err := validateKMSURIsAgainstFullBackup(kms,
+		defaultKMSInfo, err := validateKMSURIsAgainstFullBackup(ctx, kms,
 			newEncryptedDataKeyMapFromProtoMap(opts.EncryptedDataKeyByKMSMasterKeyID), &backupKMSEnv{
-				baseStores[0].Settings(),
-				&ioConf,
+				settings: baseStores[0].Settings(),
+				conf:     &ioConf,
+				execCfg:  p.ExecCfg(),
+				user:     p.User(),
 			})
 		if err != nil {
 			return err
@@ -1380,8 +1587,14 @@ func doRestorePlan(
 			KMSInfo: defaultKMSInfo}
 	}
 
+	// The job details keep an external:// KMS URI, which is resolved each time
+	// the job runs; resolve it here only to read the backups.
+	readEncryption, err := resolveEncryptionKMS(ctx, p.ExecCfg(), p.User(), encryption)
+	if err != nil {
+		return err
+	}
 	defaultURIs, mainBackupManifests, localityInfo, err := resolveBackupManifests(
-		ctx, baseStores, p.ExecCfg().DistSQLSrv.ExternalStorageFromURI, from, endTime, encryption,
+		ctx, baseStores, p.ExecCfg().DistSQLSrv.ExternalStorageFromURI, from, endTime, readEncryption,
 		p.User(),
 	)
 	if err != nil {
@@ -1395,13 +1608,15 @@ func doRestorePlan(
 		return errors.Errorf("full cluster RESTORE can only be used on full cluster BACKUP files")
 	}
 
-	// Ensure that no user table descriptors exist for a full cluster restore.
+	// Ensure that no user table descriptors exist for a full cluster restore,
+	// unless the restore only verifies the backed up data.
 	txn := p.ExecCfg().DB.NewTxn(ctx, "count-user-descs")
 	descCount, err := catalogkv.CountUserDescriptors(ctx, txn, p.ExecCfg().Codec)
 	if err != nil {
 		return errors.Wrap(err, "looking up user descriptors during restore")
 	}
-	if descCount != 0 && restoreStmt.DescriptorCoverage == tree.AllDescriptors {
+	if descCount != 0 && restoreStmt.DescriptorCoverage == tree.AllDescriptors &&
+		!restoreStmt.Options.VerifyData {
 		var userDescriptorNames []string
 		userDescriptorNames, err := getUserDescriptorNames(ctx, txn, p.ExecCfg().Codec)
 		if err != nil {
@@ -1433,6 +1648,10 @@ func doRestorePlan(
 			return pgerror.Newf(pgcode.InsufficientPrivilege, "only the system tenant can restore other tenants")
 		}
 		for _, i := range tenants {
+			// Restores which only verify the backed up data don't create tenants.
+			if restoreStmt.Options.VerifyData {
+				continue
+			}
 			res, err := p.ExecCfg().InternalExecutor.QueryRow(
 				ctx, "restore-lookup-tenant", p.ExtendedEvalContext().Txn,
 				`SELECT active FROM system.tenants WHERE id = $1`, i.ID,
@@ -1467,22 +1686,48 @@ func doRestorePlan(
 	if err != nil {
 		return err
 	}
-	descriptorRewrites, err := allocateDescriptorRewrites(
-		ctx,
-		p,
-		databasesByID,
-		schemasByID,
-		filteredTablesByID,
-		typesByID,
-		restoreDBs,
-		restoreStmt.DescriptorCoverage,
-		restoreStmt.Options,
-		intoDB,
-	)
-	if err != nil {
-		return err
+	var rowSpans []roachpb.Span
+	if restoreStmt.Options.Where != nil {
+		if len(filteredTablesByID) != 1 {
+			return errors.Errorf(
+				"%q option can only be used when restoring a single table", restoreOptWhere)
+		}
+		for _, table := range filteredTablesByID {
+			rowSpans, err = restoreRowSpans(
+				ctx, &p.ExtendedEvalContext().EvalContext, p.ExecCfg().Codec, table, where,
+			)
+			if err != nil {
+				return err
+			}
+			stripTableForRowRestore(table)
+		}
 	}
-	description, err := restoreJobDescription(p, restoreStmt, from, restoreStmt.Options, intoDB, kms)
+	var descriptorRewrites DescRewriteMap
+	if restoreStmt.Options.VerifyData {
+		descriptorRewrites = makeVerifyDescriptorRewrites(
+			databasesByID, schemasByID, filteredTablesByID, typesByID,
+		)
+	} else {
+		descriptorRewrites, err = allocateDescriptorRewrites(
+			ctx,
+			p,
+			databasesByID,
+			schemasByID,
+			filteredTablesByID,
+			typesByID,
+			restoreDBs,
+			restoreStmt.DescriptorCoverage,
+			restoreStmt.Options,
+			intoDB,
+			newDBName,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	description, err := restoreJobDescription(
+		p, restoreStmt, from, restoreStmt.Options, intoDB, newDBName, where, kms,
+	)
 	if err != nil {
 		return err
 	}
@@ -1513,11 +1758,11 @@ func doRestorePlan(
 	canResetModTime := p.ExecCfg().Settings.Version.IsActive(
 		ctx, clusterversion.VersionLeasedDatabaseDescriptors)
 	if err := RewriteTableDescs(
-		tables, descriptorRewrites, intoDB, canResetModTime,
+		tables, descriptorRewrites, restoreOverrideDB(intoDB, newDBName), canResetModTime,
 	); err != nil {
 		return err
 	}
-	if err := rewriteDatabaseDescs(databases, descriptorRewrites); err != nil {
+	if err := rewriteDatabaseDescs(databases, descriptorRewrites, newDBName); err != nil {
 		return err
 	}
 	if err := rewriteSchemaDescs(schemas, descriptorRewrites); err != nil {
@@ -1533,6 +1778,12 @@ func doRestorePlan(
 		if restoreStmt.DescriptorCoverage == tree.AllDescriptors {
 			telemetry.Count("restore.full-cluster")
 		}
+		if restoreStmt.Options.VerifyData {
+			telemetry.Count("restore.verify-data")
+		}
+		if restoreStmt.Options.Where != nil {
+			telemetry.Count("restore.where")
+		}
 	}
 
 	encodedTables := make([]*descpb.TableDescriptor, len(tables))
@@ -1543,6 +1794,11 @@ func doRestorePlan(
 		Description: description,
 		Username:    p.User(),
 		DescriptorIDs: func() (sqlDescIDs []descpb.ID) {
+			// Restores which only verify the backed up data don't create any
+			// descriptors.
+			if restoreStmt.Options.VerifyData {
+				return nil
+			}
 			for _, tableRewrite := range descriptorRewrites {
 				sqlDescIDs = append(sqlDescIDs, tableRewrite.ID)
 			}
@@ -1556,8 +1812,11 @@ func doRestorePlan(
 			TableDescs:         encodedTables,
 			Tenants:            tenants,
 			OverrideDB:         intoDB,
+			NewDBName:          newDBName,
 			DescriptorCoverage: restoreStmt.DescriptorCoverage,
 			Encryption:         encryption,
+			VerifyData:         restoreStmt.Options.VerifyData,
+			RowSpans:           rowSpans,
 		},
 		Progress: jobspb.RestoreProgress{},
 	}
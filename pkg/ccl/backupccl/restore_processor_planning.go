@@ -34,7 +34,9 @@ import (
 // scattered them to the restore data processors - the second stage. The spans
 // should be routed to the node that is the leaseholder of that span. The
 // restore data processor will finally download and insert the data, and this is
-// reported back to the coordinator via the progCh.
+// reported back to the coordinator via the progCh. If validateOnly is set, the
+// spans are neither split nor scattered and their data is only read and
+// verified, not inserted.
 // This method also closes the given progCh.
 func distRestore(
 	ctx context.Context,
@@ -44,6 +46,7 @@ func distRestore(
 	encryption *jobspb.BackupEncryptionOptions,
 	rekeys []roachpb.ImportRequest_TableRekey,
 	restoreTime hlc.Timestamp,
+	validateOnly bool,
 	progCh chan *execinfrapb.RemoteProducerMetadata_BulkProcessorProgress,
 ) error {
 	ctx = logtags.AddTag(ctx, "restore-distsql", nil)
@@ -82,16 +85,17 @@ func distRestore(
 
 	nodes := getAllCompatibleNodes(planCtx)
 
-	splitAndScatterSpecs, err := makeSplitAndScatterSpecs(nodes, chunks, rekeys)
+	splitAndScatterSpecs, err := makeSplitAndScatterSpecs(nodes, chunks, rekeys, validateOnly)
 	if err != nil {
 		return err
 	}
 
 	restoreDataSpec := execinfrapb.RestoreDataSpec{
-		RestoreTime: restoreTime,
-		Encryption:  fileEncryption,
-		Rekeys:      rekeys,
-		PKIDs:       pkIDs,
+		RestoreTime:  restoreTime,
+		Encryption:   fileEncryption,
+		Rekeys:       rekeys,
+		PKIDs:        pkIDs,
+		ValidateOnly: validateOnly,
 	}
 
 	if len(splitAndScatterSpecs) == 0 {
@@ -248,6 +252,7 @@ func makeSplitAndScatterSpecs(
 	nodes []roachpb.NodeID,
 	chunks [][]execinfrapb.RestoreSpanEntry,
 	rekeys []roachpb.ImportRequest_TableRekey,
+	validateOnly bool,
 ) (map[roachpb.NodeID]*execinfrapb.SplitAndScatterSpec, error) {
 	specsByNodes := make(map[roachpb.NodeID]*execinfrapb.SplitAndScatterSpec)
 	for i, chunk := range chunks {
@@ -261,7 +266,8 @@ func makeSplitAndScatterSpecs(
 				Chunks: []execinfrapb.SplitAndScatterSpec_RestoreEntryChunk{{
 					Entries: chunk,
 				}},
-				Rekeys: rekeys,
+				Rekeys:       rekeys,
+				ValidateOnly: validateOnly,
 			}
 		}
 	}
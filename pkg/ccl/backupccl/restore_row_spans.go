@@ -0,0 +1,368 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/errors"
+)
+
+// maxRestoreRowSpanDisjuncts bounds the number of disjuncts a where predicate
+// may expand to, to protect against predicates whose disjunctive normal form
+// explodes.
+const maxRestoreRowSpanDisjuncts = 10000
+
+// pkConstraint is a single comparison between a primary key column and a
+// constant.
+type pkConstraint struct {
+	colIdx int
+	op     tree.ComparisonOperator
+	datum  tree.Datum
+}
+
+// pkInterval is the set of values a single primary key column is restricted
+// to. A nil bound is unbounded.
+type pkInterval struct {
+	lo, hi          tree.Datum
+	loIncl, hiIncl  bool
+	constrained, eq bool
+}
+
+// restoreRowSpans converts a predicate on the primary key columns of table
+// into the spans of the primary index which contain exactly the matching
+// rows. The spans are in the keyspace of the table as it appears in the
+// backup. Only predicates which can be expressed exactly as primary key spans
+// are supported: conjunctions and disjunctions of comparisons, IN lists and
+// BETWEEN against constants, where within each disjunct every constrained
+// column but the last is restricted to a single value by preceding equality
+// constraints.
+func restoreRowSpans(
+	ctx context.Context,
+	evalCtx *tree.EvalContext,
+	codec keys.SQLCodec,
+	table *tabledesc.Mutable,
+	predicate string,
+) ([]roachpb.Span, error) {
+	if !table.IsTable() {
+		return nil, errors.Errorf("%q option can only be used when restoring a table", restoreOptWhere)
+	}
+	if table.IsInterleaved() {
+		return nil, errors.Errorf(
+			"%q option cannot be used on interleaved table %q", restoreOptWhere, table.Name)
+	}
+	expr, err := parser.ParseExpr(predicate)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %q option", restoreOptWhere)
+	}
+
+	b := rowSpanBuilder{ctx: ctx, evalCtx: evalCtx, table: table}
+	disjuncts, err := b.toDNF(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	index := table.GetPrimaryIndex()
+	prefix := codec.IndexPrefix(uint32(table.ID), uint32(index.ID))
+	var spans []roachpb.Span
+	for _, conjunction := range disjuncts {
+		span, ok, err := b.spanForConjunction(prefix, conjunction)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			spans = append(spans, span)
+		}
+	}
+	if len(spans) == 0 {
+		return nil, errors.Errorf("%q predicate %q cannot match any rows", restoreOptWhere, predicate)
+	}
+	spans, _ = roachpb.MergeSpans(spans)
+	return spans, nil
+}
+
+type rowSpanBuilder struct {
+	ctx     context.Context
+	evalCtx *tree.EvalContext
+	table   *tabledesc.Mutable
+}
+
+// toDNF returns expr in disjunctive normal form, as a list of conjunctions of
+// primary key constraints.
+func (b *rowSpanBuilder) toDNF(expr tree.Expr) ([][]pkConstraint, error) {
+	switch t := expr.(type) {
+	case *tree.ParenExpr:
+		return b.toDNF(t.Expr)
+
+	case *tree.OrExpr:
+		left, err := b.toDNF(t.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := b.toDNF(t.Right)
+		if err != nil {
+			return nil, err
+		}
+		return b.checkDNFSize(append(left, right...))
+
+	case *tree.AndExpr:
+		left, err := b.toDNF(t.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := b.toDNF(t.Right)
+		if err != nil {
+			return nil, err
+		}
+		if len(left)*len(right) > maxRestoreRowSpanDisjuncts {
+			return nil, errors.Errorf("%q predicate is too complex", restoreOptWhere)
+		}
+		res := make([][]pkConstraint, 0, len(left)*len(right))
+		for _, l := range left {
+			for _, r := range right {
+				conj := make([]pkConstraint, 0, len(l)+len(r))
+				conj = append(append(conj, l...), r...)
+				res = append(res, conj)
+			}
+		}
+		return res, nil
+
+	case *tree.RangeCond:
+		if t.Not || t.Symmetric {
+			break
+		}
+		lo, err := b.constraint(t.Left, tree.GE, t.From)
+		if err != nil {
+			return nil, err
+		}
+		hi, err := b.constraint(t.Left, tree.LE, t.To)
+		if err != nil {
+			return nil, err
+		}
+		return [][]pkConstraint{{lo, hi}}, nil
+
+	case *tree.ComparisonExpr:
+		switch t.Operator {
+		case tree.EQ, tree.LT, tree.GT, tree.LE, tree.GE:
+			col, val, op := t.Left, t.Right, t.Operator
+			if _, ok := col.(*tree.UnresolvedName); !ok {
+				col, val, op = val, col, flipComparison(op)
+			}
+			c, err := b.constraint(col, op, val)
+			if err != nil {
+				return nil, err
+			}
+			return [][]pkConstraint{{c}}, nil
+
+		case tree.In:
+			tuple, ok := t.Right.(*tree.Tuple)
+			if !ok {
+				break
+			}
+			res := make([][]pkConstraint, 0, len(tuple.Exprs))
+			for _, e := range tuple.Exprs {
+				c, err := b.constraint(t.Left, tree.EQ, e)
+				if err != nil {
+					return nil, err
+				}
+				res = append(res, []pkConstraint{c})
+			}
+			return b.checkDNFSize(res)
+		}
+	}
+	return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+		"unsupported %q predicate %s: only comparisons of primary key columns with constants, "+
+			"combined with AND and OR, are supported", restoreOptWhere, tree.AsString(expr))
+}
+
+func (b *rowSpanBuilder) checkDNFSize(dnf [][]pkConstraint) ([][]pkConstraint, error) {
+	if len(dnf) > maxRestoreRowSpanDisjuncts {
+		return nil, errors.Errorf("%q predicate is too complex", restoreOptWhere)
+	}
+	return dnf, nil
+}
+
+// constraint resolves col to a primary key column and evaluates val as a
+// constant of that column's type.
+func (b *rowSpanBuilder) constraint(
+	col tree.Expr, op tree.ComparisonOperator, val tree.Expr,
+) (pkConstraint, error) {
+	name, ok := col.(*tree.UnresolvedName)
+	if !ok || name.Star || name.NumParts != 1 {
+		return pkConstraint{}, pgerror.Newf(pgcode.FeatureNotSupported,
+			"unsupported %q predicate: expected a primary key column, found %s",
+			restoreOptWhere, tree.AsString(col))
+	}
+	index := b.table.GetPrimaryIndex()
+	colIdx := -1
+	for i, colName := range index.ColumnNames {
+		if colName == name.Parts[0] {
+			colIdx = i
+			break
+		}
+	}
+	if colIdx < 0 {
+		return pkConstraint{}, pgerror.Newf(pgcode.FeatureNotSupported,
+			"%q predicate may only reference primary key columns, but column %q is not part of the "+
+				"primary key of %q", restoreOptWhere, name.Parts[0], b.table.Name)
+	}
+	colDesc, err := b.table.FindColumnByID(index.ColumnIDs[colIdx])
+	if err != nil {
+		return pkConstraint{}, err
+	}
+	if colDesc.Type.UserDefined() {
+		return pkConstraint{}, pgerror.Newf(pgcode.FeatureNotSupported,
+			"%q predicate cannot reference column %q of user-defined type %s",
+			restoreOptWhere, colDesc.Name, colDesc.Type.SQLString())
+	}
+	semaCtx := tree.MakeSemaContext()
+	typed, err := tree.TypeCheckAndRequire(b.ctx, val, &semaCtx, colDesc.Type, restoreOptWhere)
+	if err != nil {
+		return pkConstraint{}, err
+	}
+	datum, err := typed.Eval(b.evalCtx)
+	if err != nil {
+		return pkConstraint{}, err
+	}
+	return pkConstraint{colIdx: colIdx, op: op, datum: datum}, nil
+}
+
+// spanForConjunction returns the span of the primary index, below prefix,
+// containing exactly the rows matching all of the constraints. It returns
+// false if no row can match them.
+func (b *rowSpanBuilder) spanForConjunction(
+	prefix roachpb.Key, constraints []pkConstraint,
+) (roachpb.Span, bool, error) {
+	index := b.table.GetPrimaryIndex()
+	intervals := make([]pkInterval, len(index.ColumnIDs))
+	for _, c := range constraints {
+		// Primary key columns are never NULL, so comparing them to NULL never
+		// matches a row.
+		if c.datum == tree.DNull {
+			return roachpb.Span{}, false, nil
+		}
+		if !b.restrict(&intervals[c.colIdx], c) {
+			return roachpb.Span{}, false, nil
+		}
+	}
+
+	key := append(roachpb.Key(nil), prefix...)
+	for i := range intervals {
+		iv := &intervals[i]
+		dir, err := index.ColumnDirections[i].ToEncodingDirection()
+		if err != nil {
+			return roachpb.Span{}, false, err
+		}
+		if iv.eq {
+			if key, err = rowenc.EncodeTableKey(key, iv.lo, dir); err != nil {
+				return roachpb.Span{}, false, err
+			}
+			continue
+		}
+		for j := i + 1; j < len(intervals); j++ {
+			if intervals[j].constrained {
+				return roachpb.Span{}, false, pgerror.Newf(pgcode.FeatureNotSupported,
+					"%q predicate cannot be restored exactly: column %q is constrained but preceding "+
+						"primary key column %q is not restricted to a single value",
+					restoreOptWhere, index.ColumnNames[j], index.ColumnNames[i])
+			}
+		}
+		if !iv.constrained {
+			break
+		}
+		// In a descending column the upper bound of the values sorts first.
+		start, startIncl, end, endIncl := iv.lo, iv.loIncl, iv.hi, iv.hiIncl
+		if dir == encoding.Descending {
+			start, startIncl, end, endIncl = iv.hi, iv.hiIncl, iv.lo, iv.loIncl
+		}
+		span := roachpb.Span{Key: key, EndKey: key.PrefixEnd()}
+		if start != nil {
+			if span.Key, err = rowenc.EncodeTableKey(append(roachpb.Key(nil), key...), start, dir); err != nil {
+				return roachpb.Span{}, false, err
+			}
+			if !startIncl {
+				span.Key = span.Key.PrefixEnd()
+			}
+		}
+		if end != nil {
+			if span.EndKey, err = rowenc.EncodeTableKey(append(roachpb.Key(nil), key...), end, dir); err != nil {
+				return roachpb.Span{}, false, err
+			}
+			if endIncl {
+				span.EndKey = span.EndKey.PrefixEnd()
+			}
+		}
+		return span, true, nil
+	}
+	return roachpb.Span{Key: key, EndKey: key.PrefixEnd()}, true, nil
+}
+
+// restrict intersects iv with the constraint c, returning false if the result
+// is empty.
+func (b *rowSpanBuilder) restrict(iv *pkInterval, c pkConstraint) bool {
+	iv.constrained = true
+	if c.op == tree.EQ || c.op == tree.GT || c.op == tree.GE {
+		incl := c.op != tree.GT
+		if iv.lo == nil {
+			iv.lo, iv.loIncl = c.datum, incl
+		} else if cmp := c.datum.Compare(b.evalCtx, iv.lo); cmp > 0 || (cmp == 0 && !incl) {
+			iv.lo, iv.loIncl = c.datum, incl
+		}
+	}
+	if c.op == tree.EQ || c.op == tree.LT || c.op == tree.LE {
+		incl := c.op != tree.LT
+		if iv.hi == nil {
+			iv.hi, iv.hiIncl = c.datum, incl
+		} else if cmp := c.datum.Compare(b.evalCtx, iv.hi); cmp < 0 || (cmp == 0 && !incl) {
+			iv.hi, iv.hiIncl = c.datum, incl
+		}
+	}
+	if iv.lo != nil && iv.hi != nil {
+		cmp := iv.lo.Compare(b.evalCtx, iv.hi)
+		if cmp > 0 || (cmp == 0 && !(iv.loIncl && iv.hiIncl)) {
+			return false
+		}
+		iv.eq = cmp == 0
+	}
+	return true
+}
+
+func flipComparison(op tree.ComparisonOperator) tree.ComparisonOperator {
+	switch op {
+	case tree.LT:
+		return tree.GT
+	case tree.GT:
+		return tree.LT
+	case tree.LE:
+		return tree.GE
+	case tree.GE:
+		return tree.LE
+	default:
+		return op
+	}
+}
+
+// stripTableForRowRestore removes the parts of a table descriptor which are
+// not restored when only a subset of the rows of its primary index is
+// restored: its secondary indexes, whose data is not restored, and its
+// foreign keys, which the restored subset of rows may not satisfy.
+func stripTableForRowRestore(table *tabledesc.Mutable) {
+	table.Indexes = nil
+	table.OutboundFKs = nil
+	table.InboundFKs = nil
+}
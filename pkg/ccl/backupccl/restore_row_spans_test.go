@@ -0,0 +1,133 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreRowSpans(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	evalCtx := tree.MakeTestingEvalContext(cluster.MakeTestingClusterSettings())
+	defer evalCtx.Stop(ctx)
+
+	const tableID = 53
+	table, err := sql.CreateTestTableDescriptor(ctx, keys.MinNonPredefinedUserDescID, tableID,
+		`CREATE TABLE t (a INT, b INT, c STRING, PRIMARY KEY (a, b DESC))`,
+		descpb.NewDefaultPrivilegeDescriptor("root"))
+	require.NoError(t, err)
+
+	// key returns the key of the primary index prefix for the given values of a
+	// and, optionally, b.
+	key := func(vals ...int64) roachpb.Key {
+		k := keys.SystemSQLCodec.IndexPrefix(tableID, 1)
+		k = encoding.EncodeVarintAscending(k, vals[0])
+		if len(vals) > 1 {
+			k = encoding.EncodeVarintDescending(k, vals[1])
+		}
+		return k
+	}
+
+	for _, tc := range []struct {
+		predicate string
+		expected  []roachpb.Span
+		err       string
+	}{
+		{
+			predicate: `a = 1`,
+			expected:  []roachpb.Span{{Key: key(1), EndKey: key(1).PrefixEnd()}},
+		},
+		{
+			predicate: `a BETWEEN 1 AND 3`,
+			expected:  []roachpb.Span{{Key: key(1), EndKey: key(3).PrefixEnd()}},
+		},
+		{
+			predicate: `1 < a AND (a < 3)`,
+			expected:  []roachpb.Span{{Key: key(1).PrefixEnd(), EndKey: key(3)}},
+		},
+		{
+			predicate: `a IN (1, 2) OR a = 5`,
+			expected: []roachpb.Span{
+				{Key: key(1), EndKey: key(2).PrefixEnd()},
+				{Key: key(5), EndKey: key(5).PrefixEnd()},
+			},
+		},
+		{
+			predicate: `a = 1 AND b = 2`,
+			expected:  []roachpb.Span{{Key: key(1, 2), EndKey: key(1, 2).PrefixEnd()}},
+		},
+		{
+			// b is descending, so its lower bound is the end of the span.
+			predicate: `a = 1 AND b > 5`,
+			expected:  []roachpb.Span{{Key: key(1), EndKey: key(1, 5)}},
+		},
+		{
+			predicate: `a = 1 AND b <= 5 AND b > 2`,
+			expected:  []roachpb.Span{{Key: key(1, 5), EndKey: key(1, 2)}},
+		},
+		{
+			predicate: `a = 1 OR (a = 2 AND a = 3)`,
+			expected:  []roachpb.Span{{Key: key(1), EndKey: key(1).PrefixEnd()}},
+		},
+		{
+			predicate: `a > 2 AND a < 2`,
+			err:       `cannot match any rows`,
+		},
+		{
+			predicate: `c = 'foo'`,
+			err:       `column "c" is not part of the primary key`,
+		},
+		{
+			predicate: `b = 1`,
+			err:       `preceding primary key column "a" is not restricted to a single value`,
+		},
+		{
+			predicate: `a > 1 AND b = 1`,
+			err:       `preceding primary key column "a" is not restricted to a single value`,
+		},
+		{
+			predicate: `a + 1 = 2`,
+			err:       `expected a primary key column`,
+		},
+		{
+			predicate: `a = 1 OR c IS NULL`,
+			err:       `unsupported "where" predicate`,
+		},
+		{
+			predicate: `a = 'foo'`,
+			err:       `could not parse "foo" as type int`,
+		},
+	} {
+		t.Run(tc.predicate, func(t *testing.T) {
+			spans, err := restoreRowSpans(ctx, &evalCtx, keys.SystemSQLCodec, table, tc.predicate)
+			if tc.err != "" {
+				if !testutils.IsError(err, tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, spans)
+		})
+	}
+}
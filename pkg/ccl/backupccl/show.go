@@ -9,7 +9,9 @@
 package backupccl
 
 import (
+	"bytes"
 	"context"
+	"io/ioutil"
 	"net/url"
 	"path"
 	"strings"
@@ -29,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -68,10 +71,19 @@ func showBackupPlanHook(
 		}
 	}
 
+	var localityFn func() ([]string, error)
+	if len(backup.LocalityPaths) > 0 {
+		localityFn, err = p.TypeAsStringArray(ctx, backup.LocalityPaths, "SHOW BACKUP")
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+
 	expected := map[string]sql.KVStringOptValidate{
 		backupOptEncPassphrase:  sql.KVStringOptRequireValue,
 		backupOptEncKMS:         sql.KVStringOptRequireValue,
 		backupOptWithPrivileges: sql.KVStringOptRequireNoValue,
+		backupOptCheckFiles:     sql.KVStringOptRequireNoValue,
 	}
 	optsFn, err := p.TypeAsStringOpts(ctx, backup.Options, expected)
 	if err != nil {
@@ -115,36 +127,31 @@ func showBackupPlanHook(
 			str = parsed.String()
 		}
 
-		store, err := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI(ctx, str, p.User())
-		if err != nil {
-			return errors.Wrapf(err, "make storage")
-		}
-		defer store.Close()
-
-		var encryption *jobspb.BackupEncryptionOptions
-		if passphrase, ok := opts[backupOptEncPassphrase]; ok {
-			opts, err := readEncryptionOptions(ctx, store)
+		uris := []string{str}
+		if localityFn != nil {
+			localityURIs, err := localityFn()
 			if err != nil {
 				return err
 			}
-			encryptionKey := storageccl.GenerateKey([]byte(passphrase), opts.Salt)
-			encryption = &jobspb.BackupEncryptionOptions{Mode: jobspb.EncryptionMode_Passphrase,
-				Key: encryptionKey}
-		} else if kms, ok := opts[backupOptEncKMS]; ok {
-			opts, err := readEncryptionOptions(ctx, store)
+			uris = append(uris, localityURIs...)
+		}
+		stores := make([]cloud.ExternalStorage, len(uris))
+		for i := range uris {
+			stores[i], err = p.ExecCfg().DistSQLSrv.ExternalStorageFromURI(ctx, uris[i], p.User())
 			if err != nil {
-				return err
+				return errors.Wrapf(err, "make storage")
 			}
+			defer stores[i].Close()
+		}
+		store := stores[0]
 
-			env := &backupKMSEnv{p.ExecCfg().Settings, &p.ExecCfg().ExternalIODirConfig}
-			defaultKMSInfo, err := validateKMSURIsAgainstFullBackup([]string{kms},
-				newEncryptedDataKeyMapFromProtoMap(opts.EncryptedDataKeyByKMSMasterKeyID), env)
-			if err != nil {
-				return err
-			}
-			encryption = &jobspb.BackupEncryptionOptions{
-				Mode:    jobspb.EncryptionMode_KMS,
-				KMSInfo: defaultKMSInfo}
+		encryption, err := backupEncryptionFromOpts(ctx, p, store, opts)
+		if err != nil {
+			return err
+		}
+		encryption, err = resolveEncryptionKMS(ctx, p.ExecCfg(), p.User(), encryption)
+		if err != nil {
+			return err
 		}
 
 		incPaths, err := findPriorBackupNames(ctx, store)
@@ -183,7 +190,15 @@ func showBackupPlanHook(
 			return err
 		}
 
-		datums, err := shower.fn(manifests)
+		info := backupInfo{manifests: manifests}
+		if _, ok := opts[backupOptCheckFiles]; ok {
+			info.fileSizes, err = checkBackupFiles(ctx, p, stores, uris, incPaths, manifests, encryption)
+			if err != nil {
+				return err
+			}
+		}
+
+		datums, err := shower.fn(info)
 		if err != nil {
 			return err
 		}
@@ -200,9 +215,174 @@ func showBackupPlanHook(
 	return fn, shower.header, nil, false, nil
 }
 
+// backupInfo is what a backupShower is shown about a backup: the manifests of
+// the full backup and of each incremental backup on top of it and, if the
+// files were checked, the size of each file of each manifest.
+type backupInfo struct {
+	manifests []BackupManifest
+	fileSizes [][]int64
+}
+
+// backupEncryptionFromOpts returns the options to decrypt the backup in store
+// with, as specified by the encryption_passphrase or kms option, or nil if
+// neither is set. An external:// KMS URI is kept as is and has to be resolved
+// with resolveEncryptionKMS before the options can be used to read files.
+func backupEncryptionFromOpts(
+	ctx context.Context, p sql.PlanHookState, store cloud.ExternalStorage, opts map[string]string,
+) (*jobspb.BackupEncryptionOptions, error) {
+	if passphrase, ok := opts[backupOptEncPassphrase]; ok {
+		opts, err := readEncryptionOptions(ctx, store)
+		if err != nil {
+			return nil, err
+		}
+		encryptionKey := storageccl.GenerateKey([]byte(passphrase), opts.Salt)
+		return &jobspb.BackupEncryptionOptions{Mode: jobspb.EncryptionMode_Passphrase,
+			Key: encryptionKey}, nil
+	} else if kms, ok := opts[backupOptEncKMS]; ok {
+		opts, err := readEncryptionOptions(ctx, store)
+		if err != nil {
+			return nil, err
+		}
+
+		env := &backupKMSEnv{
+			settings: p.ExecCfg().Settings,
+			conf:     &p.ExecCfg().ExternalIODirConfig,
+			execCfg:  p.ExecCfg(),
+			user:     p.User(),
+		}
+		defaultKMSInfo, err := validateKMSURIsAgainstFullBackup(ctx, []string{kms},
+			newEncryptedDataKeyMapFromProtoMap(opts.EncryptedDataKeyByKMSMasterKeyID), env)
+		if err != nil {
+			return nil, err
+		}
+		return &jobspb.BackupEncryptionOptions{
+			Mode:    jobspb.EncryptionMode_KMS,
+			KMSInfo: defaultKMSInfo}, nil
+	}
+	return nil, nil
+}
+
 type backupShower struct {
 	header colinfo.ResultColumns
-	fn     func([]BackupManifest) ([]tree.Datums, error)
+	fn     func(backupInfo) ([]tree.Datums, error)
+}
+
+// checkBackupFiles checks that every data file referenced by the manifests of
+// a backup is present in its store with the size recorded in the manifest and,
+// if the manifest recorded a checksum for it, that its contents match that
+// checksum. It returns the size of each of them. The first manifest is that of
+// the full backup at the root of the stores, and the others are those of the
+// incremental backups in incPaths. The first store holds the default locality
+// of the backup; files of the other localities of a locality-aware backup are
+// looked for in the store of the URI that holds the matching partition
+// manifest.
+func checkBackupFiles(
+	ctx context.Context,
+	p sql.PlanHookState,
+	stores []cloud.ExternalStorage,
+	uris []string,
+	incPaths []string,
+	manifests []BackupManifest,
+	encryption *jobspb.BackupEncryptionOptions,
+) ([][]int64, error) {
+	storesByURI := make(map[string]cloud.ExternalStorage, len(uris))
+	for i := range uris {
+		storesByURI[uris[i]] = stores[i]
+	}
+
+	var fileEncryption *roachpb.FileEncryptionOptions
+	if encryption != nil {
+		key, err := getEncryptionKey(ctx, encryption, p.ExecCfg().Settings, p.ExecCfg().ExternalIODirConfig)
+		if err != nil {
+			return nil, err
+		}
+		fileEncryption = &roachpb.FileEncryptionOptions{Key: key}
+	}
+
+	fileSizes := make([][]int64, len(manifests))
+	for i, manifest := range manifests {
+		var dir string
+		if i > 0 {
+			dir = path.Dir(incPaths[i-1])
+		}
+		var urisByLocalityKV map[string]string
+		if len(manifest.PartitionDescriptorFilenames) > 0 {
+			localityInfo, err := getLocalityInfo(ctx, stores, uris, manifest, encryption, dir)
+			if err != nil {
+				return nil, errors.WithHint(err,
+					"the locations of all localities of a locality-aware backup must be given to check its files")
+			}
+			urisByLocalityKV = localityInfo.URIsByOriginalLocalityKV
+		}
+		fileSizes[i] = make([]int64, len(manifest.Files))
+		for j, f := range manifest.Files {
+			store := stores[0]
+			if f.LocalityKV != "" {
+				uri, ok := urisByLocalityKV[f.LocalityKV]
+				if !ok {
+					return nil, errors.Errorf(
+						"no location found for locality %s of file %s", f.LocalityKV, f.Path)
+				}
+				store = storesByURI[uri]
+			}
+			filePath := path.Join(dir, f.Path)
+			size, err := checkBackupFile(ctx, store, filePath, f, fileEncryption)
+			if err != nil {
+				return nil, err
+			}
+			fileSizes[i][j] = size
+		}
+	}
+	return fileSizes, nil
+}
+
+// checkBackupFile checks the file at filePath in store against the size and
+// checksum recorded for it in a manifest, and returns its size.
+func checkBackupFile(
+	ctx context.Context,
+	store cloud.ExternalStorage,
+	filePath string,
+	f BackupManifest_File,
+	encryption *roachpb.FileEncryptionOptions,
+) (int64, error) {
+	size, err := store.Size(ctx, filePath)
+	if err != nil {
+		return 0, errors.Wrapf(err, "checking file %s", filePath)
+	}
+	if size == 0 {
+		return 0, errors.Errorf("file %s is empty", filePath)
+	}
+	// Backups taken by earlier versions did not record the file size.
+	if f.FileSize != 0 && size != f.FileSize {
+		return 0, errors.Errorf("file %s has size %d, expected %d", filePath, size, f.FileSize)
+	}
+	if len(f.Sha512) == 0 {
+		return size, nil
+	}
+
+	r, err := store.ReadFile(ctx, filePath)
+	if err != nil {
+		return 0, errors.Wrapf(err, "reading file %s", filePath)
+	}
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, errors.Wrapf(err, "reading file %s", filePath)
+	}
+	if encryption != nil {
+		data, err = storageccl.DecryptFile(data, encryption.Key)
+		if err != nil {
+			return 0, errors.Wrapf(err, "decrypting file %s", filePath)
+		}
+	}
+	checksum, err := storageccl.SHA512ChecksumData(data)
+	if err != nil {
+		return 0, err
+	}
+	if !bytes.Equal(checksum, f.Sha512) {
+		return 0, errors.Errorf("checksum mismatch for file %s", filePath)
+	}
+	return size, nil
 }
 
 func backupShowerHeaders(showSchemas bool, opts map[string]string) colinfo.ResultColumns {
@@ -223,6 +403,9 @@ func backupShowerHeaders(showSchemas bool, opts map[string]string) colinfo.Resul
 	if _, shouldShowPrivleges := opts[backupOptWithPrivileges]; shouldShowPrivleges {
 		baseHeaders = append(baseHeaders, colinfo.ResultColumn{Name: "privileges", Typ: types.String})
 	}
+	if _, shouldCheckFiles := opts[backupOptCheckFiles]; shouldCheckFiles {
+		baseHeaders = append(baseHeaders, colinfo.ResultColumn{Name: "file_bytes", Typ: types.Int})
+	}
 	return baseHeaders
 }
 
@@ -231,9 +414,10 @@ func backupShowerDefault(
 ) backupShower {
 	return backupShower{
 		header: backupShowerHeaders(showSchemas, opts),
-		fn: func(manifests []BackupManifest) ([]tree.Datums, error) {
+		fn: func(info backupInfo) ([]tree.Datums, error) {
+			_, shouldCheckFiles := opts[backupOptCheckFiles]
 			var rows []tree.Datums
-			for _, manifest := range manifests {
+			for layer, manifest := range info.manifests {
 				// Map database ID to descriptor name.
 				dbIDToName := make(map[descpb.ID]string)
 				schemaIDToName := make(map[descpb.ID]string)
@@ -253,7 +437,8 @@ func backupShowerDefault(
 					}
 				}
 				descSizes := make(map[descpb.ID]RowCount)
-				for _, file := range manifest.Files {
+				fileSizes := make(map[descpb.ID]int64)
+				for i, file := range manifest.Files {
 					// TODO(dan): This assumes each file in the backup only contains
 					// data from a single table, which is usually but not always
 					// correct. It does not account for interleaved tables or if a
@@ -266,6 +451,9 @@ func backupShowerDefault(
 					s := descSizes[descpb.ID(tableID)]
 					s.add(file.EntryCounts)
 					descSizes[descpb.ID(tableID)] = s
+					if shouldCheckFiles {
+						fileSizes[descpb.ID(tableID)] += info.fileSizes[layer][i]
+					}
 				}
 				start := tree.DNull
 				end, err := tree.MakeDTimestamp(timeutil.Unix(0, manifest.EndTime.WallTime), time.Nanosecond)
@@ -289,6 +477,7 @@ func backupShowerDefault(
 					createStmtDatum := tree.DNull
 					dataSizeDatum := tree.DNull
 					rowCountDatum := tree.DNull
+					fileSizeDatum := tree.DNull
 
 					desc := catalogkv.UnwrapDescriptorRaw(ctx, descriptor)
 
@@ -310,6 +499,7 @@ func backupShowerDefault(
 						descSize := descSizes[desc.GetID()]
 						dataSizeDatum = tree.NewDInt(tree.DInt(descSize.DataSize))
 						rowCountDatum = tree.NewDInt(tree.DInt(descSize.Rows))
+						fileSizeDatum = tree.NewDInt(tree.DInt(fileSizes[desc.GetID()]))
 
 						displayOptions := sql.ShowCreateDisplayOptions{
 							FKDisplayMode:  sql.OmitMissingFKClausesFromCreate,
@@ -342,6 +532,9 @@ func backupShowerDefault(
 					if _, shouldShowPrivileges := opts[backupOptWithPrivileges]; shouldShowPrivileges {
 						row = append(row, tree.NewDString(showPrivileges(descriptor)))
 					}
+					if shouldCheckFiles {
+						row = append(row, fileSizeDatum)
+					}
 					rows = append(rows, row)
 				}
 				for _, t := range manifest.Tenants {
@@ -362,6 +555,9 @@ func backupShowerDefault(
 					if _, shouldShowPrivileges := opts[backupOptWithPrivileges]; shouldShowPrivileges {
 						row = append(row, tree.DNull)
 					}
+					if shouldCheckFiles {
+						row = append(row, tree.DNull)
+					}
 					rows = append(rows, row)
 				}
 			}
@@ -430,8 +626,8 @@ var backupShowerRanges = backupShower{
 		{Name: "end_key", Typ: types.Bytes},
 	},
 
-	fn: func(manifests []BackupManifest) (rows []tree.Datums, err error) {
-		for _, manifest := range manifests {
+	fn: func(info backupInfo) (rows []tree.Datums, err error) {
+		for _, manifest := range info.manifests {
 			for _, span := range manifest.Spans {
 				rows = append(rows, tree.Datums{
 					tree.NewDString(span.Key.String()),
@@ -456,8 +652,8 @@ var backupShowerFiles = backupShower{
 		{Name: "rows", Typ: types.Int},
 	},
 
-	fn: func(manifests []BackupManifest) (rows []tree.Datums, err error) {
-		for _, manifest := range manifests {
+	fn: func(info backupInfo) (rows []tree.Datums, err error) {
+		for _, manifest := range info.manifests {
 			for _, file := range manifest.Files {
 				rows = append(rows, tree.Datums{
 					tree.NewDString(file.Path),
@@ -10,6 +10,10 @@ package backupccl
 
 import (
 	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -21,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -451,3 +456,83 @@ func TestShowBackupTenants(t *testing.T) {
 		{"/Tenant/10", "/Tenant/11"},
 	}, res)
 }
+
+func TestShowBackupCheckFiles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 11
+	_, _, sqlDB, tempDir, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitNone)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `BACKUP data.bank TO $1`, LocalFoo)
+	sqlDB.Exec(t, `INSERT INTO data.bank VALUES (100, 100, 'new')`)
+	// Append an incremental backup to the full backup.
+	sqlDB.Exec(t, `BACKUP data.bank TO $1`, LocalFoo)
+
+	res := sqlDB.QueryStr(t,
+		`SELECT object_name, file_bytes > 0 FROM [SHOW BACKUP $1 WITH check_files] WHERE object_type = 'table'`,
+		LocalFoo)
+	require.Equal(t, [][]string{{"bank", "true"}, {"bank", "true"}}, res)
+
+	// Remove one of the data files of the full backup.
+	dataFiles, err := filepath.Glob(filepath.Join(tempDir, "foo", "*.sst"))
+	require.NoError(t, err)
+	require.NotEmpty(t, dataFiles)
+	require.NoError(t, os.Remove(dataFiles[0]))
+
+	// Without check_files only the manifest is read.
+	sqlDB.Exec(t, `SHOW BACKUP $1`, LocalFoo)
+	sqlDB.ExpectErr(t, `checking file`, `SHOW BACKUP $1 WITH check_files`, LocalFoo)
+}
+
+func TestShowBackupCheckFilesPartitioned(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 1000
+	ctx, _, sqlDB, tempDir, cleanupFn := BackupRestoreTestSetup(t, MultiNode, numAccounts, InitNone)
+	defer cleanupFn()
+
+	// Ensure that each node has at least one leaseholder, so that every
+	// locality of the backup gets some files.
+	for _, stmt := range []string{
+		`ALTER TABLE data.bank EXPERIMENTAL_RELOCATE VALUES (ARRAY[1], 0)`,
+		`ALTER TABLE data.bank EXPERIMENTAL_RELOCATE VALUES (ARRAY[2], 100)`,
+		`ALTER TABLE data.bank EXPERIMENTAL_RELOCATE VALUES (ARRAY[3], 200)`,
+	} {
+		testutils.SucceedsSoon(t, func() error {
+			_, err := sqlDB.DB.ExecContext(ctx, stmt)
+			return err
+		})
+	}
+
+	backupURIs := []string{
+		fmt.Sprintf("%s/1?COCKROACH_LOCALITY=%s", LocalFoo, url.QueryEscape("default")),
+		fmt.Sprintf("%s/2?COCKROACH_LOCALITY=%s", LocalFoo, url.QueryEscape("dc=dc1")),
+		fmt.Sprintf("%s/3?COCKROACH_LOCALITY=%s", LocalFoo, url.QueryEscape("dc=dc2")),
+	}
+	showURIs := []interface{}{LocalFoo + "/1", LocalFoo + "/2", LocalFoo + "/3"}
+	sqlDB.Exec(t, `BACKUP data.bank TO ($1, $2, $3)`, backupURIs[0], backupURIs[1], backupURIs[2])
+	sqlDB.Exec(t, `BACKUP data.bank TO ($1, $2, $3)`, backupURIs[0], backupURIs[1], backupURIs[2])
+
+	res := sqlDB.QueryStr(t,
+		`SELECT object_name, file_bytes > 0 FROM [SHOW BACKUP ($1, $2, $3) WITH check_files] WHERE object_type = 'table'`,
+		showURIs...)
+	require.Equal(t, [][]string{{"bank", "true"}, {"bank", "true"}}, res)
+
+	// The files of the other localities cannot be found from the default one.
+	sqlDB.ExpectErr(t, `not found in backup locations`,
+		`SHOW BACKUP $1 WITH check_files`, showURIs[0])
+
+	// Overwrite a data file of a non-default locality with as many zero bytes.
+	dataFiles, err := filepath.Glob(filepath.Join(tempDir, "foo", "2", "*.sst"))
+	require.NoError(t, err)
+	require.NotEmpty(t, dataFiles)
+	info, err := os.Stat(dataFiles[0])
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(dataFiles[0], make([]byte, info.Size()), 0644))
+
+	sqlDB.ExpectErr(t, `checksum mismatch`,
+		`SHOW BACKUP ($1, $2, $3) WITH check_files`, showURIs...)
+}
@@ -110,6 +110,20 @@ func (s dbSplitAndScatterer) findDestination(res *roachpb.AdminScatterResponse)
 	return roachpb.NodeID(0)
 }
 
+// noopSplitAndScatterer is the scatterer used when the restored data is only
+// verified. Nothing is written, so it neither splits nor scatters, and the
+// spans are verified on the node which planned them.
+type noopSplitAndScatterer struct {
+	nodeID roachpb.NodeID
+}
+
+// splitAndScatterKey implements the splitAndScatterer interface.
+func (s noopSplitAndScatterer) splitAndScatterKey(
+	_ context.Context, _ *kv.DB, _ *storageccl.KeyRewriter, _ roachpb.Key, _ bool,
+) (roachpb.NodeID, error) {
+	return s.nodeID, nil
+}
+
 var splitAndScatterOutputTypes = []*types.T{
 	types.Bytes, // Span key for the range router
 	types.Bytes, // RestoreDataEntry bytes
@@ -141,11 +155,18 @@ func newSplitAndScatterProcessor(
 	spec execinfrapb.SplitAndScatterSpec,
 	output execinfra.RowReceiver,
 ) (execinfra.Processor, error) {
+	var scatterer splitAndScatterer = dbSplitAndScatterer{}
+	if spec.ValidateOnly {
+		// If the node ID isn't available, 0 routes the spans to the default
+		// stream.
+		nodeID, _ := flowCtx.NodeID.OptionalNodeID()
+		scatterer = noopSplitAndScatterer{nodeID: nodeID}
+	}
 	ssp := &splitAndScatterProcessor{
 		flowCtx:   flowCtx,
 		spec:      spec,
 		output:    output,
-		scatterer: dbSplitAndScatterer{},
+		scatterer: scatterer,
 	}
 	return ssp, nil
 }
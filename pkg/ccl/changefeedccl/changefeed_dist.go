@@ -125,6 +125,12 @@ func distChangefeedFlow(
 		if err != nil {
 			return err
 		}
+		if isTransactionalKafkaSink(details.SinkURI) {
+			// The rows of each table are written by a transactional producer
+			// whose id is derived from the job and the table, so each table must
+			// be watched by a single ChangeAggregator.
+			spanPartitions = assignWholeSpans(trackedSpans, spanPartitions)
+		}
 	}
 
 	corePlacement := make([]physicalplan.ProcessorCorePlacement, len(spanPartitions))
@@ -144,6 +150,7 @@ func distChangefeedFlow(
 			Watches: watches,
 			Feed:    details,
 			User:    phs.User(),
+			JobID:   jobID,
 		}
 	}
 	// NB: This SpanFrontier processor depends on the set of tracked spans being
@@ -199,6 +206,31 @@ func distChangefeedFlow(
 	return resultRows.Err()
 }
 
+// assignWholeSpans assigns each of spans, without splitting it, to the node
+// which partitions assigned its start key to.
+func assignWholeSpans(spans []roachpb.Span, partitions []sql.SpanPartition) []sql.SpanPartition {
+	var assigned []sql.SpanPartition
+	nodeIdx := make(map[roachpb.NodeID]int)
+	for _, sp := range spans {
+		node := partitions[0].Node
+		for _, p := range partitions {
+			for _, pSpan := range p.Spans {
+				if pSpan.ContainsKey(sp.Key) {
+					node = p.Node
+				}
+			}
+		}
+		idx, ok := nodeIdx[node]
+		if !ok {
+			idx = len(assigned)
+			nodeIdx[node] = idx
+			assigned = append(assigned, sql.SpanPartition{Node: node})
+		}
+		assigned[idx].Spans = append(assigned[idx].Spans, sp)
+	}
+	return assigned
+}
+
 func fetchSpansForTargets(
 	ctx context.Context,
 	db *kv.DB,
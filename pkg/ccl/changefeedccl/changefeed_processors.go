@@ -53,6 +53,9 @@ type changeAggregator struct {
 	// kvFeedDoneCh is closed when the kvfeed exits.
 	kvFeedDoneCh chan struct{}
 	kvFeedMemMon *mon.BytesMonitor
+	// kafkaTxnMemMon accounts for the rows buffered by transactional Kafka
+	// producers.
+	kafkaTxnMemMon *mon.BytesMonitor
 
 	// encoder is the Encoder to use for key and value serialization.
 	encoder Encoder
@@ -162,6 +165,7 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 	if ca.sink, err = getSink(
 		ctx, ca.spec.Feed.SinkURI, nodeID, ca.spec.Feed.Opts, ca.spec.Feed.Targets,
 		ca.flowCtx.Cfg.Settings, timestampOracle, ca.flowCtx.Cfg.ExternalStorageFromURI, ca.spec.User,
+		ca.flowCtx.Cfg.Executor, ca.flowCtx.Cfg.DB,
 	); err != nil {
 		err = MarkRetryableError(err)
 		// Early abort in the case that there is an error creating the sink.
@@ -175,6 +179,23 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 	if b, ok := ca.sink.(*bufferSink); ok {
 		ca.changedRowBuf = &b.buf
 	}
+	if k, ok := ca.sink.(*kafkaSink); ok && k.cfg.transactional {
+		if ca.spec.JobID == 0 {
+			ca.MoveToDraining(errors.Errorf(
+				`%s requires a changefeed job`, changefeedbase.SinkParamTransactional))
+			ca.cancel()
+			return ctx
+		}
+		ca.kafkaTxnMemMon = mon.NewMonitorInheritWithLimit("kafkaTxn", math.MaxInt64, ca.ProcessorBase.MemMonitor)
+		ca.kafkaTxnMemMon.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(kafkaTxnBufferCapacity))
+		if err := k.startTransactions(
+			ctx, ca.flowCtx.Cfg.Codec, ca.spec.JobID, spans, sf, ca.kafkaTxnMemMon,
+		); err != nil {
+			ca.MoveToDraining(MarkRetryableError(err))
+			ca.cancel()
+			return ctx
+		}
+	}
 
 	// The job registry has a set of metrics used to monitor the various jobs it
 	// runs. They're all stored as the `metric.Struct` interface because of
@@ -339,6 +360,9 @@ func (ca *changeAggregator) close() {
 		if ca.kvFeedMemMon != nil {
 			ca.kvFeedMemMon.Stop(ca.Ctx)
 		}
+		if ca.kafkaTxnMemMon != nil {
+			ca.kafkaTxnMemMon.Stop(ca.Ctx)
+		}
 		ca.MemMonitor.Stop(ca.Ctx)
 	}
 }
@@ -550,6 +574,7 @@ func (cf *changeFrontier) Start(ctx context.Context) context.Context {
 	if cf.sink, err = getSink(
 		ctx, cf.spec.Feed.SinkURI, nodeID, cf.spec.Feed.Opts, cf.spec.Feed.Targets,
 		cf.flowCtx.Cfg.Settings, nilOracle, cf.flowCtx.Cfg.ExternalStorageFromURI, cf.spec.User,
+		cf.flowCtx.Cfg.Executor, cf.flowCtx.Cfg.DB,
 	); err != nil {
 		err = MarkRetryableError(err)
 		cf.MoveToDraining(err)
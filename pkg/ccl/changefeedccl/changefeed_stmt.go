@@ -229,7 +229,13 @@ func changefeedPlanHook(
 		// The only upside in all this nonsense is the tests are decent. I've tuned
 		// this particular order simply by rearranging stuff until the changefeedccl
 		// tests all pass.
-		parsedSink, err := url.Parse(sinkURI)
+		resolvedSinkURI, err := resolveSinkURI(
+			ctx, sinkURI, p.ExecCfg().InternalExecutor, p.ExecCfg().DB, p.User(),
+		)
+		if err != nil {
+			return err
+		}
+		parsedSink, err := url.Parse(resolvedSinkURI)
 		if err != nil {
 			return err
 		}
@@ -291,6 +297,7 @@ func changefeedPlanHook(
 			canarySink, err := getSink(
 				ctx, details.SinkURI, nodeID, details.Opts, details.Targets,
 				settings, nilOracle, p.ExecCfg().DistSQLSrv.ExternalStorageFromURI, p.User(),
+				p.ExecCfg().InternalExecutor, p.ExecCfg().DB,
 			)
 			if err != nil {
 				return MaybeStripRetryableErrorMarker(err)
@@ -486,8 +493,16 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 		switch v := changefeedbase.FormatType(details.Opts[opt]); v {
 		case ``, changefeedbase.OptFormatJSON:
 			details.Opts[opt] = string(changefeedbase.OptFormatJSON)
+			if isSQLTableSink(details.SinkURI) {
+				details.Opts[opt] = string(sqlTableSinkFormat)
+			}
 		case changefeedbase.OptFormatAvro:
 			// No-op.
+		case sqlTableSinkFormat:
+			if !isSQLTableSink(details.SinkURI) {
+				return jobspb.ChangefeedDetails{}, errors.Errorf(
+					`unknown %s: %s`, opt, v)
+			}
 		default:
 			return jobspb.ChangefeedDetails{}, errors.Errorf(
 				`unknown %s: %s`, opt, v)
@@ -521,6 +536,13 @@ func validateChangefeedTable(
 	if tableDesc.IsSequence() {
 		return errors.Errorf(`CHANGEFEED cannot target sequences: %s`, tableDesc.GetName())
 	}
+	// The row fetchers of changefeeds don't compute virtual columns.
+	for _, col := range tableDesc.GetPublicColumns() {
+		if col.IsVirtual() {
+			return errors.Errorf(`CHANGEFEED cannot target tables with virtual columns: %s has virtual column %s`,
+				tableDesc.GetName(), col.Name)
+		}
+	}
 	if families := tableDesc.GetFamilies(); len(families) != 1 {
 		return errors.Errorf(
 			`CHANGEFEEDs are currently supported on tables with exactly 1 column family: %s has %d`,
@@ -12,8 +12,11 @@ import (
 	"context"
 	gosql "database/sql"
 	"fmt"
+	"io/ioutil"
 	"math"
 	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
@@ -1884,6 +1887,11 @@ func TestChangefeedErrors(t *testing.T) {
 		t, `CHANGEFEED cannot target views: vw`,
 		`EXPERIMENTAL CHANGEFEED FOR vw`,
 	)
+	sqlDB.Exec(t, `CREATE TABLE virt (a INT PRIMARY KEY, b INT AS (a + 1) VIRTUAL)`)
+	sqlDB.ExpectErr(
+		t, `CHANGEFEED cannot target tables with virtual columns: virt has virtual column b`,
+		`EXPERIMENTAL CHANGEFEED FOR virt`,
+	)
 	// Backup has the same bad error message #28170.
 	sqlDB.ExpectErr(
 		t, `"information_schema.tables" does not exist`,
@@ -2087,6 +2095,87 @@ func TestChangefeedDescription(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+// TestChangefeedExternalConnection tests that a changefeed sink can be given
+// as an external://<name> URI, which is resolved through
+// system.external_connections after checking the USAGE privilege.
+func TestChangefeedExternalConnection(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	dir, dirCleanupFn := testutils.TempDir(t)
+	defer dirCleanupFn()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		UseDatabase:   "d",
+		ExternalIODir: dir,
+	})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = true`)
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+	sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'a')`)
+	sqlDB.Exec(t, `CREATE EXTERNAL CONNECTION feeds AS 'experimental-nodelocal://0/feeds'`)
+
+	sqlDB.ExpectErr(t, `external connection "missing" does not exist`,
+		`CREATE CHANGEFEED FOR foo INTO 'external://missing'`)
+
+	sqlDB.Exec(t, `CREATE USER testuser CONTROLCHANGEFEED`)
+	sqlDB.Exec(t, `GRANT SELECT ON DATABASE d TO testuser`)
+	sqlDB.Exec(t, `GRANT SELECT ON foo TO testuser`)
+	pgURL, cleanup := sqlutils.PGUrl(t, s.ServingSQLAddr(),
+		"TestChangefeedExternalConnection-testuser", url.User("testuser"))
+	defer cleanup()
+	testuserDB, err := gosql.Open("postgres", pgURL.String())
+	require.NoError(t, err)
+	defer testuserDB.Close()
+	testuser := sqlutils.MakeSQLRunner(testuserDB)
+
+	testuser.ExpectErr(t, `user testuser does not have USAGE privilege on external connection feeds`,
+		`CREATE CHANGEFEED FOR d.foo INTO 'external://feeds/testuser'`)
+	sqlDB.Exec(t, `GRANT USAGE ON EXTERNAL CONNECTION feeds TO testuser`)
+	var jobID int64
+	testuser.QueryRow(t,
+		`CREATE CHANGEFEED FOR d.foo INTO 'external://feeds/testuser'`).Scan(&jobID)
+	defer sqlDB.Exec(t, `CANCEL JOB $1`, jobID)
+
+	// The job keeps the name of the connection rather than the URI it stores.
+	var description string
+	sqlDB.QueryRow(t, `SELECT description FROM [SHOW JOBS] WHERE job_id = $1`, jobID).Scan(&description)
+	require.Contains(t, description, `external://feeds/testuser`)
+
+	// The rows are written under the path of the connection, and the key is
+	// in the value as it is for every cloud storage sink.
+	feedDir := filepath.Join(dir, "feeds", "testuser")
+	testutils.SucceedsSoon(t, func() error {
+		var contents []string
+		err := filepath.Walk(feedDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			contents = append(contents, string(b))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, c := range contents {
+			if strings.Contains(c, `"key": [1]`) {
+				return nil
+			}
+		}
+		return errors.Errorf("row not found in %s: %v", feedDir, contents)
+	})
+}
+
 func TestChangefeedPauseUnpause(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -77,15 +77,18 @@ const (
 	OptFormatAvro FormatType = `experimental_avro`
 
 	SinkParamCACert           = `ca_cert`
+	SinkParamCheckpointName   = `checkpoint_name`
 	SinkParamClientCert       = `client_cert`
 	SinkParamClientKey        = `client_key`
 	SinkParamFileSize         = `file_size`
 	SinkParamSchemaTopic      = `schema_topic`
 	SinkParamTLSEnabled       = `tls_enabled`
 	SinkParamTopicPrefix      = `topic_prefix`
+	SinkParamTransactional    = `transactional`
 	SinkSchemeBuffer          = ``
 	SinkSchemeExperimentalSQL = `experimental-sql`
 	SinkSchemeKafka           = `kafka`
+	SinkSchemeSQL             = `sql`
 	SinkParamSASLEnabled      = `sasl_enabled`
 	SinkParamSASLHandshake    = `sasl_handshake`
 	SinkParamSASLUser         = `sasl_user`
@@ -40,6 +40,13 @@ func ValidateTable(targets jobspb.ChangefeedTargets, tableDesc *tabledesc.Immuta
 	if tableDesc.IsSequence() {
 		return errors.Errorf(`CHANGEFEED cannot target sequences: %s`, tableDesc.Name)
 	}
+	// The row fetchers of changefeeds don't compute virtual columns.
+	for _, col := range tableDesc.Columns {
+		if col.IsVirtual() {
+			return errors.Errorf(`CHANGEFEED cannot target tables with virtual columns: %s has virtual column %s`,
+				tableDesc.Name, col.Name)
+		}
+	}
 	if len(tableDesc.Families) != 1 {
 		return errors.Errorf(
 			`CHANGEFEEDs are currently supported on tables with exactly 1 column family: %s has %d`,
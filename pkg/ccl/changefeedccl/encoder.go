@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -88,6 +89,13 @@ func getEncoder(opts map[string]string) (Encoder, error) {
 		return makeJSONEncoder(opts)
 	case changefeedbase.OptFormatAvro:
 		return newConfluentAvroEncoder(opts)
+	case sqlTableSinkFormat:
+		e, err := makeJSONEncoder(opts)
+		if err != nil {
+			return nil, err
+		}
+		e.sqlText = true
+		return e, nil
 	default:
 		return nil, errors.Errorf(`unknown %s: %s`, changefeedbase.OptFormat, opts[changefeedbase.OptFormat])
 	}
@@ -99,6 +107,9 @@ func getEncoder(opts map[string]string) (Encoder, error) {
 // stored in a sub-object under the `__crdb__` key in the top-level JSON object.
 type jsonEncoder struct {
 	updatedField, beforeField, wrapped, keyOnly, keyInValue bool
+	// sqlText, if set, encodes every column value as a JSON string holding its
+	// SQL text representation, or null for NULL. See sqlTableSinkFormat.
+	sqlText bool
 
 	alloc rowenc.DatumAlloc
 	buf   bytes.Buffer
@@ -154,7 +165,7 @@ func (e *jsonEncoder) encodeKeyRaw(row encodeRow) ([]interface{}, error) {
 			return nil, err
 		}
 		var err error
-		jsonEntries[i], err = tree.AsJSON(datum.Datum, time.UTC)
+		jsonEntries[i], err = e.encodeDatum(datum.Datum)
 		if err != nil {
 			return nil, err
 		}
@@ -162,6 +173,33 @@ func (e *jsonEncoder) encodeKeyRaw(row encodeRow) ([]interface{}, error) {
 	return jsonEntries, nil
 }
 
+func (e *jsonEncoder) encodeDatum(d tree.Datum) (interface{}, error) {
+	if e.sqlText {
+		return sqlText(d), nil
+	}
+	return tree.AsJSON(d, time.UTC)
+}
+
+// sqlText returns the text representation of d which a database converts back
+// into a value of the type of d, or nil if d is NULL. Unlike the JSON encoding
+// of d, this keeps arrays, JSON strings and JSON nulls apart from the values
+// of other types.
+func sqlText(d tree.Datum) interface{} {
+	if d == tree.DNull {
+		return nil
+	}
+	switch t := tree.UnwrapDatum(nil, d).(type) {
+	case *tree.DString:
+		return string(*t)
+	case *tree.DCollatedString:
+		return t.Contents
+	case *tree.DBytes:
+		return lex.EncodeByteArrayToRawBytes(string(*t), lex.BytesEncodeHex, false /* skipHexPrefix */)
+	default:
+		return tree.AsStringWithFlags(t, tree.FmtPgwireText)
+	}
+}
+
 // EncodeValue implements the Encoder interface.
 func (e *jsonEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, error) {
 	if e.keyOnly || (!e.wrapped && row.deleted) {
@@ -179,7 +217,7 @@ func (e *jsonEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, err
 				return nil, err
 			}
 			var err error
-			after[col.Name], err = tree.AsJSON(datum.Datum, time.UTC)
+			after[col.Name], err = e.encodeDatum(datum.Datum)
 			if err != nil {
 				return nil, err
 			}
@@ -197,7 +235,7 @@ func (e *jsonEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, err
 				return nil, err
 			}
 			var err error
-			before[col.Name], err = tree.AsJSON(datum.Datum, time.UTC)
+			before[col.Name], err = e.encodeDatum(datum.Datum)
 			if err != nil {
 				return nil, err
 			}
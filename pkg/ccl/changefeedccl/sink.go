@@ -27,25 +27,75 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/util/bufalloc"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/span"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/logtags"
 )
 
+// resolveSinkURI returns the URI stored in the external connection named by
+// an external://<name> sink URI, with the path and query parameters of
+// sinkURI added to it. The USAGE privilege of user on the connection is
+// checked. Other URIs are returned unchanged.
+//
+// Job details keep the external:// URI, so that the connection is looked up,
+// and the privilege checked, every time a sink is created for the changefeed.
+func resolveSinkURI(
+	ctx context.Context, sinkURI string, ie sqlutil.InternalExecutor, db *kv.DB, user string,
+) (string, error) {
+	u, err := url.Parse(sinkURI)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != sql.ExternalConnectionScheme {
+		return sinkURI, nil
+	}
+	sqlIE, ok := ie.(*sql.InternalExecutor)
+	if !ok || db == nil {
+		return "", errors.New("cannot resolve external connections without access to the cluster")
+	}
+	base, err := sql.ResolveExternalConnection(ctx, sqlIE, db, user, u.Host)
+	if err != nil {
+		return "", err
+	}
+	if base, err = sql.JoinExternalConnectionPath(base, u.Path); err != nil {
+		return "", err
+	}
+	resolved, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	if resolved.Scheme == sql.ExternalConnectionScheme {
+		return "", errors.New("an external connection cannot reference another external connection")
+	}
+	q := resolved.Query()
+	for k, v := range u.Query() {
+		q[k] = v
+	}
+	resolved.RawQuery = q.Encode()
+	return resolved.String(), nil
+}
+
 // Sink is an abstraction for anything that a changefeed may emit into.
 type Sink interface {
 	// EmitRow enqueues a row message for asynchronous delivery on the sink. An
@@ -74,7 +124,13 @@ func getSink(
 	timestampOracle timestampLowerBoundOracle,
 	makeExternalStorageFromURI cloud.ExternalStorageFromURIFactory,
 	user string,
+	ie sqlutil.InternalExecutor,
+	db *kv.DB,
 ) (Sink, error) {
+	sinkURI, err := resolveSinkURI(ctx, sinkURI, ie, db, user)
+	if err != nil {
+		return nil, err
+	}
 	u, err := url.Parse(sinkURI)
 	if err != nil {
 		return nil, err
@@ -102,6 +158,13 @@ func getSink(
 			}
 		}
 		q.Del(changefeedbase.SinkParamTLSEnabled)
+		if txnBool := q.Get(changefeedbase.SinkParamTransactional); txnBool != `` {
+			var err error
+			if cfg.transactional, err = strconv.ParseBool(txnBool); err != nil {
+				return nil, errors.Errorf(`param %s must be a bool: %s`, changefeedbase.SinkParamTransactional, err)
+			}
+		}
+		q.Del(changefeedbase.SinkParamTransactional)
 		if caCertHex := q.Get(changefeedbase.SinkParamCACert); caCertHex != `` {
 			// TODO(dan): There's a straightforward and unambiguous transformation
 			// between the base 64 encoding defined in RFC 4648 and the URL variant
@@ -207,6 +270,17 @@ func getSink(
 		q.Del(`sslkey`)
 		q.Del(`sslmode`)
 		q.Del(`sslrootcert`)
+	case u.Scheme == changefeedbase.SinkSchemeSQL:
+		checkpointName := q.Get(changefeedbase.SinkParamCheckpointName)
+		q.Del(changefeedbase.SinkParamCheckpointName)
+		// The remaining parameters are connection parameters, which are validated
+		// when connecting.
+		u.Scheme = `postgres`
+		u.RawQuery = q.Encode()
+		q = url.Values{}
+		makeSink = func() (Sink, error) {
+			return makeSQLTableSink(ctx, u.String(), checkpointName, targets, opts)
+		}
 	default:
 		return nil, errors.Errorf(`unsupported sink: %s`, u.Scheme)
 	}
@@ -296,6 +370,9 @@ type kafkaSinkConfig struct {
 	saslHandshake    bool
 	saslUser         string
 	saslPassword     string
+	// transactional makes the sink write rows in Kafka transactions, see
+	// kafkaTxnProducer.
+	transactional bool
 }
 
 // kafkaSink emits to Kafka asynchronously. It is not concurrency-safe; all
@@ -305,6 +382,12 @@ type kafkaSink struct {
 	client   sarama.Client
 	producer sarama.AsyncProducer
 	topics   map[string]struct{}
+	// txns, if set, write the rows of each table emitted to the sink. They are
+	// only set once startTransactions is called.
+	txns map[descpb.ID]*kafkaTxnProducer
+	// txnClient sends the requests of the transactional producers. It is only
+	// set before startTransactions is called in tests.
+	txnClient kafkaTxnClient
 
 	lastMetadataRefresh time.Time
 
@@ -334,6 +417,10 @@ func makeKafkaSink(
 	config.ClientID = `CockroachDB`
 	config.Producer.Return.Successes = true
 	config.Producer.Partitioner = newChangefeedPartitioner
+	if cfg.transactional {
+		// Transactions were introduced in Kafka 0.11.
+		config.Version = sarama.V0_11_0_0
+	}
 
 	if cfg.caCert != nil {
 		if !cfg.tlsEnabled {
@@ -428,6 +515,54 @@ func makeKafkaSink(
 	return sink, nil
 }
 
+// isTransactionalKafkaSink returns whether sinkURI is a Kafka sink writing rows
+// in transactions.
+func isTransactionalKafkaSink(sinkURI string) bool {
+	u, err := url.Parse(sinkURI)
+	if err != nil || u.Scheme != changefeedbase.SinkSchemeKafka {
+		return false
+	}
+	transactional, _ := strconv.ParseBool(u.Query().Get(changefeedbase.SinkParamTransactional))
+	return transactional
+}
+
+// startTransactions switches the sink to writing rows in Kafka transactions,
+// with one transactional producer for each table watched by the aggregator.
+// Each of the spans must cover a whole table. Rows are only written once the
+// frontier passes them; resolved timestamps are still written outside of
+// transactions.
+func (s *kafkaSink) startTransactions(
+	ctx context.Context,
+	codec keys.SQLCodec,
+	jobID int64,
+	spans []roachpb.Span,
+	frontier *span.Frontier,
+	memMon *mon.BytesMonitor,
+) error {
+	if s.txnClient == nil {
+		s.txnClient = &saramaTxnClient{client: s.client, coordinators: make(map[string]*sarama.Broker)}
+	}
+	s.txns = make(map[descpb.ID]*kafkaTxnProducer, len(spans))
+	for _, sp := range spans {
+		_, tableID, err := codec.DecodeTablePrefix(sp.Key)
+		if err != nil {
+			return err
+		}
+		if _, ok := s.txns[descpb.ID(tableID)]; ok {
+			return errors.AssertionFailedf(`table %d is watched by more than one span`, tableID)
+		}
+		txn, err := makeKafkaTxnProducer(
+			ctx, s.txnClient, kafkaTxnID(jobID, descpb.ID(tableID)), s.topics, sp, frontier,
+			memMon.MakeBoundAccount(),
+		)
+		if err != nil {
+			return err
+		}
+		s.txns[descpb.ID(tableID)] = txn
+	}
+	return nil
+}
+
 func (s *kafkaSink) start() {
 	s.stopWorkerCh = make(chan struct{})
 	s.worker.Add(1)
@@ -442,6 +577,12 @@ func (s *kafkaSink) Close() error {
 	// If we're shutting down, we don't care what happens to the outstanding
 	// messages, so ignore this error.
 	_ = s.producer.Close()
+	for _, txn := range s.txns {
+		txn.close(context.TODO())
+	}
+	if s.txnClient != nil {
+		_ = s.txnClient.Close()
+	}
 	// s.client is only nil in tests.
 	if s.client != nil {
 		return s.client.Close()
@@ -457,6 +598,13 @@ func (s *kafkaSink) EmitRow(
 	if _, ok := s.topics[topic]; !ok {
 		return errors.Errorf(`cannot emit to undeclared topic: %s`, topic)
 	}
+	if s.txns != nil {
+		txn, ok := s.txns[table.GetID()]
+		if !ok {
+			return errors.AssertionFailedf(`no transactional producer for table %s`, table.GetName())
+		}
+		return txn.emit(ctx, topic, key, value, updated)
+	}
 
 	msg := &sarama.ProducerMessage{
 		Topic: topic,
@@ -521,6 +669,12 @@ func (s *kafkaSink) EmitResolvedTimestamp(
 
 // Flush implements the Sink interface.
 func (s *kafkaSink) Flush(ctx context.Context) error {
+	for _, txn := range s.txns {
+		if err := txn.flush(ctx); err != nil {
+			return err
+		}
+	}
+
 	flushCh := make(chan struct{}, 1)
 
 	s.mu.Lock()
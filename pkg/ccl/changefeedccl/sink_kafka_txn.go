@@ -0,0 +1,724 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/span"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	// kafkaTxnTimeout is the transaction timeout requested from the
+	// transaction coordinator. Transactions which are not committed within it,
+	// for example because their producer died, are aborted by Kafka.
+	kafkaTxnTimeout = time.Minute
+	// kafkaTxnProduceTimeout is how long brokers may wait for replication
+	// before responding to a produce request.
+	kafkaTxnProduceTimeout = 10 * time.Second
+	// kafkaTxnMaxBatchBytes bounds the size of the record batches sent in one
+	// produce request, leaving headroom below the default broker limit of 1MB.
+	kafkaTxnMaxBatchBytes = 512 << 10
+	// kafkaTxnMessageOverhead is the memory accounted for each buffered row in
+	// addition to its key and value.
+	kafkaTxnMessageOverhead = 64
+)
+
+// kafkaTxnBufferCapacity bounds the memory used by the rows buffered by the
+// transactional producers of a changeAggregator.
+var kafkaTxnBufferCapacity = envutil.EnvOrDefaultBytes(
+	"COCKROACH_CHANGEFEED_KAFKA_TXN_BUFFER_CAPACITY", 64<<20) // 64MB
+
+// kafkaTxnRetryOpts is used to retry the requests of a transaction which fail
+// with a transient error, such as a partition leadership change.
+var kafkaTxnRetryOpts = retry.Options{
+	InitialBackoff: 50 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	MaxRetries:     10,
+}
+
+type kafkaTopicPartition struct {
+	topic     string
+	partition int32
+}
+
+type kafkaTxnMessage struct {
+	topic      string
+	key, value []byte
+	updated    hlc.Timestamp
+}
+
+// kafkaTxnID returns the transactional id used for the rows of a table. It only
+// depends on the job and the table, so that whichever aggregator watches the
+// table after a restart fences the producer of the previous incarnation.
+func kafkaTxnID(jobID int64, tableID descpb.ID) string {
+	return fmt.Sprintf(`crdb-changefeed-%d-%d`, jobID, tableID)
+}
+
+// kafkaTxnClient is the part of the Kafka protocol used by kafkaTxnProducer.
+// saramaTxnClient implements it with sarama; tests use a fake broker.
+type kafkaTxnClient interface {
+	Partitions(topic string) ([]int32, error)
+	InitProducerID(*sarama.InitProducerIDRequest) (*sarama.InitProducerIDResponse, error)
+	AddPartitionsToTxn(*sarama.AddPartitionsToTxnRequest) (*sarama.AddPartitionsToTxnResponse, error)
+	AddOffsetsToTxn(*sarama.AddOffsetsToTxnRequest) (*sarama.AddOffsetsToTxnResponse, error)
+	Produce(id string, tp kafkaTopicPartition, batch *sarama.RecordBatch) (*sarama.ProduceResponse, error)
+	TxnOffsetCommit(*sarama.TxnOffsetCommitRequest) (*sarama.TxnOffsetCommitResponse, error)
+	FetchOffset(*sarama.OffsetFetchRequest) (*sarama.OffsetFetchResponse, error)
+	EndTxn(*sarama.EndTxnRequest) (*sarama.EndTxnResponse, error)
+	// Refresh discards the cached partition leaders and the coordinators of
+	// the transactional id after a request failed.
+	Refresh(id string) error
+	Close() error
+}
+
+// saramaTxnClient sends the requests of kafkaTxnProducers to the brokers
+// leading the partitions and coordinating the transactional ids involved.
+type saramaTxnClient struct {
+	client sarama.Client
+	// coordinators caches the transaction coordinator of each transactional id.
+	coordinators map[string]*sarama.Broker
+}
+
+var _ kafkaTxnClient = (*saramaTxnClient)(nil)
+
+func (c *saramaTxnClient) txnCoordinator(id string) (*sarama.Broker, error) {
+	if b, ok := c.coordinators[id]; ok {
+		return b, nil
+	}
+	controller, err := c.client.Controller()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := controller.FindCoordinator(&sarama.FindCoordinatorRequest{
+		Version:         1,
+		CoordinatorKey:  id,
+		CoordinatorType: sarama.CoordinatorTransaction,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, `finding transaction coordinator`)
+	}
+	if resp.Err != sarama.ErrNoError {
+		return nil, errors.Wrap(resp.Err, `finding transaction coordinator`)
+	}
+	b := resp.Coordinator
+	if err := b.Open(c.client.Config()); err != nil && err != sarama.ErrAlreadyConnected {
+		return nil, err
+	}
+	c.coordinators[id] = b
+	return b, nil
+}
+
+func (c *saramaTxnClient) Partitions(topic string) ([]int32, error) {
+	return c.client.Partitions(topic)
+}
+
+func (c *saramaTxnClient) InitProducerID(
+	req *sarama.InitProducerIDRequest,
+) (*sarama.InitProducerIDResponse, error) {
+	b, err := c.txnCoordinator(*req.TransactionalID)
+	if err != nil {
+		return nil, err
+	}
+	return b.InitProducerID(req)
+}
+
+func (c *saramaTxnClient) AddPartitionsToTxn(
+	req *sarama.AddPartitionsToTxnRequest,
+) (*sarama.AddPartitionsToTxnResponse, error) {
+	b, err := c.txnCoordinator(req.TransactionalID)
+	if err != nil {
+		return nil, err
+	}
+	return b.AddPartitionsToTxn(req)
+}
+
+func (c *saramaTxnClient) AddOffsetsToTxn(
+	req *sarama.AddOffsetsToTxnRequest,
+) (*sarama.AddOffsetsToTxnResponse, error) {
+	b, err := c.txnCoordinator(req.TransactionalID)
+	if err != nil {
+		return nil, err
+	}
+	return b.AddOffsetsToTxn(req)
+}
+
+func (c *saramaTxnClient) Produce(
+	id string, tp kafkaTopicPartition, batch *sarama.RecordBatch,
+) (*sarama.ProduceResponse, error) {
+	b, err := c.client.Leader(tp.topic, tp.partition)
+	if err != nil {
+		return nil, err
+	}
+	req := &sarama.ProduceRequest{
+		TransactionalID: &id,
+		RequiredAcks:    sarama.WaitForAll,
+		Timeout:         int32(kafkaTxnProduceTimeout / time.Millisecond),
+		Version:         3,
+	}
+	req.AddBatch(tp.topic, tp.partition, batch)
+	return b.Produce(req)
+}
+
+func (c *saramaTxnClient) TxnOffsetCommit(
+	req *sarama.TxnOffsetCommitRequest,
+) (*sarama.TxnOffsetCommitResponse, error) {
+	b, err := c.client.Coordinator(req.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	return b.TxnOffsetCommit(req)
+}
+
+func (c *saramaTxnClient) FetchOffset(
+	req *sarama.OffsetFetchRequest,
+) (*sarama.OffsetFetchResponse, error) {
+	b, err := c.client.Coordinator(req.ConsumerGroup)
+	if err != nil {
+		return nil, err
+	}
+	return b.FetchOffset(req)
+}
+
+func (c *saramaTxnClient) EndTxn(req *sarama.EndTxnRequest) (*sarama.EndTxnResponse, error) {
+	b, err := c.txnCoordinator(req.TransactionalID)
+	if err != nil {
+		return nil, err
+	}
+	return b.EndTxn(req)
+}
+
+func (c *saramaTxnClient) Refresh(id string) error {
+	if b, ok := c.coordinators[id]; ok {
+		_ = b.Close()
+		delete(c.coordinators, id)
+	}
+	if err := c.client.RefreshCoordinator(id); err != nil {
+		return err
+	}
+	return c.client.RefreshMetadata()
+}
+
+func (c *saramaTxnClient) Close() error {
+	for id, b := range c.coordinators {
+		_ = b.Close()
+		delete(c.coordinators, id)
+	}
+	return nil
+}
+
+// kafkaTxnProducer writes the rows of one table to Kafka in transactions, so
+// that consumers reading with read_committed isolation see each row exactly
+// once.
+//
+// Rows are buffered until the sink is flushed, which the aggregator does right
+// before it forwards its resolved spans to the changeFrontier to be
+// checkpointed in the job progress. Each flush writes every buffered row at or
+// below the aggregator's frontier for the table in a single transaction which
+// also records that frontier, as the offset metadata of a consumer group named
+// after the transactional id. Transactions are thus committed at the job
+// checkpoint boundaries, and the job checkpoint never passes an uncommitted
+// row. Since all rows at or below the frontier have been emitted by then, a
+// committed frontier means that exactly the rows of the table at or below it
+// have been committed. When the changefeed restarts, the rows at or below the
+// recorded frontier are skipped, and initializing the producer fences any
+// zombie producer of the previous incarnation and aborts its open transaction.
+//
+// The transactional id only depends on the job and the table, and each table is
+// watched by a single aggregator (see assignWholeSpans), so the recorded
+// frontier remains valid however the tables are assigned to aggregators after
+// a restart.
+type kafkaTxnProducer struct {
+	client kafkaTxnClient
+	id     string
+	// markerTopic is the topic on whose first partition the committed frontier
+	// is recorded.
+	markerTopic string
+	// span is the span of the table whose rows are written by the producer.
+	span         roachpb.Span
+	frontier     *span.Frontier
+	partitioners map[string]sarama.Partitioner
+	acc          mon.BoundAccount
+
+	// initialized is false until a producer id is obtained, and again after a
+	// transaction fails. Initializing the producer aborts any open transaction.
+	initialized bool
+	producerID  int64
+	epoch       int16
+	sequences   map[kafkaTopicPartition]int32
+
+	// committed is the frontier recorded by the last committed transaction.
+	committed hlc.Timestamp
+	buf       []kafkaTxnMessage
+	// err is set once the producer is fenced by a newer incarnation, after which
+	// it can no longer be used.
+	err error
+}
+
+func makeKafkaTxnProducer(
+	ctx context.Context,
+	client kafkaTxnClient,
+	id string,
+	topics map[string]struct{},
+	tableSpan roachpb.Span,
+	frontier *span.Frontier,
+	acc mon.BoundAccount,
+) (*kafkaTxnProducer, error) {
+	p := &kafkaTxnProducer{
+		client:       client,
+		id:           id,
+		span:         tableSpan,
+		frontier:     frontier,
+		partitioners: make(map[string]sarama.Partitioner),
+		acc:          acc,
+	}
+	sortedTopics := make([]string, 0, len(topics))
+	for topic := range topics {
+		sortedTopics = append(sortedTopics, topic)
+		p.partitioners[topic] = newChangefeedPartitioner(topic)
+	}
+	sort.Strings(sortedTopics)
+	p.markerTopic = sortedTopics[0]
+
+	if err := p.init(ctx); err != nil {
+		p.close(ctx)
+		return nil, err
+	}
+	return p, nil
+}
+
+// init obtains a new producer epoch, which fences any previous producer with
+// the same transactional id, and reads the frontier recorded by the last
+// committed transaction.
+func (p *kafkaTxnProducer) init(ctx context.Context) error {
+	if err := p.initProducerID(ctx); err != nil {
+		return err
+	}
+	if err := p.readCommitted(ctx); err != nil {
+		return err
+	}
+	p.sequences = make(map[kafkaTopicPartition]int32)
+	p.initialized = true
+
+	// Drop the buffered rows which were committed by a transaction whose
+	// outcome was not known when it failed.
+	var released int64
+	remaining := p.buf[:0]
+	for _, m := range p.buf {
+		if m.updated.LessEq(p.committed) {
+			released += kafkaTxnMessageSize(m)
+		} else {
+			remaining = append(remaining, m)
+		}
+	}
+	p.buf = remaining
+	p.acc.Shrink(ctx, released)
+	return nil
+}
+
+func (p *kafkaTxnProducer) initProducerID(ctx context.Context) error {
+	// The coordinator responds with CONCURRENT_TRANSACTIONS while it aborts an
+	// open transaction of a previous producer with the same transactional id.
+	var resp *sarama.InitProducerIDResponse
+	if err := p.retry(ctx, `initializing transactional producer`, func() error {
+		var err error
+		resp, err = p.client.InitProducerID(&sarama.InitProducerIDRequest{
+			TransactionalID:    &p.id,
+			TransactionTimeout: kafkaTxnTimeout,
+		})
+		if err != nil {
+			return err
+		}
+		return kafkaErr(resp.Err)
+	}); err != nil {
+		return err
+	}
+	p.producerID, p.epoch = resp.ProducerID, resp.ProducerEpoch
+	log.Infof(ctx, `initialized transactional producer %s with producer id %d epoch %d`,
+		p.id, p.producerID, p.epoch)
+	return nil
+}
+
+func (p *kafkaTxnProducer) readCommitted(ctx context.Context) error {
+	var block *sarama.OffsetFetchResponseBlock
+	if err := p.retry(ctx, `reading committed frontier`, func() error {
+		req := &sarama.OffsetFetchRequest{Version: 1, ConsumerGroup: p.id}
+		req.AddPartition(p.markerTopic, 0)
+		resp, err := p.client.FetchOffset(req)
+		if err != nil {
+			return err
+		}
+		if block = resp.GetBlock(p.markerTopic, 0); block == nil {
+			return nil
+		}
+		return kafkaErr(block.Err)
+	}); err != nil {
+		return err
+	}
+	if block == nil || block.Offset < 0 || block.Metadata == `` {
+		return nil
+	}
+	idx := strings.LastIndexByte(block.Metadata, '@')
+	if idx < 0 || block.Metadata[:idx] != p.id {
+		return errors.Errorf(`malformed committed frontier %q`, block.Metadata)
+	}
+	committed, err := hlc.ParseTimestamp(block.Metadata[idx+1:])
+	if err != nil {
+		return err
+	}
+	if p.committed.Less(committed) {
+		p.committed = committed
+		log.Infof(ctx, `transactional producer %s resuming after committed frontier %s`,
+			p.id, p.committed)
+	}
+	return nil
+}
+
+func (p *kafkaTxnProducer) emit(
+	ctx context.Context, topic string, key, value []byte, updated hlc.Timestamp,
+) error {
+	if p.err != nil {
+		return p.err
+	}
+	if updated.LessEq(p.committed) {
+		// This row was committed before the changefeed restarted.
+		return nil
+	}
+	m := kafkaTxnMessage{
+		topic:   topic,
+		key:     append([]byte(nil), key...),
+		value:   append([]byte(nil), value...),
+		updated: updated,
+	}
+	if err := p.acc.Grow(ctx, kafkaTxnMessageSize(m)); err != nil {
+		// Commit the rows at or below the frontier without waiting for the next
+		// flush to make room for the row.
+		if err := p.commit(ctx, p.tableFrontier()); err != nil {
+			return err
+		}
+		if err := p.acc.Grow(ctx, kafkaTxnMessageSize(m)); err != nil {
+			return errors.Wrapf(err, `buffering rows of transactional producer %s`, p.id)
+		}
+	}
+	p.buf = append(p.buf, m)
+	return nil
+}
+
+// flush commits every buffered row at or below the current frontier.
+func (p *kafkaTxnProducer) flush(ctx context.Context) error {
+	if p.err != nil {
+		return p.err
+	}
+	return p.commit(ctx, p.tableFrontier())
+}
+
+// tableFrontier returns the frontier of the aggregator for the producer's
+// table.
+func (p *kafkaTxnProducer) tableFrontier() hlc.Timestamp {
+	var frontier hlc.Timestamp
+	found := false
+	p.frontier.Entries(func(sp roachpb.Span, ts hlc.Timestamp) {
+		if sp.Overlaps(p.span) && (!found || ts.Less(frontier)) {
+			frontier, found = ts, true
+		}
+	})
+	return frontier
+}
+
+// commit writes every buffered row at or below frontier in a transaction.
+func (p *kafkaTxnProducer) commit(ctx context.Context, frontier hlc.Timestamp) error {
+	if frontier.LessEq(p.committed) {
+		return nil
+	}
+	if !p.initialized {
+		if err := p.init(ctx); err != nil {
+			return errors.Wrapf(err, `transactional producer %s`, p.id)
+		}
+		if frontier.LessEq(p.committed) {
+			return nil
+		}
+	}
+
+	var msgs, remaining []kafkaTxnMessage
+	var released int64
+	for _, m := range p.buf {
+		if m.updated.LessEq(frontier) {
+			msgs = append(msgs, m)
+			released += kafkaTxnMessageSize(m)
+		} else {
+			remaining = append(remaining, m)
+		}
+	}
+	if err := p.commitTxn(ctx, msgs, frontier); err != nil {
+		if errors.Is(err, sarama.ErrInvalidProducerEpoch) {
+			// A newer incarnation of the producer has taken over.
+			p.err = errors.Wrapf(err, `transactional producer %s was fenced`, p.id)
+			return p.err
+		}
+		// Initializing the producer again aborts the transaction, unless it was
+		// committed after all, in which case the rows it contained are dropped
+		// from the buffer.
+		p.initialized = false
+		return errors.Wrapf(err, `transactional producer %s`, p.id)
+	}
+	p.buf = remaining
+	p.acc.Shrink(ctx, released)
+	p.committed = frontier
+	return nil
+}
+
+func (p *kafkaTxnProducer) commitTxn(
+	ctx context.Context, msgs []kafkaTxnMessage, frontier hlc.Timestamp,
+) error {
+	// Assign the messages to partitions the same way as the non-transactional
+	// sink does, preserving their order within each partition.
+	byPartition := make(map[kafkaTopicPartition][]kafkaTxnMessage)
+	var partitions []kafkaTopicPartition
+	for _, m := range msgs {
+		numPartitions, err := p.client.Partitions(m.topic)
+		if err != nil {
+			return err
+		}
+		partition, err := p.partitioners[m.topic].Partition(&sarama.ProducerMessage{
+			Topic: m.topic, Key: sarama.ByteEncoder(m.key),
+		}, int32(len(numPartitions)))
+		if err != nil {
+			return err
+		}
+		tp := kafkaTopicPartition{topic: m.topic, partition: partition}
+		if _, ok := byPartition[tp]; !ok {
+			partitions = append(partitions, tp)
+		}
+		byPartition[tp] = append(byPartition[tp], m)
+	}
+
+	if len(partitions) > 0 {
+		addReq := &sarama.AddPartitionsToTxnRequest{
+			TransactionalID: p.id,
+			ProducerID:      p.producerID,
+			ProducerEpoch:   p.epoch,
+			TopicPartitions: make(map[string][]int32),
+		}
+		for _, tp := range partitions {
+			addReq.TopicPartitions[tp.topic] = append(addReq.TopicPartitions[tp.topic], tp.partition)
+		}
+		if err := p.retry(ctx, `adding partitions to transaction`, func() error {
+			resp, err := p.client.AddPartitionsToTxn(addReq)
+			if err != nil {
+				return err
+			}
+			return kafkaPartitionErr(resp.Errors)
+		}); err != nil {
+			return err
+		}
+	}
+	if err := p.retry(ctx, `adding offsets to transaction`, func() error {
+		resp, err := p.client.AddOffsetsToTxn(&sarama.AddOffsetsToTxnRequest{
+			TransactionalID: p.id,
+			ProducerID:      p.producerID,
+			ProducerEpoch:   p.epoch,
+			GroupID:         p.id,
+		})
+		if err != nil {
+			return err
+		}
+		return kafkaErr(resp.Err)
+	}); err != nil {
+		return err
+	}
+
+	for _, tp := range partitions {
+		if err := p.produce(ctx, tp, byPartition[tp]); err != nil {
+			return err
+		}
+	}
+
+	// Record the frontier as part of the transaction.
+	marker := p.id + `@` + frontier.String()
+	if err := p.retry(ctx, `recording frontier in transaction`, func() error {
+		resp, err := p.client.TxnOffsetCommit(&sarama.TxnOffsetCommitRequest{
+			TransactionalID: p.id,
+			GroupID:         p.id,
+			ProducerID:      p.producerID,
+			ProducerEpoch:   p.epoch,
+			Topics: map[string][]*sarama.PartitionOffsetMetadata{
+				p.markerTopic: {{Partition: 0, Offset: 0, Metadata: &marker}},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		return kafkaPartitionErr(resp.Topics)
+	}); err != nil {
+		return err
+	}
+
+	if err := p.retry(ctx, `committing transaction`, func() error {
+		resp, err := p.client.EndTxn(&sarama.EndTxnRequest{
+			TransactionalID:   p.id,
+			ProducerID:        p.producerID,
+			ProducerEpoch:     p.epoch,
+			TransactionResult: true,
+		})
+		if err != nil {
+			return err
+		}
+		return kafkaErr(resp.Err)
+	}); err != nil {
+		return err
+	}
+	if log.V(1) {
+		log.Infof(ctx, `transactional producer %s committed %d messages up to %s`,
+			p.id, len(msgs), frontier)
+	}
+	return nil
+}
+
+// produce writes msgs to a partition in one or more record batches.
+func (p *kafkaTxnProducer) produce(
+	ctx context.Context, tp kafkaTopicPartition, msgs []kafkaTxnMessage,
+) error {
+	for len(msgs) > 0 {
+		now := timeutil.Now()
+		batch := &sarama.RecordBatch{
+			Version:         2,
+			FirstTimestamp:  now,
+			MaxTimestamp:    now,
+			ProducerID:      p.producerID,
+			ProducerEpoch:   p.epoch,
+			FirstSequence:   p.sequences[tp],
+			IsTransactional: true,
+		}
+		size := 0
+		for len(msgs) > 0 && (len(batch.Records) == 0 || size < kafkaTxnMaxBatchBytes) {
+			m := msgs[0]
+			msgs = msgs[1:]
+			batch.Records = append(batch.Records, &sarama.Record{
+				OffsetDelta: int64(len(batch.Records)),
+				Key:         m.key,
+				Value:       m.value,
+			})
+			size += len(m.key) + len(m.value)
+		}
+		batch.LastOffsetDelta = int32(len(batch.Records) - 1)
+
+		// Retrying the batch with the same sequence number is idempotent: the
+		// leader rejects a batch it has already written as a duplicate.
+		opName := fmt.Sprintf(`producing to %s partition %d`, tp.topic, tp.partition)
+		if err := p.retry(ctx, opName, func() error {
+			resp, err := p.client.Produce(p.id, tp, batch)
+			if err != nil {
+				return err
+			}
+			block := resp.GetBlock(tp.topic, tp.partition)
+			if block == nil {
+				return errors.New(`no response`)
+			}
+			if block.Err == sarama.ErrDuplicateSequenceNumber {
+				return nil
+			}
+			return kafkaErr(block.Err)
+		}); err != nil {
+			return err
+		}
+		p.sequences[tp] += int32(len(batch.Records))
+	}
+	return nil
+}
+
+// retry runs fn until it succeeds or fails with an error which is not
+// transient, refreshing the cached partition leaders and coordinators between
+// attempts.
+func (p *kafkaTxnProducer) retry(ctx context.Context, opName string, fn func() error) error {
+	var err error
+	for r := retry.StartWithCtx(ctx, kafkaTxnRetryOpts); r.Next(); {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if !isRetryableKafkaTxnError(err) {
+			break
+		}
+		log.VEventf(ctx, 1, `transactional producer %s retrying %s: %v`, p.id, opName, err)
+		if refreshErr := p.client.Refresh(p.id); refreshErr != nil {
+			log.VEventf(ctx, 1, `refreshing metadata: %v`, refreshErr)
+		}
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	return errors.Wrap(err, opName)
+}
+
+func (p *kafkaTxnProducer) close(ctx context.Context) {
+	p.buf = nil
+	p.acc.Close(ctx)
+}
+
+// isRetryableKafkaTxnError returns whether a request of a transaction which
+// failed with err may succeed if it is retried.
+func isRetryableKafkaTxnError(err error) bool {
+	var kErr sarama.KError
+	if !errors.As(err, &kErr) {
+		// Errors sending the request, such as a broken connection to the broker.
+		return true
+	}
+	switch kErr {
+	case sarama.ErrNotLeaderForPartition,
+		sarama.ErrLeaderNotAvailable,
+		sarama.ErrUnknownTopicOrPartition,
+		sarama.ErrRequestTimedOut,
+		sarama.ErrNetworkException,
+		sarama.ErrNotEnoughReplicas,
+		sarama.ErrNotEnoughReplicasAfterAppend,
+		sarama.ErrOffsetsLoadInProgress,
+		sarama.ErrConsumerCoordinatorNotAvailable,
+		sarama.ErrNotCoordinatorForConsumer,
+		sarama.ErrConcurrentTransactions:
+		return true
+	}
+	return false
+}
+
+// kafkaErr returns nil for ErrNoError and kErr otherwise.
+func kafkaErr(kErr sarama.KError) error {
+	if kErr == sarama.ErrNoError {
+		return nil
+	}
+	return kErr
+}
+
+// kafkaPartitionErr returns the first error of the partitions in a response.
+func kafkaPartitionErr(errs map[string][]*sarama.PartitionError) error {
+	for _, pErrs := range errs {
+		for _, pErr := range pErrs {
+			if pErr.Err != sarama.ErrNoError {
+				return pErr.Err
+			}
+		}
+	}
+	return nil
+}
+
+func kafkaTxnMessageSize(m kafkaTxnMessage) int64 {
+	return int64(len(m.topic) + len(m.key) + len(m.value) + kafkaTxnMessageOverhead)
+}
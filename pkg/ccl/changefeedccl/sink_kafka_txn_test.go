@@ -0,0 +1,421 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/span"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKafkaTxnBroker is a single broker Kafka cluster implementing the parts of
+// the transactional protocol relied on by kafkaTxnProducer: the writes and
+// offset commits of a transaction only become visible when it is committed,
+// and initializing a producer fences the previous epoch of its transactional
+// id and aborts its open transaction.
+type fakeKafkaTxnBroker struct {
+	partitions     int32
+	nextProducerID int64
+	txns           map[string]*fakeKafkaTxn
+	// committed contains the values of each partition which are visible to
+	// read_committed consumers.
+	committed map[kafkaTopicPartition][]string
+	// offsets contains the committed offset metadata of each group.
+	offsets map[string]fakeKafkaOffset
+
+	// produceErrs are returned by the next produce requests.
+	produceErrs []sarama.KError
+	// endTxnErrs are returned by the next requests ending a transaction.
+	endTxnErrs []sarama.KError
+	// loseEndTxnResponse makes the next request committing a transaction of
+	// the transactional id fail after it was committed.
+	loseEndTxnResponse map[string]bool
+	refreshes          int
+}
+
+type fakeKafkaTxn struct {
+	producerID int64
+	epoch      int16
+	open       bool
+	sequences  map[kafkaTopicPartition]int32
+	writes     map[kafkaTopicPartition][]string
+	offset     *fakeKafkaOffset
+}
+
+type fakeKafkaOffset struct {
+	topic    string
+	metadata string
+}
+
+var _ kafkaTxnClient = (*fakeKafkaTxnBroker)(nil)
+
+func newFakeKafkaTxnBroker(partitions int32) *fakeKafkaTxnBroker {
+	return &fakeKafkaTxnBroker{
+		partitions:         partitions,
+		txns:               make(map[string]*fakeKafkaTxn),
+		committed:          make(map[kafkaTopicPartition][]string),
+		offsets:            make(map[string]fakeKafkaOffset),
+		loseEndTxnResponse: make(map[string]bool),
+	}
+}
+
+// committedValues returns the committed values of a topic, sorted.
+func (b *fakeKafkaTxnBroker) committedValues(topic string) []string {
+	var values []string
+	for tp, vs := range b.committed {
+		if tp.topic == topic {
+			values = append(values, vs...)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+// txn returns the open transaction of a producer, or an error if the producer
+// was fenced.
+func (b *fakeKafkaTxnBroker) txn(id string, producerID int64, epoch int16) (*fakeKafkaTxn, error) {
+	txn, ok := b.txns[id]
+	if !ok || txn.producerID != producerID {
+		return nil, sarama.ErrInvalidProducerIDMapping
+	}
+	if epoch != txn.epoch {
+		return nil, sarama.ErrInvalidProducerEpoch
+	}
+	txn.open = true
+	return txn, nil
+}
+
+func (b *fakeKafkaTxnBroker) Partitions(string) ([]int32, error) {
+	partitions := make([]int32, b.partitions)
+	for i := range partitions {
+		partitions[i] = int32(i)
+	}
+	return partitions, nil
+}
+
+func (b *fakeKafkaTxnBroker) InitProducerID(
+	req *sarama.InitProducerIDRequest,
+) (*sarama.InitProducerIDResponse, error) {
+	txn, ok := b.txns[*req.TransactionalID]
+	if !ok {
+		b.nextProducerID++
+		txn = &fakeKafkaTxn{producerID: b.nextProducerID}
+		b.txns[*req.TransactionalID] = txn
+	} else {
+		txn.epoch++
+	}
+	// Abort the open transaction of the previous epoch, if any.
+	txn.open = false
+	txn.sequences = make(map[kafkaTopicPartition]int32)
+	txn.writes = make(map[kafkaTopicPartition][]string)
+	txn.offset = nil
+	return &sarama.InitProducerIDResponse{ProducerID: txn.producerID, ProducerEpoch: txn.epoch}, nil
+}
+
+func (b *fakeKafkaTxnBroker) AddPartitionsToTxn(
+	req *sarama.AddPartitionsToTxnRequest,
+) (*sarama.AddPartitionsToTxnResponse, error) {
+	resp := &sarama.AddPartitionsToTxnResponse{Errors: make(map[string][]*sarama.PartitionError)}
+	_, err := b.txn(req.TransactionalID, req.ProducerID, req.ProducerEpoch)
+	for topic, partitions := range req.TopicPartitions {
+		for _, partition := range partitions {
+			pErr := &sarama.PartitionError{Partition: partition, Err: sarama.ErrNoError}
+			if err != nil {
+				pErr.Err = err.(sarama.KError)
+			}
+			resp.Errors[topic] = append(resp.Errors[topic], pErr)
+		}
+	}
+	return resp, nil
+}
+
+func (b *fakeKafkaTxnBroker) AddOffsetsToTxn(
+	req *sarama.AddOffsetsToTxnRequest,
+) (*sarama.AddOffsetsToTxnResponse, error) {
+	resp := &sarama.AddOffsetsToTxnResponse{Err: sarama.ErrNoError}
+	if _, err := b.txn(req.TransactionalID, req.ProducerID, req.ProducerEpoch); err != nil {
+		resp.Err = err.(sarama.KError)
+	}
+	return resp, nil
+}
+
+func (b *fakeKafkaTxnBroker) Produce(
+	id string, tp kafkaTopicPartition, batch *sarama.RecordBatch,
+) (*sarama.ProduceResponse, error) {
+	resp := &sarama.ProduceResponse{}
+	if len(b.produceErrs) > 0 {
+		resp.AddTopicPartition(tp.topic, tp.partition, b.produceErrs[0])
+		b.produceErrs = b.produceErrs[1:]
+		return resp, nil
+	}
+	txn, err := b.txn(id, batch.ProducerID, batch.ProducerEpoch)
+	if err != nil {
+		resp.AddTopicPartition(tp.topic, tp.partition, err.(sarama.KError))
+		return resp, nil
+	}
+	switch seq := txn.sequences[tp]; {
+	case batch.FirstSequence < seq:
+		resp.AddTopicPartition(tp.topic, tp.partition, sarama.ErrDuplicateSequenceNumber)
+		return resp, nil
+	case batch.FirstSequence > seq:
+		resp.AddTopicPartition(tp.topic, tp.partition, sarama.ErrOutOfOrderSequenceNumber)
+		return resp, nil
+	}
+	for _, r := range batch.Records {
+		txn.writes[tp] = append(txn.writes[tp], string(r.Value))
+	}
+	txn.sequences[tp] += int32(len(batch.Records))
+	resp.AddTopicPartition(tp.topic, tp.partition, sarama.ErrNoError)
+	return resp, nil
+}
+
+func (b *fakeKafkaTxnBroker) TxnOffsetCommit(
+	req *sarama.TxnOffsetCommitRequest,
+) (*sarama.TxnOffsetCommitResponse, error) {
+	resp := &sarama.TxnOffsetCommitResponse{Topics: make(map[string][]*sarama.PartitionError)}
+	txn, err := b.txn(req.TransactionalID, req.ProducerID, req.ProducerEpoch)
+	for topic, partitions := range req.Topics {
+		for _, p := range partitions {
+			pErr := &sarama.PartitionError{Partition: p.Partition, Err: sarama.ErrNoError}
+			if err != nil {
+				pErr.Err = err.(sarama.KError)
+			} else {
+				txn.offset = &fakeKafkaOffset{topic: topic, metadata: *p.Metadata}
+			}
+			resp.Topics[topic] = append(resp.Topics[topic], pErr)
+		}
+	}
+	return resp, nil
+}
+
+func (b *fakeKafkaTxnBroker) FetchOffset(
+	req *sarama.OffsetFetchRequest,
+) (*sarama.OffsetFetchResponse, error) {
+	resp := &sarama.OffsetFetchResponse{}
+	if offset, ok := b.offsets[req.ConsumerGroup]; ok {
+		resp.AddBlock(offset.topic, 0, &sarama.OffsetFetchResponseBlock{
+			Metadata: offset.metadata, Err: sarama.ErrNoError,
+		})
+	}
+	return resp, nil
+}
+
+func (b *fakeKafkaTxnBroker) EndTxn(req *sarama.EndTxnRequest) (*sarama.EndTxnResponse, error) {
+	resp := &sarama.EndTxnResponse{Err: sarama.ErrNoError}
+	if len(b.endTxnErrs) > 0 {
+		resp.Err = b.endTxnErrs[0]
+		b.endTxnErrs = b.endTxnErrs[1:]
+		return resp, nil
+	}
+	txn, ok := b.txns[req.TransactionalID]
+	if ok && txn.producerID == req.ProducerID && txn.epoch == req.ProducerEpoch && !txn.open {
+		// The transaction was already ended.
+		resp.Err = sarama.ErrInvalidTxnState
+		return resp, nil
+	}
+	txn, err := b.txn(req.TransactionalID, req.ProducerID, req.ProducerEpoch)
+	if err != nil {
+		resp.Err = err.(sarama.KError)
+		return resp, nil
+	}
+	if req.TransactionResult {
+		for tp, values := range txn.writes {
+			b.committed[tp] = append(b.committed[tp], values...)
+		}
+		if txn.offset != nil {
+			b.offsets[req.TransactionalID] = *txn.offset
+		}
+	}
+	txn.open = false
+	txn.writes = make(map[kafkaTopicPartition][]string)
+	txn.offset = nil
+	if b.loseEndTxnResponse[req.TransactionalID] {
+		delete(b.loseEndTxnResponse, req.TransactionalID)
+		return nil, errors.New(`connection reset by peer`)
+	}
+	return resp, nil
+}
+
+func (b *fakeKafkaTxnBroker) Refresh(string) error {
+	b.refreshes++
+	return nil
+}
+
+func (b *fakeKafkaTxnBroker) Close() error {
+	return nil
+}
+
+func TestKafkaSinkTransactional(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	defer func(opts retry.Options) { kafkaTxnRetryOpts = opts }(kafkaTxnRetryOpts)
+	kafkaTxnRetryOpts.InitialBackoff = time.Millisecond
+	kafkaTxnRetryOpts.MaxBackoff = time.Millisecond
+	kafkaTxnRetryOpts.MaxRetries = 3
+
+	ctx := context.Background()
+	ts := func(wall int64) hlc.Timestamp { return hlc.Timestamp{WallTime: wall} }
+	codec := keys.SystemSQLCodec
+	tableSpan := func(id uint32) roachpb.Span {
+		return roachpb.Span{Key: codec.TablePrefix(id), EndKey: codec.TablePrefix(id + 1)}
+	}
+	foo := tabledesc.NewImmutable(descpb.TableDescriptor{ID: 52, Name: `foo`})
+	bar := tabledesc.NewImmutable(descpb.TableDescriptor{ID: 53, Name: `bar`})
+	fooSpan, barSpan := tableSpan(52), tableSpan(53)
+
+	settings := cluster.MakeTestingClusterSettings()
+	makeMonitor := func(capacity int64) *mon.BytesMonitor {
+		m := mon.NewMonitor("test", mon.MemoryResource, nil, nil, 1 /* increment */, math.MaxInt64, settings)
+		m.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(capacity))
+		return m
+	}
+	memMon := makeMonitor(math.MaxInt64)
+	defer memMon.Stop(ctx)
+
+	broker := newFakeKafkaTxnBroker(2 /* partitions */)
+	// startAggregator returns the sink of an aggregator of the job watching
+	// spans, which starts from the job checkpoint.
+	startAggregator := func(
+		memMon *mon.BytesMonitor, checkpoint hlc.Timestamp, spans ...roachpb.Span,
+	) (*kafkaSink, *span.Frontier) {
+		sf := span.MakeFrontier(spans...)
+		for _, sp := range spans {
+			sf.Forward(sp, checkpoint)
+		}
+		sink := &kafkaSink{
+			producer: asyncProducerMock{
+				inputCh:     make(chan *sarama.ProducerMessage),
+				successesCh: make(chan *sarama.ProducerMessage),
+				errorsCh:    make(chan *sarama.ProducerError),
+			},
+			topics:    map[string]struct{}{`foo`: {}, `bar`: {}},
+			txnClient: broker,
+		}
+		sink.start()
+		require.NoError(t, sink.startTransactions(ctx, codec, 1 /* jobID */, spans, sf, memMon))
+		return sink, sf
+	}
+	emit := func(sink *kafkaSink, table *tabledesc.Immutable, value string, updated int64) error {
+		return sink.EmitRow(ctx, table, []byte(value), []byte(value), ts(updated))
+	}
+	committed := func() [][]string {
+		return [][]string{broker.committedValues(`foo`), broker.committedValues(`bar`)}
+	}
+
+	// Rows are committed once the frontier of their table passes them.
+	agg1, sf1 := startAggregator(memMon, ts(0), fooSpan, barSpan)
+	require.NoError(t, emit(agg1, foo, `foo1`, 1))
+	require.NoError(t, emit(agg1, bar, `bar1`, 1))
+	require.NoError(t, emit(agg1, foo, `foo3`, 3))
+	require.Equal(t, [][]string{nil, nil}, committed())
+	sf1.Forward(fooSpan, ts(2))
+	sf1.Forward(barSpan, ts(2))
+	require.NoError(t, agg1.Flush(ctx))
+	require.Equal(t, [][]string{{`foo1`}, {`bar1`}}, committed())
+
+	// Transient errors are retried.
+	broker.produceErrs = []sarama.KError{sarama.ErrNotLeaderForPartition, sarama.ErrRequestTimedOut}
+	sf1.Forward(fooSpan, ts(4))
+	require.NoError(t, agg1.Flush(ctx))
+	require.Equal(t, [][]string{{`foo1`, `foo3`}, {`bar1`}}, committed())
+	require.Equal(t, 2, broker.refreshes)
+
+	// After any other error, the transaction is aborted and retried by the next
+	// flush.
+	require.NoError(t, emit(agg1, foo, `foo5`, 5))
+	sf1.Forward(fooSpan, ts(6))
+	broker.endTxnErrs = []sarama.KError{sarama.ErrInvalidTxnState}
+	require.Error(t, agg1.Flush(ctx))
+	require.Equal(t, [][]string{{`foo1`, `foo3`}, {`bar1`}}, committed())
+	require.NoError(t, agg1.Flush(ctx))
+	require.Equal(t, [][]string{{`foo1`, `foo3`, `foo5`}, {`bar1`}}, committed())
+
+	// A transaction whose outcome is unknown is not committed twice.
+	require.NoError(t, emit(agg1, bar, `bar7`, 7))
+	sf1.Forward(barSpan, ts(8))
+	broker.loseEndTxnResponse[kafkaTxnID(1, 53)] = true
+	require.Error(t, agg1.Flush(ctx))
+	require.Equal(t, [][]string{{`foo1`, `foo3`, `foo5`}, {`bar1`, `bar7`}}, committed())
+	require.NoError(t, agg1.Flush(ctx))
+	require.Equal(t, [][]string{{`foo1`, `foo3`, `foo5`}, {`bar1`, `bar7`}}, committed())
+
+	// The changefeed restarts from an earlier job checkpoint, with the tables
+	// assigned to different aggregators. Rows which were committed before the
+	// restart are skipped, and the producers of the previous aggregator are
+	// fenced.
+	require.NoError(t, emit(agg1, foo, `foo9`, 9))
+	require.NoError(t, emit(agg1, bar, `bar9`, 9))
+	sf1.Forward(fooSpan, ts(10))
+	sf1.Forward(barSpan, ts(10))
+	agg2, sf2 := startAggregator(memMon, ts(2), barSpan)
+	defer func() { require.NoError(t, agg2.Close()) }()
+	agg3, sf3 := startAggregator(memMon, ts(2), fooSpan)
+	defer func() { require.NoError(t, agg3.Close()) }()
+	for tableID, txn := range agg1.txns {
+		require.Regexp(t, `transactional producer `+kafkaTxnID(1, tableID)+` was fenced`, txn.flush(ctx))
+	}
+	require.Regexp(t, `was fenced`, emit(agg1, foo, `foo11`, 11))
+	require.NoError(t, agg1.Close())
+	require.Equal(t, [][]string{{`foo1`, `foo3`, `foo5`}, {`bar1`, `bar7`}}, committed())
+
+	for _, row := range []struct {
+		table   *tabledesc.Immutable
+		value   string
+		updated int64
+	}{
+		{foo, `foo3`, 3}, {foo, `foo5`, 5}, {bar, `bar7`, 7}, {foo, `foo9`, 9}, {bar, `bar9`, 9},
+	} {
+		agg := agg3
+		if row.table == bar {
+			agg = agg2
+		}
+		require.NoError(t, emit(agg, row.table, row.value, row.updated))
+	}
+	sf2.Forward(barSpan, ts(10))
+	sf3.Forward(fooSpan, ts(10))
+	require.NoError(t, agg2.Flush(ctx))
+	require.NoError(t, agg3.Flush(ctx))
+	require.Equal(t, [][]string{
+		{`foo1`, `foo3`, `foo5`, `foo9`},
+		{`bar1`, `bar7`, `bar9`},
+	}, committed())
+
+	// The memory used by buffered rows is bounded. Rows which the frontier has
+	// passed are committed early to make room for new ones, beyond which
+	// emitting fails.
+	smallMon := makeMonitor(300)
+	defer smallMon.Stop(ctx)
+	agg4, sf4 := startAggregator(smallMon, ts(10), fooSpan)
+	defer func() { require.NoError(t, agg4.Close()) }()
+	big := strings.Repeat(`x`, 100)
+	require.NoError(t, emit(agg4, foo, `foo11`+big, 11))
+	sf4.Forward(fooSpan, ts(11))
+	require.NoError(t, emit(agg4, foo, `foo12`+big, 12))
+	require.Equal(t, []string{`foo1`, `foo11` + big, `foo3`, `foo5`, `foo9`}, committed()[0])
+	require.Regexp(t, `memory budget exceeded`, emit(agg4, foo, `foo13`+big, 13))
+}
@@ -0,0 +1,431 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	gosql "database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach-go/crdb"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	// sqlTableSinkCheckpointTable stores, at the destination, the highest
+	// resolved timestamp emitted to it by each changefeed.
+	sqlTableSinkCheckpointTable           = `crdb_changefeed_checkpoints`
+	sqlTableSinkCreateCheckpointTableStmt = `CREATE TABLE IF NOT EXISTS ` +
+		sqlTableSinkCheckpointTable + ` (
+		name TEXT PRIMARY KEY,
+		wall_time BIGINT NOT NULL,
+		logical INT NOT NULL
+	)`
+	sqlTableSinkReadCheckpointStmt = `SELECT wall_time, logical FROM ` +
+		sqlTableSinkCheckpointTable + ` WHERE name = $1`
+	// Checkpoints only ever move forward, so that a resolved timestamp which is
+	// re-emitted after a restart cannot regress the checkpoint.
+	sqlTableSinkWriteCheckpointStmt = `INSERT INTO ` + sqlTableSinkCheckpointTable + `
+		AS c (name, wall_time, logical) VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET wall_time = excluded.wall_time, logical = excluded.logical
+		WHERE (c.wall_time, c.logical) < (excluded.wall_time, excluded.logical)`
+	sqlTableSinkColumnsStmt = `SELECT column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1`
+
+	// sqlTableSinkFlushRows is the number of buffered rows after which the sink
+	// flushes on its own.
+	sqlTableSinkFlushRows = 1000
+	// sqlTableSinkStmtRows bounds the number of rows written by one statement,
+	// which keeps the number of placeholders well below the protocol limit.
+	sqlTableSinkStmtRows = 100
+)
+
+// sqlTableSinkFormat is the format used in place of json by changefeeds into
+// a sql sink. It is the wrapped JSON envelope, but with each value encoded as
+// the text of the SQL value, which the destination converts back to the type
+// of its column. The JSON encoding of a value loses its type: for example a
+// NULL and a JSON null are both encoded as null.
+const sqlTableSinkFormat changefeedbase.FormatType = `sql_text`
+
+// isSQLTableSink returns whether sinkURI refers to a sql sink.
+func isSQLTableSink(sinkURI string) bool {
+	u, err := url.Parse(sinkURI)
+	return err == nil && u.Scheme == changefeedbase.SinkSchemeSQL
+}
+
+// sqlTableSink applies changefeed events to tables of the same names in
+// another CockroachDB or Postgres database, replicating the watched tables
+// one-way. Rows are upserted and deleted by primary key and each flush is
+// applied in a single transaction.
+//
+// Whenever a resolved timestamp is emitted, it is recorded at the destination
+// in the same transaction as the rows buffered up to it. Rows at or below the
+// recorded checkpoint are skipped by sinks created later, for example when the
+// changefeed restarts from an older high-water mark, so that replayed rows
+// cannot overwrite newer values.
+//
+// Columns added to a watched table are added to the destination table the
+// first time a row containing them is emitted. The destination tables must
+// otherwise already exist with compatible primary keys.
+type sqlTableSink struct {
+	db             *gosql.DB
+	checkpointName string
+	checkpoint     hlc.Timestamp
+
+	topics map[string]struct{}
+	// tables caches the columns of each destination table.
+	tables map[string]map[string]struct{}
+
+	rows []sqlTableSinkRow
+	// rowIdx maps the table and encoded key of each buffered row to its index in
+	// rows, so that only the latest version of a row is applied.
+	rowIdx map[sqlTableSinkRowKey]int
+}
+
+type sqlTableSinkRowKey struct {
+	table, key string
+}
+
+type sqlTableSinkRow struct {
+	table catalog.TableDescriptor
+	// pk holds the values of the primary key columns, in index order.
+	pk []interface{}
+	// cols and vals hold the columns of the row in sorted order, or are nil if
+	// the row was deleted.
+	cols []string
+	vals []interface{}
+}
+
+func makeSQLTableSink(
+	ctx context.Context,
+	uri, checkpointName string,
+	targets jobspb.ChangefeedTargets,
+	opts map[string]string,
+) (*sqlTableSink, error) {
+	if changefeedbase.FormatType(opts[changefeedbase.OptFormat]) != sqlTableSinkFormat {
+		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
+			changefeedbase.OptFormat, opts[changefeedbase.OptFormat])
+	}
+	if changefeedbase.EnvelopeType(opts[changefeedbase.OptEnvelope]) != changefeedbase.OptEnvelopeWrapped {
+		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
+			changefeedbase.OptEnvelope, opts[changefeedbase.OptEnvelope])
+	}
+
+	s := &sqlTableSink{
+		checkpointName: checkpointName,
+		topics:         make(map[string]struct{}),
+		tables:         make(map[string]map[string]struct{}),
+		rowIdx:         make(map[sqlTableSinkRowKey]int),
+	}
+	var names []string
+	for _, t := range targets {
+		s.topics[t.StatementTimeName] = struct{}{}
+		names = append(names, t.StatementTimeName)
+	}
+	if s.checkpointName == `` {
+		sort.Strings(names)
+		s.checkpointName = strings.Join(names, `,`)
+	}
+
+	var err error
+	if s.db, err = gosql.Open(`postgres`, uri); err != nil {
+		return nil, err
+	}
+	if _, err := s.db.ExecContext(ctx, sqlTableSinkCreateCheckpointTableStmt); err != nil {
+		_ = s.db.Close()
+		return nil, err
+	}
+	err = s.db.QueryRowContext(ctx, sqlTableSinkReadCheckpointStmt, s.checkpointName).Scan(
+		&s.checkpoint.WallTime, &s.checkpoint.Logical)
+	if err != nil && !errors.Is(err, gosql.ErrNoRows) {
+		_ = s.db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// EmitRow implements the Sink interface.
+func (s *sqlTableSink) EmitRow(
+	ctx context.Context, table catalog.TableDescriptor, key, value []byte, updated hlc.Timestamp,
+) error {
+	topic := table.GetName()
+	if _, ok := s.topics[topic]; !ok {
+		return errors.Errorf(`cannot emit to undeclared topic: %s`, topic)
+	}
+	if !s.checkpoint.IsEmpty() && updated.LessEq(s.checkpoint) {
+		// This row was applied before the checkpoint was written.
+		return nil
+	}
+
+	var pk []*string
+	if err := json.Unmarshal(key, &pk); err != nil {
+		return errors.Wrapf(err, `decoding key of %s`, topic)
+	}
+	if len(pk) != len(table.GetPrimaryIndex().ColumnNames) {
+		return errors.Errorf(`key %s does not match the primary key of %s`, key, topic)
+	}
+	var envelope struct {
+		After map[string]*string `json:"after"`
+	}
+	if err := json.Unmarshal(value, &envelope); err != nil {
+		return errors.Wrapf(err, `decoding value of %s`, topic)
+	}
+
+	row := sqlTableSinkRow{table: table, pk: make([]interface{}, len(pk))}
+	for i := range pk {
+		row.pk[i] = sqlTableSinkArg(pk[i])
+	}
+	if envelope.After != nil {
+		for col := range envelope.After {
+			row.cols = append(row.cols, col)
+		}
+		sort.Strings(row.cols)
+		row.vals = make([]interface{}, len(row.cols))
+		for i, col := range row.cols {
+			row.vals[i] = sqlTableSinkArg(envelope.After[col])
+		}
+	}
+
+	k := sqlTableSinkRowKey{table: topic, key: string(key)}
+	if idx, ok := s.rowIdx[k]; ok {
+		s.rows[idx] = row
+		return nil
+	}
+	s.rowIdx[k] = len(s.rows)
+	s.rows = append(s.rows, row)
+	if len(s.rows) >= sqlTableSinkFlushRows {
+		return s.Flush(ctx)
+	}
+	return nil
+}
+
+// EmitResolvedTimestamp implements the Sink interface.
+func (s *sqlTableSink) EmitResolvedTimestamp(
+	ctx context.Context, _ Encoder, resolved hlc.Timestamp,
+) error {
+	return s.flush(ctx, &resolved)
+}
+
+// Flush implements the Sink interface.
+func (s *sqlTableSink) Flush(ctx context.Context) error {
+	return s.flush(ctx, nil /* resolved */)
+}
+
+// flush applies the buffered rows and, if resolved is set, records it as the
+// checkpoint in the same transaction, so that the checkpoint never covers rows
+// which were not applied.
+func (s *sqlTableSink) flush(ctx context.Context, resolved *hlc.Timestamp) error {
+	if len(s.rows) == 0 && resolved == nil {
+		return nil
+	}
+
+	// Schema changes can't be mixed with writes to the new columns in a single
+	// transaction, so add any missing columns first.
+	for _, row := range s.rows {
+		if err := s.maybeAddColumns(ctx, row); err != nil {
+			return err
+		}
+	}
+
+	// Group the rows into statements. Every buffered row has a distinct key, so
+	// the order in which they are applied doesn't matter.
+	type group struct {
+		table catalog.TableDescriptor
+		cols  []string
+		rows  []sqlTableSinkRow
+	}
+	var groups []*group
+	groupIdx := make(map[string]*group)
+	for _, row := range s.rows {
+		groupKey := row.table.GetName() + "\x00" + strings.Join(row.cols, "\x00")
+		if row.cols == nil {
+			groupKey += "\x00delete"
+		}
+		g, ok := groupIdx[groupKey]
+		if !ok || len(g.rows) >= sqlTableSinkStmtRows {
+			g = &group{table: row.table, cols: row.cols}
+			groupIdx[groupKey] = g
+			groups = append(groups, g)
+		}
+		g.rows = append(g.rows, row)
+	}
+
+	if err := crdb.ExecuteTx(ctx, s.db, nil /* txopts */, func(tx *gosql.Tx) error {
+		for _, g := range groups {
+			var stmt string
+			var args []interface{}
+			if g.cols == nil {
+				stmt, args = sqlTableSinkDeleteStmt(g.table, g.rows)
+			} else {
+				stmt, args = sqlTableSinkUpsertStmt(g.table, g.cols, g.rows)
+			}
+			if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+				return errors.Wrapf(err, `applying rows to %s`, g.table.GetName())
+			}
+		}
+		if resolved != nil {
+			if _, err := tx.ExecContext(ctx, sqlTableSinkWriteCheckpointStmt,
+				s.checkpointName, resolved.WallTime, resolved.Logical); err != nil {
+				return errors.Wrap(err, `writing checkpoint`)
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.rows = s.rows[:0]
+	s.rowIdx = make(map[sqlTableSinkRowKey]int)
+	return nil
+}
+
+// Close implements the Sink interface.
+func (s *sqlTableSink) Close() error {
+	return s.db.Close()
+}
+
+// maybeAddColumns adds any columns of row which are missing from its
+// destination table, using the types of the columns in the watched table.
+func (s *sqlTableSink) maybeAddColumns(ctx context.Context, row sqlTableSinkRow) error {
+	name := row.table.GetName()
+	cols, ok := s.tables[name]
+	if !ok {
+		rows, err := s.db.QueryContext(ctx, sqlTableSinkColumnsStmt, name)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		cols = make(map[string]struct{})
+		for rows.Next() {
+			var col string
+			if err := rows.Scan(&col); err != nil {
+				return err
+			}
+			cols[col] = struct{}{}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(cols) == 0 {
+			return errors.Errorf(`destination table %s does not exist`, name)
+		}
+		s.tables[name] = cols
+	}
+
+	for _, col := range row.cols {
+		if _, ok := cols[col]; ok {
+			continue
+		}
+		colDesc, _, err := row.table.FindColumnByName(tree.Name(col))
+		if err != nil {
+			return err
+		}
+		stmt := fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s`,
+			tree.NameString(name), tree.NameString(col), colDesc.Type.SQLStandardName())
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return errors.Wrapf(err, `adding column %s to %s`, col, name)
+		}
+		cols[col] = struct{}{}
+	}
+	return nil
+}
+
+func sqlTableSinkUpsertStmt(
+	table catalog.TableDescriptor, cols []string, rows []sqlTableSinkRow,
+) (string, []interface{}) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `INSERT INTO %s (`, tree.NameString(table.GetName()))
+	for i, col := range cols {
+		if i > 0 {
+			buf.WriteString(`, `)
+		}
+		buf.WriteString(tree.NameString(col))
+	}
+	buf.WriteString(`) VALUES `)
+	args := make([]interface{}, 0, len(cols)*len(rows))
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteString(`, `)
+		}
+		writeSQLTableSinkPlaceholders(&buf, len(args), len(row.vals))
+		args = append(args, row.vals...)
+	}
+	buf.WriteString(` ON CONFLICT (`)
+	for i, col := range table.GetPrimaryIndex().ColumnNames {
+		if i > 0 {
+			buf.WriteString(`, `)
+		}
+		buf.WriteString(tree.NameString(col))
+	}
+	buf.WriteString(`) DO UPDATE SET `)
+	for i, col := range cols {
+		if i > 0 {
+			buf.WriteString(`, `)
+		}
+		fmt.Fprintf(&buf, `%[1]s = excluded.%[1]s`, tree.NameString(col))
+	}
+	return buf.String(), args
+}
+
+func sqlTableSinkDeleteStmt(
+	table catalog.TableDescriptor, rows []sqlTableSinkRow,
+) (string, []interface{}) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `DELETE FROM %s WHERE (`, tree.NameString(table.GetName()))
+	for i, col := range table.GetPrimaryIndex().ColumnNames {
+		if i > 0 {
+			buf.WriteString(`, `)
+		}
+		buf.WriteString(tree.NameString(col))
+	}
+	buf.WriteString(`) IN (`)
+	var args []interface{}
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteString(`, `)
+		}
+		writeSQLTableSinkPlaceholders(&buf, len(args), len(row.pk))
+		args = append(args, row.pk...)
+	}
+	buf.WriteString(`)`)
+	return buf.String(), args
+}
+
+// writeSQLTableSinkPlaceholders writes a parenthesized list of n placeholders
+// numbered after the first offset ones.
+func writeSQLTableSinkPlaceholders(buf *bytes.Buffer, offset, n int) {
+	buf.WriteString(`(`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteString(`, `)
+		}
+		fmt.Fprintf(buf, `$%d`, offset+i+1)
+	}
+	buf.WriteString(`)`)
+}
+
+// sqlTableSinkArg converts a value encoded in sqlTableSinkFormat into a
+// statement argument. Values are passed as text and converted to the type of
+// their destination column by the destination database.
+func sqlTableSinkArg(v *string) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
@@ -10,21 +10,29 @@ package changefeedccl
 
 import (
 	"context"
+	gosql "database/sql"
 	"net/url"
+	"os"
 	"strconv"
 	"testing"
 	"time"
 
 	"github.com/Shopify/sarama"
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/skip"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
@@ -295,3 +303,197 @@ func TestSQLSink(t *testing.T) {
 		},
 	)
 }
+
+func TestSQLTableSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, sqlDBRaw, _ := serverutils.StartServer(t, base.TestServerArgs{UseDatabase: "d"})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(sqlDBRaw)
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+
+	sinkURL, cleanup := sqlutils.PGUrl(t, s.ServingSQLAddr(), t.Name(), url.User(security.RootUser))
+	defer cleanup()
+	sinkURL.Path = `d`
+	testSQLTableSink(t, sinkURL.String(), sqlDB)
+}
+
+// TestSQLTableSinkPostgres runs the sql sink against the Postgres database at
+// POSTGRES_URL, e.g. postgres://postgres@localhost:5432/postgres?sslmode=disable.
+func TestSQLTableSinkPostgres(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	sinkURL := os.Getenv("POSTGRES_URL")
+	if sinkURL == "" {
+		skip.IgnoreLint(t, "POSTGRES_URL env var must be set")
+	}
+	db, err := gosql.Open(`postgres`, sinkURL)
+	require.NoError(t, err)
+	defer db.Close()
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `DROP TABLE IF EXISTS foo, `+sqlTableSinkCheckpointTable)
+	testSQLTableSink(t, sinkURL, sqlDB)
+}
+
+// testSQLTableSink tests the sql sink against the destination database at
+// sinkURL, which sqlDB is connected to.
+func testSQLTableSink(t *testing.T, sinkURL string, sqlDB *sqlutils.SQLRunner) {
+	ctx := context.Background()
+	sqlDB.Exec(t, `CREATE TABLE foo (
+		a INT PRIMARY KEY, b TEXT CHECK (b != 'invalid'), arr INT[], j JSONB
+	)`)
+
+	cols := []descpb.ColumnDescriptor{
+		{Name: `a`, ID: 1, Type: types.Int},
+		{Name: `b`, ID: 2, Type: types.String, Nullable: true},
+		{Name: `arr`, ID: 3, Type: types.IntArray, Nullable: true},
+		{Name: `j`, ID: 4, Type: types.Jsonb, Nullable: true},
+	}
+	table := func(cols []descpb.ColumnDescriptor) *tabledesc.Immutable {
+		return tabledesc.NewImmutable(descpb.TableDescriptor{
+			Name:    `foo`,
+			Columns: cols,
+			PrimaryIndex: descpb.IndexDescriptor{
+				Name:        `primary`,
+				ID:          1,
+				ColumnNames: []string{`a`},
+				ColumnIDs:   []descpb.ColumnID{1},
+			},
+		})
+	}
+	foo := table(cols)
+	ts := func(wallTime int64) hlc.Timestamp { return hlc.Timestamp{WallTime: wallTime} }
+
+	targets := jobspb.ChangefeedTargets{
+		0: jobspb.ChangefeedTarget{StatementTimeName: `foo`},
+	}
+	// Changefeeds into a sql sink use sqlTableSinkFormat in place of json.
+	details, err := validateDetails(jobspb.ChangefeedDetails{SinkURI: `sql://host/db`})
+	require.NoError(t, err)
+	opts := details.Opts
+	require.Equal(t, string(sqlTableSinkFormat), opts[changefeedbase.OptFormat])
+	encoder, err := getEncoder(opts)
+	require.NoError(t, err)
+
+	sink, err := makeSQLTableSink(ctx, sinkURL, ``, targets, opts)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, sink.Close()) }()
+
+	jsonb := func(s string) tree.Datum {
+		j, err := json.ParseJSON(s)
+		require.NoError(t, err)
+		return tree.NewDJSON(j)
+	}
+	intArray := func(vals ...int) tree.Datum {
+		arr := tree.NewDArray(types.Int)
+		for _, v := range vals {
+			require.NoError(t, arr.Append(tree.NewDInt(tree.DInt(v))))
+		}
+		return arr
+	}
+	emit := func(
+		sink *sqlTableSink, table *tabledesc.Immutable, updated hlc.Timestamp, datums ...tree.Datum,
+	) error {
+		row := encodeRow{updated: updated, tableDesc: table}
+		for i, col := range table.GetPublicColumns() {
+			d := tree.DNull
+			if i < len(datums) {
+				d = datums[i]
+			}
+			row.datums = append(row.datums, rowenc.DatumToEncDatum(col.Type, d))
+		}
+		row.deleted = len(datums) == 1
+		key, err := encoder.EncodeKey(ctx, row)
+		require.NoError(t, err)
+		key = append([]byte(nil), key...)
+		value, err := encoder.EncodeValue(ctx, row)
+		require.NoError(t, err)
+		return sink.EmitRow(ctx, table, key, value, updated)
+	}
+
+	// Nothing is applied until Flush is called, and only the latest version of
+	// each row is applied. Values are converted to the types of their columns:
+	// JSON strings and nulls are kept apart from text and NULL.
+	one, two := tree.NewDInt(1), tree.NewDInt(2)
+	require.NoError(t, emit(sink, foo, ts(1), one, tree.NewDString(`v0`), intArray(1), jsonb(`{}`)))
+	require.NoError(t, emit(sink, foo, ts(1), two, tree.NewDString(`v0`), tree.DNull, jsonb(`null`)))
+	require.NoError(t, emit(sink, foo, ts(2), one, tree.NewDString(`v1`), intArray(1, 2), jsonb(`"x"`)))
+	sqlDB.CheckQueryResults(t, `SELECT a, b FROM foo ORDER BY a`, [][]string{})
+	require.NoError(t, sink.Flush(ctx))
+	sqlDB.CheckQueryResults(t, `SELECT a, b, arr, j, j IS NULL FROM foo ORDER BY a`, [][]string{
+		{`1`, `v1`, `{1,2}`, `"x"`, `false`},
+		{`2`, `v0`, `NULL`, `null`, `false`},
+	})
+
+	// Deletes.
+	require.NoError(t, emit(sink, foo, ts(3), two))
+	require.NoError(t, sink.Flush(ctx))
+	sqlDB.CheckQueryResults(t, `SELECT a, b FROM foo ORDER BY a`, [][]string{{`1`, `v1`}})
+
+	// Columns added to the watched table are added to the destination.
+	fooWithC := table(append(cols, descpb.ColumnDescriptor{
+		Name: `c`, ID: 5, Type: types.Int, Nullable: true,
+	}))
+	require.NoError(t, emit(sink, fooWithC, ts(4),
+		tree.NewDInt(3), tree.NewDString(`v0`), tree.DNull, tree.DNull, tree.NewDInt(7)))
+	require.NoError(t, sink.Flush(ctx))
+	sqlDB.CheckQueryResults(t, `SELECT a, b, c FROM foo ORDER BY a`,
+		[][]string{{`1`, `v1`, `NULL`}, {`3`, `v0`, `7`}},
+	)
+
+	// Resolved timestamps are checkpointed at the destination in the same
+	// transaction as the rows buffered before them, and rows at or below the
+	// checkpoint are skipped by later sinks.
+	require.NoError(t, emit(sink, foo, ts(4), tree.NewDInt(4), tree.NewDString(`v0`)))
+	require.NoError(t, sink.EmitResolvedTimestamp(ctx, nil /* encoder */, ts(4)))
+	require.NoError(t, sink.EmitResolvedTimestamp(ctx, nil /* encoder */, ts(3)))
+	sqlDB.CheckQueryResults(t, `SELECT a, b FROM foo ORDER BY a`,
+		[][]string{{`1`, `v1`}, {`3`, `v0`}, {`4`, `v0`}},
+	)
+	sqlDB.CheckQueryResults(t, `SELECT name, wall_time FROM crdb_changefeed_checkpoints`,
+		[][]string{{`foo`, `4`}},
+	)
+	require.NoError(t, emit(sink, foo, ts(5), tree.NewDInt(5), tree.NewDString(`invalid`)))
+	require.Error(t, sink.EmitResolvedTimestamp(ctx, nil /* encoder */, ts(5)))
+	sqlDB.CheckQueryResults(t, `SELECT name, wall_time FROM crdb_changefeed_checkpoints`,
+		[][]string{{`foo`, `4`}},
+	)
+
+	restarted, err := makeSQLTableSink(ctx, sinkURL, ``, targets, opts)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, restarted.Close()) }()
+	require.NoError(t, emit(restarted, foo, ts(1), two, tree.NewDString(`v0`)))
+	require.NoError(t, emit(restarted, foo, ts(5), one, tree.NewDString(`v2`)))
+	require.NoError(t, restarted.Flush(ctx))
+	sqlDB.CheckQueryResults(t, `SELECT a, b FROM foo ORDER BY a`,
+		[][]string{{`1`, `v2`}, {`3`, `v0`}, {`4`, `v0`}},
+	)
+
+	// Undeclared topic
+	bar := tabledesc.NewImmutable(descpb.TableDescriptor{Name: `bar`})
+	require.EqualError(t,
+		restarted.EmitRow(ctx, bar, nil, nil, zeroTS), `cannot emit to undeclared topic: bar`)
+
+	// Missing destination table
+	sqlDB.Exec(t, `DROP TABLE foo`)
+	dropped, err := makeSQLTableSink(ctx, sinkURL, ``, targets, opts)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, dropped.Close()) }()
+	require.NoError(t, emit(dropped, foo, ts(6), one, tree.NewDString(`v3`)))
+	require.EqualError(t, dropped.Flush(ctx), `destination table foo does not exist`)
+
+	// Only the wrapped envelope is supported.
+	opts[changefeedbase.OptEnvelope] = string(changefeedbase.OptEnvelopeKeyOnly)
+	_, err = makeSQLTableSink(ctx, sinkURL, ``, targets, opts)
+	require.EqualError(t, err, `this sink is incompatible with envelope=key_only`)
+
+	// The format is reserved for sql sinks.
+	_, err = validateDetails(jobspb.ChangefeedDetails{
+		SinkURI: `kafka://host`,
+		Opts:    map[string]string{changefeedbase.OptFormat: string(sqlTableSinkFormat)},
+	})
+	require.EqualError(t, err, `unknown format: sql_text`)
+}
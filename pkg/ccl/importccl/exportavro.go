@@ -0,0 +1,428 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowexec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+	"github.com/linkedin/goavro/v2"
+)
+
+const exportAvroSuffix = ".avro"
+
+// exportAvroRecordName is the name of the record schema of exported files.
+const exportAvroRecordName = "export"
+
+// exportAvroBlockRows is the number of rows buffered before they are written
+// out as a block of the object container file. The size of a file is only
+// known at the granularity of blocks.
+const exportAvroBlockRows = 1000
+
+// avroEncodeFn converts a non-NULL datum to the native Go value expected by
+// goavro for its field.
+type avroEncodeFn func(tree.Datum) (interface{}, error)
+
+// exportAvroLogicalType is the schema of an Avro logical type.
+type exportAvroLogicalType struct {
+	Type        string `json:"type"`
+	LogicalType string `json:"logicalType"`
+	Precision   int32  `json:"precision,omitempty"`
+	Scale       int32  `json:"scale,omitempty"`
+}
+
+// exportAvroArrayType is the schema of an Avro array of nullable items.
+type exportAvroArrayType struct {
+	Type  string        `json:"type"`
+	Items []interface{} `json:"items"`
+}
+
+// exportAvroField is the schema of a field of the exported records.
+type exportAvroField struct {
+	Name string        `json:"name"`
+	Type []interface{} `json:"type"`
+	// Default is always null, which is serialized as such rather than
+	// omitted.
+	Default *string `json:"default"`
+}
+
+// avroUnionKey returns the name identifying a type within an Avro union, as
+// expected by goavro.Union.
+func avroUnionKey(typ interface{}) string {
+	switch t := typ.(type) {
+	case string:
+		return t
+	case exportAvroLogicalType:
+		return t.Type + "." + t.LogicalType
+	case exportAvroArrayType:
+		return t.Type
+	default:
+		panic(errors.AssertionFailedf("unexpected avro type %T", typ))
+	}
+}
+
+// exportAvroName makes a SQL name a valid Avro name, which may only contain
+// letters, digits and underscores and must not start with a digit.
+func exportAvroName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || r == '_'):
+			b.WriteRune(r)
+		case r < unicode.MaxASCII && unicode.IsDigit(r):
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// newAvroType maps a SQL type to the Avro type used to export it. Types
+// without an Avro equivalent are exported as strings, in the same format used
+// by EXPORT INTO CSV.
+func newAvroType(name string, typ *types.T) (interface{}, avroEncodeFn, error) {
+	var avroType interface{}
+	var fn avroEncodeFn
+	switch typ.Family() {
+	case types.ArrayFamily:
+		elemType, elemFn, err := newAvroType(name, typ.ArrayContents())
+		if err != nil {
+			return nil, nil, err
+		}
+		elemKey := avroUnionKey(elemType)
+		avroType = exportAvroArrayType{Type: "array", Items: []interface{}{"null", elemType}}
+		fn = func(d tree.Datum) (interface{}, error) {
+			arr := d.(*tree.DArray)
+			elems := make([]interface{}, len(arr.Array))
+			for i, elem := range arr.Array {
+				if elem == tree.DNull {
+					elems[i] = goavro.Union("null", nil)
+					continue
+				}
+				v, err := elemFn(elem)
+				if err != nil {
+					return nil, err
+				}
+				elems[i] = goavro.Union(elemKey, v)
+			}
+			return elems, nil
+		}
+	case types.BoolFamily:
+		avroType = "boolean"
+		fn = func(d tree.Datum) (interface{}, error) {
+			return bool(*d.(*tree.DBool)), nil
+		}
+	case types.IntFamily:
+		if typ.Width() == 64 {
+			avroType = "long"
+			fn = func(d tree.Datum) (interface{}, error) {
+				return int64(*d.(*tree.DInt)), nil
+			}
+		} else {
+			avroType = "int"
+			fn = func(d tree.Datum) (interface{}, error) {
+				return int32(*d.(*tree.DInt)), nil
+			}
+		}
+	case types.FloatFamily:
+		if typ.Width() == 32 {
+			avroType = "float"
+			fn = func(d tree.Datum) (interface{}, error) {
+				return float32(*d.(*tree.DFloat)), nil
+			}
+		} else {
+			avroType = "double"
+			fn = func(d tree.Datum) (interface{}, error) {
+				return float64(*d.(*tree.DFloat)), nil
+			}
+		}
+	case types.DecimalFamily:
+		if typ.Precision() == 0 {
+			// Decimals without a fixed precision and scale can't be represented
+			// by the Avro decimal type.
+			avroType = "string"
+			fn = func(d tree.Datum) (interface{}, error) {
+				return d.(*tree.DDecimal).Decimal.String(), nil
+			}
+			break
+		}
+		scale := typ.Width()
+		avroType = exportAvroLogicalType{
+			Type:        "bytes",
+			LogicalType: "decimal",
+			Precision:   typ.Precision(),
+			Scale:       scale,
+		}
+		denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+		fn = func(d tree.Datum) (interface{}, error) {
+			unscaled, err := decimalUnscaledValue(&d.(*tree.DDecimal).Decimal, scale)
+			if err != nil {
+				return nil, errors.Wrapf(err, "column %s", name)
+			}
+			// goavro expects decimals as rationals.
+			return new(big.Rat).SetFrac(unscaled, denom), nil
+		}
+	case types.StringFamily:
+		avroType = "string"
+		fn = func(d tree.Datum) (interface{}, error) {
+			return string(*d.(*tree.DString)), nil
+		}
+	case types.CollatedStringFamily:
+		avroType = "string"
+		fn = func(d tree.Datum) (interface{}, error) {
+			return d.(*tree.DCollatedString).Contents, nil
+		}
+	case types.BytesFamily:
+		avroType = "bytes"
+		fn = func(d tree.Datum) (interface{}, error) {
+			return []byte(*d.(*tree.DBytes)), nil
+		}
+	case types.DateFamily:
+		avroType = exportAvroLogicalType{Type: "int", LogicalType: "date"}
+		fn = func(d tree.Datum) (interface{}, error) {
+			date := d.(*tree.DDate).Date
+			if !date.IsFinite() {
+				return nil, errors.Errorf(
+					"column %s: infinite date not supported by avro exports", name)
+			}
+			// goavro expects dates as a time.Time.
+			return date.ToTime()
+		}
+	case types.TimeFamily:
+		avroType = exportAvroLogicalType{Type: "long", LogicalType: "time-micros"}
+		fn = func(d tree.Datum) (interface{}, error) {
+			// goavro expects times as a time.Duration.
+			return time.Duration(*d.(*tree.DTime)) * time.Microsecond, nil
+		}
+	case types.TimestampFamily:
+		avroType = exportAvroLogicalType{Type: "long", LogicalType: "timestamp-micros"}
+		fn = func(d tree.Datum) (interface{}, error) {
+			return d.(*tree.DTimestamp).Time, nil
+		}
+	case types.TimestampTZFamily:
+		avroType = exportAvroLogicalType{Type: "long", LogicalType: "timestamp-micros"}
+		fn = func(d tree.Datum) (interface{}, error) {
+			return d.(*tree.DTimestampTZ).Time, nil
+		}
+	case types.UuidFamily:
+		avroType = "string"
+		fn = func(d tree.Datum) (interface{}, error) {
+			return d.(*tree.DUuid).UUID.String(), nil
+		}
+	case types.JsonFamily:
+		avroType = "string"
+		fn = func(d tree.Datum) (interface{}, error) {
+			return d.(*tree.DJSON).JSON.String(), nil
+		}
+	case types.EnumFamily:
+		// Enum labels are not necessarily valid Avro enum symbols, so they are
+		// exported as strings.
+		avroType = "string"
+		fn = func(d tree.Datum) (interface{}, error) {
+			return d.(*tree.DEnum).LogicalRep, nil
+		}
+	default:
+		avroType = "string"
+		f := tree.NewFmtCtx(tree.FmtExport)
+		fn = func(d tree.Datum) (interface{}, error) {
+			f.Reset()
+			d.Format(f)
+			return f.String(), nil
+		}
+	}
+	return avroType, fn, nil
+}
+
+// avroEncoder is an exportEncoder writing Avro object container files.
+type avroEncoder struct {
+	names       []string
+	unionKeys   []string
+	encodeFns   []avroEncodeFn
+	codec       *goavro.Codec
+	compression string
+
+	buf     bytes.Buffer
+	writer  *goavro.OCFWriter
+	pending []interface{}
+	// pendingSize is the approximate encoded size of the pending records.
+	pendingSize int64
+}
+
+var _ exportEncoder = &avroEncoder{}
+
+func newAvroEncoder(
+	colNames []string, typs []*types.T, compression execinfrapb.FileCompression,
+) (*avroEncoder, error) {
+	e := &avroEncoder{
+		names:     make([]string, len(typs)),
+		unionKeys: make([]string, len(typs)),
+		encodeFns: make([]avroEncodeFn, len(typs)),
+	}
+	switch compression {
+	case execinfrapb.FileCompression_None:
+		e.compression = goavro.CompressionNullLabel
+	case execinfrapb.FileCompression_Deflate:
+		e.compression = goavro.CompressionDeflateLabel
+	case execinfrapb.FileCompression_Snappy:
+		e.compression = goavro.CompressionSnappyLabel
+	default:
+		return nil, errors.Errorf("unsupported compression codec %s for avro exports", compression)
+	}
+
+	sanitized := make([]string, len(typs))
+	for i, name := range exportColumnNames(colNames, len(typs)) {
+		sanitized[i] = exportAvroName(name)
+	}
+	fields := make([]exportAvroField, len(typs))
+	for i, name := range exportColumnNames(sanitized, len(typs)) {
+		avroType, fn, err := newAvroType(name, typs[i])
+		if err != nil {
+			return nil, err
+		}
+		// Every field is made optional by unioning it with null.
+		fields[i] = exportAvroField{Name: name, Type: []interface{}{"null", avroType}}
+		e.names[i] = name
+		e.unionKeys[i] = avroUnionKey(avroType)
+		e.encodeFns[i] = fn
+	}
+	schema, err := json.Marshal(struct {
+		Type   string            `json:"type"`
+		Name   string            `json:"name"`
+		Fields []exportAvroField `json:"fields"`
+	}{Type: "record", Name: exportAvroRecordName, Fields: fields})
+	if err != nil {
+		return nil, err
+	}
+	if e.codec, err = goavro.NewCodec(string(schema)); err != nil {
+		return nil, errors.Wrap(err, "invalid avro schema")
+	}
+	return e, nil
+}
+
+// Add implements the exportEncoder interface.
+func (e *avroEncoder) Add(row tree.Datums) error {
+	if e.writer == nil {
+		e.buf.Reset()
+		var err error
+		e.writer, err = goavro.NewOCFWriter(goavro.OCFConfig{
+			W:               &e.buf,
+			Codec:           e.codec,
+			CompressionName: e.compression,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	record := make(map[string]interface{}, len(row))
+	for i, d := range row {
+		if d == tree.DNull {
+			record[e.names[i]] = goavro.Union("null", nil)
+			continue
+		}
+		v, err := e.encodeFns[i](d)
+		if err != nil {
+			return err
+		}
+		record[e.names[i]] = goavro.Union(e.unionKeys[i], v)
+		e.pendingSize += avroValueSize(v)
+	}
+	e.pending = append(e.pending, record)
+	if len(e.pending) >= exportAvroBlockRows {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *avroEncoder) flush() error {
+	if len(e.pending) == 0 {
+		return nil
+	}
+	err := e.writer.Append(e.pending)
+	e.pending = e.pending[:0]
+	e.pendingSize = 0
+	return err
+}
+
+// avroValueSize approximates the encoded size of a native Avro value.
+func avroValueSize(v interface{}) int64 {
+	switch t := v.(type) {
+	case string:
+		return int64(len(t))
+	case []byte:
+		return int64(len(t))
+	case []interface{}:
+		var size int64
+		for _, elem := range t {
+			size += avroValueSize(elem)
+		}
+		return size
+	case map[string]interface{}:
+		var size int64
+		for _, elem := range t {
+			size += avroValueSize(elem)
+		}
+		return size
+	default:
+		return 8
+	}
+}
+
+// Size implements the exportEncoder interface.
+func (e *avroEncoder) Size() int64 {
+	return int64(e.buf.Len()) + e.pendingSize
+}
+
+// Finish implements the exportEncoder interface.
+func (e *avroEncoder) Finish() ([]byte, error) {
+	if e.writer == nil {
+		return nil, errors.AssertionFailedf("no rows to finish")
+	}
+	err := e.flush()
+	e.writer = nil
+	return e.buf.Bytes(), err
+}
+
+func newAvroWriterProcessor(
+	flowCtx *execinfra.FlowCtx,
+	processorID int32,
+	spec execinfrapb.AvroWriterSpec,
+	input execinfra.RowSource,
+	output execinfra.RowReceiver,
+) (execinfra.Processor, error) {
+	encoder, err := newAvroEncoder(spec.ColNames, input.OutputTypes(), spec.CompressionCodec)
+	if err != nil {
+		return nil, err
+	}
+	return newExportFileWriter("avroWriter", flowCtx, processorID, exportFileSpec{
+		destination: spec.Destination,
+		namePattern: spec.NamePattern,
+		fileSuffix:  exportAvroSuffix,
+		chunkRows:   spec.ChunkRows,
+		chunkSize:   spec.ChunkSize,
+		user:        spec.User,
+	}, encoder, input, output)
+}
+
+func init() {
+	rowexec.NewAvroWriterProcessor = newAvroWriterProcessor
+}
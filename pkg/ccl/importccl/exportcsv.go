@@ -11,25 +11,16 @@ package importccl
 import (
 	"bytes"
 	"compress/gzip"
-	"context"
-	"fmt"
-	"strings"
 
-	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
-	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowexec"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
-	"github.com/cockroachdb/cockroach/pkg/sql/types"
-	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding/csv"
-	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/errors"
 )
 
-const exportFilePatternPart = "%part%"
-const exportFilePatternDefault = exportFilePatternPart + ".csv"
+const exportCSVSuffix = ".csv"
 
 // csvExporter data structure to augment the compression
 // and csv writer, encapsulating the internals to make
@@ -38,8 +29,17 @@ type csvExporter struct {
 	compressor *gzip.Writer
 	buf        *bytes.Buffer
 	csvWriter  *csv.Writer
+
+	nullsAs string
+	f       *tree.FmtCtx
+	csvRow  []string
+	// finished is set once the current file is finished, so that the next
+	// row starts a new one.
+	finished bool
 }
 
+var _ exportEncoder = &csvExporter{}
+
 // Write append record to csv file
 func (c *csvExporter) Write(record []string) error {
 	return c.csvWriter.Write(record)
@@ -83,21 +83,45 @@ func (c *csvExporter) Len() int {
 	return c.buf.Len()
 }
 
-func (c *csvExporter) FileName(spec execinfrapb.CSVWriterSpec, part string) string {
-	pattern := exportFilePatternDefault
-	if spec.NamePattern != "" {
-		pattern = spec.NamePattern
+// Add implements the exportEncoder interface.
+func (c *csvExporter) Add(row tree.Datums) error {
+	if c.finished {
+		c.ResetBuffer()
+		c.finished = false
+	}
+	for i, d := range row {
+		if d == tree.DNull {
+			c.csvRow[i] = c.nullsAs
+			continue
+		}
+		d.Format(c.f)
+		c.csvRow[i] = c.f.String()
+		c.f.Reset()
 	}
+	return c.Write(c.csvRow)
+}
 
-	fileName := strings.Replace(pattern, exportFilePatternPart, part, -1)
-	// TODO: add suffix based on compressor type
-	if c.compressor != nil {
-		fileName += ".gz"
+// Size implements the exportEncoder interface. The size of the buffer lags
+// behind the rows written, as the csv writer and the compressor buffer their
+// output.
+func (c *csvExporter) Size() int64 {
+	return int64(c.Len())
+}
+
+// Finish implements the exportEncoder interface.
+func (c *csvExporter) Finish() ([]byte, error) {
+	c.finished = true
+	if err := c.Flush(); err != nil {
+		return nil, errors.Wrap(err, "failed to flush csv writer")
+	}
+	// Close writer to ensure buffer and any compression footer is flushed.
+	if err := c.Close(); err != nil {
+		return nil, errors.Wrapf(err, "failed to close exporting writer")
 	}
-	return fileName
+	return c.Bytes(), nil
 }
 
-func newCSVExporter(sp execinfrapb.CSVWriterSpec) *csvExporter {
+func newCSVExporter(sp execinfrapb.CSVWriterSpec, numCols int) *csvExporter {
 	buf := bytes.NewBuffer([]byte{})
 	var exporter *csvExporter
 	switch sp.CompressionCodec {
@@ -121,6 +145,11 @@ func newCSVExporter(sp execinfrapb.CSVWriterSpec) *csvExporter {
 	if sp.Options.Comma != 0 {
 		exporter.csvWriter.Comma = sp.Options.Comma
 	}
+	if sp.Options.NullEncoding != nil {
+		exporter.nullsAs = *sp.Options.NullEncoding
+	}
+	exporter.f = tree.NewFmtCtx(tree.FmtExport)
+	exporter.csvRow = make([]string, numCols)
 	return exporter
 }
 
@@ -131,168 +160,19 @@ func newCSVWriterProcessor(
 	input execinfra.RowSource,
 	output execinfra.RowReceiver,
 ) (execinfra.Processor, error) {
-
-	c := &csvWriter{
-		flowCtx:     flowCtx,
-		processorID: processorID,
-		spec:        spec,
-		input:       input,
-		output:      output,
-	}
-	semaCtx := tree.MakeSemaContext()
-	if err := c.out.Init(&execinfrapb.PostProcessSpec{}, c.OutputTypes(), &semaCtx, flowCtx.NewEvalCtx(), output); err != nil {
-		return nil, err
+	var compressionSuffix string
+	if spec.CompressionCodec == execinfrapb.FileCompression_Gzip {
+		compressionSuffix = ".gz"
 	}
-	return c, nil
-}
-
-type csvWriter struct {
-	flowCtx     *execinfra.FlowCtx
-	processorID int32
-	spec        execinfrapb.CSVWriterSpec
-	input       execinfra.RowSource
-	out         execinfra.ProcOutputHelper
-	output      execinfra.RowReceiver
-}
-
-var _ execinfra.Processor = &csvWriter{}
-
-func (sp *csvWriter) OutputTypes() []*types.T {
-	res := make([]*types.T, len(colinfo.ExportColumns))
-	for i := range res {
-		res[i] = colinfo.ExportColumns[i].Typ
-	}
-	return res
-}
-
-func (sp *csvWriter) Run(ctx context.Context) {
-	ctx, span := tracing.ChildSpan(ctx, "csvWriter")
-	defer tracing.FinishSpan(span)
-
-	err := func() error {
-		typs := sp.input.OutputTypes()
-		sp.input.Start(ctx)
-		input := execinfra.MakeNoMetadataRowSource(sp.input, sp.output)
-
-		alloc := &rowenc.DatumAlloc{}
-
-		writer := newCSVExporter(sp.spec)
-
-		nullsAs := ""
-		if sp.spec.Options.NullEncoding != nil {
-			nullsAs = *sp.spec.Options.NullEncoding
-		}
-		f := tree.NewFmtCtx(tree.FmtExport)
-		defer f.Close()
-
-		csvRow := make([]string, len(typs))
-
-		chunk := 0
-		done := false
-		for {
-			var rows int64
-			writer.ResetBuffer()
-			for {
-				if sp.spec.ChunkRows > 0 && rows >= sp.spec.ChunkRows {
-					break
-				}
-				row, err := input.NextRow()
-				if err != nil {
-					return err
-				}
-				if row == nil {
-					done = true
-					break
-				}
-				rows++
-
-				for i, ed := range row {
-					if ed.IsNull() {
-						csvRow[i] = nullsAs
-						continue
-					}
-					if err := ed.EnsureDecoded(typs[i], alloc); err != nil {
-						return err
-					}
-					ed.Datum.Format(f)
-					csvRow[i] = f.String()
-					f.Reset()
-				}
-				if err := writer.Write(csvRow); err != nil {
-					return err
-				}
-			}
-			if rows < 1 {
-				break
-			}
-			if err := writer.Flush(); err != nil {
-				return errors.Wrap(err, "failed to flush csv writer")
-			}
-
-			conf, err := cloudimpl.ExternalStorageConfFromURI(sp.spec.Destination, sp.spec.User)
-			if err != nil {
-				return err
-			}
-			es, err := sp.flowCtx.Cfg.ExternalStorage(ctx, conf)
-			if err != nil {
-				return err
-			}
-			defer es.Close()
-
-			nodeID, err := sp.flowCtx.EvalCtx.NodeID.OptionalNodeIDErr(47970)
-			if err != nil {
-				return err
-			}
-
-			part := fmt.Sprintf("n%d.%d", nodeID, chunk)
-			chunk++
-			filename := writer.FileName(sp.spec, part)
-			// Close writer to ensure buffer and any compression footer is flushed.
-			err = writer.Close()
-			if err != nil {
-				return errors.Wrapf(err, "failed to close exporting writer")
-			}
-
-			size := writer.Len()
-
-			if err := es.WriteFile(ctx, filename, bytes.NewReader(writer.Bytes())); err != nil {
-				return err
-			}
-			res := rowenc.EncDatumRow{
-				rowenc.DatumToEncDatum(
-					types.String,
-					tree.NewDString(filename),
-				),
-				rowenc.DatumToEncDatum(
-					types.Int,
-					tree.NewDInt(tree.DInt(rows)),
-				),
-				rowenc.DatumToEncDatum(
-					types.Int,
-					tree.NewDInt(tree.DInt(size)),
-				),
-			}
-
-			cs, err := sp.out.EmitRow(ctx, res)
-			if err != nil {
-				return err
-			}
-			if cs != execinfra.NeedMoreRows {
-				// TODO(dt): presumably this is because our recv already closed due to
-				// another error... so do we really need another one?
-				return errors.New("unexpected closure of consumer")
-			}
-			if done {
-				break
-			}
-		}
-
-		return nil
-	}()
-
-	// TODO(dt): pick up tracing info in trailing meta
-	execinfra.DrainAndClose(
-		ctx, sp.output, err, func(context.Context) {} /* pushTrailingMeta */, sp.input)
+	return newExportFileWriter("csvWriter", flowCtx, processorID, exportFileSpec{
+		destination:       spec.Destination,
+		namePattern:       spec.NamePattern,
+		fileSuffix:        exportCSVSuffix,
+		compressionSuffix: compressionSuffix,
+		chunkRows:         spec.ChunkRows,
+		chunkSize:         spec.ChunkSize,
+		user:              spec.User,
+	}, newCSVExporter(spec, len(input.OutputTypes())), input, output)
 }
 
 func init() {
@@ -0,0 +1,182 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding/parquet"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportColumnNames(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	require.Equal(t,
+		[]string{"a", "a_1", "b", "a_2", "column5"},
+		exportColumnNames([]string{"a", "a", "b", "a"}, 5),
+	)
+	require.Equal(t, "_1a_b_", exportAvroName("1a b✅"))
+}
+
+func TestNewParquetColumn(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	dec, err := tree.ParseDDecimal("-12.3")
+	require.NoError(t, err)
+	date, err := tree.NewDDateFromTime(time.Unix(0, 0).UTC().AddDate(0, 0, 3))
+	require.NoError(t, err)
+	arr := tree.NewDArray(types.Int)
+	require.NoError(t, arr.Append(tree.NewDInt(1)))
+	require.NoError(t, arr.Append(tree.DNull))
+	interval := tree.NewDInterval(duration.MakeDuration(0, 1, 0), types.DefaultIntervalTypeMetadata)
+
+	for _, tc := range []struct {
+		typ      *types.T
+		datum    tree.Datum
+		col      parquet.Column
+		expected interface{}
+	}{
+		{
+			typ:      types.Int2,
+			datum:    tree.NewDInt(7),
+			col:      parquet.Column{Name: "c", Type: parquet.Int32, Logical: parquet.LogicalInt16},
+			expected: int32(7),
+		},
+		{
+			typ:      types.Float4,
+			datum:    tree.NewDFloat(1.5),
+			col:      parquet.Column{Name: "c", Type: parquet.Float},
+			expected: float32(1.5),
+		},
+		{
+			typ:   types.MakeDecimal(10, 2),
+			datum: dec,
+			col: parquet.Column{
+				Name: "c", Type: parquet.FixedLenByteArray, Logical: parquet.LogicalDecimal,
+				TypeLength: 5, Precision: 10, Scale: 2,
+			},
+			// -1230 in two's complement.
+			expected: []byte{0xff, 0xff, 0xff, 0xfb, 0x32},
+		},
+		{
+			typ:      types.Decimal,
+			datum:    dec,
+			col:      parquet.Column{Name: "c", Type: parquet.ByteArray, Logical: parquet.LogicalString},
+			expected: []byte("-12.3"),
+		},
+		{
+			typ:      types.Date,
+			datum:    date,
+			col:      parquet.Column{Name: "c", Type: parquet.Int32, Logical: parquet.LogicalDate},
+			expected: int32(3),
+		},
+		{
+			typ:      types.IntArray,
+			datum:    arr,
+			col:      parquet.Column{Name: "c", Type: parquet.Int64, List: true},
+			expected: []interface{}{int64(1), nil},
+		},
+		{
+			typ:      types.Interval,
+			datum:    interval,
+			col:      parquet.Column{Name: "c", Type: parquet.ByteArray, Logical: parquet.LogicalString},
+			expected: []byte(tree.AsStringWithFlags(interval, tree.FmtExport)),
+		},
+	} {
+		col, fn, err := newParquetColumn("c", tc.typ)
+		require.NoError(t, err)
+		require.Equal(t, tc.col, col, tc.typ.SQLString())
+		v, err := fn(tc.datum)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, v, tc.typ.SQLString())
+	}
+
+	e, err := newParquetEncoder([]string{"a"}, []*types.T{types.Int}, execinfrapb.FileCompression_Snappy)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, e.Add(tree.Datums{tree.NewDInt(tree.DInt(i))}))
+	}
+	require.Less(t, int64(0), e.Size())
+	content, err := e.Finish()
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(content, []byte("PAR1")))
+	require.True(t, bytes.HasSuffix(content, []byte("PAR1")))
+
+	_, err = newParquetEncoder(nil, []*types.T{types.Int}, execinfrapb.FileCompression_Deflate)
+	require.Error(t, err)
+}
+
+func TestAvroEncoder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC)
+	dts, err := tree.MakeDTimestamp(ts, time.Microsecond)
+	require.NoError(t, err)
+	dec, err := tree.ParseDDecimal("12.3")
+	require.NoError(t, err)
+	arr := tree.NewDArray(types.String)
+	require.NoError(t, arr.Append(tree.NewDString("x")))
+	require.NoError(t, arr.Append(tree.DNull))
+
+	typs := []*types.T{types.Int, types.Int4, types.String, types.MakeDecimal(10, 2), types.Timestamp, types.StringArray}
+	e, err := newAvroEncoder(
+		[]string{"id", "small", "str", "dec", "ts", "arr"}, typs, execinfrapb.FileCompression_Deflate,
+	)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, e.Add(tree.Datums{
+			tree.NewDInt(tree.DInt(i)), tree.NewDInt(2), tree.NewDString("s"), dec, dts, arr,
+		}))
+		require.NoError(t, e.Add(tree.Datums{
+			tree.NewDInt(tree.DInt(i)), tree.DNull, tree.DNull, tree.DNull, tree.DNull, tree.DNull,
+		}))
+		content, err := e.Finish()
+		require.NoError(t, err)
+
+		r, err := goavro.NewOCFReader(bytes.NewReader(content))
+		require.NoError(t, err)
+		require.Equal(t, goavro.CompressionDeflateLabel, r.CompressionName())
+		var records []map[string]interface{}
+		for r.Scan() {
+			record, err := r.Read()
+			require.NoError(t, err)
+			records = append(records, record.(map[string]interface{}))
+		}
+		require.NoError(t, r.Err())
+		require.Len(t, records, 2)
+
+		require.Equal(t, map[string]interface{}{"long": int64(i)}, records[0]["id"])
+		require.Equal(t, map[string]interface{}{"int": int32(2)}, records[0]["small"])
+		require.Equal(t, map[string]interface{}{"string": "s"}, records[0]["str"])
+		require.Equal(t, 0, big.NewRat(123, 10).Cmp(
+			records[0]["dec"].(map[string]interface{})["bytes.decimal"].(*big.Rat)))
+		require.True(t, ts.Equal(
+			records[0]["ts"].(map[string]interface{})["long.timestamp-micros"].(time.Time)))
+		require.Equal(t, map[string]interface{}{"array": []interface{}{
+			map[string]interface{}{"string": "x"}, nil,
+		}}, records[0]["arr"])
+		for _, name := range []string{"small", "str", "dec", "ts", "arr"} {
+			require.Nil(t, records[1][name])
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl_test
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/workload/bank"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportBankAvro(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	db, _, cleanup := setupExportableBank(t, 3, 100)
+	defer cleanup()
+
+	db.Exec(t, "UPDATE bank SET payload = payload || '✅' WHERE id = 5")
+	db.Exec(t, "UPDATE bank SET payload = NULL WHERE id % 2 = 0")
+
+	for _, codec := range []string{"", "deflate", "snappy"} {
+		t.Run("compression="+codec, func(t *testing.T) {
+			var asOf string
+			db.QueryRow(t, "SELECT cluster_logical_timestamp()").Scan(&asOf)
+
+			opts := "chunk_rows = '13'"
+			if codec != "" {
+				opts += ", compression = " + codec
+			}
+			var files []string
+			for _, row := range db.QueryStr(t, fmt.Sprintf(`EXPORT INTO AVRO 'nodelocal://0/avro%s'
+				WITH %s FROM SELECT * FROM bank AS OF SYSTEM TIME %s`, codec, opts, asOf),
+			) {
+				require.True(t, strings.HasSuffix(row[0], ".avro"), row[0])
+				files = append(files, fmt.Sprintf("'nodelocal://0/avro%s/%s'", codec, row[0]))
+			}
+			require.Less(t, 1, len(files))
+
+			schema := bank.FromRows(1).Tables()[0].Schema
+			db.Exec(t, fmt.Sprintf(`IMPORT TABLE bank2 %s AVRO DATA (%s)`, schema, strings.Join(files, ", ")))
+			db.CheckQueryResults(t,
+				fmt.Sprintf(`SELECT * FROM bank AS OF SYSTEM TIME %s ORDER BY id`, asOf),
+				db.QueryStr(t, `SELECT * FROM bank2 ORDER BY id`),
+			)
+			db.Exec(t, "DROP TABLE bank2")
+		})
+	}
+}
+
+func TestExportParquet(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE TYPE greeting AS ENUM ('hello', 'hi')`)
+	sqlDB.Exec(t, `CREATE TABLE t (
+		i INT8 PRIMARY KEY, s INT2, b BOOL, f FLOAT4, d DECIMAL(10, 2), e DECIMAL, str STRING,
+		bt BYTES, dt DATE, ts TIMESTAMP, tz TIMESTAMPTZ, tm TIME, u UUID, j JSONB,
+		g greeting, a INT[], ip INET
+	)`)
+	sqlDB.Exec(t, `INSERT INTO t VALUES
+		(1, 1, true, 1.5, 12.34, 1.000001, 'a', 'b', '2020-01-01', '2020-01-01 01:02:03',
+		 '2020-01-01 01:02:03+01', '01:02:03', 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11',
+		 '{"a": 1}', 'hi', ARRAY[1, NULL, 3], '127.0.0.1'),
+		(2, NULL, NULL, NULL, -0.5, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL,
+		 ARRAY[]::INT[], NULL),
+		(3, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL,
+		 NULL, NULL)`)
+
+	for _, codec := range []string{"", "gzip", "snappy"} {
+		opts := "chunk_rows = '2'"
+		if codec != "" {
+			opts += ", compression = " + codec
+		}
+		rows := sqlDB.QueryStr(t, fmt.Sprintf(
+			`EXPORT INTO PARQUET 'nodelocal://0/parquet%s' WITH %s FROM TABLE t`, codec, opts))
+		require.Len(t, rows, 2)
+		for _, row := range rows {
+			require.True(t, strings.HasSuffix(row[0], ".parquet"), row[0])
+			content, err := ioutil.ReadFile(filepath.Join(dir, "parquet"+codec, row[0]))
+			require.NoError(t, err)
+			require.Equal(t, row[2], fmt.Sprint(len(content)))
+			require.Equal(t, "PAR1", string(content[:4]))
+			require.Equal(t, "PAR1", string(content[len(content)-4:]))
+			footerLen := binary.LittleEndian.Uint32(content[len(content)-8:])
+			require.Less(t, int(footerLen), len(content))
+		}
+		require.Equal(t, []string{"2", "1"}, []string{rows[0][1], rows[1][1]})
+	}
+}
+
+func TestExportChunkSize(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	const numRows = 3000
+	sqlDB.Exec(t, `CREATE TABLE t AS SELECT i, repeat('x', 100) AS s FROM generate_series(1, $1) AS g(i)`, numRows)
+
+	for _, format := range []string{"CSV", "PARQUET", "AVRO"} {
+		t.Run(format, func(t *testing.T) {
+			rows := sqlDB.QueryStr(t, fmt.Sprintf(
+				`EXPORT INTO %s 'nodelocal://0/%s' WITH chunk_size = '50KiB' FROM TABLE t`, format, format))
+			require.Less(t, 1, len(rows))
+			total := 0
+			for _, row := range rows {
+				var n int
+				_, err := fmt.Sscan(row[1], &n)
+				require.NoError(t, err)
+				total += n
+			}
+			require.Equal(t, numRows, total)
+		})
+	}
+}
+
+func TestExportFormatOptions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE TABLE t (a INT PRIMARY KEY)`)
+	for _, tc := range []struct {
+		stmt, err string
+	}{
+		{`EXPORT INTO JSON 'nodelocal://0/x' FROM TABLE t`, `unsupported export format`},
+		{`EXPORT INTO PARQUET 'nodelocal://0/x' WITH delimiter = '|' FROM TABLE t`,
+			`delimiter option is only supported for CSV exports`},
+		{`EXPORT INTO AVRO 'nodelocal://0/x' WITH nullas = '' FROM TABLE t`,
+			`nullas option is only supported for CSV exports`},
+		{`EXPORT INTO CSV 'nodelocal://0/x' WITH compression = snappy FROM TABLE t`,
+			`unsupported compression codec snappy`},
+		{`EXPORT INTO PARQUET 'nodelocal://0/x' WITH compression = deflate FROM TABLE t`,
+			`unsupported compression codec deflate`},
+		{`EXPORT INTO AVRO 'nodelocal://0/x' WITH compression = gzip FROM TABLE t`,
+			`unsupported compression codec gzip`},
+		{`EXPORT INTO CSV 'nodelocal://0/x' WITH chunk_size = 'abc' FROM TABLE t`,
+			`invalid`},
+		{`EXPORT INTO CSV 'nodelocal://0/x' WITH chunk_size = '0' FROM TABLE t`,
+			`invalid chunk size`},
+	} {
+		sqlDB.ExpectErr(t, tc.err, tc.stmt)
+	}
+}
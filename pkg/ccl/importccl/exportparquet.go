@@ -0,0 +1,319 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/cockroachdb/apd/v2"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowexec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding/parquet"
+	"github.com/cockroachdb/errors"
+)
+
+const exportParquetSuffix = ".parquet"
+
+// parquetEncodeFn converts a non-NULL datum to the value expected by
+// parquet.Writer for its column.
+type parquetEncodeFn func(tree.Datum) (interface{}, error)
+
+// newParquetColumn maps a SQL type to the Parquet column used to export it.
+// Types without a Parquet equivalent are exported as strings, in the same
+// format used by EXPORT INTO CSV.
+func newParquetColumn(name string, typ *types.T) (parquet.Column, parquetEncodeFn, error) {
+	col := parquet.Column{Name: name}
+	if typ.Family() == types.ArrayFamily {
+		elemCol, elemFn, err := newParquetColumn(name, typ.ArrayContents())
+		if err != nil {
+			return parquet.Column{}, nil, err
+		}
+		if elemCol.List {
+			return parquet.Column{}, nil, errors.Errorf(
+				"column %s: nested arrays are not supported by parquet exports", name)
+		}
+		elemCol.List = true
+		return elemCol, func(d tree.Datum) (interface{}, error) {
+			arr := d.(*tree.DArray)
+			elems := make([]interface{}, len(arr.Array))
+			for i, elem := range arr.Array {
+				if elem == tree.DNull {
+					continue
+				}
+				v, err := elemFn(elem)
+				if err != nil {
+					return nil, err
+				}
+				elems[i] = v
+			}
+			return elems, nil
+		}, nil
+	}
+
+	var fn parquetEncodeFn
+	switch typ.Family() {
+	case types.BoolFamily:
+		col.Type = parquet.Boolean
+		fn = func(d tree.Datum) (interface{}, error) {
+			return bool(*d.(*tree.DBool)), nil
+		}
+	case types.IntFamily:
+		switch typ.Width() {
+		case 16:
+			col.Type, col.Logical = parquet.Int32, parquet.LogicalInt16
+			fn = func(d tree.Datum) (interface{}, error) {
+				return int32(*d.(*tree.DInt)), nil
+			}
+		case 32:
+			col.Type = parquet.Int32
+			fn = func(d tree.Datum) (interface{}, error) {
+				return int32(*d.(*tree.DInt)), nil
+			}
+		default:
+			col.Type = parquet.Int64
+			fn = func(d tree.Datum) (interface{}, error) {
+				return int64(*d.(*tree.DInt)), nil
+			}
+		}
+	case types.FloatFamily:
+		if typ.Width() == 32 {
+			col.Type = parquet.Float
+			fn = func(d tree.Datum) (interface{}, error) {
+				return float32(*d.(*tree.DFloat)), nil
+			}
+		} else {
+			col.Type = parquet.Double
+			fn = func(d tree.Datum) (interface{}, error) {
+				return float64(*d.(*tree.DFloat)), nil
+			}
+		}
+	case types.DecimalFamily:
+		if typ.Precision() == 0 {
+			// Decimals without a fixed precision and scale can't be represented
+			// by the Parquet decimal type.
+			col.Type, col.Logical = parquet.ByteArray, parquet.LogicalString
+			fn = func(d tree.Datum) (interface{}, error) {
+				return []byte(d.(*tree.DDecimal).Decimal.String()), nil
+			}
+			break
+		}
+		col.Type, col.Logical = parquet.FixedLenByteArray, parquet.LogicalDecimal
+		col.Precision, col.Scale = typ.Precision(), typ.Width()
+		col.TypeLength = parquet.DecimalLength(col.Precision)
+		fn = func(d tree.Datum) (interface{}, error) {
+			unscaled, err := decimalUnscaledValue(&d.(*tree.DDecimal).Decimal, col.Scale)
+			if err != nil {
+				return nil, errors.Wrapf(err, "column %s", name)
+			}
+			return parquet.AppendDecimal(nil, unscaled, int(col.TypeLength))
+		}
+	case types.StringFamily:
+		col.Type, col.Logical = parquet.ByteArray, parquet.LogicalString
+		fn = func(d tree.Datum) (interface{}, error) {
+			return []byte(string(*d.(*tree.DString))), nil
+		}
+	case types.CollatedStringFamily:
+		col.Type, col.Logical = parquet.ByteArray, parquet.LogicalString
+		fn = func(d tree.Datum) (interface{}, error) {
+			return []byte(d.(*tree.DCollatedString).Contents), nil
+		}
+	case types.BytesFamily:
+		col.Type = parquet.ByteArray
+		fn = func(d tree.Datum) (interface{}, error) {
+			return []byte(*d.(*tree.DBytes)), nil
+		}
+	case types.DateFamily:
+		col.Type, col.Logical = parquet.Int32, parquet.LogicalDate
+		fn = func(d tree.Datum) (interface{}, error) {
+			date := d.(*tree.DDate).Date
+			if !date.IsFinite() {
+				return nil, errors.Errorf(
+					"column %s: infinite date not supported by parquet exports", name)
+			}
+			return int32(date.UnixEpochDays()), nil
+		}
+	case types.TimeFamily:
+		col.Type, col.Logical = parquet.Int64, parquet.LogicalTimeMicros
+		fn = func(d tree.Datum) (interface{}, error) {
+			return int64(*d.(*tree.DTime)), nil
+		}
+	case types.TimestampFamily:
+		col.Type, col.Logical = parquet.Int64, parquet.LogicalTimestampMicros
+		fn = func(d tree.Datum) (interface{}, error) {
+			t := d.(*tree.DTimestamp).Time
+			return t.Unix()*1e6 + int64(t.Nanosecond()/1e3), nil
+		}
+	case types.TimestampTZFamily:
+		col.Type, col.Logical = parquet.Int64, parquet.LogicalTimestampMicrosUTC
+		fn = func(d tree.Datum) (interface{}, error) {
+			t := d.(*tree.DTimestampTZ).Time
+			return t.Unix()*1e6 + int64(t.Nanosecond()/1e3), nil
+		}
+	case types.UuidFamily:
+		col.Type, col.Logical = parquet.FixedLenByteArray, parquet.LogicalUUID
+		col.TypeLength = 16
+		fn = func(d tree.Datum) (interface{}, error) {
+			return d.(*tree.DUuid).UUID.GetBytes(), nil
+		}
+	case types.JsonFamily:
+		col.Type, col.Logical = parquet.ByteArray, parquet.LogicalJSON
+		fn = func(d tree.Datum) (interface{}, error) {
+			return []byte(d.(*tree.DJSON).JSON.String()), nil
+		}
+	case types.EnumFamily:
+		col.Type, col.Logical = parquet.ByteArray, parquet.LogicalEnum
+		fn = func(d tree.Datum) (interface{}, error) {
+			return []byte(d.(*tree.DEnum).LogicalRep), nil
+		}
+	default:
+		col.Type, col.Logical = parquet.ByteArray, parquet.LogicalString
+		f := tree.NewFmtCtx(tree.FmtExport)
+		fn = func(d tree.Datum) (interface{}, error) {
+			f.Reset()
+			d.Format(f)
+			return []byte(f.String()), nil
+		}
+	}
+	return col, fn, nil
+}
+
+// decimalUnscaledValue returns the unscaled value of a decimal at the given
+// scale, erroring out if the decimal isn't finite or doesn't have an exact
+// representation at that scale.
+func decimalUnscaledValue(dec *apd.Decimal, scale int32) (*big.Int, error) {
+	if dec.Form != apd.Finite {
+		return nil, errors.Errorf("cannot export %s form decimal", dec.Form)
+	}
+	var scaled apd.Decimal
+	// Quantizing needs a context with a non-zero precision: with ExactCtx the
+	// result is NaN.
+	cond, err := tree.HighPrecisionCtx.Quantize(&scaled, dec, -scale)
+	if err != nil {
+		return nil, err
+	}
+	if cond.Inexact() || scaled.Form != apd.Finite {
+		return nil, errors.Errorf("%s cannot be exported at scale %d", dec, scale)
+	}
+	unscaled := new(big.Int).Set(&scaled.Coeff)
+	if scaled.Negative {
+		unscaled.Neg(unscaled)
+	}
+	return unscaled, nil
+}
+
+// parquetEncoder is an exportEncoder writing Parquet files, with a single
+// row group per file.
+type parquetEncoder struct {
+	cols      []parquet.Column
+	encodeFns []parquetEncodeFn
+	codec     parquet.Codec
+
+	buf    bytes.Buffer
+	writer *parquet.Writer
+	row    []interface{}
+}
+
+var _ exportEncoder = &parquetEncoder{}
+
+func newParquetEncoder(
+	colNames []string, typs []*types.T, compression execinfrapb.FileCompression,
+) (*parquetEncoder, error) {
+	e := &parquetEncoder{
+		cols:      make([]parquet.Column, len(typs)),
+		encodeFns: make([]parquetEncodeFn, len(typs)),
+		row:       make([]interface{}, len(typs)),
+	}
+	switch compression {
+	case execinfrapb.FileCompression_None:
+		e.codec = parquet.Uncompressed
+	case execinfrapb.FileCompression_Gzip:
+		e.codec = parquet.Gzip
+	case execinfrapb.FileCompression_Snappy:
+		e.codec = parquet.Snappy
+	default:
+		return nil, errors.Errorf("unsupported compression codec %s for parquet exports", compression)
+	}
+	for i, name := range exportColumnNames(colNames, len(typs)) {
+		var err error
+		if e.cols[i], e.encodeFns[i], err = newParquetColumn(name, typs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// Add implements the exportEncoder interface.
+func (e *parquetEncoder) Add(row tree.Datums) error {
+	if e.writer == nil {
+		e.buf.Reset()
+		var err error
+		if e.writer, err = parquet.NewWriter(&e.buf, e.cols, e.codec); err != nil {
+			return err
+		}
+	}
+	for i, d := range row {
+		if d == tree.DNull {
+			e.row[i] = nil
+			continue
+		}
+		v, err := e.encodeFns[i](d)
+		if err != nil {
+			return err
+		}
+		e.row[i] = v
+	}
+	return e.writer.AddRow(e.row)
+}
+
+// Size implements the exportEncoder interface.
+func (e *parquetEncoder) Size() int64 {
+	if e.writer == nil {
+		return 0
+	}
+	return int64(e.buf.Len()) + e.writer.BufferedSize()
+}
+
+// Finish implements the exportEncoder interface.
+func (e *parquetEncoder) Finish() ([]byte, error) {
+	if e.writer == nil {
+		return nil, errors.AssertionFailedf("no rows to finish")
+	}
+	err := e.writer.Close()
+	e.writer = nil
+	return e.buf.Bytes(), err
+}
+
+func newParquetWriterProcessor(
+	flowCtx *execinfra.FlowCtx,
+	processorID int32,
+	spec execinfrapb.ParquetWriterSpec,
+	input execinfra.RowSource,
+	output execinfra.RowReceiver,
+) (execinfra.Processor, error) {
+	encoder, err := newParquetEncoder(spec.ColNames, input.OutputTypes(), spec.CompressionCodec)
+	if err != nil {
+		return nil, err
+	}
+	return newExportFileWriter("parquetWriter", flowCtx, processorID, exportFileSpec{
+		destination: spec.Destination,
+		namePattern: spec.NamePattern,
+		fileSuffix:  exportParquetSuffix,
+		chunkRows:   spec.ChunkRows,
+		chunkSize:   spec.ChunkSize,
+		user:        spec.User,
+	}, encoder, input, output)
+}
+
+func init() {
+	rowexec.NewParquetWriterProcessor = newParquetWriterProcessor
+}
@@ -117,6 +117,7 @@ func (cp *readImportDataProcessor) Run(ctx context.Context) {
 
 func makeInputConverter(
 	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
 	spec *execinfrapb.ReadImportDataSpec,
 	evalCtx *tree.EvalContext,
 	kvCh chan row.KVBatch,
@@ -177,6 +178,14 @@ func makeInputConverter(
 		return newAvroInputReader(
 			kvCh, singleTable, spec.Format.Avro, spec.WalltimeNanos,
 			int(spec.ReaderParallelism), evalCtx)
+	case roachpb.IOFileFormat_Parquet:
+		return newParquetInputReader(
+			kvCh, singleTable, spec.Format.Parquet, spec.WalltimeNanos,
+			int(spec.ReaderParallelism), singleTableTargetCols, evalCtx, flowCtx), nil
+	case roachpb.IOFileFormat_JSON:
+		return newJSONInputReader(
+			kvCh, singleTable, spec.Format.Json, spec.WalltimeNanos,
+			int(spec.ReaderParallelism), singleTableTargetCols, evalCtx), nil
 	default:
 		return nil, errors.Errorf(
 			"Requested IMPORT format (%d) not supported by this node", spec.Format.Format)
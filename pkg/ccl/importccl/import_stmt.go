@@ -82,7 +82,7 @@ const (
 
 	optMaxRowSize = "max_row_size"
 
-	// Turn on strict validation when importing avro records.
+	// Turn on strict validation when importing avro, parquet or JSON records.
 	avroStrict = "strict_validation"
 	// Default input format is assumed to be OCF (object container file).
 	// This default can be changed by specified either of these options.
@@ -144,6 +144,8 @@ var avroAllowedOptions = makeStringSet(
 	avroStrict, avroBinRecords, avroJSONRecords,
 	avroRecordsSeparatedBy, avroSchema, avroSchemaURI, optMaxRowSize,
 )
+var parquetAllowedOptions = makeStringSet(avroStrict)
+var jsonAllowedOptions = makeStringSet(avroStrict, optMaxRowSize)
 var csvAllowedOptions = makeStringSet(
 	csvDelimiter, csvComment, csvNullIf, csvSkip, csvStrictQuotes,
 )
@@ -165,6 +167,8 @@ var allowedIntoFormats = map[string]struct{}{
 	"AVRO":      {},
 	"DELIMITED": {},
 	"PGCOPY":    {},
+	"PARQUET":   {},
+	"JSON":      {},
 }
 
 func validateFormatOptions(
@@ -565,6 +569,36 @@ func importPlanHook(
 			if err != nil {
 				return err
 			}
+		case "PARQUET":
+			if err = validateFormatOptions(importStmt.FileFormat, opts, parquetAllowedOptions); err != nil {
+				return err
+			}
+			format.Format = roachpb.IOFileFormat_Parquet
+			_, format.Parquet.StrictMode = opts[avroStrict]
+			if _, ok := opts[importOptionSaveRejected]; ok {
+				format.SaveRejected = true
+			}
+		case "JSON":
+			if err = validateFormatOptions(importStmt.FileFormat, opts, jsonAllowedOptions); err != nil {
+				return err
+			}
+			format.Format = roachpb.IOFileFormat_JSON
+			_, format.Json.StrictMode = opts[avroStrict]
+			maxRowSize := int32(defaultScanBuffer)
+			if override, ok := opts[optMaxRowSize]; ok {
+				sz, err := humanizeutil.ParseBytes(override)
+				if err != nil {
+					return err
+				}
+				if sz < 1 || sz > math.MaxInt32 {
+					return errors.Errorf("%d out of range: %d", maxRowSize, sz)
+				}
+				maxRowSize = int32(sz)
+			}
+			format.Json.MaxRowSize = maxRowSize
+			if _, ok := opts[importOptionSaveRejected]; ok {
+				format.SaveRejected = true
+			}
 		default:
 			return unimplemented.Newf("import.format", "unsupported import format: %q", importStmt.FileFormat)
 		}
@@ -57,6 +57,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding/parquet"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -5380,6 +5381,65 @@ func TestImportAvro(t *testing.T) {
 	})
 }
 
+func TestImportParquetAndJSON(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	baseDir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	tc := testcluster.StartTestCluster(
+		t, 1, base.TestClusterArgs{ServerArgs: base.TestServerArgs{ExternalIODir: baseDir}})
+	defer tc.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(tc.Conns[0])
+
+	// Write a Parquet file with several row groups, which are read in parallel.
+	var buf bytes.Buffer
+	w, err := parquet.NewWriter(&buf, []parquet.Column{
+		{Name: "id", Type: parquet.Int64},
+		{Name: "name", Type: parquet.ByteArray, Logical: parquet.LogicalString},
+		{Name: "tags", Type: parquet.ByteArray, Logical: parquet.LogicalString, List: true},
+	}, parquet.Snappy)
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, w.AddRow([]interface{}{
+			int64(i), []byte(fmt.Sprint("name", i)), []interface{}{[]byte("a"), nil},
+		}))
+		if i%10 == 9 {
+			require.NoError(t, w.Flush())
+		}
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, ioutil.WriteFile(filepath.Join(baseDir, "data.parquet"), buf.Bytes(), 0644))
+
+	jsonData := `{"id": 1, "name": "a", "tags": ["x"]}
+{"id": "two", "name": "b"}
+{"id": 3, "tags": null}
+`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(baseDir, "data.ndjson"), []byte(jsonData), 0644))
+
+	sqlDB.Exec(t, `CREATE TABLE t (id INT PRIMARY KEY, name STRING, tags STRING[])`)
+	sqlDB.Exec(t, `IMPORT INTO t PARQUET DATA ('nodelocal://0/data.parquet') WITH strict_validation`)
+	sqlDB.CheckQueryResults(t,
+		`SELECT count(*), sum(id), count(DISTINCT name), min(tags::STRING) FROM t`,
+		[][]string{{"100", "4950", "100", "{a,NULL}"}})
+
+	sqlDB.Exec(t, `CREATE TABLE j (id INT PRIMARY KEY, name STRING, tags STRING[])`)
+	sqlDB.ExpectErr(t, `could not parse "two" as type int`,
+		`IMPORT INTO j JSON DATA ('nodelocal://0/data.ndjson')`)
+	sqlDB.Exec(t, `IMPORT INTO j JSON DATA ('nodelocal://0/data.ndjson') WITH experimental_save_rejected`)
+	sqlDB.CheckQueryResults(t, `SELECT * FROM j`, [][]string{
+		{"1", "a", "{x}"},
+		{"3", "NULL", "NULL"},
+	})
+	rejected, err := ioutil.ReadFile(filepath.Join(baseDir, "data.ndjson.rejected"))
+	require.NoError(t, err)
+	require.Equal(t, "{\"id\": \"two\", \"name\": \"b\"}\n", string(rejected))
+
+	sqlDB.ExpectErr(t, `invalid option "delimiter"`,
+		`IMPORT INTO j JSON DATA ('nodelocal://0/data.ndjson') WITH delimiter = '|'`)
+}
+
 // TestImportClientDisconnect ensures that an import job can complete even if
 // the client connection which started it closes. This test uses a helper
 // subprocess to force a closed client connection without needing to rely
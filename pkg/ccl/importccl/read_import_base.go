@@ -68,7 +68,7 @@ func runImport(
 		flowCtx.TypeResolverFactory.Descriptors.ReleaseAll(ctx)
 	}
 
-	conv, err := makeInputConverter(ctx, spec, flowCtx.NewEvalCtx(), kvCh)
+	conv, err := makeInputConverter(ctx, flowCtx, spec, flowCtx.NewEvalCtx(), kvCh)
 	if err != nil {
 		return nil, err
 	}
@@ -207,7 +207,9 @@ func readInputFiles(
 
 			var rejected chan string
 			if (format.Format == roachpb.IOFileFormat_CSV && format.SaveRejected) ||
-				(format.Format == roachpb.IOFileFormat_MysqlOutfile && format.SaveRejected) {
+				(format.Format == roachpb.IOFileFormat_MysqlOutfile && format.SaveRejected) ||
+				(format.Format == roachpb.IOFileFormat_Parquet && format.SaveRejected) ||
+				(format.Format == roachpb.IOFileFormat_JSON && format.SaveRejected) {
 				rejected = make(chan string)
 			}
 			if rejected != nil {
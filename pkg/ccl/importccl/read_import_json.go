@@ -0,0 +1,253 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/errors"
+)
+
+// jsonToDatum converts a JSON value to a datum of the given type. JSONB
+// columns hold the value as is. Other columns take strings, numbers and
+// booleans, which are parsed as the column's type, and arrays, whose elements
+// are converted to the column's element type.
+func jsonToDatum(j json.JSON, typ *types.T, evalCtx *tree.EvalContext) (tree.Datum, error) {
+	if j.Type() == json.NullJSONType {
+		return tree.DNull, nil
+	}
+	if typ.Family() == types.JsonFamily {
+		return tree.NewDJSON(j), nil
+	}
+	switch j.Type() {
+	case json.ArrayJSONType:
+		if typ.Family() != types.ArrayFamily {
+			return nil, errors.Errorf("cannot convert array to %s", typ.SQLString())
+		}
+		arr := tree.NewDArray(typ.ArrayContents())
+		for i := 0; i < j.Len(); i++ {
+			elem, err := j.FetchValIdx(i)
+			if err != nil {
+				return nil, err
+			}
+			d, err := jsonToDatum(elem, typ.ArrayContents(), evalCtx)
+			if err != nil {
+				return nil, err
+			}
+			if err := arr.Append(d); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	case json.ObjectJSONType:
+		return nil, errors.Errorf("cannot convert object to %s", typ.SQLString())
+	}
+	s, err := j.AsText()
+	if err != nil {
+		return nil, err
+	}
+	return rowenc.ParseDatumStringAs(typ, *s, evalCtx)
+}
+
+// jsonConsumer implements importRowConsumer interface. The rows it consumes
+// are lines holding a JSON object, whose keys are mapped to the columns of
+// the table by name.
+type jsonConsumer struct {
+	fieldNameToIdx map[string]int
+	strict         bool
+}
+
+var _ importRowConsumer = &jsonConsumer{}
+
+func (c *jsonConsumer) convertObject(line string, conv *row.DatumRowConverter) error {
+	j, err := json.ParseJSON(line)
+	if err != nil {
+		return err
+	}
+	it, err := j.ObjectIter()
+	if err != nil {
+		return err
+	}
+	if it == nil {
+		return errors.New("expected a JSON object")
+	}
+	for it.Next() {
+		name := lex.NormalizeName(it.Key())
+		idx, ok := c.fieldNameToIdx[name]
+		if _, isTargetCol := conv.IsTargetCol[idx]; !ok || !isTargetCol {
+			if c.strict {
+				return errors.Errorf("could not find column for key %s", name)
+			}
+			continue
+		}
+		d, err := jsonToDatum(it.Value(), conv.VisibleColTypes[idx], conv.EvalCtx)
+		if err != nil {
+			return errors.Wrapf(err, "converting key %s to %s",
+				name, conv.VisibleColTypes[idx].SQLString())
+		}
+		conv.Datums[idx] = d
+	}
+	return nil
+}
+
+// FillDatums implements importRowConsumer interface.
+func (c *jsonConsumer) FillDatums(
+	native interface{}, rowNum int64, conv *row.DatumRowConverter,
+) error {
+	line := native.(string)
+	for i := range conv.Datums {
+		if _, isTargetCol := conv.IsTargetCol[i]; isTargetCol {
+			conv.Datums[i] = nil
+		}
+	}
+	if err := c.convertObject(line, conv); err != nil {
+		return newImportRowError(err, line, rowNum)
+	}
+
+	// Set any nil datums to DNull, in case the object didn't have a key for
+	// the column.
+	for i := range conv.Datums {
+		if _, isTargetCol := conv.IsTargetCol[i]; isTargetCol && conv.Datums[i] == nil {
+			if c.strict {
+				return newImportRowError(
+					errors.Errorf("column %s was not set", conv.VisibleCols[i].Name), line, rowNum)
+			}
+			conv.Datums[i] = tree.DNull
+		}
+	}
+	return nil
+}
+
+// jsonLineStream produces the lines of a newline-delimited JSON file. Blank
+// lines are skipped.
+type jsonLineStream struct {
+	input *fileReader
+	s     *bufio.Scanner
+	err   error
+}
+
+var _ importRowProducer = &jsonLineStream{}
+
+// Scan implements importRowProducer interface.
+func (j *jsonLineStream) Scan() bool {
+	for j.s.Scan() {
+		if len(bytes.TrimSpace(j.s.Bytes())) > 0 {
+			return true
+		}
+	}
+	if err := j.s.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			err = wrapWithLineTooLongHint(errors.New("line too long"))
+		}
+		j.err = err
+	}
+	return false
+}
+
+// Err implements importRowProducer interface.
+func (j *jsonLineStream) Err() error {
+	return j.err
+}
+
+// Skip implements importRowProducer interface.
+func (j *jsonLineStream) Skip() error {
+	return nil
+}
+
+// Row implements importRowProducer interface.
+func (j *jsonLineStream) Row() (interface{}, error) {
+	return j.s.Text(), nil
+}
+
+// Progress implements importRowProducer interface.
+func (j *jsonLineStream) Progress() float32 {
+	return j.input.ReadFraction()
+}
+
+type jsonInputReader struct {
+	importCtx *parallelImportContext
+	opts      roachpb.JSONOptions
+}
+
+var _ inputConverter = &jsonInputReader{}
+
+func newJSONInputReader(
+	kvCh chan row.KVBatch,
+	tableDesc *tabledesc.Immutable,
+	opts roachpb.JSONOptions,
+	walltime int64,
+	parallelism int,
+	targetCols tree.NameList,
+	evalCtx *tree.EvalContext,
+) *jsonInputReader {
+	return &jsonInputReader{
+		importCtx: &parallelImportContext{
+			walltime:   walltime,
+			numWorkers: parallelism,
+			evalCtx:    evalCtx,
+			tableDesc:  tableDesc,
+			targetCols: targetCols,
+			kvCh:       kvCh,
+		},
+		opts: opts,
+	}
+}
+
+func (j *jsonInputReader) start(group ctxgroup.Group) {}
+
+func (j *jsonInputReader) readFiles(
+	ctx context.Context,
+	dataFiles map[int32]string,
+	resumePos map[int32]int64,
+	format roachpb.IOFileFormat,
+	makeExternalStorage cloud.ExternalStorageFactory,
+	user string,
+) error {
+	return readInputFiles(ctx, dataFiles, resumePos, format, j.readFile, makeExternalStorage, user)
+}
+
+func (j *jsonInputReader) readFile(
+	ctx context.Context, input *fileReader, inputIdx int32, resumePos int64, rejected chan string,
+) error {
+	fieldNameToIdx := make(map[string]int)
+	for idx, col := range j.importCtx.tableDesc.VisibleColumns() {
+		fieldNameToIdx[col.Name] = idx
+	}
+	consumer := &jsonConsumer{
+		fieldNameToIdx: fieldNameToIdx,
+		strict:         j.opts.StrictMode,
+	}
+
+	maxRowSize := int(j.opts.MaxRowSize)
+	if maxRowSize <= 0 {
+		maxRowSize = defaultScanBuffer
+	}
+	s := bufio.NewScanner(input)
+	s.Buffer(nil, maxRowSize)
+	producer := &jsonLineStream{input: input, s: s}
+
+	fileCtx := &importFileContext{
+		source:   inputIdx,
+		skip:     resumePos,
+		rejected: rejected,
+	}
+	return runParallelImport(ctx, j.importCtx, fileCtx, producer, consumer)
+}
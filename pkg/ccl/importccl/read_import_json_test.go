@@ -0,0 +1,155 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONToDatum(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	evalCtx := tree.MakeTestingEvalContext(cluster.MakeTestingClusterSettings())
+	for _, tc := range []struct {
+		json     string
+		typ      *types.T
+		expected string
+		err      string
+	}{
+		{json: `null`, typ: types.Int, expected: `NULL`},
+		{json: `12`, typ: types.Int, expected: `12`},
+		{json: `"12"`, typ: types.Int, expected: `12`},
+		{json: `1.5`, typ: types.Decimal, expected: `1.5`},
+		{json: `true`, typ: types.Bool, expected: `true`},
+		{json: `12`, typ: types.String, expected: `12`},
+		{json: `"2020-01-02 03:04:05"`, typ: types.Timestamp, expected: `2020-01-02 03:04:05`},
+		{json: `{"a": [1, null]}`, typ: types.Jsonb, expected: `'{"a": [1, null]}'`},
+		{json: `"a"`, typ: types.Jsonb, expected: `"a"`},
+		{json: `[1, null, "3"]`, typ: types.IntArray, expected: `ARRAY[1,NULL,3]`},
+		{json: `[1]`, typ: types.Int, err: `cannot convert array to INT8`},
+		{json: `{}`, typ: types.String, err: `cannot convert object to STRING`},
+		{json: `1.5`, typ: types.Int, err: `could not parse`},
+	} {
+		t.Run(tc.json+"-"+tc.typ.SQLString(), func(t *testing.T) {
+			j, err := json.ParseJSON(tc.json)
+			require.NoError(t, err)
+			d, err := jsonToDatum(j, tc.typ, &evalCtx)
+			if tc.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, tree.AsStringWithFlags(d, tree.FmtBareStrings))
+		})
+	}
+}
+
+// readJSONRows imports newline-delimited JSON with a jsonInputReader's
+// pipeline, returning the datums of each row.
+func readJSONRows(
+	t *testing.T,
+	tableDesc *tabledesc.Immutable,
+	input string,
+	strict bool,
+	maxRowSize int,
+	evalCtx *tree.EvalContext,
+) ([]tree.Datums, error) {
+	// Ensure datum converter doesn't flush (since we're using nil kv channel
+	// for this test).
+	defer row.TestingSetDatumRowConverterBatchSize(1 << 20)()
+
+	fieldNameToIdx := make(map[string]int)
+	for idx, col := range tableDesc.VisibleColumns() {
+		fieldNameToIdx[col.Name] = idx
+	}
+	consumer := &jsonConsumer{fieldNameToIdx: fieldNameToIdx, strict: strict}
+	reader := &fileReader{Reader: strings.NewReader(input)}
+	s := bufio.NewScanner(reader)
+	s.Buffer(nil, maxRowSize)
+	producer := &jsonLineStream{input: reader, s: s}
+	conv, err := row.NewDatumRowConverter(context.Background(), tableDesc, nil, evalCtx.Copy(), nil)
+	require.NoError(t, err)
+
+	var rows []tree.Datums
+	for producer.Scan() {
+		line, err := producer.Row()
+		require.NoError(t, err)
+		if err := consumer.FillDatums(line, int64(len(rows)+1), conv); err != nil {
+			return rows, err
+		}
+		rows = append(rows, append(tree.Datums(nil), conv.Datums...))
+	}
+	return rows, producer.Err()
+}
+
+func TestJSONInputReader(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	evalCtx := tree.MakeTestingEvalContext(cluster.MakeTestingClusterSettings())
+	tableDesc := descForTable(ctx, t,
+		"CREATE TABLE t (id INT PRIMARY KEY, name STRING, j JSONB)",
+		10, 20, NoFKs).ImmutableCopy().(*tabledesc.Immutable)
+
+	// Keys are mapped to columns by name after being normalized, and blank
+	// lines are skipped. Columns without a key are NULL.
+	input := `{"id": 1, "Name": "a", "j": {"k": [1]}}
+
+{"id": 2, "extra": true}
+  {"j": null, "id": "3", "name": "c"}
+`
+	rows, err := readJSONRows(t, tableDesc, input, false /* strict */, defaultScanBuffer, &evalCtx)
+	require.NoError(t, err)
+	var actual []string
+	for _, row := range rows {
+		actual = append(actual, tree.AsString(&tree.DTuple{D: row}))
+	}
+	require.Equal(t, []string{
+		`(1, 'a', '{"k": [1]}')`,
+		`(2, NULL, NULL)`,
+		`(3, 'c', NULL)`,
+	}, actual)
+
+	// Strict validation rejects unknown keys and missing columns.
+	_, err = readJSONRows(t, tableDesc, `{"id": 1, "name": "a", "j": 1, "x": 1}`, true, defaultScanBuffer, &evalCtx)
+	require.EqualError(t, err,
+		`error parsing row 1: could not find column for key x (row: "{\"id\": 1, \"name\": \"a\", \"j\": 1, \"x\": 1}")`)
+	_, err = readJSONRows(t, tableDesc, `{"id": 1, "name": "a"}`, true, defaultScanBuffer, &evalCtx)
+	require.EqualError(t, err,
+		`error parsing row 1: column j was not set (row: "{\"id\": 1, \"name\": \"a\"}")`)
+
+	// Lines which can't be converted are reported as import row errors, which
+	// are saved if experimental_save_rejected is specified.
+	for _, line := range []string{`{"id": "x"}`, `[1]`, `{"id": 1`} {
+		_, err := readJSONRows(t, tableDesc, line, false, defaultScanBuffer, &evalCtx)
+		var rowErr *importRowError
+		require.True(t, errors.As(err, &rowErr), "%+v", err)
+		require.Equal(t, line, rowErr.row)
+	}
+
+	_, err = readJSONRows(t, tableDesc, `{"id": 1, "name": "long"}`, false, 10, &evalCtx)
+	require.EqualError(t, err, "line too long")
+}
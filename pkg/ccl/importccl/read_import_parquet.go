@@ -0,0 +1,655 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/cockroachdb/apd/v2"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding/parquet"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// parquetDecodeFn converts a non-NULL value read from a Parquet column to a
+// datum.
+type parquetDecodeFn func(v interface{}, evalCtx *tree.EvalContext) (tree.Datum, error)
+
+// newParquetNativeDecoder returns the function converting the values of a
+// Parquet column to the datums of the SQL type closest to the column's type,
+// along with that type. It is the inverse of newParquetColumn.
+func newParquetNativeDecoder(col parquet.Column) (parquetDecodeFn, *types.T, error) {
+	if col.List {
+		elemCol := col
+		elemCol.List = false
+		elemFn, elemT, err := newParquetNativeDecoder(elemCol)
+		if err != nil {
+			return nil, nil, err
+		}
+		return func(v interface{}, evalCtx *tree.EvalContext) (tree.Datum, error) {
+			arr := tree.NewDArray(elemT)
+			for _, elem := range v.([]interface{}) {
+				d := tree.DNull
+				if elem != nil {
+					var err error
+					if d, err = elemFn(elem, evalCtx); err != nil {
+						return nil, err
+					}
+				}
+				if err := arr.Append(d); err != nil {
+					return nil, err
+				}
+			}
+			return arr, nil
+		}, types.MakeArray(elemT), nil
+	}
+
+	switch col.Type {
+	case parquet.Boolean:
+		return func(v interface{}, _ *tree.EvalContext) (tree.Datum, error) {
+			return tree.MakeDBool(tree.DBool(v.(bool))), nil
+		}, types.Bool, nil
+
+	case parquet.Int32, parquet.Int64:
+		// Widen the values of both physical types to int64 so that the logical
+		// types which may annotate either only need to be handled once.
+		asInt64 := func(v interface{}) int64 {
+			if i, ok := v.(int32); ok {
+				if col.Logical == parquet.LogicalUnsigned {
+					return int64(uint32(i))
+				}
+				return int64(i)
+			}
+			return v.(int64)
+		}
+		switch col.Logical {
+		case parquet.LogicalDate:
+			return func(v interface{}, _ *tree.EvalContext) (tree.Datum, error) {
+				date, err := pgdate.MakeDateFromUnixEpoch(asInt64(v))
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDDate(date), nil
+			}, types.Date, nil
+		case parquet.LogicalTimeMillis, parquet.LogicalTimeMicros, parquet.LogicalTimeNanos:
+			unit := parquetTimeUnit(col.Logical)
+			return func(v interface{}, _ *tree.EvalContext) (tree.Datum, error) {
+				micros := asInt64(v) * int64(unit) / int64(time.Microsecond)
+				return tree.MakeDTime(timeofday.FromInt(micros)), nil
+			}, types.Time, nil
+		case parquet.LogicalTimestampMillis, parquet.LogicalTimestampMicros,
+			parquet.LogicalTimestampNanos:
+			unit := parquetTimeUnit(col.Logical)
+			return func(v interface{}, _ *tree.EvalContext) (tree.Datum, error) {
+				return tree.MakeDTimestamp(parquetTime(asInt64(v), unit), time.Microsecond)
+			}, types.Timestamp, nil
+		case parquet.LogicalTimestampMillisUTC, parquet.LogicalTimestampMicrosUTC,
+			parquet.LogicalTimestampNanosUTC:
+			unit := parquetTimeUnit(col.Logical)
+			return func(v interface{}, _ *tree.EvalContext) (tree.Datum, error) {
+				return tree.MakeDTimestampTZ(parquetTime(asInt64(v), unit), time.Microsecond)
+			}, types.TimestampTZ, nil
+		case parquet.LogicalDecimal:
+			return func(v interface{}, _ *tree.EvalContext) (tree.Datum, error) {
+				return parquetDecimal(big.NewInt(asInt64(v)), col.Scale), nil
+			}, types.MakeDecimal(col.Precision, col.Scale), nil
+		case parquet.LogicalInt16:
+			return func(v interface{}, _ *tree.EvalContext) (tree.Datum, error) {
+				return tree.NewDInt(tree.DInt(asInt64(v))), nil
+			}, types.Int2, nil
+		}
+		if col.Type == parquet.Int32 && col.Logical != parquet.LogicalUnsigned {
+			return func(v interface{}, _ *tree.EvalContext) (tree.Datum, error) {
+				return tree.NewDInt(tree.DInt(v.(int32))), nil
+			}, types.Int4, nil
+		}
+		unsigned64 := col.Type == parquet.Int64 && col.Logical == parquet.LogicalUnsigned
+		return func(v interface{}, _ *tree.EvalContext) (tree.Datum, error) {
+			i := asInt64(v)
+			if unsigned64 && i < 0 {
+				return nil, errors.Errorf("unsigned value %d out of range for INT8", uint64(i))
+			}
+			return tree.NewDInt(tree.DInt(i)), nil
+		}, types.Int, nil
+
+	case parquet.Int96:
+		return func(v interface{}, _ *tree.EvalContext) (tree.Datum, error) {
+			return tree.MakeDTimestamp(parquet.Int96Time(v.([]byte)), time.Microsecond)
+		}, types.Timestamp, nil
+
+	case parquet.Float:
+		return func(v interface{}, _ *tree.EvalContext) (tree.Datum, error) {
+			return tree.NewDFloat(tree.DFloat(v.(float32))), nil
+		}, types.Float4, nil
+
+	case parquet.Double:
+		return func(v interface{}, _ *tree.EvalContext) (tree.Datum, error) {
+			return tree.NewDFloat(tree.DFloat(v.(float64))), nil
+		}, types.Float, nil
+
+	case parquet.ByteArray, parquet.FixedLenByteArray:
+		switch col.Logical {
+		case parquet.LogicalString, parquet.LogicalEnum:
+			return func(v interface{}, _ *tree.EvalContext) (tree.Datum, error) {
+				return tree.NewDString(string(v.([]byte))), nil
+			}, types.String, nil
+		case parquet.LogicalJSON:
+			return func(v interface{}, _ *tree.EvalContext) (tree.Datum, error) {
+				return tree.ParseDJSON(string(v.([]byte)))
+			}, types.Jsonb, nil
+		case parquet.LogicalUUID:
+			return func(v interface{}, _ *tree.EvalContext) (tree.Datum, error) {
+				u, err := uuid.FromBytes(v.([]byte))
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDUuid(tree.DUuid{UUID: u}), nil
+			}, types.Uuid, nil
+		case parquet.LogicalDecimal:
+			return func(v interface{}, _ *tree.EvalContext) (tree.Datum, error) {
+				return parquetDecimal(parquetBigInt(v.([]byte)), col.Scale), nil
+			}, types.MakeDecimal(col.Precision, col.Scale), nil
+		}
+		return func(v interface{}, _ *tree.EvalContext) (tree.Datum, error) {
+			return tree.NewDBytes(tree.DBytes(v.([]byte))), nil
+		}, types.Bytes, nil
+	}
+	return nil, nil, errors.Errorf("column %s has an unsupported type", col.Name)
+}
+
+// newParquetDecoder returns the function converting the values of a Parquet
+// column to datums of the given type. Strings and byte arrays are parsed as
+// the target type, like the values of every other text-based format; other
+// values are cast to it.
+func newParquetDecoder(col parquet.Column, typ *types.T) (parquetDecodeFn, error) {
+	fn, nativeT, err := newParquetNativeDecoder(col)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case typ.Equivalent(nativeT):
+		return fn, nil
+	case !col.List && (nativeT.Family() == types.StringFamily || nativeT.Family() == types.BytesFamily):
+		return func(v interface{}, evalCtx *tree.EvalContext) (tree.Datum, error) {
+			return rowenc.ParseDatumStringAs(typ, string(v.([]byte)), evalCtx)
+		}, nil
+	case col.List && typ.Family() != types.ArrayFamily:
+		return nil, errors.Errorf("cannot convert list column %s to %s", col.Name, typ.SQLString())
+	}
+	return func(v interface{}, evalCtx *tree.EvalContext) (tree.Datum, error) {
+		d, err := fn(v, evalCtx)
+		if err != nil {
+			return nil, err
+		}
+		return tree.PerformCast(evalCtx, d, typ)
+	}, nil
+}
+
+// parquetTimeUnit returns the unit of the values of a time or timestamp
+// logical type.
+func parquetTimeUnit(logical parquet.LogicalType) time.Duration {
+	switch logical {
+	case parquet.LogicalTimeMillis, parquet.LogicalTimestampMillis, parquet.LogicalTimestampMillisUTC:
+		return time.Millisecond
+	case parquet.LogicalTimeNanos, parquet.LogicalTimestampNanos, parquet.LogicalTimestampNanosUTC:
+		return time.Nanosecond
+	default:
+		return time.Microsecond
+	}
+}
+
+// parquetTime returns the time v units after the Unix epoch.
+func parquetTime(v int64, unit time.Duration) time.Time {
+	perSecond := int64(time.Second / unit)
+	sec, frac := v/perSecond, v%perSecond
+	if frac < 0 {
+		sec, frac = sec-1, frac+perSecond
+	}
+	return time.Unix(sec, frac*int64(unit)).UTC()
+}
+
+// parquetBigInt decodes a big-endian two's complement integer.
+func parquetBigInt(b []byte) *big.Int {
+	i := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		i.Sub(i, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+	}
+	return i
+}
+
+func parquetDecimal(unscaled *big.Int, scale int32) tree.Datum {
+	return &tree.DDecimal{Decimal: *apd.NewWithBigInt(unscaled, -scale)}
+}
+
+// parquetRowString renders a row of a Parquet file as a JSON object, which is
+// how rows which can't be imported are saved when experimental_save_rejected
+// is specified. The saved rows can be imported using the JSON format.
+func parquetRowString(
+	cols []parquet.Column, natives []parquetDecodeFn, values []interface{}, evalCtx *tree.EvalContext,
+) string {
+	b := json.NewObjectBuilder(len(cols))
+	for i, v := range values {
+		var j json.JSON = json.NullJSONValue
+		if v != nil {
+			j = json.FromString(fmt.Sprint(v))
+			if d, err := natives[i](v, evalCtx); err == nil {
+				if dj, err := tree.AsJSON(d, time.UTC); err == nil {
+					j = dj
+				}
+			}
+		}
+		b.Add(cols[i].Name, j)
+	}
+	return b.Build().String()
+}
+
+// parquetConsumer implements importRowConsumer interface. The rows it
+// consumes hold the values of the Parquet columns which map to a column of
+// the table.
+type parquetConsumer struct {
+	// cols are the Parquet columns which are read, and colIdx the indexes of
+	// the visible table columns they map to.
+	cols    []parquet.Column
+	colIdx  []int
+	decode  []parquetDecodeFn
+	natives []parquetDecodeFn
+	strict  bool
+}
+
+var _ importRowConsumer = &parquetConsumer{}
+
+// FillDatums implements importRowConsumer interface.
+func (p *parquetConsumer) FillDatums(
+	native interface{}, rowNum int64, conv *row.DatumRowConverter,
+) error {
+	values := native.([]interface{})
+	for i := range conv.Datums {
+		if _, isTargetCol := conv.IsTargetCol[i]; isTargetCol {
+			conv.Datums[i] = tree.DNull
+		}
+	}
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		d, err := p.decode[i](v, conv.EvalCtx)
+		if err != nil {
+			col := conv.VisibleCols[p.colIdx[i]]
+			return newImportRowError(
+				errors.Wrapf(err, "converting column %s to %s", p.cols[i].Name, col.Type.SQLString()),
+				parquetRowString(p.cols, p.natives, values, conv.EvalCtx), rowNum)
+		}
+		conv.Datums[p.colIdx[i]] = d
+	}
+	return nil
+}
+
+// parquetRowGroup is the result of decoding a row group.
+type parquetRowGroup struct {
+	rows [][]interface{}
+	err  error
+}
+
+// parquetStream produces the rows of a Parquet file. Row groups are decoded
+// in the background, up to numWorkers of them at a time, and their rows are
+// produced in order.
+type parquetStream struct {
+	ctx        context.Context
+	reader     *parquet.Reader
+	cols       []int
+	numWorkers int
+	// acc, if set, is charged for the row groups being decoded or produced.
+	acc *mon.BoundAccount
+	// pending holds the row groups being decoded, in order.
+	pending []chan parquetRowGroup
+	// reserved holds the memory reserved for the pending row groups, in order,
+	// and for the row group whose rows are being produced, at the end.
+	reserved []int64
+	// next is the next row group to decode, and done the number of row groups
+	// whose rows have been produced.
+	next, done int
+	rows       [][]interface{}
+	err        error
+}
+
+var _ importRowProducer = &parquetStream{}
+
+// decodeNext starts decoding the next row group, after reserving memory for
+// its rows.
+func (p *parquetStream) decodeNext() error {
+	size := p.reader.RowGroupSize(p.next)
+	if p.acc != nil {
+		if err := p.acc.Grow(p.ctx, size); err != nil {
+			return errors.Wrapf(err, "decoding parquet row group %d", p.next)
+		}
+	}
+	p.reserved = append(p.reserved, size)
+	ch := make(chan parquetRowGroup, 1)
+	go func(idx int) {
+		rows, err := p.reader.ReadRowGroup(idx, p.cols)
+		ch <- parquetRowGroup{rows: rows, err: errors.Wrapf(err, "row group %d", idx)}
+	}(p.next)
+	p.pending = append(p.pending, ch)
+	p.next++
+	return nil
+}
+
+// Scan implements importRowProducer interface.
+func (p *parquetStream) Scan() bool {
+	for len(p.rows) == 0 {
+		// The rows of the previous row group have all been produced.
+		if len(p.reserved) > len(p.pending) {
+			if p.acc != nil {
+				p.acc.Shrink(p.ctx, p.reserved[0])
+			}
+			p.reserved = p.reserved[1:]
+		}
+		for p.next < p.reader.NumRowGroups() && len(p.pending) < p.numWorkers {
+			if err := p.decodeNext(); err != nil {
+				p.err = err
+				return false
+			}
+		}
+		if len(p.pending) == 0 {
+			return false
+		}
+		select {
+		case <-p.ctx.Done():
+			p.err = p.ctx.Err()
+			return false
+		case rg := <-p.pending[0]:
+			p.pending = p.pending[1:]
+			p.done++
+			if rg.err != nil {
+				p.err = rg.err
+				return false
+			}
+			p.rows = rg.rows
+		}
+	}
+	return true
+}
+
+// Err implements importRowProducer interface.
+func (p *parquetStream) Err() error {
+	return p.err
+}
+
+// Skip implements importRowProducer interface.
+func (p *parquetStream) Skip() error {
+	p.rows = p.rows[1:]
+	return nil
+}
+
+// Row implements importRowProducer interface.
+func (p *parquetStream) Row() (interface{}, error) {
+	row := p.rows[0]
+	p.rows = p.rows[1:]
+	return row, nil
+}
+
+// Progress implements importRowProducer interface.
+func (p *parquetStream) Progress() float32 {
+	return float32(p.done) / float32(p.reader.NumRowGroups())
+}
+
+type parquetInputReader struct {
+	importCtx *parallelImportContext
+	opts      roachpb.ParquetOptions
+	// flowCtx, if set, provides the temporary storage the input files are
+	// spooled to.
+	flowCtx *execinfra.FlowCtx
+}
+
+var _ inputConverter = &parquetInputReader{}
+
+func newParquetInputReader(
+	kvCh chan row.KVBatch,
+	tableDesc *tabledesc.Immutable,
+	opts roachpb.ParquetOptions,
+	walltime int64,
+	parallelism int,
+	targetCols tree.NameList,
+	evalCtx *tree.EvalContext,
+	flowCtx *execinfra.FlowCtx,
+) *parquetInputReader {
+	return &parquetInputReader{
+		importCtx: &parallelImportContext{
+			walltime:   walltime,
+			numWorkers: parallelism,
+			evalCtx:    evalCtx,
+			tableDesc:  tableDesc,
+			targetCols: targetCols,
+			kvCh:       kvCh,
+		},
+		opts:    opts,
+		flowCtx: flowCtx,
+	}
+}
+
+func (p *parquetInputReader) start(group ctxgroup.Group) {}
+
+func (p *parquetInputReader) readFiles(
+	ctx context.Context,
+	dataFiles map[int32]string,
+	resumePos map[int32]int64,
+	format roachpb.IOFileFormat,
+	makeExternalStorage cloud.ExternalStorageFactory,
+	user string,
+) error {
+	return readInputFiles(ctx, dataFiles, resumePos, format, p.readFile, makeExternalStorage, user)
+}
+
+// newParquetConsumer maps the columns of a Parquet file to the target columns
+// of the table by name.
+func (p *parquetInputReader) newParquetConsumer(
+	fileCols []parquet.Column,
+) (*parquetConsumer, []int, error) {
+	visibleCols := p.importCtx.tableDesc.VisibleColumns()
+	isTargetCol := make(map[string]bool, len(visibleCols))
+	if len(p.importCtx.targetCols) == 0 {
+		for _, col := range visibleCols {
+			isTargetCol[col.Name] = true
+		}
+	} else {
+		for _, name := range p.importCtx.targetCols {
+			isTargetCol[string(name)] = true
+		}
+	}
+	consumer := &parquetConsumer{strict: p.opts.StrictMode}
+	var read []int
+	found := make(map[string]bool, len(fileCols))
+	for i, fileCol := range fileCols {
+		name := lex.NormalizeName(fileCol.Name)
+		idx := -1
+		for j := range visibleCols {
+			if visibleCols[j].Name == name && isTargetCol[name] {
+				idx = j
+				break
+			}
+		}
+		if idx < 0 {
+			if p.opts.StrictMode {
+				return nil, nil, errors.Errorf("could not find column for parquet column %s", fileCol.Name)
+			}
+			continue
+		}
+		if found[name] {
+			return nil, nil, errors.Errorf("parquet file has more than one column named %s", name)
+		}
+		found[name] = true
+		decode, err := newParquetDecoder(fileCol, visibleCols[idx].Type)
+		if err != nil {
+			return nil, nil, err
+		}
+		native, _, err := newParquetNativeDecoder(fileCol)
+		if err != nil {
+			return nil, nil, err
+		}
+		consumer.cols = append(consumer.cols, fileCol)
+		consumer.colIdx = append(consumer.colIdx, idx)
+		consumer.decode = append(consumer.decode, decode)
+		consumer.natives = append(consumer.natives, native)
+		read = append(read, i)
+	}
+	if p.opts.StrictMode {
+		for name := range isTargetCol {
+			if !found[name] {
+				return nil, nil, errors.Errorf("column %s was not found in the parquet file", name)
+			}
+		}
+	}
+	return consumer, read, nil
+}
+
+func (p *parquetInputReader) readFile(
+	ctx context.Context, input *fileReader, inputIdx int32, resumePos int64, rejected chan string,
+) error {
+	var acc *mon.BoundAccount
+	if memMon := p.importCtx.evalCtx.Mon; memMon != nil {
+		a := memMon.MakeBoundAccount()
+		acc = &a
+		defer acc.Close(ctx)
+	}
+	file, size, cleanup, err := p.spoolFile(ctx, input, acc)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	r, err := parquet.NewReader(file, size)
+	if err != nil {
+		return err
+	}
+	if r.NumRowGroups() == 0 {
+		return nil
+	}
+	consumer, cols, err := p.newParquetConsumer(r.Columns())
+	if err != nil {
+		return err
+	}
+
+	numWorkers := p.importCtx.numWorkers
+	if numWorkers <= 0 {
+		numWorkers = runtime.NumCPU()
+	}
+	producer := &parquetStream{
+		ctx:        ctx,
+		reader:     r,
+		cols:       cols,
+		numWorkers: numWorkers,
+		acc:        acc,
+	}
+	fileCtx := &importFileContext{
+		source:   inputIdx,
+		skip:     resumePos,
+		rejected: rejected,
+	}
+	return runParallelImport(ctx, p.importCtx, fileCtx, producer, consumer)
+}
+
+// parquetSpoolChunkSize is the size of the chunks in which input files are
+// copied to temporary storage or memory.
+const parquetSpoolChunkSize = 64 << 10
+
+// spoolFile makes the input file readable at arbitrary offsets: Parquet
+// metadata is stored at the end of the file, and each row group is then read
+// on its own, but external storage can only read files sequentially. The file
+// is copied to temporary storage, with its size charged to the flow's disk
+// monitor, or, when there is no temporary storage, to memory charged to acc.
+// The returned function releases the copy.
+func (p *parquetInputReader) spoolFile(
+	ctx context.Context, input io.Reader, acc *mon.BoundAccount,
+) (io.ReaderAt, int64, func(), error) {
+	if p.flowCtx == nil || p.flowCtx.Cfg.TempFS == nil {
+		var buf bytes.Buffer
+		size, err := spool(ctx, &buf, input, acc)
+		if err != nil {
+			return nil, 0, nil, errors.Wrap(err, "buffering parquet file")
+		}
+		return bytes.NewReader(buf.Bytes()), size, func() {}, nil
+	}
+
+	tempFS := p.flowCtx.Cfg.TempFS
+	path := filepath.Join(p.flowCtx.Cfg.TempStoragePath, "import-parquet-"+uuid.FastMakeV4().String())
+	diskAcc := p.flowCtx.ParentDiskMonitor().MakeBoundAccount()
+	removeFile := func() {
+		if err := tempFS.Remove(path); err != nil {
+			log.Warningf(ctx, "unable to remove spooled parquet file %s: %v", path, err)
+		}
+		diskAcc.Close(ctx)
+	}
+	f, err := tempFS.Create(path)
+	if err != nil {
+		diskAcc.Close(ctx)
+		return nil, 0, nil, err
+	}
+	size, err := spool(ctx, f, input, &diskAcc)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		f, err = tempFS.Open(path)
+	}
+	if err != nil {
+		removeFile()
+		return nil, 0, nil, errors.Wrap(err, "spooling parquet file to temporary storage")
+	}
+	return f, size, func() {
+		_ = f.Close()
+		removeFile()
+	}, nil
+}
+
+// spool copies the input to w, charging each chunk to acc if it is set, and
+// returns the number of bytes copied.
+func spool(ctx context.Context, w io.Writer, input io.Reader, acc *mon.BoundAccount) (int64, error) {
+	buf := make([]byte, parquetSpoolChunkSize)
+	var size int64
+	for {
+		n, err := input.Read(buf)
+		if n > 0 {
+			if acc != nil {
+				if err := acc.Grow(ctx, int64(n)); err != nil {
+					return 0, err
+				}
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				return 0, err
+			}
+			size += int64(n)
+		}
+		if err == io.EOF {
+			return size, nil
+		} else if err != nil {
+			return 0, err
+		}
+	}
+}
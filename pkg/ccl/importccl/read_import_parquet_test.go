@@ -0,0 +1,394 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding/parquet"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParquetDecoder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	evalCtx := tree.MakeTestingEvalContext(cluster.MakeTestingClusterSettings())
+	dec, err := parquet.AppendDecimal(nil, big.NewInt(-1230), 5)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		col      parquet.Column
+		typ      *types.T
+		value    interface{}
+		expected string
+		err      string
+	}{
+		{
+			col:      parquet.Column{Type: parquet.Int32, Logical: parquet.LogicalInt16},
+			typ:      types.Int,
+			value:    int32(-7),
+			expected: "-7",
+		},
+		{
+			col:      parquet.Column{Type: parquet.Int32, Logical: parquet.LogicalUnsigned},
+			typ:      types.Int,
+			value:    int32(-1),
+			expected: "4294967295",
+		},
+		{
+			col:   parquet.Column{Type: parquet.Int64, Logical: parquet.LogicalUnsigned},
+			typ:   types.Int,
+			value: int64(-1),
+			err:   "out of range",
+		},
+		{
+			col:      parquet.Column{Type: parquet.Int64},
+			typ:      types.Float,
+			value:    int64(3),
+			expected: "3.0",
+		},
+		{
+			col:      parquet.Column{Type: parquet.Int32, Logical: parquet.LogicalDate},
+			typ:      types.Date,
+			value:    int32(-1),
+			expected: "1969-12-31",
+		},
+		{
+			col:      parquet.Column{Type: parquet.Int64, Logical: parquet.LogicalTimestampMillisUTC},
+			typ:      types.TimestampTZ,
+			value:    int64(-1),
+			expected: "1969-12-31 23:59:59.999+00:00",
+		},
+		{
+			col:      parquet.Column{Type: parquet.Int64, Logical: parquet.LogicalTimestampNanos},
+			typ:      types.Timestamp,
+			value:    int64(1500),
+			expected: "1970-01-01 00:00:00.000002",
+		},
+		{
+			col:      parquet.Column{Type: parquet.Int64, Logical: parquet.LogicalTimeMicros},
+			typ:      types.Time,
+			value:    int64(3600e6),
+			expected: "01:00:00",
+		},
+		{
+			// Midnight on the 1st of January 2020.
+			col:      parquet.Column{Type: parquet.Int96},
+			typ:      types.Timestamp,
+			value:    []byte{0, 0, 0, 0, 0, 0, 0, 0, 0xe2, 0x84, 0x25, 0x00},
+			expected: "2020-01-01 00:00:00",
+		},
+		{
+			col: parquet.Column{
+				Type: parquet.FixedLenByteArray, Logical: parquet.LogicalDecimal,
+				TypeLength: 5, Precision: 10, Scale: 2,
+			},
+			typ:      types.MakeDecimal(10, 2),
+			value:    dec,
+			expected: "-12.30",
+		},
+		{
+			col:      parquet.Column{Type: parquet.Int64, Logical: parquet.LogicalDecimal, Precision: 12, Scale: 3},
+			typ:      types.String,
+			value:    int64(12345),
+			expected: "12.345",
+		},
+		{
+			col:      parquet.Column{Type: parquet.ByteArray, Logical: parquet.LogicalString},
+			typ:      types.INet,
+			value:    []byte("10.0.0.1/8"),
+			expected: "10.0.0.1/8",
+		},
+		{
+			col:   parquet.Column{Type: parquet.ByteArray, Logical: parquet.LogicalString},
+			typ:   types.Int,
+			value: []byte("x"),
+			err:   "could not parse",
+		},
+		{
+			col:      parquet.Column{Type: parquet.ByteArray, Logical: parquet.LogicalJSON},
+			typ:      types.Jsonb,
+			value:    []byte(`{"a": [1]}`),
+			expected: `'{"a": [1]}'`,
+		},
+		{
+			col:      parquet.Column{Type: parquet.ByteArray},
+			typ:      types.Bytes,
+			value:    []byte("a"),
+			expected: `\x61`,
+		},
+		{
+			col:      parquet.Column{Type: parquet.Int32, List: true},
+			typ:      types.IntArray,
+			value:    []interface{}{int32(1), nil},
+			expected: "ARRAY[1,NULL]",
+		},
+		{
+			col:      parquet.Column{Type: parquet.ByteArray, Logical: parquet.LogicalString, List: true},
+			typ:      types.MakeArray(types.Date),
+			value:    []interface{}{[]byte("2020-01-01")},
+			expected: "ARRAY[2020-01-01]",
+		},
+		{
+			col: parquet.Column{Type: parquet.Int32, List: true},
+			typ: types.Int,
+			err: "cannot convert list column",
+		},
+	} {
+		t.Run(fmt.Sprintf("%v-%s", tc.value, tc.typ.SQLString()), func(t *testing.T) {
+			fn, err := newParquetDecoder(tc.col, tc.typ)
+			if err == nil {
+				var d tree.Datum
+				d, err = fn(tc.value, &evalCtx)
+				if err == nil {
+					require.Equal(t, tc.expected, tree.AsStringWithFlags(d, tree.FmtBareStrings))
+				}
+			}
+			if tc.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+// readParquetRows imports a Parquet file with a parquetInputReader, returning
+// the datums of each row.
+func readParquetRows(
+	t *testing.T,
+	tableDesc *tabledesc.Immutable,
+	data []byte,
+	strict bool,
+	evalCtx *tree.EvalContext,
+) ([]tree.Datums, error) {
+	ctx := context.Background()
+	// Ensure datum converter doesn't flush (since we're using nil kv channel
+	// for this test).
+	defer row.TestingSetDatumRowConverterBatchSize(1 << 20)()
+
+	r, err := parquet.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	p := newParquetInputReader(nil, tableDesc, roachpb.ParquetOptions{StrictMode: strict}, 0, 2, nil, evalCtx, nil /* flowCtx */)
+	consumer, cols, err := p.newParquetConsumer(r.Columns())
+	if err != nil {
+		return nil, err
+	}
+	producer := &parquetStream{ctx: ctx, reader: r, cols: cols, numWorkers: 2}
+	conv, err := row.NewDatumRowConverter(ctx, tableDesc, nil, evalCtx.Copy(), nil)
+	require.NoError(t, err)
+
+	var rows []tree.Datums
+	for producer.Scan() {
+		native, err := producer.Row()
+		require.NoError(t, err)
+		if err := consumer.FillDatums(native, int64(len(rows)+1), conv); err != nil {
+			return rows, err
+		}
+		rows = append(rows, append(tree.Datums(nil), conv.Datums...))
+	}
+	return rows, producer.Err()
+}
+
+func TestParquetInputReader(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	evalCtx := tree.MakeTestingEvalContext(cluster.MakeTestingClusterSettings())
+	tableDesc := descForTable(ctx, t,
+		"CREATE TABLE t (id INT PRIMARY KEY, name STRING, n DECIMAL(10,2), tags STRING[])",
+		10, 20, NoFKs).ImmutableCopy().(*tabledesc.Immutable)
+
+	write := func(cols []parquet.Column, rows [][]interface{}) []byte {
+		var buf bytes.Buffer
+		w, err := parquet.NewWriter(&buf, cols, parquet.Snappy)
+		require.NoError(t, err)
+		for i, row := range rows {
+			require.NoError(t, w.AddRow(row))
+			// Write a row group for every two rows.
+			if i%2 == 1 {
+				require.NoError(t, w.Flush())
+			}
+		}
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	// The file's columns are mapped to the table's by name, after being
+	// normalized. The extra column isn't imported.
+	cols := []parquet.Column{
+		{Name: "extra", Type: parquet.Boolean},
+		{Name: "Tags", Type: parquet.ByteArray, Logical: parquet.LogicalString, List: true},
+		{Name: "ID", Type: parquet.Int64},
+		{Name: "n", Type: parquet.ByteArray, Logical: parquet.LogicalString},
+	}
+	var fileRows [][]interface{}
+	for i := 0; i < 5; i++ {
+		fileRows = append(fileRows, []interface{}{
+			true, []interface{}{[]byte("a"), nil}, int64(i), []byte(fmt.Sprintf("%d.25", i)),
+		})
+	}
+	data := write(cols, fileRows)
+
+	rows, err := readParquetRows(t, tableDesc, data, false /* strict */, &evalCtx)
+	require.NoError(t, err)
+	require.Len(t, rows, 5)
+	for i, row := range rows {
+		require.Equal(t,
+			fmt.Sprintf("(%d, NULL, %d.25, ARRAY['a',NULL])", i, i),
+			tree.AsString(&tree.DTuple{D: row}))
+	}
+
+	_, err = readParquetRows(t, tableDesc, data, true /* strict */, &evalCtx)
+	require.EqualError(t, err, "could not find column for parquet column extra")
+
+	_, err = readParquetRows(t, tableDesc, write(cols[1:], nil), true /* strict */, &evalCtx)
+	require.EqualError(t, err, "column name was not found in the parquet file")
+
+	// Values which can't be converted are reported as import row errors, which
+	// are saved as JSON if experimental_save_rejected is specified.
+	fileRows[3][3] = []byte("x")
+	rows, err = readParquetRows(t, tableDesc, write(cols, fileRows), false /* strict */, &evalCtx)
+	require.Len(t, rows, 3)
+	var rowErr *importRowError
+	require.True(t, errors.As(err, &rowErr), "%+v", err)
+	require.Equal(t, int64(4), rowErr.rowNum)
+	require.Equal(t, `{"ID": 3, "Tags": ["a", null], "n": "x"}`, rowErr.row)
+}
+
+// TestParquetSpoolFile checks that Parquet files are spooled to temporary
+// storage, or to memory when there is none, and that the disk and memory they
+// use are accounted for.
+func TestParquetSpoolFile(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	tableDesc := descForTable(ctx, t,
+		"CREATE TABLE t (id INT PRIMARY KEY, name STRING)", 10, 20, NoFKs,
+	).ImmutableCopy().(*tabledesc.Immutable)
+
+	var buf bytes.Buffer
+	w, err := parquet.NewWriter(&buf, []parquet.Column{
+		{Name: "id", Type: parquet.Int64},
+		{Name: "name", Type: parquet.ByteArray, Logical: parquet.LogicalString},
+	}, parquet.Uncompressed)
+	require.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, w.AddRow([]interface{}{int64(i), []byte(fmt.Sprint("name", i))}))
+		if i%100 == 99 {
+			require.NoError(t, w.Flush())
+		}
+	}
+	require.NoError(t, w.Close())
+	data := buf.Bytes()
+
+	newMonitor := func(res mon.Resource, limit int64) *mon.BytesMonitor {
+		m := mon.NewMonitorWithLimit(
+			"test", res, limit, nil /* curCount */, nil /* maxHist */, 1, /* increment */
+			math.MaxInt64 /* noteworthy */, st)
+		m.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(limit))
+		return m
+	}
+	// readAll spools the file and decodes its rows, with the given memory limit.
+	readAll := func(flowCtx *execinfra.FlowCtx, memLimit int64) (int, error) {
+		memMon := newMonitor(mon.MemoryResource, memLimit)
+		defer memMon.Stop(ctx)
+		acc := memMon.MakeBoundAccount()
+		defer acc.Close(ctx)
+		evalCtx := tree.MakeTestingEvalContextWithMon(st, memMon)
+		p := newParquetInputReader(
+			nil, tableDesc, roachpb.ParquetOptions{}, 0, 2, nil, &evalCtx, flowCtx)
+		file, size, cleanup, err := p.spoolFile(ctx, bytes.NewReader(data), &acc)
+		if err != nil {
+			return 0, err
+		}
+		defer cleanup()
+		r, err := parquet.NewReader(file, size)
+		require.NoError(t, err)
+		producer := &parquetStream{
+			ctx: ctx, reader: r, cols: []int{0, 1}, numWorkers: 2, acc: &acc,
+		}
+		n := 0
+		for producer.Scan() {
+			n++
+			require.NoError(t, producer.Skip())
+		}
+		return n, producer.Err()
+	}
+
+	// Without temporary storage, the whole file is charged to the memory
+	// monitor.
+	fileSize := int64(len(data))
+	_, err = readAll(nil /* flowCtx */, fileSize/2)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "buffering parquet file")
+	n, err := readAll(nil /* flowCtx */, 2*fileSize)
+	require.NoError(t, err)
+	require.Equal(t, 1000, n)
+
+	tempEngine, tempFS, err := storage.NewTempEngine(
+		ctx, storage.DefaultStorageEngine, base.DefaultTestTempStorageConfig(st), base.DefaultTestStoreSpec)
+	require.NoError(t, err)
+	defer tempEngine.Close()
+	newFlowCtx := func(diskMon *mon.BytesMonitor) *execinfra.FlowCtx {
+		return &execinfra.FlowCtx{Cfg: &execinfra.ServerConfig{
+			Settings:    st,
+			TempFS:      tempFS,
+			DiskMonitor: diskMon,
+		}}
+	}
+
+	// With temporary storage, the file is charged to the disk monitor, and
+	// only the row groups being decoded are charged to the memory monitor.
+	diskMon := newMonitor(mon.DiskResource, fileSize/2)
+	_, err = readAll(newFlowCtx(diskMon), math.MaxInt64)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "spooling parquet file to temporary storage")
+	diskMon.Stop(ctx)
+
+	diskMon = newMonitor(mon.DiskResource, fileSize)
+	defer diskMon.Stop(ctx)
+	n, err = readAll(newFlowCtx(diskMon), fileSize/2)
+	require.NoError(t, err)
+	require.Equal(t, 1000, n)
+	// The spooled file is removed once it has been read.
+	files, err := tempFS.List("")
+	require.NoError(t, err)
+	for _, f := range files {
+		require.False(t, strings.HasPrefix(f, "import-parquet-"), f)
+	}
+
+	_, err = readAll(newFlowCtx(diskMon), 1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "decoding parquet row group 0")
+}
@@ -511,6 +511,8 @@ func readPostgresStmt(
 		// ignore txns.
 	case *tree.SetVar, *tree.Insert, *tree.CopyFrom, copyData, *tree.Delete:
 		// ignore SETs and DMLs.
+	case *tree.CreateFunction, *tree.CreateTrigger:
+		// Functions and triggers are not imported.
 	case *tree.Analyze:
 		// ANALYZE is syntatictic sugar for CreateStatistics. It can be ignored because
 		// the auto stats stuff will pick up the changes and run if needed.
@@ -866,6 +868,8 @@ func (m *pgDumpReader) readFile(
 			// ignored.
 		case *tree.CreateTable, *tree.AlterTable, *tree.CreateIndex, *tree.CreateSequence:
 			// handled during schema extraction.
+		case *tree.CreateFunction, *tree.CreateTrigger:
+			// Functions and triggers are not imported.
 		case *tree.Delete:
 			switch stmt := i.Table.(type) {
 			case *tree.AliasedTableExpr:
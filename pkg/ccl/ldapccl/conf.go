@@ -0,0 +1,225 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package ldapccl
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
+	"github.com/cockroachdb/errors"
+)
+
+// The options below are accepted in the HBA entry of an "ldap" rule. They
+// mirror the names used by PostgreSQL, with the exception of the
+// group synchronization options which are specific to CockroachDB.
+//
+// Two modes of operation are supported. In simple bind mode, the server
+// binds to the directory with the DN ldapprefix + username + ldapsuffix
+// and the password provided by the client. In search+bind mode, the
+// server first binds with ldapbinddn/ldapbindpasswd (or searches
+// anonymously), looks up ldapbasedn for an entry whose
+// ldapsearchattribute equals the username, then binds as that entry with
+// the password provided by the client.
+const (
+	optServer          = "ldapserver"
+	optPort            = "ldapport"
+	optScheme          = "ldapscheme"
+	optTLS             = "ldaptls"
+	optPrefix          = "ldapprefix"
+	optSuffix          = "ldapsuffix"
+	optBaseDN          = "ldapbasedn"
+	optBindDN          = "ldapbinddn"
+	optBindPasswd      = "ldapbindpasswd"
+	optSearchAttribute = "ldapsearchattribute"
+	optSearchFilter    = "ldapsearchfilter"
+
+	// optGroupAttribute is the attribute of the user's directory entry
+	// that lists the DNs of the groups the user belongs to.
+	optGroupAttribute = "ldapgroupattribute"
+	// optGroupRolePrefix enables group-to-role synchronization. For every
+	// group the user belongs to, the user is granted membership in the
+	// SQL role named by the prefix followed by the group's common name,
+	// if that role exists. Memberships in roles carrying the prefix that
+	// are not backed by a group are revoked.
+	optGroupRolePrefix = "ldapgrouproleprefix"
+)
+
+const (
+	defaultSearchAttribute = "uid"
+	defaultGroupAttribute  = "memberOf"
+)
+
+// ldapConf is the configuration of a single HBA entry using the "ldap"
+// method, as extracted from the entry's options.
+type ldapConf struct {
+	// server and port identify the directory server.
+	server string
+	port   int
+	// scheme is either "ldap" or "ldaps".
+	scheme string
+	// startTLS, if set, causes the connection to be upgraded with
+	// StartTLS before any bind is attempted. It is incompatible with
+	// the ldaps scheme.
+	startTLS bool
+
+	// prefix and suffix are used in simple bind mode.
+	prefix, suffix string
+
+	// The following fields are used in search+bind mode.
+	baseDN          string
+	bindDN          string
+	bindPasswd      string
+	searchAttribute string
+	searchFilter    string
+
+	// groupAttribute and groupRolePrefix configure group-to-role
+	// synchronization. Synchronization is disabled if groupRolePrefix is
+	// empty.
+	groupAttribute  string
+	groupRolePrefix string
+}
+
+// searchBind returns true if the configuration requests search+bind
+// mode, and false for simple bind mode.
+func (c *ldapConf) searchBind() bool {
+	return c.baseDN != ""
+}
+
+// syncRoles returns true if group-to-role synchronization is enabled.
+func (c *ldapConf) syncRoles() bool {
+	return c.groupRolePrefix != ""
+}
+
+// url returns the URL used to dial the directory server.
+func (c *ldapConf) url() string {
+	return fmt.Sprintf("%s://%s", c.scheme, net.JoinHostPort(c.server, strconv.Itoa(c.port)))
+}
+
+// userFilter returns the filter used in search+bind mode to find the
+// directory entry for the given user.
+func (c *ldapConf) userFilter(escapedUser string) string {
+	if c.searchFilter != "" {
+		return strings.Replace(c.searchFilter, "$username", escapedUser, -1)
+	}
+	return fmt.Sprintf("(%s=%s)", c.searchAttribute, escapedUser)
+}
+
+// parseConf extracts the LDAP configuration from an HBA entry and checks
+// that it is consistent.
+func parseConf(entry hba.Entry) (*ldapConf, error) {
+	c := &ldapConf{
+		scheme:          "ldap",
+		groupAttribute:  defaultGroupAttribute,
+		searchAttribute: defaultSearchAttribute,
+	}
+	seen := make(map[string]bool)
+	for _, op := range entry.Options {
+		name, val := op[0], op[1]
+		if seen[name] {
+			return nil, errors.Errorf("option %s specified more than once", name)
+		}
+		seen[name] = true
+		switch name {
+		case optServer:
+			c.server = val
+		case optPort:
+			port, err := strconv.Atoi(val)
+			if err != nil || port <= 0 || port > 65535 {
+				return nil, errors.Errorf("invalid %s: %q", optPort, val)
+			}
+			c.port = port
+		case optScheme:
+			if val != "ldap" && val != "ldaps" {
+				return nil, errors.Errorf("invalid %s: %q, expected ldap or ldaps", optScheme, val)
+			}
+			c.scheme = val
+		case optTLS:
+			switch val {
+			case "0":
+			case "1":
+				c.startTLS = true
+			default:
+				return nil, errors.Errorf("invalid %s: %q, expected 0 or 1", optTLS, val)
+			}
+		case optPrefix:
+			c.prefix = val
+		case optSuffix:
+			c.suffix = val
+		case optBaseDN:
+			c.baseDN = val
+		case optBindDN:
+			c.bindDN = val
+		case optBindPasswd:
+			c.bindPasswd = val
+		case optSearchAttribute:
+			c.searchAttribute = val
+		case optSearchFilter:
+			c.searchFilter = val
+		case optGroupAttribute:
+			c.groupAttribute = val
+		case optGroupRolePrefix:
+			c.groupRolePrefix = val
+		default:
+			return nil, errors.Errorf("unsupported option %s", name)
+		}
+	}
+
+	if c.server == "" {
+		return nil, errors.Errorf("missing %q option in LDAP entry", optServer)
+	}
+	if c.port == 0 {
+		if c.scheme == "ldaps" {
+			c.port = 636
+		} else {
+			c.port = 389
+		}
+	}
+	if c.startTLS && c.scheme == "ldaps" {
+		return nil, errors.Errorf("%s cannot be used with the ldaps scheme", optTLS)
+	}
+
+	simpleBind := seen[optPrefix] || seen[optSuffix]
+	if simpleBind && c.searchBind() {
+		return nil, errors.Errorf(
+			"cannot mix options for simple bind (%s, %s) and search+bind (%s) modes",
+			optPrefix, optSuffix, optBaseDN)
+	}
+	if !simpleBind && !c.searchBind() {
+		return nil, errors.Errorf(
+			"either %s/%s (simple bind) or %s (search+bind) must be specified",
+			optPrefix, optSuffix, optBaseDN)
+	}
+	if !c.searchBind() {
+		for _, opt := range []string{optBindDN, optBindPasswd, optSearchAttribute, optSearchFilter} {
+			if seen[opt] {
+				return nil, errors.Errorf("option %s requires %s", opt, optBaseDN)
+			}
+		}
+	}
+	if seen[optSearchAttribute] && seen[optSearchFilter] {
+		return nil, errors.Errorf("cannot specify both %s and %s", optSearchAttribute, optSearchFilter)
+	}
+	if seen[optBindPasswd] && !seen[optBindDN] {
+		return nil, errors.Errorf("option %s requires %s", optBindPasswd, optBindDN)
+	}
+	if seen[optGroupAttribute] && !c.syncRoles() {
+		return nil, errors.Errorf("option %s requires %s", optGroupAttribute, optGroupRolePrefix)
+	}
+	return c, nil
+}
+
+// checkEntry is the entry validation hook registered with pgwire. It is
+// called when the HBA configuration is loaded.
+func checkEntry(entry hba.Entry) error {
+	_, err := parseConf(entry)
+	return err
+}
@@ -0,0 +1,241 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package ldapccl
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
+	"github.com/cockroachdb/errors"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// authTypeCleartextPassword is the pgwire auth response code to request
+// a plaintext password during the connection handshake.
+const authTypeCleartextPassword int32 = 3
+
+// ldapTimeout bounds the duration of every request sent to the
+// directory server. It is a variable so that tests can lower it.
+var ldapTimeout = 10 * time.Second
+
+// errInvalidCredentials is returned when the directory rejects the
+// credentials of the user. The details are logged on the
+// authentication log but not reported to the client.
+var errInvalidCredentials = errors.New("LDAP authentication failed")
+
+// authLDAP performs authentication against an LDAP directory. See:
+// https://www.postgresql.org/docs/current/auth-ldap.html
+func authLDAP(
+	ctx context.Context,
+	c pgwire.AuthConn,
+	_ tls.ConnectionState,
+	_ pgwire.PasswordRetrievalFn,
+	_ pgwire.PasswordValidUntilFn,
+	execCfg *sql.ExecutorConfig,
+	entry *hba.Entry,
+) (security.UserAuthHook, error) {
+	conf, err := parseConf(*entry)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.SendAuthRequest(authTypeCleartextPassword, nil /* data */); err != nil {
+		return nil, err
+	}
+	pwdData, err := c.GetPwdData()
+	if err != nil {
+		return nil, err
+	}
+	password, err := passwordString(pwdData)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(requestedUser string, _ bool) (func(), error) {
+		groups, err := authenticate(ctx, conf, dialLDAP, requestedUser, password)
+		if err != nil {
+			c.Logf(ctx, "LDAP authentication failed: %v", err)
+			return nil, errInvalidCredentials
+		}
+		c.Logf(ctx, "LDAP authentication succeeded")
+
+		// Do the license check after the directory accepted the
+		// credentials, so that administrators are able to test whether
+		// their LDAP configuration is correct before enabling enterprise
+		// features.
+		if err := utilccl.CheckEnterpriseEnabled(
+			execCfg.Settings, execCfg.ClusterID(), execCfg.Organization(), "LDAP authentication",
+		); err != nil {
+			return nil, err
+		}
+
+		if conf.syncRoles() {
+			if err := syncRoles(ctx, execCfg.InternalExecutor, conf, requestedUser, groups); err != nil {
+				c.Logf(ctx, "LDAP role synchronization failed: %v", err)
+				return nil, errors.Wrap(err, "synchronizing LDAP groups")
+			}
+		}
+		return nil, nil
+	}, nil
+}
+
+// ldapConn is the subset of the go-ldap client API used for
+// authentication.
+type ldapConn interface {
+	StartTLS(*tls.Config) error
+	Bind(username, password string) error
+	Search(*ldap.SearchRequest) (*ldap.SearchResult, error)
+	Close()
+}
+
+// dialFn opens a connection to the directory server described by conf.
+type dialFn func(conf *ldapConf) (ldapConn, error)
+
+func dialLDAP(conf *ldapConf) (ldapConn, error) {
+	tlsConf := &tls.Config{ServerName: conf.server}
+	conn, err := ldap.DialURL(conf.url(), ldap.DialWithTLSConfig(tlsConf))
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(ldapTimeout)
+	return conn, nil
+}
+
+// authenticate verifies the password of user against the directory. If
+// group synchronization is enabled, it also returns the DNs of the groups
+// the user belongs to.
+func authenticate(
+	ctx context.Context, conf *ldapConf, dial dialFn, user, password string,
+) (groups []string, _ error) {
+	// An empty password is interpreted by most LDAP servers as an
+	// unauthenticated bind, which always succeeds. See RFC 4513 section
+	// 5.1.2. Reject it explicitly.
+	if password == "" {
+		return nil, errors.New("empty password")
+	}
+
+	conn, err := dial(conf)
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to LDAP server %s", conf.url())
+	}
+	defer conn.Close()
+
+	if conf.startTLS {
+		if err := conn.StartTLS(&tls.Config{ServerName: conf.server}); err != nil {
+			return nil, errors.Wrap(err, "starting TLS")
+		}
+	}
+
+	var userDN string
+	if conf.searchBind() {
+		if userDN, err = searchUser(conn, conf, user); err != nil {
+			return nil, err
+		}
+	} else {
+		userDN = conf.prefix + escapeDN(user) + conf.suffix
+	}
+
+	if err := conn.Bind(userDN, password); err != nil {
+		return nil, errors.Wrapf(err, "binding as %q", userDN)
+	}
+
+	if !conf.syncRoles() {
+		return nil, nil
+	}
+	// The group lookup is performed with the user's credentials, as the
+	// search bind (if any) has been replaced by the user's bind.
+	res, err := conn.Search(ldap.NewSearchRequest(
+		userDN, ldap.ScopeBaseObject, ldap.NeverDerefAliases,
+		0 /* sizeLimit */, int(ldapTimeout.Seconds()), false, /* typesOnly */
+		"(objectClass=*)", []string{conf.groupAttribute}, nil, /* controls */
+	))
+	if err != nil {
+		return nil, errors.Wrapf(err, "retrieving groups of %q", userDN)
+	}
+	if len(res.Entries) != 1 {
+		return nil, errors.Errorf("expected 1 entry for %q, found %d", userDN, len(res.Entries))
+	}
+	return res.Entries[0].GetEqualFoldAttributeValues(conf.groupAttribute), nil
+}
+
+// searchUser finds the DN of the directory entry for user, for use in
+// search+bind mode.
+func searchUser(conn ldapConn, conf *ldapConf, user string) (string, error) {
+	// Without a bind DN, the search is performed anonymously: LDAP
+	// sessions are anonymous until the first successful bind.
+	if conf.bindDN != "" {
+		if err := conn.Bind(conf.bindDN, conf.bindPasswd); err != nil {
+			return "", errors.Wrap(err, "performing search bind")
+		}
+	}
+
+	filter := conf.userFilter(ldap.EscapeFilter(user))
+	res, err := conn.Search(ldap.NewSearchRequest(
+		conf.baseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2 /* sizeLimit */, int(ldapTimeout.Seconds()), false, /* typesOnly */
+		filter, []string{"dn"}, nil, /* controls */
+	))
+	if err != nil {
+		return "", errors.Wrapf(err, "searching for %s under %q", filter, conf.baseDN)
+	}
+	switch len(res.Entries) {
+	case 0:
+		return "", errors.Errorf("user %q not found in LDAP directory", user)
+	case 1:
+		return res.Entries[0].DN, nil
+	default:
+		return "", errors.Errorf("user %q matches more than one LDAP entry", user)
+	}
+}
+
+// escapeDN escapes the special characters of an attribute value so that
+// it can be embedded in a distinguished name, as specified by RFC 4514
+// section 2.4. Without this, a user name containing e.g. a comma could be
+// used to bind as an arbitrary entry of the directory. The equals sign
+// does not need to be escaped, but doing so is allowed and harmless.
+func escapeDN(value string) string {
+	var buf strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case strings.IndexByte(`"+,;<=>\`, c) >= 0,
+			c == ' ' && (i == 0 || i == len(value)-1),
+			c == '#' && i == 0:
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case c == 0:
+			buf.WriteString(`\00`)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String()
+}
+
+func passwordString(pwdData []byte) (string, error) {
+	// Make a string out of the byte array.
+	if bytes.IndexByte(pwdData, 0) != len(pwdData)-1 {
+		return "", errors.New("expected 0-terminated byte array")
+	}
+	return string(pwdData[:len(pwdData)-1]), nil
+}
+
+func init() {
+	// The password is transmitted in cleartext from the client to the
+	// server, so the method is only allowed over SSL connections.
+	pgwire.RegisterAuthMethod("ldap", authLDAP, clusterversion.VersionLDAPAuthentication, hba.ConnHostSSL, checkEntry)
+}
@@ -0,0 +1,366 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package ldapccl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/require"
+)
+
+// stubEntry is a directory entry served by stubServer.
+type stubEntry struct {
+	dn       string
+	password string
+	attrs    map[string][]string
+}
+
+// stubServer is a minimal in-process LDAP server. It supports simple
+// binds, base and subtree searches with equality and presence filters,
+// and unbind. This is sufficient to exercise the client code paths used
+// by the ldap authentication method.
+type stubServer struct {
+	ln      net.Listener
+	entries []stubEntry
+	wg      sync.WaitGroup
+}
+
+func newStubServer(t *testing.T, entries ...stubEntry) *stubServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &stubServer{ln: ln, entries: entries}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.serve(conn)
+			}()
+		}
+	}()
+	return s
+}
+
+func (s *stubServer) close() {
+	_ = s.ln.Close()
+	s.wg.Wait()
+}
+
+// options returns the HBA options pointing at the server.
+func (s *stubServer) options() [][2]string {
+	host, port, _ := net.SplitHostPort(s.ln.Addr().String())
+	return [][2]string{{optServer, host}, {optPort, port}}
+}
+
+func (s *stubServer) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		req, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		msgID := req.Children[0].Value.(int64)
+		op := req.Children[1]
+		var resps []*ber.Packet
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := op.Children[1].Value.(string)
+			password := op.Children[2].Data.String()
+			resps = append(resps, s.result(ldap.ApplicationBindResponse, s.bind(dn, password)))
+		case ldap.ApplicationSearchRequest:
+			for _, e := range s.search(op) {
+				resps = append(resps, e)
+			}
+			resps = append(resps, s.result(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+		case ldap.ApplicationUnbindRequest:
+			return
+		default:
+			return
+		}
+		for _, resp := range resps {
+			envelope := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+			envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, msgID, "MessageID"))
+			envelope.AppendChild(resp)
+			if _, err := conn.Write(envelope.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *stubServer) bind(dn, password string) int {
+	for _, e := range s.entries {
+		if strings.EqualFold(e.dn, dn) {
+			if e.password != "" && e.password == password {
+				return ldap.LDAPResultSuccess
+			}
+			break
+		}
+	}
+	return ldap.LDAPResultInvalidCredentials
+}
+
+func (s *stubServer) search(op *ber.Packet) []*ber.Packet {
+	baseDN := strings.ToLower(op.Children[0].Value.(string))
+	scope := op.Children[1].Value.(int64)
+	filter, err := ldap.DecompileFilter(op.Children[6])
+	if err != nil {
+		return nil
+	}
+	// Only "(attr=value)" and "(attr=*)" filters are supported.
+	filter = strings.TrimSuffix(strings.TrimPrefix(filter, "("), ")")
+	eq := strings.IndexByte(filter, '=')
+	attr, val := filter[:eq], filter[eq+1:]
+
+	var res []*ber.Packet
+	for _, e := range s.entries {
+		dn := strings.ToLower(e.dn)
+		if scope == ldap.ScopeBaseObject && dn != baseDN {
+			continue
+		}
+		if scope == ldap.ScopeWholeSubtree && !strings.HasSuffix(dn, baseDN) {
+			continue
+		}
+		if !strings.EqualFold(attr, "objectClass") || val != "*" {
+			matched := false
+			for _, v := range e.attrs[attr] {
+				matched = matched || v == val
+			}
+			if !matched {
+				continue
+			}
+		}
+		entry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+		entry.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.dn, "DN"))
+		attrs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+		for name, vals := range e.attrs {
+			a := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+			a.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+			set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+			for _, v := range vals {
+				set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, "Value"))
+			}
+			a.AppendChild(set)
+			attrs.AppendChild(a)
+		}
+		entry.AppendChild(attrs)
+		res = append(res, entry)
+	}
+	return res
+}
+
+func (s *stubServer) result(tag ber.Tag, code int) *ber.Packet {
+	p := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "ResultCode"))
+	p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "MatchedDN"))
+	p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "ErrorMessage"))
+	return p
+}
+
+func TestEscapeDN(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		in, exp string
+	}{
+		{"alice", "alice"},
+		{"eve,ou=admins", `eve\,ou\=admins`},
+		{`a+b;c<d>e"f\g`, `a\+b\;c\<d\>e\"f\\g`},
+		{" #a b ", `\ #a b\ `},
+		{"#a#", `\#a#`},
+		{"a\x00b", `a\00b`},
+	}
+	for _, tc := range testData {
+		require.Equal(t, tc.exp, escapeDN(tc.in), "input %q", tc.in)
+	}
+}
+
+func TestParseConf(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		opts   [][2]string
+		expErr string
+	}{
+		{[][2]string{{optServer, "ad"}, {optPrefix, "cn="}}, ""},
+		{[][2]string{{optServer, "ad"}, {optBaseDN, "dc=example"}}, ""},
+		{[][2]string{{optServer, "ad"}, {optBaseDN, "dc=example"}, {optGroupRolePrefix, "ldap_"}}, ""},
+		{[][2]string{{optPrefix, "cn="}}, `missing "ldapserver" option`},
+		{[][2]string{{optServer, "ad"}}, `either ldapprefix/ldapsuffix`},
+		{[][2]string{{optServer, "ad"}, {optPrefix, "cn="}, {optBaseDN, "dc=example"}}, "cannot mix options"},
+		{[][2]string{{optServer, "ad"}, {optPrefix, "cn="}, {optBindDN, "cn=admin"}}, "requires ldapbasedn"},
+		{[][2]string{{optServer, "ad"}, {optPrefix, "cn="}, {optPort, "x"}}, "invalid ldapport"},
+		{[][2]string{{optServer, "ad"}, {optPrefix, "cn="}, {optTLS, "yes"}}, "invalid ldaptls"},
+		{[][2]string{{optServer, "ad"}, {optPrefix, "cn="}, {optTLS, "1"}, {optScheme, "ldaps"}}, "cannot be used with the ldaps scheme"},
+		{[][2]string{{optServer, "ad"}, {optPrefix, "cn="}, {optGroupAttribute, "memberOf"}}, "requires ldapgrouproleprefix"},
+		{[][2]string{{optServer, "ad"}, {optPrefix, "cn="}, {"krb_realm", "x"}}, "unsupported option krb_realm"},
+		{[][2]string{{optServer, "ad"}, {optServer, "ad2"}, {optPrefix, "cn="}}, "specified more than once"},
+	}
+	for _, tc := range testData {
+		t.Run(fmt.Sprint(tc.opts), func(t *testing.T) {
+			_, err := parseConf(hba.Entry{Options: tc.opts})
+			if !testutils.IsError(err, tc.expErr) {
+				t.Fatalf("expected error %q, got %v", tc.expErr, err)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	// The LDAP client leaves a goroutine running for the duration of the
+	// timeout after every request.
+	defer func(old time.Duration) { ldapTimeout = old }(ldapTimeout)
+	ldapTimeout = 100 * time.Millisecond
+
+	s := newStubServer(t,
+		stubEntry{dn: "cn=admin,dc=example", password: "adminpw"},
+		stubEntry{
+			dn:       "uid=alice,ou=people,dc=example",
+			password: "alicepw",
+			attrs: map[string][]string{
+				"uid":      {"alice"},
+				"mail":     {"alice@example.com"},
+				"memberOf": {"cn=Engineering,ou=groups,dc=example", "cn=ops,ou=groups,dc=example"},
+			},
+		},
+		stubEntry{dn: "uid=eve,ou=admins,ou=people,dc=example", password: "evepw"},
+	)
+	defer s.close()
+
+	withOpts := func(opts ...[2]string) *ldapConf {
+		conf, err := parseConf(hba.Entry{Options: append(s.options(), opts...)})
+		require.NoError(t, err)
+		return conf
+	}
+	simple := withOpts([2]string{optPrefix, "uid="}, [2]string{optSuffix, ",ou=people,dc=example"})
+	search := withOpts([2]string{optBaseDN, "dc=example"},
+		[2]string{optBindDN, "cn=admin,dc=example"}, [2]string{optBindPasswd, "adminpw"})
+	anonSearch := withOpts([2]string{optBaseDN, "dc=example"},
+		[2]string{optSearchFilter, "(mail=$username@example.com)"})
+	badSearchBind := withOpts([2]string{optBaseDN, "dc=example"},
+		[2]string{optBindDN, "cn=admin,dc=example"}, [2]string{optBindPasswd, "wrong"})
+	groupSync := withOpts([2]string{optBaseDN, "dc=example"}, [2]string{optGroupRolePrefix, "ldap_"})
+
+	testData := []struct {
+		name      string
+		conf      *ldapConf
+		user      string
+		password  string
+		expErr    string
+		expGroups []string
+	}{
+		{"simple bind", simple, "alice", "alicepw", "", nil},
+		{"simple bind wrong password", simple, "alice", "nope", "Invalid Credentials", nil},
+		{"simple bind unknown user", simple, "bob", "alicepw", "Invalid Credentials", nil},
+		{"empty password", simple, "alice", "", "empty password", nil},
+		{"simple bind escapes DN", simple, "eve,ou=admins", "evepw", "Invalid Credentials", nil},
+		{"search bind", search, "alice", "alicepw", "", nil},
+		{"search bind wrong password", search, "alice", "nope", "Invalid Credentials", nil},
+		{"search bind unknown user", search, "bob", "alicepw", `user "bob" not found`, nil},
+		{"search bind escapes filter", search, "*", "alicepw", `user "\*" not found`, nil},
+		{"search bind bad bind password", badSearchBind, "alice", "alicepw", "performing search bind", nil},
+		{"anonymous search with filter", anonSearch, "alice", "alicepw", "", nil},
+		{"group sync", groupSync, "alice", "alicepw", "", []string{
+			"cn=Engineering,ou=groups,dc=example", "cn=ops,ou=groups,dc=example",
+		}},
+	}
+	for _, tc := range testData {
+		t.Run(tc.name, func(t *testing.T) {
+			groups, err := authenticate(context.Background(), tc.conf, dialLDAP, tc.user, tc.password)
+			if !testutils.IsError(err, tc.expErr) {
+				t.Fatalf("expected error %q, got %v", tc.expErr, err)
+			}
+			require.Equal(t, tc.expGroups, groups)
+		})
+	}
+}
+
+func TestGroupRoles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	roles, err := groupRoles("ldap_", []string{
+		"cn=Engineering,ou=groups,dc=example",
+		"ou=ops,dc=example",
+		"CN=engineering,ou=other,dc=example",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"ldap_engineering", "ldap_ops"}, roles)
+
+	_, err = groupRoles("ldap_", []string{"not a dn"})
+	require.Error(t, err)
+}
+
+func TestSyncRoles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	ie := s.InternalExecutor().(*sql.InternalExecutor)
+
+	sqlDB.Exec(t, `CREATE USER alice`)
+	sqlDB.Exec(t, `CREATE ROLE ldap_engineering`)
+	sqlDB.Exec(t, `CREATE ROLE ldap_ops`)
+	sqlDB.Exec(t, `CREATE ROLE ldap_sales`)
+	sqlDB.Exec(t, `CREATE ROLE other`)
+	sqlDB.Exec(t, `GRANT ldap_sales, other TO alice`)
+
+	conf := &ldapConf{groupRolePrefix: "ldap_"}
+	require.NoError(t, syncRoles(ctx, ie, conf, "alice", []string{
+		"cn=Engineering,ou=groups,dc=example",
+		"cn=ops,ou=groups,dc=example",
+		"cn=marketing,ou=groups,dc=example",
+	}))
+
+	// Memberships in roles without the prefix are left untouched, while
+	// ldap_sales is revoked since alice is no longer in that group.
+	sqlDB.CheckQueryResults(t,
+		`SELECT role FROM system.role_members WHERE member = 'alice' ORDER BY role`,
+		[][]string{{"ldap_engineering"}, {"ldap_ops"}, {"other"}},
+	)
+
+	// Synchronizing again is a no-op.
+	require.NoError(t, syncRoles(ctx, ie, conf, "alice", []string{
+		"cn=Engineering,ou=groups,dc=example",
+		"cn=ops,ou=groups,dc=example",
+	}))
+	sqlDB.CheckQueryResults(t,
+		`SELECT role FROM system.role_members WHERE member = 'alice' ORDER BY role`,
+		[][]string{{"ldap_engineering"}, {"ldap_ops"}, {"other"}},
+	)
+}
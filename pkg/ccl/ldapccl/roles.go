@@ -0,0 +1,117 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package ldapccl
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/errors"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// groupRoles maps the DNs of the LDAP groups a user belongs to onto the
+// names of the SQL roles they correspond to. The role name is the
+// configured prefix followed by the normalized common name (or, failing
+// that, the first RDN value) of the group.
+func groupRoles(prefix string, groupDNs []string) ([]string, error) {
+	seen := make(map[string]bool, len(groupDNs))
+	roles := make([]string, 0, len(groupDNs))
+	for _, groupDN := range groupDNs {
+		dn, err := ldap.ParseDN(groupDN)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing group DN %q", groupDN)
+		}
+		if len(dn.RDNs) == 0 || len(dn.RDNs[0].Attributes) == 0 {
+			continue
+		}
+		name := dn.RDNs[0].Attributes[0].Value
+		for _, attr := range dn.RDNs[0].Attributes {
+			if strings.EqualFold(attr.Type, "cn") {
+				name = attr.Value
+				break
+			}
+		}
+		role := tree.Name(prefix + name).Normalize()
+		if !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	sort.Strings(roles)
+	return roles, nil
+}
+
+// syncRoles reconciles the role memberships of user with the groups it
+// belongs to in the directory. Only roles whose name starts with the
+// configured prefix are considered: the user is granted every such role
+// backed by one of its groups, and the memberships in every other such
+// role are revoked. Groups with no corresponding role are ignored.
+func syncRoles(
+	ctx context.Context, ie *sql.InternalExecutor, conf *ldapConf, user string, groupDNs []string,
+) error {
+	wanted, err := groupRoles(conf.groupRolePrefix, groupDNs)
+	if err != nil {
+		return err
+	}
+	prefix := tree.Name(conf.groupRolePrefix).Normalize()
+	override := sessiondata.InternalExecutorOverride{User: security.RootUser}
+
+	rows, err := ie.QueryEx(ctx, "ldap-roles-existing", nil /* txn */, override,
+		`SELECT username FROM system.users WHERE "isRole" AND starts_with(username, $1)`, prefix)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		existing[string(tree.MustBeDString(row[0]))] = true
+	}
+
+	rows, err = ie.QueryEx(ctx, "ldap-roles-granted", nil /* txn */, override,
+		`SELECT role FROM system.role_members WHERE member = $1 AND starts_with(role, $2)`, user, prefix)
+	if err != nil {
+		return err
+	}
+	granted := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		granted[string(tree.MustBeDString(row[0]))] = true
+	}
+
+	for _, role := range wanted {
+		if !existing[role] || granted[role] {
+			continue
+		}
+		if _, err := ie.ExecEx(ctx, "ldap-grant-role", nil /* txn */, override,
+			"GRANT "+tree.NameString(role)+" TO "+tree.NameString(user),
+		); err != nil {
+			return errors.Wrapf(err, "granting role %s", role)
+		}
+	}
+
+	wantedSet := make(map[string]bool, len(wanted))
+	for _, role := range wanted {
+		wantedSet[role] = true
+	}
+	for role := range granted {
+		if wantedSet[role] {
+			continue
+		}
+		if _, err := ie.ExecEx(ctx, "ldap-revoke-role", nil /* txn */, override,
+			"REVOKE "+tree.NameString(role)+" FROM "+tree.NameString(user),
+		); err != nil {
+			return errors.Wrapf(err, "revoking role %s", role)
+		}
+	}
+	return nil
+}
@@ -203,6 +203,7 @@ func evalExport(
 			// Create a unique int differently.
 			nodeID := cArgs.EvalCtx.NodeID()
 			exported.Path = fmt.Sprintf("%d.sst", builtins.GenerateUniqueInt(base.SQLInstanceID(nodeID)))
+			exported.FileSize = int64(len(data))
 			if err := retry.WithMaxAttempts(ctx, base.DefaultRetryOptions(), maxUploadRetries, func() error {
 				// We blindly retry any error here because we expect the caller to have
 				// verified the target is writable before sending ExportRequests for it.
@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -142,13 +143,106 @@ func evalImport(ctx context.Context, cArgs batcheval.CommandArgs) (*roachpb.Impo
 	}
 	defer cArgs.EvalCtx.GetLimiters().ConcurrentImportRequests.Finish()
 
+	batcher, err := bulk.MakeSSTBatcher(ctx, db, cArgs.EvalCtx.ClusterSettings(), func() int64 { return MaxImportBatchSize(cArgs.EvalCtx.ClusterSettings()) })
+	if err != nil {
+		return nil, err
+	}
+	defer batcher.Close()
+
+	if err := ReadImportFiles(
+		ctx, args, cArgs.EvalCtx.GetExternalStorage, kr,
+		func(key storage.MVCCKey, value roachpb.Value) error {
+			if log.V(3) {
+				log.Infof(ctx, "Put %s -> %s", key.Key, value.PrettyPrint())
+			}
+			if err := batcher.AddMVCCKey(ctx, key, value.RawBytes); err != nil {
+				return errors.Wrapf(err, "adding to batch: %s -> %s", key, value.PrettyPrint())
+			}
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+	// Flush out the last batch.
+	if err := batcher.Flush(ctx); err != nil {
+		return nil, err
+	}
+	log.Event(ctx, "done")
+	return &roachpb.ImportResponse{Imported: batcher.GetSummary()}, nil
+}
+
+// readExternalFile fetches the file at basename in the store, retrying
+// transient errors, and decrypts it if encryption is set.
+func readExternalFile(
+	ctx context.Context,
+	store cloud.ExternalStorage,
+	basename string,
+	encryption *roachpb.FileEncryptionOptions,
+) ([]byte, error) {
+	const maxAttempts = 3
+	var fileContents []byte
+	if err := retry.WithMaxAttempts(ctx, base.DefaultRetryOptions(), maxAttempts, func() error {
+		f, err := store.ReadFile(ctx, basename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		fileContents, err = ioutil.ReadAll(f)
+		return err
+	}); err != nil {
+		return nil, errors.Wrapf(err, "fetching %q", basename)
+	}
+	dataSize := int64(len(fileContents))
+	log.Eventf(ctx, "fetched file (%s)", humanizeutil.IBytes(dataSize))
+
+	if encryption != nil {
+		var err error
+		fileContents, err = DecryptFile(fileContents, encryption.Key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fileContents, nil
+}
+
+// ExternalSSTReader returns an iterator over the SST at basename in the store,
+// which is decrypted first if encryption is set. The caller is responsible for
+// closing the returned iterator.
+func ExternalSSTReader(
+	ctx context.Context,
+	store cloud.ExternalStorage,
+	basename string,
+	encryption *roachpb.FileEncryptionOptions,
+) (storage.SimpleIterator, error) {
+	fileContents, err := readExternalFile(ctx, store, basename, encryption)
+	if err != nil {
+		return nil, err
+	}
+	return storage.NewMemSSTIterator(fileContents, false)
+}
+
+// ReadImportFiles reads the files of an ImportRequest, decrypting them and
+// verifying their checksums, and calls fn with the latest value as of
+// args.EndTime of each key in args.DataSpan, rewritten using kr. Keys which kr
+// doesn't match are skipped. The key and value passed to fn are only valid
+// until it returns.
+//
+// It is used to evaluate ImportRequests and, without writing anything, to
+// verify the data of backups.
+func ReadImportFiles(
+	ctx context.Context,
+	args *roachpb.ImportRequest,
+	makeExternalStorage cloud.ExternalStorageFactory,
+	kr *KeyRewriter,
+	fn func(key storage.MVCCKey, value roachpb.Value) error,
+) error {
 	var iters []storage.SimpleIterator
 	for _, file := range args.Files {
 		log.VEventf(ctx, 2, "import file %s %s", file.Path, args.Key)
 
-		dir, err := cArgs.EvalCtx.GetExternalStorage(ctx, file.Dir)
+		dir, err := makeExternalStorage(ctx, file.Dir)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		defer func() {
 			if err := dir.Close(); err != nil {
@@ -156,54 +250,30 @@ func evalImport(ctx context.Context, cArgs batcheval.CommandArgs) (*roachpb.Impo
 			}
 		}()
 
-		const maxAttempts = 3
-		var fileContents []byte
-		if err := retry.WithMaxAttempts(ctx, base.DefaultRetryOptions(), maxAttempts, func() error {
-			f, err := dir.ReadFile(ctx, file.Path)
-			if err != nil {
-				return err
-			}
-			defer f.Close()
-			fileContents, err = ioutil.ReadAll(f)
+		fileContents, err := readExternalFile(ctx, dir, file.Path, args.Encryption)
+		if err != nil {
 			return err
-		}); err != nil {
-			return nil, errors.Wrapf(err, "fetching %q", file.Path)
-		}
-		dataSize := int64(len(fileContents))
-		log.Eventf(ctx, "fetched file (%s)", humanizeutil.IBytes(dataSize))
-
-		if args.Encryption != nil {
-			fileContents, err = DecryptFile(fileContents, args.Encryption.Key)
-			if err != nil {
-				return nil, err
-			}
 		}
 
 		if len(file.Sha512) > 0 {
 			checksum, err := SHA512ChecksumData(fileContents)
 			if err != nil {
-				return nil, err
+				return err
 			}
 			if !bytes.Equal(checksum, file.Sha512) {
-				return nil, errors.Errorf("checksum mismatch for %s", file.Path)
+				return errors.Errorf("checksum mismatch for %s", file.Path)
 			}
 		}
 
 		iter, err := storage.NewMemSSTIterator(fileContents, false)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		defer iter.Close()
 		iters = append(iters, iter)
 	}
 
-	batcher, err := bulk.MakeSSTBatcher(ctx, db, cArgs.EvalCtx.ClusterSettings(), func() int64 { return MaxImportBatchSize(cArgs.EvalCtx.ClusterSettings()) })
-	if err != nil {
-		return nil, err
-	}
-	defer batcher.Close()
-
 	startKeyMVCC, endKeyMVCC := storage.MVCCKey{Key: args.DataSpan.Key}, storage.MVCCKey{Key: args.DataSpan.EndKey}
 	iter := storage.MakeMultiIterator(iters)
 	defer iter.Close()
@@ -212,7 +282,7 @@ func evalImport(ctx context.Context, cArgs batcheval.CommandArgs) (*roachpb.Impo
 	for iter.SeekGE(startKeyMVCC); ; {
 		ok, err := iter.Valid()
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if !ok {
 			break
@@ -244,7 +314,7 @@ func evalImport(ctx context.Context, cArgs batcheval.CommandArgs) (*roachpb.Impo
 
 		key.Key, ok, err = kr.RewriteKey(key.Key, false /* isFromSpan */)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if !ok {
 			// If the key rewriter didn't match this key, it's not data for the
@@ -259,17 +329,9 @@ func evalImport(ctx context.Context, cArgs batcheval.CommandArgs) (*roachpb.Impo
 		value.ClearChecksum()
 		value.InitChecksum(key.Key)
 
-		if log.V(3) {
-			log.Infof(ctx, "Put %s -> %s", key.Key, value.PrettyPrint())
-		}
-		if err := batcher.AddMVCCKey(ctx, key, value.RawBytes); err != nil {
-			return nil, errors.Wrapf(err, "adding to batch: %s -> %s", key, value.PrettyPrint())
+		if err := fn(key, value); err != nil {
+			return err
 		}
 	}
-	// Flush out the last batch.
-	if err := batcher.Flush(ctx); err != nil {
-		return nil, err
-	}
-	log.Event(ctx, "done")
-	return &roachpb.ImportResponse{Imported: batcher.GetSummary()}, nil
+	return nil
 }
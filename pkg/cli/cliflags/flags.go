@@ -1173,6 +1173,55 @@ Minimum verbosity of messages written to the log file.
 `,
 	}
 
+	LogFormat = FlagInfo{
+		Name: "log-format",
+		Description: `
+Format of the entries written to log files. The supported formats are:
+<PRE>
+
+  crdb-v1        the traditional text format (default)
+  json           one JSON object per line, with descriptive field names
+  json-compact   one JSON object per line, with abbreviated field names
+
+</PRE>
+Entries written to the standard error stream always use the crdb-v1
+format. Log files in all formats can be read by 'debug merge-logs' and
+are collected by 'debug zip'.
+`,
+	}
+
+	LogSink = FlagInfo{
+		Name: "log-sink",
+		Description: `
+Send a copy of the log entries to a network collector. The flag can be
+specified multiple times to configure multiple collectors. The value is
+a URL whose scheme selects the protocol:
+<PRE>
+
+  syslog+udp://host:port    RFC 5424 syslog over UDP
+  syslog+tcp://host:port    RFC 5424 syslog over TCP
+  fluent://host:port        fluentd forward protocol
+  http(s)://host/path       JSON batches sent with HTTP POST
+
+</PRE>
+The following URL parameters are recognized:
+<PRE>
+
+  channels=main,sql-audit   only send the entries of these loggers
+  threshold=WARNING         minimum severity (default INFO)
+  buffer-size=N             number of entries buffered in memory
+  max-batch-size=N          number of entries sent at once
+  flush-interval=1s         maximum buffering delay
+  on-full=drop|block        behavior when the buffer is full
+  redact=true               remove sensitive information
+  redactable=true           keep redaction markers
+  tag=name                  fluentd tag or syslog app name
+  facility=N                syslog facility (default 16, local0)
+
+</PRE>
+`,
+	}
+
 	WriteSize = FlagInfo{
 		Name: "write-size",
 		Description: `
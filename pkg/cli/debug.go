@@ -1146,7 +1146,9 @@ are ignored. If log lines appear out of order within a file (which happens), the
 timestamp is ratcheted to the highest value seen so far. The command supports
 efficient time filtering as well as multiline regexp pattern matching via flags.
 If the filter regexp contains captures, such as '^abc(hello)def(world)', only
-the captured parts will be printed.
+the captured parts will be printed. The format of each file (crdb-v1, json or
+json-compact, see --log-format) is detected automatically; the output always
+uses the crdb-v1 format.
 `,
 	Args: cobra.MinimumNArgs(1),
 	RunE: runDebugMergeLogs,
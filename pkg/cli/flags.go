@@ -312,9 +312,11 @@ func init() {
 		case logflags.LogDirName,
 			logflags.LogFileMaxSizeName,
 			logflags.LogFilesCombinedMaxSizeName,
-			logflags.LogFileVerbosityThresholdName:
-			// The --log-dir* and --log-file* flags are specified only for the
-			// `start` and `demo` commands.
+			logflags.LogFileVerbosityThresholdName,
+			logflags.LogFormatName,
+			logflags.LogSinkName:
+			// The --log-dir*, --log-file*, --log-format and --log-sink flags
+			// are specified only for the `start` and `demo` commands.
 			return
 		}
 		pf.AddFlag(flag)
@@ -464,6 +466,12 @@ func init() {
 		varFlag(f,
 			pflag.PFlagFromGoFlag(flag.Lookup(logflags.LogFileVerbosityThresholdName)).Value,
 			cliflags.LogFileVerbosity)
+		varFlag(f,
+			pflag.PFlagFromGoFlag(flag.Lookup(logflags.LogFormatName)).Value,
+			cliflags.LogFormat)
+		varFlag(f,
+			pflag.PFlagFromGoFlag(flag.Lookup(logflags.LogSinkName)).Value,
+			cliflags.LogSink)
 
 		// Report flag usage for server commands in telemetry. We do this
 		// only for server commands, as there is no point in accumulating
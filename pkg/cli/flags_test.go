@@ -47,7 +47,9 @@ func TestStdFlagToPflag(t *testing.T) {
 		case logflags.LogDirName,
 			logflags.LogFileMaxSizeName,
 			logflags.LogFilesCombinedMaxSizeName,
-			logflags.LogFileVerbosityThresholdName:
+			logflags.LogFileVerbosityThresholdName,
+			logflags.LogFormatName,
+			logflags.LogSinkName:
 			return
 		}
 		if pf := cf.Lookup(f.Name); pf == nil {
@@ -1094,6 +1094,22 @@ func setupAndInitializeLoggingAndProfiling(
 		return nil, err
 	}
 
+	// Start the network log sinks configured with --log-sink. This is
+	// done after the redaction set up above, so that the sinks observe
+	// the final logging configuration. The sinks are flushed and stopped
+	// with the stopper.
+	stopNetSinks, err := log.StartNetworkSinks()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if stopper != nil {
+			stopper.AddCloser(stop.CloserFn(stopNetSinks))
+		} else {
+			stopNetSinks()
+		}
+	}()
+
 	// Record redaction usage for telemetry.
 	if log.RedactableLogsEnabled() {
 		telemetry.Count("server.logging.redactable_logs.enabled")
@@ -81,6 +81,16 @@ const (
 	VersionUpdateScheduledJobsSchema
 	VersionCreateLoginPrivilege
 	VersionHBAForNonTLS
+	VersionLDAPAuthentication
+	VersionExternalConnections
+	VersionUserDefinedFunctions
+	VersionDeferrableForeignKeys
+	VersionVirtualComputedColumns
+	VersionDomains
+	VersionCompositeTypes
+	VersionIndexNullsOrder
+	VersionTrigramIndexes
+	VersionDeferrableUniqueConstraints
 
 	// Add new versions here (step one of two).
 )
@@ -606,6 +616,63 @@ var versionsSingleton = keyedVersions([]keyedVersion{
 		Key:     VersionHBAForNonTLS,
 		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 21},
 	},
+	{
+		// VersionLDAPAuthentication is when the 'ldap' HBA method is
+		// introduced.
+		Key:     VersionLDAPAuthentication,
+		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 22},
+	},
+	{
+		// VersionExternalConnections adds the system.external_connections table
+		// and the CREATE EXTERNAL CONNECTION statement.
+		Key:     VersionExternalConnections,
+		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 23},
+	},
+	{
+		// VersionUserDefinedFunctions adds function descriptors and the CREATE
+		// FUNCTION statement.
+		Key:     VersionUserDefinedFunctions,
+		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 24},
+	},
+	{
+		// VersionDeferrableForeignKeys adds DEFERRABLE and INITIALLY DEFERRED
+		// foreign key constraints.
+		Key:     VersionDeferrableForeignKeys,
+		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 25},
+	},
+	{
+		// VersionVirtualComputedColumns adds virtual computed columns.
+		Key:     VersionVirtualComputedColumns,
+		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 26},
+	},
+	{
+		// VersionDomains adds DOMAIN user defined types.
+		Key:     VersionDomains,
+		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 27},
+	},
+	{
+		// VersionCompositeTypes adds composite user defined types.
+		Key:     VersionCompositeTypes,
+		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 28},
+	},
+	{
+		// VersionIndexNullsOrder adds support for indexes with a non-default NULL
+		// ordering, such as ASC NULLS LAST.
+		Key:     VersionIndexNullsOrder,
+		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 29},
+	},
+	{
+		// VersionTrigramIndexes adds support for trigram inverted indexes on
+		// string columns.
+		Key:     VersionTrigramIndexes,
+		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 30},
+	},
+	{
+		// VersionDeferrableUniqueConstraints adds support for DEFERRABLE unique
+		// constraints.
+		Key:     VersionDeferrableUniqueConstraints,
+		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 31},
+	},
 
 	// Add new versions here (step two of two).
 })
@@ -238,6 +238,10 @@ var (
 	// table and namespace IDs for the system tenant. All other tenants use a
 	// SQL sequence for this purpose. See sqlEncoder.DescIDSequenceKey.
 	descIDGenerator = roachpb.Key(makeKey(SystemPrefix, roachpb.RKey("desc-idgen")))
+	// ExternalConnectionEncryptionKey stores the randomly generated key used to
+	// encrypt the URIs of external connections. It lives outside of the SQL
+	// keyspace so that it cannot be read through SQL.
+	ExternalConnectionEncryptionKey = roachpb.Key(makeKey(SystemPrefix, roachpb.RKey("external-connection-key")))
 	// NodeIDGenerator is the global node ID generator sequence.
 	NodeIDGenerator = roachpb.Key(makeKey(SystemPrefix, roachpb.RKey("node-idgen")))
 	// RangeIDGenerator is the global range ID generator sequence.
@@ -380,6 +384,7 @@ const (
 	ScheduledJobsTableID                = 37
 	TenantsRangesID                     = 38 // pseudo
 	SqllivenessID                       = 39
+	ExternalConnectionsTableID          = 40
 
 	// CommentType is type for system.comments
 	DatabaseCommentType = 0
@@ -123,6 +123,12 @@ func (s *StatementStatistics) Add(other *StatementStatistics) {
 	s.OverheadLat.Add(other.OverheadLat, s.Count, other.Count)
 	s.BytesRead.Add(other.BytesRead, s.Count, other.Count)
 	s.RowsRead.Add(other.RowsRead, s.Count, other.Count)
+	s.MaxMemUsage.Add(other.MaxMemUsage, s.Count, other.Count)
+	s.MaxDiskUsage.Add(other.MaxDiskUsage, s.Count, other.Count)
+	s.NetworkBytes.Add(other.NetworkBytes, s.Count, other.Count)
+	s.KVTime.Add(other.KVTime, s.Count, other.Count)
+	s.CPUTime.Add(other.CPUTime, s.CPUTimeSampleCount, other.CPUTimeSampleCount)
+	s.CPUTimeSampleCount += other.CPUTimeSampleCount
 
 	if other.SensitiveInfo.LastErr != "" {
 		s.SensitiveInfo.LastErr = other.SensitiveInfo.LastErr
@@ -149,5 +155,11 @@ func (s *StatementStatistics) AlmostEqual(other *StatementStatistics, eps float6
 		s.OverheadLat.AlmostEqual(other.OverheadLat, eps) &&
 		s.SensitiveInfo.Equal(other.SensitiveInfo) &&
 		s.BytesRead.AlmostEqual(other.BytesRead, eps) &&
-		s.RowsRead.AlmostEqual(other.RowsRead, eps)
+		s.RowsRead.AlmostEqual(other.RowsRead, eps) &&
+		s.MaxMemUsage.AlmostEqual(other.MaxMemUsage, eps) &&
+		s.MaxDiskUsage.AlmostEqual(other.MaxDiskUsage, eps) &&
+		s.NetworkBytes.AlmostEqual(other.NetworkBytes, eps) &&
+		s.KVTime.AlmostEqual(other.KVTime, eps) &&
+		s.CPUTimeSampleCount == other.CPUTimeSampleCount &&
+		s.CPUTime.AlmostEqual(other.CPUTime, eps)
 }
@@ -11,6 +11,7 @@
 package sql
 
 import (
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
@@ -65,6 +66,14 @@ func (p *planner) addColumnImpl(
 	}
 	d = newDef
 
+	if d.IsVirtual() &&
+		!params.ExecCfg().Settings.Version.IsActive(params.ctx, clusterversion.VersionVirtualComputedColumns) {
+		return errVirtualColumnsNotSupported
+	}
+	if d.UniqueDeferrability != tree.ConstraintNotDeferrable {
+		return errAddDeferrableUniqueNotSupported
+	}
+
 	col, idx, expr, err := tabledesc.MakeColumnDefDescs(params.ctx, d, &params.p.semaCtx, params.EvalContext())
 	if err != nil {
 		return err
@@ -89,16 +98,28 @@ func (p *planner) addColumnImpl(
 		}
 	}
 
+	// A column of a DOMAIN type with a NOT NULL constraint is non-nullable as
+	// well, unless the domain has a default.
+	domainNotNull := false
+	if col.Type.IsDomain() && col.Type.TypeMeta.DomainData != nil {
+		for _, c := range col.Type.TypeMeta.DomainData.Constraints {
+			domainNotNull = domainNotNull || c.NotNull
+		}
+	}
+
 	// We're checking to see if a user is trying add a non-nullable column without a default to a
 	// non empty table by scanning the primary index span with a limit of 1 to see if any key exists.
-	if !col.Nullable && (col.DefaultExpr == nil && !col.IsComputed()) {
+	if (!col.Nullable || domainNotNull) && (schemaexpr.ColumnDefaultExpr(col) == nil && !col.IsComputed()) {
 		span := n.tableDesc.PrimaryIndexSpan(params.ExecCfg().Codec)
 		kvs, err := params.p.txn.Scan(params.ctx, span.Key, span.EndKey, 1)
 		if err != nil {
 			return err
 		}
 		if len(kvs) > 0 {
-			return sqlerrors.NewNonNullViolationError(col.Name)
+			if !col.Nullable {
+				return sqlerrors.NewNonNullViolationError(col.Name)
+			}
+			return schemaexpr.DomainNotNullViolationError(col.Type.Name())
 		}
 	}
 	_, err = n.tableDesc.FindActiveColumnByName(string(d.Name))
@@ -62,6 +62,9 @@ func AlterColumnType(
 	cmds tree.AlterTableCmds,
 	tn *tree.TableName,
 ) error {
+	if err := params.p.canChangeColumnWithFunctions(ctx, tableDesc, col, "alter type of"); err != nil {
+		return err
+	}
 
 	typ, err := tree.ResolveType(ctx, t.ToType, params.p.semaCtx.GetTypeResolver())
 	if err != nil {
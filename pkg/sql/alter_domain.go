@@ -0,0 +1,174 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/errors"
+)
+
+type alterDomainNode struct {
+	n    *tree.AlterDomain
+	desc *typedesc.Mutable
+}
+
+// alterDomainNode implements planNode. We set n here to satisfy the linter.
+var _ planNode = &alterDomainNode{n: nil}
+
+func (p *planner) AlterDomain(ctx context.Context, n *tree.AlterDomain) (planNode, error) {
+	// Resolve the domain.
+	desc, err := p.ResolveMutableTypeDescriptor(ctx, n.Domain, true /* required */)
+	if err != nil {
+		return nil, err
+	}
+	if desc.Kind != descpb.TypeDescriptor_DOMAIN {
+		return nil, pgerror.Newf(pgcode.WrongObjectType, "%q is not a domain", desc.Name)
+	}
+
+	// The user needs ownership privilege to alter the domain.
+	if err := p.canModifyType(ctx, desc); err != nil {
+		return nil, err
+	}
+
+	sqltelemetry.IncrementDomainCounter(sqltelemetry.DomainAlter)
+
+	return &alterDomainNode{
+		n:    n,
+		desc: desc,
+	}, nil
+}
+
+func (n *alterDomainNode) startExec(params runParams) error {
+	var err error
+	switch t := n.n.Cmd.(type) {
+	case *tree.AlterDomainSetDefault:
+		err = params.p.setDomainDefault(params.ctx, n, t.Default)
+	case *tree.AlterDomainSetNotNull:
+		if t.NotNull {
+			err = params.p.addDomainConstraint(params.ctx, n, &tree.DomainConstraint{NotNull: true})
+		} else {
+			err = params.p.dropDomainNotNull(params.ctx, n)
+		}
+	case *tree.AlterDomainAddConstraint:
+		err = params.p.addDomainConstraint(params.ctx, n, &t.Constraint)
+	case *tree.AlterDomainDropConstraint:
+		err = params.p.dropDomainConstraint(params.ctx, n, t)
+	default:
+		err = errors.AssertionFailedf("unknown alter domain cmd %s", t)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Validate the type descriptor after the changes.
+	dg := catalogkv.NewOneLevelUncachedDescGetter(params.p.txn, params.ExecCfg().Codec)
+	if err := n.desc.Validate(params.ctx, dg); err != nil {
+		return err
+	}
+
+	// Write a log event.
+	return MakeEventLogger(params.p.ExecCfg()).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		EventLogAlterType,
+		int32(n.desc.ID),
+		int32(params.extendedEvalCtx.NodeID.SQLInstanceID()),
+		struct {
+			TypeName  string
+			Statement string
+			User      string
+		}{n.desc.Name, tree.AsStringWithFQNames(n.n, params.Ann()), params.p.User()},
+	)
+}
+
+func (p *planner) setDomainDefault(ctx context.Context, n *alterDomainNode, def tree.Expr) error {
+	if def == nil {
+		n.desc.DomainDefaultExpr = nil
+	} else {
+		expr, err := schemaexpr.ValidateDomainDefaultExpr(ctx, def, n.desc.Alias, &p.semaCtx)
+		if err != nil {
+			return err
+		}
+		n.desc.DomainDefaultExpr = &expr
+	}
+	return p.writeTypeSchemaChange(ctx, n.desc, tree.AsStringWithFQNames(n.n, p.Ann()))
+}
+
+// addDomainConstraint adds a constraint to a domain. The constraint is
+// enforced for new values right away, and is validated against the existing
+// values of the domain by the type schema changer.
+func (p *planner) addDomainConstraint(
+	ctx context.Context, n *alterDomainNode, c *tree.DomainConstraint,
+) error {
+	if c.NotNull {
+		// Setting NOT NULL on a domain which is already NOT NULL is a no-op.
+		for i := range n.desc.DomainConstraints {
+			if n.desc.DomainConstraints[i].NotNull {
+				return nil
+			}
+		}
+	}
+	constraint, err := makeDomainConstraint(
+		ctx, &p.semaCtx, n.desc.Name, n.desc.Alias, c, n.desc.DomainConstraints,
+	)
+	if err != nil {
+		return err
+	}
+	constraint.Validity = descpb.ConstraintValidity_Validating
+	n.desc.DomainConstraints = append(n.desc.DomainConstraints, constraint)
+	return p.writeTypeSchemaChange(ctx, n.desc, tree.AsStringWithFQNames(n.n, p.Ann()))
+}
+
+func (p *planner) dropDomainNotNull(ctx context.Context, n *alterDomainNode) error {
+	for i := range n.desc.DomainConstraints {
+		if n.desc.DomainConstraints[i].NotNull {
+			n.desc.DomainConstraints = append(n.desc.DomainConstraints[:i], n.desc.DomainConstraints[i+1:]...)
+			return p.writeTypeSchemaChange(ctx, n.desc, tree.AsStringWithFQNames(n.n, p.Ann()))
+		}
+	}
+	// Dropping NOT NULL from a domain which is not NOT NULL is a no-op.
+	return nil
+}
+
+func (p *planner) dropDomainConstraint(
+	ctx context.Context, n *alterDomainNode, t *tree.AlterDomainDropConstraint,
+) error {
+	for i := range n.desc.DomainConstraints {
+		if n.desc.DomainConstraints[i].Name == string(t.Constraint) {
+			n.desc.DomainConstraints = append(n.desc.DomainConstraints[:i], n.desc.DomainConstraints[i+1:]...)
+			return p.writeTypeSchemaChange(ctx, n.desc, tree.AsStringWithFQNames(n.n, p.Ann()))
+		}
+	}
+	if t.IfExists {
+		p.BufferClientNotice(
+			ctx,
+			pgnotice.Newf("constraint %q of domain %q does not exist, skipping", t.Constraint, n.desc.Name),
+		)
+		return nil
+	}
+	return pgerror.Newf(pgcode.UndefinedObject,
+		"constraint %q of domain %q does not exist", t.Constraint, n.desc.Name)
+}
+
+func (n *alterDomainNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *alterDomainNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *alterDomainNode) Close(ctx context.Context)           {}
+func (n *alterDomainNode) ReadingOwnWrites()                   {}
@@ -168,6 +168,13 @@ func (p *planner) AlterPrimaryKey(
 		telemetry.Inc(sqltelemetry.HashShardedIndexCounter)
 	}
 
+	version := p.ExecCfg().Settings.Version.ActiveVersionOrEmpty(ctx)
+	if err := validateIndexOpClasses(version, tableDesc, alterPKNode.Columns, false /* inverted */); err != nil {
+		return err
+	}
+	if err := validateIndexNullsOrder(version, alterPKNode.Columns, false /* inverted */); err != nil {
+		return err
+	}
 	if err := newPrimaryIndexDesc.FillColumns(alterPKNode.Columns); err != nil {
 		return err
 	}
@@ -184,6 +191,9 @@ func (p *planner) AlterPrimaryKey(
 	// to consider the indexed columns to be newPrimaryIndexDesc.ColumnIDs.
 	newPrimaryIndexDesc.StoreColumnNames, newPrimaryIndexDesc.StoreColumnIDs = nil, nil
 	for _, col := range tableDesc.Columns {
+		if col.IsVirtual() {
+			continue
+		}
 		containsCol := false
 		for _, colID := range newPrimaryIndexDesc.ColumnIDs {
 			if colID == col.ID {
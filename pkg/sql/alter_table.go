@@ -35,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/errors"
 	"github.com/gogo/protobuf/proto"
@@ -193,11 +194,21 @@ func (n *alterTableNode) startExec(params runParams) error {
 					}
 					continue
 				}
+				if d.Deferrability != tree.ConstraintNotDeferrable {
+					return errAddDeferrableUniqueNotSupported
+				}
 				idx := descpb.IndexDescriptor{
 					Name:             string(d.Name),
 					Unique:           true,
 					StoreColumnNames: d.Storing.ToStrings(),
 				}
+				version := params.ExecCfg().Settings.Version.ActiveVersionOrEmpty(params.ctx)
+				if err := validateIndexOpClasses(version, n.tableDesc, d.Columns, false /* inverted */); err != nil {
+					return err
+				}
+				if err := validateIndexNullsOrder(version, d.Columns, false /* inverted */); err != nil {
+					return err
+				}
 				if err := idx.FillColumns(d.Columns); err != nil {
 					return err
 				}
@@ -397,6 +408,15 @@ func (n *alterTableNode) startExec(params runParams) error {
 				}
 			}
 
+			// Likewise for functions.
+			if err := params.p.dropFunctionsDependingOnColumn(
+				params.ctx, n.tableDesc, colToDrop, t.DropBehavior,
+				fmt.Sprintf("removing functions dependent on column %q which is being dropped",
+					colToDrop.ColName()),
+			); err != nil {
+				return err
+			}
+
 			// We cannot remove this column if there are computed columns that use it.
 			computedColValidator := schemaexpr.MakeComputedColumnValidator(
 				params.ctx,
@@ -654,6 +674,56 @@ func (n *alterTableNode) startExec(params runParams) error {
 			}
 			descriptorChanged = true
 
+		case *tree.AlterTableAlterConstraint:
+			info, err := n.tableDesc.GetConstraintInfo(params.ctx, nil)
+			if err != nil {
+				return err
+			}
+			constraint, ok := info[string(t.Constraint)]
+			if !ok {
+				return pgerror.Newf(pgcode.UndefinedObject,
+					"constraint %q of relation %q does not exist",
+					tree.ErrString(&t.Constraint), tree.ErrString(n.n.Table))
+			}
+			if constraint.Kind == descpb.ConstraintTypeUnique {
+				// A deferrable unique constraint is enforced by an index with a
+				// different encoding than a non-deferrable one, so only the initial
+				// mode of a deferrable unique constraint can be changed.
+				if !constraint.Index.DeferrableUnique || t.Deferrability == tree.ConstraintNotDeferrable {
+					return unimplemented.NewWithIssueDetail(31632, "alter unique deferrability",
+						"the deferrability of a unique constraint cannot be changed")
+				}
+				idx, _, err := n.tableDesc.FindIndexByName(constraint.Index.Name)
+				if err != nil {
+					return err
+				}
+				idx.InitiallyDeferred = t.Deferrability == tree.ConstraintInitiallyDeferred
+				descriptorChanged = true
+				continue
+			}
+			if constraint.Kind != descpb.ConstraintTypeFK {
+				return pgerror.Newf(pgcode.WrongObjectType,
+					"constraint %q of relation %q is not a foreign key constraint",
+					tree.ErrString(&t.Constraint), tree.ErrString(n.n.Table))
+			}
+			if t.Deferrability != tree.ConstraintNotDeferrable &&
+				!params.p.ExecCfg().Settings.Version.IsActive(params.ctx, clusterversion.VersionDeferrableForeignKeys) {
+				return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+					`deferrable foreign keys require all nodes to be upgraded to %s`,
+					clusterversion.VersionByKey(clusterversion.VersionDeferrableForeignKeys))
+			}
+			fk, err := n.tableDesc.FindFKByName(string(t.Constraint))
+			if err != nil {
+				return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+					"constraint %q in the middle of being added, try again later", t.Constraint)
+			}
+			fk.Deferrable = t.Deferrability != tree.ConstraintNotDeferrable
+			fk.InitiallyDeferred = t.Deferrability == tree.ConstraintInitiallyDeferred
+			if err := params.p.updateFKBackReferenceDeferrability(params.ctx, n.tableDesc, fk); err != nil {
+				return err
+			}
+			descriptorChanged = true
+
 		case tree.ColumnMutationCmd:
 			// Column mutations
 			col, dropped, err := n.tableDesc.FindColumnByName(t.GetColumn())
@@ -943,6 +1013,10 @@ func applyColumnMutation(
 		if !col.Nullable {
 			return nil
 		}
+		if col.IsVirtual() {
+			return unimplemented.New("virtual column not null",
+				"virtual columns with a NOT NULL constraint are not supported")
+		}
 		// See if there's already a mutation to add a not null constraint
 		for i := range tableDesc.Mutations {
 			if constraint := tableDesc.Mutations[i].GetConstraint(); constraint != nil &&
@@ -1190,6 +1264,44 @@ func (p *planner) updateFKBackReferenceName(
 	return errors.Errorf("missing backreference for foreign key %s", ref.Name)
 }
 
+// updateFKBackReferenceDeferrability updates the deferrability of the
+// backreference on the referenced table of the given foreign key to match it.
+func (p *planner) updateFKBackReferenceDeferrability(
+	ctx context.Context, tableDesc *tabledesc.Mutable, ref *descpb.ForeignKeyConstraint,
+) error {
+	var referencedTableDesc *tabledesc.Mutable
+	// We don't want to lookup/edit a second copy of the same table.
+	if tableDesc.ID == ref.ReferencedTableID {
+		referencedTableDesc = tableDesc
+	} else {
+		lookup, err := p.Descriptors().GetMutableTableVersionByID(ctx, ref.ReferencedTableID, p.txn)
+		if err != nil {
+			return errors.Errorf("error resolving referenced table ID %d: %v", ref.ReferencedTableID, err)
+		}
+		referencedTableDesc = lookup
+	}
+	if referencedTableDesc.Dropped() {
+		// The referenced table is being dropped. No need to modify it further.
+		return nil
+	}
+	for i := range referencedTableDesc.InboundFKs {
+		backref := &referencedTableDesc.InboundFKs[i]
+		if backref.Name == ref.Name && backref.OriginTableID == tableDesc.ID {
+			backref.Deferrable = ref.Deferrable
+			backref.InitiallyDeferred = ref.InitiallyDeferred
+			if referencedTableDesc == tableDesc {
+				return nil
+			}
+			return p.writeSchemaChange(
+				ctx, referencedTableDesc, descpb.InvalidMutationID,
+				fmt.Sprintf("updating referenced FK table %s(%d) for table %s(%d)",
+					referencedTableDesc.Name, referencedTableDesc.ID, tableDesc.Name, tableDesc.ID),
+			)
+		}
+	}
+	return errors.Errorf("missing backreference for foreign key %s", ref.Name)
+}
+
 // alterTableOwner sets the owner of the table to newOwner and returns true if the descriptor
 // was updated.
 func (p *planner) alterTableOwner(
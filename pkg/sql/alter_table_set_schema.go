@@ -75,6 +75,10 @@ func (p *planner) AlterTableSetSchema(
 			"set schema on",
 		)
 	}
+	// The same applies to functions.
+	if err := p.canDropRelationWithFunctions(ctx, tableDesc, "set schema on", tree.DropRestrict); err != nil {
+		return nil, err
+	}
 
 	return &alterTableSetSchemaNode{
 		newSchema: n.Schema,
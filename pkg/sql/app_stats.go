@@ -146,6 +146,25 @@ var logicalPlanCollectionPeriod = settings.RegisterPublicNonNegativeDurationSett
 	5*time.Minute,
 )
 
+// cpuTimeSampleRate is the fraction of statement executions whose DistSQL
+// flows measure the CPU time they consume. Measuring it locks the goroutines of
+// the flows to their OS threads, so it is only done for a sample of the
+// executions.
+var cpuTimeSampleRate = func() *settings.FloatSetting {
+	s := settings.RegisterValidatedFloatSetting(
+		"sql.metrics.statement_details.cpu_time.sample_rate",
+		"the fraction of statement executions that measure the CPU time consumed by their DistSQL flows",
+		0.01,
+		func(v float64) error {
+			if v < 0 || v > 1 {
+				return errors.Errorf("sample rate must be between 0 and 1")
+			}
+			return nil
+		})
+	s.SetVisibility(settings.Public)
+	return s
+}()
+
 func (s stmtKey) String() string {
 	if s.failed {
 		return "!" + s.anonymizedStmt
@@ -216,6 +235,14 @@ func (a *appStats) recordStatement(
 	s.mu.data.OverheadLat.Record(s.mu.data.Count, ovhLat)
 	s.mu.data.BytesRead.Record(s.mu.data.Count, float64(stats.bytesRead))
 	s.mu.data.RowsRead.Record(s.mu.data.Count, float64(stats.rowsRead))
+	s.mu.data.MaxMemUsage.Record(s.mu.data.Count, float64(stats.maxMemUsage))
+	s.mu.data.MaxDiskUsage.Record(s.mu.data.Count, float64(stats.maxDiskUsage))
+	s.mu.data.NetworkBytes.Record(s.mu.data.Count, float64(stats.networkBytes))
+	s.mu.data.KVTime.Record(s.mu.data.Count, stats.kvTime.Seconds())
+	if stats.cpuTimeMeasured {
+		s.mu.data.CPUTimeSampleCount++
+		s.mu.data.CPUTime.Record(s.mu.data.CPUTimeSampleCount, stats.cpuTime.Seconds())
+	}
 	s.mu.vectorized = vectorized
 	s.mu.distSQLUsed = distSQLUsed
 	s.mu.Unlock()
@@ -718,6 +745,16 @@ func quantizeCounts(d *roachpb.StatementStatistics) {
 	d.RunLat.SquaredDiffs = (d.RunLat.SquaredDiffs / oldCountMinusOne) * newCountMinusOne
 	d.ServiceLat.SquaredDiffs = (d.ServiceLat.SquaredDiffs / oldCountMinusOne) * newCountMinusOne
 	d.OverheadLat.SquaredDiffs = (d.OverheadLat.SquaredDiffs / oldCountMinusOne) * newCountMinusOne
+	d.MaxMemUsage.SquaredDiffs = (d.MaxMemUsage.SquaredDiffs / oldCountMinusOne) * newCountMinusOne
+	d.MaxDiskUsage.SquaredDiffs = (d.MaxDiskUsage.SquaredDiffs / oldCountMinusOne) * newCountMinusOne
+	d.NetworkBytes.SquaredDiffs = (d.NetworkBytes.SquaredDiffs / oldCountMinusOne) * newCountMinusOne
+	d.KVTime.SquaredDiffs = (d.KVTime.SquaredDiffs / oldCountMinusOne) * newCountMinusOne
+	// The CPU time is aggregated over its own sample count.
+	if oldSampleCount := d.CPUTimeSampleCount; oldSampleCount > 1 {
+		newSampleCount := telemetry.Bucket10(oldSampleCount)
+		d.CPUTimeSampleCount = newSampleCount
+		d.CPUTime.SquaredDiffs = (d.CPUTime.SquaredDiffs / float64(oldSampleCount-1)) * float64(newSampleCount-1)
+	}
 
 	d.MaxRetries = telemetry.Bucket10(d.MaxRetries)
 
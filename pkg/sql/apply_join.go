@@ -243,10 +243,21 @@ func runPlanInsidePlan(
 	)
 	defer recv.Release()
 
+	// Subqueries nested in the plan, including the ones in its own subqueries,
+	// must be evaluated against the plan rather than the outer plan, so use a
+	// copy of the planner and the EvalContext that refers to it.
+	plannerCopy := *params.p
+	plannerCopy.curPlan.planComponents = *plan
+	evalCtxFactory := func() *extendedEvalContext {
+		evalCtx := params.p.ExtendedEvalContextCopy()
+		evalCtx.Planner = &plannerCopy
+		return evalCtx
+	}
+
 	if !params.p.extendedEvalCtx.ExecCfg.DistSQLPlanner.PlanAndRunSubqueries(
 		params.ctx,
-		params.p,
-		params.extendedEvalCtx.copy,
+		&plannerCopy,
+		evalCtxFactory,
 		plan.subqueryPlans,
 		recv,
 		false, /* maybeDistribute */
@@ -257,14 +268,10 @@ func runPlanInsidePlan(
 		return recv.commErr
 	}
 
-	// Make a copy of the EvalContext so it can be safely modified.
-	evalCtx := params.p.ExtendedEvalContextCopy()
-	plannerCopy := *params.p
+	evalCtx := evalCtxFactory()
 	planCtx := params.p.extendedEvalCtx.ExecCfg.DistSQLPlanner.NewPlanningCtx(
 		params.ctx, evalCtx, &plannerCopy, params.p.txn, false, /* distribute */
 	)
-	planCtx.planner.curPlan.planComponents = *plan
-	planCtx.ExtendedEvalCtx.Planner = &plannerCopy
 	planCtx.stmtType = recv.stmtType
 
 	params.p.extendedEvalCtx.ExecCfg.DistSQLPlanner.PlanAndRun(
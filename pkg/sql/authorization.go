@@ -340,7 +340,7 @@ func (p *planner) MemberOfWithAdminOption(
 		}
 
 		// Lookup memberships outside the lock.
-		memberships, err := p.resolveMemberOfWithAdminOption(ctx, member)
+		memberships, err := resolveMemberOfWithAdminOption(ctx, p.ExecCfg().InternalExecutor, member)
 		if err != nil {
 			return nil, err
 		}
@@ -365,8 +365,8 @@ func (p *planner) MemberOfWithAdminOption(
 // TODO(mberhault): this is the naive way and performs a full lookup for each user,
 // we could save detailed memberships (as opposed to fully expanded) and reuse them
 // across users. We may then want to lookup more than just this user.
-func (p *planner) resolveMemberOfWithAdminOption(
-	ctx context.Context, member string,
+func resolveMemberOfWithAdminOption(
+	ctx context.Context, ie *InternalExecutor, member string,
 ) (map[string]bool, error) {
 	ret := map[string]bool{}
 
@@ -384,7 +384,7 @@ func (p *planner) resolveMemberOfWithAdminOption(
 		}
 		visited[m] = struct{}{}
 
-		rows, err := p.ExecCfg().InternalExecutor.Query(
+		rows, err := ie.Query(
 			ctx, "expand-roles", nil /* txn */, lookupRolesStmt, m,
 		)
 		if err != nil {
@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemaexpr"
@@ -82,6 +83,11 @@ func (cb *ColumnBackfiller) initCols(desc *tabledesc.Immutable) {
 		for _, m := range desc.Mutations {
 			if ColumnMutationFilter(m) {
 				desc := *m.GetColumn()
+				if desc.IsVirtual() {
+					// Virtual columns are not stored, so there is nothing to
+					// backfill.
+					continue
+				}
 				switch m.Direction {
 				case descpb.DescriptorMutation_ADD:
 					cb.added = append(cb.added, desc)
@@ -120,9 +126,14 @@ func (cb *ColumnBackfiller) init(
 		cb.updateExprs[j+len(cb.added)] = tree.DNull
 	}
 
-	// We need all the columns.
+	// We need all the columns, except for the virtual columns which are not
+	// stored.
 	var valNeededForCol util.FastIntSet
-	valNeededForCol.AddRange(0, len(desc.Columns)-1)
+	for i := range desc.Columns {
+		if !desc.Columns[i].IsVirtual() {
+			valNeededForCol.Add(i)
+		}
+	}
 
 	tableArgs := row.FetcherTableArgs{
 		Desc:            desc,
@@ -393,6 +404,12 @@ type IndexBackfiller struct {
 	// predicates is a map of indexes to partial index predicate expressions. It
 	// includes entries for partial indexes only.
 	predicates map[descpb.IndexID]tree.TypedExpr
+	// virtualCols are the ordinals in cols of the virtual columns that are
+	// needed to backfill the added indexes, and virtualExprs are their computed
+	// expressions. Virtual columns are not stored, so their values are computed
+	// from the fetched columns of each row.
+	virtualCols  []int
+	virtualExprs []tree.TypedExpr
 	// indexesToEncode is a list of indexes to encode entries for a given row.
 	// It is a field of IndexBackfiller to avoid allocating a slice for each row
 	// backfilled.
@@ -450,6 +467,11 @@ func (ib *IndexBackfiller) InitForLocalUse(
 		valNeededForCol.Add(ib.colIdxMap[col])
 	})
 
+	valNeededForCol, err = ib.initVirtualCols(ctx, evalCtx, semaCtx, desc, valNeededForCol)
+	if err != nil {
+		return err
+	}
+
 	return ib.init(evalCtx, predicates, valNeededForCol, desc, mon)
 }
 
@@ -491,7 +513,14 @@ func (ib *IndexBackfiller) InitForDistributedUse(
 			return err
 		}
 
-		return nil
+		// Add the columns referenced in the predicate to valNeededForCol so that
+		// columns necessary to evaluate the predicate expression are fetched.
+		predicateRefColIDs.ForEach(func(col descpb.ColumnID) {
+			valNeededForCol.Add(ib.colIdxMap[col])
+		})
+
+		valNeededForCol, err = ib.initVirtualCols(ctx, evalCtx, &semaCtx, desc, valNeededForCol)
+		return err
 	}); err != nil {
 		return err
 	}
@@ -500,12 +529,6 @@ func (ib *IndexBackfiller) InitForDistributedUse(
 	// entire backfill process.
 	flowCtx.TypeResolverFactory.Descriptors.ReleaseAll(ctx)
 
-	// Add the columns referenced in the predicate to valNeededForCol so that
-	// columns necessary to evaluate the predicate expression are fetched.
-	predicateRefColIDs.ForEach(func(col descpb.ColumnID) {
-		valNeededForCol.Add(ib.colIdxMap[col])
-	})
-
 	return ib.init(evalCtx, predicates, valNeededForCol, desc, mon)
 }
 
@@ -579,6 +602,60 @@ func (ib *IndexBackfiller) initIndexes(desc *tabledesc.Immutable) util.FastIntSe
 	return valNeededForCol
 }
 
+// initVirtualCols builds the computed expressions of the virtual columns in
+// valNeededForCol. Virtual columns are not stored, so they are replaced in the
+// returned set of column ordinals to fetch by the columns that their
+// expressions reference.
+func (ib *IndexBackfiller) initVirtualCols(
+	ctx context.Context,
+	evalCtx *tree.EvalContext,
+	semaCtx *tree.SemaContext,
+	desc *tabledesc.Immutable,
+	valNeededForCol util.FastIntSet,
+) (util.FastIntSet, error) {
+	ib.virtualCols = ib.virtualCols[:0]
+	var cols []descpb.ColumnDescriptor
+	for i := range ib.cols {
+		if ib.cols[i].IsVirtual() && valNeededForCol.Contains(i) {
+			ib.virtualCols = append(ib.virtualCols, i)
+			cols = append(cols, ib.cols[i])
+		}
+	}
+	if len(cols) == 0 {
+		return valNeededForCol, nil
+	}
+
+	exprs, err := schemaexpr.MakeComputedExprs(
+		ctx,
+		cols,
+		desc,
+		tree.NewUnqualifiedTableName(tree.Name(desc.Name)),
+		evalCtx,
+		semaCtx,
+	)
+	if err != nil {
+		return util.FastIntSet{}, err
+	}
+	ib.virtualExprs = exprs
+
+	valNeededForCol = valNeededForCol.Copy()
+	for i, ord := range ib.virtualCols {
+		valNeededForCol.Remove(ord)
+		expr, err := parser.ParseExpr(*cols[i].ComputeExpr)
+		if err != nil {
+			return util.FastIntSet{}, err
+		}
+		colIDs, err := schemaexpr.ExtractColumnIDs(desc, expr)
+		if err != nil {
+			return util.FastIntSet{}, err
+		}
+		colIDs.ForEach(func(col descpb.ColumnID) {
+			valNeededForCol.Add(ib.colIdxMap[col])
+		})
+	}
+	return valNeededForCol, nil
+}
+
 // init completes the initialization of an IndexBackfiller.
 func (ib *IndexBackfiller) init(
 	evalCtx *tree.EvalContext,
@@ -703,6 +780,15 @@ func (ib *IndexBackfiller) BuildIndexEntriesChunk(
 
 		iv.CurSourceRow = ib.rowVals
 
+		// Compute the values of the virtual columns, which are not stored.
+		for j, ord := range ib.virtualCols {
+			val, err := ib.virtualExprs[j].Eval(ib.evalCtx)
+			if err != nil {
+				return nil, nil, err
+			}
+			ib.rowVals[ord] = val
+		}
+
 		// If there are any partial indexes being added, make a list of the
 		// indexes that the current row should be added to.
 		if len(ib.predicates) > 0 {
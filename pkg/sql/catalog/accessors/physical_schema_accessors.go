@@ -135,6 +135,20 @@ func (a *CachedPhysicalAccessor) GetObjectDesc(
 			return nil, err
 		}
 		return table, err
+	case tree.FunctionObject:
+		a.tableName = tree.MakeTableNameWithSchema(tree.Name(db), tree.Name(schema), tree.Name(object))
+		if flags.RequireMutable {
+			fn, err := a.tc.GetMutableFunctionDescriptor(ctx, txn, &a.tableName, flags)
+			if fn == nil {
+				return nil, err
+			}
+			return fn, err
+		}
+		fn, err := a.tc.GetFunctionVersion(ctx, txn, &a.tableName, flags)
+		if fn == nil {
+			return nil, err
+		}
+		return fn, err
 	default:
 		return nil, errors.AssertionFailedf("unknown desired object kind %d", flags.DesiredObjectKind)
 	}
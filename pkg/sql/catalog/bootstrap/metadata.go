@@ -342,6 +342,7 @@ func addSystemDescriptorsToSchema(target *MetadataSchema) {
 
 	target.AddDescriptor(keys.SystemDatabaseID, systemschema.ScheduledJobsTable)
 	target.AddDescriptor(keys.SystemDatabaseID, systemschema.SqllivenessTable)
+	target.AddDescriptor(keys.SystemDatabaseID, systemschema.ExternalConnectionsTable)
 }
 
 // addSplitIDs adds a split point for each of the PseudoTableIDs to the supplied
@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkeys"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
@@ -287,8 +288,8 @@ func unwrapDescriptor(
 	ctx context.Context, dg catalog.DescGetter, ts hlc.Timestamp, desc *descpb.Descriptor,
 ) (catalog.Descriptor, error) {
 	descpb.MaybeSetDescriptorModificationTimeFromMVCCTimestamp(ctx, desc, ts)
-	table, database, typ, schema := descpb.TableFromDescriptor(desc, hlc.Timestamp{}),
-		desc.GetDatabase(), desc.GetType(), desc.GetSchema()
+	table, database, typ, schema, fn := descpb.TableFromDescriptor(desc, hlc.Timestamp{}),
+		desc.GetDatabase(), desc.GetType(), desc.GetSchema(), desc.GetFunction()
 	switch {
 	case table != nil:
 		immTable, err := tabledesc.NewFilledInImmutable(ctx, dg, table)
@@ -309,6 +310,8 @@ func unwrapDescriptor(
 		return typedesc.NewImmutable(*typ), nil
 	case schema != nil:
 		return schemadesc.NewImmutable(*schema), nil
+	case fn != nil:
+		return funcdesc.NewImmutable(*fn), nil
 	default:
 		return nil, nil
 	}
@@ -321,9 +324,9 @@ func unwrapDescriptorMutable(
 	ctx context.Context, dg catalog.DescGetter, ts hlc.Timestamp, desc *descpb.Descriptor,
 ) (catalog.MutableDescriptor, error) {
 	descpb.MaybeSetDescriptorModificationTimeFromMVCCTimestamp(ctx, desc, ts)
-	table, database, typ, schema :=
+	table, database, typ, schema, fn :=
 		descpb.TableFromDescriptor(desc, hlc.Timestamp{}),
-		desc.GetDatabase(), desc.GetType(), desc.GetSchema()
+		desc.GetDatabase(), desc.GetType(), desc.GetSchema(), desc.GetFunction()
 	switch {
 	case table != nil:
 		mutTable, err := tabledesc.NewFilledInExistingMutable(ctx, dg, false /* skipFKsWithMissingTable */, table)
@@ -344,6 +347,8 @@ func unwrapDescriptorMutable(
 		return typedesc.NewExistingMutable(*typ), nil
 	case schema != nil:
 		return schemadesc.NewMutableExisting(*schema), nil
+	case fn != nil:
+		return funcdesc.NewMutableExisting(*fn), nil
 	default:
 		return nil, nil
 	}
@@ -642,8 +647,8 @@ func GetSchemaDescriptorsFromIDs(
 // TODO(ajwerner): unify this with the other unwrapping logic.
 func UnwrapDescriptorRaw(ctx context.Context, desc *descpb.Descriptor) catalog.MutableDescriptor {
 	descpb.MaybeSetDescriptorModificationTimeFromMVCCTimestamp(ctx, desc, hlc.Timestamp{})
-	table, database, typ, schema := descpb.TableFromDescriptor(desc, hlc.Timestamp{}),
-		desc.GetDatabase(), desc.GetType(), desc.GetSchema()
+	table, database, typ, schema, fn := descpb.TableFromDescriptor(desc, hlc.Timestamp{}),
+		desc.GetDatabase(), desc.GetType(), desc.GetSchema(), desc.GetFunction()
 	switch {
 	case table != nil:
 		return tabledesc.NewExistingMutable(*table)
@@ -653,6 +658,8 @@ func UnwrapDescriptorRaw(ctx context.Context, desc *descpb.Descriptor) catalog.M
 		return typedesc.NewExistingMutable(*typ)
 	case schema != nil:
 		return schemadesc.NewMutableExisting(*schema)
+	case fn != nil:
+		return funcdesc.NewMutableExisting(*fn)
 	default:
 		log.Fatalf(ctx, "failed to unwrap descriptor of type %T", desc.Union)
 		return nil // unreachable
@@ -150,6 +150,8 @@ const (
 	PgCatalogStatActivityTableID
 	PgCatalogSecurityLabelTableID
 	PgCatalogSharedSecurityLabelTableID
+	PgCatalogCursorsTableID
+	InformationSchemaDomainsTableID
 	PgExtensionSchemaID
 	PgExtensionGeographyColumnsTableID
 	PgExtensionGeometryColumnsTableID
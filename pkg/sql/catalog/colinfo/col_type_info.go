@@ -97,6 +97,19 @@ func ValidateColumnDefType(t *types.T) error {
 		}
 		return ValidateColumnDefType(t.ArrayContents())
 
+	case types.TupleFamily:
+		// Only composite types can be used for table columns, and their fields
+		// must be valid column types.
+		if !t.IsComposite() {
+			return pgerror.Newf(pgcode.InvalidTableDefinition,
+				"value type %s cannot be used for table columns", t.String())
+		}
+		for _, typ := range t.TupleContents() {
+			if err := ValidateColumnDefType(typ); err != nil {
+				return err
+			}
+		}
+
 	case types.BitFamily, types.IntFamily, types.FloatFamily, types.BoolFamily, types.BytesFamily, types.DateFamily,
 		types.INetFamily, types.IntervalFamily, types.JsonFamily, types.OidFamily, types.TimeFamily,
 		types.TimestampFamily, types.TimestampTZFamily, types.UuidFamily, types.TimeTZFamily,
@@ -126,6 +139,13 @@ func ColumnTypeIsInvertedIndexable(t *types.T) bool {
 		family == types.GeographyFamily || family == types.GeometryFamily
 }
 
+// ColumnTypeIsTrigramIndexable returns whether the type t is valid to be
+// indexed using a trigram index, which is an inverted index on the trigrams
+// of a string.
+func ColumnTypeIsTrigramIndexable(t *types.T) bool {
+	return t.Family() == types.StringFamily
+}
+
 // MustBeValueEncoded returns true if columns of the given kind can only be value
 // encoded.
 func MustBeValueEncoded(semanticType *types.T) bool {
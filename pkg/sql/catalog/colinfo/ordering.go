@@ -22,6 +22,9 @@ import (
 type ColumnOrderInfo struct {
 	ColIdx    int
 	Direction encoding.Direction
+	// NullsReversed is true if NULLs are ordered opposite to the default for
+	// the direction; i.e. last if ascending, or first if descending.
+	NullsReversed bool
 }
 
 // ColumnOrdering is used to describe a desired column ordering. For example,
@@ -44,6 +47,13 @@ func (ordering ColumnOrdering) String(columns ResultColumns) string {
 
 		fmtCtx.FormatNameP(&columns[o.ColIdx].Name)
 		_, _ = fmtCtx.WriteTo(&buf)
+		if o.NullsReversed {
+			if o.Direction == encoding.Descending {
+				buf.WriteString(":nulls-first")
+			} else {
+				buf.WriteString(":nulls-last")
+			}
+		}
 	}
 	fmtCtx.Close()
 	return buf.String()
@@ -63,6 +73,9 @@ func CompareDatums(ordering ColumnOrdering, evalCtx *tree.EvalContext, lhs, rhs
 		// types for a column for different rows. Investigate how other RDBMs
 		// handle this.
 		if cmp := lhs[c.ColIdx].Compare(evalCtx, rhs[c.ColIdx]); cmp != 0 {
+			if c.NullsReversed && (lhs[c.ColIdx] == tree.DNull) != (rhs[c.ColIdx] == tree.DNull) {
+				cmp = -cmp
+			}
 			if c.Direction == encoding.Descending {
 				cmp = -cmp
 			}
@@ -40,6 +40,12 @@ func (desc *ColumnDescriptor) IsComputed() bool {
 	return desc.ComputeExpr != nil
 }
 
+// IsVirtual returns true if this is a virtual computed column, which is not
+// stored in the table.
+func (desc *ColumnDescriptor) IsVirtual() bool {
+	return desc.Virtual
+}
+
 // ColName returns the name of the column as a tree.Name.
 func (desc *ColumnDescriptor) ColName() tree.Name {
 	return tree.Name(desc.Name)
@@ -89,7 +95,11 @@ func (desc *ColumnDescriptor) SQLStringNotHumanReadable() string {
 	if desc.IsComputed() {
 		f.WriteString(" AS (")
 		f.WriteString(*desc.ComputeExpr)
-		f.WriteString(") STORED")
+		if desc.IsVirtual() {
+			f.WriteString(") VIRTUAL")
+		} else {
+			f.WriteString(") STORED")
+		}
 	}
 	return f.CloseAndGetString()
 }
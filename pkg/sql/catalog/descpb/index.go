@@ -65,6 +65,31 @@ func (desc *IndexDescriptor) IsPartial() bool {
 	return desc.Predicate != ""
 }
 
+// SetUniqueDeferrability sets the deferrability of the unique constraint of
+// the index. A deferrable unique index is encoded like a non-unique index, so
+// it stops being Unique.
+func (desc *IndexDescriptor) SetUniqueDeferrability(d tree.ConstraintDeferrability) {
+	if d == tree.ConstraintNotDeferrable {
+		return
+	}
+	desc.Unique = false
+	desc.DeferrableUnique = true
+	desc.InitiallyDeferred = d == tree.ConstraintInitiallyDeferred
+}
+
+// UniqueDeferrability returns the deferrability of the unique constraint of
+// the index.
+func (desc *IndexDescriptor) UniqueDeferrability() tree.ConstraintDeferrability {
+	switch {
+	case !desc.DeferrableUnique:
+		return tree.ConstraintNotDeferrable
+	case desc.InitiallyDeferred:
+		return tree.ConstraintInitiallyDeferred
+	default:
+		return tree.ConstraintInitiallyImmediate
+	}
+}
+
 // ColNamesFormat writes a string describing the column names and directions
 // in this index to the given buffer.
 func (desc *IndexDescriptor) ColNamesFormat(ctx *tree.FmtCtx) {
@@ -80,15 +105,51 @@ func (desc *IndexDescriptor) ColNamesFormat(ctx *tree.FmtCtx) {
 		if desc.Type != IndexDescriptor_INVERTED {
 			ctx.WriteByte(' ')
 			ctx.WriteString(desc.ColumnDirections[i].String())
+			if desc.ColumnNullsReversedAt(i) {
+				ctx.WriteByte(' ')
+				ctx.WriteString(desc.ColumnNullsOrder(i).String())
+			}
 		}
 	}
 }
 
-// FillColumns sets the column names and directions in desc.
+// ColumnNullsReversedAt returns whether the NULL ordering of the i-th key
+// column of the index is reversed, i.e. whether NULLs sort last in an
+// ascending column or first in a descending column. The extra columns of
+// non-unique indexes never have a reversed NULL ordering.
+func (desc *IndexDescriptor) ColumnNullsReversedAt(i int) bool {
+	return i < len(desc.ColumnNullsReversed) && desc.ColumnNullsReversed[i]
+}
+
+// ColumnNullsOrder returns the NULL ordering of the i-th column of the index.
+func (desc *IndexDescriptor) ColumnNullsOrder(i int) tree.NullsOrder {
+	nullsFirst := desc.ColumnDirections[i] == IndexDescriptor_ASC
+	if desc.ColumnNullsReversedAt(i) {
+		nullsFirst = !nullsFirst
+	}
+	if nullsFirst {
+		return tree.NullsFirst
+	}
+	return tree.NullsLast
+}
+
+// HasReversedNulls returns whether any column of the index has a reversed NULL
+// ordering.
+func (desc *IndexDescriptor) HasReversedNulls() bool {
+	for _, reversed := range desc.ColumnNullsReversed {
+		if reversed {
+			return true
+		}
+	}
+	return false
+}
+
+// FillColumns sets the column names, directions and NULL orderings in desc.
 func (desc *IndexDescriptor) FillColumns(elems tree.IndexElemList) error {
 	desc.ColumnNames = make([]string, 0, len(elems))
 	desc.ColumnDirections = make([]IndexDescriptor_Direction, 0, len(elems))
-	for _, c := range elems {
+	desc.ColumnNullsReversed = nil
+	for i, c := range elems {
 		desc.ColumnNames = append(desc.ColumnNames, string(c.Column))
 		switch c.Direction {
 		case tree.Ascending, tree.DefaultDirection:
@@ -98,6 +159,14 @@ func (desc *IndexDescriptor) FillColumns(elems tree.IndexElemList) error {
 		default:
 			return fmt.Errorf("invalid direction %s for column %s", c.Direction, c.Column)
 		}
+		if c.NullsOrder.IsReversed(c.Direction) {
+			// The list is only populated if at least one column has a reversed
+			// NULL ordering.
+			if desc.ColumnNullsReversed == nil {
+				desc.ColumnNullsReversed = make([]bool, len(elems))
+			}
+			desc.ColumnNullsReversed[i] = true
+		}
 	}
 	return nil
 }
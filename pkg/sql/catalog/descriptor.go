@@ -90,6 +90,13 @@ type SchemaDescriptor interface {
 	SchemaDesc() *descpb.SchemaDescriptor
 }
 
+// FunctionDescriptor will eventually be called funcdesc.Descriptor.
+// It is implemented by Immutable.
+type FunctionDescriptor interface {
+	Descriptor
+	FuncDesc() *descpb.FunctionDescriptor
+}
+
 // TableDescriptor is an interface around the table descriptor types.
 type TableDescriptor interface {
 	Descriptor
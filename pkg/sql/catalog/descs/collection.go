@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/database"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/hydratedtables"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/lease"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
@@ -1414,6 +1415,84 @@ func (tc *Collection) GetTypeVersionByID(
 	return typ, nil
 }
 
+// User defined function accessors.
+
+// GetMutableFunctionDescriptor is the equivalent of GetMutableTableDescriptor
+// but for accessing functions.
+func (tc *Collection) GetMutableFunctionDescriptor(
+	ctx context.Context, txn *kv.Txn, tn *tree.TableName, flags tree.ObjectLookupFlags,
+) (*funcdesc.Mutable, error) {
+	desc, err := tc.getMutableObjectDescriptor(ctx, txn, tn, flags)
+	if err != nil {
+		return nil, err
+	}
+	mutDesc, ok := desc.(*funcdesc.Mutable)
+	if !ok {
+		if flags.Required {
+			return nil, sqlerrors.NewUndefinedFunctionError(tn)
+		}
+		return nil, nil
+	}
+	return mutDesc, nil
+}
+
+// GetMutableFunctionVersionByID is the equivalent of
+// GetMutableTableDescriptorByID but for accessing functions.
+func (tc *Collection) GetMutableFunctionVersionByID(
+	ctx context.Context, txn *kv.Txn, funcID descpb.ID,
+) (*funcdesc.Mutable, error) {
+	desc, err := tc.GetMutableDescriptorByID(ctx, funcID, txn)
+	if err != nil {
+		return nil, err
+	}
+	fn, ok := desc.(*funcdesc.Mutable)
+	if !ok {
+		return nil, pgerror.Newf(
+			pgcode.UndefinedFunction, "function with ID %d does not exist", funcID)
+	}
+	return fn, nil
+}
+
+// GetFunctionVersion is the equivalent of GetTableVersion but for accessing
+// functions.
+func (tc *Collection) GetFunctionVersion(
+	ctx context.Context, txn *kv.Txn, tn *tree.TableName, flags tree.ObjectLookupFlags,
+) (*funcdesc.Immutable, error) {
+	desc, err := tc.getObjectVersion(ctx, txn, tn, flags)
+	if err != nil {
+		return nil, err
+	}
+	fn, ok := desc.(*funcdesc.Immutable)
+	if !ok {
+		if flags.Required {
+			return nil, sqlerrors.NewUndefinedFunctionError(tn)
+		}
+		return nil, nil
+	}
+	return fn, nil
+}
+
+// GetFunctionVersionByID is the equivalent of GetTableVersionByID but for
+// accessing functions.
+func (tc *Collection) GetFunctionVersionByID(
+	ctx context.Context, txn *kv.Txn, funcID descpb.ID, flags tree.ObjectLookupFlags,
+) (*funcdesc.Immutable, error) {
+	desc, err := tc.getDescriptorVersionByID(ctx, txn, funcID, flags.CommonLookupFlags, true /* setTxnDeadline */)
+	if err != nil {
+		if errors.Is(err, catalog.ErrDescriptorNotFound) {
+			return nil, pgerror.Newf(
+				pgcode.UndefinedFunction, "function with ID %d does not exist", funcID)
+		}
+		return nil, err
+	}
+	fn, ok := desc.(*funcdesc.Immutable)
+	if !ok {
+		return nil, pgerror.Newf(
+			pgcode.UndefinedFunction, "function with ID %d does not exist", funcID)
+	}
+	return fn, nil
+}
+
 // DBAction is an operation to an uncommitted database.
 type DBAction bool
 
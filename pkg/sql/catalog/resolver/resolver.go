@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkeys"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catconstants"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
@@ -143,6 +144,24 @@ func ResolveMutableType(
 	return &tn, desc.(*typedesc.Mutable), nil
 }
 
+// ResolveMutableFunction resolves a function descriptor for mutable access.
+// It returns the resolved descriptor, as well as the fully qualified resolved
+// object name.
+func ResolveMutableFunction(
+	ctx context.Context, sc SchemaResolver, un *tree.UnresolvedObjectName, required bool,
+) (*tree.TableName, *funcdesc.Mutable, error) {
+	lookupFlags := tree.ObjectLookupFlags{
+		CommonLookupFlags: tree.CommonLookupFlags{Required: required, RequireMutable: true},
+		DesiredObjectKind: tree.FunctionObject,
+	}
+	desc, prefix, err := ResolveExistingObject(ctx, sc, un, lookupFlags)
+	if err != nil || desc == nil {
+		return nil, nil, err
+	}
+	fn := tree.MakeTableNameFromPrefix(prefix, tree.Name(un.Object()))
+	return &fn, desc.(*funcdesc.Mutable), nil
+}
+
 // ResolveExistingObject resolves an object with the given flags.
 func ResolveExistingObject(
 	ctx context.Context,
@@ -174,6 +193,15 @@ func ResolveExistingObject(
 			return obj.(*typedesc.Mutable), prefix, nil
 		}
 		return obj.(*typedesc.Immutable), prefix, nil
+	case tree.FunctionObject:
+		_, isFunc := obj.(catalog.FunctionDescriptor)
+		if !isFunc {
+			return nil, prefix, sqlerrors.NewUndefinedFunctionError(&resolvedTn)
+		}
+		if lookupFlags.RequireMutable {
+			return obj.(*funcdesc.Mutable), prefix, nil
+		}
+		return obj.(*funcdesc.Immutable), prefix, nil
 	case tree.TableObject:
 		table, ok := obj.(catalog.TableDescriptor)
 		if !ok {
@@ -44,6 +44,8 @@ func ShouldSplitAtDesc(rawDesc *roachpb.Value) bool {
 		return false
 	case *descpb.Descriptor_Schema:
 		return false
+	case *descpb.Descriptor_Function:
+		return false
 	default:
 		panic(errors.AssertionFailedf("unexpected descriptor type %#v", &desc))
 	}
@@ -342,6 +344,16 @@ CREATE TABLE system.sqlliveness (
     expiration       DECIMAL NOT NULL,
   	FAMILY fam0_session_id_expiration (session_id, expiration)
 )`
+
+	ExternalConnectionsTableSchema = `
+CREATE TABLE system.external_connections (
+    connection_name    STRING PRIMARY KEY NOT NULL,
+    created            TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated            TIMESTAMPTZ NOT NULL DEFAULT now(),
+    connection_details BYTES NOT NULL,
+    privileges         BYTES NOT NULL,
+    FAMILY "primary" (connection_name, created, updated, connection_details, privileges)
+)`
 )
 
 func pk(name string) descpb.IndexDescriptor {
@@ -1684,6 +1696,39 @@ var (
 		FormatVersion:  descpb.InterleavedFormatVersion,
 		NextMutationID: 1,
 	})
+
+	// ExternalConnectionsTable is the descriptor for the external connections
+	// table.
+	ExternalConnectionsTable = tabledesc.NewImmutable(descpb.TableDescriptor{
+		Name:                    "external_connections",
+		ID:                      keys.ExternalConnectionsTableID,
+		ParentID:                keys.SystemDatabaseID,
+		UnexposedParentSchemaID: keys.PublicSchemaID,
+		Version:                 1,
+		Columns: []descpb.ColumnDescriptor{
+			{Name: "connection_name", ID: 1, Type: types.String, Nullable: false},
+			{Name: "created", ID: 2, Type: types.TimestampTZ, DefaultExpr: &nowTZString, Nullable: false},
+			{Name: "updated", ID: 3, Type: types.TimestampTZ, DefaultExpr: &nowTZString, Nullable: false},
+			{Name: "connection_details", ID: 4, Type: types.Bytes, Nullable: false},
+			{Name: "privileges", ID: 5, Type: types.Bytes, Nullable: false},
+		},
+		NextColumnID: 6,
+		Families: []descpb.ColumnFamilyDescriptor{
+			{
+				Name:        "primary",
+				ID:          0,
+				ColumnNames: []string{"connection_name", "created", "updated", "connection_details", "privileges"},
+				ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4, 5},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: pk("connection_name"),
+		NextIndexID:  2,
+		Privileges: descpb.NewCustomSuperuserPrivilegeDescriptor(
+			descpb.SystemAllowedPrivileges[keys.ExternalConnectionsTableID], security.NodeUser),
+		FormatVersion:  descpb.InterleavedFormatVersion,
+		NextMutationID: 1,
+	})
 )
 
 // newCommentPrivilegeDescriptor returns a privilege descriptor for comment table
@@ -362,7 +362,7 @@ func allocateIndexName(tableDesc *Mutable, idx *descpb.IndexDescriptor) {
 	segments := make([]string, 0, len(idx.ColumnNames)+2)
 	segments = append(segments, tableDesc.Name)
 	segments = append(segments, idx.ColumnNames...)
-	if idx.Unique {
+	if idx.Unique || idx.DeferrableUnique {
 		segments = append(segments, "key")
 	} else {
 		segments = append(segments, "idx")
@@ -1289,6 +1289,11 @@ func (desc *Mutable) allocateColumnFamilyIDs(columnNames map[string]descpb.Colum
 	}
 
 	ensureColumnInFamily := func(col *descpb.ColumnDescriptor) {
+		if col.IsVirtual() {
+			// Virtual columns are not stored, so they are not part of any
+			// family.
+			return
+		}
 		if _, ok := columnsInFamilies[col.ID]; ok {
 			return
 		}
@@ -1805,6 +1810,9 @@ func (desc *Immutable) ValidateTable(ctx context.Context) error {
 		if err := desc.validatePartitioning(); err != nil {
 			return err
 		}
+		if err := desc.validateTriggers(); err != nil {
+			return err
+		}
 	}
 
 	// Fill in any incorrect privileges that may have been missed due to mixed-versions.
@@ -1865,6 +1873,29 @@ func (desc *Immutable) ValidateTable(ctx context.Context) error {
 	return desc.Privileges.Validate(desc.GetID(), privilege.Table)
 }
 
+// validateTriggers validates that the triggers of the table have distinct
+// names and a trigger function, and are fired by at least one event.
+func (desc *Immutable) validateTriggers() error {
+	names := make(map[string]struct{}, len(desc.Triggers))
+	for i := range desc.Triggers {
+		trig := &desc.Triggers[i]
+		if err := catalog.ValidateName(trig.Name, "trigger"); err != nil {
+			return err
+		}
+		if _, ok := names[trig.Name]; ok {
+			return errors.Newf("duplicate trigger name: %q", trig.Name)
+		}
+		names[trig.Name] = struct{}{}
+		if trig.FunctionID == descpb.InvalidID {
+			return errors.AssertionFailedf("trigger %q has no function", trig.Name)
+		}
+		if !trig.Insert && !trig.Update && !trig.Delete {
+			return errors.AssertionFailedf("trigger %q has no events", trig.Name)
+		}
+	}
+	return nil
+}
+
 func (desc *Immutable) validateColumnFamilies(columnIDs map[descpb.ColumnID]string) error {
 	if len(desc.Families) < 1 {
 		return fmt.Errorf("at least 1 column family must be specified")
@@ -1921,6 +1952,9 @@ func (desc *Immutable) validateColumnFamilies(columnIDs map[descpb.ColumnID]stri
 				return fmt.Errorf("family %q column %d should have name %q, but found name %q",
 					family.Name, colID, name, family.ColumnNames[i])
 			}
+			if col, err := desc.FindColumnByID(colID); err == nil && col.IsVirtual() {
+				return fmt.Errorf("virtual column %q cannot be in family %q", name, family.Name)
+			}
 		}
 
 		for _, colID := range family.ColumnIDs {
@@ -1932,6 +1966,10 @@ func (desc *Immutable) validateColumnFamilies(columnIDs map[descpb.ColumnID]stri
 	}
 	for colID := range columnIDs {
 		if _, ok := colIDToFamilyID[colID]; !ok {
+			// Virtual columns are not stored, so they have no family.
+			if col, err := desc.FindColumnByID(colID); err == nil && col.IsVirtual() {
+				continue
+			}
 			return fmt.Errorf("column %d is not in any column family", colID)
 		}
 	}
@@ -1990,11 +2028,22 @@ func (desc *Immutable) validateTableIndexes(columnNames map[string]descpb.Column
 			return fmt.Errorf("mismatched column IDs (%d) and directions (%d)",
 				len(index.ColumnIDs), len(index.ColumnDirections))
 		}
+		if len(index.ColumnNullsReversed) != 0 && len(index.ColumnIDs) != len(index.ColumnNullsReversed) {
+			return fmt.Errorf("mismatched column IDs (%d) and NULL orderings (%d)",
+				len(index.ColumnIDs), len(index.ColumnNullsReversed))
+		}
 
 		if len(index.ColumnIDs) == 0 {
 			return fmt.Errorf("index %q must contain at least 1 column", index.Name)
 		}
 
+		if index.DeferrableUnique && (index.Unique || index.Type == descpb.IndexDescriptor_INVERTED || index.IsPartial()) {
+			return fmt.Errorf("index %q cannot be the index of a deferrable unique constraint", index.Name)
+		}
+		if index.InitiallyDeferred && !index.DeferrableUnique {
+			return fmt.Errorf("index %q is initially deferred but not deferrable", index.Name)
+		}
+
 		validateIndexDup := make(map[descpb.ColumnID]struct{})
 		for i, name := range index.ColumnNames {
 			colID, ok := columnNames[name]
@@ -2019,11 +2068,51 @@ func (desc *Immutable) validateTableIndexes(columnNames map[string]descpb.Column
 					index.Name, index.Sharded.Name)
 			}
 		}
+		if err := desc.validateIndexVirtualColumns(index); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// validateIndexVirtualColumns ensures that virtual columns are only used as key
+// columns of non-inverted secondary indexes of non-interleaved tables. A
+// virtual column is not stored in the primary index, so its value cannot be
+// stored by an index either. The rows of interleaved tables are deleted by
+// scanning the primary index when the table is dropped, which doesn't compute
+// the virtual columns needed to delete the secondary index entries.
+func (desc *Immutable) validateIndexVirtualColumns(index *descpb.IndexDescriptor) error {
+	isVirtual := func(colName string) bool {
+		col, _, err := desc.FindColumnByName(tree.Name(colName))
+		return err == nil && col.IsVirtual()
+	}
+	for _, colName := range index.ColumnNames {
+		if !isVirtual(colName) {
+			continue
+		}
+		if index.ID == desc.PrimaryIndex.ID {
+			return pgerror.Newf(pgcode.InvalidTableDefinition,
+				"virtual column %q cannot be part of the primary key", colName)
+		}
+		if index.Type == descpb.IndexDescriptor_INVERTED {
+			return unimplemented.New("inverted virtual column index",
+				"inverted indexes on virtual columns are not supported")
+		}
+		if desc.IsInterleaved() {
+			return unimplemented.New("interleaved virtual column index",
+				"indexes on virtual columns are not supported in interleaved tables")
+		}
+	}
+	for _, colName := range index.StoreColumnNames {
+		if isVirtual(colName) {
+			return pgerror.Newf(pgcode.InvalidTableDefinition,
+				"index %q cannot store virtual column %q", index.Name, colName)
+		}
+	}
+	return nil
+}
+
 // ensureShardedIndexNotComputed ensures that the sharded index is not based on a computed
 // column. This is because the sharded index is based on a hidden computed shard column
 // under the hood and we don't support transitively computed columns (computed column A
@@ -2290,7 +2379,8 @@ func checkColumnsValidForInvertedIndex(tableDesc *Mutable, indexColNames []strin
 	for _, indexCol := range indexColNames {
 		for _, col := range tableDesc.AllNonDropColumns() {
 			if col.Name == indexCol {
-				if !colinfo.ColumnTypeIsInvertedIndexable(col.Type) {
+				if !colinfo.ColumnTypeIsInvertedIndexable(col.Type) &&
+					!colinfo.ColumnTypeIsTrigramIndexable(col.Type) {
 					invalidColumns = append(invalidColumns, col)
 				}
 			}
@@ -3010,6 +3100,9 @@ func (desc *Mutable) MakeMutationComplete(m descpb.DescriptorMutation) error {
 			primaryIndexCopy := protoutil.Clone(&desc.PrimaryIndex).(*descpb.IndexDescriptor)
 			primaryIndexCopy.EncodingType = descpb.PrimaryIndexEncoding
 			for _, col := range desc.Columns {
+				if col.IsVirtual() {
+					continue
+				}
 				containsCol := false
 				for _, colID := range primaryIndexCopy.ColumnIDs {
 					if colID == col.ID {
@@ -3411,7 +3504,7 @@ func (desc *Immutable) MakeFirstMutationPublic(includeConstraints bool) (*Mutabl
 // ColumnNeedsBackfill returns true if adding the given column requires a
 // backfill (dropping a column always requires a backfill).
 func ColumnNeedsBackfill(desc *descpb.ColumnDescriptor) bool {
-	if desc.HasNullDefault() {
+	if desc.HasNullDefault() || desc.IsVirtual() {
 		return false
 	}
 	return desc.HasDefault() || !desc.Nullable || desc.IsComputed()
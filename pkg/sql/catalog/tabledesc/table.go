@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
@@ -104,6 +105,23 @@ func MakeColumnDefDescs(
 	if d.IsComputed() {
 		s := tree.Serialize(d.Computed.Expr)
 		col.ComputeExpr = &s
+		col.Virtual = d.IsVirtual()
+	}
+	if d.IsVirtual() {
+		if d.PrimaryKey.IsPrimaryKey {
+			return nil, nil, nil, pgerror.Newf(pgcode.InvalidTableDefinition,
+				"virtual column %q cannot be part of the primary key", d.Name)
+		}
+		if d.HasColumnFamily() {
+			return nil, nil, nil, pgerror.Newf(pgcode.InvalidTableDefinition,
+				"virtual column %q cannot be part of a column family", d.Name)
+		}
+		// The row fetchers don't compute virtual columns, so they would
+		// report their values as unexpected NULLs.
+		if d.Nullable.Nullability == tree.NotNull {
+			return nil, nil, nil, unimplemented.New("virtual column not null",
+				"virtual columns with a NOT NULL constraint are not supported")
+		}
 	}
 
 	var idx *descpb.IndexDescriptor
@@ -135,6 +153,13 @@ func MakeColumnDefDescs(
 		if d.UniqueConstraintName != "" {
 			idx.Name = string(d.UniqueConstraintName)
 		}
+		if d.UniqueDeferrability != tree.ConstraintNotDeferrable {
+			if d.PrimaryKey.IsPrimaryKey {
+				return nil, nil, nil, unimplemented.NewWithIssueDetail(31632, "deferrable primary key",
+					"primary keys cannot be deferrable")
+			}
+			idx.SetUniqueDeferrability(d.UniqueDeferrability)
+		}
 	}
 
 	return col, idx, typedExpr, nil
@@ -239,7 +264,7 @@ func (desc *Immutable) collectConstraintInfo(
 			detail.Columns = index.ColumnNames
 			detail.Index = index
 			info[index.Name] = detail
-		} else if index.Unique {
+		} else if index.Unique || index.DeferrableUnique {
 			if _, ok := info[index.Name]; ok {
 				return nil, pgerror.Newf(pgcode.DuplicateObject,
 					"duplicate constraint name: %q", index.Name)
@@ -96,6 +96,12 @@ var validationMap = []struct {
 			"DependedOnBy": {
 				status: todoIAmKnowinglyAddingTechDebt,
 				reason: "initial import: TODO(features): add validation"},
+			"DependedOnByFunctions": {
+				status: todoIAmKnowinglyAddingTechDebt,
+				reason: "checked from the other side when validating the functions"},
+			"Triggers": {
+				status: todoIAmKnowinglyAddingTechDebt,
+				reason: "the functions are only checked from the other side when validating them"},
 			"MutationJobs": {status: thisFieldReferencesNoObjects},
 			"SequenceOpts": {status: todoIAmKnowinglyAddingTechDebt,
 				reason: "initial import: TODO(features): add validation"},
@@ -135,6 +141,9 @@ var validationMap = []struct {
 			"CompositeColumnIDs": {
 				status: todoIAmKnowinglyAddingTechDebt,
 				reason: "initial import: TODO(features): add validation"},
+			"ColumnNullsReversed": {status: iSolemnlySwearThisFieldIsValidated},
+			"DeferrableUnique":    {status: iSolemnlySwearThisFieldIsValidated},
+			"InitiallyDeferred":   {status: iSolemnlySwearThisFieldIsValidated},
 			// These next 2 are deprecated and not used anymore.
 			"ForeignKey":   {status: thisFieldReferencesNoObjects},
 			"ReferencedBy": {status: thisFieldReferencesNoObjects},
@@ -178,6 +187,7 @@ var validationMap = []struct {
 				reason: "initial import: TODO(features): add validation"},
 			"AlterColumnTypeInProgress": {status: thisFieldReferencesNoObjects},
 			"SystemColumnKind":          {status: thisFieldReferencesNoObjects},
+			"Virtual":                   {status: thisFieldReferencesNoObjects},
 		},
 	},
 	{
@@ -196,6 +206,8 @@ var validationMap = []struct {
 			"OnDelete":          {status: thisFieldReferencesNoObjects},
 			"OnUpdate":          {status: thisFieldReferencesNoObjects},
 			"Match":             {status: thisFieldReferencesNoObjects},
+			"Deferrable":        {status: thisFieldReferencesNoObjects},
+			"InitiallyDeferred": {status: thisFieldReferencesNoObjects},
 		},
 	},
 	{
@@ -216,6 +228,11 @@ var validationMap = []struct {
 			"ReferencingDescriptorIDs": {status: iSolemnlySwearThisFieldIsValidated},
 			"Privileges":               {status: iSolemnlySwearThisFieldIsValidated},
 			"OfflineReason":            {status: thisFieldReferencesNoObjects},
+			"DomainConstraints":        {status: iSolemnlySwearThisFieldIsValidated},
+			"CompositeFields":          {status: iSolemnlySwearThisFieldIsValidated},
+			"DomainDefaultExpr": {
+				status: todoIAmKnowinglyAddingTechDebt,
+				reason: "TODO(features): add validation"},
 		},
 	},
 }
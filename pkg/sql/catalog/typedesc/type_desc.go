@@ -85,6 +85,9 @@ type Immutable struct {
 	physicalReps    [][]byte
 	readOnlyMembers []bool
 
+	// domainData is used to fill user defined type metadata for DOMAINs.
+	domainData *types.DomainMetadata
+
 	// isUncommittedVersion is set to true if this descriptor was created from
 	// a copy of a Mutable with an uncommitted version.
 	isUncommittedVersion bool
@@ -131,6 +134,21 @@ func makeImmutable(desc descpb.TypeDescriptor) Immutable {
 			immutDesc.readOnlyMembers[i] =
 				member.Capability == descpb.TypeDescriptor_EnumMember_READ_ONLY
 		}
+	case descpb.TypeDescriptor_DOMAIN:
+		immutDesc.domainData = &types.DomainMetadata{
+			BaseType:    desc.Alias,
+			DefaultExpr: desc.DomainDefaultExpr,
+			Constraints: make([]types.DomainConstraint, len(desc.DomainConstraints)),
+		}
+		for i := range desc.DomainConstraints {
+			c := &desc.DomainConstraints[i]
+			immutDesc.domainData.Constraints[i] = types.DomainConstraint{
+				Name:      c.Name,
+				NotNull:   c.NotNull,
+				CheckExpr: c.CheckExpr,
+				Validated: c.Validity == descpb.ConstraintValidity_Validated,
+			}
+		}
 	}
 
 	return immutDesc
@@ -429,6 +447,60 @@ func (desc *Immutable) Validate(ctx context.Context, dg catalog.DescGetter) erro
 		if desc.Alias == nil {
 			return errors.AssertionFailedf("ALIAS type desc has nil alias type")
 		}
+	case descpb.TypeDescriptor_DOMAIN:
+		if desc.Alias == nil {
+			return errors.AssertionFailedf("DOMAIN type desc has nil base type")
+		}
+		if desc.Alias.UserDefined() {
+			return errors.AssertionFailedf("DOMAIN type desc has user defined base type %d", desc.Alias.Oid())
+		}
+		// Ensure there are no duplicate constraint names, and that there is at
+		// most one NOT NULL constraint.
+		names := make(map[string]struct{}, len(desc.DomainConstraints))
+		hasNotNull := false
+		for i := range desc.DomainConstraints {
+			c := &desc.DomainConstraints[i]
+			if _, ok := names[c.Name]; ok {
+				return errors.AssertionFailedf("duplicate domain constraint %q", c.Name)
+			}
+			names[c.Name] = struct{}{}
+			if c.NotNull {
+				if hasNotNull {
+					return errors.AssertionFailedf("domain has more than one NOT NULL constraint")
+				}
+				hasNotNull = true
+			} else if c.CheckExpr == "" {
+				return errors.AssertionFailedf("domain constraint %q has no expression", c.Name)
+			}
+		}
+
+		// Validate the Privileges of the descriptor.
+		if err := desc.Privileges.Validate(desc.ID, privilege.Type); err != nil {
+			return err
+		}
+	case descpb.TypeDescriptor_COMPOSITE:
+		// Ensure that the fields have types that are not user defined, and that
+		// there are no duplicate field names.
+		names := make(map[string]struct{}, len(desc.CompositeFields))
+		for i := range desc.CompositeFields {
+			f := &desc.CompositeFields[i]
+			if f.Type == nil {
+				return errors.AssertionFailedf("composite field %q has nil type", f.Name)
+			}
+			if f.Type.UserDefined() {
+				return errors.AssertionFailedf(
+					"composite field %q has user defined type %d", f.Name, f.Type.Oid())
+			}
+			if _, ok := names[f.Name]; ok {
+				return errors.AssertionFailedf("duplicate composite field %q", f.Name)
+			}
+			names[f.Name] = struct{}{}
+		}
+
+		// Validate the Privileges of the descriptor.
+		if err := desc.Privileges.Validate(desc.ID, privilege.Type); err != nil {
+			return err
+		}
 	default:
 		return errors.AssertionFailedf("invalid desc kind %s", desc.Kind.String())
 	}
@@ -460,7 +532,7 @@ func (desc *Immutable) Validate(ctx context.Context, dg catalog.DescGetter) erro
 	}
 
 	switch desc.Kind {
-	case descpb.TypeDescriptor_ENUM:
+	case descpb.TypeDescriptor_ENUM, descpb.TypeDescriptor_DOMAIN, descpb.TypeDescriptor_COMPOSITE:
 		// Ensure that the referenced array type exists.
 		reqs = append(reqs, desc.ArrayTypeID)
 		checks = append(checks, func(got catalog.Descriptor) error {
@@ -475,20 +547,27 @@ func (desc *Immutable) Validate(ctx context.Context, dg catalog.DescGetter) erro
 		}
 	}
 
-	// Validate that all of the referencing descriptors exist.
-	tableExists := func(id descpb.ID) func(got catalog.Descriptor) error {
+	// Validate that all of the referencing descriptors exist. Types are
+	// referenced by tables, and composite types also by the functions that
+	// return them.
+	referenceExists := func(id descpb.ID) func(got catalog.Descriptor) error {
 		return func(got catalog.Descriptor) error {
-			if _, isTable := got.(catalog.TableDescriptor); !isTable {
-				return errors.AssertionFailedf("referencing descriptor %d does not exist", id)
+			switch got.(type) {
+			case catalog.TableDescriptor:
+				return nil
+			case catalog.FunctionDescriptor:
+				if desc.Kind == descpb.TypeDescriptor_COMPOSITE {
+					return nil
+				}
 			}
-			return nil
+			return errors.AssertionFailedf("referencing descriptor %d does not exist", id)
 		}
 	}
 	if !desc.Dropped() {
 
 		for _, id := range desc.ReferencingDescriptorIDs {
 			reqs = append(reqs, id)
-			checks = append(checks, tableExists(id))
+			checks = append(checks, referenceExists(id))
 		}
 	}
 
@@ -534,6 +613,26 @@ func (desc *Immutable) MakeTypesT(
 			return nil, err
 		}
 		return desc.Alias, nil
+	case descpb.TypeDescriptor_DOMAIN:
+		typ := types.MakeDomain(TypeIDToOID(desc.GetID()), TypeIDToOID(desc.ArrayTypeID), desc.Alias)
+		if err := desc.HydrateTypeInfoWithName(ctx, typ, name, res); err != nil {
+			return nil, err
+		}
+		return typ, nil
+	case descpb.TypeDescriptor_COMPOSITE:
+		contents := make([]*types.T, len(desc.CompositeFields))
+		labels := make([]string, len(desc.CompositeFields))
+		for i := range desc.CompositeFields {
+			contents[i] = desc.CompositeFields[i].Type
+			labels[i] = desc.CompositeFields[i].Name
+		}
+		typ := types.MakeComposite(
+			TypeIDToOID(desc.GetID()), TypeIDToOID(desc.ArrayTypeID), contents, labels,
+		)
+		if err := desc.HydrateTypeInfoWithName(ctx, typ, name, res); err != nil {
+			return nil, err
+		}
+		return typ, nil
 	default:
 		return nil, errors.AssertionFailedf("unknown type kind %s", t.String())
 	}
@@ -624,6 +723,19 @@ func (desc *Immutable) HydrateTypeInfoWithName(
 			}
 		}
 		return nil
+	case descpb.TypeDescriptor_DOMAIN:
+		if !typ.IsDomain() {
+			return errors.New("cannot hydrate a non-domain type with a domain type descriptor")
+		}
+		typ.TypeMeta.DomainData = desc.domainData
+		return nil
+	case descpb.TypeDescriptor_COMPOSITE:
+		// The fields of a composite type are never user defined types, so there
+		// is nothing else to hydrate.
+		if !typ.IsComposite() {
+			return errors.New("cannot hydrate a non-composite type with a composite type descriptor")
+		}
+		return nil
 	default:
 		return errors.AssertionFailedf("unknown type descriptor kind %s", desc.Kind)
 	}
@@ -680,6 +792,15 @@ func (desc *Immutable) HasPendingSchemaChanges() bool {
 			}
 		}
 		return false
+	case descpb.TypeDescriptor_DOMAIN:
+		// If there are any constraints being validated, then a type schema change
+		// is needed to validate them against existing data.
+		for i := range desc.DomainConstraints {
+			if desc.DomainConstraints[i].Validity == descpb.ConstraintValidity_Validating {
+				return true
+			}
+		}
+		return false
 	default:
 		return false
 	}
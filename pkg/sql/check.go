@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
@@ -347,14 +348,15 @@ func checkMutationInput(
 
 	checks := tabDesc.ActiveChecks()
 	colIdx := 0
-	for i := range checks {
-		if !checkOrds.Contains(i) {
-			continue
-		}
-
+	for i, ok := checkOrds.Next(0); ok; i, ok = checkOrds.Next(i + 1) {
 		if res, err := tree.GetBool(checkVals[colIdx]); err != nil {
 			return err
 		} else if !res && checkVals[colIdx] != tree.DNull {
+			if i >= len(checks) {
+				// Failed to satisfy a check constraint synthesized for a user
+				// defined type.
+				return synthesizedCheckError(tabDesc, i-len(checks))
+			}
 			// Failed to satisfy CHECK constraint, so unwrap the serialized
 			// check expression to display to the user.
 			expr, err := schemaexpr.FormatExprForDisplay(ctx, tabDesc, checks[i].Expr, semaCtx, tree.FmtParsable)
@@ -371,3 +373,47 @@ func checkMutationInput(
 	}
 	return nil
 }
+
+// visitSynthesizedChecks calls fn for each check constraint that the
+// optimizer synthesizes for the user defined types of the given columns, in
+// the order in which they follow the check constraints of the table:
+//   - one check for each ENUM column, for which the constraint is nil;
+//   - one check for each constraint of the type of each DOMAIN column.
+func visitSynthesizedChecks(
+	cols []descpb.ColumnDescriptor, fn func(col *descpb.ColumnDescriptor, c *types.DomainConstraint),
+) {
+	for i := range cols {
+		col := &cols[i]
+		switch {
+		case col.Type.Family() == types.EnumFamily && col.Type.UserDefined():
+			fn(col, nil)
+		case col.Type.IsDomain() && col.Type.TypeMeta.DomainData != nil:
+			constraints := col.Type.TypeMeta.DomainData.Constraints
+			for j := range constraints {
+				fn(col, &constraints[j])
+			}
+		}
+	}
+}
+
+// synthesizedCheckError returns the error for a violation of the check
+// constraint with the given ordinal among the synthesized checks of the
+// table.
+func synthesizedCheckError(tabDesc catalog.TableDescriptor, ord int) error {
+	err := errors.AssertionFailedf("synthesized check constraint %d failed", ord)
+	visitSynthesizedChecks(tabDesc.GetPublicColumns(), func(col *descpb.ColumnDescriptor, c *types.DomainConstraint) {
+		if ord--; ord != -1 {
+			return
+		}
+		switch {
+		case c == nil:
+			// Enum values are verified when they are constructed, so this check
+			// never fails.
+		case c.NotNull:
+			err = schemaexpr.DomainNotNullViolationError(col.Type.Name())
+		default:
+			err = schemaexpr.DomainCheckViolationError(col.Type.Name(), c.Name)
+		}
+	})
+	return err
+}
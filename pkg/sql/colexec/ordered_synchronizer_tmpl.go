@@ -244,6 +244,9 @@ func (o *OrderedSynchronizer) Init() {
 	for i := range o.ordering {
 		typ := o.typs[o.ordering[i].ColIdx]
 		o.comparators[i] = GetVecComparator(typ, len(o.inputs))
+		if o.ordering[i].NullsReversed {
+			o.comparators[i] = newNullsReversedVecComparator(o.comparators[i], len(o.inputs))
+		}
 	}
 }
 
@@ -57,6 +57,18 @@ func init() {
 			typs:     []*types.T{types.Int, types.Int},
 			ordCols:  []execinfrapb.Ordering_Column{{ColIdx: 0}, {ColIdx: 1}},
 		},
+		{
+			tuples:   tuples{{1, 2}, {1, 1}, {1, nil}, {2, nil}, {2, 3}, {2, nil}, {5, 1}},
+			expected: tuples{{1, 1}, {1, 2}, {1, nil}, {2, 3}, {2, nil}, {2, nil}, {5, 1}},
+			typs:     []*types.T{types.Int, types.Int},
+			ordCols:  []execinfrapb.Ordering_Column{{ColIdx: 0}, {ColIdx: 1, NullsReversed: true}},
+		},
+		{
+			tuples:   tuples{{nil, 1}, {2, 2}, {nil, 3}, {1, 4}, {3, 5}},
+			expected: tuples{{nil, 1}, {nil, 3}, {3, 5}, {2, 2}, {1, 4}},
+			typs:     []*types.T{types.Int, types.Int},
+			ordCols:  []execinfrapb.Ordering_Column{{ColIdx: 0, Direction: execinfrapb.Ordering_Column_DESC, NullsReversed: true}, {ColIdx: 1}},
+		},
 		{
 			tuples:   tuples{{1}, {2}, {3}, {4}, {5}, {6}, {7}},
 			expected: tuples{{1}, {2}, {3}, {4}, {5}, {6}, {7}},
@@ -83,7 +83,7 @@ func isSorterSupported(t *types.T, dir execinfrapb.Ordering_Column_Direction) bo
 }
 
 func newSingleSorter(
-	t *types.T, dir execinfrapb.Ordering_Column_Direction, hasNulls bool,
+	t *types.T, dir execinfrapb.Ordering_Column_Direction, nullsReversed bool, hasNulls bool,
 ) colSorter {
 	switch hasNulls {
 	// {{range .}}
@@ -99,7 +99,7 @@ func newSingleSorter(
 				switch t.Width() {
 				// {{range .WidthOverloads}}
 				case _TYPE_WIDTH:
-					return &sort_TYPE_DIR_HANDLES_NULLSOp{}
+					return &sort_TYPE_DIR_HANDLES_NULLSOp{nullsReversed: nullsReversed}
 					// {{end}}
 				}
 				// {{end}}
@@ -124,6 +124,7 @@ type sort_TYPE_DIR_HANDLES_NULLSOp struct {
 	sortCol       _GOTYPESLICE
 	nulls         *coldata.Nulls
 	order         []int
+	nullsReversed bool
 	cancelChecker CancelChecker
 }
 
@@ -161,25 +162,20 @@ func (s *sort_TYPE_DIR_HANDLES_NULLSOp) Less(i, j int) bool {
 	n1 := s.nulls.MaybeHasNulls() && s.nulls.NullAt(s.order[i])
 	n2 := s.nulls.MaybeHasNulls() && s.nulls.NullAt(s.order[j])
 	// {{if eq $dir "Asc"}}
-	// If ascending, nulls always sort first, so we encode that logic here.
-	if n1 && n2 {
-		return false
-	} else if n1 {
-		return true
-	} else if n2 {
-		return false
-	}
+	// If ascending, nulls sort first unless the NULL order is reversed.
+	nullsFirst := !s.nullsReversed
 	// {{else if eq $dir "Desc"}}
-	// If descending, nulls always sort last, so we encode that logic here.
+	// If descending, nulls sort last unless the NULL order is reversed.
+	nullsFirst := s.nullsReversed
+	// {{end}}
 	if n1 && n2 {
 		return false
 	} else if n1 {
-		return false
+		return nullsFirst
 	} else if n2 {
-		return true
+		return !nullsFirst
 	}
 	// {{end}}
-	// {{end}}
 	var lt bool
 	// We always indirect via the order vector.
 	arg1 := s.sortCol.Get(s.order[i])
@@ -103,6 +103,11 @@ func (t *topKSorter) Init() {
 	for i, typ := range t.inputTypes {
 		t.comparators[i] = GetVecComparator(typ, 2)
 	}
+	for _, ord := range t.orderingCols {
+		if ord.NullsReversed {
+			t.comparators[ord.ColIdx] = newNullsReversedVecComparator(t.comparators[ord.ColIdx], 2)
+		}
+	}
 	// TODO(yuzefovich): switch to calling this method on allocator. This will
 	// require plumbing unlimited allocator to work correctly in tests with
 	// memory limit of 1.
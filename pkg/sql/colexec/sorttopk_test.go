@@ -49,6 +49,14 @@ func init() {
 			ordCols:     []execinfrapb.Ordering_Column{{ColIdx: 0}},
 			k:           3,
 		},
+		{
+			description: "nulls last",
+			tuples:      tuples{{nil}, {2}, {nil}, {3}, {1}},
+			expected:    tuples{{1}, {2}, {3}, {nil}},
+			typs:        []*types.T{types.Int},
+			ordCols:     []execinfrapb.Ordering_Column{{ColIdx: 0, NullsReversed: true}},
+			k:           4,
+		},
 		{
 			description: "descending",
 			tuples:      tuples{{0, 1}, {0, 2}, {0, 3}, {0, 4}, {0, 5}, {1, 5}},
@@ -139,3 +139,37 @@ func GetVecComparator(t *types.T, numVecs int) vecComparator {
 	// This code is unreachable, but the compiler cannot infer that.
 	return nil
 }
+
+// nullsReversedVecComparator wraps a vecComparator so that NULLs compare
+// greater than all other values. It is used for columns whose NULL ordering is
+// the opposite of the direction's default (ASC NULLS LAST or DESC NULLS
+// FIRST).
+type nullsReversedVecComparator struct {
+	vecComparator
+	nulls []*coldata.Nulls
+}
+
+var _ vecComparator = &nullsReversedVecComparator{}
+
+func newNullsReversedVecComparator(c vecComparator, numVecs int) vecComparator {
+	return &nullsReversedVecComparator{
+		vecComparator: c,
+		nulls:         make([]*coldata.Nulls, numVecs),
+	}
+}
+
+func (c *nullsReversedVecComparator) compare(vecIdx1, vecIdx2 int, valIdx1, valIdx2 int) int {
+	n1 := c.nulls[vecIdx1].MaybeHasNulls() && c.nulls[vecIdx1].NullAt(valIdx1)
+	n2 := c.nulls[vecIdx2].MaybeHasNulls() && c.nulls[vecIdx2].NullAt(valIdx2)
+	if n1 && !n2 {
+		return 1
+	} else if !n1 && n2 {
+		return -1
+	}
+	return c.vecComparator.compare(vecIdx1, vecIdx2, valIdx1, valIdx2)
+}
+
+func (c *nullsReversedVecComparator) setVec(idx int, vec coldata.Vec) {
+	c.vecComparator.setVec(idx, vec)
+	c.nulls[idx] = vec.Nulls()
+}
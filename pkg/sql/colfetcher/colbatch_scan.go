@@ -113,6 +113,9 @@ func (s *ColBatchScan) DrainMeta(ctx context.Context) []execinfrapb.ProducerMeta
 	meta.Metrics.BytesRead = s.GetBytesRead()
 	meta.Metrics.RowsRead = s.GetRowsRead()
 	trailingMeta = append(trailingMeta, *meta)
+	trailingMeta = append(trailingMeta, execinfrapb.ProducerMetadata{
+		ComponentStats: &execinfrapb.ComponentStats{KVTime: s.rf.fetcher.GetKVTime().Nanoseconds()},
+	})
 	return trailingMeta
 }
 
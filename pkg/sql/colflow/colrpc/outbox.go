@@ -58,6 +58,9 @@ type Outbox struct {
 	// closers is a slice of Closers that need to be Closed on termination.
 	closers colexec.Closers
 
+	// bytesSent contains the number of bytes of batches sent by the Outbox.
+	bytesSent int64
+
 	scratch struct {
 		buf *bytes.Buffer
 		msg *execinfrapb.ProducerMessage
@@ -99,6 +102,12 @@ func NewOutbox(
 	return o, nil
 }
 
+// GetBytesSent returns the number of bytes of batches sent by the Outbox so
+// far.
+func (o *Outbox) GetBytesSent() int64 {
+	return o.bytesSent
+}
+
 func (o *Outbox) close(ctx context.Context) {
 	o.closers.CloseAndLogOnErr(ctx, "outbox")
 }
@@ -262,6 +271,7 @@ func (o *Outbox) sendBatches(
 			return false, err
 		}
 		o.scratch.msg.Data.RawBytes = o.scratch.buf.Bytes()
+		o.bytesSent += int64(len(o.scratch.msg.Data.RawBytes))
 
 		// o.scratch.msg can be reused as soon as Send returns since it returns as
 		// soon as the message is written to the control buffer. The message is
@@ -542,7 +542,7 @@ func (s *vectorizedFlowCreator) createBufferingUnlimitedMemMonitor(
 func (s *vectorizedFlowCreator) createDiskAccounts(
 	ctx context.Context, flowCtx *execinfra.FlowCtx, name string, numAccounts int,
 ) (*mon.BytesMonitor, []*mon.BoundAccount) {
-	diskMonitor := execinfra.NewMonitor(ctx, flowCtx.Cfg.DiskMonitor, name)
+	diskMonitor := execinfra.NewMonitor(ctx, flowCtx.ParentDiskMonitor(), name)
 	s.monitors = append(s.monitors, diskMonitor)
 	diskAccounts := make([]*mon.BoundAccount, numAccounts)
 	for i := range diskAccounts {
@@ -576,6 +576,21 @@ func (s *vectorizedFlowCreator) setupRemoteOutputStream(
 	toClose []colexec.Closer,
 	factory coldata.ColumnFactory,
 ) (execinfra.OpNode, error) {
+	var outbox *colrpc.Outbox
+	// cpuTimer measures the CPU time of the outbox goroutine, which runs the
+	// operators the outbox pulls from.
+	var cpuTimer execinfra.CPUTimer
+	if flowCtx.DiskMonitor != nil {
+		// The flow tracks its resource usage, so the outbox reports it. The
+		// metadata is drained by the outbox goroutine.
+		metadataSourcesQueue = append(metadataSourcesQueue, execinfrapb.CallbackMetadataSource{
+			DrainMetaCb: func(context.Context) []execinfrapb.ProducerMetadata {
+				meta := flowCtx.GetFlowStatsMeta(outbox.GetBytesSent(), cpuTimer.Elapsed())
+				defer meta.Release()
+				return []execinfrapb.ProducerMetadata{*meta}
+			},
+		})
+	}
 	outbox, err := s.remoteComponentCreator.newOutbox(
 		colmem.NewAllocator(ctx, s.newStreamingMemAccount(flowCtx), factory),
 		op, outputTyps, metadataSourcesQueue, toClose,
@@ -590,6 +605,7 @@ func (s *vectorizedFlowCreator) setupRemoteOutputStream(
 		// derive a separate child context for each outbox.
 		var outboxCancelFn context.CancelFunc
 		ctx, outboxCancelFn = context.WithCancel(ctx)
+		cpuTimer = flowCtx.StartCPUTimer()
 		outbox.Run(
 			ctx,
 			s.nodeDialer,
@@ -599,6 +615,7 @@ func (s *vectorizedFlowCreator) setupRemoteOutputStream(
 			outboxCancelFn,
 			flowinfra.SettingFlowStreamTimeout.Get(&flowCtx.Cfg.Settings.SV),
 		)
+		cpuTimer.Stop()
 		currentOutboxes := atomic.AddInt32(&s.numOutboxes, -1)
 		// When the last Outbox on this node exits, we want to make sure that
 		// everything is shutdown; namely, we need to call cancelFn if:
@@ -450,6 +450,7 @@ func (s *Server) SetupConn(
 		ctx, sd, args.SessionDefaults, stmtBuf, clientComm, memMetrics, &s.Metrics,
 		s.sqlStats.getStatsForApplication(sd.ApplicationName),
 	)
+	ex.transitionCtx.traceParent = args.TraceParent
 	return ConnectionHandler{ex}, nil
 }
 
@@ -612,8 +613,9 @@ func (s *Server) newConnExecutor(
 			clock:        s.cfg.Clock,
 			// Future transaction's monitors will inherits from sessionRootMon.
 			connMon:  sessionRootMon,
-			tracer:   s.cfg.AmbientCtx.Tracer,
-			settings: s.cfg.Settings,
+			tracer:      s.cfg.AmbientCtx.Tracer,
+			settings:    s.cfg.Settings,
+			sessionData: sd,
 		},
 		memMetrics: memMetrics,
 		planner:    planner{execCfg: s.cfg, alloc: &rowenc.DatumAlloc{}},
@@ -1012,6 +1014,14 @@ type connExecutor struct {
 		// prepStmtsNamespaceAtTxnRewindPos).
 		prepStmtsNamespace prepStmtNamespace
 
+		// sqlCursors contains the cursors declared in the transaction. Like
+		// portals, cursors are destroyed once the transaction finishes.
+		sqlCursors cursorMap
+
+		// deferredConstraints keeps track of the deferred constraints of the
+		// transaction, which are validated before it commits.
+		deferredConstraints deferredConstraints
+
 		// prepStmtsNamespaceAtTxnRewindPos is a snapshot of the prep stmts/portals
 		// (ex.prepStmtsNamespace) before processing the command at position
 		// txnRewindPos.
@@ -1261,9 +1271,13 @@ func (ex *connExecutor) resetExtraTxnState(
 		delete(ex.extraTxnState.prepStmtsNamespace.portals, name)
 	}
 
+	// Close all cursors.
+	ex.extraTxnState.sqlCursors.closeAll(ctx)
+
 	switch ev {
 	case txnCommit, txnRollback:
 		ex.extraTxnState.savepoints.clear()
+		ex.extraTxnState.deferredConstraints.reset()
 		// After txn is finished, we need to call onTxnFinish (if it's non-nil).
 		if ex.extraTxnState.onTxnFinish != nil {
 			ex.extraTxnState.onTxnFinish(ev)
@@ -1896,7 +1910,10 @@ func (ex *connExecutor) execCopyIn(
 			ctx, cmd.Conn, cmd.Stmt, txnOpt, ex.server.cfg,
 			// execInsertPlan
 			func(ctx context.Context, p *planner, res RestrictedCommandResult) error {
-				_, err := ex.execWithDistSQLEngine(ctx, p, tree.RowsAffected, res, false /* distribute */, nil /* progressAtomic */)
+				_, err := ex.execWithDistSQLEngine(
+					ctx, p, tree.RowsAffected, res, false, /* distribute */
+					nil /* progressAtomic */, nil, /* resourceUsage */
+				)
 				return err
 			},
 		)
@@ -2176,6 +2193,11 @@ func (ex *connExecutor) initPlanner(ctx context.Context, p *planner) {
 	p.sessionDataMutator = ex.dataMutator
 	p.noticeSender = nil
 	p.preparedStatements = ex.getPrepStmtsAccessor()
+	p.sqlCursors = &ex.extraTxnState.sqlCursors
+	p.deferredConstraints = nil
+	if ex.executorType != executorTypeInternal {
+		p.deferredConstraints = &ex.extraTxnState.deferredConstraints
+	}
 
 	p.queryCacheSession.Init()
 	p.optPlanningCtx.init(p)
@@ -2427,6 +2449,7 @@ func (ex *connExecutor) serialize() serverpb.Session {
 			Phase:         (serverpb.ActiveQuery_Phase)(query.phase),
 			Progress:      float32(progress),
 		})
+		query.resourceUsage.addToActiveQuery(&activeQueries[len(activeQueries)-1])
 	}
 	lastActiveQuery := ""
 	lastActiveQueryAnon := ""
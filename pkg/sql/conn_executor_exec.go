@@ -14,6 +14,7 @@ import (
 	"context"
 	"fmt"
 	"hash/fnv"
+	"math/rand"
 	"runtime/pprof"
 	"strings"
 	"time"
@@ -40,6 +41,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/fsm"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/sysutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/errors"
@@ -438,6 +440,10 @@ func (ex *connExecutor) execStmtInOpenState(
 		}
 	}()
 
+	// cursorDecl is set for DECLARE statements, whose query is planned below
+	// for a new cursor instead of being executed.
+	var cursorDecl *tree.DeclareCursor
+
 	switch s := stmt.AST.(type) {
 	case *tree.BeginTransaction:
 		// BEGIN is always an error when in the Open state. It's legitimate only in
@@ -539,6 +545,16 @@ func (ex *connExecutor) execStmtInOpenState(
 		if s.DiscardRows {
 			p.discardRows = true
 		}
+
+	case *tree.DeclareCursor:
+		// Replace the `DECLARE foo CURSOR FOR ...` statement with the query of
+		// the cursor, which is planned below.
+		if err := ex.checkDeclareCursor(s, os.ImplicitTxn.Get()); err != nil {
+			return makeErrEvent(err)
+		}
+		cursorDecl = s
+		stmt.AST = s.Select
+		stmt.ExpectedTypes = nil
 	}
 
 	p.semaCtx.Annotations = tree.MakeAnnotations(stmt.NumAnnotations)
@@ -636,7 +652,11 @@ func (ex *connExecutor) execStmtInOpenState(
 	p.stmt = &stmt
 	p.cancelChecker = cancelchecker.NewCancelChecker(ctx)
 	p.autoCommit = os.ImplicitTxn.Get() && !ex.server.cfg.TestingKnobs.DisableAutoCommit
-	if err := ex.dispatchToExecutionEngine(ctx, p, res); err != nil {
+	if cursorDecl != nil {
+		if err := ex.declareCursor(ctx, p, cursorDecl, stmt); err != nil {
+			return makeErrEvent(err)
+		}
+	} else if err := ex.dispatchToExecutionEngine(ctx, p, res); err != nil {
 		return nil, nil, err
 	}
 	if err := res.Err(); err != nil {
@@ -715,6 +735,14 @@ func (ex *connExecutor) commitSQLTransaction(
 func (ex *connExecutor) commitSQLTransactionInternal(
 	ctx context.Context, stmt tree.Statement,
 ) error {
+	// Import the state of the queries of the cursors into the transaction
+	// before closing them, so that the reads performed by the queries are
+	// refreshed along with the other reads of the transaction.
+	if err := ex.extraTxnState.sqlCursors.updateRootTxn(ctx); err != nil {
+		return err
+	}
+	ex.extraTxnState.sqlCursors.closeAll(ctx)
+
 	if err := validatePrimaryKeys(&ex.extraTxnState.descCollection); err != nil {
 		return err
 	}
@@ -723,6 +751,13 @@ func (ex *connExecutor) commitSQLTransactionInternal(
 		return err
 	}
 
+	ie := ex.planner.extendedEvalCtx.InternalExecutor.(*InternalExecutor)
+	if err := ex.extraTxnState.deferredConstraints.validatePending(
+		ctx, ex.state.mu.txn, &ex.extraTxnState.descCollection, ie, ex.server.cfg.Codec,
+	); err != nil {
+		return err
+	}
+
 	if err := ex.state.mu.txn.Commit(ctx); err != nil {
 		return err
 	}
@@ -755,6 +790,8 @@ func validatePrimaryKeys(tc *descs.Collection) error {
 // rollbackSQLTransaction executes a ROLLBACK statement: the KV transaction is
 // rolled-back and an event is produced.
 func (ex *connExecutor) rollbackSQLTransaction(ctx context.Context) (fsm.Event, fsm.EventPayload) {
+	// Stop the queries of the cursors while the transaction is still open.
+	ex.extraTxnState.sqlCursors.closeAll(ctx)
 	if err := ex.state.mu.txn.Rollback(ctx); err != nil {
 		log.Warningf(ctx, "txn rollback failed: %s", err)
 	}
@@ -844,6 +881,7 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 	// TODO(yuzefovich): introduce ternary PlanDistribution into queryMeta.
 	queryMeta.isDistributed = distributePlan.WillDistribute()
 	progAtomic := &queryMeta.progressAtomic
+	resourceUsage := &queryMeta.resourceUsage
 	ex.mu.Unlock()
 
 	// We need to set the "exec done" flag early because
@@ -871,7 +909,8 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 	}
 	ex.sessionTracing.TraceExecStart(ctx, "distributed")
 	stats, err := ex.execWithDistSQLEngine(
-		ctx, planner, stmt.AST.StatementType(), res, distributePlan.WillDistribute(), progAtomic,
+		ctx, planner, stmt.AST.StatementType(), res, distributePlan.WillDistribute(),
+		progAtomic, resourceUsage,
 	)
 	ex.sessionTracing.TraceExecEnd(ctx, res.Err(), res.RowsAffected())
 	ex.statsCollector.phaseTimes[plannerEndExecStmt] = timeutil.Now()
@@ -934,6 +973,58 @@ type topLevelQueryStats struct {
 	bytesRead int64
 	// rowsRead is the number of rows read from disk.
 	rowsRead int64
+
+	queryResourceStats
+}
+
+// queryResourceStats are the resources consumed by the flows of a query,
+// including the flows of its subqueries and postqueries.
+type queryResourceStats struct {
+	// maxMemUsage is the largest peak memory usage among the flows of the
+	// query, in bytes.
+	maxMemUsage int64
+	// maxDiskUsage is the largest peak temporary storage usage among the flows
+	// of the query, in bytes.
+	maxDiskUsage int64
+	// networkBytes is the number of bytes sent over the network by the flows of
+	// the query.
+	networkBytes int64
+	// kvTime is the time spent waiting for KV batches by the processors of the
+	// query, summed across the processors.
+	kvTime time.Duration
+	// cpuTimeMeasured is true if the flows of the query measured their CPU
+	// time, which is only done for a sample of the executions.
+	cpuTimeMeasured bool
+	// cpuTime is the CPU time consumed by the goroutines of the query's flows.
+	cpuTime time.Duration
+}
+
+// addComponentStats accumulates the stats reported by a component of a flow
+// into the stats.
+func (s *queryResourceStats) addComponentStats(cs *execinfrapb.ComponentStats) {
+	if cs.MaxMemUsage > s.maxMemUsage {
+		s.maxMemUsage = cs.MaxMemUsage
+	}
+	if cs.MaxDiskUsage > s.maxDiskUsage {
+		s.maxDiskUsage = cs.MaxDiskUsage
+	}
+	s.networkBytes += cs.NetworkBytes
+	s.kvTime += time.Duration(cs.KVTime)
+	s.cpuTime += time.Duration(cs.CPUTime)
+}
+
+// sampleCPUTime returns whether the flows of the statement being executed
+// should measure their CPU time, according to
+// sql.metrics.statement_details.cpu_time.sample_rate.
+func (ex *connExecutor) sampleCPUTime() bool {
+	rate := cpuTimeSampleRate.Get(&ex.server.cfg.Settings.SV)
+	if rate <= 0 || (rate < 1 && rand.Float64() >= rate) {
+		return false
+	}
+	// Don't report a CPU time of zero for the statement if the gateway can't
+	// measure it.
+	_, ok := sysutil.ThreadCPUTime()
+	return ok
 }
 
 // execWithDistSQLEngine converts a plan to a distributed SQL physical plan and
@@ -947,6 +1038,7 @@ func (ex *connExecutor) execWithDistSQLEngine(
 	res RestrictedCommandResult,
 	distribute bool,
 	progressAtomic *uint64,
+	resourceUsage *queryResourceUsage,
 ) (topLevelQueryStats, error) {
 	recv := MakeDistSQLReceiver(
 		ctx, res, stmtType,
@@ -958,6 +1050,13 @@ func (ex *connExecutor) execWithDistSQLEngine(
 		&ex.sessionTracing,
 	)
 	recv.progressAtomic = progressAtomic
+	recv.resourceUsage = resourceUsage
+	if ex.sampleCPUTime() {
+		recv.stats.cpuTimeMeasured = true
+		if resourceUsage != nil {
+			resourceUsage.update(&recv.stats)
+		}
+	}
 	defer recv.Release()
 
 	evalCtx := planner.ExtendedEvalContext()
@@ -441,6 +441,11 @@ func (p *planner) preparePlannerForCopy(
 	}
 	txnOpt.resetPlanner(ctx, p, txn, txnTs, stmtTs)
 	p.autoCommit = autoCommit
+	if autoCommit {
+		// The copyMachine commits its own transactions, so the constraints
+		// cannot be deferred until the commit.
+		p.deferredConstraints = nil
+	}
 
 	return func(ctx context.Context, prevErr error) (err error) {
 		// Ensure that we clean up any accumulated extraTxnState state if we've
@@ -44,6 +44,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
@@ -53,6 +54,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
@@ -770,6 +772,17 @@ CREATE TABLE crdb_internal.node_statement_statistics (
   bytes_read_var      FLOAT NOT NULL,
   rows_read_avg       FLOAT NOT NULL,
   rows_read_var       FLOAT NOT NULL,
+  max_mem_usage_avg   FLOAT NOT NULL,
+  max_mem_usage_var   FLOAT NOT NULL,
+  max_disk_usage_avg  FLOAT NOT NULL,
+  max_disk_usage_var  FLOAT NOT NULL,
+  network_bytes_avg   FLOAT NOT NULL,
+  network_bytes_var   FLOAT NOT NULL,
+  kv_time_avg         FLOAT NOT NULL,
+  kv_time_var         FLOAT NOT NULL,
+  cpu_time_samples    INT NOT NULL,
+  cpu_time_avg        FLOAT NOT NULL,
+  cpu_time_var        FLOAT NOT NULL,
   implicit_txn        BOOL NOT NULL
 )`,
 	populate: func(ctx context.Context, p *planner, _ *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
@@ -863,6 +876,17 @@ CREATE TABLE crdb_internal.node_statement_statistics (
 					tree.NewDFloat(tree.DFloat(s.mu.data.BytesRead.GetVariance(s.mu.data.Count))),
 					tree.NewDFloat(tree.DFloat(s.mu.data.RowsRead.Mean)),
 					tree.NewDFloat(tree.DFloat(s.mu.data.RowsRead.GetVariance(s.mu.data.Count))),
+					tree.NewDFloat(tree.DFloat(s.mu.data.MaxMemUsage.Mean)),
+					tree.NewDFloat(tree.DFloat(s.mu.data.MaxMemUsage.GetVariance(s.mu.data.Count))),
+					tree.NewDFloat(tree.DFloat(s.mu.data.MaxDiskUsage.Mean)),
+					tree.NewDFloat(tree.DFloat(s.mu.data.MaxDiskUsage.GetVariance(s.mu.data.Count))),
+					tree.NewDFloat(tree.DFloat(s.mu.data.NetworkBytes.Mean)),
+					tree.NewDFloat(tree.DFloat(s.mu.data.NetworkBytes.GetVariance(s.mu.data.Count))),
+					tree.NewDFloat(tree.DFloat(s.mu.data.KVTime.Mean)),
+					tree.NewDFloat(tree.DFloat(s.mu.data.KVTime.GetVariance(s.mu.data.Count))),
+					tree.NewDInt(tree.DInt(s.mu.data.CPUTimeSampleCount)),
+					tree.NewDFloat(tree.DFloat(s.mu.data.CPUTime.Mean)),
+					tree.NewDFloat(tree.DFloat(s.mu.data.CPUTime.GetVariance(s.mu.data.CPUTimeSampleCount))),
 					tree.MakeDBool(tree.DBool(stmtKey.implicitTxn)),
 				)
 				s.mu.Unlock()
@@ -1265,7 +1289,12 @@ CREATE TABLE crdb_internal.%s (
   client_address   STRING,         -- the address of the client that issued the query
   application_name STRING,         -- the name of the application as per SET application_name
   distributed      BOOL,           -- whether the query is running distributed
-  phase            STRING          -- the current execution phase
+  phase            STRING,         -- the current execution phase
+  max_mem_usage    INT,            -- the peak memory usage of the query's flows so far
+  max_disk_usage   INT,            -- the peak temporary storage usage of the query's flows so far
+  network_bytes    INT,            -- the number of bytes sent over the network so far
+  kv_time          INTERVAL,       -- the time spent waiting for KV batches by the query's finished processors
+  cpu_time         INTERVAL        -- the CPU time consumed by the query's finished goroutines, if measured
 )`
 
 func (p *planner) makeSessionsRequest(ctx context.Context) (serverpb.ListSessionsRequest, error) {
@@ -1378,6 +1407,12 @@ func populateQueriesTable(
 				txnID = tree.NewDUuid(tree.DUuid{UUID: query.TxnID})
 			}
 
+			// Only a sample of the queries measure their CPU time.
+			cpuTime := tree.DNull
+			if query.CPUTimeMeasured {
+				cpuTime = tree.NewDInterval(duration.MakeDuration(query.CPUTime, 0, 0), types.DefaultIntervalTypeMetadata)
+			}
+
 			ts, err := tree.MakeDTimestamp(query.Start, time.Microsecond)
 			if err != nil {
 				return err
@@ -1394,6 +1429,11 @@ func populateQueriesTable(
 				tree.NewDString(session.ApplicationName),
 				isDistributedDatum,
 				tree.NewDString(phase),
+				tree.NewDInt(tree.DInt(query.MaxMemUsage)),
+				tree.NewDInt(tree.DInt(query.MaxDiskUsage)),
+				tree.NewDInt(tree.DInt(query.NetworkBytes)),
+				tree.NewDInterval(duration.MakeDuration(query.KVTime, 0, 0), types.DefaultIntervalTypeMetadata),
+				cpuTime,
 			); err != nil {
 				return err
 			}
@@ -1417,6 +1457,11 @@ func populateQueriesTable(
 				tree.DNull,                             // application_name
 				tree.DNull,                             // distributed
 				tree.DNull,                             // phase
+				tree.DNull,                             // max_mem_usage
+				tree.DNull,                             // max_disk_usage
+				tree.DNull,                             // network_bytes
+				tree.DNull,                             // kv_time
+				tree.DNull,                             // cpu_time
 			); err != nil {
 				return err
 			}
@@ -1679,6 +1724,69 @@ CREATE TABLE crdb_internal.create_type_statements (
 				); err != nil {
 					return err
 				}
+			case descpb.TypeDescriptor_DOMAIN:
+				name, err := tree.NewUnresolvedObjectName(2, [3]string{typeDesc.GetName(), sc}, 0)
+				if err != nil {
+					return err
+				}
+				node := &tree.CreateType{
+					Variety:    tree.Domain,
+					TypeName:   name,
+					DomainType: typeDesc.Alias,
+				}
+				if typeDesc.DomainDefaultExpr != nil {
+					if node.DomainDefault, err = parser.ParseExpr(*typeDesc.DomainDefaultExpr); err != nil {
+						return err
+					}
+				}
+				for i := range typeDesc.DomainConstraints {
+					c := &typeDesc.DomainConstraints[i]
+					constraint := tree.DomainConstraint{Name: tree.Name(c.Name), NotNull: c.NotNull}
+					if !c.NotNull {
+						if constraint.Check, err = parser.ParseExpr(c.CheckExpr); err != nil {
+							return err
+						}
+					}
+					node.DomainConstraints = append(node.DomainConstraints, constraint)
+				}
+				if err := addRow(
+					tree.NewDInt(tree.DInt(db.GetID())),       // database_id
+					tree.NewDString(db.GetName()),             // database_name
+					tree.NewDString(sc),                       // schema_name
+					tree.NewDInt(tree.DInt(typeDesc.GetID())), // descriptor_id
+					tree.NewDString(typeDesc.GetName()),       // descriptor_name
+					tree.NewDString(tree.AsString(node)),      // create_statement
+					tree.DNull,
+				); err != nil {
+					return err
+				}
+			case descpb.TypeDescriptor_COMPOSITE:
+				name, err := tree.NewUnresolvedObjectName(2, [3]string{typeDesc.GetName(), sc}, 0)
+				if err != nil {
+					return err
+				}
+				node := &tree.CreateType{
+					Variety:         tree.Composite,
+					TypeName:        name,
+					CompositeFields: make([]tree.CompositeTypeField, len(typeDesc.CompositeFields)),
+				}
+				for i := range typeDesc.CompositeFields {
+					node.CompositeFields[i] = tree.CompositeTypeField{
+						Label: tree.Name(typeDesc.CompositeFields[i].Name),
+						Type:  typeDesc.CompositeFields[i].Type,
+					}
+				}
+				if err := addRow(
+					tree.NewDInt(tree.DInt(db.GetID())),       // database_id
+					tree.NewDString(db.GetName()),             // database_name
+					tree.NewDString(sc),                       // schema_name
+					tree.NewDInt(tree.DInt(typeDesc.GetID())), // descriptor_id
+					tree.NewDString(typeDesc.GetName()),       // descriptor_name
+					tree.NewDString(tree.AsString(node)),      // create_statement
+					tree.DNull,
+				); err != nil {
+					return err
+				}
 			case descpb.TypeDescriptor_ALIAS:
 			// Alias types are created implicitly, so we don't have create
 			// statements for them.
@@ -2013,7 +2121,7 @@ CREATE TABLE crdb_internal.table_indexes (
 							tree.NewDInt(tree.DInt(idx.ID)),
 							tree.NewDString(idx.Name),
 							idxType,
-							tree.MakeDBool(tree.DBool(idx.Unique)),
+							tree.MakeDBool(tree.DBool(idx.Unique || idx.DeferrableUnique)),
 							tree.MakeDBool(idx.Type == descpb.IndexDescriptor_INVERTED),
 						)
 						return pusher.pushRow(row...)
@@ -0,0 +1,278 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// createFunctionNode represents a CREATE FUNCTION statement.
+type createFunctionNode struct {
+	// n is the CREATE FUNCTION statement, with all the types in the signature
+	// resolved and all data sources in the body fully qualified.
+	n      *tree.CreateFunction
+	dbDesc *dbdesc.Immutable
+	schema catalog.ResolvedSchema
+
+	// planDeps tracks which tables and views the function body depends on.
+	planDeps planDependencies
+}
+
+// ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
+// This is because CREATE FUNCTION performs multiple KV operations on
+// descriptors and expects to see its own writes.
+func (n *createFunctionNode) ReadingOwnWrites() {}
+
+func (n *createFunctionNode) startExec(params runParams) error {
+	p := params.p
+	if !p.ExecCfg().Settings.Version.IsActive(params.ctx, clusterversion.VersionUserDefinedFunctions) {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			`creating functions requires all nodes to be upgraded to %s`,
+			clusterversion.VersionByKey(clusterversion.VersionUserDefinedFunctions))
+	}
+	telemetry.Inc(sqltelemetry.SchemaChangeCreateCounter("function"))
+
+	if n.schema.Kind == catalog.SchemaTemporary {
+		return unimplemented.NewWithIssue(17511, "cannot create functions in a temporary schema")
+	}
+	fnName := tree.MakeTableNameWithSchema(
+		tree.Name(n.dbDesc.GetName()), tree.Name(n.schema.Name), tree.Name(n.n.Name.Object()),
+	)
+	log.VEventf(params.ctx, 2, "dependencies for function %s:\n%s", fnName.Object(), n.planDeps.String())
+
+	// A function outlives the session, so it cannot depend on temporary
+	// relations.
+	for _, dep := range n.planDeps {
+		if dep.desc.Temporary {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"function %q cannot depend on temporary relation %q", fnName.Object(), dep.desc.Name)
+		}
+	}
+
+	// Look for an existing object with the same name, which must be a function
+	// if we are replacing it.
+	var replacingDesc *funcdesc.Mutable
+	exists, collided, err := catalogkv.LookupObjectID(
+		params.ctx, p.txn, p.ExecCfg().Codec, n.dbDesc.GetID(), n.schema.ID, fnName.Object(),
+	)
+	if err != nil {
+		return err
+	}
+	if exists {
+		desc, err := catalogkv.GetAnyDescriptorByID(
+			params.ctx, p.txn, p.ExecCfg().Codec, collided, catalogkv.Immutable,
+		)
+		if err != nil {
+			return sqlerrors.WrapErrorWhileConstructingObjectAlreadyExistsErr(err)
+		}
+		if _, isFunc := desc.(catalog.FunctionDescriptor); !isFunc || !n.n.Replace {
+			return sqlerrors.MakeObjectAlreadyExistsError(desc.DescriptorProto(), fnName.FQString())
+		}
+		replacingDesc, err = p.Descriptors().GetMutableFunctionVersionByID(params.ctx, p.txn, collided)
+		if err != nil {
+			return err
+		}
+		if err := p.CheckPrivilege(params.ctx, replacingDesc, privilege.DROP); err != nil {
+			return err
+		}
+		// Triggers rely on the function being a trigger function, and other
+		// functions are not attached to triggers.
+		if replacingDesc.ReturnsTrigger != n.n.ReturnsTrigger {
+			return errors.WithHintf(
+				pgerror.Newf(pgcode.InvalidFunctionDefinition,
+					"cannot change return type of existing function"),
+				"Use DROP FUNCTION %s first.", fnName.Object(),
+			)
+		}
+	}
+
+	// Build the signature.
+	fnParams := make([]descpb.FunctionDescriptor_Param, len(n.n.Params))
+	for i := range n.n.Params {
+		fnParams[i] = descpb.FunctionDescriptor_Param{
+			Name: string(n.n.Params[i].Name),
+			Type: n.n.Params[i].Type.(*types.T),
+		}
+	}
+	var returnType *types.T
+	switch {
+	case n.n.ReturnsTrigger:
+		// Trigger functions have no return type.
+	case n.n.ReturnType != nil:
+		returnType = n.n.ReturnType.(*types.T)
+	default:
+		contents := make([]*types.T, len(n.n.TableColumns))
+		labels := make([]string, len(n.n.TableColumns))
+		for i := range n.n.TableColumns {
+			contents[i] = n.n.TableColumns[i].Type.(*types.T)
+			labels[i] = string(n.n.TableColumns[i].Name)
+		}
+		returnType = types.MakeLabeledTuple(contents, labels)
+	}
+	volatility := n.n.Volatility
+	if volatility == 0 {
+		volatility = tree.VolatilityVolatile
+	}
+	dependsOn := make([]descpb.ID, 0, len(n.planDeps))
+	for id := range n.planDeps {
+		dependsOn = append(dependsOn, id)
+	}
+	sort.Slice(dependsOn, func(i, j int) bool { return dependsOn[i] < dependsOn[j] })
+
+	var newDesc *funcdesc.Mutable
+	var oldDependsOn []descpb.ID
+	if replacingDesc != nil {
+		oldDependsOn = replacingDesc.DependsOn
+		newDesc = replacingDesc
+	} else {
+		id, err := catalogkv.GenerateUniqueDescID(params.ctx, p.ExecCfg().DB, p.ExecCfg().Codec)
+		if err != nil {
+			return err
+		}
+		// Functions can be executed by everybody by default, like in Postgres.
+		privs := descpb.NewDefaultPrivilegeDescriptor(params.SessionData().User)
+		privs.Grant(security.PublicRole, privilege.List{privilege.EXECUTE})
+		newDesc = funcdesc.NewCreatedMutable(descpb.FunctionDescriptor{
+			Name:           fnName.Object(),
+			ID:             id,
+			ParentID:       n.dbDesc.GetID(),
+			ParentSchemaID: n.schema.ID,
+			Version:        1,
+			Privileges:     privs,
+		})
+	}
+	newDesc.Params = fnParams
+	newDesc.ReturnType = returnType
+	newDesc.ReturnsSet = n.n.ReturnsSet
+	newDesc.ReturnsTrigger = n.n.ReturnsTrigger
+	newDesc.Volatility = funcdesc.VolatilityToProto(volatility)
+	newDesc.Strict = n.n.Strict
+	newDesc.Body = n.n.Body
+	newDesc.DependsOn = dependsOn
+
+	jobDesc := tree.AsStringWithFQNames(n.n, params.Ann())
+	if replacingDesc != nil {
+		if err := p.writeFunctionDescChange(params.ctx, newDesc, jobDesc); err != nil {
+			return err
+		}
+	} else {
+		key := catalogkv.MakeObjectNameKey(
+			params.ctx, p.ExecCfg().Settings, n.dbDesc.GetID(), n.schema.ID, fnName.Object(),
+		)
+		if err := p.createDescriptorWithID(
+			params.ctx, key.Key(p.ExecCfg().Codec), newDesc.ID, newDesc, p.ExecCfg().Settings, jobDesc,
+		); err != nil {
+			return err
+		}
+	}
+
+	// Update the back-references in the relations the function depends on,
+	// including the ones the function no longer depends on if it was replaced.
+	for _, id := range oldDependsOn {
+		if _, ok := n.planDeps[id]; ok {
+			continue
+		}
+		backRefMutable, err := p.Descriptors().GetMutableTableVersionByID(params.ctx, id, p.txn)
+		if err != nil {
+			return err
+		}
+		backRefMutable.DependedOnByFunctions = removeMatchingReferences(
+			backRefMutable.DependedOnByFunctions, newDesc.ID,
+		)
+		if err := p.writeSchemaChange(
+			params.ctx, backRefMutable, descpb.InvalidMutationID,
+			fmt.Sprintf("removing function reference %q in table %s(%d)",
+				fnName.Object(), backRefMutable.Name, backRefMutable.ID),
+		); err != nil {
+			return err
+		}
+	}
+	for _, id := range dependsOn {
+		backRefMutable := p.Descriptors().GetUncommittedTableByID(id)
+		if backRefMutable == nil {
+			backRefMutable = tabledesc.NewExistingMutable(*n.planDeps[id].desc.TableDesc())
+		}
+		// Remove the existing references of a replaced function so that we
+		// don't leave any out of date references, then add the new ones.
+		backRefMutable.DependedOnByFunctions = removeMatchingReferences(
+			backRefMutable.DependedOnByFunctions, newDesc.ID,
+		)
+		for _, dep := range n.planDeps[id].deps {
+			dep.ID = newDesc.ID
+			backRefMutable.DependedOnByFunctions = append(backRefMutable.DependedOnByFunctions, dep)
+		}
+		if err := p.writeSchemaChange(
+			params.ctx, backRefMutable, descpb.InvalidMutationID,
+			fmt.Sprintf("updating function reference %q in table %s(%d)",
+				fnName.Object(), backRefMutable.Name, backRefMutable.ID),
+		); err != nil {
+			return err
+		}
+	}
+
+	// A function that returns a composite type depends on the type. The return
+	// type of a replaced function cannot change, so the reference only needs
+	// to be added when the function is created.
+	if replacingDesc == nil && returnType != nil && returnType.UserDefined() {
+		if err := p.addTypeBackReference(
+			params.ctx, typedesc.UserDefinedTypeOIDToID(returnType.Oid()), newDesc.ID, jobDesc,
+		); err != nil {
+			return err
+		}
+	}
+
+	dg := catalogkv.NewOneLevelUncachedDescGetter(p.txn, p.ExecCfg().Codec)
+	if err := newDesc.Validate(params.ctx, dg); err != nil {
+		return err
+	}
+
+	// Log Create Function event. This is an auditable log event and is
+	// recorded in the same transaction as the function descriptor update.
+	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+		params.ctx,
+		p.txn,
+		EventLogCreateFunction,
+		int32(newDesc.ID),
+		int32(params.extendedEvalCtx.NodeID.SQLInstanceID()),
+		struct {
+			FunctionName string
+			Statement    string
+			User         string
+		}{fnName.FQString(), jobDesc, params.SessionData().User},
+	)
+}
+
+func (*createFunctionNode) Next(runParams) (bool, error) { return false, nil }
+func (*createFunctionNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *createFunctionNode) Close(ctx context.Context)  {}
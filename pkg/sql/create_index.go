@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/errors"
 )
 
@@ -142,6 +143,13 @@ func MakeIndexDescriptor(
 	if err := validateIndexColumnsExist(tableDesc, n.Columns); err != nil {
 		return nil, err
 	}
+	version := params.ExecCfg().Settings.Version.ActiveVersionOrEmpty(params.ctx)
+	if err := validateIndexOpClasses(version, tableDesc, n.Columns, n.Inverted); err != nil {
+		return nil, err
+	}
+	if err := validateIndexNullsOrder(version, n.Columns, n.Inverted); err != nil {
+		return nil, err
+	}
 
 	// Ensure that the index name does not exist before trying to create the index.
 	if err := tableDesc.ValidateIndexNameIsUnique(string(n.Name)); err != nil {
@@ -190,6 +198,8 @@ func MakeIndexDescriptor(
 		case types.GeographyFamily:
 			indexDesc.GeoConfig = *geoindex.DefaultGeographyIndexConfig()
 			telemetry.Inc(sqltelemetry.GeographyInvertedIndexCounter)
+		case types.StringFamily:
+			telemetry.Inc(sqltelemetry.TrigramInvertedIndexCounter)
 		}
 		telemetry.Inc(sqltelemetry.InvertedIndexCounter)
 	}
@@ -264,6 +274,112 @@ func validateIndexColumnsExist(desc *tabledesc.Mutable, columns tree.IndexElemLi
 	return nil
 }
 
+// validateIndexOpClasses checks that the operator classes of the given index
+// columns are valid for the index. An inverted index on a string column is a
+// trigram index, which requires the gin_trgm_ops operator class and all nodes
+// to know how to encode and scan such an index. Trigram operator classes
+// cannot be used with any other index. The operator class is not stored in
+// the index descriptor, so gist_trgm_ops is rejected rather than being
+// treated as gin_trgm_ops. An empty version skips the version check.
+func validateIndexOpClasses(
+	version clusterversion.ClusterVersion,
+	desc *tabledesc.Mutable,
+	columns tree.IndexElemList,
+	inverted bool,
+) error {
+	for _, column := range columns {
+		if !inverted {
+			if column.OpClass != "" {
+				return pgerror.Newf(pgcode.UndefinedObject,
+					"operator class %q does not exist for access method \"btree\"", column.OpClass)
+			}
+			continue
+		}
+		if column.OpClass == "gist_trgm_ops" {
+			return unimplemented.NewWithIssueDetail(41285, "index using gist_trgm_ops",
+				"operator class gist_trgm_ops is not supported, use gin_trgm_ops instead")
+		}
+		col, _, err := desc.FindColumnByName(column.Column)
+		if err != nil {
+			return err
+		}
+		isTrigram := colinfo.ColumnTypeIsTrigramIndexable(col.Type)
+		if isTrigram && column.OpClass == "" {
+			return errors.WithHint(
+				pgerror.Newf(pgcode.UndefinedObject,
+					"data type %s has no default operator class for access method \"gin\"",
+					col.Type.SQLString()),
+				"You must specify an operator class for the index, such as gin_trgm_ops.")
+		}
+		if !isTrigram && column.OpClass != "" {
+			return pgerror.Newf(pgcode.DatatypeMismatch,
+				"operator class %q does not accept data type %s", column.OpClass, col.Type.SQLString())
+		}
+		if isTrigram && version != (clusterversion.ClusterVersion{}) &&
+			!version.IsActive(clusterversion.VersionTrigramIndexes) {
+			return errTrigramIndexesNotSupported
+		}
+	}
+	return nil
+}
+
+var errTrigramIndexesNotSupported = pgerror.Newf(pgcode.FeatureNotSupported,
+	"trigram indexes require all nodes to be upgraded to %s",
+	clusterversion.VersionByKey(clusterversion.VersionTrigramIndexes))
+
+// validateIndexNullsOrder checks that the NULL orderings of the given index
+// columns are valid for the index. Inverted indexes do not accept NULL
+// orderings, and a NULL ordering opposite to the default for the column's
+// direction requires all nodes to know how to encode and scan such an index.
+// An empty version skips the version check.
+func validateIndexNullsOrder(
+	version clusterversion.ClusterVersion, columns tree.IndexElemList, inverted bool,
+) error {
+	for _, column := range columns {
+		if column.NullsOrder == tree.DefaultNullsOrder {
+			continue
+		}
+		if inverted {
+			return pgerror.New(pgcode.FeatureNotSupported,
+				"access method \"gin\" does not support NULLS FIRST/LAST options")
+		}
+		if column.NullsOrder.IsReversed(column.Direction) &&
+			version != (clusterversion.ClusterVersion{}) &&
+			!version.IsActive(clusterversion.VersionIndexNullsOrder) {
+			return errIndexNullsOrderNotSupported
+		}
+	}
+	return nil
+}
+
+var errIndexNullsOrderNotSupported = pgerror.Newf(pgcode.FeatureNotSupported,
+	"indexes with NULLS FIRST or NULLS LAST require all nodes to be upgraded to %s",
+	clusterversion.VersionByKey(clusterversion.VersionIndexNullsOrder))
+
+// validateUniqueDeferrability checks that a unique constraint with the given
+// deferrability can be created. A deferrable unique constraint requires all
+// nodes to know that its index must be checked for duplicates. An empty
+// version skips the version check.
+func validateUniqueDeferrability(
+	version clusterversion.ClusterVersion, d tree.ConstraintDeferrability,
+) error {
+	if d != tree.ConstraintNotDeferrable && version != (clusterversion.ClusterVersion{}) &&
+		!version.IsActive(clusterversion.VersionDeferrableUniqueConstraints) {
+		return errDeferrableUniqueNotSupported
+	}
+	return nil
+}
+
+var errDeferrableUniqueNotSupported = pgerror.Newf(pgcode.FeatureNotSupported,
+	"deferrable unique constraints require all nodes to be upgraded to %s",
+	clusterversion.VersionByKey(clusterversion.VersionDeferrableUniqueConstraints))
+
+// errAddDeferrableUniqueNotSupported is returned when adding a deferrable
+// unique constraint to an existing table, whose rows would not be checked for
+// duplicates when the index is backfilled.
+var errAddDeferrableUniqueNotSupported = unimplemented.NewWithIssueDetail(31632,
+	"add deferrable unique", "deferrable unique constraints can only be added by CREATE TABLE")
+
 // ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
 // This is because CREATE INDEX performs multiple KV operations on descriptors
 // and expects to see its own writes.
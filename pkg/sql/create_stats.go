@@ -212,6 +212,10 @@ func (n *createStatsNode) makeJobRecord(ctx context.Context) (*jobs.Record, erro
 
 		columnIDs := make([]descpb.ColumnID, len(columns))
 		for i := range columns {
+			if columns[i].IsVirtual() {
+				return nil, pgerror.Newf(pgcode.InvalidColumnReference,
+					"cannot create statistics on virtual column %q", columns[i].Name)
+			}
 			columnIDs[i] = columns[i].ID
 		}
 		col, err := tableDesc.FindColumnByID(columnIDs[0])
@@ -307,7 +311,15 @@ func createStatsDefaultColumns(
 	// trackStatsIfNotExists adds the given column IDs as a set to the
 	// requestedStats set. If the columnIDs were not already in the set, it
 	// returns true.
+	//
+	// Virtual columns are not stored, so it always returns false for column
+	// sets that include one of them.
 	trackStatsIfNotExists := func(colIDs []descpb.ColumnID) bool {
+		for _, colID := range colIDs {
+			if col, err := desc.FindColumnByID(colID); err == nil && col.IsVirtual() {
+				return false
+			}
+		}
 		key := makeColStatKey(colIDs)
 		if _, ok := requestedStats[key]; ok {
 			return false
@@ -371,6 +383,16 @@ func createStatsDefaultColumns(
 	// Add column stats for each secondary index.
 	for i := range desc.Indexes {
 		isInverted := desc.Indexes[i].Type == descpb.IndexDescriptor_INVERTED
+		if isInverted && len(desc.Indexes[i].ColumnIDs) > 0 {
+			// Trigram indexes are inverted indexes on string columns. The optimizer
+			// relies on the regular histograms of string columns, so we don't
+			// replace them with histograms on the trigram keys.
+			col, err := desc.FindColumnByID(desc.Indexes[i].ColumnIDs[0])
+			if err != nil {
+				return nil, err
+			}
+			isInverted = colinfo.ColumnTypeIsInvertedIndexable(col.Type)
+		}
 
 		for j := range desc.Indexes[i].ColumnIDs {
 			// Generate stats for each indexed column.
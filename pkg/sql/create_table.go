@@ -624,6 +624,13 @@ func ResolveFK(
 	validationBehavior tree.ValidationBehavior,
 	evalCtx *tree.EvalContext,
 ) error {
+	if d.Deferrability != tree.ConstraintNotDeferrable &&
+		!evalCtx.Settings.Version.IsActive(ctx, clusterversion.VersionDeferrableForeignKeys) {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			`deferrable foreign keys require all nodes to be upgraded to %s`,
+			clusterversion.VersionByKey(clusterversion.VersionDeferrableForeignKeys))
+	}
+
 	originCols := make([]*descpb.ColumnDescriptor, len(d.FromCols))
 	originColMap := make(map[descpb.ColumnID]struct{}, len(d.FromCols))
 	for i, col := range d.FromCols {
@@ -839,6 +846,8 @@ func ResolveFK(
 		OnDelete:            descpb.ForeignKeyReferenceActionValue[d.Actions.Delete],
 		OnUpdate:            descpb.ForeignKeyReferenceActionValue[d.Actions.Update],
 		Match:               descpb.CompositeKeyMatchMethodValue[d.Match],
+		Deferrable:          d.Deferrability != tree.ConstraintNotDeferrable,
+		InitiallyDeferred:   d.Deferrability == tree.ConstraintInitiallyDeferred,
 	}
 
 	if ts == NewTable {
@@ -1318,6 +1327,13 @@ func NewTableDesc(
 				n.Defs = append(n.Defs, checkConstraint)
 				columnDefaultExprs = append(columnDefaultExprs, nil)
 			}
+			if d.IsVirtual() && version != (clusterversion.ClusterVersion{}) &&
+				!version.IsActive(clusterversion.VersionVirtualComputedColumns) {
+				return nil, errVirtualColumnsNotSupported
+			}
+			if err := validateUniqueDeferrability(version, d.UniqueDeferrability); err != nil {
+				return nil, err
+			}
 			col, idx, expr, err := tabledesc.MakeColumnDefDescs(ctx, d, semaCtx, evalCtx)
 			if err != nil {
 				return nil, err
@@ -1424,6 +1440,12 @@ func NewTableDesc(
 			if d.Inverted {
 				idx.Type = descpb.IndexDescriptor_INVERTED
 			}
+			if err := validateIndexOpClasses(version, &desc, d.Columns, d.Inverted); err != nil {
+				return nil, err
+			}
+			if err := validateIndexNullsOrder(version, d.Columns, d.Inverted); err != nil {
+				return nil, err
+			}
 			if d.Sharded != nil {
 				if d.Interleave != nil {
 					return nil, pgerror.New(pgcode.FeatureNotSupported, "interleaved indexes cannot also be hash sharded")
@@ -1489,6 +1511,20 @@ func NewTableDesc(
 				StoreColumnNames: d.Storing.ToStrings(),
 				Version:          indexEncodingVersion,
 			}
+			if err := validateIndexOpClasses(version, &desc, d.Columns, false /* inverted */); err != nil {
+				return nil, err
+			}
+			if err := validateIndexNullsOrder(version, d.Columns, false /* inverted */); err != nil {
+				return nil, err
+			}
+			if err := validateUniqueDeferrability(version, d.Deferrability); err != nil {
+				return nil, err
+			}
+			if d.Deferrability != tree.ConstraintNotDeferrable && d.Predicate != nil {
+				return nil, unimplemented.NewWithIssueDetail(31632, "deferrable partial unique",
+					"partial unique constraints cannot be deferrable")
+			}
+			idx.SetUniqueDeferrability(d.Deferrability)
 			if d.Sharded != nil {
 				if n.Interleave != nil && d.PrimaryKey {
 					return nil, pgerror.New(pgcode.FeatureNotSupported, "interleaved indexes cannot also be hash sharded")
@@ -1738,6 +1774,9 @@ func NewTableDesc(
 				} else if geoindex.IsGeometryConfig(&idx.GeoConfig) {
 					telemetry.Inc(sqltelemetry.GeometryInvertedIndexCounter)
 				}
+			} else if col, err := desc.FindColumnByID(idx.ColumnIDs[0]); err == nil &&
+				colinfo.ColumnTypeIsTrigramIndexable(col.Type) {
+				telemetry.Inc(sqltelemetry.TrigramInvertedIndexCounter)
 			}
 		}
 		return nil
@@ -1944,13 +1983,16 @@ func replaceLikeTableOpts(n *tree.CreateTable, params runParams) (tree.TableDefs
 					if idx.ColumnDirections[i] == descpb.IndexDescriptor_DESC {
 						elem.Direction = tree.Descending
 					}
+					if idx.ColumnNullsReversedAt(i) {
+						elem.NullsOrder = idx.ColumnNullsOrder(i)
+					}
 					indexDef.Columns = append(indexDef.Columns, elem)
 				}
 				for _, name := range idx.StoreColumnNames {
 					indexDef.Storing = append(indexDef.Storing, tree.Name(name))
 				}
 				var def tree.TableDef = &indexDef
-				if idx.Unique {
+				if idx.Unique || idx.DeferrableUnique {
 					isPK := idx.ID == td.PrimaryIndex.ID
 					if isPK && td.IsPrimaryIndexDefaultRowID() {
 						continue
@@ -1959,6 +2001,7 @@ func replaceLikeTableOpts(n *tree.CreateTable, params runParams) (tree.TableDefs
 					def = &tree.UniqueConstraintTableDef{
 						IndexTableDef: indexDef,
 						PrimaryKey:    isPK,
+						Deferrability: idx.UniqueDeferrability(),
 					}
 				}
 				if idx.IsPartial() {
@@ -2076,18 +2119,27 @@ func makeShardCheckConstraintDef(
 	}, nil
 }
 
+var errVirtualColumnsNotSupported = pgerror.Newf(pgcode.FeatureNotSupported,
+	"virtual computed columns require all nodes to be upgraded to %s",
+	clusterversion.VersionByKey(clusterversion.VersionVirtualComputedColumns))
+
 // incTelemetryForNewColumn increments relevant telemetry every time a new column
 // is added to a table.
 func incTelemetryForNewColumn(def *tree.ColumnTableDef, desc *descpb.ColumnDescriptor) {
-	switch desc.Type.Family() {
-	case types.EnumFamily:
+	switch {
+	case desc.Type.Family() == types.EnumFamily:
 		sqltelemetry.IncrementEnumCounter(sqltelemetry.EnumInTable)
+	case desc.Type.IsDomain():
+		sqltelemetry.IncrementDomainCounter(sqltelemetry.DomainInTable)
 	default:
 		telemetry.Inc(sqltelemetry.SchemaNewTypeCounter(desc.Type.TelemetryName()))
 	}
 	if desc.IsComputed() {
 		telemetry.Inc(sqltelemetry.SchemaNewColumnTypeQualificationCounter("computed"))
 	}
+	if desc.IsVirtual() {
+		telemetry.Inc(sqltelemetry.SchemaNewColumnTypeQualificationCounter("virtual"))
+	}
 	if desc.HasDefault() {
 		telemetry.Inc(sqltelemetry.SchemaNewColumnTypeQualificationCounter("default_expr"))
 	}
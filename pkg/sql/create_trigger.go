@@ -0,0 +1,160 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+)
+
+type createTriggerNode struct {
+	n         *tree.CreateTrigger
+	tableDesc *tabledesc.Mutable
+	tableName *tree.TableName
+	fnDesc    *funcdesc.Mutable
+}
+
+// CreateTrigger creates a row-level trigger on a table.
+// Privileges: CREATE on table, EXECUTE on function.
+//   notes: postgres requires TRIGGER on the table and EXECUTE on the function.
+func (p *planner) CreateTrigger(ctx context.Context, n *tree.CreateTrigger) (planNode, error) {
+	tableDesc, err := p.ResolveMutableTableDescriptorEx(
+		ctx, n.Table, true /* required */, tree.ResolveRequireTableDesc,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+	tableName := n.Table.ToTableName()
+
+	_, fnDesc, err := resolver.ResolveMutableFunction(ctx, p, n.FuncName, true /* required */)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CheckPrivilege(ctx, fnDesc, privilege.EXECUTE); err != nil {
+		return nil, err
+	}
+	if !fnDesc.ReturnsTrigger {
+		return nil, pgerror.Newf(pgcode.InvalidObjectDefinition,
+			"function %s must return type trigger", fnDesc.Name)
+	}
+
+	// The row returned by the function executed by a BEFORE trigger replaces the
+	// row being written, so it must be a query. The function executed by an
+	// AFTER trigger is only executed for its side effects.
+	stmt, err := parser.ParseOne(fnDesc.Body)
+	if err != nil {
+		return nil, err
+	}
+	_, isSelect := stmt.AST.(*tree.Select)
+	if n.Before && !isSelect {
+		return nil, pgerror.Newf(pgcode.InvalidObjectDefinition,
+			"function %s executed by a BEFORE trigger must consist of a SELECT statement",
+			fnDesc.Name)
+	}
+	if !n.Before && isSelect {
+		return nil, pgerror.Newf(pgcode.InvalidObjectDefinition,
+			"function %s executed by an AFTER trigger must consist of an INSERT, UPSERT or DELETE statement",
+			fnDesc.Name)
+	}
+
+	return &createTriggerNode{n: n, tableDesc: tableDesc, tableName: &tableName, fnDesc: fnDesc}, nil
+}
+
+// ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
+// This is because CREATE TRIGGER performs multiple KV operations on
+// descriptors and expects to see its own writes.
+func (n *createTriggerNode) ReadingOwnWrites() {}
+
+func (n *createTriggerNode) startExec(params runParams) error {
+	p := params.p
+	if !p.ExecCfg().Settings.Version.IsActive(params.ctx, clusterversion.VersionUserDefinedFunctions) {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			`creating triggers requires all nodes to be upgraded to %s`,
+			clusterversion.VersionByKey(clusterversion.VersionUserDefinedFunctions))
+	}
+	telemetry.Inc(sqltelemetry.SchemaChangeCreateCounter("trigger"))
+
+	for i := range n.tableDesc.Triggers {
+		if n.tableDesc.Triggers[i].Name == string(n.n.Name) {
+			return pgerror.Newf(pgcode.DuplicateObject,
+				"trigger %q for relation %q already exists", n.n.Name, n.tableDesc.Name)
+		}
+	}
+
+	// Triggers are kept sorted by name, which is the order in which they fire.
+	n.tableDesc.Triggers = append(n.tableDesc.Triggers, descpb.TableDescriptor_Trigger{
+		Name:       string(n.n.Name),
+		Before:     n.n.Before,
+		Insert:     n.n.Events.Contains(tree.TriggerEventInsert),
+		Update:     n.n.Events.Contains(tree.TriggerEventUpdate),
+		Delete:     n.n.Events.Contains(tree.TriggerEventDelete),
+		FunctionID: n.fnDesc.ID,
+	})
+	sort.Slice(n.tableDesc.Triggers, func(i, j int) bool {
+		return n.tableDesc.Triggers[i].Name < n.tableDesc.Triggers[j].Name
+	})
+	n.fnDesc.DependedOnByTriggers = append(
+		removeMatchingIDs(n.fnDesc.DependedOnByTriggers, n.tableDesc.ID), n.tableDesc.ID,
+	)
+
+	if err := n.tableDesc.Validate(
+		params.ctx, catalogkv.NewOneLevelUncachedDescGetter(p.txn, p.ExecCfg().Codec),
+	); err != nil {
+		return err
+	}
+
+	jobDesc := tree.AsStringWithFQNames(n.n, params.Ann())
+	if err := p.writeSchemaChange(
+		params.ctx, n.tableDesc, descpb.InvalidMutationID, jobDesc,
+	); err != nil {
+		return err
+	}
+	if err := p.writeFunctionDescChange(params.ctx, n.fnDesc, jobDesc); err != nil {
+		return err
+	}
+
+	// Log Create Trigger event. This is an auditable log event and is
+	// recorded in the same transaction as the table descriptor update.
+	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+		params.ctx,
+		p.txn,
+		EventLogCreateTrigger,
+		int32(n.tableDesc.ID),
+		int32(params.extendedEvalCtx.NodeID.SQLInstanceID()),
+		struct {
+			TriggerName string
+			TableName   string
+			Statement   string
+			User        string
+		}{n.n.Name.String(), n.tableName.FQString(), jobDesc, params.SessionData().User},
+	)
+}
+
+func (*createTriggerNode) Next(runParams) (bool, error) { return false, nil }
+func (*createTriggerNode) Values() tree.Datums          { return tree.Datums{} }
+func (*createTriggerNode) Close(context.Context)        {}
@@ -21,18 +21,21 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkeys"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/enum"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/errors"
+	"github.com/lib/pq/oid"
 )
 
 type createTypeNode struct {
@@ -50,6 +53,10 @@ func (n *createTypeNode) startExec(params runParams) error {
 	switch n.n.Variety {
 	case tree.Enum:
 		return params.p.createEnum(params, n.n)
+	case tree.Domain:
+		return params.p.createDomain(params, n.n)
+	case tree.Composite:
+		return params.p.createComposite(params, n.n)
 	default:
 		return unimplemented.NewWithIssue(25123, "CREATE TYPE")
 	}
@@ -194,6 +201,18 @@ func (p *planner) createArrayType(
 	switch t := typDesc.Kind; t {
 	case descpb.TypeDescriptor_ENUM:
 		elemTyp = types.MakeEnum(typedesc.TypeIDToOID(typDesc.GetID()), typedesc.TypeIDToOID(id))
+	case descpb.TypeDescriptor_DOMAIN:
+		elemTyp = types.MakeDomain(typedesc.TypeIDToOID(typDesc.GetID()), typedesc.TypeIDToOID(id), typDesc.Alias)
+	case descpb.TypeDescriptor_COMPOSITE:
+		contents := make([]*types.T, len(typDesc.CompositeFields))
+		labels := make([]string, len(typDesc.CompositeFields))
+		for i := range typDesc.CompositeFields {
+			contents[i] = typDesc.CompositeFields[i].Type
+			labels[i] = typDesc.CompositeFields[i].Name
+		}
+		elemTyp = types.MakeComposite(
+			typedesc.TypeIDToOID(typDesc.GetID()), typedesc.TypeIDToOID(id), contents, labels,
+		)
 	default:
 		return 0, errors.AssertionFailedf("cannot make array type for kind %s", t.String())
 	}
@@ -341,6 +360,327 @@ func (p *planner) createEnum(params runParams, n *tree.CreateType) error {
 	)
 }
 
+func (p *planner) createDomain(params runParams, n *tree.CreateType) error {
+	// Make sure that all nodes in the cluster are able to recognize DOMAIN
+	// types.
+	if !p.ExecCfg().Settings.Version.IsActive(params.ctx, clusterversion.VersionDomains) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"not all nodes are the correct version for DOMAIN type creation")
+	}
+
+	sqltelemetry.IncrementDomainCounter(sqltelemetry.DomainCreate)
+
+	baseType, err := tree.ResolveType(params.ctx, n.DomainType, p.semaCtx.GetTypeResolver())
+	if err != nil {
+		return err
+	}
+	if err := checkDomainBaseType(baseType); err != nil {
+		return err
+	}
+
+	// Resolve the desired new type name.
+	typeName, db, err := resolveNewTypeName(params, n.TypeName)
+	if err != nil {
+		return err
+	}
+	n.TypeName.SetAnnotation(&p.semaCtx.Annotations, typeName)
+
+	// Generate a key in the namespace table and a new id for this type.
+	typeKey, schemaID, err := getCreateTypeParams(params, typeName, db)
+	if err != nil {
+		return err
+	}
+
+	var defaultExpr *string
+	if n.DomainDefault != nil {
+		expr, err := schemaexpr.ValidateDomainDefaultExpr(params.ctx, n.DomainDefault, baseType, &p.semaCtx)
+		if err != nil {
+			return err
+		}
+		defaultExpr = &expr
+	}
+
+	// There is no existing data of the new type, so its constraints are
+	// validated from the start.
+	var constraints []descpb.TypeDescriptor_DomainConstraint
+	for i := range n.DomainConstraints {
+		c, err := makeDomainConstraint(
+			params.ctx, &p.semaCtx, typeName.Type(), baseType, &n.DomainConstraints[i], constraints,
+		)
+		if err != nil {
+			return err
+		}
+		c.Validity = descpb.ConstraintValidity_Validated
+		constraints = append(constraints, c)
+	}
+
+	// Generate a stable ID for the new type.
+	id, err := catalogkv.GenerateUniqueDescID(params.ctx, params.ExecCfg().DB, params.ExecCfg().Codec)
+	if err != nil {
+		return err
+	}
+
+	// Having USAGE on a parent schema of the type gives USAGE privilege to the
+	// type, as for enums.
+	privs := descpb.NewDefaultPrivilegeDescriptor(params.p.User())
+	resolvedSchema, err := p.Descriptors().ResolveSchemaByID(params.ctx, p.Txn(), schemaID)
+	if err != nil {
+		return err
+	}
+
+	inheritUsagePrivilegeFromSchema(resolvedSchema, privs)
+	privs.Grant(params.p.User(), privilege.List{privilege.ALL})
+
+	typeDesc := typedesc.NewCreatedMutable(
+		descpb.TypeDescriptor{
+			Name:              typeName.Type(),
+			ID:                id,
+			ParentID:          db.GetID(),
+			ParentSchemaID:    schemaID,
+			Kind:              descpb.TypeDescriptor_DOMAIN,
+			Alias:             baseType,
+			DomainConstraints: constraints,
+			DomainDefaultExpr: defaultExpr,
+			Version:           1,
+			Privileges:        privs,
+		})
+
+	// Create the implicit array type for this type before finishing the type.
+	arrayTypeID, err := p.createArrayType(params, n, typeName, typeDesc, db, schemaID)
+	if err != nil {
+		return err
+	}
+
+	// Update the typeDesc with the created array type ID.
+	typeDesc.ArrayTypeID = arrayTypeID
+
+	// Now create the type after the implicit array type as been created.
+	if err := p.createDescriptorWithID(
+		params.ctx,
+		typeKey.Key(params.ExecCfg().Codec),
+		id,
+		typeDesc,
+		params.EvalContext().Settings,
+		tree.AsStringWithFQNames(n, params.Ann()),
+	); err != nil {
+		return err
+	}
+
+	// Log the event.
+	return MakeEventLogger(p.ExecCfg()).InsertEventRecord(
+		params.ctx,
+		p.txn,
+		EventLogCreateType,
+		int32(typeDesc.GetID()),
+		int32(p.ExtendedEvalContext().NodeID.SQLInstanceID()),
+		struct {
+			TypeName  string
+			Statement string
+			User      string
+		}{typeName.FQString(), tree.AsStringWithFQNames(n, params.Ann()), p.User()},
+	)
+}
+
+func (p *planner) createComposite(params runParams, n *tree.CreateType) error {
+	// Make sure that all nodes in the cluster are able to recognize composite
+	// types.
+	if !p.ExecCfg().Settings.Version.IsActive(params.ctx, clusterversion.VersionCompositeTypes) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"not all nodes are the correct version for composite type creation")
+	}
+
+	sqltelemetry.IncrementCompositeTypeCounter(sqltelemetry.CompositeTypeCreate)
+
+	fields := make([]descpb.TypeDescriptor_CompositeField, len(n.CompositeFields))
+	seenNames := make(map[tree.Name]struct{}, len(n.CompositeFields))
+	for i := range n.CompositeFields {
+		f := &n.CompositeFields[i]
+		if _, ok := seenNames[f.Label]; ok {
+			return pgerror.Newf(pgcode.DuplicateColumn,
+				"column %q specified more than once", f.Label)
+		}
+		seenNames[f.Label] = struct{}{}
+
+		typ, err := tree.ResolveType(params.ctx, f.Type, p.semaCtx.GetTypeResolver())
+		if err != nil {
+			return err
+		}
+		if err := checkCompositeFieldType(typ); err != nil {
+			return err
+		}
+		fields[i] = descpb.TypeDescriptor_CompositeField{Name: string(f.Label), Type: typ}
+	}
+
+	// Resolve the desired new type name.
+	typeName, db, err := resolveNewTypeName(params, n.TypeName)
+	if err != nil {
+		return err
+	}
+	n.TypeName.SetAnnotation(&p.semaCtx.Annotations, typeName)
+
+	// Generate a key in the namespace table and a new id for this type.
+	typeKey, schemaID, err := getCreateTypeParams(params, typeName, db)
+	if err != nil {
+		return err
+	}
+
+	// Generate a stable ID for the new type.
+	id, err := catalogkv.GenerateUniqueDescID(params.ctx, params.ExecCfg().DB, params.ExecCfg().Codec)
+	if err != nil {
+		return err
+	}
+
+	// Having USAGE on a parent schema of the type gives USAGE privilege to the
+	// type, as for enums.
+	privs := descpb.NewDefaultPrivilegeDescriptor(params.p.User())
+	resolvedSchema, err := p.Descriptors().ResolveSchemaByID(params.ctx, p.Txn(), schemaID)
+	if err != nil {
+		return err
+	}
+
+	inheritUsagePrivilegeFromSchema(resolvedSchema, privs)
+	privs.Grant(params.p.User(), privilege.List{privilege.ALL})
+
+	typeDesc := typedesc.NewCreatedMutable(
+		descpb.TypeDescriptor{
+			Name:            typeName.Type(),
+			ID:              id,
+			ParentID:        db.GetID(),
+			ParentSchemaID:  schemaID,
+			Kind:            descpb.TypeDescriptor_COMPOSITE,
+			CompositeFields: fields,
+			Version:         1,
+			Privileges:      privs,
+		})
+
+	// Create the implicit array type for this type before finishing the type.
+	arrayTypeID, err := p.createArrayType(params, n, typeName, typeDesc, db, schemaID)
+	if err != nil {
+		return err
+	}
+
+	// Update the typeDesc with the created array type ID.
+	typeDesc.ArrayTypeID = arrayTypeID
+
+	// Now create the type after the implicit array type as been created.
+	if err := p.createDescriptorWithID(
+		params.ctx,
+		typeKey.Key(params.ExecCfg().Codec),
+		id,
+		typeDesc,
+		params.EvalContext().Settings,
+		tree.AsStringWithFQNames(n, params.Ann()),
+	); err != nil {
+		return err
+	}
+
+	// Log the event.
+	return MakeEventLogger(p.ExecCfg()).InsertEventRecord(
+		params.ctx,
+		p.txn,
+		EventLogCreateType,
+		int32(typeDesc.GetID()),
+		int32(p.ExtendedEvalContext().NodeID.SQLInstanceID()),
+		struct {
+			TypeName  string
+			Statement string
+			User      string
+		}{typeName.FQString(), tree.AsStringWithFQNames(n, params.Ann()), p.User()},
+	)
+}
+
+// checkCompositeFieldType returns an error if a field of a composite type
+// cannot have the given type. The fields of a composite type must be valid
+// column types, so that the composite type can be used for table columns.
+func checkCompositeFieldType(typ *types.T) error {
+	if typ.UserDefined() {
+		return unimplemented.NewWithIssueDetailf(27792, "user defined",
+			"composite type fields of user defined types are not supported")
+	}
+	return colinfo.ValidateColumnDefType(typ)
+}
+
+// checkDomainBaseType returns an error if a DOMAIN cannot be defined over the
+// given type. A domain has the same representation as its base type but a
+// different OID, so base types whose semantics depend on their OID are not
+// supported.
+func checkDomainBaseType(typ *types.T) error {
+	if typ.UserDefined() {
+		return unimplemented.NewWithIssueDetailf(27796, "user defined",
+			"domains over user defined types are not supported")
+	}
+	switch typ.Family() {
+	case types.ArrayFamily, types.TupleFamily, types.OidFamily, types.CollatedStringFamily:
+		return unimplemented.NewWithIssueDetailf(27796, typ.Family().Name(),
+			"domains over type %s are not supported", typ.SQLString())
+	case types.AnyFamily, types.UnknownFamily:
+		return pgerror.Newf(pgcode.DatatypeMismatch,
+			"%q is not a valid base type for a domain", typ.SQLString())
+	}
+	switch typ.Oid() {
+	case oid.T_bpchar, oid.T_char, oid.T_name, oid.T_varbit:
+		return unimplemented.NewWithIssueDetailf(27796, typ.Name(),
+			"domains over type %s are not supported", typ.SQLString())
+	}
+	return nil
+}
+
+// makeDomainConstraint validates the given constraint of the DOMAIN with the
+// given name and base type, and returns its descriptor. A name is generated
+// for the constraint if it has none, which does not conflict with any of the
+// existing constraints of the domain.
+func makeDomainConstraint(
+	ctx context.Context,
+	semaCtx *tree.SemaContext,
+	domainName string,
+	baseType *types.T,
+	c *tree.DomainConstraint,
+	existing []descpb.TypeDescriptor_DomainConstraint,
+) (descpb.TypeDescriptor_DomainConstraint, error) {
+	inUse := func(name string) bool {
+		for i := range existing {
+			if existing[i].Name == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	name := string(c.Name)
+	if name != "" && inUse(name) {
+		return descpb.TypeDescriptor_DomainConstraint{}, pgerror.Newf(pgcode.DuplicateObject,
+			"constraint %q for domain %q already exists", name, domainName)
+	}
+
+	if c.NotNull {
+		for i := range existing {
+			if existing[i].NotNull {
+				return descpb.TypeDescriptor_DomainConstraint{}, pgerror.Newf(pgcode.DuplicateObject,
+					"domain %q already has a NOT NULL constraint", domainName)
+			}
+		}
+		if name == "" {
+			name = domainName + "_not_null"
+			for i := 1; inUse(name); i++ {
+				name = fmt.Sprintf("%s_not_null%d", domainName, i)
+			}
+		}
+		return descpb.TypeDescriptor_DomainConstraint{Name: name, NotNull: true}, nil
+	}
+
+	expr, err := schemaexpr.ValidateDomainCheckExpr(ctx, c.Check, baseType, semaCtx)
+	if err != nil {
+		return descpb.TypeDescriptor_DomainConstraint{}, err
+	}
+	if name == "" {
+		name = domainName + "_check"
+		for i := 1; inUse(name); i++ {
+			name = fmt.Sprintf("%s_check%d", domainName, i)
+		}
+	}
+	return descpb.TypeDescriptor_DomainConstraint{Name: name, CheckExpr: expr}, nil
+}
+
 func (n *createTypeNode) Next(params runParams) (bool, error) { return false, nil }
 func (n *createTypeNode) Values() tree.Datums                 { return tree.Datums{} }
 func (n *createTypeNode) Close(ctx context.Context)           {}
@@ -0,0 +1,463 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// Deferrable foreign key constraints are implemented as follows. The FK
+// checks of a mutation always run at the end of the statement. If a check
+// finds violations and its constraint is deferred, the violations are not
+// reported; instead, the values of the constraint's columns in the violating
+// rows are recorded in the transaction. Before the transaction commits, the
+// rows of the origin table with these values are validated again, and the
+// commit fails if any of them still has no match in the referenced table.
+// Rows that were not involved in a violation are not checked again: a
+// statement that makes them violate the constraint records them itself.
+//
+// Deferrable UNIQUE constraints work the same way. Their index is not unique,
+// so rows are written to it without a conflict check, and duplicates are
+// found by a check that runs at the end of the statement like an FK check.
+// The duplicated values of a deferred constraint are recorded, and the commit
+// fails if any of them is still present in more than one row.
+//
+// If a constraint is violated by more than maxDeferredKeys distinct values,
+// the values are no longer recorded and the constraint is validated against
+// the whole table instead, the same way as with VALIDATE CONSTRAINT.
+
+// maxDeferredKeys is the maximum number of distinct values recorded for the
+// violations of a deferred constraint.
+const maxDeferredKeys = 10000
+
+// deferredKeysBatchSize is the number of recorded values validated by each
+// query at commit.
+const deferredKeysBatchSize = 100
+
+// constraintsMode is the mode set by SET CONSTRAINTS ALL.
+type constraintsMode int8
+
+const (
+	// constraintsModeDefault defers the checks of the constraints that are
+	// INITIALLY DEFERRED.
+	constraintsModeDefault constraintsMode = iota
+	// constraintsModeAllDeferred defers the checks of all deferrable
+	// constraints.
+	constraintsModeAllDeferred
+	// constraintsModeAllImmediate defers no checks.
+	constraintsModeAllImmediate
+)
+
+// deferredConstraintKey identifies a foreign key constraint by its origin
+// table and name, or a unique constraint by its table and the name of its
+// index.
+type deferredConstraintKey struct {
+	tableID descpb.ID
+	name    string
+}
+
+// deferredConstraints keeps track of the deferred constraints of a
+// transaction.
+type deferredConstraints struct {
+	mode constraintsMode
+
+	// pending contains the deferred constraints that were violated by the
+	// transaction, and which must be validated before it commits.
+	pending map[deferredConstraintKey]*pendingConstraint
+}
+
+// pendingConstraint records the violations of a deferred constraint.
+type pendingConstraint struct {
+	// keys contains the values of the columns of the constraint in the rows
+	// that violated it, indexed by their SQL representation.
+	keys map[string]tree.Datums
+	// all is set if the constraint was violated by too many distinct values
+	// for them to be recorded, in which case the whole table is validated.
+	all bool
+	// unique is set if the constraint is a unique constraint.
+	unique bool
+}
+
+// isDeferred returns true if the checks of the given constraint are
+// currently deferred.
+func (dc *deferredConstraints) isDeferred(c *exec.DeferrableConstraint) bool {
+	switch dc.mode {
+	case constraintsModeAllDeferred:
+		return true
+	case constraintsModeAllImmediate:
+		return false
+	}
+	return c.InitiallyDeferred
+}
+
+// addPending records a violation of the given deferred constraint. row is the
+// row produced by the check of the constraint.
+func (dc *deferredConstraints) addPending(c *exec.DeferrableConstraint, row tree.Datums) {
+	if dc.pending == nil {
+		dc.pending = make(map[deferredConstraintKey]*pendingConstraint)
+	}
+	k := deferredConstraintKey{tableID: descpb.ID(c.TableID), name: c.Name}
+	pc := dc.pending[k]
+	if pc == nil {
+		pc = &pendingConstraint{keys: make(map[string]tree.Datums), unique: c.Unique}
+		dc.pending[k] = pc
+	}
+	if pc.all {
+		return
+	}
+	vals := make(tree.Datums, len(c.KeyCols))
+	for i, col := range c.KeyCols {
+		vals[i] = row[col]
+	}
+	key := tree.AsStringWithFlags(&vals, tree.FmtSerializable)
+	if _, ok := pc.keys[key]; ok {
+		return
+	}
+	if len(pc.keys) >= maxDeferredKeys {
+		pc.keys = nil
+		pc.all = true
+		return
+	}
+	pc.keys[key] = vals
+}
+
+// reset clears the state at the end of a transaction.
+func (dc *deferredConstraints) reset() {
+	*dc = deferredConstraints{}
+}
+
+// validatePending validates the pending constraints and clears them. The
+// descriptor collection must be that of the transaction, so that the
+// constraints are validated against the descriptors the transaction sees.
+func (dc *deferredConstraints) validatePending(
+	ctx context.Context, txn *kv.Txn, tc *descs.Collection, ie *InternalExecutor, codec keys.SQLCodec,
+) error {
+	if len(dc.pending) == 0 {
+		return nil
+	}
+	// Validate the constraints in a deterministic order, so that the error is
+	// the same if several of them are violated.
+	sorted := make([]deferredConstraintKey, 0, len(dc.pending))
+	for k := range dc.pending {
+		sorted = append(sorted, k)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].tableID != sorted[j].tableID {
+			return sorted[i].tableID < sorted[j].tableID
+		}
+		return sorted[i].name < sorted[j].name
+	})
+
+	// The internal executor must see the schema changes made by the
+	// transaction.
+	ie.tcModifier = tc
+	defer func() {
+		ie.tcModifier = nil
+	}()
+
+	flags := tree.ObjectLookupFlagsWithRequired()
+	flags.IncludeDropped = true
+	for _, k := range sorted {
+		desc, err := tc.GetTableVersionByID(ctx, txn, k.tableID, flags)
+		if err != nil {
+			return err
+		}
+		if desc.Dropped() {
+			continue
+		}
+		pc := dc.pending[k]
+		if pc.unique {
+			idx, dropped, err := desc.FindIndexByName(k.name)
+			if err != nil || dropped || !idx.DeferrableUnique {
+				// The constraint was dropped by the transaction.
+				continue
+			}
+			if err := validateUniqueValues(ctx, desc, idx, pc, ie, txn); err != nil {
+				return err
+			}
+			continue
+		}
+		fk, err := desc.FindFKByName(k.name)
+		if err != nil {
+			// The constraint was dropped by the transaction.
+			continue
+		}
+		srcTable := tabledesc.NewExistingMutable(*desc.TableDesc())
+		if pc.all {
+			err = validateForeignKey(ctx, srcTable, fk, ie, txn, codec)
+		} else {
+			err = validateForeignKeyValues(ctx, srcTable, fk, pc.keys, ie, txn, codec)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	dc.pending = nil
+	return nil
+}
+
+// validateForeignKeyValues is like validateForeignKey, but it only verifies
+// the rows of srcTable whose values for the columns of the constraint are
+// among vals.
+func validateForeignKeyValues(
+	ctx context.Context,
+	srcTable *tabledesc.Mutable,
+	fk *descpb.ForeignKeyConstraint,
+	vals map[string]tree.Datums,
+	ie *InternalExecutor,
+	txn *kv.Txn,
+	codec keys.SQLCodec,
+) error {
+	desc, err := catalogkv.GetDescriptorByID(ctx, txn, codec, fk.ReferencedTableID, catalogkv.Immutable,
+		catalogkv.TableDescriptorKind, true /* required */)
+	if err != nil {
+		return err
+	}
+	targetTable := desc.(catalog.TableDescriptor)
+	nCols := len(fk.OriginColumnIDs)
+	srcColNames, err := srcTable.NamesForColumnIDs(fk.OriginColumnIDs)
+	if err != nil {
+		return err
+	}
+	targetColNames, err := targetTable.NamesForColumnIDs(fk.ReferencedColumnIDs)
+	if err != nil {
+		return err
+	}
+
+	// Like validateForeignKey, return the primary key of a violating row along
+	// with its values for the columns of the constraint.
+	colNames := append([]string(nil), srcColNames...)
+	for _, id := range srcTable.GetPrimaryIndex().ColumnIDs {
+		found := false
+		for _, otherID := range fk.OriginColumnIDs {
+			if id == otherID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			col, err := srcTable.FindActiveColumnByID(id)
+			if err != nil {
+				return err
+			}
+			colNames = append(colNames, col.Name)
+		}
+	}
+	returnedCols := make([]string, len(colNames))
+	for i, n := range colNames {
+		// s and t are table aliases used in the query.
+		returnedCols[i] = fmt.Sprintf("s.%s", tree.NameString(n))
+	}
+	on := make([]string, nCols)
+	for i := 0; i < nCols; i++ {
+		on[i] = fmt.Sprintf("s.%s = t.%s",
+			tree.NameString(srcColNames[i]), tree.NameString(targetColNames[i]))
+	}
+
+	// Validate the values in a deterministic order, so that the error is the
+	// same if several rows violate the constraint.
+	sorted := make([]string, 0, len(vals))
+	for k := range vals {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for len(sorted) > 0 {
+		batch := sorted
+		if len(batch) > deferredKeysBatchSize {
+			batch = batch[:deferredKeysBatchSize]
+		}
+		sorted = sorted[len(batch):]
+
+		filters := make([]string, len(batch))
+		for i, k := range batch {
+			v := vals[k]
+			if len(v) != nCols {
+				// The constraint was replaced by one on different columns.
+				return validateForeignKey(ctx, srcTable, fk, ie, txn, codec)
+			}
+			conds := make([]string, nCols)
+			for j := range v {
+				if v[j] == tree.DNull {
+					// A MATCH FULL constraint was violated by a row mixing null
+					// and non-null values.
+					conds[j] = fmt.Sprintf("s.%s IS NULL", tree.NameString(srcColNames[j]))
+				} else {
+					conds[j] = fmt.Sprintf("s.%s = %s",
+						tree.NameString(srcColNames[j]), tree.AsStringWithFlags(v[j], tree.FmtSerializable))
+				}
+			}
+			filters[i] = fmt.Sprintf("(%s)", strings.Join(conds, " AND "))
+		}
+		query := fmt.Sprintf(
+			`SELECT %[1]s FROM [%[2]d AS s]@{IGNORE_FOREIGN_KEYS}
+			 WHERE (%[3]s) AND NOT EXISTS (SELECT 1 FROM [%[4]d AS t] WHERE %[5]s)
+			 LIMIT 1`,
+			strings.Join(returnedCols, ", "), // 1
+			srcTable.GetID(),                 // 2
+			strings.Join(filters, " OR "),    // 3
+			targetTable.GetID(),              // 4
+			strings.Join(on, " AND "),        // 5
+		)
+		log.VEventf(ctx, 2, "validating deferred FK %q with query %q", fk.Name, query)
+
+		values, err := ie.QueryRow(ctx, "validate deferred fk constraint", txn, query)
+		if err != nil {
+			return err
+		}
+		if values.Len() == 0 {
+			continue
+		}
+		for i := 0; i < nCols; i++ {
+			if values[i] == tree.DNull {
+				return pgerror.Newf(pgcode.ForeignKeyViolation,
+					"foreign key violation: MATCH FULL does not allow mixing of null and nonnull values %s for %s",
+					formatValues(colNames, values), fk.Name,
+				)
+			}
+		}
+		return pgerror.Newf(pgcode.ForeignKeyViolation,
+			"foreign key violation: %q row %s has no match in %q",
+			srcTable.Name, formatValues(colNames, values), targetTable.GetName())
+	}
+	return nil
+}
+
+// validateUniqueValues verifies that the values recorded for the violations of
+// the deferrable unique constraint enforced by idx are not present in more
+// than one row of table, or that no values are present in more than one row
+// if too many values were recorded.
+func validateUniqueValues(
+	ctx context.Context,
+	table catalog.TableDescriptor,
+	idx *descpb.IndexDescriptor,
+	pc *pendingConstraint,
+	ie *InternalExecutor,
+	txn *kv.Txn,
+) error {
+	nCols := len(idx.ColumnNames)
+	cols := make([]string, nCols)
+	notNull := make([]string, nCols)
+	for i, n := range idx.ColumnNames {
+		cols[i] = tree.NameString(n)
+		notNull[i] = fmt.Sprintf("%s IS NOT NULL", cols[i])
+	}
+
+	// Build the filter of each query. Validate the values in a deterministic
+	// order, so that the error is the same if several of them are duplicated.
+	sorted := make([]string, 0, len(pc.keys))
+	for k := range pc.keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	var filters []string
+	all := pc.all
+	for len(sorted) > 0 && !all {
+		batch := sorted
+		if len(batch) > deferredKeysBatchSize {
+			batch = batch[:deferredKeysBatchSize]
+		}
+		sorted = sorted[len(batch):]
+
+		disjuncts := make([]string, len(batch))
+		for i, k := range batch {
+			v := pc.keys[k]
+			if len(v) != nCols {
+				// The constraint was replaced by one on different columns.
+				all = true
+				break
+			}
+			conds := make([]string, nCols)
+			for j := range v {
+				conds[j] = fmt.Sprintf("%s = %s", cols[j], tree.AsStringWithFlags(v[j], tree.FmtSerializable))
+			}
+			disjuncts[i] = fmt.Sprintf("(%s)", strings.Join(conds, " AND "))
+		}
+		filters = append(filters, strings.Join(disjuncts, " OR "))
+	}
+	if all {
+		filters = []string{strings.Join(notNull, " AND ")}
+	}
+
+	for _, filter := range filters {
+		query := fmt.Sprintf(
+			`SELECT %[1]s FROM [%[2]d AS t] WHERE %[3]s GROUP BY %[1]s HAVING count(*) > 1 LIMIT 1`,
+			strings.Join(cols, ", "), // 1
+			table.GetID(),            // 2
+			filter,                   // 3
+		)
+		log.VEventf(ctx, 2, "validating deferred unique constraint %q with query %q", idx.Name, query)
+
+		values, err := ie.QueryRow(ctx, "validate deferred unique constraint", txn, query)
+		if err != nil {
+			return err
+		}
+		if values.Len() > 0 {
+			strs := make([]string, nCols)
+			for i := range strs {
+				strs[i] = values[i].String()
+			}
+			return pgerror.WithConstraintName(
+				pgerror.Newf(pgcode.UniqueViolation,
+					"duplicate key value (%s)=(%s) violates unique constraint %q",
+					strings.Join(idx.ColumnNames, ","), strings.Join(strs, ","), idx.Name,
+				),
+				idx.Name,
+			)
+		}
+	}
+	return nil
+}
+
+// SetConstraints implements the SET CONSTRAINTS statement.
+// See https://www.postgresql.org/docs/current/sql-set-constraints.html for
+// details.
+func (p *planner) SetConstraints(ctx context.Context, n *tree.SetConstraints) (planNode, error) {
+	if p.extendedEvalCtx.TxnImplicit {
+		// Like in Postgres, SET CONSTRAINTS has no effect outside of a
+		// transaction block.
+		p.BufferClientNotice(ctx, pgnotice.Newf("SET CONSTRAINTS can only be used in transaction blocks"))
+		return newZeroNode(nil /* columns */), nil
+	}
+	dc := p.deferredConstraints
+	if dc == nil {
+		// Internal executors always check the constraints immediately.
+		return newZeroNode(nil /* columns */), nil
+	}
+	if n.Deferred {
+		dc.mode = constraintsModeAllDeferred
+		return newZeroNode(nil /* columns */), nil
+	}
+	// The constraints that were deferred by the previous statements are
+	// checked now.
+	dc.mode = constraintsModeAllImmediate
+	ie := p.extendedEvalCtx.InternalExecutor.(*InternalExecutor)
+	if err := dc.validatePending(ctx, p.txn, p.Descriptors(), ie, p.ExecCfg().Codec); err != nil {
+		return nil, err
+	}
+	return newZeroNode(nil /* columns */), nil
+}
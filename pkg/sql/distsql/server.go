@@ -347,6 +347,12 @@ func (ds *ServerImpl) setupFlow(
 
 	// Create the FlowCtx for the flow.
 	flowCtx := ds.NewFlowContext(ctx, req.Flow.FlowID, evalCtx, req.TraceKV, localState)
+	if ds.DiskMonitor != nil {
+		// Like the memory monitor above, the disk monitor is closed in
+		// Flow.Cleanup().
+		flowCtx.DiskMonitor = execinfra.NewMonitor(ctx, ds.DiskMonitor, "flow-disk")
+	}
+	flowCtx.MeasureCPUTime = req.MeasureCPUTime
 
 	// req always contains the desired vectorize mode, regardless of whether we
 	// have non-nil localState.EvalContext. We don't want to update EvalContext
@@ -366,9 +372,12 @@ func (ds *ServerImpl) setupFlow(
 	var err error
 	if ctx, err = f.Setup(ctx, &req.Flow, opt); err != nil {
 		log.Errorf(ctx, "error setting up flow: %s", err)
-		// Flow.Cleanup will not be called, so we have to close the memory monitor
-		// and finish the span manually.
+		// Flow.Cleanup will not be called, so we have to close the monitors and
+		// finish the span manually.
 		monitor.Stop(ctx)
+		if flowCtx.DiskMonitor != nil {
+			flowCtx.DiskMonitor.Stop(ctx)
+		}
 		tracing.FinishSpan(sp)
 		ctx = opentracing.ContextWithSpan(ctx, nil)
 		return ctx, nil, err
@@ -1038,6 +1038,7 @@ func (dsp *DistSQLPlanner) convertOrdering(
 			dir = execinfrapb.Ordering_Column_DESC
 		}
 		result.Columns[i].Direction = dir
+		result.Columns[i].NullsReversed = o.NullsReversed
 	}
 	return result
 }
@@ -1724,6 +1725,7 @@ func (dsp *DistSQLPlanner) planAggregators(
 			} else {
 				ordCols[i].Direction = execinfrapb.Ordering_Column_ASC
 			}
+			ordCols[i].NullsReversed = o.NullsReversed
 		}
 
 		localAggsSpec := execinfrapb.AggregatorSpec{
@@ -3437,7 +3439,8 @@ func (dsp *DistSQLPlanner) createPlanForWindow(
 }
 
 // createPlanForExport creates a physical plan for EXPORT.
-// We add a new stage of CSVWriter processors to the input plan.
+// We add a new stage of CSVWriter, ParquetWriter or AvroWriter processors to
+// the input plan.
 func (dsp *DistSQLPlanner) createPlanForExport(
 	planCtx *PlanningCtx, n *exportNode,
 ) (*PhysicalPlan, error) {
@@ -3446,13 +3449,51 @@ func (dsp *DistSQLPlanner) createPlanForExport(
 		return nil, err
 	}
 
-	core := execinfrapb.ProcessorCoreUnion{CSVWriter: &execinfrapb.CSVWriterSpec{
-		Destination:      n.destination,
-		NamePattern:      n.fileNamePattern,
-		Options:          n.csvOpts,
-		ChunkRows:        int64(n.chunkRows),
-		CompressionCodec: n.fileCompression,
-	}}
+	var core execinfrapb.ProcessorCoreUnion
+	switch n.format {
+	case exportFormatCSV:
+		core.CSVWriter = &execinfrapb.CSVWriterSpec{
+			Destination:      n.destination,
+			NamePattern:      n.fileNamePattern,
+			Options:          n.csvOpts,
+			ChunkRows:        int64(n.chunkRows),
+			ChunkSize:        n.chunkSize,
+			CompressionCodec: n.fileCompression,
+		}
+	case exportFormatParquet, exportFormatAvro:
+		// The writers name the columns of the exported files after the columns
+		// of the input rows, which may be in a different order in the streams.
+		colNames := make([]string, len(plan.ResultTypes))
+		for i := range colNames {
+			colNames[i] = fmt.Sprintf("column%d", i+1)
+		}
+		for i, col := range planColumns(n.source) {
+			if idx := plan.PlanToStreamColMap[i]; idx >= 0 {
+				colNames[idx] = col.Name
+			}
+		}
+		if n.format == exportFormatParquet {
+			core.ParquetWriter = &execinfrapb.ParquetWriterSpec{
+				Destination:      n.destination,
+				NamePattern:      n.fileNamePattern,
+				ColNames:         colNames,
+				ChunkRows:        int64(n.chunkRows),
+				ChunkSize:        n.chunkSize,
+				CompressionCodec: n.fileCompression,
+			}
+		} else {
+			core.AvroWriter = &execinfrapb.AvroWriterSpec{
+				Destination:      n.destination,
+				NamePattern:      n.fileNamePattern,
+				ColNames:         colNames,
+				ChunkRows:        int64(n.chunkRows),
+				ChunkSize:        n.chunkSize,
+				CompressionCodec: n.fileCompression,
+			}
+		}
+	default:
+		return nil, errors.AssertionFailedf("unexpected export format %d", n.format)
+	}
 
 	resTypes := make([]*types.T, len(colinfo.ExportColumns))
 	for i := range colinfo.ExportColumns {
@@ -3462,7 +3503,7 @@ func (dsp *DistSQLPlanner) createPlanForExport(
 		core, execinfrapb.PostProcessSpec{}, resTypes, execinfrapb.Ordering{},
 	)
 
-	// The CSVWriter produces the same columns as the EXPORT statement.
+	// The writers produce the same columns as the EXPORT statement.
 	plan.PlanToStreamColMap = identityMap(plan.PlanToStreamColMap, len(colinfo.ExportColumns))
 	return plan, nil
 }
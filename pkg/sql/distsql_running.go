@@ -142,6 +142,7 @@ func (dsp *DistSQLPlanner) setupFlows(
 		Version:           execinfra.Version,
 		EvalContext:       evalCtxProto,
 		TraceKV:           evalCtx.Tracing.KVTracingEnabled(),
+		MeasureCPUTime:    recv.stats.cpuTimeMeasured,
 	}
 
 	// Start all the flows except the flow on this node (there is always a flow on
@@ -423,6 +424,16 @@ func (dsp *DistSQLPlanner) Run(
 		log.Fatalf(ctx, "unexpected error from syncFlow.Start(): %v\n"+
 			"The error should have gone to the consumer.", err)
 	}
+	// The gateway flow doesn't have an outbox, so we account for its memory and
+	// disk usage here, before flow.Cleanup closes its monitors. Its goroutines
+	// report their CPU time themselves.
+	if meta := flow.GetFlowCtx().GetFlowStatsMeta(0 /* networkBytes */, 0 /* cpuTime */); meta != nil {
+		recv.stats.addComponentStats(meta.ComponentStats)
+		if recv.resourceUsage != nil {
+			recv.resourceUsage.update(&recv.stats)
+		}
+		meta.Release()
+	}
 
 	// TODO(yuzefovich): it feels like this closing should happen after
 	// PlanAndRun. We should refactor this and get rid off ignoreClose field.
@@ -507,6 +518,9 @@ type DistSQLReceiver struct {
 
 	expectedRowsRead int64
 	progressAtomic   *uint64
+	// resourceUsage, if set, is updated with the resources consumed by the
+	// query as its flows report them.
+	resourceUsage *queryResourceUsage
 }
 
 // rowResultWriter is a subset of CommandResult to be used with the
@@ -628,6 +642,10 @@ func (r *DistSQLReceiver) clone() *DistSQLReceiver {
 		updateClock: r.updateClock,
 		stmtType:    tree.Rows,
 		tracing:     r.tracing,
+		// The resources consumed by subqueries and postqueries count towards
+		// the query's. The caller copies the stats back once the clone is done.
+		stats:         topLevelQueryStats{queryResourceStats: r.stats.queryResourceStats},
+		resourceUsage: r.resourceUsage,
 	}
 	return ret
 }
@@ -700,6 +718,12 @@ func (r *DistSQLReceiver) Push(
 			meta.Metrics.Release()
 			meta.Release()
 		}
+		if meta.ComponentStats != nil {
+			r.stats.addComponentStats(meta.ComponentStats)
+			if r.resourceUsage != nil {
+				r.resourceUsage.update(&r.stats)
+			}
+		}
 		if metaWriter, ok := r.resultWriter.(MetadataResultWriter); ok {
 			metaWriter.AddMeta(r.ctx, meta)
 		}
@@ -911,6 +935,7 @@ func (dsp *DistSQLPlanner) planAndRunSubquery(
 	subqueryRecv.resultWriter = subqueryRowReceiver
 	subqueryPlans[planIdx].started = true
 	dsp.Run(subqueryPlanCtx, planner.txn, subqueryPhysPlan, subqueryRecv, evalCtx, nil /* finishedSetupFn */)()
+	recv.stats.queryResourceStats = subqueryRecv.stats.queryResourceStats
 	if subqueryRecv.commErr != nil {
 		return subqueryRecv.commErr
 	}
@@ -1002,6 +1027,11 @@ func (dsp *DistSQLPlanner) PlanAndRun(
 	return dsp.Run(planCtx, txn, physPlan, recv, evalCtx, nil /* finishedSetupFn */)
 }
 
+// maxTriggerDepth is the maximum number of nested AFTER triggers that can fire
+// as a result of a single statement, for example when a trigger modifies the
+// table it is defined on.
+const maxTriggerDepth = 32
+
 // PlanAndRunCascadesAndChecks runs any cascade and check queries.
 //
 // Because cascades can themselves generate more cascades or check queries, this
@@ -1025,6 +1055,15 @@ func (dsp *DistSQLPlanner) PlanAndRunCascadesAndChecks(
 	prevSteppingMode := planner.Txn().ConfigureStepping(ctx, kv.SteppingEnabled)
 	defer func() { _ = planner.Txn().ConfigureStepping(ctx, prevSteppingMode) }()
 
+	// triggerDepths[i] is the number of AFTER triggers that fired to cause
+	// plan.cascades[i], including itself.
+	triggerDepths := make([]int, len(plan.cascades))
+	for i := range plan.cascades {
+		if plan.cascades[i].Trigger {
+			triggerDepths[i] = 1
+		}
+	}
+
 	// We treat plan.cascades as a queue.
 	for i := 0; i < len(plan.cascades); i++ {
 		// The original bufferNode is stored in c.Buffer; we can refer to it
@@ -1075,7 +1114,20 @@ func (dsp *DistSQLPlanner) PlanAndRunCascadesAndChecks(
 			return false
 		}
 
-		// Queue any new cascades.
+		// Queue any new cascades. Triggers can fire each other recursively, so we
+		// limit the depth of nested triggers.
+		for j := range cp.cascades {
+			depth := triggerDepths[i]
+			if cp.cascades[j].Trigger {
+				depth++
+				if depth > maxTriggerDepth {
+					recv.SetError(pgerror.Newf(pgcode.TriggeredActionException,
+						"trigger depth limit (%d) reached", maxTriggerDepth))
+					return false
+				}
+			}
+			triggerDepths = append(triggerDepths, depth)
+		}
 		if len(cp.cascades) > 0 {
 			plan.cascades = append(plan.cascades, cp.cascades...)
 		}
@@ -1191,6 +1243,7 @@ func (dsp *DistSQLPlanner) planAndRunPostquery(
 	// but it may not be the case when we support cascades through the optimizer.
 	postqueryRecv.resultWriter = &errOnlyResultWriter{}
 	dsp.Run(postqueryPlanCtx, planner.txn, postqueryPhysPlan, postqueryRecv, evalCtx, nil /* finishedSetupFn */)()
+	recv.stats.queryResourceStats = postqueryRecv.stats.queryResourceStats
 	if postqueryRecv.commErr != nil {
 		return postqueryRecv.commErr
 	}
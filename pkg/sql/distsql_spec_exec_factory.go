@@ -867,6 +867,12 @@ func (e *distSQLSpecExecFactory) ConstructCreateView(
 	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: create view")
 }
 
+func (e *distSQLSpecExecFactory) ConstructCreateFunction(
+	schema cat.Schema, cf *tree.CreateFunction, deps opt.ViewDeps,
+) (exec.Node, error) {
+	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: create function")
+}
+
 func (e *distSQLSpecExecFactory) ConstructSequenceSelect(sequence cat.Sequence) (exec.Node, error) {
 	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: sequence select")
 }
@@ -878,7 +884,7 @@ func (e *distSQLSpecExecFactory) ConstructSaveTable(
 }
 
 func (e *distSQLSpecExecFactory) ConstructErrorIfRows(
-	input exec.Node, mkErr exec.MkErrFn,
+	input exec.Node, mkErr exec.MkErrFn, deferrable *exec.DeferrableConstraint,
 ) (exec.Node, error) {
 	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: error if rows")
 }
@@ -888,7 +894,13 @@ func (e *distSQLSpecExecFactory) ConstructOpaque(metadata opt.OpaqueMetadata) (e
 	if err != nil {
 		return nil, err
 	}
-	physPlan, err := e.dsp.wrapPlan(e.getPlanCtx(cannotDistribute), plan)
+	// An opaque operator is always the root of the plan, so the statements that
+	// only return the number of rows they affect use the fast path of the
+	// wrapped planNode.
+	planCtx := e.getPlanCtx(cannotDistribute)
+	planCtx.planDepth = 1
+	planCtx.stmtType = e.planner.stmt.AST.StatementType()
+	physPlan, err := e.dsp.wrapPlan(planCtx, plan)
 	if err != nil {
 		return nil, err
 	}
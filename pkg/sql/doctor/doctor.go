@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -155,6 +156,12 @@ func ExamineDescriptors(
 		case catalog.SchemaDescriptor:
 			// parent schema id is always 0.
 			parentSchemaExists = true
+		case catalog.FunctionDescriptor:
+			fn := funcdesc.NewImmutable(*d.FuncDesc())
+			if err := fn.Validate(ctx, descGetter); err != nil {
+				problemsFound = true
+				fmt.Fprint(stdout, reportMsg(desc, "%s", err))
+			}
 		}
 		if desc.GetParentID() != descpb.InvalidID && !parentExists {
 			problemsFound = true
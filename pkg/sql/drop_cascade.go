@@ -16,6 +16,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
@@ -33,6 +34,7 @@ type dropCascadeState struct {
 	td                      []toDelete
 	allTableObjectsToDelete []*tabledesc.Mutable
 	typesToDelete           []*typedesc.Mutable
+	functionsToDelete       []*funcdesc.Mutable
 
 	droppedNames []string
 }
@@ -113,8 +115,38 @@ func (d *dropCascadeState) resolveCollectedObjects(
 				}
 			}
 			d.td = append(d.td, toDelete{objName, tbDesc})
+			continue
+		}
+		// If we couldn't resolve objName as a table, try a function.
+		found, desc, err = p.LookupObject(
+			ctx,
+			tree.ObjectLookupFlags{
+				CommonLookupFlags: tree.CommonLookupFlags{
+					Required:       false,
+					RequireMutable: true,
+					IncludeOffline: true,
+				},
+				DesiredObjectKind: tree.FunctionObject,
+			},
+			objName.Catalog(),
+			objName.Schema(),
+			objName.Object(),
+		)
+		if err != nil {
+			return err
+		}
+		if found {
+			fnDesc, ok := desc.(*funcdesc.Mutable)
+			if !ok {
+				return errors.AssertionFailedf(
+					"descriptor for %q is not Mutable",
+					objName.Object(),
+				)
+			}
+			d.functionsToDelete = append(d.functionsToDelete, fnDesc)
+			d.droppedNames = append(d.droppedNames, objName.FQString())
 		} else {
-			// If we couldn't resolve objName as a table, try a type.
+			// If we couldn't resolve objName as a table or function, try a type.
 			found, desc, err := p.LookupObject(
 				ctx,
 				tree.ObjectLookupFlags{
@@ -181,6 +213,17 @@ func (d *dropCascadeState) dropAllCollectedObjects(ctx context.Context, p *plann
 		d.droppedNames = append(d.droppedNames, toDel.tn.FQString())
 	}
 
+	// Delete all of the functions that were not already dropped along with a
+	// relation they depend on.
+	for _, fn := range d.functionsToDelete {
+		if fn.Dropped() {
+			continue
+		}
+		if err := p.dropFunctionImpl(ctx, fn, descpb.InvalidID, "dropping function in dropped schema"); err != nil {
+			return err
+		}
+	}
+
 	// Now delete all of the types.
 	for _, typ := range d.typesToDelete {
 		// Drop the types. Note that we set queueJob to be false because the types
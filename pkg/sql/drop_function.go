@@ -0,0 +1,122 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/errors"
+)
+
+type dropFunctionNode struct {
+	n *tree.DropFunction
+	// toDrop contains the functions to drop, in the order in which they were
+	// named in the statement.
+	toDrop []*funcdesc.Mutable
+	names  []*tree.TableName
+}
+
+// DropFunction drops user-defined functions.
+// Privileges: DROP on function.
+func (p *planner) DropFunction(ctx context.Context, n *tree.DropFunction) (planNode, error) {
+	node := &dropFunctionNode{n: n}
+	seen := make(map[descpb.ID]struct{}, len(n.Names))
+	for _, name := range n.Names {
+		fnName, fnDesc, err := resolver.ResolveMutableFunction(ctx, p, name, !n.IfExists)
+		if err != nil {
+			return nil, err
+		}
+		if fnDesc == nil {
+			continue
+		}
+		if _, ok := seen[fnDesc.ID]; ok {
+			continue
+		}
+		seen[fnDesc.ID] = struct{}{}
+		if err := p.CheckPrivilege(ctx, fnDesc, privilege.DROP); err != nil {
+			return nil, err
+		}
+		if err := p.canDropFunctionWithTriggers(ctx, fnDesc, n.DropBehavior); err != nil {
+			return nil, err
+		}
+		node.toDrop = append(node.toDrop, fnDesc)
+		node.names = append(node.names, fnName)
+	}
+	return node, nil
+}
+
+func (n *dropFunctionNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeDropCounter("function"))
+
+	jobDesc := tree.AsStringWithFQNames(n.n, params.Ann())
+	for i, fnDesc := range n.toDrop {
+		// Triggers are the only objects that can depend on a function, and
+		// they were checked above.
+		if err := params.p.dropFunctionImpl(params.ctx, fnDesc, descpb.InvalidID, jobDesc); err != nil {
+			return err
+		}
+		// Log a Drop Function event.
+		if err := MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+			params.ctx,
+			params.p.txn,
+			EventLogDropFunction,
+			int32(fnDesc.ID),
+			int32(params.extendedEvalCtx.NodeID.SQLInstanceID()),
+			struct {
+				FunctionName string
+				Statement    string
+				User         string
+			}{n.names[i].FQString(), jobDesc, params.SessionData().User},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropFunctionImpl marks the given function as dropped, drops the triggers
+// that execute it and removes the back-references to it from the relations it
+// depends on, except for the relation with ID skipID, which is being dropped by
+// the caller. The descriptor itself is deleted by the schema change job.
+func (p *planner) dropFunctionImpl(
+	ctx context.Context, fnDesc *funcdesc.Mutable, skipID descpb.ID, jobDesc string,
+) error {
+	if fnDesc.Dropped() {
+		return errors.Errorf("function %q is already being dropped", fnDesc.Name)
+	}
+	if err := p.dropTriggersDependingOn(ctx, fnDesc, skipID, jobDesc); err != nil {
+		return err
+	}
+	if err := p.removeFunctionBackReferences(ctx, fnDesc, skipID, jobDesc); err != nil {
+		return err
+	}
+
+	// Add a draining name.
+	fnDesc.DrainingNames = append(fnDesc.DrainingNames, descpb.NameInfo{
+		ParentID:       fnDesc.ParentID,
+		ParentSchemaID: fnDesc.ParentSchemaID,
+		Name:           fnDesc.Name,
+	})
+	fnDesc.SetDropped()
+	return p.writeFunctionDescChange(ctx, fnDesc, jobDesc)
+}
+
+func (n *dropFunctionNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *dropFunctionNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *dropFunctionNode) Close(ctx context.Context)           {}
+func (n *dropFunctionNode) ReadingOwnWrites()                   {}
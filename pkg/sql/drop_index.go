@@ -255,7 +255,7 @@ func (p *planner) dropIndexByName(
 		return nil
 	}
 
-	if idx.Unique && behavior != tree.DropCascade && constraintBehavior != ignoreIdxConstraint && !idx.CreatedExplicitly {
+	if (idx.Unique || idx.DeferrableUnique) && behavior != tree.DropCascade && constraintBehavior != ignoreIdxConstraint && !idx.CreatedExplicitly {
 		return errors.WithHint(
 			pgerror.Newf(pgcode.DependentObjectsStillExist,
 				"index %q is in use as unique constraint", idx.Name),
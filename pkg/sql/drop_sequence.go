@@ -47,6 +47,9 @@ func (p *planner) DropSequence(ctx context.Context, n *tree.DropSequence) (planN
 		if depErr := p.sequenceDependencyError(ctx, droppedDesc); depErr != nil {
 			return nil, depErr
 		}
+		if err := p.canDropRelationWithFunctions(ctx, droppedDesc, "drop", n.DropBehavior); err != nil {
+			return nil, err
+		}
 
 		td = append(td, toDelete{tn, droppedDesc})
 	}
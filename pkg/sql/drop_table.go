@@ -91,6 +91,9 @@ func (p *planner) DropTable(ctx context.Context, n *tree.DropTable) (planNode, e
 		if err := p.canRemoveAllTableOwnedSequences(ctx, droppedDesc, n.DropBehavior); err != nil {
 			return nil, err
 		}
+		if err := p.canDropRelationWithFunctions(ctx, droppedDesc, "drop", n.DropBehavior); err != nil {
+			return nil, err
+		}
 
 	}
 
@@ -391,6 +394,15 @@ func (p *planner) initiateDropTable(
 		return errors.Errorf("table %q is already being dropped", tableDesc.Name)
 	}
 
+	// Drop all the functions that depend on this relation, assuming that we
+	// wouldn't have made it to this point if `cascade` wasn't enabled.
+	if err := p.dropFunctionsDependingOn(ctx, tableDesc, jobDesc); err != nil {
+		return err
+	}
+	if err := p.removeTriggerFunctionBackReferences(ctx, tableDesc, jobDesc); err != nil {
+		return err
+	}
+
 	// If the table is not interleaved , use the delayed GC mechanism to
 	// schedule usage of the more efficient ClearRange pathway. ClearRange will
 	// only work if the entire hierarchy of interleaved tables are dropped at
@@ -0,0 +1,123 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+)
+
+type dropTriggerNode struct {
+	n         *tree.DropTrigger
+	tableDesc *tabledesc.Mutable
+	tableName *tree.TableName
+}
+
+// DropTrigger drops a trigger from a table.
+// Privileges: CREATE on table.
+func (p *planner) DropTrigger(ctx context.Context, n *tree.DropTrigger) (planNode, error) {
+	tableDesc, err := p.ResolveMutableTableDescriptorEx(
+		ctx, n.Table, !n.IfExists, tree.ResolveRequireTableDesc,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if tableDesc == nil {
+		return newZeroNode(nil /* columns */), nil
+	}
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+	tableName := n.Table.ToTableName()
+	return &dropTriggerNode{n: n, tableDesc: tableDesc, tableName: &tableName}, nil
+}
+
+// ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
+// This is because DROP TRIGGER performs multiple KV operations on descriptors
+// and expects to see its own writes.
+func (n *dropTriggerNode) ReadingOwnWrites() {}
+
+func (n *dropTriggerNode) startExec(params runParams) error {
+	p := params.p
+	telemetry.Inc(sqltelemetry.SchemaChangeDropCounter("trigger"))
+
+	idx := -1
+	for i := range n.tableDesc.Triggers {
+		if n.tableDesc.Triggers[i].Name == string(n.n.Name) {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		if n.n.IfExists {
+			return nil
+		}
+		return pgerror.Newf(pgcode.UndefinedObject,
+			"trigger %q for table %q does not exist", n.n.Name, n.tableDesc.Name)
+	}
+	fnID := n.tableDesc.Triggers[idx].FunctionID
+	n.tableDesc.Triggers = append(n.tableDesc.Triggers[:idx], n.tableDesc.Triggers[idx+1:]...)
+
+	jobDesc := tree.AsStringWithFQNames(n.n, params.Ann())
+	if err := p.writeSchemaChange(
+		params.ctx, n.tableDesc, descpb.InvalidMutationID, jobDesc,
+	); err != nil {
+		return err
+	}
+
+	// Remove the back-reference from the function, unless another trigger on
+	// the table still executes it.
+	stillUsed := false
+	for i := range n.tableDesc.Triggers {
+		if n.tableDesc.Triggers[i].FunctionID == fnID {
+			stillUsed = true
+			break
+		}
+	}
+	if !stillUsed {
+		fnDesc, err := p.Descriptors().GetMutableFunctionVersionByID(params.ctx, p.txn, fnID)
+		if err != nil {
+			return err
+		}
+		fnDesc.DependedOnByTriggers = removeMatchingIDs(fnDesc.DependedOnByTriggers, n.tableDesc.ID)
+		if err := p.writeFunctionDescChange(params.ctx, fnDesc, jobDesc); err != nil {
+			return err
+		}
+	}
+
+	// Log Drop Trigger event. This is an auditable log event and is recorded
+	// in the same transaction as the table descriptor update.
+	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+		params.ctx,
+		p.txn,
+		EventLogDropTrigger,
+		int32(n.tableDesc.ID),
+		int32(params.extendedEvalCtx.NodeID.SQLInstanceID()),
+		struct {
+			TriggerName string
+			TableName   string
+			Statement   string
+			User        string
+		}{n.n.Name.String(), n.tableName.FQString(), jobDesc, params.SessionData().User},
+	)
+}
+
+func (*dropTriggerNode) Next(runParams) (bool, error) { return false, nil }
+func (*dropTriggerNode) Values() tree.Datums          { return tree.Datums{} }
+func (*dropTriggerNode) Close(context.Context)        {}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
@@ -56,6 +57,9 @@ func (p *planner) DropType(ctx context.Context, n *tree.DropType) (planNode, err
 		if _, ok := node.td[typeDesc.ID]; ok {
 			continue
 		}
+		if n.IsDomain && typeDesc.Kind != descpb.TypeDescriptor_DOMAIN {
+			return nil, pgerror.Newf(pgcode.WrongObjectType, "%q is not a domain", name)
+		}
 		switch typeDesc.Kind {
 		case descpb.TypeDescriptor_ALIAS:
 			// The implicit array types are not directly droppable.
@@ -66,6 +70,10 @@ func (p *planner) DropType(ctx context.Context, n *tree.DropType) (planNode, err
 			)
 		case descpb.TypeDescriptor_ENUM:
 			sqltelemetry.IncrementEnumCounter(sqltelemetry.EnumDrop)
+		case descpb.TypeDescriptor_DOMAIN:
+			sqltelemetry.IncrementDomainCounter(sqltelemetry.DomainDrop)
+		case descpb.TypeDescriptor_COMPOSITE:
+			sqltelemetry.IncrementCompositeTypeCounter(sqltelemetry.CompositeTypeDrop)
 		}
 
 		// Check if we can drop the type.
@@ -99,11 +107,21 @@ func (p *planner) canDropTypeDesc(
 	if len(desc.ReferencingDescriptorIDs) > 0 && behavior != tree.DropCascade {
 		var dependentNames []string
 		for _, id := range desc.ReferencingDescriptorIDs {
-			desc, err := p.Descriptors().GetMutableTableVersionByID(ctx, id, p.txn)
+			refDesc, err := p.Descriptors().GetMutableDescriptorByID(ctx, id, p.txn)
 			if err != nil {
 				return errors.Wrapf(err, "type has dependent objects")
 			}
-			fqName, err := p.getQualifiedTableName(ctx, desc)
+			var fqName *tree.TableName
+			switch refDesc := refDesc.(type) {
+			case *tabledesc.Mutable:
+				fqName, err = p.getQualifiedTableName(ctx, refDesc)
+			case *funcdesc.Mutable:
+				// Functions can depend on the composite type they return.
+				fqName, err = p.getQualifiedFunctionName(ctx, refDesc)
+			default:
+				return errors.AssertionFailedf(
+					"unexpected descriptor %d referencing type %q", id, desc.Name)
+			}
 			if err != nil {
 				return errors.Wrapf(err, "type %q has dependent objects", desc.Name)
 			}
@@ -68,6 +68,9 @@ func (p *planner) DropView(ctx context.Context, n *tree.DropView) (planNode, err
 				return nil, err
 			}
 		}
+		if err := p.canDropRelationWithFunctions(ctx, droppedDesc, "drop", n.DropBehavior); err != nil {
+			return nil, err
+		}
 	}
 
 	if len(td) == 0 {
@@ -26,6 +26,11 @@ type errorIfRowsNode struct {
 	// produced.
 	mkErr exec.MkErrFn
 
+	// deferrable is set if the node checks a deferrable constraint. While the
+	// constraint is deferred, the violations are recorded in the transaction
+	// instead of returning an error, and are checked again at commit.
+	deferrable *exec.DeferrableConstraint
+
 	nexted bool
 }
 
@@ -44,6 +49,15 @@ func (n *errorIfRowsNode) Next(params runParams) (bool, error) {
 		return false, err
 	}
 	if ok {
+		if dc := params.p.deferredConstraints; n.deferrable != nil && dc != nil && dc.isDeferred(n.deferrable) {
+			for ok {
+				dc.addPending(n.deferrable, n.plan.Values())
+				if ok, err = n.plan.Next(params); err != nil {
+					return false, err
+				}
+			}
+			return false, nil
+		}
 		return false, n.mkErr(n.plan.Values())
 	}
 	return false, nil
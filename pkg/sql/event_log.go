@@ -86,6 +86,16 @@ const (
 	// EventAlterType is recorded when a type is altered.
 	EventLogAlterType EventLogType = "alter_type"
 
+	// EventLogCreateFunction is recorded when a function is created.
+	EventLogCreateFunction EventLogType = "create_function"
+	// EventLogDropFunction is recorded when a function is dropped.
+	EventLogDropFunction EventLogType = "drop_function"
+
+	// EventLogCreateTrigger is recorded when a trigger is created.
+	EventLogCreateTrigger EventLogType = "create_trigger"
+	// EventLogDropTrigger is recorded when a trigger is dropped.
+	EventLogDropTrigger EventLogType = "drop_trigger"
+
 	// EventLogNodeJoin is recorded when a node joins the cluster.
 	EventLogNodeJoin EventLogType = "node_join"
 	// EventLogNodeRestart is recorded when an existing node rejoins the cluster
@@ -21,6 +21,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cockroachdb/apd/v2"
@@ -1271,6 +1272,9 @@ type queryMeta struct {
 	hidden bool
 
 	progressAtomic uint64
+
+	// resourceUsage is the resources consumed by the query so far.
+	resourceUsage queryResourceUsage
 }
 
 // cancel cancels the query associated with this queryMeta, by closing the associated
@@ -1289,6 +1293,41 @@ func (q *queryMeta) getStatement() (tree.Statement, error) {
 	return parsed.AST, nil
 }
 
+// queryResourceUsage exposes the resources consumed so far by a running query
+// to SHOW QUERIES. It is updated by the DistSQLReceiver as the flows of the
+// query report their metrics, so its fields are accessed atomically.
+type queryResourceUsage struct {
+	maxMemUsage  int64
+	maxDiskUsage int64
+	networkBytes int64
+	kvTime       int64
+	cpuTime      int64
+	// cpuTimeMeasured is 1 if the query measures its CPU time.
+	cpuTimeMeasured int32
+}
+
+// update stores the given stats.
+func (u *queryResourceUsage) update(stats *topLevelQueryStats) {
+	atomic.StoreInt64(&u.maxMemUsage, stats.maxMemUsage)
+	atomic.StoreInt64(&u.maxDiskUsage, stats.maxDiskUsage)
+	atomic.StoreInt64(&u.networkBytes, stats.networkBytes)
+	atomic.StoreInt64(&u.kvTime, int64(stats.kvTime))
+	atomic.StoreInt64(&u.cpuTime, int64(stats.cpuTime))
+	if stats.cpuTimeMeasured {
+		atomic.StoreInt32(&u.cpuTimeMeasured, 1)
+	}
+}
+
+// addToActiveQuery fills in the resource usage fields of the given query.
+func (u *queryResourceUsage) addToActiveQuery(q *serverpb.ActiveQuery) {
+	q.MaxMemUsage = atomic.LoadInt64(&u.maxMemUsage)
+	q.MaxDiskUsage = atomic.LoadInt64(&u.maxDiskUsage)
+	q.NetworkBytes = atomic.LoadInt64(&u.networkBytes)
+	q.KVTime = atomic.LoadInt64(&u.kvTime)
+	q.CPUTime = atomic.LoadInt64(&u.cpuTime)
+	q.CPUTimeMeasured = atomic.LoadInt32(&u.cpuTimeMeasured) == 1
+}
+
 // SessionDefaults mirrors fields in Session, for restoring default
 // configuration values in SET ... TO DEFAULT (or RESET ...) statements.
 type SessionDefaults map[string]string
@@ -1301,6 +1340,9 @@ type SessionArgs struct {
 	// client.
 	RemoteAddr            net.Addr
 	ConnResultsBufferSize int64
+	// TraceParent is the W3C Trace Context traceparent value provided by the
+	// client, if any. The transactions of the session are part of that trace.
+	TraceParent string
 }
 
 // SessionRegistry stores a set of all sessions on this node.
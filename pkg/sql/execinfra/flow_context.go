@@ -13,6 +13,9 @@
 package execinfra
 
 import (
+	"runtime"
+	"time"
+
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
@@ -20,7 +23,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/sysutil"
 )
 
 // FlowCtx encompasses the configuration parameters needed for various flow
@@ -70,6 +75,17 @@ type FlowCtx struct {
 	// on remote nodes with a new descs.Collection. After the flow is complete,
 	// all descriptors leased from the factory must be released.
 	TypeResolverFactory *descs.DistSQLTypeResolverFactory
+
+	// DiskMonitor is used to monitor the temporary storage usage of this flow.
+	// It is a child of Cfg.DiskMonitor opened (and closed in Flow.Cleanup)
+	// together with the flow's memory monitor in EvalCtx.Mon. It can be nil for
+	// flows not set up by the DistSQL server, in which case processors use
+	// Cfg.DiskMonitor directly and the flow doesn't report its resource usage.
+	DiskMonitor *mon.BytesMonitor
+
+	// MeasureCPUTime is true if the goroutines of this flow report the CPU time
+	// they consume (see StartCPUTimer).
+	MeasureCPUTime bool
 }
 
 // NewEvalCtx returns a modifiable copy of the FlowCtx's EvalContext.
@@ -98,3 +114,87 @@ func (ctx *FlowCtx) Stopper() *stop.Stopper {
 func (ctx *FlowCtx) Codec() keys.SQLCodec {
 	return ctx.EvalCtx.Codec
 }
+
+// ParentDiskMonitor returns the monitor that processors should use as the
+// parent of their temporary storage monitors.
+func (ctx *FlowCtx) ParentDiskMonitor() *mon.BytesMonitor {
+	if ctx.DiskMonitor != nil {
+		return ctx.DiskMonitor
+	}
+	return ctx.Cfg.DiskMonitor
+}
+
+// GetFlowStatsMeta returns a metadata object reporting the resources consumed
+// by this flow: the peak memory and disk usage so far, the given number of
+// bytes sent over the network and the given CPU time. The peak usages are
+// aggregated with max by the receiver, so they can be reported by each output
+// of the flow. nil is returned if the flow doesn't track its resource usage.
+func (ctx *FlowCtx) GetFlowStatsMeta(
+	networkBytes int64, cpuTime time.Duration,
+) *execinfrapb.ProducerMetadata {
+	if ctx.DiskMonitor == nil {
+		return nil
+	}
+	meta := execinfrapb.GetProducerMeta()
+	meta.ComponentStats = &execinfrapb.ComponentStats{
+		MaxMemUsage:  ctx.EvalCtx.Mon.MaximumBytes(),
+		MaxDiskUsage: ctx.DiskMonitor.MaximumBytes(),
+		NetworkBytes: networkBytes,
+		CPUTime:      cpuTime.Nanoseconds(),
+	}
+	return meta
+}
+
+// CPUTimer measures the CPU time consumed by a goroutine of a flow. The zero
+// value doesn't measure anything.
+type CPUTimer struct {
+	running bool
+	start   time.Duration
+}
+
+// StartCPUTimer starts measuring the CPU time consumed by the calling
+// goroutine if the flow measures its CPU time. In that case, the goroutine is
+// locked to its OS thread until the timer is stopped, and the timer must be
+// stopped by the same goroutine.
+func (ctx *FlowCtx) StartCPUTimer() CPUTimer {
+	if !ctx.MeasureCPUTime {
+		return CPUTimer{}
+	}
+	runtime.LockOSThread()
+	start, ok := sysutil.ThreadCPUTime()
+	if !ok {
+		runtime.UnlockOSThread()
+		return CPUTimer{}
+	}
+	return CPUTimer{running: true, start: start}
+}
+
+// Running returns whether the timer is measuring the CPU time of its
+// goroutine.
+func (t *CPUTimer) Running() bool {
+	return t.running
+}
+
+// Elapsed returns the CPU time consumed by the goroutine since the timer was
+// started, or zero if the timer isn't running. It must be called by the
+// goroutine that started the timer.
+func (t *CPUTimer) Elapsed() time.Duration {
+	if !t.running {
+		return 0
+	}
+	cur, _ := sysutil.ThreadCPUTime()
+	return cur - t.start
+}
+
+// Stop stops the timer, unlocking the goroutine from its OS thread, and
+// returns the CPU time it consumed since the timer was started. It must be
+// called by the goroutine that started the timer.
+func (t *CPUTimer) Stop() time.Duration {
+	if !t.running {
+		return 0
+	}
+	elapsed := t.Elapsed()
+	t.running = false
+	runtime.UnlockOSThread()
+	return elapsed
+}
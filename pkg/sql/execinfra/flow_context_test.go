@@ -0,0 +1,89 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package execinfra
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/skip"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/sysutil"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFlowStatsMeta(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	evalCtx := tree.MakeTestingEvalContext(st)
+	defer evalCtx.Stop(ctx)
+	diskMonitor := NewTestDiskMonitor(ctx, st)
+	defer diskMonitor.Stop(ctx)
+
+	flowCtx := &FlowCtx{
+		EvalCtx: &evalCtx,
+		Cfg:     &ServerConfig{Settings: st, DiskMonitor: diskMonitor},
+	}
+	// Flows without their own disk monitor don't report their usage.
+	require.Equal(t, diskMonitor, flowCtx.ParentDiskMonitor())
+	require.Nil(t, flowCtx.GetFlowStatsMeta(0 /* networkBytes */, 0 /* cpuTime */))
+
+	flowCtx.DiskMonitor = NewMonitor(ctx, diskMonitor, "flow-disk")
+	defer flowCtx.DiskMonitor.Stop(ctx)
+	require.Equal(t, flowCtx.DiskMonitor, flowCtx.ParentDiskMonitor())
+
+	memAcc := evalCtx.Mon.MakeBoundAccount()
+	require.NoError(t, memAcc.Grow(ctx, 100))
+	memAcc.Close(ctx)
+	diskAcc := flowCtx.DiskMonitor.MakeBoundAccount()
+	require.NoError(t, diskAcc.Grow(ctx, 200))
+	diskAcc.Close(ctx)
+
+	meta := flowCtx.GetFlowStatsMeta(300 /* networkBytes */, 400 /* cpuTime */)
+	require.NotNil(t, meta)
+	require.GreaterOrEqual(t, meta.ComponentStats.MaxMemUsage, int64(100))
+	require.GreaterOrEqual(t, meta.ComponentStats.MaxDiskUsage, int64(200))
+	require.Equal(t, int64(300), meta.ComponentStats.NetworkBytes)
+	require.Equal(t, int64(400), meta.ComponentStats.CPUTime)
+}
+
+func TestCPUTimer(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	flowCtx := &FlowCtx{}
+	timer := flowCtx.StartCPUTimer()
+	require.False(t, timer.Running())
+	require.Zero(t, timer.Stop())
+
+	flowCtx.MeasureCPUTime = true
+	timer = flowCtx.StartCPUTimer()
+	if _, ok := sysutil.ThreadCPUTime(); !ok {
+		require.False(t, timer.Running())
+		skip.IgnoreLint(t, "thread CPU time is not supported on this platform")
+	}
+	require.True(t, timer.Running())
+	// Spin until the goroutine has consumed some CPU time, which is reported
+	// with the granularity of the scheduler tick.
+	testutils.SucceedsSoon(t, func() error {
+		if timer.Elapsed() == 0 {
+			return errors.New("no CPU time consumed yet")
+		}
+		return nil
+	})
+	require.Greater(t, int64(timer.Stop()), int64(0))
+	require.False(t, timer.Running())
+}
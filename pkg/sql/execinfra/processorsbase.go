@@ -771,8 +771,31 @@ func (pb *ProcessorBase) Run(ctx context.Context) {
 	if pb.Out.output == nil {
 		panic("processor output not initialized for emitting rows")
 	}
+	output := pb.Out.output
+	// The CPU time consumed by the processor's goroutine includes the time
+	// spent by the inputs the processor pulls from.
+	if timer := pb.FlowCtx.StartCPUTimer(); timer.Running() {
+		output = &cpuTimeReceiver{RowReceiver: output, timer: timer}
+	}
 	ctx = pb.self.Start(ctx)
-	Run(ctx, pb.self, pb.Out.output)
+	Run(ctx, pb.self, output)
+}
+
+// cpuTimeReceiver is a RowReceiver that reports the CPU time consumed by the
+// goroutine of its producer in a ComponentStats metadata before the producer
+// finishes.
+type cpuTimeReceiver struct {
+	RowReceiver
+	timer CPUTimer
+}
+
+// ProducerDone is part of the RowReceiver interface.
+func (r *cpuTimeReceiver) ProducerDone() {
+	cpuTime := r.timer.Stop()
+	r.RowReceiver.Push(nil /* row */, &execinfrapb.ProducerMetadata{
+		ComponentStats: &execinfrapb.ComponentStats{CPUTime: cpuTime.Nanoseconds()},
+	})
+	r.RowReceiver.ProducerDone()
 }
 
 // ProcStateOpts contains fields used by the ProcessorBase's family of functions
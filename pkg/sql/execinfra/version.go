@@ -39,7 +39,7 @@ import "github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 //
 // ATTENTION: When updating these fields, add a brief description of what
 // changed to the version history below.
-const Version execinfrapb.DistSQLVersion = 38
+const Version execinfrapb.DistSQLVersion = 39
 
 // MinAcceptedVersion is the oldest version that the server is compatible with.
 // A server will not accept flows with older versions.
@@ -51,6 +51,11 @@ const MinAcceptedVersion execinfrapb.DistSQLVersion = 37
 
 Please add new entries at the top.
 
+- Version: 39 (MinAcceptedVersion: 37)
+  - Added a nulls_reversed field to Ordering.Column for orderings with a
+    non-default NULL order (e.g. ASC NULLS LAST). The change is backwards
+    compatible (mixed versions will prevent parallelization).
+
 - Version: 38 (MinAcceptedVersion: 38)
   - A paired joiner approach for inverted joins was added, for left
     outer/semi/anti joins involving the invertedJoiner and joinReader.
@@ -34,6 +34,7 @@ func ConvertToColumnOrdering(specOrdering Ordering) colinfo.ColumnOrdering {
 		} else {
 			ordering[i].Direction = encoding.Descending
 		}
+		ordering[i].NullsReversed = c.NullsReversed
 	}
 	return ordering
 }
@@ -66,6 +67,7 @@ func ConvertToMappedSpecOrdering(
 		} else {
 			specOrdering.Columns[i].Direction = Ordering_Column_DESC
 		}
+		specOrdering.Columns[i].NullsReversed = c.NullsReversed
 	}
 	return specOrdering
 }
@@ -196,6 +198,9 @@ type ProducerMetadata struct {
 	BulkProcessorProgress *RemoteProducerMetadata_BulkProcessorProgress
 	// Metrics contains information about goodput of the node.
 	Metrics *RemoteProducerMetadata_Metrics
+	// ComponentStats contains the resources consumed by a component of the
+	// flow.
+	ComponentStats *ComponentStats
 }
 
 var (
@@ -264,6 +269,8 @@ func RemoteProducerMetaToLocalMeta(
 		meta.Err = v.Error.ErrorDetail(ctx)
 	case *RemoteProducerMetadata_Metrics_:
 		meta.Metrics = v.Metrics
+	case *RemoteProducerMetadata_ComponentStats:
+		meta.ComponentStats = v.ComponentStats
 	default:
 		return *meta, false
 	}
@@ -308,6 +315,10 @@ func LocalMetaToRemoteProducerMeta(
 		rpm.Value = &RemoteProducerMetadata_Metrics_{
 			Metrics: meta.Metrics,
 		}
+	} else if meta.ComponentStats != nil {
+		rpm.Value = &RemoteProducerMetadata_ComponentStats{
+			ComponentStats: meta.ComponentStats,
+		}
 	} else {
 		rpm.Value = &RemoteProducerMetadata_Error{
 			Error: NewError(ctx, meta.Err),
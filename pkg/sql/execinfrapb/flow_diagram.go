@@ -450,6 +450,16 @@ func (s *CSVWriterSpec) summary() (string, []string) {
 	return "CSVWriter", []string{s.Destination}
 }
 
+// summary implements the diagramCellType interface.
+func (s *ParquetWriterSpec) summary() (string, []string) {
+	return "ParquetWriter", []string{s.Destination}
+}
+
+// summary implements the diagramCellType interface.
+func (s *AvroWriterSpec) summary() (string, []string) {
+	return "AvroWriter", []string{s.Destination}
+}
+
 // summary implements the diagramCellType interface.
 func (s *BulkRowWriterSpec) summary() (string, []string) {
 	return "BulkRowWriterSpec", []string{}
@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/errors"
 )
 
@@ -37,11 +38,57 @@ type exportNode struct {
 	// fileNamePattern represents the file naming pattern for the
 	// export, typically to be appended to the destination URI
 	fileNamePattern string
+	// format is the format of the exported files.
+	format          exportFormat
 	csvOpts         roachpb.CSVOptions
 	chunkRows       int
+	chunkSize       int64
 	fileCompression execinfrapb.FileCompression
 }
 
+// exportFormat is a file format supported by EXPORT.
+type exportFormat int
+
+const (
+	exportFormatCSV exportFormat = iota
+	exportFormatParquet
+	exportFormatAvro
+)
+
+// exportFormats maps the names of the supported formats, as they appear in
+// EXPORT INTO <format>, to their description.
+var exportFormats = map[string]struct {
+	format exportFormat
+	// fileSuffix is the suffix of the names of the exported files.
+	fileSuffix string
+	// codecs are the compression codecs supported by the format, by name.
+	codecs map[string]execinfrapb.FileCompression
+}{
+	"CSV": {
+		format:     exportFormatCSV,
+		fileSuffix: ".csv",
+		codecs: map[string]execinfrapb.FileCompression{
+			"gzip": execinfrapb.FileCompression_Gzip,
+		},
+	},
+	"PARQUET": {
+		format:     exportFormatParquet,
+		fileSuffix: ".parquet",
+		codecs: map[string]execinfrapb.FileCompression{
+			"gzip":   execinfrapb.FileCompression_Gzip,
+			"snappy": execinfrapb.FileCompression_Snappy,
+		},
+	},
+	"AVRO": {
+		format:     exportFormatAvro,
+		fileSuffix: ".avro",
+		codecs: map[string]execinfrapb.FileCompression{
+			"deflate": execinfrapb.FileCompression_Deflate,
+			"snappy":  execinfrapb.FileCompression_Snappy,
+		},
+	},
+}
+
 func (e *exportNode) startExec(params runParams) error {
 	panic("exportNode cannot be run in local mode")
 }
@@ -62,12 +109,14 @@ const (
 	exportOptionDelimiter   = "delimiter"
 	exportOptionNullAs      = "nullas"
 	exportOptionChunkRows   = "chunk_rows"
+	exportOptionChunkSize   = "chunk_size"
 	exportOptionFileName    = "filename"
 	exportOptionCompression = "compression"
 )
 
 var exportOptionExpectValues = map[string]KVStringOptValidate{
 	exportOptionChunkRows:   KVStringOptRequireValue,
+	exportOptionChunkSize:   KVStringOptRequireValue,
 	exportOptionDelimiter:   KVStringOptRequireValue,
 	exportOptionFileName:    KVStringOptRequireValue,
 	exportOptionNullAs:      KVStringOptRequireValue,
@@ -76,8 +125,6 @@ var exportOptionExpectValues = map[string]KVStringOptValidate{
 
 const exportChunkRowsDefault = 100000
 const exportFilePatternPart = "%part%"
-const exportFilePatternDefault = exportFilePatternPart + ".csv"
-const exportCompressionCodec = "gzip"
 
 // ConstructExport is part of the exec.Factory interface.
 func (ef *execFactory) ConstructExport(
@@ -87,7 +134,8 @@ func (ef *execFactory) ConstructExport(
 		return nil, errors.Errorf("EXPORT cannot be used inside a transaction")
 	}
 
-	if fileFormat != "CSV" {
+	format, ok := exportFormats[fileFormat]
+	if !ok {
 		return nil, errors.Errorf("unsupported export format: %q", fileFormat)
 	}
 
@@ -106,6 +154,15 @@ func (ef *execFactory) ConstructExport(
 		return nil, err
 	}
 
+	if format.format != exportFormatCSV {
+		for _, opt := range []string{exportOptionDelimiter, exportOptionNullAs} {
+			if _, ok := optVals[opt]; ok {
+				return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+					"%s option is only supported for CSV exports", opt)
+			}
+		}
+	}
+
 	csvOpts := roachpb.CSVOptions{}
 
 	if override, ok := optVals[exportOptionDelimiter]; ok {
@@ -130,27 +187,38 @@ func (ef *execFactory) ConstructExport(
 		}
 	}
 
+	var chunkSize int64
+	if override, ok := optVals[exportOptionChunkSize]; ok {
+		chunkSize, err = humanizeutil.ParseBytes(override)
+		if err != nil {
+			return nil, pgerror.WithCandidateCode(err, pgcode.InvalidParameterValue)
+		}
+		if chunkSize < 1 {
+			return nil, pgerror.New(pgcode.InvalidParameterValue, "invalid chunk size")
+		}
+	}
+
 	// Check whenever compression is expected and extract compression codec name in case
 	// of positive result
 	var codec execinfrapb.FileCompression
 	if name, ok := optVals[exportOptionCompression]; ok && len(name) != 0 {
-		if strings.EqualFold(name, exportCompressionCodec) {
-			codec = execinfrapb.FileCompression_Gzip
-		} else {
+		if codec, ok = format.codecs[strings.ToLower(name)]; !ok {
 			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
 				"unsupported compression codec %s", name)
 		}
 	}
 
 	exportID := ef.planner.stmt.queryID.String()
-	namePattern := fmt.Sprintf("export%s-%s", exportID, exportFilePatternDefault)
+	namePattern := fmt.Sprintf("export%s-%s%s", exportID, exportFilePatternPart, format.fileSuffix)
 
 	return &exportNode{
 		source:          input.(planNode),
 		destination:     string(*destination),
 		fileNamePattern: namePattern,
+		format:          format.format,
 		csvOpts:         csvOpts,
 		chunkRows:       chunkRows,
+		chunkSize:       chunkSize,
 		fileCompression: codec,
 	}, nil
 }
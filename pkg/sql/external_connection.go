@@ -0,0 +1,556 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"net/url"
+	"path"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+)
+
+// ExternalConnectionScheme is the URI scheme used to reference a named
+// external connection, e.g. external://backups/daily. The host names the
+// connection and the path is appended to the path of the connection's URI.
+const ExternalConnectionScheme = "external"
+
+// externalConnectionKey returns the key used to encrypt the URIs stored in
+// system.external_connections, generating it on first use. The key is kept in
+// the system keyspace rather than in a SQL table so that it cannot be read
+// through SQL, not even by users who can read the encrypted URIs.
+func externalConnectionKey(ctx context.Context, db *kv.DB) ([]byte, error) {
+	existing, err := db.Get(ctx, keys.ExternalConnectionEncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Exists() {
+		return existing.ValueBytes(), nil
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := db.CPut(ctx, keys.ExternalConnectionEncryptionKey, key, nil /* expValue */); err != nil {
+		// Another node generated the key concurrently; use theirs.
+		var condErr *roachpb.ConditionFailedError
+		if errors.As(err, &condErr) && condErr.ActualValue != nil {
+			return condErr.ActualValue.GetBytes()
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+func externalConnectionAEAD(ctx context.Context, db *kv.DB) (cipher.AEAD, error) {
+	key, err := externalConnectionKey(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptExternalConnectionURI seals uri with a random nonce, which is
+// prepended to the returned ciphertext.
+func encryptExternalConnectionURI(ctx context.Context, db *kv.DB, uri string) ([]byte, error) {
+	aead, err := externalConnectionAEAD(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, []byte(uri), nil), nil
+}
+
+func decryptExternalConnectionURI(ctx context.Context, db *kv.DB, details []byte) (string, error) {
+	aead, err := externalConnectionAEAD(ctx, db)
+	if err != nil {
+		return "", err
+	}
+	if len(details) < aead.NonceSize() {
+		return "", errors.New("external connection details are corrupt")
+	}
+	nonce, ciphertext := details[:aead.NonceSize()], details[aead.NonceSize():]
+	uri, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "decrypting external connection details")
+	}
+	return string(uri), nil
+}
+
+// validateExternalConnectionURI checks that uri can be stored as the target
+// of an external connection.
+func validateExternalConnectionURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return pgerror.Wrap(err, pgcode.InvalidParameterValue, "invalid external connection URI")
+	}
+	if u.Scheme == "" {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"external connection URI %q must specify a scheme", redactExternalConnectionURI(uri))
+	}
+	if u.Scheme == ExternalConnectionScheme {
+		return pgerror.New(pgcode.InvalidParameterValue,
+			"an external connection cannot reference another external connection")
+	}
+	return nil
+}
+
+// redactExternalConnectionURI strips the password and the values of all
+// query parameters from uri, since these commonly carry credentials.
+func redactExternalConnectionURI(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "<unparseable>"
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "redacted")
+	}
+	params := u.Query()
+	for param := range params {
+		params.Set(param, "redacted")
+	}
+	u.RawQuery = params.Encode()
+	return u.String()
+}
+
+func errExternalConnectionNotFound(name string) error {
+	return pgerror.Newf(pgcode.UndefinedObject, "external connection %q does not exist", name)
+}
+
+// loadExternalConnection reads the encrypted details and privileges of the
+// named connection. It returns a nil descriptor if there is no such
+// connection.
+func loadExternalConnection(
+	ctx context.Context, ie *InternalExecutor, txn *kv.Txn, name string,
+) ([]byte, *descpb.PrivilegeDescriptor, error) {
+	row, err := ie.QueryRowEx(
+		ctx, "load-external-connection", txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUser},
+		`SELECT connection_details, privileges FROM system.external_connections
+		 WHERE connection_name = $1`,
+		name,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	if row == nil {
+		return nil, nil, nil
+	}
+	privs := &descpb.PrivilegeDescriptor{}
+	if err := protoutil.Unmarshal([]byte(tree.MustBeDBytes(row[1])), privs); err != nil {
+		return nil, nil, err
+	}
+	return []byte(tree.MustBeDBytes(row[0])), privs, nil
+}
+
+func writeExternalConnectionPrivileges(
+	ctx context.Context,
+	ie *InternalExecutor,
+	txn *kv.Txn,
+	name string,
+	privs *descpb.PrivilegeDescriptor,
+) error {
+	encoded, err := protoutil.Marshal(privs)
+	if err != nil {
+		return err
+	}
+	_, err = ie.ExecEx(
+		ctx, "update-external-connection-privileges", txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUser},
+		`UPDATE system.external_connections SET privileges = $2, updated = now()
+		 WHERE connection_name = $1`,
+		name, tree.NewDBytes(tree.DBytes(encoded)),
+	)
+	return err
+}
+
+// hasExternalConnectionPrivilege returns true if user, or any of the roles in
+// memberOf, owns the connection or holds priv on it. Privileges granted to
+// the public role apply to everyone.
+func hasExternalConnectionPrivilege(
+	privs *descpb.PrivilegeDescriptor, user string, memberOf map[string]bool, priv privilege.Kind,
+) bool {
+	if user == security.RootUser || user == security.NodeUser {
+		return true
+	}
+	if privs.Owner == user || privs.CheckPrivilege(user, priv) ||
+		privs.CheckPrivilege(security.PublicRole, priv) {
+		return true
+	}
+	for role := range memberOf {
+		if privs.Owner == role || privs.CheckPrivilege(role, priv) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkExternalConnectionPrivilege returns an error if the current user does
+// not hold priv on the external connection.
+func (p *planner) checkExternalConnectionPrivilege(
+	ctx context.Context, name string, privs *descpb.PrivilegeDescriptor, priv privilege.Kind,
+) error {
+	memberOf, err := p.MemberOfWithAdminOption(ctx, p.User())
+	if err != nil {
+		return err
+	}
+	if !hasExternalConnectionPrivilege(privs, p.User(), memberOf, priv) {
+		return pgerror.Newf(pgcode.InsufficientPrivilege,
+			"user %s does not have %s privilege on external connection %s",
+			p.User(), priv, name)
+	}
+	return nil
+}
+
+// ResolveExternalConnection returns the URI stored for the named external
+// connection, after checking that user holds the USAGE privilege on it.
+func ResolveExternalConnection(
+	ctx context.Context, ie *InternalExecutor, db *kv.DB, user, name string,
+) (string, error) {
+	details, privs, err := loadExternalConnection(ctx, ie, nil /* txn */, name)
+	if err != nil {
+		return "", err
+	}
+	if privs == nil {
+		return "", errExternalConnectionNotFound(name)
+	}
+	var memberOf map[string]bool
+	if user != security.RootUser && user != security.NodeUser {
+		if memberOf, err = resolveMemberOfWithAdminOption(ctx, ie, user); err != nil {
+			return "", err
+		}
+	}
+	if !hasExternalConnectionPrivilege(privs, user, memberOf, privilege.USAGE) {
+		return "", pgerror.Newf(pgcode.InsufficientPrivilege,
+			"user %s does not have %s privilege on external connection %s",
+			user, privilege.USAGE, name)
+	}
+	return decryptExternalConnectionURI(ctx, db, details)
+}
+
+// JoinExternalConnectionPath appends subPath to the path of the connection
+// URI base.
+func JoinExternalConnectionPath(base, subPath string) (string, error) {
+	if subPath == "" || subPath == "/" {
+		return base, nil
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(u.Path, subPath)
+	return u.String(), nil
+}
+
+func (p *planner) checkExternalConnectionsVersion(ctx context.Context) error {
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.VersionExternalConnections) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"external connections require the cluster to be upgraded to %s",
+			clusterversion.VersionByKey(clusterversion.VersionExternalConnections))
+	}
+	return nil
+}
+
+type createExternalConnNode struct {
+	n     *tree.CreateExternalConnection
+	uriFn func() (string, error)
+}
+
+// CreateExternalConnection creates a named external connection.
+// Privileges: admin.
+func (p *planner) CreateExternalConnection(
+	ctx context.Context, n *tree.CreateExternalConnection,
+) (planNode, error) {
+	if err := p.checkExternalConnectionsVersion(ctx); err != nil {
+		return nil, err
+	}
+	if err := p.RequireAdminRole(ctx, "CREATE EXTERNAL CONNECTION"); err != nil {
+		return nil, err
+	}
+	uriFn, err := p.TypeAsString(ctx, n.URI, "CREATE EXTERNAL CONNECTION")
+	if err != nil {
+		return nil, err
+	}
+	return &createExternalConnNode{n: n, uriFn: uriFn}, nil
+}
+
+func (n *createExternalConnNode) startExec(params runParams) error {
+	name := string(n.n.Name)
+	uri, err := n.uriFn()
+	if err != nil {
+		return err
+	}
+	if err := validateExternalConnectionURI(uri); err != nil {
+		return err
+	}
+
+	ie := params.ExecCfg().InternalExecutor
+	_, existing, err := loadExternalConnection(params.ctx, ie, params.p.txn, name)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		if n.n.IfNotExists {
+			return nil
+		}
+		return pgerror.Newf(pgcode.DuplicateObject, "external connection %q already exists", name)
+	}
+
+	details, err := encryptExternalConnectionURI(params.ctx, params.ExecCfg().DB, uri)
+	if err != nil {
+		return err
+	}
+	privs, err := protoutil.Marshal(descpb.NewDefaultPrivilegeDescriptor(params.p.User()))
+	if err != nil {
+		return err
+	}
+	_, err = ie.ExecEx(
+		params.ctx, "create-external-connection", params.p.txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUser},
+		`INSERT INTO system.external_connections (connection_name, connection_details, privileges)
+		 VALUES ($1, $2, $3)`,
+		name, tree.NewDBytes(tree.DBytes(details)), tree.NewDBytes(tree.DBytes(privs)),
+	)
+	return err
+}
+
+func (*createExternalConnNode) Next(runParams) (bool, error) { return false, nil }
+func (*createExternalConnNode) Values() tree.Datums          { return tree.Datums{} }
+func (*createExternalConnNode) Close(context.Context)        {}
+
+type alterExternalConnNode struct {
+	n     *tree.AlterExternalConnection
+	uriFn func() (string, error)
+}
+
+// AlterExternalConnection changes the URI of a named external connection.
+// Privileges: DROP on the connection.
+func (p *planner) AlterExternalConnection(
+	ctx context.Context, n *tree.AlterExternalConnection,
+) (planNode, error) {
+	if err := p.checkExternalConnectionsVersion(ctx); err != nil {
+		return nil, err
+	}
+	uriFn, err := p.TypeAsString(ctx, n.URI, "ALTER EXTERNAL CONNECTION")
+	if err != nil {
+		return nil, err
+	}
+	return &alterExternalConnNode{n: n, uriFn: uriFn}, nil
+}
+
+func (n *alterExternalConnNode) startExec(params runParams) error {
+	name := string(n.n.Name)
+	uri, err := n.uriFn()
+	if err != nil {
+		return err
+	}
+	if err := validateExternalConnectionURI(uri); err != nil {
+		return err
+	}
+
+	ie := params.ExecCfg().InternalExecutor
+	_, privs, err := loadExternalConnection(params.ctx, ie, params.p.txn, name)
+	if err != nil {
+		return err
+	}
+	if privs == nil {
+		return errExternalConnectionNotFound(name)
+	}
+	if err := params.p.checkExternalConnectionPrivilege(params.ctx, name, privs, privilege.DROP); err != nil {
+		return err
+	}
+
+	details, err := encryptExternalConnectionURI(params.ctx, params.ExecCfg().DB, uri)
+	if err != nil {
+		return err
+	}
+	_, err = ie.ExecEx(
+		params.ctx, "alter-external-connection", params.p.txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUser},
+		`UPDATE system.external_connections SET connection_details = $2, updated = now()
+		 WHERE connection_name = $1`,
+		name, tree.NewDBytes(tree.DBytes(details)),
+	)
+	return err
+}
+
+func (*alterExternalConnNode) Next(runParams) (bool, error) { return false, nil }
+func (*alterExternalConnNode) Values() tree.Datums          { return tree.Datums{} }
+func (*alterExternalConnNode) Close(context.Context)        {}
+
+type dropExternalConnNode struct {
+	n *tree.DropExternalConnection
+}
+
+// DropExternalConnection removes a named external connection.
+// Privileges: DROP on the connection.
+func (p *planner) DropExternalConnection(
+	ctx context.Context, n *tree.DropExternalConnection,
+) (planNode, error) {
+	if err := p.checkExternalConnectionsVersion(ctx); err != nil {
+		return nil, err
+	}
+	return &dropExternalConnNode{n: n}, nil
+}
+
+func (n *dropExternalConnNode) startExec(params runParams) error {
+	name := string(n.n.Name)
+	ie := params.ExecCfg().InternalExecutor
+	_, privs, err := loadExternalConnection(params.ctx, ie, params.p.txn, name)
+	if err != nil {
+		return err
+	}
+	if privs == nil {
+		if n.n.IfExists {
+			return nil
+		}
+		return errExternalConnectionNotFound(name)
+	}
+	if err := params.p.checkExternalConnectionPrivilege(params.ctx, name, privs, privilege.DROP); err != nil {
+		return err
+	}
+	_, err = ie.ExecEx(
+		params.ctx, "drop-external-connection", params.p.txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUser},
+		`DELETE FROM system.external_connections WHERE connection_name = $1`,
+		name,
+	)
+	return err
+}
+
+func (*dropExternalConnNode) Next(runParams) (bool, error) { return false, nil }
+func (*dropExternalConnNode) Values() tree.Datums          { return tree.Datums{} }
+func (*dropExternalConnNode) Close(context.Context)        {}
+
+// changeExternalConnectionPrivileges implements GRANT and REVOKE on external
+// connections, which are stored outside of the descriptor table.
+func (n *changePrivilegesNode) changeExternalConnectionPrivileges(params runParams) error {
+	ie := params.ExecCfg().InternalExecutor
+	for _, connName := range n.targets.ExternalConnections {
+		name := string(connName)
+		_, privs, err := loadExternalConnection(params.ctx, ie, params.p.txn, name)
+		if err != nil {
+			return err
+		}
+		if privs == nil {
+			return errExternalConnectionNotFound(name)
+		}
+		if err := params.p.checkExternalConnectionPrivilege(
+			params.ctx, name, privs, privilege.GRANT,
+		); err != nil {
+			return err
+		}
+		// Only allow granting/revoking privileges that the requesting
+		// user themselves have on the connection.
+		for _, priv := range n.desiredprivs {
+			if err := params.p.checkExternalConnectionPrivilege(params.ctx, name, privs, priv); err != nil {
+				return err
+			}
+		}
+		for _, grantee := range n.grantees {
+			n.changePrivilege(privs, string(grantee))
+		}
+		if err := writeExternalConnectionPrivileges(params.ctx, ie, params.p.txn, name, privs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var showExternalConnectionsColumns = colinfo.ResultColumns{
+	{Name: "connection_name", Typ: types.String},
+	{Name: "connection_uri", Typ: types.String},
+	{Name: "owner", Typ: types.String},
+	{Name: "created", Typ: types.TimestampTZ},
+	{Name: "updated", Typ: types.TimestampTZ},
+}
+
+// ShowExternalConnections lists the external connections the current user
+// holds the USAGE privilege on, with credentials redacted from their URIs.
+func (p *planner) ShowExternalConnections(
+	ctx context.Context, n *tree.ShowExternalConnections,
+) (planNode, error) {
+	if err := p.checkExternalConnectionsVersion(ctx); err != nil {
+		return nil, err
+	}
+	return &delayedNode{
+		name:    n.String(),
+		columns: showExternalConnectionsColumns,
+
+		constructor: func(ctx context.Context, p *planner) (planNode, error) {
+			rows, err := p.ExecCfg().InternalExecutor.QueryEx(
+				ctx, "show-external-connections", p.txn,
+				sessiondata.InternalExecutorOverride{User: security.RootUser},
+				`SELECT connection_name, connection_details, privileges, created, updated
+				 FROM system.external_connections ORDER BY connection_name`,
+			)
+			if err != nil {
+				return nil, err
+			}
+			memberOf, err := p.MemberOfWithAdminOption(ctx, p.User())
+			if err != nil {
+				return nil, err
+			}
+
+			v := p.newContainerValuesNode(showExternalConnectionsColumns, 0)
+			for _, row := range rows {
+				privs := &descpb.PrivilegeDescriptor{}
+				if err := protoutil.Unmarshal([]byte(tree.MustBeDBytes(row[2])), privs); err != nil {
+					v.Close(ctx)
+					return nil, err
+				}
+				if !hasExternalConnectionPrivilege(privs, p.User(), memberOf, privilege.USAGE) {
+					continue
+				}
+				uri, err := decryptExternalConnectionURI(ctx, p.ExecCfg().DB, []byte(tree.MustBeDBytes(row[1])))
+				if err != nil {
+					v.Close(ctx)
+					return nil, err
+				}
+				if _, err := v.rows.AddRow(ctx, tree.Datums{
+					row[0],
+					tree.NewDString(redactExternalConnectionURI(uri)),
+					tree.NewDString(privs.Owner),
+					row[3],
+					row[4],
+				}); err != nil {
+					v.Close(ctx)
+					return nil, err
+				}
+			}
+			return v, nil
+		},
+	}, nil
+}
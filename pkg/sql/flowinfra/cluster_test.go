@@ -312,11 +312,11 @@ func ignoreLeafTxnState(metas []execinfrapb.ProducerMetadata) []execinfrapb.Prod
 }
 
 // ignoreMetricsMeta takes a slice of metadata and returns the entries
-// excluding the metrics about node's goodput.
+// excluding the metrics about node's goodput and the stats of the flows.
 func ignoreMetricsMeta(metas []execinfrapb.ProducerMetadata) []execinfrapb.ProducerMetadata {
 	res := make([]execinfrapb.ProducerMetadata, 0)
 	for _, m := range metas {
-		if m.Metrics == nil {
+		if m.Metrics == nil && m.ComponentStats == nil {
 			res = append(res, m)
 		}
 	}
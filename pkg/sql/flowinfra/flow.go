@@ -446,8 +446,11 @@ func (f *FlowBase) Cleanup(ctx context.Context) {
 		f.TypeResolverFactory.CleanupFunc(ctx)
 	}
 
-	// This closes the monitor opened in ServerImpl.setupFlow.
+	// This closes the monitors opened in ServerImpl.setupFlow.
 	f.EvalCtx.Stop(ctx)
+	if f.DiskMonitor != nil {
+		f.DiskMonitor.Stop(ctx)
+	}
 	for _, p := range f.processors {
 		if d, ok := p.(Releasable); ok {
 			d.Release()
@@ -153,9 +153,7 @@ func (m *Outbox) flush(ctx context.Context) error {
 		return nil
 	}
 	msg := m.encoder.FormMessage(ctx)
-	if m.statsCollectionEnabled {
-		m.stats.BytesSent += int64(msg.Size())
-	}
+	m.stats.BytesSent += int64(msg.Size())
 
 	if log.V(3) {
 		log.Infof(ctx, "flushing outbox")
@@ -201,6 +199,9 @@ func (m *Outbox) mainLoop(ctx context.Context) error {
 	// writers could be writing to it as soon as we are started.
 	defer m.RowChannel.ConsumerClosed()
 
+	cpuTimer := m.flowCtx.StartCPUTimer()
+	defer cpuTimer.Stop()
+
 	var span opentracing.Span
 	ctx, span = execinfra.ProcessorSpan(ctx, "outbox")
 	if span != nil && tracing.IsRecording(span) {
@@ -278,6 +279,12 @@ func (m *Outbox) mainLoop(ctx context.Context) error {
 		case msg, ok := <-m.RowChannel.C:
 			if !ok {
 				// No more data.
+				if meta := m.flowCtx.GetFlowStatsMeta(m.stats.BytesSent, cpuTimer.Stop()); meta != nil {
+					if err := m.addRow(ctx, nil, meta); err != nil {
+						return err
+					}
+					meta.Release()
+				}
 				if m.statsCollectionEnabled {
 					err := m.flush(ctx)
 					if err != nil {
@@ -0,0 +1,335 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+func (p *planner) writeFunctionDesc(ctx context.Context, desc *funcdesc.Mutable) error {
+	b := p.txn.NewBatch()
+	if err := p.Descriptors().WriteDescToBatch(
+		ctx, p.extendedEvalCtx.Tracing.KVTracingEnabled(), desc, b,
+	); err != nil {
+		return err
+	}
+	return p.txn.Run(ctx, b)
+}
+
+// writeFunctionDescChange writes the function descriptor and queues a schema
+// change job for it, which waits for the new version to be leased and, for
+// dropped functions, deletes the descriptor.
+func (p *planner) writeFunctionDescChange(
+	ctx context.Context, desc *funcdesc.Mutable, jobDesc string,
+) error {
+	job, jobExists := p.extendedEvalCtx.SchemaChangeJobCache[desc.ID]
+	if jobExists {
+		// Update it.
+		if err := job.WithTxn(p.txn).SetDescription(ctx,
+			func(ctx context.Context, desc string) (string, error) {
+				return desc + "; " + jobDesc, nil
+			},
+		); err != nil {
+			return err
+		}
+		log.Infof(ctx, "job %d: updated with for change on function %d", *job.ID(), desc.ID)
+	} else {
+		// Or, create a new job.
+		jobRecord := jobs.Record{
+			Description:   jobDesc,
+			Username:      p.User(),
+			DescriptorIDs: descpb.IDs{desc.ID},
+			Details: jobspb.SchemaChangeDetails{
+				DescID: desc.ID,
+				// The version distinction for database jobs doesn't matter for
+				// function jobs.
+				FormatVersion: jobspb.DatabaseJobFormatVersion,
+			},
+			Progress: jobspb.SchemaChangeProgress{},
+		}
+		newJob, err := p.extendedEvalCtx.QueueJob(jobRecord)
+		if err != nil {
+			return err
+		}
+		p.extendedEvalCtx.SchemaChangeJobCache[desc.ID] = newJob
+		log.Infof(ctx, "queued new schema change job %d for function %d", *newJob.ID(), desc.ID)
+	}
+
+	return p.writeFunctionDesc(ctx, desc)
+}
+
+// getQualifiedFunctionName returns the fully qualified name of the given
+// function.
+func (p *planner) getQualifiedFunctionName(
+	ctx context.Context, desc *funcdesc.Mutable,
+) (*tree.TableName, error) {
+	dbDesc, err := catalogkv.MustGetDatabaseDescByID(ctx, p.txn, p.ExecCfg().Codec, desc.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	schemaName, err := resolver.ResolveSchemaNameByID(
+		ctx, p.txn, p.ExecCfg().Codec, desc.ParentID, desc.ParentSchemaID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	fnName := tree.MakeTableNameWithSchema(
+		tree.Name(dbDesc.GetName()), tree.Name(schemaName), tree.Name(desc.Name),
+	)
+	return &fnName, nil
+}
+
+// canDropRelationWithFunctions returns an error if the given relation is used
+// by any user-defined function, unless the drop behavior is CASCADE. op
+// describes the operation (e.g. "drop" or "rename").
+func (p *planner) canDropRelationWithFunctions(
+	ctx context.Context, desc *tabledesc.Mutable, op string, behavior tree.DropBehavior,
+) error {
+	if len(desc.DependedOnByFunctions) == 0 || behavior == tree.DropCascade {
+		return nil
+	}
+	return p.dependentFunctionError(ctx, "relation", desc.Name, desc.DependedOnByFunctions[0].ID, op)
+}
+
+// canChangeColumnWithFunctions returns an error if the given column is used by
+// any user-defined function. op describes the operation (e.g. "rename" or
+// "alter type of").
+func (p *planner) canChangeColumnWithFunctions(
+	ctx context.Context, desc *tabledesc.Mutable, col *descpb.ColumnDescriptor, op string,
+) error {
+	for _, ref := range desc.DependedOnByFunctions {
+		if referencesColumn(ref, col.ID) {
+			return p.dependentFunctionError(ctx, "column", col.Name, ref.ID, op)
+		}
+	}
+	return nil
+}
+
+// dropFunctionsDependingOnColumn drops all the functions that use the given
+// column, as part of dropping it, or returns an error if there are any and the
+// drop behavior is not CASCADE.
+func (p *planner) dropFunctionsDependingOnColumn(
+	ctx context.Context,
+	desc *tabledesc.Mutable,
+	col *descpb.ColumnDescriptor,
+	behavior tree.DropBehavior,
+	jobDesc string,
+) error {
+	var dropped []descpb.ID
+	for _, ref := range desc.DependedOnByFunctions {
+		if !referencesColumn(ref, col.ID) {
+			continue
+		}
+		if behavior != tree.DropCascade {
+			return p.dependentFunctionError(ctx, "column", col.Name, ref.ID, "drop")
+		}
+		fnDesc, err := p.Descriptors().GetMutableFunctionVersionByID(ctx, p.txn, ref.ID)
+		if err != nil {
+			return err
+		}
+		if !fnDesc.Dropped() {
+			if err := p.dropFunctionImpl(ctx, fnDesc, desc.ID, jobDesc); err != nil {
+				return err
+			}
+		}
+		dropped = append(dropped, ref.ID)
+	}
+	// A dropped function may also have references to the relation that do not
+	// involve the column.
+	for _, id := range dropped {
+		desc.DependedOnByFunctions = removeMatchingReferences(desc.DependedOnByFunctions, id)
+	}
+	return nil
+}
+
+// referencesColumn returns whether the back-reference ref involves the column
+// with ID colID.
+func referencesColumn(ref descpb.TableDescriptor_Reference, colID descpb.ColumnID) bool {
+	for _, id := range ref.ColumnIDs {
+		if id == colID {
+			return true
+		}
+	}
+	return false
+}
+
+// dependentFunctionError returns the error for an operation on an object that
+// the function with ID fnID depends on.
+func (p *planner) dependentFunctionError(
+	ctx context.Context, typeName, objName string, fnID descpb.ID, op string,
+) error {
+	fnDesc, err := p.Descriptors().GetMutableFunctionVersionByID(ctx, p.txn, fnID)
+	if err != nil {
+		return err
+	}
+	fnName, err := p.getQualifiedFunctionName(ctx, fnDesc)
+	if err != nil {
+		return err
+	}
+	return pgerror.Newf(pgcode.DependentObjectsStillExist,
+		"cannot %s %s %q because function %q depends on it",
+		op, typeName, objName, fnName.FQString())
+}
+
+// dropFunctionsDependingOn drops all the functions that depend on the given
+// relation, as part of dropping it with CASCADE.
+func (p *planner) dropFunctionsDependingOn(
+	ctx context.Context, desc *tabledesc.Mutable, jobDesc string,
+) error {
+	for _, ref := range desc.DependedOnByFunctions {
+		fnDesc, err := p.Descriptors().GetMutableFunctionVersionByID(ctx, p.txn, ref.ID)
+		if err != nil {
+			return err
+		}
+		if fnDesc.Dropped() {
+			continue
+		}
+		if err := p.dropFunctionImpl(ctx, fnDesc, desc.ID, jobDesc); err != nil {
+			return err
+		}
+	}
+	desc.DependedOnByFunctions = nil
+	return nil
+}
+
+// removeFunctionBackReferences removes the back-references to the given
+// function from the relations it depends on, except for the relation with ID
+// skipID (if any), which the caller is responsible for updating.
+func (p *planner) removeFunctionBackReferences(
+	ctx context.Context, fnDesc *funcdesc.Mutable, skipID descpb.ID, jobDesc string,
+) error {
+	for _, id := range fnDesc.DependsOn {
+		if id == skipID {
+			continue
+		}
+		tblDesc, err := p.Descriptors().GetMutableTableVersionByID(ctx, id, p.txn)
+		if err != nil {
+			return err
+		}
+		if tblDesc.Dropped() {
+			continue
+		}
+		tblDesc.DependedOnByFunctions = removeMatchingReferences(tblDesc.DependedOnByFunctions, fnDesc.ID)
+		if err := p.writeSchemaChange(ctx, tblDesc, descpb.InvalidMutationID, jobDesc); err != nil {
+			return err
+		}
+	}
+	if fnDesc.ReturnType != nil && fnDesc.ReturnType.UserDefined() {
+		if err := p.removeTypeBackReference(
+			ctx, typedesc.UserDefinedTypeOIDToID(fnDesc.ReturnType.Oid()), fnDesc.ID, jobDesc,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// canDropFunctionWithTriggers returns an error if the given function is
+// executed by any trigger, unless the drop behavior is CASCADE.
+func (p *planner) canDropFunctionWithTriggers(
+	ctx context.Context, fnDesc *funcdesc.Mutable, behavior tree.DropBehavior,
+) error {
+	if len(fnDesc.DependedOnByTriggers) == 0 || behavior == tree.DropCascade {
+		return nil
+	}
+	tblDesc, err := p.Descriptors().GetMutableTableVersionByID(ctx, fnDesc.DependedOnByTriggers[0], p.txn)
+	if err != nil {
+		return err
+	}
+	for i := range tblDesc.Triggers {
+		if tblDesc.Triggers[i].FunctionID == fnDesc.ID {
+			return pgerror.Newf(pgcode.DependentObjectsStillExist,
+				"cannot drop function %q because trigger %q on table %q depends on it",
+				fnDesc.Name, tblDesc.Triggers[i].Name, tblDesc.Name)
+		}
+	}
+	return errors.AssertionFailedf(
+		"table %q has no trigger executing function %q", tblDesc.Name, fnDesc.Name)
+}
+
+// dropTriggersDependingOn drops all the triggers that execute the given
+// function, except the ones on the relation with ID skipID (if any), which is
+// being dropped by the caller.
+func (p *planner) dropTriggersDependingOn(
+	ctx context.Context, fnDesc *funcdesc.Mutable, skipID descpb.ID, jobDesc string,
+) error {
+	for _, id := range fnDesc.DependedOnByTriggers {
+		if id == skipID {
+			continue
+		}
+		tblDesc, err := p.Descriptors().GetMutableTableVersionByID(ctx, id, p.txn)
+		if err != nil {
+			return err
+		}
+		if tblDesc.Dropped() {
+			continue
+		}
+		triggers := tblDesc.Triggers[:0]
+		for _, tr := range tblDesc.Triggers {
+			if tr.FunctionID != fnDesc.ID {
+				triggers = append(triggers, tr)
+			}
+		}
+		tblDesc.Triggers = triggers
+		if err := p.writeSchemaChange(ctx, tblDesc, descpb.InvalidMutationID, jobDesc); err != nil {
+			return err
+		}
+	}
+	fnDesc.DependedOnByTriggers = nil
+	return nil
+}
+
+// removeTriggerFunctionBackReferences removes the back-references to the
+// given relation from the functions executed by its triggers, as part of
+// dropping the relation.
+func (p *planner) removeTriggerFunctionBackReferences(
+	ctx context.Context, desc *tabledesc.Mutable, jobDesc string,
+) error {
+	for i := range desc.Triggers {
+		fnDesc, err := p.Descriptors().GetMutableFunctionVersionByID(ctx, p.txn, desc.Triggers[i].FunctionID)
+		if err != nil {
+			return err
+		}
+		if fnDesc.Dropped() {
+			continue
+		}
+		fnDesc.DependedOnByTriggers = removeMatchingIDs(fnDesc.DependedOnByTriggers, desc.ID)
+		if err := p.writeFunctionDescChange(ctx, fnDesc, jobDesc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeMatchingIDs removes all occurrences of id from ids, in place.
+func removeMatchingIDs(ids []descpb.ID, id descpb.ID) []descpb.ID {
+	updated := ids[:0]
+	for _, other := range ids {
+		if other != id {
+			updated = append(updated, other)
+		}
+	}
+	return updated
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
@@ -49,6 +50,12 @@ func (p *planner) Grant(ctx context.Context, n *tree.Grant) (planNode, error) {
 	case n.Targets.Types != nil:
 		sqltelemetry.IncIAMGrantPrivilegesCounter(sqltelemetry.OnType)
 		grantOn = privilege.Type
+	case n.Targets.Functions != nil:
+		sqltelemetry.IncIAMGrantPrivilegesCounter(sqltelemetry.OnFunction)
+		grantOn = privilege.Function
+	case n.Targets.ExternalConnections != nil:
+		sqltelemetry.IncIAMGrantPrivilegesCounter(sqltelemetry.OnExternalConnection)
+		grantOn = privilege.ExternalConnection
 	default:
 		sqltelemetry.IncIAMGrantPrivilegesCounter(sqltelemetry.OnTable)
 		grantOn = privilege.Table
@@ -90,6 +97,12 @@ func (p *planner) Revoke(ctx context.Context, n *tree.Revoke) (planNode, error)
 	case n.Targets.Types != nil:
 		sqltelemetry.IncIAMRevokePrivilegesCounter(sqltelemetry.OnType)
 		grantOn = privilege.Type
+	case n.Targets.Functions != nil:
+		sqltelemetry.IncIAMRevokePrivilegesCounter(sqltelemetry.OnFunction)
+		grantOn = privilege.Function
+	case n.Targets.ExternalConnections != nil:
+		sqltelemetry.IncIAMRevokePrivilegesCounter(sqltelemetry.OnExternalConnection)
+		grantOn = privilege.ExternalConnection
 	default:
 		sqltelemetry.IncIAMRevokePrivilegesCounter(sqltelemetry.OnTable)
 		grantOn = privilege.Table
@@ -142,6 +155,10 @@ func (n *changePrivilegesNode) startExec(params runParams) error {
 		}
 	}
 
+	if n.targets.ExternalConnections != nil {
+		return n.changeExternalConnectionPrivileges(params)
+	}
+
 	var descriptors []catalog.Descriptor
 	// DDL statements avoid the cache to avoid leases, and can view non-public descriptors.
 	// TODO(vivek): check if the cache can be used.
@@ -235,6 +252,14 @@ func (n *changePrivilegesNode) startExec(params runParams) error {
 			); err != nil {
 				return err
 			}
+		case *funcdesc.Mutable:
+			if err := p.writeFunctionDescChange(
+				ctx,
+				d,
+				fmt.Sprintf("updating privileges for function %d", d.ID),
+			); err != nil {
+				return err
+			}
 		}
 	}
 
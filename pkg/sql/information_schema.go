@@ -77,7 +77,6 @@ var informationSchema = virtualSchema{
 		"data_type_privileges",
 		"domain_constraints",
 		"domain_udt_usage",
-		"domains",
 		"element_types",
 		"enabled_roles",
 		"foreign_data_wrapper_options",
@@ -131,6 +130,7 @@ var informationSchema = virtualSchema{
 		catconstants.InformationSchemaColumnsTableID:                    informationSchemaColumnsTable,
 		catconstants.InformationSchemaColumnUDTUsageID:                  informationSchemaColumnUDTUsage,
 		catconstants.InformationSchemaConstraintColumnUsageTableID:      informationSchemaConstraintColumnUsageTable,
+		catconstants.InformationSchemaDomainsTableID:                    informationSchemaDomainsTable,
 		catconstants.InformationSchemaTypePrivilegesID:                  informationSchemaTypePrivilegesTable,
 		catconstants.InformationSchemaEnabledRolesID:                    informationSchemaEnabledRoles,
 		catconstants.InformationSchemaKeyColumnUsageTableID:             informationSchemaKeyColumnUsageTable,
@@ -399,6 +399,18 @@ https://www.postgresql.org/docs/9.5/infoschema-columns.html`,
 					}
 					colDefault = tree.NewDString(colExpr)
 				}
+				// Columns of a DOMAIN type are described by the base type of the
+				// domain, with the domain in the domain_* columns.
+				colDataType := column.Type
+				domainCatalog := tree.DNull
+				domainSchema := tree.DNull
+				domainName := tree.DNull
+				if column.Type.IsDomain() && column.Type.TypeMeta.DomainData != nil {
+					colDataType = column.Type.TypeMeta.DomainData.BaseType
+					domainCatalog = tree.NewDString(column.Type.TypeMeta.Name.Catalog)
+					domainSchema = tree.NewDString(column.Type.TypeMeta.Name.Schema)
+					domainName = tree.NewDString(column.Type.TypeMeta.Name.Name)
+				}
 				colComputed := emptyString
 				if column.ComputeExpr != nil {
 					colExpr, err := schemaexpr.FormatExprForDisplay(ctx, table, *column.ComputeExpr, &p.semaCtx, tree.FmtSimple)
@@ -415,7 +427,7 @@ https://www.postgresql.org/docs/9.5/infoschema-columns.html`,
 					tree.NewDInt(tree.DInt(column.GetPGAttributeNum())), // ordinal_position
 					colDefault,                    // column_default
 					yesOrNoDatum(column.Nullable), // is_nullable
-					tree.NewDString(column.Type.InformationSchemaName()), // data_type
+					tree.NewDString(colDataType.InformationSchemaName()), // data_type
 					characterMaximumLength(column.Type),                  // character_maximum_length
 					characterOctetLength(column.Type),                    // character_octet_length
 					numericPrecision(column.Type),                        // numeric_precision
@@ -430,12 +442,12 @@ https://www.postgresql.org/docs/9.5/infoschema-columns.html`,
 					collationCatalog,                                     // collation_catalog
 					collationSchema,                                      // collation_schema
 					collationName,                                        // collation_name
-					tree.DNull,                                           // domain_catalog
-					tree.DNull,                                           // domain_schema
-					tree.DNull,                                           // domain_name
+					domainCatalog,                                        // domain_catalog
+					domainSchema,                                         // domain_schema
+					domainName,                                           // domain_name
 					dbNameStr,                                            // udt_catalog
 					pgCatalogNameDString,                                 // udt_schema
-					tree.NewDString(column.Type.PGName()),                // udt_name
+					tree.NewDString(colDataType.PGName()),                // udt_name
 					tree.DNull,                                           // scope_catalog
 					tree.DNull,                                           // scope_schema
 					tree.DNull,                                           // scope_name
@@ -493,6 +505,62 @@ https://www.postgresql.org/docs/current/infoschema-column-udt-usage.html`,
 	},
 }
 
+var informationSchemaDomainsTable = virtualSchemaTable{
+	comment: `domains
+https://www.postgresql.org/docs/current/infoschema-domains.html`,
+	schema: vtable.InformationSchemaDomains,
+	populate: func(ctx context.Context, p *planner, dbContext *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		return forEachTypeDesc(ctx, p, dbContext, func(db *dbdesc.Immutable, sc string, typeDesc *typedesc.Immutable) error {
+			if typeDesc.Kind != descpb.TypeDescriptor_DOMAIN {
+				return nil
+			}
+			dbNameStr := tree.NewDString(db.GetName())
+			baseType := typeDesc.Alias
+			collationCatalog := tree.DNull
+			collationSchema := tree.DNull
+			collationName := tree.DNull
+			if locale := baseType.Locale(); locale != "" {
+				collationCatalog = dbNameStr
+				collationSchema = pgCatalogNameDString
+				collationName = tree.NewDString(locale)
+			}
+			domainDefault := tree.DNull
+			if typeDesc.DomainDefaultExpr != nil {
+				domainDefault = tree.NewDString(*typeDesc.DomainDefaultExpr)
+			}
+			return addRow(
+				dbNameStr,                           // domain_catalog
+				tree.NewDString(sc),                 // domain_schema
+				tree.NewDString(typeDesc.GetName()), // domain_name
+				tree.NewDString(baseType.InformationSchemaName()), // data_type
+				characterMaximumLength(baseType),                  // character_maximum_length
+				characterOctetLength(baseType),                    // character_octet_length
+				tree.DNull,                                        // character_set_catalog
+				tree.DNull,                                        // character_set_schema
+				tree.DNull,                                        // character_set_name
+				collationCatalog,                                  // collation_catalog
+				collationSchema,                                   // collation_schema
+				collationName,                                     // collation_name
+				numericPrecision(baseType),                        // numeric_precision
+				numericPrecisionRadix(baseType),                   // numeric_precision_radix
+				numericScale(baseType),                            // numeric_scale
+				datetimePrecision(baseType),                       // datetime_precision
+				tree.DNull,                                        // interval_type
+				tree.DNull,                                        // interval_precision
+				domainDefault,                                     // domain_default
+				dbNameStr,                                         // udt_catalog
+				pgCatalogNameDString,                              // udt_schema
+				tree.NewDString(baseType.PGName()),                // udt_name
+				tree.DNull,                                        // scope_catalog
+				tree.DNull,                                        // scope_schema
+				tree.DNull,                                        // scope_name
+				tree.DNull,                                        // maximum_cardinality
+				tree.DNull,                                        // dtd_identifier
+			)
+		})
+	},
+}
+
 var informationSchemaEnabledRoles = virtualSchemaTable{
 	comment: `roles for the current user
 ` + base.DocsURL("information-schema.html#enabled_roles") + `
@@ -1242,11 +1310,12 @@ CREATE TABLE information_schema.statistics (
 				appendRow := func(index *descpb.IndexDescriptor, colName string, sequence int,
 					direction tree.Datum, isStored, isImplicit bool,
 				) error {
+					nonUnique := !index.Unique && !index.DeferrableUnique
 					return addRow(
 						dbNameStr,                         // table_catalog
 						scNameStr,                         // table_schema
 						tbNameStr,                         // table_name
-						yesOrNoDatum(!index.Unique),       // non_unique
+						yesOrNoDatum(nonUnique),           // non_unique
 						scNameStr,                         // index_schema
 						tree.NewDString(index.Name),       // index_name
 						tree.NewDInt(tree.DInt(sequence)), // seq_in_index
@@ -1348,6 +1417,12 @@ CREATE TABLE information_schema.table_constraints (
 				tbNameStr := tree.NewDString(table.GetName())
 
 				for conName, c := range conInfo {
+					deferrable, initiallyDeferred := false, false
+					if c.FK != nil {
+						deferrable, initiallyDeferred = c.FK.Deferrable, c.FK.InitiallyDeferred
+					} else if c.Kind == descpb.ConstraintTypeUnique {
+						deferrable, initiallyDeferred = c.Index.DeferrableUnique, c.Index.InitiallyDeferred
+					}
 					if err := addRow(
 						dbNameStr,                       // constraint_catalog
 						scNameStr,                       // constraint_schema
@@ -1356,8 +1431,8 @@ CREATE TABLE information_schema.table_constraints (
 						scNameStr,                       // table_schema
 						tbNameStr,                       // table_name
 						tree.NewDString(string(c.Kind)), // constraint_type
-						yesOrNoDatum(false),             // is_deferrable
-						yesOrNoDatum(false),             // initially_deferred
+						yesOrNoDatum(deferrable),        // is_deferrable
+						yesOrNoDatum(initiallyDeferred), // initially_deferred
 					); err != nil {
 						return err
 					}
@@ -51,6 +51,8 @@ func buildOpaque(
 	switch n := stmt.(type) {
 	case *tree.AlterDatabaseOwner:
 		plan, err = p.AlterDatabaseOwner(ctx, n)
+	case *tree.AlterExternalConnection:
+		plan, err = p.AlterExternalConnection(ctx, n)
 	case *tree.AlterIndex:
 		plan, err = p.AlterIndex(ctx, n)
 	case *tree.AlterSchema:
@@ -61,12 +63,16 @@ func buildOpaque(
 		plan, err = p.AlterTableSetSchema(ctx, n)
 	case *tree.AlterType:
 		plan, err = p.AlterType(ctx, n)
+	case *tree.AlterDomain:
+		plan, err = p.AlterDomain(ctx, n)
 	case *tree.AlterRole:
 		plan, err = p.AlterRole(ctx, n)
 	case *tree.AlterSequence:
 		plan, err = p.AlterSequence(ctx, n)
 	case *tree.Analyze:
 		plan, err = p.Analyze(ctx, n)
+	case *tree.CloseCursor:
+		plan, err = p.CloseCursor(ctx, n)
 	case *tree.CommentOnColumn:
 		plan, err = p.CommentOnColumn(ctx, n)
 	case *tree.CommentOnDatabase:
@@ -81,6 +87,8 @@ func buildOpaque(
 		plan, err = p.CreateIndex(ctx, n)
 	case *tree.CreateSchema:
 		plan, err = p.CreateSchema(ctx, n)
+	case *tree.CreateTrigger:
+		plan, err = p.CreateTrigger(ctx, n)
 	case *tree.CreateType:
 		plan, err = p.CreateType(ctx, n)
 	case *tree.CreateRole:
@@ -91,12 +99,18 @@ func buildOpaque(
 		plan, err = p.CreateStatistics(ctx, n)
 	case *tree.CreateExtension:
 		plan, err = p.CreateExtension(ctx, n)
+	case *tree.CreateExternalConnection:
+		plan, err = p.CreateExternalConnection(ctx, n)
 	case *tree.Deallocate:
 		plan, err = p.Deallocate(ctx, n)
 	case *tree.Discard:
 		plan, err = p.Discard(ctx, n)
 	case *tree.DropDatabase:
 		plan, err = p.DropDatabase(ctx, n)
+	case *tree.DropExternalConnection:
+		plan, err = p.DropExternalConnection(ctx, n)
+	case *tree.DropFunction:
+		plan, err = p.DropFunction(ctx, n)
 	case *tree.DropIndex:
 		plan, err = p.DropIndex(ctx, n)
 	case *tree.DropRole:
@@ -105,16 +119,22 @@ func buildOpaque(
 		plan, err = p.DropSchema(ctx, n)
 	case *tree.DropTable:
 		plan, err = p.DropTable(ctx, n)
+	case *tree.DropTrigger:
+		plan, err = p.DropTrigger(ctx, n)
 	case *tree.DropType:
 		plan, err = p.DropType(ctx, n)
 	case *tree.DropView:
 		plan, err = p.DropView(ctx, n)
 	case *tree.DropSequence:
 		plan, err = p.DropSequence(ctx, n)
+	case *tree.FetchCursor:
+		plan, err = p.FetchCursor(ctx, n)
 	case *tree.Grant:
 		plan, err = p.Grant(ctx, n)
 	case *tree.GrantRole:
 		plan, err = p.GrantRole(ctx, n)
+	case *tree.MoveCursor:
+		plan, err = p.MoveCursor(ctx, n)
 	case *tree.ReassignOwnedBy:
 		plan, err = p.ReassignOwnedBy(ctx, n)
 	case *tree.RefreshMaterializedView:
@@ -139,6 +159,8 @@ func buildOpaque(
 		plan, err = p.Scrub(ctx, n)
 	case *tree.SetClusterSetting:
 		plan, err = p.SetClusterSetting(ctx, n)
+	case *tree.SetConstraints:
+		plan, err = p.SetConstraints(ctx, n)
 	case *tree.SetZoneConfig:
 		plan, err = p.SetZoneConfig(ctx, n)
 	case *tree.SetVar:
@@ -151,6 +173,8 @@ func buildOpaque(
 		plan, err = p.SetSessionCharacteristics(n)
 	case *tree.ShowClusterSetting:
 		plan, err = p.ShowClusterSetting(ctx, n)
+	case *tree.ShowExternalConnections:
+		plan, err = p.ShowExternalConnections(ctx, n)
 	case *tree.ShowHistogram:
 		plan, err = p.ShowHistogram(ctx, n)
 	case *tree.ShowTableStats:
@@ -186,38 +210,48 @@ func buildOpaque(
 func init() {
 	for _, stmt := range []tree.Statement{
 		&tree.AlterDatabaseOwner{},
+		&tree.AlterExternalConnection{},
 		&tree.AlterIndex{},
 		&tree.AlterSchema{},
 		&tree.AlterTable{},
 		&tree.AlterTableSetSchema{},
 		&tree.AlterType{},
+		&tree.AlterDomain{},
 		&tree.AlterSequence{},
 		&tree.AlterRole{},
 		&tree.Analyze{},
+		&tree.CloseCursor{},
 		&tree.CommentOnColumn{},
 		&tree.CommentOnDatabase{},
 		&tree.CommentOnIndex{},
 		&tree.CommentOnTable{},
 		&tree.CreateDatabase{},
 		&tree.CreateExtension{},
+		&tree.CreateExternalConnection{},
 		&tree.CreateIndex{},
 		&tree.CreateSchema{},
 		&tree.CreateSequence{},
 		&tree.CreateStats{},
+		&tree.CreateTrigger{},
 		&tree.CreateType{},
 		&tree.CreateRole{},
 		&tree.Deallocate{},
 		&tree.Discard{},
 		&tree.DropDatabase{},
+		&tree.DropExternalConnection{},
+		&tree.DropFunction{},
 		&tree.DropIndex{},
 		&tree.DropSchema{},
 		&tree.DropTable{},
+		&tree.DropTrigger{},
 		&tree.DropType{},
 		&tree.DropView{},
 		&tree.DropRole{},
 		&tree.DropSequence{},
+		&tree.FetchCursor{},
 		&tree.Grant{},
 		&tree.GrantRole{},
+		&tree.MoveCursor{},
 		&tree.ReassignOwnedBy{},
 		&tree.RefreshMaterializedView{},
 		&tree.RenameColumn{},
@@ -230,12 +264,14 @@ func init() {
 		&tree.Scatter{},
 		&tree.Scrub{},
 		&tree.SetClusterSetting{},
+		&tree.SetConstraints{},
 		&tree.SetZoneConfig{},
 		&tree.SetVar{},
 		&tree.SetTransaction{},
 		&tree.SetSessionAuthorizationDefault{},
 		&tree.SetSessionCharacteristics{},
 		&tree.ShowClusterSetting{},
+		&tree.ShowExternalConnections{},
 		&tree.ShowHistogram{},
 		&tree.ShowTableStats{},
 		&tree.ShowTraceForSession{},
@@ -245,6 +281,7 @@ func init() {
 
 		// CCL statements (without Export which has an optimizer operator).
 		&tree.Backup{},
+		&tree.CompactBackup{},
 		&tree.ShowBackup{},
 		&tree.Restore{},
 		&tree.CreateChangefeed{},
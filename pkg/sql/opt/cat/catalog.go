@@ -125,6 +125,22 @@ type Catalog interface {
 		ctx context.Context, name *tree.UnresolvedObjectName,
 	) (*types.T, error)
 
+	// ResolveFunction locates a user-defined function with the given name,
+	// following the search path for unqualified names. If no such function
+	// exists, then ResolveFunction returns nil without an error, so that the
+	// caller can fall back to other kinds of functions.
+	//
+	// NOTE: The returned function must be immutable after construction, and so
+	// can be safely copied or used across goroutines.
+	ResolveFunction(
+		ctx context.Context, flags Flags, name *tree.UnresolvedObjectName,
+	) (Function, error)
+
+	// ResolveFunctionByID is similar to ResolveFunction, except that it locates
+	// a user-defined function by its StableID. It is used to look up the
+	// function executed by a table trigger.
+	ResolveFunctionByID(ctx context.Context, flags Flags, id StableID) (Function, error)
+
 	// CheckPrivilege verifies that the current user has the given privilege on
 	// the given catalog object. If not, then CheckPrivilege returns an error.
 	CheckPrivilege(ctx context.Context, o Object, priv privilege.Kind) error
@@ -22,7 +22,7 @@ type Column struct {
 	// The fields in this struct correspond to the getter methods below. Refer to
 	// those for documentation.
 	//
-	// Warning! If any fields are added here, make sure both Init methods below
+	// Warning! If any fields are added here, make sure all Init methods below
 	// set all fields (even if they are the empty value).
 	ordinal                     int
 	stableID                    StableID
@@ -34,6 +34,7 @@ type Column struct {
 	defaultExpr                 *string
 	computedExpr                *string
 	invertedSourceColumnOrdinal int
+	virtualComputed             bool
 }
 
 // Ordinal returns the position of the column in its table. The following always
@@ -124,6 +125,17 @@ func (c *Column) ComputedExprStr() string {
 	return *c.computedExpr
 }
 
+// IsVirtualComputed returns true if this is a virtual computed column. Such a
+// column is not stored in the table; its value is always produced by
+// evaluating ComputedExprStr on the other columns of the row. It can still be
+// stored in secondary indexes.
+//
+// Note that virtual computed columns are Ordinary columns (or mutation columns)
+// and are unrelated to the Virtual column kind used by inverted indexes.
+func (c *Column) IsVirtualComputed() bool {
+	return c.virtualComputed
+}
+
 // InvertedSourceColumnOrdinal is used for virtual columns that are part
 // of inverted indexes. It returns the ordinal of the table column from which
 // the inverted column is derived.
@@ -189,6 +201,7 @@ func (c *Column) InitNonVirtual(
 	c.defaultExpr = defaultExpr
 	c.computedExpr = computedExpr
 	c.invertedSourceColumnOrdinal = -1
+	c.virtualComputed = false
 }
 
 // InitVirtual is used by catalog implementations to populate a virtual Column.
@@ -206,4 +219,33 @@ func (c *Column) InitVirtual(
 	c.defaultExpr = nil
 	c.computedExpr = nil
 	c.invertedSourceColumnOrdinal = invertedSourceColumnOrdinal
+	c.virtualComputed = false
+}
+
+// InitVirtualComputed is used by catalog implementations to populate a virtual
+// computed Column. It should not be used anywhere else.
+func (c *Column) InitVirtualComputed(
+	ordinal int,
+	stableID StableID,
+	name tree.Name,
+	kind ColumnKind,
+	datumType *types.T,
+	nullable bool,
+	hidden bool,
+	computedExpr string,
+) {
+	if kind == Virtual || kind == System {
+		panic(errors.AssertionFailedf("incorrect init method"))
+	}
+	c.ordinal = ordinal
+	c.stableID = stableID
+	c.name = name
+	c.kind = kind
+	c.datumType = datumType
+	c.nullable = nullable
+	c.hidden = hidden
+	c.defaultExpr = nil
+	c.computedExpr = &computedExpr
+	c.invertedSourceColumnOrdinal = -1
+	c.virtualComputed = true
 }
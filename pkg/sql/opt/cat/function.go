@@ -0,0 +1,65 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cat
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+// Function is an interface to a user-defined SQL function, exposing only the
+// information needed by the query optimizer to inline calls to it.
+type Function interface {
+	Object
+
+	// Name returns the unqualified name of the function.
+	Name() tree.Name
+
+	// ParamCount returns the number of parameters of the function.
+	ParamCount() int
+
+	// ParamName returns the name of the ith parameter, where i < ParamCount.
+	// The name is empty if the parameter is unnamed, in which case it can only
+	// be referenced positionally (e.g. $1) in the body.
+	ParamName(i int) tree.Name
+
+	// ParamType returns the type of the ith parameter, where i < ParamCount.
+	ParamType(i int) *types.T
+
+	// ReturnType returns the type of the result of the function. For functions
+	// declared with RETURNS TABLE this is a labeled tuple type, with one
+	// element per output column.
+	ReturnType() *types.T
+
+	// ReturnsTrigger returns true if the function was declared with RETURNS
+	// TRIGGER. A trigger function can only be executed by a table trigger, in
+	// which case ReturnType is nil and the body can reference the OLD and NEW
+	// rows.
+	ReturnsTrigger() bool
+
+	// ReturnsSet returns true if the function returns a set of rows rather than
+	// a single value.
+	ReturnsSet() bool
+
+	// Strict returns true if the function returns NULL (or no rows) whenever
+	// any of its arguments is NULL, without evaluating the body.
+	Strict() bool
+
+	// Volatility returns the volatility the function was declared with. The
+	// body of the function is never more volatile than that, but it may be
+	// less volatile.
+	Volatility() tree.Volatility
+
+	// Body returns the SQL text of the statement that constitutes the body of
+	// the function; data sources are always fully qualified. The body is always
+	// a SELECT statement unless the function returns a trigger.
+	Body() string
+}
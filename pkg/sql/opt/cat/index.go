@@ -52,6 +52,14 @@ type Index interface {
 	// IsUnique returns true if this index is declared as UNIQUE in the schema.
 	IsUnique() bool
 
+	// UniqueDeferrability returns the deferrability of the unique constraint
+	// enforced by this index, or tree.ConstraintNotDeferrable if the index does
+	// not enforce a deferrable unique constraint. The index of a deferrable
+	// unique constraint is not unique: duplicate values of its explicit columns
+	// are only detected by checks that run after the statement, or at the end of
+	// the transaction if the constraint is deferred.
+	UniqueDeferrability() tree.ConstraintDeferrability
+
 	// IsInverted returns true if this is an inverted index.
 	IsInverted() bool
 
@@ -62,6 +70,11 @@ type Index interface {
 	// the table.
 	ColumnCount() int
 
+	// ExplicitColumnCount returns the number of columns in the index that were
+	// part of the index definition, excluding the STORING clause. These columns
+	// are a prefix of the full column list.
+	ExplicitColumnCount() int
+
 	// Predicate returns the partial index predicate expression and true if the
 	// index is a partial index. If it is not a partial index, the empty string
 	// and false are returned.
@@ -234,6 +247,11 @@ type IndexColumn struct {
 	// Descending is true if the index is ordered from greatest to least on
 	// this column, rather than least to greatest.
 	Descending bool
+
+	// NullsReversed is true if NULLs are ordered opposite to the default for
+	// the direction of this column; i.e. last if the column is ascending, or
+	// first if it is descending. It is always false for non-nullable columns.
+	NullsReversed bool
 }
 
 // IsMutationIndex is a convenience function that returns true if the index at
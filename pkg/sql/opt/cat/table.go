@@ -123,6 +123,14 @@ type Table interface {
 
 	// InboundForeignKey returns the ith inbound foreign key reference.
 	InboundForeignKey(i int) ForeignKeyConstraint
+
+	// TriggerCount returns the number of row-level triggers defined on the
+	// table.
+	TriggerCount() int
+
+	// Trigger returns the ith trigger, where i < TriggerCount. Triggers are
+	// ordered by name, which is also the order in which they fire.
+	Trigger(i int) Trigger
 }
 
 // CheckConstraint contains the SQL text and the validity status for a check
@@ -137,6 +145,29 @@ type CheckConstraint struct {
 	Validated  bool
 }
 
+// Trigger describes a row-level trigger on a table, which executes a
+// user-defined trigger function for each row affected by a mutation. For
+// example:
+//
+//   CREATE TRIGGER tr BEFORE INSERT OR UPDATE ON t FOR EACH ROW EXECUTE FUNCTION f()
+//
+type Trigger struct {
+	// Name is the name of the trigger, unique within the table.
+	Name tree.Name
+
+	// Before is true if the trigger fires before the row is written, and false
+	// if it fires after the statement has modified all rows.
+	Before bool
+
+	// Insert, Update and Delete indicate the events that fire the trigger.
+	Insert bool
+	Update bool
+	Delete bool
+
+	// Function is the ID of the trigger function.
+	Function StableID
+}
+
 // TableStatistic is an interface to a table statistic. Each statistic is
 // associated with a set of columns.
 type TableStatistic interface {
@@ -236,4 +267,12 @@ type ForeignKeyConstraint interface {
 	// UpdateReferenceAction returns the action to be performed if the foreign key
 	// constraint would be violated by an update.
 	UpdateReferenceAction() tree.ReferenceAction
+
+	// Deferrable is true if the checks of the constraint can be deferred until
+	// the end of the transaction.
+	Deferrable() bool
+
+	// InitiallyDeferred is true if the checks of a deferrable constraint are
+	// deferred unless the transaction specifies otherwise.
+	InitiallyDeferred() bool
 }
@@ -152,8 +152,13 @@ func (c Columns) String() string {
 	var b strings.Builder
 
 	for i := 0; i < c.Count(); i++ {
+		col := c.Get(i)
 		b.WriteRune('/')
-		b.WriteString(fmt.Sprintf("%d", c.Get(i)))
+		if col.Descending() {
+			b.WriteRune('-')
+		}
+		b.WriteString(fmt.Sprintf("%d", col.ID()))
+		opt.FormatNullsOrder(&b, col.Descending(), col.NullsReversed())
 	}
 	return b.String()
 }
@@ -612,7 +612,7 @@ func (c *Constraint) ExtractNotNullCols(evalCtx *tree.EvalContext) opt.ColSet {
 		span := c.Spans.Get(i)
 		var key Key
 		var boundary SpanBoundary
-		if !col.Descending() {
+		if col.NullsFirst() {
 			key, boundary = span.StartKey(), span.StartBoundary()
 		} else {
 			key, boundary = span.EndKey(), span.EndBoundary()
@@ -293,7 +293,12 @@ func (c *KeyContext) Compare(colIdx int, a, b tree.Datum) int {
 		return 0
 	}
 	cmp := a.Compare(c.EvalCtx, b)
-	if c.Columns.Get(colIdx).Descending() {
+	col := c.Columns.Get(colIdx)
+	if col.NullsReversed() && (a == tree.DNull) != (b == tree.DNull) {
+		// NULLs are ordered after all other values in the column's direction.
+		cmp = -cmp
+	}
+	if col.Descending() {
 		cmp = -cmp
 	}
 	return cmp
@@ -150,8 +150,9 @@ func makeCascadeBuilder(b *Builder, mutationWithID opt.WithID) (*cascadeBuilder,
 // setupCascade fills in an exec.Cascade struct for the given cascade.
 func (cb *cascadeBuilder) setupCascade(cascade *memo.FKCascade) exec.Cascade {
 	return exec.Cascade{
-		FKName: cascade.FKName,
-		Buffer: cb.mutationBuffer,
+		FKName:  cascade.FKName,
+		Trigger: cascade.Trigger,
+		Buffer:  cb.mutationBuffer,
 		PlanFn: func(
 			ctx context.Context,
 			semaCtx *tree.SemaContext,
@@ -14,6 +14,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/lex"
@@ -115,6 +116,10 @@ func (b *Builder) buildInsert(ins *memo.InsertExpr) (execPlan, error) {
 		return execPlan{}, err
 	}
 
+	if err := b.buildFKCascades(ins.WithID, ins.FKCascades); err != nil {
+		return execPlan{}, err
+	}
+
 	return ep, nil
 }
 
@@ -132,6 +137,11 @@ func (b *Builder) tryBuildFastPathInsert(ins *memo.InsertExpr) (_ execPlan, ok b
 		return execPlan{}, false, nil
 	}
 
+	//  - there are no AFTER triggers, which need to buffer the input;
+	if len(ins.FKCascades) > 0 {
+		return execPlan{}, false, nil
+	}
+
 	//  - the input is Values with at most InsertFastPathMaxRows, and there are no
 	//    subqueries;
 	values, ok := ins.Input.(*memo.ValuesExpr)
@@ -143,6 +153,8 @@ func (b *Builder) tryBuildFastPathInsert(ins *memo.InsertExpr) (_ execPlan, ok b
 	tab := md.Table(ins.Table)
 
 	//  - there are no self-referencing foreign keys;
+	//  - there are no deferrable foreign keys, whose checks may need to be
+	//    deferred until the end of the transaction;
 	//  - all FK checks can be performed using direct lookups into unique indexes.
 	fkChecks := make([]exec.InsertFastPathFKCheck, len(ins.Checks))
 	for i := range ins.Checks {
@@ -151,6 +163,9 @@ func (b *Builder) tryBuildFastPathInsert(ins *memo.InsertExpr) (_ execPlan, ok b
 			// Self-referencing FK.
 			return execPlan{}, false, nil
 		}
+		if c.Deferrable {
+			return execPlan{}, false, nil
+		}
 		fk := tab.OutboundForeignKey(c.FKOrdinal)
 		lookupJoin, isLookupJoin := c.Check.(*memo.LookupJoinExpr)
 		if !isLookupJoin || lookupJoin.JoinType != opt.AntiJoinOp {
@@ -502,6 +517,11 @@ func (b *Builder) tryBuildDeleteRange(del *memo.DeleteExpr) (_ execPlan, ok bool
 		return execPlan{}, false, nil
 	}
 
+	// Triggers need the values of the deleted rows.
+	if tab.TriggerCount() > 0 {
+		return execPlan{}, false, nil
+	}
+
 	primaryIdx := tab.Index(cat.PrimaryIndex)
 
 	// If the table is interleaved in another table, we cannot use the fast path.
@@ -557,6 +577,10 @@ func (b *Builder) tryBuildDeleteRangeOnInterleaving(
 		if currTab.DeletableIndexCount() > 1 {
 			return execPlan{}, false, nil
 		}
+		// Triggers must fire for each row deleted from the interleaved tables.
+		if currTab.TriggerCount() > 0 {
+			return execPlan{}, false, nil
+		}
 
 		currIdx := currTab.Index(cat.PrimaryIndex)
 		for i, n := 0, currIdx.InterleavedByCount(); i < n; i++ {
@@ -757,9 +781,43 @@ func (b *Builder) buildFKChecks(checks memo.FKChecksExpr) error {
 			for i, col := range c.KeyCols {
 				keyVals[i] = row[query.getNodeColumnOrdinal(col)]
 			}
+			if c.Unique {
+				return mkUniqueCheckErr(md, c, keyVals)
+			}
 			return mkFKCheckErr(md, c, keyVals)
 		}
-		node, err := b.factory.ConstructErrorIfRows(query.root, mkErr)
+		var deferrable *exec.DeferrableConstraint
+		if c.Unique {
+			tab := md.Table(c.OriginTable)
+			index := tab.Index(c.IndexOrdinal)
+			deferrable = &exec.DeferrableConstraint{
+				TableID:           tab.ID(),
+				Name:              string(index.Name()),
+				InitiallyDeferred: index.UniqueDeferrability() == tree.ConstraintInitiallyDeferred,
+				KeyCols:           make([]exec.NodeColumnOrdinal, len(c.KeyCols)),
+				Unique:            true,
+			}
+			for i, col := range c.KeyCols {
+				deferrable.KeyCols[i] = query.getNodeColumnOrdinal(col)
+			}
+		} else if c.Deferrable {
+			var fk cat.ForeignKeyConstraint
+			if c.FKOutbound {
+				fk = md.Table(c.OriginTable).OutboundForeignKey(c.FKOrdinal)
+			} else {
+				fk = md.Table(c.ReferencedTable).InboundForeignKey(c.FKOrdinal)
+			}
+			deferrable = &exec.DeferrableConstraint{
+				TableID:           fk.OriginTableID(),
+				Name:              fk.Name(),
+				InitiallyDeferred: fk.InitiallyDeferred(),
+				KeyCols:           make([]exec.NodeColumnOrdinal, len(c.KeyCols)),
+			}
+			for i, col := range c.KeyCols {
+				deferrable.KeyCols[i] = query.getNodeColumnOrdinal(col)
+			}
+		}
+		node, err := b.factory.ConstructErrorIfRows(query.root, mkErr, deferrable)
 		if err != nil {
 			return err
 		}
@@ -861,6 +919,27 @@ func mkFKCheckErr(md *opt.Metadata, c *memo.FKChecksItem, keyVals tree.Datums) e
 	)
 }
 
+// mkUniqueCheckErr generates a user-friendly error describing a violation of
+// a deferrable unique constraint. The keyVals are the values that correspond
+// to the columns of the constraint.
+func mkUniqueCheckErr(md *opt.Metadata, c *memo.FKChecksItem, keyVals tree.Datums) error {
+	tab := md.Table(c.OriginTable)
+	index := tab.Index(c.IndexOrdinal)
+	names := make([]string, len(keyVals))
+	values := make([]string, len(keyVals))
+	for i := range keyVals {
+		names[i] = string(index.Column(i).ColName())
+		values[i] = keyVals[i].String()
+	}
+	return pgerror.WithConstraintName(
+		pgerror.Newf(pgcode.UniqueViolation,
+			"duplicate key value (%s)=(%s) violates unique constraint %q",
+			strings.Join(names, ","), strings.Join(values, ","), index.Name(),
+		),
+		string(index.Name()),
+	)
+}
+
 func (b *Builder) buildFKCascades(withID opt.WithID, cascades memo.FKCascades) error {
 	if len(cascades) == 0 {
 		return nil
@@ -135,6 +135,7 @@ func (ep *execPlan) sqlOrdering(ordering opt.Ordering) colinfo.ColumnOrdering {
 		} else {
 			colOrder[i].Direction = encoding.Ascending
 		}
+		colOrder[i].NullsReversed = ordering[i].NullsReversed()
 	}
 
 	return colOrder
@@ -262,6 +263,9 @@ func (b *Builder) buildRelational(e memo.RelExpr) (execPlan, error) {
 	case *memo.CreateViewExpr:
 		ep, err = b.buildCreateView(t)
 
+	case *memo.CreateFunctionExpr:
+		ep, err = b.buildCreateFunction(t)
+
 	case *memo.WithExpr:
 		ep, err = b.buildWith(t)
 
@@ -1979,12 +1983,19 @@ func (b *Builder) buildWindow(w *memo.WindowExpr) (execPlan, error) {
 	orderingExprs := make(tree.OrderBy, len(ord))
 	for i, c := range ord {
 		direction := tree.Ascending
+		nullsOrder := tree.DefaultNullsOrder
 		if c.Descending() {
 			direction = tree.Descending
+			if c.NullsReversed() {
+				nullsOrder = tree.NullsFirst
+			}
+		} else if c.NullsReversed() {
+			nullsOrder = tree.NullsLast
 		}
 		orderingExprs[i] = &tree.Order{
-			Expr:      b.indexedVar(&ctx, b.mem.Metadata(), c.ID()),
-			Direction: direction,
+			Expr:       b.indexedVar(&ctx, b.mem.Metadata(), c.ID()),
+			Direction:  direction,
+			NullsOrder: nullsOrder,
 		}
 	}
 
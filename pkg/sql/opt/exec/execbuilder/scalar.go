@@ -336,11 +336,12 @@ func (b *Builder) buildCast(ctx *buildScalarCtx, scalar opt.ScalarExpr) (tree.Ty
 	if err != nil {
 		return nil, err
 	}
-	if cast.Typ.Family() == types.TupleFamily {
+	if cast.Typ.Family() == types.TupleFamily && !cast.Typ.IsComposite() {
 		// TODO(radu): casts to Tuple are not supported (they can't be serialized
 		// for distsql). This should only happen when the input is always NULL so
 		// the expression should still be valid without the cast (though there could
-		// be cornercases where the type does matter).
+		// be cornercases where the type does matter). Casts to composite types are
+		// kept, since they convert each element to the type of its field.
 		return input, nil
 	}
 	return tree.NewTypedCastExpr(input, cast.Typ), nil
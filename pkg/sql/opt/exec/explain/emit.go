@@ -247,6 +247,7 @@ var nodeNames = [...]string{
 	createTableOp:          "create table",
 	createTableAsOp:        "create table as",
 	createViewOp:           "create view",
+	createFunctionOp:       "create function",
 	deleteOp:               "delete",
 	deleteRangeOp:          "delete range",
 	distinctOp:             "distinct",
@@ -655,6 +656,7 @@ func (e *emitter) emitNodeAttributes(n *Node) error {
 		createTableOp,
 		createTableAsOp,
 		createViewOp,
+		createFunctionOp,
 		sequenceSelectOp,
 		saveTableOp,
 		errorIfRowsOp,
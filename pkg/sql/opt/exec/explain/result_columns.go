@@ -188,8 +188,8 @@ func getResultColumns(
 		}
 		return colinfo.ShowTraceColumns, nil
 
-	case createTableOp, createTableAsOp, createViewOp, controlJobsOp, controlSchedulesOp,
-		cancelQueriesOp, cancelSessionsOp, errorIfRowsOp, deleteRangeOp:
+	case createTableOp, createTableAsOp, createViewOp, createFunctionOp, controlJobsOp,
+		controlSchedulesOp, cancelQueriesOp, cancelSessionsOp, errorIfRowsOp, deleteRangeOp:
 		// These operations produce no columns.
 		return nil, nil
 
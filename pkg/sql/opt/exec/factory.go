@@ -200,9 +200,13 @@ type ApplyJoinPlanRightSideFn func(ef Factory, leftRow tree.Datums) (Plan, error
 // ConstructBuffer as an input; it should only be triggered if this buffer is
 // not empty.
 type Cascade struct {
-	// FKName is the name of the foreign key constraint.
+	// FKName is the name of the foreign key constraint, or the name of the
+	// trigger if Trigger is set.
 	FKName string
 
+	// Trigger is true if the cascade executes an AFTER trigger.
+	Trigger bool
+
 	// Buffer is the Node returned by ConstructBuffer which stores the input to
 	// the mutation.
 	Buffer Node
@@ -259,6 +263,26 @@ type InsertFastPathFKCheck struct {
 // relevant row.
 type MkErrFn func(tree.Datums) error
 
+// DeferrableConstraint identifies a deferrable foreign key or unique
+// constraint whose check may be deferred until the end of the transaction.
+type DeferrableConstraint struct {
+	// TableID is the ID of the origin table of the constraint, or of the table
+	// of a unique constraint.
+	TableID cat.StableID
+	// Name is the name of the constraint.
+	Name string
+	// InitiallyDeferred is true if the check is deferred unless the
+	// transaction specifies otherwise.
+	InitiallyDeferred bool
+	// KeyCols are the columns of the rows produced by the check that contain
+	// the values of the columns of the constraint, in the order of the
+	// constraint's columns.
+	KeyCols []NodeColumnOrdinal
+	// Unique is true if the constraint is a unique constraint, whose name is
+	// the name of the index of the table that enforces it.
+	Unique bool
+}
+
 // ExplainFactory is an extension of Factory used when constructing a plan that
 // can be explained. It allows annotation of nodes with extra information.
 type ExplainFactory interface {
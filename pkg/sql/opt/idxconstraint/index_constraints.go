@@ -46,6 +46,10 @@ func (c *indexConstraintCtx) unconstrained(offset int, out *constraint.Constrain
 //
 // If swap is true, the start and end key/boundary are swapped (this is provided
 // just for convenience, to avoid having two branches in every caller).
+//
+// The start and end keys are given as if NULL was ordered before all other
+// values of the column. If the NULL ordering of the column is reversed, the
+// span is adjusted accordingly; see reverseNullsSpan.
 func (c *indexConstraintCtx) singleSpan(
 	offset int,
 	start constraint.Key,
@@ -55,6 +59,10 @@ func (c *indexConstraintCtx) singleSpan(
 	swap bool,
 	out *constraint.Constraint,
 ) {
+	if c.columns[offset].NullsReversed() && c.isNullable(offset) {
+		c.reverseNullsSpan(offset, start, startBoundary, end, endBoundary, swap, out)
+		return
+	}
 	var span constraint.Span
 	if !swap {
 		span.Init(start, startBoundary, end, endBoundary)
@@ -66,6 +74,50 @@ func (c *indexConstraintCtx) singleSpan(
 	out.InitSingleSpan(keyCtx, &span)
 }
 
+// reverseNullsSpan creates a constraint for a span given as in singleSpan, on a
+// nullable column in which NULL is ordered after all other values:
+//
+//   (/NULL - /5]  becomes  [ - /5]
+//   (/NULL - ]    becomes  [ - /NULL)
+//   [/5 - ]       becomes  [/5 - /NULL)
+//   [ - /5)       becomes  [ - /5) [/NULL - /NULL]
+//
+// The last example is a span that includes NULL along with values from the
+// other end of the column; it results in two spans.
+func (c *indexConstraintCtx) reverseNullsSpan(
+	offset int,
+	start constraint.Key,
+	startBoundary constraint.SpanBoundary,
+	end constraint.Key,
+	endBoundary constraint.SpanBoundary,
+	swap bool,
+	out *constraint.Constraint,
+) {
+	nullKey := constraint.MakeKey(tree.DNull)
+	includesNull := start.IsEmpty() ||
+		(start.Value(0) == tree.DNull && startBoundary == includeBoundary)
+	if !start.IsEmpty() && start.Value(0) == tree.DNull {
+		start, startBoundary = emptyKey, includeBoundary
+	}
+	if end.IsEmpty() && !includesNull {
+		end, endBoundary = nullKey, excludeBoundary
+	}
+	var span constraint.Span
+	if !swap {
+		span.Init(start, startBoundary, end, endBoundary)
+	} else {
+		span.Init(end, endBoundary, start, startBoundary)
+	}
+	keyCtx := &c.keyCtx[offset]
+	span.PreferInclusive(keyCtx)
+	out.InitSingleSpan(keyCtx, &span)
+	if includesNull && !end.IsEmpty() {
+		var other constraint.Constraint
+		c.eqSpan(offset, tree.DNull, &other)
+		out.UnionWith(c.evalCtx, &other)
+	}
+}
+
 // eqSpan returns a span that constrains a column to a single value (which
 // can be DNull).
 func (c *indexConstraintCtx) eqSpan(offset int, value tree.Datum, out *constraint.Constraint) {
@@ -370,6 +422,16 @@ func (c *indexConstraintCtx) makeSpansForTupleInequality(
 			// [ - /1/2/2], [1/2/4 - ] apply for any combination of directions.
 			break
 		}
+		if i > 0 && c.columns[offset+i].NullsReversed() && c.isNullable(offset+i) &&
+			e.Op() != opt.NeOp {
+			// NULLs are ordered after all other values in this column, so a span
+			// on multiple columns could include unwanted NULLs. For example:
+			//   a ASCENDING, b ASCENDING NULLS LAST
+			//   (a, b) >= (1, 2)
+			// The span [/1/2 - ] would include (1, NULL). We can only use a >= 1
+			// here.
+			break
+		}
 		prefixLen++
 	}
 	if prefixLen == 0 {
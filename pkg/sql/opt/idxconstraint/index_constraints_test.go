@@ -44,7 +44,7 @@ import (
 //
 //       Sets the types for the index vars in the expression.
 //
-//     - index=(@<index> [ascending|asc|descending|desc] [not null], ...)
+//     - index=(@<index> [ascending|asc|descending|desc] [nulls first|last] [not null], ...)
 //
 //       Information for the index (used by index-constraints). Each column of the
 //       index refers to an index var.
@@ -265,7 +265,8 @@ func parseIndexColumns(
 		if err != nil {
 			tb.Fatal(err)
 		}
-		columns[i] = opt.MakeOrderingColumn(opt.ColumnID(id), false /* descending */)
+		descending := false
+		nullsOrder := ""
 		fields = fields[1:]
 		for len(fields) > 0 {
 			switch strings.ToLower(fields[0]) {
@@ -273,9 +274,16 @@ func parseIndexColumns(
 				// ascending is the default.
 				fields = fields[1:]
 			case "descending", "desc":
-				columns[i] = -columns[i]
+				descending = true
 				fields = fields[1:]
 
+			case "nulls":
+				if len(fields) < 2 {
+					tb.Fatalf("unknown column attribute %s", fields)
+				}
+				nullsOrder = strings.ToLower(fields[1])
+				fields = fields[2:]
+
 			case "not":
 				if len(fields) < 2 || strings.ToLower(fields[1]) != "null" {
 					tb.Fatalf("unknown column attribute %s", fields)
@@ -286,6 +294,8 @@ func parseIndexColumns(
 				tb.Fatalf("unknown column attribute %s", fields)
 			}
 		}
+		nullsReversed := (descending && nullsOrder == "first") || (!descending && nullsOrder == "last")
+		columns[i] = opt.MakeOrderingColumnWithNulls(opt.ColumnID(id), descending, nullsReversed)
 	}
 	return columns, notNullCols
 }
@@ -0,0 +1,286 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package invertedidx
+
+import (
+	"regexp/syntax"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/invertedexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/trigram"
+)
+
+// This file contains functions for building trigram inverted index scans that
+// are used throughout the xform package.
+
+// IsTrigramIndex returns true if the given index is a trigram index, which is
+// an inverted index on a string column.
+func IsTrigramIndex(index cat.Index) bool {
+	return index.IsInverted() && colinfo.ColumnTypeIsTrigramIndexable(index.Column(0).DatumType())
+}
+
+// TryConstrainTrigramIndex tries to derive an inverted filter condition for
+// the given trigram index from the specified filters. If a condition is
+// derived, it returns a SpanExpression and ok=true. The following conditions
+// can constrain the index:
+//
+//   s = 'string'
+//   s LIKE 'pattern'     s ILIKE 'pattern'
+//   s ~ 'regexp'         s ~* 'regexp'
+//   s % 'string'
+//
+// For equality, LIKE and regular expressions, the index is constrained to the
+// rows that have all of the trigrams of the literal fragments that every
+// matching string must contain. For %, it is constrained to the rows that
+// share at least one trigram with the given string. Trigram index scans are
+// never tight, so the original filters must always be applied to the results.
+func TryConstrainTrigramIndex(
+	filters memo.FiltersExpr, tabID opt.TableID, index cat.Index,
+) (spanExpr *invertedexpr.SpanExpression, ok bool) {
+	if !IsTrigramIndex(index) {
+		return nil, false
+	}
+	col := tabID.ColumnID(index.Column(0).InvertedSourceColumnOrdinal())
+
+	var invertedExpr invertedexpr.InvertedExpression
+	for i := range filters {
+		invertedExprLocal := constrainTrigramIndex(filters[i].Condition, col)
+		if invertedExpr == nil {
+			invertedExpr = invertedExprLocal
+		} else {
+			invertedExpr = invertedexpr.And(invertedExpr, invertedExprLocal)
+		}
+	}
+
+	if invertedExpr == nil {
+		return nil, false
+	}
+
+	spanExpr, ok = invertedExpr.(*invertedexpr.SpanExpression)
+	if !ok {
+		return nil, false
+	}
+	return spanExpr, true
+}
+
+// constrainTrigramIndex returns an InvertedExpression representing a
+// constraint of the trigram index on the given column, based on the given
+// expression. It returns NonInvertedColExpression if the expression cannot
+// constrain the index.
+func constrainTrigramIndex(expr opt.ScalarExpr, col opt.ColumnID) invertedexpr.InvertedExpression {
+	switch t := expr.(type) {
+	case *memo.AndExpr:
+		l := constrainTrigramIndex(t.Left, col)
+		r := constrainTrigramIndex(t.Right, col)
+		return invertedexpr.And(l, r)
+
+	case *memo.OrExpr:
+		l := constrainTrigramIndex(t.Left, col)
+		r := constrainTrigramIndex(t.Right, col)
+		return invertedexpr.Or(l, r)
+
+	case *memo.EqExpr:
+		s, ok := stringConstForCol(t.Left, t.Right, col)
+		if !ok {
+			s, ok = stringConstForCol(t.Right, t.Left, col)
+		}
+		if ok {
+			return allTrigramsExpr(trigram.MakeTrigrams(s))
+		}
+
+	case *memo.LikeExpr:
+		if pattern, ok := stringConstForCol(t.Left, t.Right, col); ok {
+			return allTrigramsExpr(trigramsForLikePattern(pattern))
+		}
+
+	case *memo.ILikeExpr:
+		if pattern, ok := stringConstForCol(t.Left, t.Right, col); ok {
+			return allTrigramsExpr(trigramsForLikePattern(pattern))
+		}
+
+	case *memo.RegMatchExpr:
+		if pattern, ok := stringConstForCol(t.Left, t.Right, col); ok {
+			return allTrigramsExpr(trigramsForRegexp(pattern))
+		}
+
+	case *memo.RegIMatchExpr:
+		if pattern, ok := stringConstForCol(t.Left, t.Right, col); ok {
+			return allTrigramsExpr(trigramsForRegexp(pattern))
+		}
+
+	case *memo.ModExpr:
+		// The % operator on strings is the similarity operator, which is
+		// commutative.
+		s, ok := stringConstForCol(t.Left, t.Right, col)
+		if !ok {
+			s, ok = stringConstForCol(t.Right, t.Left, col)
+		}
+		if ok {
+			return anyTrigramExpr(trigram.MakeTrigrams(s))
+		}
+	}
+	return invertedexpr.NonInvertedColExpression{}
+}
+
+// stringConstForCol returns the value of right and ok=true if left is a
+// reference to the given column and right is a constant string.
+func stringConstForCol(left, right opt.ScalarExpr, col opt.ColumnID) (string, bool) {
+	v, ok := left.(*memo.VariableExpr)
+	if !ok || v.Col != col {
+		return "", false
+	}
+	if !memo.CanExtractConstDatum(right) {
+		return "", false
+	}
+	s, ok := tree.AsDString(memo.ExtractConstDatum(right))
+	if !ok {
+		return "", false
+	}
+	return string(s), true
+}
+
+// trigramExpr returns a SpanExpression for the index entries of the given
+// trigram.
+func trigramExpr(t string) *invertedexpr.SpanExpression {
+	key := invertedexpr.EncInvertedVal(rowenc.EncodeTrigramInvertedIndexKey(nil, t))
+	return invertedexpr.ExprForInvertedSpan(
+		invertedexpr.MakeSingleInvertedValSpan(key), false, /* tight */
+	)
+}
+
+// allTrigramsExpr returns an InvertedExpression for the rows that have all of
+// the given trigrams.
+func allTrigramsExpr(trigrams []string) invertedexpr.InvertedExpression {
+	if len(trigrams) == 0 {
+		return invertedexpr.NonInvertedColExpression{}
+	}
+	var expr invertedexpr.InvertedExpression = trigramExpr(trigrams[0])
+	for _, t := range trigrams[1:] {
+		expr = invertedexpr.And(expr, trigramExpr(t))
+	}
+	return expr
+}
+
+// anyTrigramExpr returns an InvertedExpression for the rows that have at
+// least one of the given trigrams.
+func anyTrigramExpr(trigrams []string) invertedexpr.InvertedExpression {
+	if len(trigrams) == 0 {
+		return invertedexpr.NonInvertedColExpression{}
+	}
+	var expr invertedexpr.InvertedExpression = trigramExpr(trigrams[0])
+	for _, t := range trigrams[1:] {
+		expr = invertedexpr.Or(expr, trigramExpr(t))
+	}
+	return expr
+}
+
+// trigramsForLikePattern returns the trigrams that every string matching the
+// given LIKE pattern must have. The pattern uses the default escape character.
+func trigramsForLikePattern(pattern string) []string {
+	var res []string
+	var fragment strings.Builder
+	// The first fragment is at the start of the string, unless the pattern
+	// starts with a wildcard.
+	startsWord := true
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			fragment.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%' || r == '_':
+			res = append(res, trigram.MakeFragmentTrigrams(fragment.String(), startsWord, false /* endsWord */)...)
+			fragment.Reset()
+			startsWord = false
+		default:
+			fragment.WriteRune(r)
+		}
+	}
+	// The last fragment is at the end of the string, unless the pattern ends
+	// with a wildcard.
+	res = append(res, trigram.MakeFragmentTrigrams(fragment.String(), startsWord, true /* endsWord */)...)
+	return dedupTrigrams(res)
+}
+
+// trigramsForRegexp returns the trigrams that every string matching the given
+// regular expression must have.
+func trigramsForRegexp(pattern string) []string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		// The error is reported when the filter is evaluated.
+		return nil
+	}
+	var res []string
+	for _, fragment := range requiredRegexpLiterals(re.Simplify()) {
+		res = append(res, trigram.MakeFragmentTrigrams(fragment, false /* startsWord */, false /* endsWord */)...)
+	}
+	return dedupTrigrams(res)
+}
+
+// requiredRegexpLiterals returns literal strings that must occur in every
+// string matching the given regular expression. It does not try to find all
+// such literals; for example, it ignores alternations.
+func requiredRegexpLiterals(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{string(re.Rune)}
+
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredRegexpLiterals(re.Sub[0])
+
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			return requiredRegexpLiterals(re.Sub[0])
+		}
+
+	case syntax.OpConcat:
+		var res []string
+		var literal []rune
+		for _, sub := range re.Sub {
+			if sub.Op == syntax.OpLiteral {
+				literal = append(literal, sub.Rune...)
+				continue
+			}
+			if len(literal) > 0 {
+				res = append(res, string(literal))
+				literal = nil
+			}
+			res = append(res, requiredRegexpLiterals(sub)...)
+		}
+		if len(literal) > 0 {
+			res = append(res, string(literal))
+		}
+		return res
+	}
+	return nil
+}
+
+// dedupTrigrams sorts the given trigrams and removes duplicates.
+func dedupTrigrams(trigrams []string) []string {
+	sort.Strings(trigrams)
+	n := 0
+	for i := range trigrams {
+		if i == 0 || trigrams[i] != trigrams[i-1] {
+			trigrams[n] = trigrams[i]
+			n++
+		}
+	}
+	return trigrams[:n]
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package invertedidx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrigramsForLikePattern(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		expected []string
+	}{
+		{pattern: "%", expected: nil},
+		{pattern: "%ab%", expected: nil},
+		{pattern: "%qu_ck%", expected: nil},
+		{pattern: "%fox%", expected: []string{"fox"}},
+		{pattern: "fox%", expected: []string{"  f", " fo", "fox"}},
+		{pattern: "%fox", expected: []string{"fox", "ox "}},
+		{pattern: "fox", expected: []string{"  f", " fo", "fox", "ox "}},
+		{pattern: "%Foo%bar_", expected: []string{"bar", "foo"}},
+		{pattern: `%50\%%`, expected: []string{"50 "}},
+		{pattern: `%ab\_cd%`, expected: []string{"  c", " cd", "ab "}},
+		{pattern: `%abc\%def%`, expected: []string{"  d", " de", "abc", "bc ", "def"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			require.Equal(t, tc.expected, trigramsForLikePattern(tc.pattern))
+		})
+	}
+}
+
+func TestTrigramsForRegexp(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		expected []string
+	}{
+		{pattern: "(", expected: nil},
+		{pattern: "a|b", expected: nil},
+		{pattern: "abc|def", expected: nil},
+		{pattern: "abc", expected: []string{"abc"}},
+		{pattern: "^abc$", expected: []string{"abc"}},
+		{pattern: "abc(de)+f?", expected: []string{"abc"}},
+		{pattern: "qu+ick", expected: []string{"ick"}},
+		{pattern: `BROWN\s+fox`, expected: []string{"bro", "fox", "own", "row"}},
+		{pattern: "(abc){2,}", expected: []string{"abc"}},
+		{pattern: "(abc)*def", expected: []string{"def"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			require.Equal(t, tc.expected, trigramsForRegexp(tc.pattern))
+		})
+	}
+}
@@ -942,6 +942,12 @@ type FKCascade struct {
 	// new values of the modified rows. The list maps 1-to-1 to foreign key columns.
 	// It is empty if the mutation is a deletion.
 	NewValues opt.ColList
+
+	// Trigger is true if the cascading query executes an AFTER trigger rather
+	// than a foreign key action. In that case FKName is the name of the trigger,
+	// and OldValues and NewValues map 1-to-1 to the columns of the trigger row
+	// (either of which is empty if the mutation has no such row).
+	Trigger bool
 }
 
 // CascadeBuilder is an interface used to construct a cascading query for a
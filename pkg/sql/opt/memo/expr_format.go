@@ -207,7 +207,7 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 		*WindowExpr, *OpaqueRelExpr, *OpaqueMutationExpr, *OpaqueDDLExpr,
 		*AlterTableSplitExpr, *AlterTableUnsplitExpr, *AlterTableUnsplitAllExpr,
 		*AlterTableRelocateExpr, *ControlJobsExpr, *CancelQueriesExpr,
-		*CancelSessionsExpr, *CreateViewExpr, *ExportExpr:
+		*CancelSessionsExpr, *CreateViewExpr, *CreateFunctionExpr, *ExportExpr:
 		fmt.Fprintf(f.Buffer, "%v", e.Op())
 		FormatPrivate(f, e.Private(), required)
 
@@ -1001,6 +1001,20 @@ func (f *ExprFmtCtx) formatScalarPrivate(scalar opt.ScalarExpr) {
 
 	case *FKChecksItem:
 		origin := f.Memo.metadata.TableMeta(t.OriginTable)
+		if t.Unique {
+			// Print the unique constraint as:
+			//   t(a,b) unique
+			index := origin.Table.Index(t.IndexOrdinal)
+			fmt.Fprintf(f.Buffer, ": %s(", origin.Alias.ObjectName)
+			for i, n := 0, index.ExplicitColumnCount(); i < n; i++ {
+				if i > 0 {
+					f.Buffer.WriteByte(',')
+				}
+				f.Buffer.WriteString(string(index.Column(i).ColName()))
+			}
+			f.Buffer.WriteString(") unique")
+			break
+		}
 		referenced := f.Memo.metadata.TableMeta(t.ReferencedTable)
 		var fk cat.ForeignKeyConstraint
 		if t.FKOutbound {
@@ -1367,6 +1381,10 @@ func FormatPrivate(f *ExprFmtCtx, private interface{}, physProps *physical.Requi
 		schema := f.Memo.Metadata().Schema(t.Schema)
 		fmt.Fprintf(f.Buffer, " %s.%s", schema.Name(), t.ViewName)
 
+	case *CreateFunctionPrivate:
+		schema := f.Memo.Metadata().Schema(t.Schema)
+		fmt.Fprintf(f.Buffer, " %s.%s", schema.Name(), t.Syntax.Name.Object())
+
 	case *JoinPrivate:
 		// Nothing to show; flags are shown separately.
 
@@ -453,6 +453,7 @@ func (h *hasher) HashOrderingChoice(val physical.OrderingChoice) {
 		choice := &val.Columns[i]
 		h.HashColSet(choice.Group)
 		h.HashBool(choice.Descending)
+		h.HashBool(choice.NullsReversed)
 	}
 }
 
@@ -1394,6 +1394,12 @@ func (b *logicalPropsBuilder) buildCreateViewProps(cv *CreateViewExpr, rel *prop
 	BuildSharedProps(cv, &rel.Shared)
 }
 
+func (b *logicalPropsBuilder) buildCreateFunctionProps(
+	cf *CreateFunctionExpr, rel *props.Relational,
+) {
+	BuildSharedProps(cf, &rel.Shared)
+}
+
 func (b *logicalPropsBuilder) buildFiltersItemProps(item *FiltersItem, scalar *props.Scalar) {
 	BuildSharedProps(item.Condition, &scalar.Shared)
 
@@ -521,6 +521,11 @@ func (sb *statisticsBuilder) makeTableStatistics(tabID opt.TableID) *props.Stati
 		}
 		// The first column of inverted indexes is always virtual.
 		col := index.Column(0)
+		if colinfo.ColumnTypeIsTrigramIndexable(col.DatumType()) {
+			// Statistics are not collected on the keys of trigram indexes, so
+			// histograms on string columns always describe the column itself.
+			continue
+		}
 		srcOrd := col.InvertedSourceColumnOrdinal()
 		invIndexVirtualCols[srcOrd] = append(invIndexVirtualCols[srcOrd], col.Ordinal())
 	}
@@ -3970,14 +3975,14 @@ func (sb *statisticsBuilder) numConjunctsInConstraint(
 			// have the same estimated selectivity as /a: [/10 - ]. Selectivity
 			// of NULL constraints is handled in selectivityFromMultiColDistinctCounts,
 			// selectivityFromHistograms, and selectivityFromNullsRemoved.
-			if c.Columns.Get(nth).Descending() ||
+			if !c.Columns.Get(nth).NullsFirst() ||
 				span.StartKey().Value(nth) != tree.DNull {
 				numSpanConjuncts++
 			}
 		}
 		if span.EndKey().Length() > nth {
 			// Ignore cases of NULL in constraints. (see above comment).
-			if !c.Columns.Get(nth).Descending() ||
+			if c.Columns.Get(nth).NullsFirst() ||
 				span.EndKey().Value(nth) != tree.DNull {
 				numSpanConjuncts++
 			}
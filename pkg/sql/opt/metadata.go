@@ -107,6 +107,11 @@ type Metadata struct {
 	// we want to verify the resolution of both names.
 	deps []mdDep
 
+	// funcDeps stores information about all user-defined functions depended on
+	// by the query, as well as the privileges required to execute them. Like
+	// deps, any name/function pair shows up at most once.
+	funcDeps []mdFuncDep
+
 	// views stores the list of referenced views. This information is only
 	// needed for EXPLAIN (opt, env).
 	views []cat.View
@@ -130,6 +135,15 @@ type mdDep struct {
 	privileges privilegeBitmap
 }
 
+type mdFuncDep struct {
+	fn cat.Function
+
+	name MDDepName
+
+	// privileges is the union of all required privileges.
+	privileges privilegeBitmap
+}
+
 // MDDepName stores either the unresolved DataSourceName or the StableID from
 // the query that was used to resolve a data source.
 type MDDepName struct {
@@ -175,6 +189,11 @@ func (md *Metadata) Init() {
 	}
 	md.deps = md.deps[:0]
 
+	for i := range md.funcDeps {
+		md.funcDeps[i] = mdFuncDep{}
+	}
+	md.funcDeps = md.funcDeps[:0]
+
 	for i := range md.views {
 		md.views[i] = nil
 	}
@@ -194,8 +213,8 @@ func (md *Metadata) Init() {
 // the copy.
 func (md *Metadata) CopyFrom(from *Metadata) {
 	if len(md.schemas) != 0 || len(md.cols) != 0 || len(md.tables) != 0 ||
-		len(md.sequences) != 0 || len(md.deps) != 0 || len(md.views) != 0 ||
-		len(md.userDefinedTypes) != 0 || len(md.userDefinedTypesSlice) != 0 {
+		len(md.sequences) != 0 || len(md.deps) != 0 || len(md.funcDeps) != 0 ||
+		len(md.views) != 0 || len(md.userDefinedTypes) != 0 || len(md.userDefinedTypesSlice) != 0 {
 		panic(errors.AssertionFailedf("CopyFrom requires empty destination"))
 	}
 	md.schemas = append(md.schemas, from.schemas...)
@@ -221,6 +240,7 @@ func (md *Metadata) CopyFrom(from *Metadata) {
 
 	md.sequences = append(md.sequences, from.sequences...)
 	md.deps = append(md.deps, from.deps...)
+	md.funcDeps = append(md.funcDeps, from.funcDeps...)
 	md.views = append(md.views, from.views...)
 	md.currUniqueID = from.currUniqueID
 
@@ -229,12 +249,14 @@ func (md *Metadata) CopyFrom(from *Metadata) {
 }
 
 // DepByName is used with AddDependency when the data source was looked up using a
-// data source name.
+// data source name. It is also used with AddFunctionDependency when the function
+// was looked up by name.
 func DepByName(name *cat.DataSourceName) MDDepName {
 	return MDDepName{byName: *name}
 }
 
-// DepByID is used with AddDependency when the data source was looked up by ID.
+// DepByID is used with AddDependency when the data source was looked up by ID,
+// and with AddFunctionDependency when the function was looked up by ID.
 func DepByID(id cat.StableID) MDDepName {
 	return MDDepName{byID: id}
 }
@@ -259,6 +281,25 @@ func (md *Metadata) AddDependency(name MDDepName, ds cat.DataSource, priv privil
 	})
 }
 
+// AddFunctionDependency tracks one of the user-defined functions on which the
+// query depends, as well as the privilege required to execute it. It is the
+// equivalent of AddDependency for functions: CheckDependencies detects if the
+// name resolves to a different function now, or if the function was replaced
+// since the metadata was built.
+func (md *Metadata) AddFunctionDependency(name MDDepName, fn cat.Function, priv privilege.Kind) {
+	for i := range md.funcDeps {
+		if md.funcDeps[i].fn == fn && md.funcDeps[i].name.equals(&name) {
+			md.funcDeps[i].privileges |= (1 << priv)
+			return
+		}
+	}
+	md.funcDeps = append(md.funcDeps, mdFuncDep{
+		fn:         fn,
+		name:       name,
+		privileges: (1 << priv),
+	})
+}
+
 // CheckDependencies resolves (again) each data source on which this metadata
 // depends, in order to check that all data source names resolve to the same
 // objects, and that the user still has sufficient privileges to access the
@@ -291,20 +332,30 @@ func (md *Metadata) CheckDependencies(
 			return false, nil
 		}
 
-		for privs := md.deps[i].privileges; privs != 0; {
-			// Strip off each privilege bit and make call to CheckPrivilege for it.
-			// Note that priv == 0 can occur when a dependency was added with
-			// privilege.Kind = 0 (e.g. for a table within a view, where the table
-			// privileges do not need to be checked). Ignore the "zero privilege".
-			priv := privilege.Kind(bits.TrailingZeros32(uint32(privs)))
-			if priv != 0 {
-				if err := catalog.CheckPrivilege(ctx, toCheck, priv); err != nil {
-					return false, err
-				}
-			}
-
-			// Set the just-handled privilege bit to zero and look for next.
-			privs &= ^(1 << priv)
+		if err := checkPrivileges(ctx, catalog, toCheck, md.deps[i].privileges); err != nil {
+			return false, err
+		}
+	}
+	// Check that all of the user-defined functions still resolve to the same
+	// version of the same function.
+	for i := range md.funcDeps {
+		name := &md.funcDeps[i].name
+		var toCheck cat.Function
+		var err error
+		if name.byID != 0 {
+			toCheck, err = catalog.ResolveFunctionByID(ctx, cat.Flags{}, name.byID)
+		} else {
+			toCheck, err = catalog.ResolveFunction(ctx, cat.Flags{}, name.byName.ToUnresolvedObjectName())
+		}
+		if err != nil {
+			return false, err
+		}
+		// Handle when the function no longer exists.
+		if toCheck == nil || !toCheck.Equals(md.funcDeps[i].fn) {
+			return false, nil
+		}
+		if err := checkPrivileges(ctx, catalog, toCheck, md.funcDeps[i].privileges); err != nil {
+			return false, err
 		}
 	}
 	// Check that all of the user defined types present have not changed.
@@ -324,6 +375,29 @@ func (md *Metadata) CheckDependencies(
 	return true, nil
 }
 
+// checkPrivileges checks that the current user still has each of the given
+// privileges on the given object.
+func checkPrivileges(
+	ctx context.Context, catalog cat.Catalog, o cat.Object, privs privilegeBitmap,
+) error {
+	for privs != 0 {
+		// Strip off each privilege bit and make call to CheckPrivilege for it.
+		// Note that priv == 0 can occur when a dependency was added with
+		// privilege.Kind = 0 (e.g. for a table within a view, where the table
+		// privileges do not need to be checked). Ignore the "zero privilege".
+		priv := privilege.Kind(bits.TrailingZeros32(uint32(privs)))
+		if priv != 0 {
+			if err := catalog.CheckPrivilege(ctx, o, priv); err != nil {
+				return err
+			}
+		}
+
+		// Set the just-handled privilege bit to zero and look for next.
+		privs &= ^(1 << priv)
+	}
+	return nil
+}
+
 // AddSchema indexes a new reference to a schema used by the query.
 func (md *Metadata) AddSchema(sch cat.Schema) SchemaID {
 	md.schemas = append(md.schemas, sch)
@@ -379,6 +453,13 @@ func (md *Metadata) AddTable(tab cat.Table, alias *tree.TableName) TableID {
 		col := tab.Column(i)
 		colID := md.AddColumn(string(col.ColName()), col.DatumType())
 		md.ColumnMeta(colID).Table = tabID
+
+		// The constraints and default value of a DOMAIN are applied to the
+		// columns of that type, so the query depends on the version of the
+		// domain.
+		if col.DatumType().IsDomain() {
+			md.AddUserDefinedType(col.DatumType())
+		}
 	}
 
 	return tabID
@@ -13,6 +13,7 @@ package norm
 import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/errors"
@@ -216,6 +217,63 @@ func (c *CustomFuncs) CanInline(scalar opt.ScalarExpr) bool {
 	return false
 }
 
+// CanInlineVirtualCols returns true if all of the given projections that are
+// referenced by the filters compute virtual computed columns of a table, and at
+// least one of them is part of a secondary index or of a partial index
+// predicate. See the PushSelectIntoVirtualColumnProject rule.
+func (c *CustomFuncs) CanInlineVirtualCols(
+	projections memo.ProjectionsExpr, filters memo.FiltersExpr,
+) bool {
+	md := c.mem.Metadata()
+	filterCols := c.FilterOuterCols(filters)
+
+	var virtualCols opt.ColSet
+	var tabID opt.TableID
+	for i := range projections {
+		item := &projections[i]
+		if !filterCols.Contains(item.Col) {
+			continue
+		}
+		colTabID := md.ColumnMeta(item.Col).Table
+		if colTabID == 0 || (tabID != 0 && colTabID != tabID) {
+			return false
+		}
+		tabID = colTabID
+		tabMeta := md.TableMeta(tabID)
+
+		// The projection must be identical to the expression of the computed
+		// column, which is only available if the expression is immutable.
+		if expr, ok := tabMeta.ComputedCols[item.Col]; !ok || expr != item.Element {
+			return false
+		}
+		if !tabMeta.Table.Column(tabID.ColumnOrdinal(item.Col)).IsVirtualComputed() {
+			return false
+		}
+		virtualCols.Add(item.Col)
+	}
+	if virtualCols.Empty() {
+		return false
+	}
+
+	tabMeta := md.TableMeta(tabID)
+	tab := tabMeta.Table
+	for i, n := 0, tab.IndexCount(); i < n; i++ {
+		if i == cat.PrimaryIndex || tab.Index(i).IsInverted() {
+			continue
+		}
+		if tabMeta.IndexVirtualComputedColumns(i).Intersects(virtualCols) {
+			return true
+		}
+		if _, isPartialIndex := tab.Index(i).Predicate(); isPartialIndex {
+			pred := memo.PartialIndexPredicate(tabMeta, i)
+			if c.FilterOuterCols(pred).Intersects(virtualCols) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // InlineSelectProject searches the filter conditions for any variable
 // references to columns from the given projections expression. Each variable is
 // replaced by the corresponding inlined projection expression.
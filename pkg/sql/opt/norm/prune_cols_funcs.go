@@ -73,6 +73,10 @@ func (c *CustomFuncs) NeededMutationCols(
 			withUses := memo.WithUses(checks[i].Check)
 			cols.UnionWith(withUses[private.WithID].UsedCols)
 		}
+		for i := range private.FKCascades {
+			addCols(private.FKCascades[i].OldValues)
+			addCols(private.FKCascades[i].NewValues)
+		}
 	}
 
 	return cols
@@ -137,6 +141,19 @@ func neededMutationFetchCols(
 		}
 	}
 
+	// Retain any FetchCols that are passed to cascades, such as the OLD and NEW
+	// rows of AFTER triggers.
+	for i := range private.FKCascades {
+		var cascadeCols opt.ColSet
+		cascadeCols.UnionWith(private.FKCascades[i].OldValues.ToSet())
+		cascadeCols.UnionWith(private.FKCascades[i].NewValues.ToSet())
+		for ord, col := range private.FetchCols {
+			if col != 0 && cascadeCols.Contains(col) {
+				cols.Add(tabMeta.MetaID.ColumnID(ord))
+			}
+		}
+	}
+
 	switch op {
 	case opt.UpdateOp, opt.UpsertOp:
 		// Determine set of target table columns that need to be updated.
@@ -144,6 +161,12 @@ func neededMutationFetchCols(
 		for ord, col := range private.UpdateCols {
 			if col != 0 {
 				updateCols.Add(tabMeta.MetaID.ColumnID(ord))
+
+				// Virtual computed columns are not part of any family, but the
+				// row updater requires a FETCH column for each UPDATE column.
+				if tabMeta.Table.Column(ord).IsVirtualComputed() {
+					cols.Add(tabMeta.MetaID.ColumnID(ord))
+				}
 			}
 		}
 
@@ -108,6 +108,10 @@ type Builder struct {
 	// are disabled and certain statements (like mutations) are disallowed.
 	insideViewDef bool
 
+	// If set, we are processing the body of a function definition. This is
+	// always set together with insideViewDef.
+	insideFuncDef bool
+
 	// If set, we are collecting view dependencies in viewDeps. This can only
 	// happen inside view definitions.
 	//
@@ -127,6 +131,11 @@ type Builder struct {
 	// isCorrelated is set to true if we already reported to telemetry that the
 	// query contains a correlated subquery.
 	isCorrelated bool
+
+	// afterTriggerRows, if set, contains the OLD and NEW rows of the AFTER
+	// trigger whose body is being built (see afterTriggerBuilder). The input of
+	// the INSERT or DELETE statement in the body is joined with these rows.
+	afterTriggerRows *scope
 }
 
 // New creates a new Builder structure initialized with the given
@@ -252,7 +261,7 @@ func (b *Builder) buildStmt(
 		// A blocklist of statements that can't be used from inside a view.
 		switch stmt := stmt.(type) {
 		case *tree.Delete, *tree.Insert, *tree.Update, *tree.CreateTable, *tree.CreateView,
-			*tree.Split, *tree.Unsplit, *tree.Relocate,
+			*tree.CreateFunction, *tree.Split, *tree.Unsplit, *tree.Relocate,
 			*tree.ControlJobs, *tree.ControlSchedules, *tree.CancelQueries, *tree.CancelSessions:
 			panic(pgerror.Newf(
 				pgcode.Syntax, "%s cannot be used inside a view definition", stmt.StatementTag(),
@@ -288,6 +297,9 @@ func (b *Builder) buildStmt(
 	case *tree.CreateView:
 		return b.buildCreateView(stmt, inScope)
 
+	case *tree.CreateFunction:
+		return b.buildCreateFunction(stmt, inScope)
+
 	case *tree.Explain:
 		return b.buildExplain(stmt, inScope)
 
@@ -0,0 +1,302 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+func (b *Builder) buildCreateFunction(cf *tree.CreateFunction, inScope *scope) (outScope *scope) {
+	b.DisableMemoReuse = true
+	if cf.Language != "sql" {
+		panic(unimplemented.NewWithIssuef(17511, "language %q is not supported", cf.Language))
+	}
+	fnName := cf.Name.ToTableName()
+	sch, _ := b.resolveSchemaForCreate(&fnName)
+	schID := b.factory.Metadata().AddSchema(sch)
+
+	// Builtin functions are resolved before user-defined ones, so a function
+	// with the same name as a builtin could never be called.
+	if _, ok := tree.FunDefs[cf.Name.Object()]; ok {
+		panic(pgerror.Newf(pgcode.DuplicateFunction,
+			"function %s already exists as a built-in function", tree.ErrString(&fnName.ObjectName)))
+	}
+	if cf.ReturnsTrigger {
+		return b.buildCreateTriggerFunction(cf, schID)
+	}
+
+	// Resolve the types in the signature.
+	syntax := *cf
+	syntax.Params = make(tree.FuncParams, len(cf.Params))
+	paramNames := make([]tree.Name, len(cf.Params))
+	for i := range cf.Params {
+		name := funcParamColName(cf.Params[i].Name, i)
+		for j := 0; j < i; j++ {
+			if paramNames[j] == name {
+				panic(pgerror.Newf(pgcode.InvalidFunctionDefinition,
+					"parameter name %q used more than once", name))
+			}
+		}
+		paramNames[i] = name
+		syntax.Params[i] = tree.FuncParam{
+			Name: cf.Params[i].Name,
+			Type: b.resolveFuncSignatureType(cf.Params[i].Type),
+		}
+	}
+	var retTypes []*types.T
+	if cf.ReturnType != nil {
+		typ := b.resolveFuncReturnType(cf.ReturnType, cf.ReturnsSet)
+		syntax.ReturnType = typ
+		retTypes = []*types.T{typ}
+	} else {
+		syntax.TableColumns = make(tree.FuncParams, len(cf.TableColumns))
+		for i := range cf.TableColumns {
+			typ := b.resolveFuncSignatureType(cf.TableColumns[i].Type)
+			syntax.TableColumns[i] = tree.FuncParam{Name: cf.TableColumns[i].Name, Type: typ}
+			retTypes = append(retTypes, typ)
+		}
+	}
+
+	// Rewrite the positional parameter references in the body into references
+	// to the columns of the parameter scope below, so that the stored body
+	// only refers to parameters by name.
+	body := parseFuncBody(cf.Body)
+	fnTable := tree.MakeUnqualifiedTableName(tree.Name(cf.Name.Object()))
+	var paramErr error
+	fmtCtx := tree.NewFmtCtx(tree.FmtParsable)
+	fmtCtx.SetPlaceholderFormat(func(ctx *tree.FmtCtx, p *tree.Placeholder) {
+		if int(p.Idx) >= len(paramNames) {
+			if paramErr == nil {
+				paramErr = pgerror.Newf(pgcode.UndefinedParameter,
+					"there is no parameter $%d", p.Idx+1)
+			}
+			ctx.Printf("$%d", p.Idx+1)
+			return
+		}
+		ctx.FormatNode(tree.NewColumnItem(&fnTable, paramNames[p.Idx]))
+	})
+	fmtCtx.FormatNode(body)
+	rewritten := fmtCtx.CloseAndGetString()
+	if paramErr != nil {
+		panic(paramErr)
+	}
+	body = parseFuncBody(rewritten)
+
+	// We build the body to:
+	//  - check the statement semantically,
+	//  - get the fully resolved names into the AST, and
+	//  - collect the dependencies in b.viewDeps.
+	// The parameters are visible to the body as outer columns, qualified by
+	// the function name. The result is not otherwise used.
+	b.insideViewDef = true
+	b.insideFuncDef = true
+	b.trackViewDeps = true
+	b.qualifyDataSourceNamesInAST = true
+	defer func() {
+		b.insideViewDef = false
+		b.insideFuncDef = false
+		b.trackViewDeps = false
+		b.viewDeps = nil
+		b.qualifyDataSourceNamesInAST = false
+	}()
+
+	paramScope := inScope.push()
+	for i := range syntax.Params {
+		col := b.synthesizeColumn(
+			paramScope, string(paramNames[i]), syntax.Params[i].Type.(*types.T), nil /* expr */, nil, /* scalar */
+		)
+		col.table = fnTable
+	}
+
+	// Stable functions in the body must not be folded into constants, since
+	// the volatility of the body is checked below.
+	var defScope *scope
+	b.factory.FoldingControl().TemporarilyDisallowStableFolds(func() {
+		b.pushWithFrame()
+		defScope = b.buildStmtAtRoot(body, nil /* desiredTypes */, paramScope)
+		b.popWithFrame(defScope)
+	})
+
+	// Verify that the body produces the declared result.
+	p := defScope.makePhysicalProps().Presentation
+	if len(p) != len(retTypes) {
+		panic(errors.WithDetailf(
+			pgerror.Newf(pgcode.InvalidFunctionDefinition,
+				"return type mismatch in function declared to return %s", funcReturnTypeString(&syntax)),
+			"Function's final statement must return %d column(s), but returns %d.", len(retTypes), len(p),
+		))
+	}
+	for i := range p {
+		typ := b.factory.Metadata().ColumnMeta(p[i].ID).Type
+		if typ.Family() != types.UnknownFamily && !typ.Equivalent(retTypes[i]) {
+			panic(errors.WithDetailf(
+				pgerror.Newf(pgcode.InvalidFunctionDefinition,
+					"return type mismatch in function declared to return %s", funcReturnTypeString(&syntax)),
+				"Final statement returns %s instead of %s at column %d.",
+				typ.SQLString(), retTypes[i].SQLString(), i+1,
+			))
+		}
+	}
+
+	// Calls to the function are inlined with the declared volatility, so the
+	// body must not be more volatile than that.
+	declared := cf.Volatility
+	if declared == 0 {
+		declared = tree.VolatilityVolatile
+	}
+	vs := defScope.expr.Relational().VolatilitySet
+	if declared < tree.VolatilityVolatile && vs.HasVolatile() {
+		panic(pgerror.Newf(pgcode.InvalidFunctionDefinition,
+			"function declared %s cannot contain volatile expressions",
+			strings.ToUpper(declared.String())))
+	}
+	if declared < tree.VolatilityStable && vs.HasStable() {
+		panic(pgerror.Newf(pgcode.InvalidFunctionDefinition,
+			"function declared %s cannot contain stable expressions",
+			strings.ToUpper(declared.String())))
+	}
+	syntax.Body = tree.AsStringWithFlags(body, tree.FmtParsable)
+
+	outScope = b.allocScope()
+	outScope.expr = b.factory.ConstructCreateFunction(
+		&memo.CreateFunctionPrivate{
+			Schema: schID,
+			Syntax: &syntax,
+			Deps:   b.viewDeps,
+		},
+	)
+	return outScope
+}
+
+// buildCreateTriggerFunction builds a CREATE FUNCTION statement for a function
+// declared with RETURNS TRIGGER. The body of a trigger function references the
+// OLD and NEW rows of whichever table the function is attached to, so unlike
+// other functions it cannot be built until the trigger fires; it is only
+// parsed here, and its data sources are resolved when it is executed.
+func (b *Builder) buildCreateTriggerFunction(
+	cf *tree.CreateFunction, schID opt.SchemaID,
+) (outScope *scope) {
+	if len(cf.Params) > 0 {
+		panic(pgerror.New(pgcode.InvalidFunctionDefinition,
+			"trigger functions cannot have declared arguments"))
+	}
+	syntax := *cf
+	syntax.Body = tree.AsStringWithFlags(parseTriggerFuncBody(cf.Body), tree.FmtParsable)
+
+	outScope = b.allocScope()
+	outScope.expr = b.factory.ConstructCreateFunction(
+		&memo.CreateFunctionPrivate{
+			Schema: schID,
+			Syntax: &syntax,
+		},
+	)
+	return outScope
+}
+
+// parseTriggerFuncBody parses the body of a trigger function, which must be a
+// single SELECT, INSERT, UPSERT or DELETE statement.
+func parseTriggerFuncBody(body string) tree.Statement {
+	stmt, err := parser.ParseOne(body)
+	if err != nil {
+		panic(pgerror.Wrap(err, pgcode.InvalidFunctionDefinition, "invalid function body"))
+	}
+	switch stmt.AST.(type) {
+	case *tree.Select, *tree.Insert, *tree.Delete:
+		return stmt.AST
+	}
+	panic(unimplemented.NewWithIssuef(28296,
+		"%s statements are not supported in the body of a trigger function", stmt.AST.StatementTag()))
+}
+
+// resolveFuncSignatureType resolves a type in the signature of a function
+// being created.
+func (b *Builder) resolveFuncSignatureType(ref tree.ResolvableTypeReference) *types.T {
+	typ, err := tree.ResolveType(b.ctx, ref, b.semaCtx.GetTypeResolver())
+	if err != nil {
+		panic(err)
+	}
+	if typ.UserDefined() {
+		panic(unimplemented.NewWithIssuef(17511,
+			"user-defined types cannot be used in the signature of a function"))
+	}
+	return typ
+}
+
+// resolveFuncReturnType resolves the declared return type of a function.
+// Unlike the other types in a function signature, it may be a composite type,
+// as long as the function does not return a set.
+func (b *Builder) resolveFuncReturnType(
+	ref tree.ResolvableTypeReference, returnsSet bool,
+) *types.T {
+	typ, err := tree.ResolveType(b.ctx, ref, b.semaCtx.GetTypeResolver())
+	if err != nil {
+		panic(err)
+	}
+	if typ.IsComposite() && !returnsSet {
+		return typ
+	}
+	return b.resolveFuncSignatureType(typ)
+}
+
+// parseFuncBody parses the body of a SQL-language function, which must be a
+// single SELECT statement.
+func parseFuncBody(body string) *tree.Select {
+	stmt, err := parser.ParseOne(body)
+	if err != nil {
+		panic(pgerror.Wrap(err, pgcode.InvalidFunctionDefinition, "invalid function body"))
+	}
+	sel, ok := stmt.AST.(*tree.Select)
+	if !ok {
+		panic(unimplemented.NewWithIssuef(17511,
+			"%s statements are not supported in the body of a function", stmt.AST.StatementTag()))
+	}
+	return sel
+}
+
+// funcParamColName returns the name by which the ith parameter of a function
+// is referenced from its body. Unnamed parameters are named after their
+// position.
+func funcParamColName(name tree.Name, i int) tree.Name {
+	if name != "" {
+		return name
+	}
+	return tree.Name(fmt.Sprintf("$%d", i+1))
+}
+
+// funcReturnTypeString returns the declared return type of the function, for
+// use in error messages.
+func funcReturnTypeString(cf *tree.CreateFunction) string {
+	fmtCtx := tree.NewFmtCtx(tree.FmtSimple)
+	if cf.ReturnsTrigger {
+		fmtCtx.WriteString("TRIGGER")
+	} else if cf.ReturnType == nil {
+		fmtCtx.WriteString("TABLE (")
+		fmtCtx.FormatNode(&cf.TableColumns)
+		fmtCtx.WriteByte(')')
+	} else {
+		if cf.ReturnsSet {
+			fmtCtx.WriteString("SETOF ")
+		}
+		fmtCtx.FormatTypeReference(cf.ReturnType)
+	}
+	return fmtCtx.CloseAndGetString()
+}
@@ -74,8 +74,13 @@ func (b *Builder) buildDelete(del *tree.Delete, inScope *scope) (outScope *scope
 // buildDelete constructs a Delete operator, possibly wrapped by a Project
 // operator that corresponds to the given RETURNING clause.
 func (mb *mutationBuilder) buildDelete(returning tree.ReturningExprs) {
+	// Fire any BEFORE DELETE triggers, which may skip some of the rows.
+	mb.buildBeforeTriggers(tree.TriggerEventDelete)
+
 	mb.buildFKChecksAndCascadesForDelete()
 
+	mb.buildAfterTriggers(tree.TriggerEventDelete)
+
 	private := mb.makeMutationPrivate(returning != nil)
 	mb.outScope.expr = mb.b.factory.ConstructDelete(mb.outScope.expr, mb.checks, private)
 
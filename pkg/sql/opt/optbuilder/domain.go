@@ -0,0 +1,130 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// buildDomainCast builds a cast of the given scalar to a DOMAIN type. The
+// constraints of the domain are enforced on the result of the cast, which is
+// wrapped in a CASE expression that raises an error for the first constraint
+// that the value violates:
+//
+//   CASE
+//     WHEN v IS NULL THEN crdb_internal.force_error(...)
+//     WHEN NOT (<check expression with VALUE replaced by v>) THEN crdb_internal.force_error(...)
+//     ELSE v
+//   END
+//
+// Like CHECK constraints of tables, the CHECK constraints of a domain are
+// satisfied if they evaluate to NULL.
+func (b *Builder) buildDomainCast(
+	arg opt.ScalarExpr, domain *types.T, inScope *scope, colRefs *opt.ColSet,
+) opt.ScalarExpr {
+	val := b.factory.ConstructCast(arg, domain)
+	data := domain.TypeMeta.DomainData
+	if data == nil || len(data.Constraints) == 0 {
+		return val
+	}
+
+	props, overloads := builtins.GetBuiltinProperties("crdb_internal.force_error")
+	whens := make(memo.ScalarListExpr, 0, len(data.Constraints))
+	for i := range data.Constraints {
+		c := &data.Constraints[i]
+		var cond opt.ScalarExpr
+		var violation error
+		if c.NotNull {
+			cond = b.factory.ConstructIs(val, memo.NullSingleton)
+			violation = schemaexpr.DomainNotNullViolationError(domain.Name())
+		} else {
+			expr, err := schemaexpr.ParseDomainCheckExpr(c.CheckExpr, &domainValue{typ: data.BaseType, scalar: val})
+			if err != nil {
+				panic(err)
+			}
+			texpr, err := tree.TypeCheck(b.ctx, expr, b.semaCtx, types.Bool)
+			if err != nil {
+				panic(err)
+			}
+			cond = b.factory.ConstructNot(b.buildScalar(texpr, inScope, nil, nil, colRefs))
+			violation = schemaexpr.DomainCheckViolationError(domain.Name(), c.Name)
+		}
+
+		// The error is raised by a function that has the type of the domain, so
+		// that all the branches of the CASE have the same type. The function
+		// never returns a value.
+		raise := b.factory.ConstructFunction(
+			memo.ScalarListExpr{
+				b.factory.ConstructConstVal(tree.NewDString(pgerror.GetPGCode(violation).String()), types.String),
+				b.factory.ConstructConstVal(tree.NewDString(violation.Error()), types.String),
+			},
+			&memo.FunctionPrivate{
+				Name:       "crdb_internal.force_error",
+				Typ:        domain,
+				Properties: props,
+				Overload:   &overloads[0],
+			},
+		)
+		whens = append(whens, b.factory.ConstructWhen(cond, raise))
+	}
+	return b.factory.ConstructCase(memo.TrueSingleton, whens, val)
+}
+
+// domainValue stands for the value being cast to a DOMAIN in the CHECK
+// constraint expressions of the domain. It is built as the scalar of the
+// cast.
+type domainValue struct {
+	typ    *types.T
+	scalar opt.ScalarExpr
+}
+
+var _ tree.TypedExpr = &domainValue{}
+
+// String implements the Stringer interface.
+func (v *domainValue) String() string {
+	return tree.AsString(v)
+}
+
+// Format implements the NodeFormatter interface.
+func (v *domainValue) Format(ctx *tree.FmtCtx) {
+	ctx.WriteString("value")
+}
+
+// Walk implements the Expr interface.
+func (v *domainValue) Walk(_ tree.Visitor) tree.Expr {
+	return v
+}
+
+// TypeCheck implements the Expr interface.
+func (v *domainValue) TypeCheck(
+	_ context.Context, _ *tree.SemaContext, _ *types.T,
+) (tree.TypedExpr, error) {
+	return v, nil
+}
+
+// Eval implements the TypedExpr interface.
+func (*domainValue) Eval(_ *tree.EvalContext) (tree.Datum, error) {
+	return nil, errors.AssertionFailedf("domainValue must be replaced before evaluation")
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (v *domainValue) ResolvedType() *types.T {
+	return v.typ
+}
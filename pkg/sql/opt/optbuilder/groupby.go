@@ -45,6 +45,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/errors"
 )
 
@@ -100,6 +101,24 @@ type groupby struct {
 	// It is used to ensure that the builder does not throw a grouping error
 	// prematurely.
 	buildingGroupingCols bool
+
+	// groupingSets contains the grouping sets of a GROUP BY clause with ROLLUP,
+	// CUBE or GROUPING SETS, as sets of ordinals of the grouping columns. It is
+	// nil if there are no such elements, in which case there is a single
+	// grouping set with all the grouping columns.
+	groupingSets []util.FastIntSet
+
+	// groupingSetCols is set if there are several grouping sets. It maps each
+	// grouping column which is missing from some of the grouping sets to the
+	// aggOutScope column that the aggregation produces in its place. That
+	// column is NULL in the rows of the grouping sets that don't contain the
+	// grouping column. See constructGroupingSetsInput.
+	groupingSetCols map[opt.ColumnID]opt.ColumnID
+
+	// groupingIDCol is set if there are several grouping sets. It is a grouping
+	// column of the aggregation which contains the ordinal in groupingSets of
+	// the grouping set of each row.
+	groupingIDCol opt.ColumnID
 }
 
 // groupByStrSet is a set of stringified GROUP BY expressions that map to the
@@ -134,6 +153,22 @@ func (g *groupby) groupingCols() []scopeColumn {
 	return g.aggInScope.cols[len(g.aggInScope.cols)-len(g.groupStrs):]
 }
 
+// groupingColOrdinal returns the ordinal of the given grouping column within
+// groupingCols(). The column can also be the aggOutScope column which replaces
+// the grouping column when there are several grouping sets.
+func (g *groupby) groupingColOrdinal(id opt.ColumnID) int {
+	cols := g.groupingCols()
+	for i := range cols {
+		if cols[i].id == id {
+			return i
+		}
+		if outCol, ok := g.groupingSetCols[cols[i].id]; ok && outCol == id {
+			return i
+		}
+	}
+	panic(errors.AssertionFailedf("column %d is not a grouping column", id))
+}
+
 // getAggregateArgCols returns the columns in the aggInScope corresponding to
 // arguments to aggregate functions. If the aggregate has a filter, the column
 // corresponding to the filter's input will immediately follow the arguments.
@@ -334,21 +369,269 @@ func (b *Builder) buildGroupingColumns(sel *tree.SelectClause, projectionsScope,
 	b.buildGroupingList(sel.GroupBy, sel.Exprs, projectionsScope, fromScope)
 
 	// Copy the grouping columns to the aggOutScope.
-	g.aggOutScope.appendColumns(g.groupingCols())
+	if len(g.groupingSets) > 1 {
+		b.buildGroupingSetCols(g)
+	} else {
+		g.aggOutScope.appendColumns(g.groupingCols())
+	}
 }
 
-// buildAggregation builds the aggregation operators and constructs the
-// GroupBy expression. Returns the output scope for the aggregation operation.
-func (b *Builder) buildAggregation(having opt.ScalarExpr, fromScope *scope) (outScope *scope) {
-	g := fromScope.groupby
+// buildGroupingSetCols adds the grouping columns of an aggregation with
+// several grouping sets to the aggOutScope. The grouping columns which are
+// missing from some of the grouping sets are replaced by new columns, and the
+// GROUP BY expressions are remapped to the new columns so that the SELECT,
+// HAVING and ORDER BY expressions refer to them.
+func (b *Builder) buildGroupingSetCols(g *groupby) {
+	inAllSets := g.groupingSets[0].Copy()
+	for _, set := range g.groupingSets[1:] {
+		inAllSets.IntersectionWith(set)
+	}
 
-	groupingCols := g.groupingCols()
+	cols := g.groupingCols()
+	g.groupingSetCols = make(map[opt.ColumnID]opt.ColumnID, len(cols)-inAllSets.Len())
+	for i := range cols {
+		col := &cols[i]
+		if inAllSets.Contains(i) {
+			g.aggOutScope.appendColumn(col)
+			continue
+		}
+		outCol := b.synthesizeColumn(g.aggOutScope, string(col.name), col.typ, col.expr, nil /* scalar */)
+		g.groupingSetCols[col.id] = outCol.id
+		for exprStr, groupingCol := range g.groupStrs {
+			if groupingCol.id == col.id {
+				g.groupStrs[exprStr] = outCol
+			}
+		}
+	}
+	g.groupingIDCol = b.factory.Metadata().AddColumn("grouping_id", types.Int)
+}
 
-	// Build ColSet of grouping columns.
+// groupingColSet returns the grouping columns of the aggregation. When there
+// are several grouping sets, these are the columns that replace the grouping
+// columns which are missing from some of the grouping sets, as well as
+// groupingIDCol.
+func (g *groupby) groupingColSet() opt.ColSet {
 	var groupingColSet opt.ColSet
+	groupingCols := g.groupingCols()
 	for i := range groupingCols {
-		groupingColSet.Add(groupingCols[i].id)
+		id := groupingCols[i].id
+		if outCol, ok := g.groupingSetCols[id]; ok {
+			id = outCol
+		}
+		groupingColSet.Add(id)
 	}
+	if g.groupingIDCol != 0 {
+		groupingColSet.Add(g.groupingIDCol)
+	}
+	return groupingColSet
+}
+
+// constructGroupingSetsInput constructs the input of an aggregation with
+// several grouping sets, given the pre-projection and the grouping columns it
+// produces. Each row of the
+// pre-projection is repeated once for every grouping set, by joining it with a
+// Values expression that produces the ordinal of each grouping set in
+// groupingIDCol. The grouping columns that are missing from the grouping set
+// of a row are then replaced by NULL. For example:
+//
+//   SELECT b, c, sum(a) FROM abcd GROUP BY ROLLUP (b, c)
+//
+// has the grouping sets (b, c), (b) and (), and is built as:
+//
+//   group-by
+//    ├── grouping columns: b:7 c:8 grouping_id:9
+//    ├── project
+//    │    ├── inner-join (cross)
+//    │    │    ├── project
+//    │    │    │    └── scan abcd
+//    │    │    ├── values
+//    │    │    │    ├── (0,)
+//    │    │    │    ├── (1,)
+//    │    │    │    └── (2,)
+//    │    │    └── filters (true)
+//    │    └── projections
+//    │         ├── CASE grouping_id:9 WHEN 2 THEN NULL ELSE abcd.b:2 END [as=b:7]
+//    │         └── CASE grouping_id:9 WHEN 1 THEN NULL WHEN 2 THEN NULL ELSE abcd.c:3 END [as=c:8]
+//    └── aggregations
+//         └── sum [as=sum:6]
+//              └── a:1
+//
+// Since the grouping set is part of the grouping columns, the rows of
+// different grouping sets are never aggregated together, even if two grouping
+// sets are identical.
+//
+// An empty grouping set must produce a row even if the input is empty, which
+// the aggregation does not do since it is not a scalar aggregation. See
+// constructEmptyGroupingSets.
+func (b *Builder) constructGroupingSetsInput(
+	input memo.RelExpr, groupingCols []scopeColumn, g *groupby,
+) memo.RelExpr {
+	ordinal := func(i int) opt.ScalarExpr {
+		return b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(i)), types.Int)
+	}
+
+	rows := make(memo.ScalarListExpr, len(g.groupingSets))
+	tupleTyp := types.MakeTuple([]*types.T{types.Int})
+	for i := range g.groupingSets {
+		rows[i] = b.factory.ConstructTuple(memo.ScalarListExpr{ordinal(i)}, tupleTyp)
+	}
+	values := b.factory.ConstructValues(rows, &memo.ValuesPrivate{
+		Cols: opt.ColList{g.groupingIDCol},
+		ID:   b.factory.Metadata().NextUniqueID(),
+	})
+	join := b.factory.ConstructInnerJoin(input, values, memo.TrueFilter, memo.EmptyJoinPrivate)
+
+	groupingID := b.factory.ConstructVariable(g.groupingIDCol)
+	projections := make(memo.ProjectionsExpr, 0, len(g.groupingSetCols))
+	for i := range groupingCols {
+		col := &groupingCols[i]
+		outCol, ok := g.groupingSetCols[col.id]
+		if !ok {
+			continue
+		}
+		var whens memo.ScalarListExpr
+		for j, set := range g.groupingSets {
+			if !set.Contains(i) {
+				whens = append(whens, b.factory.ConstructWhen(ordinal(j), b.factory.ConstructNull(col.typ)))
+			}
+		}
+		projections = append(projections, b.factory.ConstructProjectionsItem(
+			b.factory.ConstructCase(groupingID, whens, b.factory.ConstructVariable(col.id)),
+			outCol,
+		))
+	}
+	return b.factory.ConstructProject(join, projections, join.Relational().OutputCols)
+}
+
+// constructEmptyGroupingSets adds the rows of the empty grouping sets to an
+// aggregation with several grouping sets when its input is empty. As in a
+// scalar aggregation, each empty grouping set produces a single row in that
+// case, in which the grouping columns and the aggregates are NULL (except for
+// count, which is 0). The aggregation is bound to a With expression, and is
+// unioned with a branch which produces those rows only if the aggregation
+// produces no row. For example:
+//
+//   SELECT b, count(*) FROM abcd GROUP BY ROLLUP (b)
+//
+// is built as:
+//
+//   with &1
+//    ├── group-by
+//    │    └── ...
+//    └── union-all
+//         ├── with-scan &1
+//         └── project
+//              ├── inner-join (cross)
+//              │    ├── select
+//              │    │    ├── scalar-group-by
+//              │    │    │    ├── with-scan &1
+//              │    │    │    └── aggregations
+//              │    │    │         └── count-rows
+//              │    │    └── filters
+//              │    │         └── count_rows = 0
+//              │    ├── values
+//              │    │    └── (1,)
+//              │    └── filters (true)
+//              └── projections
+//                   ├── NULL [as=b:8]
+//                   └── 0 [as=count_rows:9]
+//
+// If there is no empty grouping set, the aggregation is returned unchanged.
+func (b *Builder) constructEmptyGroupingSets(agg memo.RelExpr, g *groupby) memo.RelExpr {
+	tupleTyp := types.MakeTuple([]*types.T{types.Int})
+	var rows memo.ScalarListExpr
+	for i, set := range g.groupingSets {
+		if set.Empty() {
+			ordinal := b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(i)), types.Int)
+			rows = append(rows, b.factory.ConstructTuple(memo.ScalarListExpr{ordinal}, tupleTyp))
+		}
+	}
+	if len(rows) == 0 {
+		return agg
+	}
+
+	md := b.factory.Metadata()
+	withID := b.factory.Memo().NextWithID()
+	md.AddWithBinding(withID, agg)
+
+	aggCols := opt.ColSetToList(agg.Relational().OutputCols)
+	newCols := func() opt.ColList {
+		cols := make(opt.ColList, len(aggCols))
+		for i, id := range aggCols {
+			col := md.ColumnMeta(id)
+			cols[i] = md.AddColumn(col.Alias, col.Type)
+		}
+		return cols
+	}
+	withScan := func(outCols opt.ColList) memo.RelExpr {
+		return b.factory.ConstructWithScan(&memo.WithScanPrivate{
+			With:    withID,
+			InCols:  aggCols,
+			OutCols: outCols,
+			ID:      md.NextUniqueID(),
+		})
+	}
+
+	// The scalar aggregation produces a row only if the aggregation is empty.
+	countCol := md.AddColumn("count_rows", types.Int)
+	count := b.factory.ConstructScalarGroupBy(
+		withScan(newCols()),
+		memo.AggregationsExpr{
+			b.factory.ConstructAggregationsItem(b.factory.ConstructCountRows(), countCol),
+		},
+		&memo.GroupingPrivate{},
+	)
+	empty := b.factory.ConstructSelect(count, memo.FiltersExpr{b.factory.ConstructFiltersItem(
+		b.factory.ConstructEq(
+			b.factory.ConstructVariable(countCol),
+			b.factory.ConstructConstVal(tree.NewDInt(0), types.Int),
+		),
+	)})
+	groupingIDCol := md.AddColumn("grouping_id", types.Int)
+	values := b.factory.ConstructValues(rows, &memo.ValuesPrivate{
+		Cols: opt.ColList{groupingIDCol},
+		ID:   md.NextUniqueID(),
+	})
+	join := b.factory.ConstructInnerJoin(empty, values, memo.TrueFilter, memo.EmptyJoinPrivate)
+
+	defaults := make(map[opt.ColumnID]opt.ScalarExpr)
+	for i := range g.aggs {
+		if val, ok := b.overrideDefaultNullValue(g.aggs[i]); ok {
+			defaults[g.aggs[i].col.id] = val
+		}
+	}
+	rightCols := make(opt.ColList, len(aggCols))
+	projections := make(memo.ProjectionsExpr, 0, len(aggCols))
+	for i, id := range aggCols {
+		if id == g.groupingIDCol {
+			rightCols[i] = groupingIDCol
+			continue
+		}
+		col := md.ColumnMeta(id)
+		val, ok := defaults[id]
+		if !ok {
+			val = b.factory.ConstructNull(col.Type)
+		}
+		rightCols[i] = md.AddColumn(col.Alias, col.Type)
+		projections = append(projections, b.factory.ConstructProjectionsItem(val, rightCols[i]))
+	}
+	right := b.factory.ConstructProject(join, projections, opt.MakeColSet(groupingIDCol))
+
+	leftCols := newCols()
+	union := b.factory.ConstructUnionAll(withScan(leftCols), right, &memo.SetPrivate{
+		LeftCols:  leftCols,
+		RightCols: rightCols,
+		OutCols:   aggCols,
+	})
+	return b.factory.ConstructWith(agg, union, &memo.WithPrivate{ID: withID})
+}
+
+// buildAggregation builds the aggregation operators and constructs the
+// GroupBy expression. Returns the output scope for the aggregation operation.
+func (b *Builder) buildAggregation(having opt.ScalarExpr, fromScope *scope) (outScope *scope) {
+	g := fromScope.groupby
+
+	groupingColSet := g.groupingColSet()
 
 	// If there are any aggregates that are ordering sensitive, build the
 	// aggregations as window functions over each group.
@@ -429,12 +712,20 @@ func (b *Builder) buildAggregation(having opt.ScalarExpr, fromScope *scope) (out
 	// aggregate arguments, as well as any additional order by columns.
 	b.constructProjectForScope(fromScope, g.aggInScope)
 
+	input := g.aggInScope.expr.(memo.RelExpr)
+	if g.groupingSetCols != nil {
+		input = b.constructGroupingSetsInput(input, g.groupingCols(), g)
+	}
+
 	g.aggOutScope.expr = b.constructGroupBy(
-		g.aggInScope.expr.(memo.RelExpr),
+		input,
 		groupingColSet,
 		aggCols,
 		g.aggInScope.ordering,
 	)
+	if g.groupingSetCols != nil {
+		g.aggOutScope.expr = b.constructEmptyGroupingSets(g.aggOutScope.expr.(memo.RelExpr), g)
+	}
 
 	// Wrap with having filter if it exists.
 	if having != nil {
@@ -506,15 +797,105 @@ func (b *Builder) buildGroupingList(
 	// used in an aggregate function`. The builder cannot know whether there is
 	// a grouping error until the grouping columns are fully built.
 	g.buildingGroupingCols = true
+	hasGroupingSets := false
+	sets := []util.FastIntSet{{}}
 	for _, e := range groupBy {
-		b.buildGrouping(e, selects, projectionsScope, fromScope, g.aggInScope)
+		if _, ok := e.(*tree.GroupingSet); ok {
+			hasGroupingSets = true
+		}
+		// The grouping sets of the GROUP BY clause are the cross product of the
+		// grouping sets of its elements.
+		elemSets := b.buildGroupingSets(e, selects, projectionsScope, fromScope)
+		newSets := make([]util.FastIntSet, 0, len(sets)*len(elemSets))
+		for _, set := range sets {
+			for _, elemSet := range elemSets {
+				newSets = append(newSets, set.Union(elemSet))
+			}
+		}
+		sets = newSets
+		if len(sets) > maxGroupingSets {
+			panic(pgerror.Newf(pgcode.StatementTooComplex,
+				"too many grouping sets present (maximum %d)", maxGroupingSets))
+		}
 	}
 	g.buildingGroupingCols = false
+	if hasGroupingSets {
+		g.groupingSets = sets
+	}
+}
+
+// maxGroupingSets is the maximum number of grouping sets of a GROUP BY clause.
+const maxGroupingSets = 4096
+
+// maxCubeElements is the maximum number of elements of a CUBE.
+const maxCubeElements = 12
+
+// buildGroupingSets builds the grouping columns of an element of a GROUP BY
+// clause, and returns the grouping sets that the element stands for, as sets
+// of ordinals of grouping columns. An expression stands for a single grouping
+// set, while ROLLUP, CUBE and GROUPING SETS elements stand for several:
+//
+//   ROLLUP (a, b, c)           => (a, b, c), (a, b), (a), ()
+//   CUBE (a, b)                => (a, b), (a), (b), ()
+//   GROUPING SETS (a, (b, c))  => (a), (b, c)
+//
+func (b *Builder) buildGroupingSets(
+	groupBy tree.Expr, selects tree.SelectExprs, projectionsScope, fromScope *scope,
+) []util.FastIntSet {
+	aggInScope := fromScope.groupby.aggInScope
+	gs, ok := groupBy.(*tree.GroupingSet)
+	if !ok {
+		return []util.FastIntSet{b.buildGrouping(groupBy, selects, projectionsScope, fromScope, aggInScope)}
+	}
+
+	if gs.Type == tree.ListGroupingSet {
+		var sets []util.FastIntSet
+		for _, e := range gs.Exprs {
+			sets = append(sets, b.buildGroupingSets(e, selects, projectionsScope, fromScope)...)
+			if len(sets) > maxGroupingSets {
+				panic(pgerror.Newf(pgcode.StatementTooComplex,
+					"too many grouping sets present (maximum %d)", maxGroupingSets))
+			}
+		}
+		return sets
+	}
+
+	elems := make([]util.FastIntSet, len(gs.Exprs))
+	for i, e := range gs.Exprs {
+		elems[i] = b.buildGrouping(e, selects, projectionsScope, fromScope, aggInScope)
+	}
+
+	if gs.Type == tree.RollupGroupingSet {
+		// Each grouping set is the union of the first i elements, from i=n down
+		// to i=0.
+		sets := make([]util.FastIntSet, len(elems)+1)
+		for i := range elems {
+			sets[len(elems)-1-i] = sets[len(elems)-i].Union(elems[i])
+		}
+		return sets
+	}
+
+	if len(elems) > maxCubeElements {
+		panic(pgerror.Newf(pgcode.ProgramLimitExceeded,
+			"CUBE is limited to %d elements", maxCubeElements))
+	}
+	sets := make([]util.FastIntSet, 0, 1<<len(elems))
+	for mask := 1<<len(elems) - 1; mask >= 0; mask-- {
+		var set util.FastIntSet
+		for i := range elems {
+			if mask&(1<<(len(elems)-1-i)) != 0 {
+				set.UnionWith(elems[i])
+			}
+		}
+		sets = append(sets, set)
+	}
+	return sets
 }
 
 // buildGrouping builds a set of memo groups that represent a GROUP BY
 // expression. The expression (or expressions, if we have a star) is added to
-// groupStrs and to the aggInScope.
+// groupStrs and to the aggInScope. Returns the ordinals of the grouping columns
+// of the expression.
 //
 //
 // groupBy          The given GROUP BY expression.
@@ -528,7 +909,7 @@ func (b *Builder) buildGroupingList(
 //                  as the aggregate function arguments.
 func (b *Builder) buildGrouping(
 	groupBy tree.Expr, selects tree.SelectExprs, projectionsScope, fromScope, aggInScope *scope,
-) {
+) util.FastIntSet {
 	// Unwrap parenthesized expressions like "((a))" to "a".
 	groupBy = tree.StripParens(groupBy)
 	alias := ""
@@ -622,11 +1003,14 @@ func (b *Builder) buildGrouping(
 	exprs = flattenTuples(exprs)
 
 	// Finally, build each of the GROUP BY columns.
+	g := fromScope.groupby
+	var ordinals util.FastIntSet
 	for _, e := range exprs {
 		// If a grouping column has already been added, don't add it again.
 		// GROUP BY a, a is semantically equivalent to GROUP BY a.
 		exprStr := symbolicExprStr(e)
-		if _, ok := fromScope.groupby.groupStrs[exprStr]; ok {
+		if col, ok := g.groupStrs[exprStr]; ok {
+			ordinals.Add(g.groupingColOrdinal(col.id))
 			continue
 		}
 
@@ -635,10 +1019,73 @@ func (b *Builder) buildGrouping(
 		//   SELECT x+y FROM t GROUP BY x+y
 		col := b.addColumn(aggInScope, alias, e)
 		b.buildScalar(e, fromScope, aggInScope, col, nil)
-		fromScope.groupby.groupStrs[exprStr] = col
+		g.groupStrs[exprStr] = col
+		ordinals.Add(len(g.groupStrs) - 1)
+	}
+	return ordinals
+}
+
+// buildGroupingExpr builds a GROUPING(...) expression. Its value is a bit mask
+// with one bit per argument, where the bit of the last argument is the least
+// significant one. The bit is set if the argument is missing from the grouping
+// set of the row. When there are several grouping sets, the value depends on
+// groupingIDCol; otherwise it is always 0.
+func (b *Builder) buildGroupingExpr(t *tree.GroupingExpr, inScope *scope) opt.ScalarExpr {
+	if !inScope.inGroupingContext() || inScope.inAgg || inScope.groupby.buildingGroupingCols {
+		panic(errGroupingArgs)
+	}
+	if len(t.Exprs) > maxGroupingArgs {
+		panic(pgerror.Newf(pgcode.TooManyArguments,
+			"GROUPING must have fewer than %d arguments", maxGroupingArgs+1))
+	}
+	g := inScope.groupby
+	ordinals := make([]int, len(t.Exprs))
+	for i := range t.Exprs {
+		col, ok := g.groupStrs[symbolicExprStr(t.Exprs[i].(tree.TypedExpr))]
+		if !ok {
+			panic(errGroupingArgs)
+		}
+		ordinals[i] = g.groupingColOrdinal(col.id)
+	}
+
+	if g.groupingIDCol == 0 {
+		return b.factory.ConstructConstVal(tree.NewDInt(0), types.Int)
+	}
+
+	mask := func(set util.FastIntSet) opt.ScalarExpr {
+		var res tree.DInt
+		for _, ord := range ordinals {
+			res <<= 1
+			if !set.Contains(ord) {
+				res |= 1
+			}
+		}
+		return b.factory.ConstructConstVal(tree.NewDInt(res), types.Int)
 	}
+
+	// Build the expression:
+	//
+	//   CASE grouping_id WHEN 0 THEN <mask0> WHEN 1 THEN <mask1> ... ELSE <maskN> END
+	//
+	last := len(g.groupingSets) - 1
+	whens := make(memo.ScalarListExpr, last)
+	for i := range whens {
+		whens[i] = b.factory.ConstructWhen(
+			b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(i)), types.Int),
+			mask(g.groupingSets[i]),
+		)
+	}
+	return b.factory.ConstructCase(
+		b.factory.ConstructVariable(g.groupingIDCol), whens, mask(g.groupingSets[last]),
+	)
 }
 
+// maxGroupingArgs is the maximum number of arguments of GROUPING(...).
+const maxGroupingArgs = 31
+
+var errGroupingArgs = pgerror.New(pgcode.Grouping,
+	"arguments to GROUPING must be grouping expressions of the associated query level")
+
 // buildAggArg builds a scalar expression which is used as an input in some form
 // to an aggregate expression. The scopeColumn for the built expression will
 // be added to tempScope.
@@ -897,9 +1344,13 @@ func (b *Builder) allowImplicitGroupingColumn(colID opt.ColumnID, g *groupby) bo
 		pkCols.Add(colMeta.Table.IndexColumnID(primaryIndex, i))
 	}
 	// Remove PK columns that are grouping cols and see if there's anything left.
+	// Grouping columns which are missing from some grouping sets don't count,
+	// since they are NULL in the rows of those grouping sets.
 	groupingCols := g.groupingCols()
 	for i := range groupingCols {
-		pkCols.Remove(groupingCols[i].id)
+		if _, ok := g.groupingSetCols[groupingCols[i].id]; !ok {
+			pkCols.Remove(groupingCols[i].id)
+		}
 	}
 	return pkCols.Empty()
 }
@@ -213,6 +213,14 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 		}
 	}
 
+	// INSERT and UPDATE triggers would need to fire depending on whether each
+	// row conflicts with an existing row.
+	if ins.OnConflict != nil && !ins.OnConflict.DoNothing &&
+		(hasTriggers(tab, tree.TriggerEventInsert) || hasTriggers(tab, tree.TriggerEventUpdate)) {
+		panic(unimplemented.NewWithIssuef(28296,
+			"UPSERT and INSERT ... ON CONFLICT DO UPDATE are not supported on tables with INSERT or UPDATE triggers"))
+	}
+
 	var mb mutationBuilder
 	if ins.OnConflict != nil && ins.OnConflict.IsUpsertAlias() {
 		mb.init(b, "upsert", tab, alias)
@@ -327,10 +335,11 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 		mb.buildInputForUpsert(inScope, conflictOrds, ins.OnConflict.ArbiterPredicate, ins.OnConflict.Where)
 
 		// Derive the columns that will be updated from the SET expressions.
-		mb.addTargetColsForUpdate(ins.OnConflict.Exprs)
+		exprs := mb.expandFieldUpdates(ins.OnConflict.Exprs)
+		mb.addTargetColsForUpdate(exprs)
 
 		// Build each of the SET expressions.
-		mb.addUpdateCols(ins.OnConflict.Exprs)
+		mb.addUpdateCols(exprs)
 
 		// Build the final upsert statement, including any returned expressions.
 		mb.buildUpsert(returning)
@@ -537,6 +546,18 @@ func (mb *mutationBuilder) addTargetTableColsForInsert(maxCols int) {
 // buildInputForInsert constructs the memo group for the input expression and
 // constructs a new output scope containing that expression's output columns.
 func (mb *mutationBuilder) buildInputForInsert(inScope *scope, inputRows *tree.Select) {
+	// If this is the body of an AFTER trigger, the input rows can refer to the
+	// OLD and NEW rows, and they are inserted once for each of those rows.
+	if rows := mb.b.afterTriggerRows; rows != nil {
+		mb.b.afterTriggerRows = nil
+		inScope = rows
+		defer func() {
+			mb.outScope.expr = mb.b.factory.ConstructInnerJoinApply(
+				rows.expr, mb.outScope.expr, memo.TrueFilter, memo.EmptyJoinPrivate,
+			)
+		}()
+	}
+
 	// Handle DEFAULT VALUES case by creating a single empty row as input.
 	if inputRows == nil {
 		mb.outScope = inScope.push()
@@ -624,6 +645,9 @@ func (mb *mutationBuilder) addSynthesizedColsForInsert() {
 	// synthesized or not).
 	mb.roundDecimalValues(mb.insertColIDs, false /* roundComputedCols */)
 
+	// Fire any BEFORE INSERT triggers, which may replace the inserted values.
+	mb.buildBeforeTriggers(tree.TriggerEventInsert)
+
 	// Now add all computed columns.
 	mb.addSynthesizedCols(
 		mb.insertColIDs,
@@ -653,6 +677,9 @@ func (mb *mutationBuilder) buildInsert(returning tree.ReturningExprs) {
 	mb.projectPartialIndexPutCols(preCheckScope)
 
 	mb.buildFKChecksForInsert()
+	mb.buildUniqueChecks(false /* isUpdate */)
+
+	mb.buildAfterTriggers(tree.TriggerEventInsert)
 
 	private := mb.makeMutationPrivate(returning != nil)
 	mb.outScope.expr = mb.b.factory.ConstructInsert(mb.outScope.expr, mb.checks, private)
@@ -1085,6 +1112,7 @@ func (mb *mutationBuilder) buildUpsert(returning tree.ReturningExprs) {
 	mb.projectPartialIndexPutCols(preCheckScope)
 
 	mb.buildFKChecksForUpsert()
+	mb.buildUniqueChecks(false /* isUpdate */)
 
 	private := mb.makeMutationPrivate(returning != nil)
 	mb.outScope.expr = mb.b.factory.ConstructUpsert(mb.outScope.expr, mb.checks, private)
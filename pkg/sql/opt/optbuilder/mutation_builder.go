@@ -355,6 +355,14 @@ func (mb *mutationBuilder) buildInputForDelete(
 		telemetry.Inc(sqltelemetry.IndexHintDeleteUseCounter)
 	}
 
+	// If this is the body of an AFTER trigger, the WHERE clause can refer to the
+	// OLD and NEW rows, and it is evaluated once for each of those rows.
+	rows := mb.b.afterTriggerRows
+	if rows != nil {
+		mb.b.afterTriggerRows = nil
+		inScope = rows
+	}
+
 	// Fetch columns from different instance of the table metadata, so that it's
 	// possible to remap columns, as in this example:
 	//
@@ -378,6 +386,11 @@ func (mb *mutationBuilder) buildInputForDelete(
 
 	// WHERE
 	mb.b.buildWhere(where, mb.outScope)
+	if rows != nil {
+		mb.outScope.expr = mb.b.factory.ConstructInnerJoinApply(
+			rows.expr, mb.outScope.expr, memo.TrueFilter, memo.EmptyJoinPrivate,
+		)
+	}
 
 	// SELECT + ORDER BY (which may add projected expressions)
 	projectionsScope := mb.outScope.replace()
@@ -393,6 +406,18 @@ func (mb *mutationBuilder) buildInputForDelete(
 
 	mb.outScope = projectionsScope
 
+	// Delete each row at most once, even if it matches more than one of the
+	// trigger rows.
+	if rows != nil {
+		var pkCols opt.ColSet
+		primaryIndex := mb.tab.Index(cat.PrimaryIndex)
+		for i := 0; i < primaryIndex.KeyColumnCount(); i++ {
+			pkCols.Add(mb.outScope.cols[primaryIndex.Column(i).Ordinal()].id)
+		}
+		mb.outScope = mb.b.buildDistinctOn(
+			pkCols, mb.outScope, false /* nullsAreDistinct */, "" /* errorOnDup */)
+	}
+
 	// Set list of columns that will be fetched by the input expression.
 	mb.setFetchColIDs(mb.outScope.cols)
 
@@ -163,7 +163,7 @@ func (mb *mutationBuilder) buildFKChecksAndCascadesForDelete() {
 		}
 
 		fkInput, withScanCols, _ := h.makeFKInputScan(fkInputScanFetchedVals)
-		mb.checks = append(mb.checks, h.buildDeletionCheck(fkInput, withScanCols))
+		mb.checks = append(mb.checks, h.buildDeletionCheck(fkInput, withScanCols, h.fk.DeleteReferenceAction()))
 	}
 	telemetry.Inc(sqltelemetry.ForeignKeyChecksUseCounter)
 }
@@ -344,7 +344,7 @@ func (mb *mutationBuilder) buildFKChecksForUpdate() {
 			},
 		)
 
-		mb.checks = append(mb.checks, h.buildDeletionCheck(deletedRows, colsForOldRow))
+		mb.checks = append(mb.checks, h.buildDeletionCheck(deletedRows, colsForOldRow, h.fk.UpdateReferenceAction()))
 	}
 	telemetry.Inc(sqltelemetry.ForeignKeyChecksUseCounter)
 }
@@ -447,7 +447,7 @@ func (mb *mutationBuilder) buildFKChecksForUpsert() {
 				OutCols:   colsForOldRow,
 			},
 		)
-		mb.checks = append(mb.checks, h.buildDeletionCheck(deletedRows, colsForOldRow))
+		mb.checks = append(mb.checks, h.buildDeletionCheck(deletedRows, colsForOldRow, h.fk.UpdateReferenceAction()))
 	}
 	telemetry.Inc(sqltelemetry.ForeignKeyChecksUseCounter)
 }
@@ -767,15 +767,18 @@ func (h *fkCheckHelper) buildInsertionCheck() memo.FKChecksItem {
 		FKOrdinal:       h.fkOrdinal,
 		KeyCols:         withScanCols,
 		OpName:          h.mb.opName,
+		Deferrable:      h.fk.Deferrable() && h.fk.Validated(),
 	})
 }
 
 // buildDeletionCheck creates a FK check for rows which are removed from a
 // table. deletedRows is used as the input to the deletion check, and deleteCols
 // is a list of the columns for the rows being deleted, containing values for
-// the referenced FK columns in the table we are mutating.
+// the referenced FK columns in the table we are mutating. action is the
+// reference action of the FK for the mutation, which is either NO ACTION or
+// RESTRICT; RESTRICT checks cannot be deferred.
 func (h *fkCheckHelper) buildDeletionCheck(
-	deletedRows memo.RelExpr, deleteCols opt.ColList,
+	deletedRows memo.RelExpr, deleteCols opt.ColList, action tree.ReferenceAction,
 ) memo.FKChecksItem {
 	// Build a semi join, with the referenced FK columns on the left and the
 	// origin columns on the right.
@@ -809,5 +812,6 @@ func (h *fkCheckHelper) buildDeletionCheck(
 		FKOrdinal:       h.fkOrdinal,
 		KeyCols:         deleteCols,
 		OpName:          h.mb.opName,
+		Deferrable:      h.fk.Deferrable() && h.fk.Validated() && action != tree.Restrict,
 	})
 }
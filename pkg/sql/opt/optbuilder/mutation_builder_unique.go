@@ -0,0 +1,192 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/errors"
+)
+
+// buildUniqueChecks builds the checks of the deferrable unique constraints of
+// the table for the rows written by an insert, update or upsert, and adds them
+// to mutationBuilder.checks.
+//
+// A deferrable unique constraint is enforced by an index that is not unique,
+// so that rows can be written to it without a conflict check. Like a FK
+// check, the check of the constraint runs after the statement; it is a
+// semi-join with the left side being a WithScan of the new values of the
+// constraint and primary key columns, and the right side being the table
+// itself. Any row returned by the check has the same values as another row of
+// the table, and is a violation of the constraint. For example:
+//
+//   insert t
+//    ├── ...
+//    ├── input binding: &1
+//    └── f-k-checks
+//         └── f-k-checks-item: t(b) unique
+//              └── semi-join (hash)
+//                   ├── columns: column2:10!null column1:11!null
+//                   ├── with-scan &1
+//                   │    ├── columns: column2:10!null column1:11!null
+//                   │    └── mapping:
+//                   │         ├──  column2:7 => column2:10
+//                   │         └──  column1:6 => column1:11
+//                   ├── scan t
+//                   │    └── columns: a:12!null b:13
+//                   └── filters
+//                        ├── column2:10 = b:13
+//                        └── column1:11 != a:12
+//
+// Rows with a NULL in any of the columns of the constraint never violate it,
+// and are filtered out of the WithScan.
+// An update only needs checks for the constraints on updated columns.
+func (mb *mutationBuilder) buildUniqueChecks(isUpdate bool) {
+	for i, n := 0, mb.tab.IndexCount(); i < n; i++ {
+		index := mb.tab.Index(i)
+		if index.UniqueDeferrability() == tree.ConstraintNotDeferrable {
+			continue
+		}
+		if isUpdate && !mb.indexColsUpdated(index) {
+			continue
+		}
+		if check, ok := mb.buildUniqueCheck(index); ok {
+			mb.checks = append(mb.checks, check)
+		}
+	}
+}
+
+// indexColsUpdated returns true if any of the explicit columns of the given
+// index are being updated (according to updateColIDs).
+func (mb *mutationBuilder) indexColsUpdated(index cat.Index) bool {
+	for i, n := 0, index.ExplicitColumnCount(); i < n; i++ {
+		if mb.updateColIDs[index.Column(i).Ordinal()] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// buildUniqueCheck creates the check of the deferrable unique constraint
+// enforced by the given index. See buildUniqueChecks.
+//
+// Returns false if the constraint cannot be violated because its columns
+// include the primary key.
+func (mb *mutationBuilder) buildUniqueCheck(index cat.Index) (_ memo.FKChecksItem, ok bool) {
+	f := mb.b.factory
+	numKeyCols := index.ExplicitColumnCount()
+	primary := mb.tab.Index(cat.PrimaryIndex)
+
+	// The ordinals of the columns of the constraint, followed by the other
+	// primary key columns, which identify the rows.
+	tabOrdinals := make([]int, 0, numKeyCols+primary.KeyColumnCount())
+	var keyOrdinals util.FastIntSet
+	for i := 0; i < numKeyCols; i++ {
+		ord := index.Column(i).Ordinal()
+		tabOrdinals = append(tabOrdinals, ord)
+		keyOrdinals.Add(ord)
+	}
+	for i, n := 0, primary.KeyColumnCount(); i < n; i++ {
+		if ord := primary.Column(i).Ordinal(); !keyOrdinals.Contains(ord) {
+			tabOrdinals = append(tabOrdinals, ord)
+		}
+	}
+	if len(tabOrdinals) == numKeyCols {
+		return memo.FKChecksItem{}, false
+	}
+	if mb.withID == 0 {
+		mb.withID = f.Memo().NextWithID()
+		mb.md.AddWithBinding(mb.withID, mb.outScope.expr)
+	}
+
+	// Build a WithScan of the new values of these columns.
+	inputCols := make(opt.ColList, len(tabOrdinals))
+	withScanCols := make(opt.ColList, len(tabOrdinals))
+	for i, tabOrd := range tabOrdinals {
+		inputCols[i] = mb.mapToReturnColID(tabOrd)
+		if inputCols[i] == 0 {
+			panic(errors.AssertionFailedf("no value for unique column (tabOrd=%d)", tabOrd))
+		}
+		c := mb.md.ColumnMeta(inputCols[i])
+		withScanCols[i] = mb.md.AddColumn(c.Alias, c.Type)
+	}
+	var input memo.RelExpr = f.ConstructWithScan(&memo.WithScanPrivate{
+		With:    mb.withID,
+		InCols:  inputCols,
+		OutCols: withScanCols,
+		ID:      mb.md.NextUniqueID(),
+	})
+
+	// Filter out the rows which have a NULL in the columns of the constraint;
+	// build filters of the form
+	//   (a IS NOT NULL) AND (b IS NOT NULL) ...
+	var filters memo.FiltersExpr
+	for i := 0; i < numKeyCols; i++ {
+		if mb.outScope.expr.Relational().NotNullCols.Contains(inputCols[i]) ||
+			!mb.tab.Column(tabOrdinals[i]).IsNullable() {
+			continue
+		}
+		filters = append(filters, f.ConstructFiltersItem(
+			f.ConstructIsNot(f.ConstructVariable(withScanCols[i]), memo.NullSingleton),
+		))
+	}
+	if len(filters) > 0 {
+		input = f.ConstructSelect(input, filters)
+	}
+
+	// Build a semi-join with the table, which finds the rows that have the same
+	// values for the columns of the constraint as another row:
+	//   (new_a = a) AND (new_b = b) AND ... AND ((new_pk1 != pk1) OR ...)
+	tabMeta := mb.b.addTable(mb.tab, tree.NewUnqualifiedTableName(mb.tab.Name()))
+	scanScope := mb.b.buildScan(
+		tabMeta,
+		tabOrdinals,
+		&tree.IndexFlags{IgnoreForeignKeys: true},
+		noRowLocking,
+		mb.b.allocScope(),
+	)
+	semiJoinFilters := make(memo.FiltersExpr, 0, numKeyCols+1)
+	for i := 0; i < numKeyCols; i++ {
+		semiJoinFilters = append(semiJoinFilters, f.ConstructFiltersItem(
+			f.ConstructEq(
+				f.ConstructVariable(withScanCols[i]),
+				f.ConstructVariable(scanScope.cols[i].id),
+			),
+		))
+	}
+	var otherRow opt.ScalarExpr
+	for i := numKeyCols; i < len(tabOrdinals); i++ {
+		ne := f.ConstructNe(
+			f.ConstructVariable(withScanCols[i]),
+			f.ConstructVariable(scanScope.cols[i].id),
+		)
+		if otherRow == nil {
+			otherRow = ne
+		} else {
+			otherRow = f.ConstructOr(otherRow, ne)
+		}
+	}
+	semiJoinFilters = append(semiJoinFilters, f.ConstructFiltersItem(otherRow))
+	semiJoin := f.ConstructSemiJoin(input, scanScope.expr, semiJoinFilters, memo.EmptyJoinPrivate)
+
+	return f.ConstructFKChecksItem(semiJoin, &memo.FKChecksItemPrivate{
+		OriginTable:     mb.tabID,
+		ReferencedTable: tabMeta.MetaID,
+		KeyCols:         withScanCols[:numKeyCols],
+		OpName:          mb.opName,
+		Deferrable:      true,
+		Unique:          true,
+		IndexOrdinal:    index.Ordinal(),
+	}), true
+}
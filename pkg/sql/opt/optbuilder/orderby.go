@@ -139,7 +139,8 @@ func (b *Builder) analyzeOrderByIndex(
 
 		desc := col.Descending
 
-		// DESC inverts the order of the index.
+		// DESC inverts the order of the index. It also moves NULLs to the other
+		// end, so the NULL ordering stays reversed if it was.
 		if order.Direction == tree.Descending {
 			desc = !desc
 		}
@@ -148,6 +149,7 @@ func (b *Builder) analyzeOrderByIndex(
 		expr := inScope.resolveType(colItem, types.Any)
 		outCol := b.addColumn(orderByScope, "" /* alias */, expr)
 		outCol.descending = desc
+		outCol.nullsReversed = col.NullsReversed
 	}
 }
 
@@ -168,6 +170,7 @@ func (b *Builder) analyzeOrderByArg(
 	for i := start; i < len(orderByScope.cols); i++ {
 		col := &orderByScope.cols[i]
 		col.descending = order.Direction == tree.Descending
+		col.nullsReversed = order.NullsOrder.IsReversed(order.Direction)
 	}
 }
 
@@ -182,10 +185,23 @@ func (b *Builder) buildOrderByArg(
 
 	// Add the new column to the ordering.
 	orderByScope.ordering = append(orderByScope.ordering,
-		opt.MakeOrderingColumn(orderByCol.id, orderByCol.descending),
+		makeOrderingColumn(inScope, orderByCol.id, orderByCol.descending, orderByCol.nullsReversed),
 	)
 }
 
+// makeOrderingColumn returns an ordering column with the given direction and
+// NULL ordering. The NULL ordering is only kept if the column can be NULL in
+// the input scope, so that orderings on non-NULL columns can be provided
+// regardless of how NULLs are ordered.
+func makeOrderingColumn(
+	inScope *scope, id opt.ColumnID, descending, nullsReversed bool,
+) opt.OrderingColumn {
+	if nullsReversed && inScope.expr != nil && inScope.expr.Relational().NotNullCols.Contains(id) {
+		nullsReversed = false
+	}
+	return opt.MakeOrderingColumnWithNulls(id, descending, nullsReversed)
+}
+
 // analyzeExtraArgument analyzes a single ORDER BY or DISTINCT ON argument.
 // Typically this is a single column, with the exception of qualified star
 // (table.*). The resulting typed expression(s) are added to extraColsScope.
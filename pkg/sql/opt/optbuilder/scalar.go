@@ -249,7 +249,11 @@ func (b *Builder) buildScalar(
 	case *tree.CastExpr:
 		texpr := t.Expr.(tree.TypedExpr)
 		arg := b.buildScalar(texpr, inScope, nil, nil, colRefs)
-		out = b.factory.ConstructCast(arg, t.ResolvedType())
+		if typ := t.ResolvedType(); typ.IsDomain() {
+			out = b.buildDomainCast(arg, typ, inScope, colRefs)
+		} else {
+			out = b.factory.ConstructCast(arg, typ)
+		}
 
 	case *tree.CoalesceExpr:
 		args := make(memo.ScalarListExpr, len(t.Exprs))
@@ -293,6 +297,9 @@ func (b *Builder) buildScalar(
 	case *tree.FuncExpr:
 		return b.buildFunction(t, inScope, outScope, outCol, colRefs)
 
+	case *tree.GroupingExpr:
+		out = b.buildGroupingExpr(t, inScope)
+
 	case *tree.IfExpr:
 		valType := t.ResolvedType()
 		input := b.buildScalar(t.Cond.(tree.TypedExpr), inScope, nil, nil, colRefs)
@@ -392,6 +399,9 @@ func (b *Builder) buildScalar(
 		}
 		out = b.factory.ConstructTuple(els, t.ResolvedType())
 
+	case *domainValue:
+		out = t.scalar
+
 	case *subquery:
 		out, _ = b.buildSingleRowSubquery(t, inScope)
 		// Perform correctness checks on the outer cols, update colRefs and
@@ -908,6 +908,13 @@ func (s *scope) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
 	case *tree.FuncExpr:
 		def, err := t.Func.Resolve(s.builder.semaCtx.SearchPath)
 		if err != nil {
+			if fn := s.builder.resolveUDF(t, err); fn != nil {
+				expr = s.replaceSubquery(
+					s.builder.buildUDFCall(fn, t.Exprs), false /* wrapInTuple */, 1, /* desiredNumColumns */
+					noExtraColsAllowed,
+				)
+				break
+			}
 			panic(err)
 		}
 
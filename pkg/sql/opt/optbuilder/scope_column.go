@@ -55,6 +55,11 @@ type scopeColumn struct {
 	// This field is only used for ordering columns.
 	descending bool
 
+	// nullsReversed indicates whether NULLs are sorted opposite to the default
+	// for the direction of this column. This field is only used for ordering
+	// columns.
+	nullsReversed bool
+
 	// scalar is the scalar expression associated with this column. If it is nil,
 	// then the column is a passthrough from an inner scope or a table column.
 	scalar opt.ScalarExpr
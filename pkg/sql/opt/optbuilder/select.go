@@ -135,6 +135,15 @@ func (b *Builder) buildDataSource(
 		return b.buildDataSource(source.Expr, indexFlags, locking, inScope)
 
 	case *tree.RowsFromExpr:
+		if len(source.Items) == 1 {
+			if f, ok := source.Items[0].(*tree.FuncExpr); ok {
+				if _, err := f.Func.Resolve(b.semaCtx.SearchPath); err != nil {
+					if fn := b.resolveUDF(f, err); fn != nil {
+						return b.buildUDFDataSource(fn, f.Exprs, inScope)
+					}
+				}
+			}
+		}
 		return b.buildZip(source.Items, inScope)
 
 	case *tree.Subquery:
@@ -451,12 +460,16 @@ func (b *Builder) buildScan(
 
 	outScope = inScope.push()
 
-	var tabColIDs opt.ColSet
+	var tabColIDs, virtualColIDs opt.ColSet
 	outScope.cols = make([]scopeColumn, len(ordinals))
 	for i, ord := range ordinals {
 		col := tab.Column(ord)
 		colID := tabID.ColumnID(ord)
-		tabColIDs.Add(colID)
+		if col.IsVirtualComputed() {
+			virtualColIDs.Add(colID)
+		} else {
+			tabColIDs.Add(colID)
+		}
 		name := col.ColName()
 		kind := col.Kind()
 		outScope.cols[i] = scopeColumn{
@@ -518,12 +531,27 @@ func (b *Builder) buildScan(
 		b.addCheckConstraintsForTable(tabMeta)
 		b.addComputedColsForTable(tabMeta)
 
+		// Virtual computed columns are not stored in the table, so they cannot
+		// be produced by the scan. Instead, the scan produces the columns they
+		// depend on and a Project on top of it computes them.
+		var virtualCols memo.ProjectionsExpr
+		if !virtualColIDs.Empty() {
+			virtualCols = b.buildVirtualComputedCols(tabMeta, virtualColIDs)
+			for i := range virtualCols {
+				private.Cols.UnionWith(virtualCols[i].ScalarProps().OuterCols)
+			}
+		}
+
 		outScope.expr = b.factory.ConstructScan(&private)
 
 		// Add the partial indexes after constructing the scan so we can use the
 		// logical properties of the scan to fully normalize the index predicates.
 		b.addPartialIndexPredicatesForTable(tabMeta, outScope)
 
+		if virtualCols != nil {
+			outScope.expr = b.factory.ConstructProject(outScope.expr, virtualCols, tabColIDs)
+		}
+
 		if b.trackViewDeps {
 			dep := opt.ViewDep{DataSource: tab}
 			dep.ColumnIDToOrd = make(map[opt.ColumnID]int)
@@ -657,6 +685,35 @@ func (b *Builder) addComputedColsForTable(tabMeta *opt.TableMeta) {
 	}
 }
 
+// buildVirtualComputedCols builds a projection for each of the given virtual
+// computed columns of the table. Unlike the expressions in
+// TableMeta.ComputedCols, the projections are built for mutation columns as
+// well, since they must still be computed when they are fetched by a mutation.
+func (b *Builder) buildVirtualComputedCols(
+	tabMeta *opt.TableMeta, cols opt.ColSet,
+) memo.ProjectionsExpr {
+	tableScope := b.allocScope()
+	tableScope.appendOrdinaryColumnsFromTable(tabMeta, &tabMeta.Alias)
+
+	projections := make(memo.ProjectionsExpr, 0, cols.Len())
+	for col, ok := cols.Next(0); ok; col, ok = cols.Next(col + 1) {
+		scalar, ok := tabMeta.ComputedCols[col]
+		if !ok {
+			tabCol := tabMeta.Table.Column(tabMeta.MetaID.ColumnOrdinal(col))
+			expr, err := parser.ParseExpr(tabCol.ComputedExprStr())
+			if err != nil {
+				panic(err)
+			}
+			texpr := tableScope.resolveAndRequireType(expr, types.Any)
+			b.factory.FoldingControl().TemporarilyDisallowStableFolds(func() {
+				scalar = b.buildScalar(texpr, tableScope, nil, nil, nil)
+			})
+		}
+		projections = append(projections, b.factory.ConstructProjectionsItem(scalar, col))
+	}
+	return projections
+}
+
 // addPartialIndexPredicatesForTable finds all partial indexes in the table and
 // adds their predicates to the table metadata (see
 // TableMeta.PartialIndexPredicates). The predicates are converted from strings
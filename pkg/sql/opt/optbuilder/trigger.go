@@ -0,0 +1,423 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/norm"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+// Row-level triggers execute a trigger function for each row modified by a
+// mutation. The body of the function refers to the row being modified through
+// the OLD and NEW data sources, which contain the existing and new values of
+// the row, respectively.
+//
+// BEFORE triggers are planned as part of the mutation itself. The body of the
+// function must be a SELECT statement; it is built as a correlated subquery
+// of the mutation input, and the row it produces replaces the NEW row that
+// will be written. If it produces no row, the row is skipped. For example,
+// given:
+//
+//   CREATE FUNCTION f() RETURNS TRIGGER LANGUAGE SQL
+//     AS 'SELECT new.k, upper(new.v)'
+//   CREATE TRIGGER tr BEFORE INSERT ON kv FOR EACH ROW EXECUTE FUNCTION f()
+//
+// the input of INSERT INTO kv VALUES (1, 'a') is built as:
+//
+//   SELECT * FROM (VALUES (1, 'a')) AS new (k, v),
+//     LATERAL (SELECT new.k, upper(new.v) LIMIT 1)
+//
+// AFTER triggers are planned as cascades of the mutation (see
+// afterTriggerBuilder), which run after the mutation has modified all rows.
+// The body of the function must be an INSERT, UPSERT or DELETE statement.
+//
+// Triggers fire in the order of their names.
+
+// triggerFires returns true if the given trigger fires on the given event.
+func triggerFires(t *cat.Trigger, event tree.TriggerEvent) bool {
+	switch event {
+	case tree.TriggerEventInsert:
+		return t.Insert
+	case tree.TriggerEventUpdate:
+		return t.Update
+	case tree.TriggerEventDelete:
+		return t.Delete
+	}
+	return false
+}
+
+// hasTriggers returns true if the given table has any triggers that fire on
+// the given event.
+func hasTriggers(tab cat.Table, event tree.TriggerEvent) bool {
+	for i, n := 0, tab.TriggerCount(); i < n; i++ {
+		if t := tab.Trigger(i); triggerFires(&t, event) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTriggerFunc resolves and parses the body of the function executed by
+// the given trigger.
+func (b *Builder) parseTriggerFunc(t *cat.Trigger) tree.Statement {
+	fn, err := b.catalog.ResolveFunctionByID(b.ctx, cat.Flags{}, t.Function)
+	if err != nil {
+		panic(err)
+	}
+	// The trigger function is executed without checking privileges on it, so
+	// only its version needs to be checked when the memo is reused.
+	b.factory.Metadata().AddFunctionDependency(opt.DepByID(t.Function), fn, 0 /* priv */)
+	stmt, err := parser.ParseOne(fn.Body())
+	if err != nil {
+		panic(pgerror.Wrapf(err, pgcode.Syntax,
+			"failed to parse body of function %q", fn.Name()))
+	}
+	return stmt.AST
+}
+
+// triggerNewColID returns the ID of the input column that provides the NEW
+// value of the column at the given ordinal position in the table.
+func (mb *mutationBuilder) triggerNewColID(ord int) opt.ColumnID {
+	if id := mb.updateColIDs[ord]; id != 0 {
+		return id
+	}
+	if id := mb.insertColIDs[ord]; id != 0 {
+		return id
+	}
+	return mb.fetchColIDs[ord]
+}
+
+// buildBeforeTriggers wraps the mutation input with the BEFORE triggers that
+// fire on the given event, in order. It must be called after the input values
+// of the non-computed columns are built, and before the computed columns are
+// synthesized.
+func (mb *mutationBuilder) buildBeforeTriggers(event tree.TriggerEvent) {
+	for i, n := 0, mb.tab.TriggerCount(); i < n; i++ {
+		if t := mb.tab.Trigger(i); t.Before && triggerFires(&t, event) {
+			mb.buildBeforeTrigger(&t, event)
+		}
+	}
+}
+
+// buildBeforeTrigger wraps the mutation input with an apply join of the body
+// of the given BEFORE trigger. For INSERT and UPDATE, the columns produced by
+// the body become the new values of the visible, non-computed columns of the
+// table. For DELETE, the body only decides whether the row is deleted.
+func (mb *mutationBuilder) buildBeforeTrigger(t *cat.Trigger, event tree.TriggerEvent) {
+	body, ok := mb.b.parseTriggerFunc(t).(*tree.Select)
+	if !ok {
+		panic(pgerror.Newf(pgcode.InvalidObjectDefinition,
+			"function executed by BEFORE trigger %q must consist of a SELECT statement", t.Name))
+	}
+	if !hasLimit(body) {
+		body.Limit = &tree.Limit{Count: tree.NewDInt(1)}
+	}
+
+	// Build the scope containing the OLD and NEW rows, and determine which
+	// columns make up the row returned by the body. Computed columns are not
+	// part of the NEW row, since they are only computed after the triggers
+	// have fired.
+	rowScope := mb.b.allocScope()
+	var rowOrds []int
+	var desiredTypes []*types.T
+	newTable := tree.MakeUnqualifiedTableName("new")
+	oldTable := tree.MakeUnqualifiedTableName("old")
+	for i, n := 0, mb.tab.ColumnCount(); i < n; i++ {
+		col := mb.tab.Column(i)
+		if col.Kind() != cat.Ordinary {
+			continue
+		}
+		if event != tree.TriggerEventInsert {
+			rowScope.cols = append(rowScope.cols, scopeColumn{
+				name:   col.ColName(),
+				table:  oldTable,
+				typ:    col.DatumType(),
+				id:     mb.fetchColIDs[i],
+				hidden: col.IsHidden(),
+			})
+		}
+		if event != tree.TriggerEventDelete && !col.IsComputed() {
+			rowScope.cols = append(rowScope.cols, scopeColumn{
+				name:   col.ColName(),
+				table:  newTable,
+				typ:    col.DatumType(),
+				id:     mb.triggerNewColID(i),
+				hidden: col.IsHidden(),
+			})
+			if !col.IsHidden() {
+				rowOrds = append(rowOrds, i)
+				desiredTypes = append(desiredTypes, col.DatumType())
+			}
+		}
+	}
+
+	bodyScope := mb.b.buildSelect(body, noRowLocking, desiredTypes, rowScope)
+
+	if event == tree.TriggerEventDelete {
+		mb.outScope.expr = mb.b.factory.ConstructSemiJoinApply(
+			mb.outScope.expr, bodyScope.expr, memo.TrueFilter, memo.EmptyJoinPrivate,
+		)
+		return
+	}
+
+	cols := bodyScope.makePhysicalProps().Presentation
+	if len(cols) != len(rowOrds) {
+		panic(errors.WithDetailf(
+			pgerror.Newf(pgcode.DatatypeMismatch,
+				"returned row structure does not match the structure of the triggering table"),
+			"Number of returned columns (%d) does not match expected column count (%d).",
+			len(cols), len(rowOrds),
+		))
+	}
+
+	outScope := mb.outScope.replace()
+	outScope.appendColumnsFromScope(mb.outScope)
+	outScope.appendColumnsFromScope(bodyScope)
+	outScope.expr = mb.b.factory.ConstructInnerJoinApply(
+		mb.outScope.expr, bodyScope.expr, memo.TrueFilter, memo.EmptyJoinPrivate,
+	)
+	mb.outScope = outScope
+
+	colIDs := mb.insertColIDs
+	if event == tree.TriggerEventUpdate {
+		colIDs = mb.updateColIDs
+	}
+	for i, ord := range rowOrds {
+		col := mb.tab.Column(ord)
+		if typ := mb.md.ColumnMeta(cols[i].ID).Type; typ.Family() != types.UnknownFamily {
+			checkDatumTypeFitsColumnType(col, typ)
+		}
+
+		// The returned column provides the value to write.
+		scopeCol := outScope.getColumn(cols[i].ID)
+		scopeCol.name = col.ColName()
+		scopeCol.table = tree.TableName{}
+		scopeCol.hidden = false
+		colIDs[ord] = cols[i].ID
+		if tabColID := mb.tabID.ColumnID(ord); !mb.targetColSet.Contains(tabColID) {
+			mb.targetColList = append(mb.targetColList, tabColID)
+			mb.targetColSet.Add(tabColID)
+		}
+	}
+
+	// Make sure that the names of the replaced columns refer to the returned
+	// columns, and round the returned values if necessary.
+	mb.disambiguateColumns()
+	mb.roundDecimalValues(colIDs, false /* roundComputedCols */)
+}
+
+// buildAfterTriggers adds a cascade for each of the AFTER triggers that fire on
+// the given event. It must be called once the mutation input is complete.
+func (mb *mutationBuilder) buildAfterTriggers(event tree.TriggerEvent) {
+	for i, n := 0, mb.tab.TriggerCount(); i < n; i++ {
+		t := mb.tab.Trigger(i)
+		if t.Before || !triggerFires(&t, event) {
+			continue
+		}
+		if mb.withID == 0 {
+			mb.withID = mb.b.factory.Memo().NextWithID()
+			mb.md.AddWithBinding(mb.withID, mb.outScope.expr)
+		}
+
+		// The OLD and NEW rows contain all the public columns of the table.
+		var oldOrds, newOrds []int
+		var oldValues, newValues opt.ColList
+		for ord, n := 0, mb.tab.ColumnCount(); ord < n; ord++ {
+			if mb.tab.Column(ord).Kind() != cat.Ordinary {
+				continue
+			}
+			if event != tree.TriggerEventInsert {
+				oldOrds = append(oldOrds, ord)
+				oldValues = append(oldValues, mb.fetchColIDs[ord])
+			}
+			if event != tree.TriggerEventDelete {
+				newOrds = append(newOrds, ord)
+				newValues = append(newValues, mb.triggerNewColID(ord))
+			}
+		}
+
+		// Resolve the trigger function now, so that the statement fails early
+		// if it does not exist.
+		mb.b.parseTriggerFunc(&t)
+
+		mb.cascades = append(mb.cascades, memo.FKCascade{
+			FKName:    string(t.Name),
+			Builder:   newAfterTriggerBuilder(mb.tab, t, oldOrds, newOrds),
+			WithID:    mb.withID,
+			OldValues: oldValues,
+			NewValues: newValues,
+			Trigger:   true,
+		})
+	}
+}
+
+// afterTriggerBuilder is a memo.CascadeBuilder implementation for AFTER
+// triggers.
+//
+// It builds the body of the trigger function, which is an INSERT, UPSERT or
+// DELETE statement, so that it is executed once for each row modified by the
+// original mutation. The OLD and NEW rows are provided by a WithScan of the
+// mutation input, which is joined with the input of the statement in the
+// body. For example, given:
+//
+//   CREATE FUNCTION f() RETURNS TRIGGER LANGUAGE SQL
+//     AS 'INSERT INTO audit VALUES (old.k, new.v)'
+//   CREATE TRIGGER tr AFTER UPDATE ON kv FOR EACH ROW EXECUTE FUNCTION f()
+//
+// the trigger executes a query equivalent to:
+//
+//   INSERT INTO audit
+//   SELECT v.* FROM original_mutation_input AS r, LATERAL (VALUES (r.old_k, r.new_v)) AS v
+//
+// For DELETE, each row of the target table is deleted at most once, even if
+// the WHERE clause matches it for more than one OLD or NEW row.
+//
+type afterTriggerBuilder struct {
+	mutatedTable cat.Table
+	trigger      cat.Trigger
+
+	// oldOrds and newOrds are the ordinals of the table columns in the OLD and
+	// NEW rows; they map 1-to-1 to the oldValues and newValues passed to Build.
+	oldOrds []int
+	newOrds []int
+}
+
+var _ memo.CascadeBuilder = &afterTriggerBuilder{}
+
+func newAfterTriggerBuilder(
+	mutatedTable cat.Table, trigger cat.Trigger, oldOrds, newOrds []int,
+) *afterTriggerBuilder {
+	return &afterTriggerBuilder{
+		mutatedTable: mutatedTable,
+		trigger:      trigger,
+		oldOrds:      oldOrds,
+		newOrds:      newOrds,
+	}
+}
+
+// Build is part of the memo.CascadeBuilder interface.
+func (tb *afterTriggerBuilder) Build(
+	ctx context.Context,
+	semaCtx *tree.SemaContext,
+	evalCtx *tree.EvalContext,
+	catalog cat.Catalog,
+	factoryI interface{},
+	binding opt.WithID,
+	bindingProps *props.Relational,
+	oldValues, newValues opt.ColList,
+) (_ memo.RelExpr, err error) {
+	factory := factoryI.(*norm.Factory)
+	b := New(ctx, semaCtx, evalCtx, catalog, factory, nil /* stmt */)
+
+	// Enact panic handling similar to Builder.Build().
+	defer func() {
+		if r := recover(); r != nil {
+			if ok, e := errorutil.ShouldCatch(r); ok {
+				err = e
+			} else {
+				panic(r)
+			}
+		}
+	}()
+
+	if len(oldValues) != len(tb.oldOrds) || len(newValues) != len(tb.newOrds) {
+		panic(errors.AssertionFailedf(
+			"expected %d oldValues/newValues columns, got %d/%d",
+			len(tb.oldOrds)+len(tb.newOrds), len(oldValues), len(newValues),
+		))
+	}
+
+	// The rows returned by the statement, if any, are discarded.
+	stmt := b.parseTriggerFunc(&tb.trigger)
+	switch t := stmt.(type) {
+	case *tree.Insert:
+		t.Returning = tree.AbsentReturningClause
+	case *tree.Delete:
+		if t.OrderBy != nil || t.Limit != nil {
+			panic(unimplemented.NewWithIssuef(28296,
+				"DELETE with ORDER BY or LIMIT is not supported in the body of a trigger function"))
+		}
+		t.Returning = tree.AbsentReturningClause
+	default:
+		panic(pgerror.Newf(pgcode.InvalidObjectDefinition,
+			"function executed by AFTER trigger %q must consist of an INSERT, UPSERT or DELETE statement",
+			tb.trigger.Name))
+	}
+
+	b.afterTriggerRows = b.buildAfterTriggerRows(
+		tb.mutatedTable, binding, bindingProps, tb.oldOrds, oldValues, tb.newOrds, newValues,
+	)
+	outScope := b.buildStmtAtRoot(stmt, nil /* desiredTypes */, b.allocScope())
+	if b.afterTriggerRows != nil {
+		panic(errors.AssertionFailedf("trigger rows were not used"))
+	}
+	return outScope.expr, nil
+}
+
+// buildAfterTriggerRows returns a scope containing the OLD and NEW rows of an
+// AFTER trigger, which are read from the given binding.
+func (b *Builder) buildAfterTriggerRows(
+	tab cat.Table,
+	binding opt.WithID,
+	bindingProps *props.Relational,
+	oldOrds []int,
+	oldValues opt.ColList,
+	newOrds []int,
+	newValues opt.ColList,
+) *scope {
+	md := b.factory.Metadata()
+	md.AddWithBinding(binding, b.factory.ConstructFakeRel(&memo.FakeRelPrivate{
+		Props: bindingProps,
+	}))
+
+	outScope := b.allocScope()
+	inCols := make(opt.ColList, 0, len(oldValues)+len(newValues))
+	outCols := make(opt.ColList, 0, len(oldValues)+len(newValues))
+	addCols := func(alias string, ords []int, values opt.ColList) {
+		table := tree.MakeUnqualifiedTableName(tree.Name(alias))
+		for i, ord := range ords {
+			col := tab.Column(ord)
+			id := md.AddColumn(alias+"_"+string(col.ColName()), col.DatumType())
+			inCols = append(inCols, values[i])
+			outCols = append(outCols, id)
+			outScope.cols = append(outScope.cols, scopeColumn{
+				name:   col.ColName(),
+				table:  table,
+				typ:    col.DatumType(),
+				id:     id,
+				hidden: col.IsHidden(),
+			})
+		}
+	}
+	addCols("old", oldOrds, oldValues)
+	addCols("new", newOrds, newValues)
+
+	outScope.expr = b.factory.ConstructWithScan(&memo.WithScanPrivate{
+		With:    binding,
+		InCols:  inCols,
+		OutCols: outCols,
+		ID:      md.NextUniqueID(),
+	})
+	return outScope
+}
@@ -0,0 +1,260 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+// Calls to user-defined SQL functions are inlined: the body of the function
+// is parsed and spliced into the calling query as a subquery, with the
+// arguments bound to columns named after the parameters. For example, given:
+//
+//   CREATE FUNCTION add(x INT, y INT) RETURNS INT IMMUTABLE LANGUAGE SQL
+//     AS 'SELECT x + y'
+//
+// the expression add(a, 1) is built as:
+//
+//   (SELECT CAST((SELECT add.x + add.y LIMIT 1) AS INT) AS add
+//    FROM (VALUES (a:::INT, 1:::INT)) AS add (x, y))
+//
+// The optimizer is then free to decorrelate and simplify the result like any
+// other subquery. The result of a call to a STABLE or VOLATILE function is
+// additionally wrapped in an identity function with that volatility; see
+// withUDFVolatility.
+
+// resolveUDF attempts to resolve the given function call, which could not be
+// resolved to a builtin function with the given error, to a user-defined
+// function. It returns nil if there is no such function, in which case the
+// caller should report the original error.
+func (b *Builder) resolveUDF(f *tree.FuncExpr, resolveErr error) cat.Function {
+	if pgerror.GetPGCode(resolveErr) != pgcode.UndefinedFunction {
+		return nil
+	}
+	un, ok := f.Func.FunctionReference.(*tree.UnresolvedName)
+	if !ok {
+		return nil
+	}
+	name, err := un.ToUnresolvedObjectName(tree.NoAnnotation)
+	if err != nil {
+		return nil
+	}
+	var flags cat.Flags
+	if b.insideViewDef {
+		flags.AvoidDescriptorCaches = true
+	}
+	fn, err := b.catalog.ResolveFunction(b.ctx, flags, name)
+	if err != nil {
+		panic(err)
+	}
+	if fn == nil {
+		return nil
+	}
+	if b.insideViewDef {
+		// The dependency of the view or function on the called function would
+		// not be tracked.
+		panic(unimplemented.NewWithIssuef(17511,
+			"user-defined functions cannot be used inside a view or function definition"))
+	}
+	if err := b.catalog.CheckPrivilege(b.ctx, fn, privilege.EXECUTE); err != nil {
+		panic(err)
+	}
+	// Add dependency on the function to the metadata, so that the metadata can
+	// be cached and later checked for freshness.
+	tn := name.ToTableName()
+	b.factory.Metadata().AddFunctionDependency(opt.DepByName(&tn), fn, privilege.EXECUTE)
+	if fn.ReturnsTrigger() {
+		panic(pgerror.New(pgcode.FeatureNotSupported,
+			"trigger functions can only be called as triggers"))
+	}
+	if f.Type != 0 || f.Filter != nil || f.WindowDef != nil || len(f.OrderBy) > 0 {
+		panic(pgerror.Newf(pgcode.WrongObjectType,
+			"%s() is not an aggregate or window function", tree.ErrString(un)))
+	}
+	if len(f.Exprs) != fn.ParamCount() {
+		panic(pgerror.Newf(pgcode.UndefinedFunction,
+			"function %s() expects %d argument(s), but %d were given",
+			tree.ErrString(un), fn.ParamCount(), len(f.Exprs)))
+	}
+	return fn
+}
+
+// buildUDFCall returns the scalar subquery that inlines a call to the given
+// user-defined function. Set-returning functions can only be called from the
+// FROM clause; see buildUDFDataSource.
+func (b *Builder) buildUDFCall(fn cat.Function, args tree.Exprs) *tree.Subquery {
+	if fn.ReturnsSet() {
+		panic(unimplemented.NewWithIssuef(17511,
+			"set-returning user-defined functions are only supported in the FROM clause"))
+	}
+	body := parseUDFBody(fn)
+
+	// A scalar function returns the first row produced by its body.
+	if !hasLimit(body) {
+		body.Limit = &tree.Limit{Count: tree.NewDInt(1)}
+	}
+	sel := &tree.SelectClause{
+		Exprs: tree.SelectExprs{{
+			Expr: withUDFVolatility(fn, &tree.CastExpr{
+				Expr:       &tree.Subquery{Select: &tree.ParenSelect{Select: body}},
+				Type:       fn.ReturnType(),
+				SyntaxMode: tree.CastShort,
+			}),
+			As: tree.UnrestrictedName(fn.Name()),
+		}},
+	}
+	addUDFArgs(sel, fn, args)
+	return &tree.Subquery{Select: &tree.ParenSelect{Select: &tree.Select{Select: sel}}}
+}
+
+// buildUDFDataSource builds a call to the given user-defined function that
+// appears in a FROM clause. Set-returning functions produce the rows of
+// their body; other functions produce a single row.
+func (b *Builder) buildUDFDataSource(
+	fn cat.Function, args tree.Exprs, inScope *scope,
+) (outScope *scope) {
+	var sel *tree.SelectClause
+	if !fn.ReturnsSet() {
+		sel = &tree.SelectClause{
+			Exprs: tree.SelectExprs{{Expr: b.buildUDFCall(fn, args), As: tree.UnrestrictedName(fn.Name())}},
+		}
+	} else {
+		// Name the output columns after the columns of RETURNS TABLE, or after
+		// the function for RETURNS SETOF.
+		var cols tree.NameList
+		if labels := fn.ReturnType().TupleLabels(); fn.ReturnType().Family() == types.TupleFamily {
+			cols = make(tree.NameList, len(labels))
+			for i := range labels {
+				cols[i] = tree.Name(labels[i])
+			}
+		} else {
+			cols = tree.NameList{fn.Name()}
+		}
+		resultName := tree.MakeUnqualifiedTableName(fn.Name() + "_result")
+		sel = &tree.SelectClause{
+			From: tree.From{Tables: tree.TableExprs{&tree.AliasedTableExpr{
+				Expr:    &tree.Subquery{Select: &tree.ParenSelect{Select: parseUDFBody(fn)}},
+				As:      tree.AliasClause{Alias: resultName.ObjectName, Cols: cols},
+				Lateral: true,
+			}}},
+		}
+		for i := range cols {
+			sel.Exprs = append(sel.Exprs, tree.SelectExpr{
+				Expr: withUDFVolatility(fn, tree.NewColumnItem(&resultName, cols[i])),
+				As:   tree.UnrestrictedName(cols[i]),
+			})
+		}
+		addUDFArgs(sel, fn, args)
+	}
+
+	outScope = b.buildSelect(&tree.Select{Select: sel}, noRowLocking, nil /* desiredTypes */, inScope)
+
+	// Like builtin generator functions, the result is an anonymous data source
+	// whose single column (if any) is renamed along with the source.
+	outScope.setTableAlias("")
+	outScope.singleSRFColumn = len(outScope.cols) == 1 && fn.ReturnType().Family() != types.TupleFamily
+	return outScope
+}
+
+// addUDFArgs adds the arguments of a call to the given function to the FROM
+// clause of sel, as a single row whose columns are named after the
+// parameters and qualified by the function name. If the function is strict,
+// it also filters out that row if any argument is NULL.
+func addUDFArgs(sel *tree.SelectClause, fn cat.Function, args tree.Exprs) {
+	if fn.ParamCount() == 0 {
+		return
+	}
+	row := make(tree.Exprs, len(args))
+	cols := make(tree.NameList, len(args))
+	var filter tree.Expr
+	fnTable := tree.MakeUnqualifiedTableName(fn.Name())
+	for i := range args {
+		row[i] = &tree.AnnotateTypeExpr{
+			Expr:       args[i],
+			Type:       fn.ParamType(i),
+			SyntaxMode: tree.AnnotateShort,
+		}
+		cols[i] = funcParamColName(fn.ParamName(i), i)
+		if fn.Strict() {
+			var notNull tree.Expr = &tree.IsNotNullExpr{Expr: tree.NewColumnItem(&fnTable, cols[i])}
+			if filter != nil {
+				notNull = &tree.AndExpr{Left: filter, Right: notNull}
+			}
+			filter = notNull
+		}
+	}
+	values := &tree.AliasedTableExpr{
+		Expr: &tree.Subquery{Select: &tree.ParenSelect{Select: &tree.Select{
+			Select: &tree.ValuesClause{Rows: []tree.Exprs{row}},
+		}}},
+		As: tree.AliasClause{Alias: fn.Name(), Cols: cols},
+	}
+	sel.From.Tables = append(tree.TableExprs{values}, sel.From.Tables...)
+	if filter != nil {
+		sel.Where = tree.NewWhere(tree.AstWhere, filter)
+	}
+}
+
+// withUDFVolatility wraps the given result of an inlined call to fn in an
+// identity function with the volatility fn was declared with. The body of a
+// function may be less volatile than its declaration (e.g. a VOLATILE
+// function whose body is SELECT 1), in which case the inlined expression
+// alone would let the optimizer fold, deduplicate or eliminate calls to it.
+func withUDFVolatility(fn cat.Function, expr tree.Expr) tree.Expr {
+	var name string
+	switch fn.Volatility() {
+	case tree.VolatilityStable:
+		name = "crdb_internal.stable_identity"
+	case tree.VolatilityVolatile:
+		name = "crdb_internal.volatile_identity"
+	default:
+		return expr
+	}
+	return &tree.FuncExpr{Func: tree.WrapFunction(name), Exprs: tree.Exprs{expr}}
+}
+
+// parseUDFBody parses the body of the given user-defined function.
+func parseUDFBody(fn cat.Function) *tree.Select {
+	stmt, err := parser.ParseOne(fn.Body())
+	if err != nil {
+		panic(pgerror.Wrapf(err, pgcode.Syntax,
+			"failed to parse body of function %q", fn.Name()))
+	}
+	sel, ok := stmt.AST.(*tree.Select)
+	if !ok {
+		panic(errors.AssertionFailedf("expected SELECT statement"))
+	}
+	return sel
+}
+
+// hasLimit returns true if the given SELECT statement, or any of the
+// parenthesized statements it wraps, has a LIMIT clause.
+func hasLimit(sel *tree.Select) bool {
+	for {
+		if sel.Limit != nil {
+			return true
+		}
+		p, ok := sel.Select.(*tree.ParenSelect)
+		if !ok {
+			return false
+		}
+		sel = p.Select
+	}
+}
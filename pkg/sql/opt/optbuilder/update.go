@@ -13,12 +13,14 @@ package optbuilder
 import (
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/errors"
 )
@@ -97,10 +99,11 @@ func (b *Builder) buildUpdate(upd *tree.Update, inScope *scope) (outScope *scope
 	mb.buildInputForUpdate(inScope, upd.Table, upd.From, upd.Where, upd.Limit, upd.OrderBy)
 
 	// Derive the columns that will be updated from the SET expressions.
-	mb.addTargetColsForUpdate(upd.Exprs)
+	exprs := mb.expandFieldUpdates(upd.Exprs)
+	mb.addTargetColsForUpdate(exprs)
 
 	// Build each of the SET expressions.
-	mb.addUpdateCols(upd.Exprs)
+	mb.addUpdateCols(exprs)
 
 	// Build the final update statement, including any returned expressions.
 	if resultsNeeded(upd.Returning) {
@@ -112,6 +115,95 @@ func (b *Builder) buildUpdate(upd *tree.Update, inScope *scope) (outScope *scope
 	return mb.outScope
 }
 
+// expandFieldUpdates rewrites SET expressions that assign a single field of a
+// column of a composite type into expressions that assign the entire column,
+// preserving the values of its other fields:
+//
+//   SET c.f2 = e
+//     is rewritten to:
+//   SET c = (ROW((c).f1, e, (c).f3))::<type of c>
+//
+// Assignments to several fields of the same column are combined into a single
+// assignment. Expressions that assign entire columns are returned unchanged.
+func (mb *mutationBuilder) expandFieldUpdates(exprs tree.UpdateExprs) tree.UpdateExprs {
+	hasField := false
+	for _, expr := range exprs {
+		if expr.Field != "" {
+			hasField = true
+			break
+		}
+	}
+	if !hasField {
+		return exprs
+	}
+	sqltelemetry.IncrementCompositeTypeCounter(sqltelemetry.CompositeTypeFieldUpdate)
+
+	type fieldUpdate struct {
+		typ      *types.T
+		row      *tree.Tuple
+		assigned []bool
+	}
+	updates := make(map[tree.Name]*fieldUpdate)
+	res := make(tree.UpdateExprs, 0, len(exprs))
+	for _, expr := range exprs {
+		if expr.Field == "" {
+			res = append(res, expr)
+			continue
+		}
+		colName := expr.Names[0]
+		upd, ok := updates[colName]
+		if !ok {
+			ord := findPublicTableColumnByName(mb.tab, colName)
+			if ord == -1 {
+				panic(colinfo.NewUndefinedColumnError(string(colName)))
+			}
+			typ := mb.tab.Column(ord).DatumType()
+			if !typ.IsComposite() {
+				panic(pgerror.Newf(pgcode.DatatypeMismatch,
+					"cannot assign to field %q of column %q because its type %s is not a composite type",
+					expr.Field, colName, typ.SQLString()))
+			}
+			col := tree.NewColumnItem(&mb.alias, colName)
+			upd = &fieldUpdate{
+				typ:      typ,
+				row:      &tree.Tuple{Exprs: make(tree.Exprs, len(typ.TupleContents())), Row: true},
+				assigned: make([]bool, len(typ.TupleContents())),
+			}
+			for i, label := range typ.TupleLabels() {
+				upd.row.Exprs[i] = &tree.ColumnAccessExpr{Expr: col, ColName: label}
+			}
+			updates[colName] = upd
+			res = append(res, &tree.UpdateExpr{
+				Names: tree.NameList{colName},
+				Expr:  &tree.CastExpr{Expr: upd.row, Type: typ, SyntaxMode: tree.CastShort},
+			})
+		}
+		idx := -1
+		for i, label := range upd.typ.TupleLabels() {
+			if label == string(expr.Field) {
+				idx = i
+				break
+			}
+		}
+		if idx == -1 {
+			panic(pgerror.Newf(pgcode.UndefinedColumn,
+				"cannot assign to field %q of column %q because there is no such column in data type %s",
+				expr.Field, colName, upd.typ.SQLString()))
+		}
+		if upd.assigned[idx] {
+			panic(pgerror.Newf(pgcode.Syntax,
+				"multiple assignments to the same column \"%s.%s\"", colName, expr.Field))
+		}
+		if _, ok := expr.Expr.(tree.DefaultVal); ok {
+			panic(unimplementedWithIssueDetailf(27792, "field default",
+				"cannot assign DEFAULT to field %q of column %q", expr.Field, colName))
+		}
+		upd.assigned[idx] = true
+		upd.row.Exprs[idx] = expr.Expr
+	}
+	return res
+}
+
 // addTargetColsForUpdate compiles the given SET expressions and adds the user-
 // specified column names to the list of table columns that will be updated by
 // the Update operation. Verify that the RHS of the SET expression provides
@@ -302,6 +394,9 @@ func (mb *mutationBuilder) addSynthesizedColsForUpdate() {
 	// the inserted columns.
 	mb.roundDecimalValues(mb.updateColIDs, false /* roundComputedCols */)
 
+	// Fire any BEFORE UPDATE triggers, which may replace the updated values.
+	mb.buildBeforeTriggers(tree.TriggerEventUpdate)
+
 	// Disambiguate names so that references in the computed expression refer to
 	// the correct columns.
 	mb.disambiguateColumns()
@@ -334,6 +429,9 @@ func (mb *mutationBuilder) buildUpdate(returning tree.ReturningExprs) {
 	mb.projectPartialIndexPutCols(preCheckScope)
 
 	mb.buildFKChecksForUpdate()
+	mb.buildUniqueChecks(true /* isUpdate */)
+
+	mb.buildAfterTriggers(tree.TriggerEventUpdate)
 
 	private := mb.makeMutationPrivate(returning != nil)
 	for _, col := range mb.extraAccessibleCols {
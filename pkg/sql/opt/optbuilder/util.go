@@ -49,6 +49,9 @@ func getTypedExprs(exprs []tree.Expr) []tree.TypedExpr {
 func (b *Builder) expandStar(
 	expr tree.Expr, inScope *scope,
 ) (aliases []string, exprs []tree.TypedExpr) {
+	if b.insideFuncDef {
+		panic(unimplemented.NewWithIssue(10028, "functions do not currently support * expressions"))
+	}
 	if b.insideViewDef {
 		panic(unimplemented.NewWithIssue(10028, "views do not currently support * expressions"))
 	}
@@ -218,8 +221,8 @@ func (b *Builder) populateSynthesizedColumn(col *scopeColumn, scalar opt.ScalarE
 // - We don't copy hidden, because projecting a column makes it visible.
 //   dst already has hidden=false, so keep it as-is.
 // - We don't copy table, since the table becomes anonymous in the new scope.
-// - We don't copy descending, since we don't want to overwrite dst.descending
-//   if dst is an ORDER BY column.
+// - We don't copy descending and nullsReversed, since we don't want to
+//   overwrite them if dst is an ORDER BY column.
 // - expr, exprStr and typ in dst already correspond to the expression and type
 //   of the src column.
 func (b *Builder) projectColumn(dst *scopeColumn, src *scopeColumn) {
@@ -240,6 +240,7 @@ func (b *Builder) buildAggregationAsWindow(
 
 	// Construct the pre-projection, which renders the grouping columns and the
 	// aggregate arguments, as well as any additional order by columns.
+	groupingCols := g.groupingCols()
 	g.aggInScope.appendColumnsFromScope(fromScope)
 	b.constructProjectForScope(fromScope, g.aggInScope)
 
@@ -267,6 +268,9 @@ func (b *Builder) buildAggregationAsWindow(
 
 	// Initialize the aggregate expression.
 	aggregateExpr := g.aggInScope.expr
+	if g.groupingSetCols != nil {
+		aggregateExpr = b.constructGroupingSetsInput(aggregateExpr, groupingCols, g)
+	}
 
 	// frames accumulates the set of distinct window frames we're computing over
 	// so that we can group functions over the same partition and ordering.
@@ -302,6 +306,9 @@ func (b *Builder) buildAggregationAsWindow(
 	// instead of each group. To rectify this, we must 'squash' the values down by
 	// wrapping it with a GroupBy or ScalarGroupBy.
 	g.aggOutScope.expr = b.constructWindowGroup(aggregateExpr, groupingColSet, g.aggs, g.aggOutScope)
+	if g.groupingSetCols != nil {
+		g.aggOutScope.expr = b.constructEmptyGroupingSets(g.aggOutScope.expr.(memo.RelExpr), g)
+	}
 
 	// Wrap with having filter if it exists.
 	if having != nil {
@@ -409,7 +416,9 @@ func (b *Builder) buildWindowOrdering(
 					b.buildScalar(e, inScope, nil, nil, nil),
 				)
 			}
-			ord = append(ord, opt.MakeOrderingColumn(col.id, t.Direction == tree.Descending))
+			ord = append(ord, makeOrderingColumn(
+				inScope, col.id, t.Direction == tree.Descending, t.NullsOrder.IsReversed(t.Direction),
+			))
 		}
 	}
 	return ord
@@ -13,15 +13,21 @@ package opt
 import (
 	"bytes"
 	"fmt"
+	"io"
 
 	"github.com/cockroachdb/errors"
 )
 
 // OrderingColumn is the ColumnID for a column that is part of an ordering,
 // except that it can be negated to indicate a descending ordering on that
-// column.
+// column. It can also be flagged to indicate that NULLs are ordered opposite to
+// the default for the direction; see NullsReversed.
 type OrderingColumn int32
 
+// nullsReversedFlag is set in the absolute value of an OrderingColumn whose
+// NULL ordering is reversed. It is above the range of valid column IDs.
+const nullsReversedFlag = 1 << 30
+
 // MakeOrderingColumn initializes an ordering column with a ColumnID and a flag
 // indicating whether the direction is descending.
 func MakeOrderingColumn(id ColumnID, descending bool) OrderingColumn {
@@ -31,12 +37,21 @@ func MakeOrderingColumn(id ColumnID, descending bool) OrderingColumn {
 	return OrderingColumn(id)
 }
 
+// MakeOrderingColumnWithNulls is like MakeOrderingColumn, except that it also
+// takes a flag indicating whether the NULL ordering is reversed.
+func MakeOrderingColumnWithNulls(id ColumnID, descending, nullsReversed bool) OrderingColumn {
+	if nullsReversed {
+		id |= nullsReversedFlag
+	}
+	return MakeOrderingColumn(id, descending)
+}
+
 // ID returns the ColumnID for this OrderingColumn.
 func (c OrderingColumn) ID() ColumnID {
 	if c < 0 {
-		return ColumnID(-c)
+		c = -c
 	}
-	return ColumnID(c)
+	return ColumnID(c &^ nullsReversedFlag)
 }
 
 // Ascending returns true if the ordering on this column is ascending.
@@ -49,12 +64,29 @@ func (c OrderingColumn) Descending() bool {
 	return c < 0
 }
 
+// NullsReversed returns true if NULLs are ordered opposite to the default for
+// the direction of this column. By default, NULLs are ordered first when the
+// direction is ascending and last when it is descending, as if they were
+// smaller than any other value.
+func (c OrderingColumn) NullsReversed() bool {
+	if c < 0 {
+		c = -c
+	}
+	return c&nullsReversedFlag != 0
+}
+
+// NullsFirst returns true if NULLs are ordered before all other values in this
+// column.
+func (c OrderingColumn) NullsFirst() bool {
+	return c.Descending() == c.NullsReversed()
+}
+
 // RemapColumn returns a new OrderingColumn that uses a ColumnID from the 'to'
 // table. The original ColumnID must be from the 'from' table.
 func (c OrderingColumn) RemapColumn(from, to TableID) OrderingColumn {
 	ord := from.ColumnOrdinal(c.ID())
 	newColID := to.ColumnID(ord)
-	return MakeOrderingColumn(newColID, c.Descending())
+	return MakeOrderingColumnWithNulls(newColID, c.Descending(), c.NullsReversed())
 }
 
 func (c OrderingColumn) String() string {
@@ -71,6 +103,20 @@ func (c OrderingColumn) Format(buf *bytes.Buffer) {
 		buf.WriteByte('+')
 	}
 	fmt.Fprintf(buf, "%d", c.ID())
+	FormatNullsOrder(buf, c.Descending(), c.NullsReversed())
+}
+
+// FormatNullsOrder prints the NULL ordering of an ordering column to the
+// buffer if it is reversed; the default NULL ordering is not printed.
+func FormatNullsOrder(buf io.StringWriter, descending, nullsReversed bool) {
+	if !nullsReversed {
+		return
+	}
+	if descending {
+		_, _ = buf.WriteString(":nulls-first")
+	} else {
+		_, _ = buf.WriteString(":nulls-last")
+	}
 }
 
 // Ordering defines the order of rows provided or required by an operator. A
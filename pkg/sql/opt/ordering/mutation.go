@@ -37,8 +37,9 @@ func mutationBuildChildReqOrdering(
 	for i := range required.Columns {
 		colChoice := &required.Columns[i]
 		columns[i] = physical.OrderingColumnChoice{
-			Group:      private.MapToInputCols(colChoice.Group),
-			Descending: colChoice.Descending,
+			Group:         private.MapToInputCols(colChoice.Group),
+			Descending:    colChoice.Descending,
+			NullsReversed: colChoice.NullsReversed,
 		}
 	}
 	return physical.OrderingChoice{Optional: optional, Columns: columns}
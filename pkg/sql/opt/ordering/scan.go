@@ -94,6 +94,12 @@ func ScanPrivateCanProvide(
 		if !reqCol.Group.Contains(indexColID) {
 			return false, false
 		}
+		// The NULL ordering is the same in both scan directions, because
+		// reversing an ascending column with NULLs last results in a descending
+		// column with NULLs first.
+		if indexCol.NullsReversed != reqCol.NullsReversed {
+			return false, false
+		}
 		// The directions of the index column and the required column impose either
 		// a forward or a reverse scan.
 		required := fwd
@@ -140,7 +146,7 @@ func scanBuildProvided(expr memo.RelExpr, required *physical.OrderingChoice) opt
 			continue
 		}
 		direction := (indexCol.Descending != reverse) // != is bool XOR
-		provided = append(provided, opt.MakeOrderingColumn(colID, direction))
+		provided = append(provided, opt.MakeOrderingColumnWithNulls(colID, direction, indexCol.NullsReversed))
 	}
 
 	return trimProvided(provided, required, fds)
@@ -173,6 +173,11 @@ func (h *Histogram) CanFilter(c *constraint.Constraint) (colOffset, exactPrefix
 	constrainedCols := c.ConstrainedColumns(h.evalCtx)
 	for i := 0; i < constrainedCols && i <= exactPrefix; i++ {
 		if c.Columns.Get(i).ID() == h.col {
+			if c.Columns.Get(i).NullsReversed() {
+				// Histogram buckets are ordered with NULLs first, so they can't be
+				// filtered by spans in which NULLs are ordered last.
+				break
+			}
 			return i, exactPrefix, true
 		}
 	}
@@ -220,6 +220,21 @@ func (tm *TableMeta) IndexKeyColumnsMapVirtual(indexOrd int) ColSet {
 	return indexCols
 }
 
+// IndexVirtualComputedColumns returns the metadata IDs for the set of virtual
+// computed columns in the given index.
+func (tm *TableMeta) IndexVirtualComputedColumns(indexOrd int) ColSet {
+	index := tm.Table.Index(indexOrd)
+
+	var indexCols ColSet
+	for i, n := 0, index.ColumnCount(); i < n; i++ {
+		col := index.Column(i)
+		if col.IsVirtualComputed() {
+			indexCols.Add(tm.MetaID.ColumnID(col.Ordinal()))
+		}
+	}
+	return indexCols
+}
+
 // SetConstraints sets the filters derived from check constraints; see
 // TableMeta.Constraint. The argument must be a *FiltersExpr.
 func (tm *TableMeta) SetConstraints(constraints ScalarExpr) {
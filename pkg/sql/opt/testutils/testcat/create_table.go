@@ -178,7 +178,14 @@ func (tc *Catalog) CreateTable(stmt *tree.CreateTable) *Table {
 	for _, def := range stmt.Defs {
 		switch def := def.(type) {
 		case *tree.UniqueConstraintTableDef:
-			if !def.PrimaryKey {
+			if def.PrimaryKey {
+				break
+			}
+			if def.Deferrability != tree.ConstraintNotDeferrable {
+				// The index of a deferrable unique constraint is not unique.
+				idx := tab.addIndex(&def.IndexTableDef, nonUniqueIndex)
+				idx.deferrability = def.Deferrability
+			} else {
 				tab.addIndex(&def.IndexTableDef, uniqueIndex)
 			}
 
@@ -190,13 +197,18 @@ func (tc *Catalog) CreateTable(stmt *tree.CreateTable) *Table {
 
 		case *tree.ColumnTableDef:
 			if def.Unique {
-				tab.addIndex(
+				typ := uniqueIndex
+				if def.UniqueDeferrability != tree.ConstraintNotDeferrable {
+					typ = nonUniqueIndex
+				}
+				idx := tab.addIndex(
 					&tree.IndexTableDef{
 						Name:    tree.Name(fmt.Sprintf("%s_%s_key", stmt.Table.ObjectName, def.Name)),
 						Columns: tree.IndexElemList{{Column: def.Name}},
 					},
-					uniqueIndex,
+					typ,
 				)
+				idx.deferrability = def.UniqueDeferrability
 			}
 		}
 	}
@@ -209,6 +221,11 @@ func (tc *Catalog) CreateTable(stmt *tree.CreateTable) *Table {
 OuterLoop:
 	for colOrd := range tab.Columns {
 		col := &tab.Columns[colOrd]
+		if col.IsVirtualComputed() {
+			// Virtual computed columns are not stored, so they are not part of any
+			// family.
+			continue
+		}
 		for _, fam := range tab.Families {
 			for _, famCol := range fam.Columns {
 				if col.ColName() == famCol.ColName() {
@@ -416,6 +433,7 @@ func (tc *Catalog) resolveFK(tab *Table, d *tree.ForeignKeyConstraintTableDef) {
 		matchMethod:              d.Match,
 		deleteAction:             d.Actions.Delete,
 		updateAction:             d.Actions.Update,
+		deferrability:            d.Deferrability,
 	}
 	tab.outboundFKs = append(tab.outboundFKs, fk)
 	targetTable.inboundFKs = append(targetTable.inboundFKs, fk)
@@ -450,28 +468,42 @@ func (tt *Table) addColumn(def *tree.ColumnTableDef) {
 	}
 
 	var col cat.Column
-	col.InitNonVirtual(
-		ordinal,
-		cat.StableID(1+ordinal),
-		name,
-		kind,
-		typ,
-		nullable,
-		false, /* hidden */
-		defaultExpr,
-		computedExpr,
-	)
+	if def.Computed.Virtual {
+		col.InitVirtualComputed(
+			ordinal,
+			cat.StableID(1+ordinal),
+			name,
+			kind,
+			typ,
+			nullable,
+			false, /* hidden */
+			*computedExpr,
+		)
+	} else {
+		col.InitNonVirtual(
+			ordinal,
+			cat.StableID(1+ordinal),
+			name,
+			kind,
+			typ,
+			nullable,
+			false, /* hidden */
+			defaultExpr,
+			computedExpr,
+		)
+	}
 	tt.Columns = append(tt.Columns, col)
 }
 
 func (tt *Table) addIndex(def *tree.IndexTableDef, typ indexType) *Index {
 	idx := &Index{
-		IdxName:     tt.makeIndexName(def.Name, typ),
-		Unique:      typ != nonUniqueIndex,
-		Inverted:    def.Inverted,
-		IdxZone:     &zonepb.ZoneConfig{},
-		table:       tt,
-		partitionBy: def.PartitionBy,
+		IdxName:       tt.makeIndexName(def.Name, typ),
+		Unique:        typ != nonUniqueIndex,
+		ExplicitCount: len(def.Columns),
+		Inverted:      def.Inverted,
+		IdxZone:       &zonepb.ZoneConfig{},
+		table:         tt,
+		partitionBy:   def.PartitionBy,
 	}
 
 	// Look for name suffixes indicating this is a mutation index.
@@ -493,10 +525,15 @@ func (tt *Table) addIndex(def *tree.IndexTableDef, typ indexType) *Index {
 			panic(fmt.Errorf("column %s of type %s is not indexable", colDef.Column, colType))
 		}
 		if def.Inverted && i == 0 && !colinfo.ColumnTypeIsInvertedIndexable(colType) {
-			panic(fmt.Errorf("column %s of type %s is not inverted indexable", colDef.Column, colType))
+			if !colinfo.ColumnTypeIsTrigramIndexable(colType) || colDef.OpClass == "" {
+				panic(fmt.Errorf("column %s of type %s is not inverted indexable", colDef.Column, colType))
+			}
 		}
 
 		col := idx.addColumn(tt, string(colDef.Column), colDef.Direction, keyCol)
+		if typ != primaryIndex && col.IsNullable() && colDef.NullsOrder.IsReversed(colDef.Direction) {
+			idx.Columns[len(idx.Columns)-1].NullsReversed = true
+		}
 
 		if typ == primaryIndex && col.IsNullable() {
 			// Reinitialize the column to make it non-nullable.
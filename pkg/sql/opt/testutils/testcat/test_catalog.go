@@ -177,6 +177,20 @@ func (tc *Catalog) ResolveTypeByOID(context.Context, oid.Oid) (*types.T, error)
 	return nil, errors.Newf("test catalog cannot handle user defined types")
 }
 
+// ResolveFunction is part of the cat.Catalog interface.
+func (tc *Catalog) ResolveFunction(
+	context.Context, cat.Flags, *tree.UnresolvedObjectName,
+) (cat.Function, error) {
+	return nil, nil
+}
+
+// ResolveFunctionByID is part of the cat.Catalog interface.
+func (tc *Catalog) ResolveFunctionByID(
+	context.Context, cat.Flags, cat.StableID,
+) (cat.Function, error) {
+	return nil, errors.Newf("test catalog cannot handle user defined functions")
+}
+
 // CheckPrivilege is part of the cat.Catalog interface.
 func (tc *Catalog) CheckPrivilege(ctx context.Context, o cat.Object, priv privilege.Kind) error {
 	return tc.CheckAnyPrivilege(ctx, o)
@@ -710,6 +724,16 @@ func (tt *Table) InboundForeignKey(i int) cat.ForeignKeyConstraint {
 	return &tt.inboundFKs[i]
 }
 
+// TriggerCount is part of the cat.Table interface.
+func (tt *Table) TriggerCount() int {
+	return 0
+}
+
+// Trigger is part of the cat.Table interface.
+func (tt *Table) Trigger(i int) cat.Trigger {
+	panic("no triggers")
+}
+
 // FindOrdinal returns the ordinal of the column with the given name.
 func (tt *Table) FindOrdinal(name string) int {
 	for i, col := range tt.Columns {
@@ -740,6 +764,10 @@ type Index struct {
 	// Unique is true if this index is declared as UNIQUE in the schema.
 	Unique bool
 
+	// ExplicitCount is the number of columns that are part of the index
+	// definition. See the cat.Index.ExplicitColumnCount for more details.
+	ExplicitCount int
+
 	// Inverted is true when this index is an inverted index.
 	Inverted bool
 
@@ -762,6 +790,10 @@ type Index struct {
 	// predicate is the partial index predicate expression, if it exists.
 	predicate string
 
+	// deferrability is the deferrability of the unique constraint of the index,
+	// if it enforces a deferrable unique constraint.
+	deferrability tree.ConstraintDeferrability
+
 	// geoConfig is the geospatial index configuration, if this is a geospatial
 	// inverted index. Otherwise geoConfig is nil.
 	geoConfig *geoindex.Config
@@ -792,6 +824,11 @@ func (ti *Index) IsUnique() bool {
 	return ti.Unique
 }
 
+// UniqueDeferrability is part of the cat.Index interface.
+func (ti *Index) UniqueDeferrability() tree.ConstraintDeferrability {
+	return ti.deferrability
+}
+
 // IsInverted is part of the cat.Index interface.
 func (ti *Index) IsInverted() bool {
 	return ti.Inverted
@@ -802,6 +839,11 @@ func (ti *Index) ColumnCount() int {
 	return len(ti.Columns)
 }
 
+// ExplicitColumnCount is part of the cat.Index interface.
+func (ti *Index) ExplicitColumnCount() int {
+	return ti.ExplicitCount
+}
+
 // KeyColumnCount is part of the cat.Index interface.
 func (ti *Index) KeyColumnCount() int {
 	return ti.KeyCount
@@ -1021,6 +1063,8 @@ type ForeignKeyConstraint struct {
 	matchMethod  tree.CompositeKeyMatchMethod
 	deleteAction tree.ReferenceAction
 	updateAction tree.ReferenceAction
+
+	deferrability tree.ConstraintDeferrability
 }
 
 var _ cat.ForeignKeyConstraint = &ForeignKeyConstraint{}
@@ -1088,6 +1132,16 @@ func (fk *ForeignKeyConstraint) UpdateReferenceAction() tree.ReferenceAction {
 	return fk.updateAction
 }
 
+// Deferrable is part of the cat.ForeignKeyConstraint interface.
+func (fk *ForeignKeyConstraint) Deferrable() bool {
+	return fk.deferrability != tree.ConstraintNotDeferrable
+}
+
+// InitiallyDeferred is part of the cat.ForeignKeyConstraint interface.
+func (fk *ForeignKeyConstraint) InitiallyDeferred() bool {
+	return fk.deferrability == tree.ConstraintInitiallyDeferred
+}
+
 // Sequence implements the cat.Sequence interface for testing purposes.
 type Sequence struct {
 	SeqID      cat.StableID
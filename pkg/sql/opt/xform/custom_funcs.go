@@ -969,6 +969,197 @@ func (c *CustomFuncs) partitionValuesFilters(
 	return inPartition, inBetween
 }
 
+// HasIndexedVirtualCols returns true if at least one virtual computed column
+// of the Scan operator's table is part of a non-inverted secondary index or is
+// referenced by the predicate of a partial index.
+func (c *CustomFuncs) HasIndexedVirtualCols(scanPrivate *memo.ScanPrivate) bool {
+	tabMeta := c.e.mem.Metadata().TableMeta(scanPrivate.Table)
+	iter := makeScanIndexIter(c.e.mem, scanPrivate, rejectPrimaryIndex|rejectInvertedIndexes)
+	for iter.Next() {
+		if !c.indexedVirtualCols(tabMeta, iter.IndexOrdinal()).Empty() {
+			return true
+		}
+	}
+	return false
+}
+
+// indexedVirtualCols returns the virtual computed columns that are part of the
+// given index or are referenced by its partial index predicate. Only columns
+// with an expression in TableMeta.ComputedCols are returned.
+func (c *CustomFuncs) indexedVirtualCols(tabMeta *opt.TableMeta, indexOrd int) opt.ColSet {
+	cols := tabMeta.IndexVirtualComputedColumns(indexOrd)
+	if _, isPartialIndex := tabMeta.Table.Index(indexOrd).Predicate(); isPartialIndex {
+		predCols := c.FilterOuterCols(memo.PartialIndexPredicate(tabMeta, indexOrd))
+		for col, ok := predCols.Next(0); ok; col, ok = predCols.Next(col + 1) {
+			if tabMeta.Table.Column(tabMeta.MetaID.ColumnOrdinal(col)).IsVirtualComputed() {
+				cols.Add(col)
+			}
+		}
+	}
+	for col, ok := cols.Next(0); ok; col, ok = cols.Next(col + 1) {
+		if _, ok := tabMeta.ComputedCols[col]; !ok {
+			cols.Remove(col)
+		}
+	}
+	return cols
+}
+
+// GenerateVirtualColumnScans enumerates all non-inverted secondary indexes that
+// contain virtual computed columns (or whose partial index predicates reference
+// them) and tries to use them to satisfy the given filters. Virtual computed
+// columns are not produced by the canonical Scan, so the filters reference
+// their expressions instead. For each index, the filters are rewritten so that
+// the expressions of the virtual columns are replaced with references to the
+// columns, and the rewritten filters are used to constrain the index or to
+// prove that the filters imply a partial index predicate.
+//
+// The virtual columns are read from the index. Since they cannot be fetched from
+// the primary index, they are not available above an IndexJoin; any filters
+// that remain after an IndexJoin reference the expressions of the virtual
+// columns again. The resulting expressions are:
+//
+//   (Project (Select (Scan $scanDef) $filter) [] $cols)
+//
+//   (Select
+//     (IndexJoin
+//       (Select (Scan $scanDef) $innerFilter)
+//       $indexJoinDef
+//     )
+//     $outerFilter
+//   )
+//
+// where the Project, Selects and IndexJoin are omitted when they are not
+// needed.
+func (c *CustomFuncs) GenerateVirtualColumnScans(
+	grp memo.RelExpr, scanPrivate *memo.ScanPrivate, filters memo.FiltersExpr,
+) {
+	md := c.e.mem.Metadata()
+	tabMeta := md.TableMeta(scanPrivate.Table)
+
+	iter := makeScanIndexIter(c.e.mem, scanPrivate, rejectPrimaryIndex|rejectInvertedIndexes)
+	for iter.Next() {
+		virtualCols := c.indexedVirtualCols(tabMeta, iter.IndexOrdinal())
+		if virtualCols.Empty() {
+			continue
+		}
+
+		// Replace the expressions of the virtual columns in the filters with
+		// references to the columns. If the filters do not reference any of the
+		// virtual columns after that, the index is handled by the other rules.
+		projections := make(memo.ProjectionsExpr, 0, virtualCols.Len())
+		for col, ok := virtualCols.Next(0); ok; col, ok = virtualCols.Next(col + 1) {
+			projections = append(projections, c.e.f.ConstructProjectionsItem(tabMeta.ComputedCols[col], col))
+		}
+		remainingFilters := c.mapVirtualColExprs(filters, projections)
+		if !c.FilterOuterCols(remainingFilters).Intersects(virtualCols) {
+			continue
+		}
+
+		// If the index is a partial index, the filters must imply its predicate.
+		_, isPartialIndex := md.Table(scanPrivate.Table).Index(iter.IndexOrdinal()).Predicate()
+		if isPartialIndex {
+			pred := memo.PartialIndexPredicate(tabMeta, iter.IndexOrdinal())
+			var ok bool
+			remainingFilters, ok = c.im.FiltersImplyPredicate(remainingFilters, pred)
+			if !ok {
+				continue
+			}
+		}
+
+		newScanPrivate := *scanPrivate
+		newScanPrivate.Index = iter.IndexOrdinal()
+		constraint, remaining, ok := c.tryConstrainIndex(
+			remainingFilters,
+			nil, /* optionalFilters */
+			scanPrivate.Table,
+			iter.IndexOrdinal(),
+			false, /* isInverted */
+		)
+		if ok {
+			newScanPrivate.Constraint = constraint
+			remainingFilters = remaining
+		} else if !isPartialIndex {
+			continue
+		}
+
+		// Virtual columns that are only referenced by the partial index predicate
+		// cannot be read from the index, so inline their expressions again.
+		indexCols := iter.IndexColumns()
+		var notIndexed memo.ProjectionsExpr
+		for i := range projections {
+			if !indexCols.Contains(projections[i].Col) {
+				notIndexed = append(notIndexed, projections[i])
+			}
+		}
+		if notIndexed != nil {
+			remainingFilters = c.InlineSelectProject(remainingFilters, notIndexed)
+		}
+		virtualCols.IntersectionWith(indexCols)
+
+		var sb indexScanBuilder
+		sb.init(c, scanPrivate.Table)
+		if scanPrivate.Cols.SubsetOf(indexCols) {
+			// Scan the virtual columns that are needed by the remaining filters,
+			// in addition to the columns needed by the original Scan.
+			newScanPrivate.Cols = scanPrivate.Cols.Union(
+				c.FilterOuterCols(remainingFilters).Intersection(virtualCols),
+			)
+			if newScanPrivate.Cols.Equals(scanPrivate.Cols) {
+				sb.setScan(&newScanPrivate)
+				sb.addSelect(remainingFilters)
+				sb.build(grp)
+				continue
+			}
+
+			// The virtual columns needed by the remaining filters are not part of
+			// the output, so project them away.
+			input := c.e.f.ConstructSelect(c.e.f.ConstructScan(&newScanPrivate), remainingFilters)
+			c.e.mem.AddProjectToGroup(&memo.ProjectExpr{
+				Input:       input,
+				Projections: memo.EmptyProjectionsExpr,
+				Passthrough: scanPrivate.Cols,
+			}, grp)
+			continue
+		}
+
+		// The index is not covering, so scan the needed index columns plus the
+		// primary key columns and use an IndexJoin to retrieve the rest of the
+		// columns. Only the virtual columns needed by the filters that can be
+		// applied before the IndexJoin are scanned.
+		newScanPrivate.Cols = indexCols.Intersection(scanPrivate.Cols)
+		newScanPrivate.Cols.UnionWith(sb.primaryKeyCols())
+		innerFilters := c.ExtractBoundConditions(remainingFilters, newScanPrivate.Cols.Union(virtualCols))
+		newScanPrivate.Cols.UnionWith(c.FilterOuterCols(innerFilters).Intersection(virtualCols))
+		sb.setScan(&newScanPrivate)
+		remainingFilters = sb.addSelectAfterSplit(remainingFilters, newScanPrivate.Cols)
+		sb.addIndexJoin(scanPrivate.Cols)
+		sb.addSelect(c.InlineSelectProject(remainingFilters, projections))
+		sb.build(grp)
+	}
+}
+
+// mapVirtualColExprs replaces any expression in the filters that is identical
+// to one of the given projections with a reference to the projected column.
+func (c *CustomFuncs) mapVirtualColExprs(
+	filters memo.FiltersExpr, projections memo.ProjectionsExpr,
+) memo.FiltersExpr {
+	var replace norm.ReplaceFunc
+	replace = func(e opt.Expr) opt.Expr {
+		for i := range projections {
+			if e == projections[i].Element {
+				return c.e.f.ConstructVariable(projections[i].Col)
+			}
+		}
+		return c.e.f.Replace(e, replace)
+	}
+
+	newFilters := make(memo.FiltersExpr, len(filters))
+	for i := range filters {
+		newFilters[i] = c.e.f.ConstructFiltersItem(replace(filters[i].Condition).(opt.ScalarExpr))
+	}
+	return newFilters
+}
+
 // HasInvertedIndexes returns true if at least one inverted index is defined on
 // the Scan operator's table.
 func (c *CustomFuncs) HasInvertedIndexes(scanPrivate *memo.ScanPrivate) bool {
@@ -1000,7 +1191,7 @@ func (c *CustomFuncs) GenerateInvertedIndexScans(
 		var pfState *invertedexpr.PreFiltererStateForInvertedFilterer
 		var spansToRead invertedexpr.InvertedSpans
 		var constraint *constraint.Constraint
-		var geoOk, nonGeoOk bool
+		var trigramOk, geoOk, nonGeoOk bool
 		remaining := filters
 
 		// If the index is a partial index, check whether or not the filter
@@ -1018,13 +1209,24 @@ func (c *CustomFuncs) GenerateInvertedIndexScans(
 		}
 
 		// Check whether the filter can constrain the index.
-		// TODO(rytaft): Unify these two cases so both return a spanExpr.
-		spanExpr, pfState, geoOk = invertedidx.TryConstrainGeoIndex(
-			c.e.evalCtx.Context, c.e.f, remaining, scanPrivate.Table, iter.Index(),
-		)
-		if geoOk {
-			// Geo index scans can never be tight, so the remaining filters do
-			// not change.
+		// TODO(rytaft): Unify these cases so they all return a spanExpr.
+		if invertedidx.IsTrigramIndex(iter.Index()) {
+			spanExpr, trigramOk = invertedidx.TryConstrainTrigramIndex(
+				remaining, scanPrivate.Table, iter.Index(),
+			)
+			if !trigramOk {
+				// Trigram indexes cannot be constrained like other inverted
+				// indexes, since their keys are not values of the indexed column.
+				continue
+			}
+		} else {
+			spanExpr, pfState, geoOk = invertedidx.TryConstrainGeoIndex(
+				c.e.evalCtx.Context, c.e.f, remaining, scanPrivate.Table, iter.Index(),
+			)
+		}
+		if trigramOk || geoOk {
+			// Trigram and geo index scans can never be tight, so the remaining
+			// filters do not change.
 			spansToRead = spanExpr.SpansToRead
 		} else {
 			constraint, remaining, nonGeoOk = c.tryConstrainIndex(
@@ -1107,7 +1309,7 @@ func (c *CustomFuncs) initIdxConstraintForIndex(
 			nullable = col.IsNullable()
 		}
 		colID := tabID.ColumnID(ordinal)
-		columns[i] = opt.MakeOrderingColumn(colID, col.Descending)
+		columns[i] = opt.MakeOrderingColumnWithNulls(colID, col.Descending, col.NullsReversed)
 		if !nullable {
 			notNullCols.Add(colID)
 		}
@@ -1651,6 +1853,8 @@ func (c *CustomFuncs) GenerateMergeJoins(
 		merge.RightEq = make(opt.Ordering, n)
 		merge.LeftOrdering.Columns = make([]physical.OrderingColumnChoice, 0, n)
 		merge.RightOrdering.Columns = make([]physical.OrderingColumnChoice, 0, n)
+		// The merge joiner relies on NULLs being ordered before all other values
+		// (in the column's direction), so the NULL ordering of o is not used.
 		for i := 0; i < n; i++ {
 			eqIdx, _ := colToEq.Get(int(o[i].ID()))
 			l, r, descending := leftEq[eqIdx], rightEq[eqIdx], o[i].Descending()
@@ -3039,7 +3243,8 @@ func (c *CustomFuncs) GenerateStreamingGroupBy(
 
 			if intraIdx < len(intraOrd.Columns) &&
 				intraOrd.Columns[intraIdx].Group.Contains(oCol) &&
-				intraOrd.Columns[intraIdx].Descending == o[oIdx].Descending() {
+				intraOrd.Columns[intraIdx].Descending == o[oIdx].Descending() &&
+				intraOrd.Columns[intraIdx].NullsReversed == o[oIdx].NullsReversed() {
 				// Column matches the one in the ordering.
 				intraIdx++
 				continue
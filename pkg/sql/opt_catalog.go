@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
@@ -34,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
@@ -259,6 +261,56 @@ func (oc *optCatalog) ResolveType(
 	return oc.planner.ResolveType(ctx, name)
 }
 
+// ResolveFunction is part of the cat.Catalog interface.
+func (oc *optCatalog) ResolveFunction(
+	ctx context.Context, flags cat.Flags, name *tree.UnresolvedObjectName,
+) (cat.Function, error) {
+	if flags.AvoidDescriptorCaches {
+		defer func(prev bool) {
+			oc.planner.avoidCachedDescriptors = prev
+		}(oc.planner.avoidCachedDescriptors)
+		oc.planner.avoidCachedDescriptors = true
+	}
+
+	lookupFlags := tree.ObjectLookupFlags{
+		CommonLookupFlags: tree.CommonLookupFlags{Required: false},
+		DesiredObjectKind: tree.FunctionObject,
+	}
+	desc, _, err := resolver.ResolveExistingObject(ctx, oc.planner, name, lookupFlags)
+	if err != nil || desc == nil {
+		return nil, err
+	}
+	fn := desc.(*funcdesc.Immutable)
+
+	// Ensure that the current user can access the target schema.
+	if err := oc.planner.canResolveDescUnderSchema(ctx, fn.ParentSchemaID, fn); err != nil {
+		return nil, err
+	}
+	return oc.newOptFunction(ctx, fn)
+}
+
+// ResolveFunctionByID is part of the cat.Catalog interface.
+func (oc *optCatalog) ResolveFunctionByID(
+	ctx context.Context, flags cat.Flags, id cat.StableID,
+) (cat.Function, error) {
+	if flags.AvoidDescriptorCaches {
+		defer func(prev bool) {
+			oc.planner.avoidCachedDescriptors = prev
+		}(oc.planner.avoidCachedDescriptors)
+		oc.planner.avoidCachedDescriptors = true
+	}
+
+	lookupFlags := tree.ObjectLookupFlagsWithRequired()
+	lookupFlags.AvoidCached = oc.planner.avoidCachedDescriptors
+	fn, err := oc.planner.Descriptors().GetFunctionVersionByID(
+		ctx, oc.planner.Txn(), descpb.ID(id), lookupFlags,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return oc.newOptFunction(ctx, fn)
+}
+
 func getDescFromCatalogObjectForPermissions(o cat.Object) (catalog.Descriptor, error) {
 	switch t := o.(type) {
 	case *optSchema:
@@ -271,6 +323,8 @@ func getDescFromCatalogObjectForPermissions(o cat.Object) (catalog.Descriptor, e
 		return t.desc, nil
 	case *optSequence:
 		return t.desc, nil
+	case *optFunction:
+		return t.desc, nil
 	default:
 		return nil, errors.AssertionFailedf("invalid object type: %T", o)
 	}
@@ -516,6 +570,101 @@ func (ov *optView) ColumnName(i int) tree.Name {
 	return tree.Name(ov.desc.Columns[i].Name)
 }
 
+// optFunction is a wrapper around funcdesc.Immutable that implements the
+// cat.Object and cat.Function interfaces.
+type optFunction struct {
+	desc *funcdesc.Immutable
+
+	// returnType is the hydrated return type of the function.
+	returnType *types.T
+}
+
+var _ cat.Function = &optFunction{}
+
+func (oc *optCatalog) newOptFunction(
+	ctx context.Context, desc *funcdesc.Immutable,
+) (*optFunction, error) {
+	returnType := desc.ReturnType
+	if returnType != nil && returnType.UserDefined() {
+		// Composite return types are stored as references to the type
+		// descriptor, which must be resolved to hydrate the type.
+		var err error
+		if returnType, err = oc.planner.ResolveTypeByOID(ctx, returnType.Oid()); err != nil {
+			return nil, err
+		}
+	}
+	return &optFunction{desc: desc, returnType: returnType}, nil
+}
+
+// ID is part of the cat.Object interface.
+func (of *optFunction) ID() cat.StableID {
+	return cat.StableID(of.desc.ID)
+}
+
+// PostgresDescriptorID is part of the cat.Object interface.
+func (of *optFunction) PostgresDescriptorID() cat.StableID {
+	return cat.StableID(of.desc.ID)
+}
+
+// Equals is part of the cat.Object interface.
+func (of *optFunction) Equals(other cat.Object) bool {
+	otherFunc, ok := other.(*optFunction)
+	if !ok {
+		return false
+	}
+	return of.desc.ID == otherFunc.desc.ID && of.desc.Version == otherFunc.desc.Version
+}
+
+// Name is part of the cat.Function interface.
+func (of *optFunction) Name() tree.Name {
+	return tree.Name(of.desc.Name)
+}
+
+// ParamCount is part of the cat.Function interface.
+func (of *optFunction) ParamCount() int {
+	return len(of.desc.Params)
+}
+
+// ParamName is part of the cat.Function interface.
+func (of *optFunction) ParamName(i int) tree.Name {
+	return tree.Name(of.desc.Params[i].Name)
+}
+
+// ParamType is part of the cat.Function interface.
+func (of *optFunction) ParamType(i int) *types.T {
+	return of.desc.Params[i].Type
+}
+
+// ReturnType is part of the cat.Function interface.
+func (of *optFunction) ReturnType() *types.T {
+	return of.returnType
+}
+
+// ReturnsSet is part of the cat.Function interface.
+func (of *optFunction) ReturnsSet() bool {
+	return of.desc.ReturnsSet
+}
+
+// Strict is part of the cat.Function interface.
+func (of *optFunction) Strict() bool {
+	return of.desc.Strict
+}
+
+// Volatility is part of the cat.Function interface.
+func (of *optFunction) Volatility() tree.Volatility {
+	return of.desc.GetVolatility()
+}
+
+// ReturnsTrigger is part of the cat.Function interface.
+func (of *optFunction) ReturnsTrigger() bool {
+	return of.desc.ReturnsTrigger
+}
+
+// Body is part of the cat.Function interface.
+func (of *optFunction) Body() string {
+	return of.desc.Body
+}
+
 // optSequence is a wrapper around sqlbase.Immutable that
 // implements the cat.Object and cat.DataSource interfaces.
 type optSequence struct {
@@ -650,6 +799,19 @@ func newOptTable(
 			kind = cat.DeleteOnly
 		}
 
+		if desc.Virtual {
+			ot.columns[i].InitVirtualComputed(
+				i,
+				cat.StableID(desc.ID),
+				tree.Name(desc.Name),
+				kind,
+				desc.Type,
+				desc.Nullable,
+				desc.Hidden,
+				*desc.ComputeExpr,
+			)
+			continue
+		}
 		ot.columns[i].InitNonVirtual(
 			i,
 			cat.StableID(desc.ID),
@@ -658,7 +820,7 @@ func newOptTable(
 			desc.Type,
 			desc.Nullable,
 			desc.Hidden,
-			desc.DefaultExpr,
+			schemaexpr.ColumnDefaultExpr(&colDescs[i]),
 			desc.ComputeExpr,
 		)
 	}
@@ -762,6 +924,8 @@ func newOptTable(
 			match:             fk.Match,
 			deleteAction:      fk.OnDelete,
 			updateAction:      fk.OnUpdate,
+			deferrable:        fk.Deferrable,
+			initiallyDeferred: fk.InitiallyDeferred,
 		})
 	}
 	for i := range ot.desc.InboundFKs {
@@ -776,6 +940,8 @@ func newOptTable(
 			match:             fk.Match,
 			deleteAction:      fk.OnDelete,
 			updateAction:      fk.OnUpdate,
+			deferrable:        fk.Deferrable,
+			initiallyDeferred: fk.InitiallyDeferred,
 		})
 	}
 
@@ -785,30 +951,50 @@ func newOptTable(
 		ot.families[i].init(ot, &desc.Families[i+1])
 	}
 
-	// Synthesize any check constraints for user defined types.
+	// Synthesize any check constraints for user defined types. Note that the
+	// order of the synthesized checks must match the one of
+	// visitSynthesizedChecks, which maps them back to the constraints of the
+	// column types when a check fails.
 	var synthesizedChecks []cat.CheckConstraint
-	for i := 0; i < ot.ColumnCount(); i++ {
-		col := ot.Column(i)
-		if col.IsMutation() {
-			// We do not synthesize check constraints for mutation columns.
-			continue
-		}
-		colType := col.DatumType()
-		if colType.UserDefined() {
-			switch colType.Family() {
-			case types.EnumFamily:
-				// We synthesize an (x IN (v1, v2, v3...)) check for enum types.
-				expr := &tree.ComparisonExpr{
-					Operator: tree.In,
-					Left:     &tree.ColumnItem{ColumnName: col.ColName()},
-					Right:    tree.NewDTuple(colType, tree.MakeAllDEnumsInType(colType)...),
-				}
-				synthesizedChecks = append(synthesizedChecks, cat.CheckConstraint{
-					Constraint: tree.Serialize(expr),
-					Validated:  true,
-				})
+	var synthesizeErr error
+	visitSynthesizedChecks(desc.Columns, func(col *descpb.ColumnDescriptor, c *types.DomainConstraint) {
+		colType := col.Type
+		colItem := &tree.ColumnItem{ColumnName: tree.Name(col.Name)}
+		switch {
+		case c == nil:
+			// We synthesize an (x IN (v1, v2, v3...)) check for enum types.
+			expr := &tree.ComparisonExpr{
+				Operator: tree.In,
+				Left:     colItem,
+				Right:    tree.NewDTuple(colType, tree.MakeAllDEnumsInType(colType)...),
 			}
+			synthesizedChecks = append(synthesizedChecks, cat.CheckConstraint{
+				Constraint: tree.Serialize(expr),
+				Validated:  true,
+			})
+		case c.NotNull:
+			// We synthesize an (x IS NOT NULL) check for the NOT NULL constraint
+			// of a domain.
+			synthesizedChecks = append(synthesizedChecks, cat.CheckConstraint{
+				Constraint: tree.Serialize(&tree.IsNotNullExpr{Expr: colItem}),
+				Validated:  c.Validated,
+			})
+		default:
+			// We synthesize the CHECK constraints of a domain, with VALUE replaced
+			// by the column.
+			expr, err := schemaexpr.ParseDomainCheckExpr(c.CheckExpr, colItem)
+			if err != nil {
+				synthesizeErr = err
+				return
+			}
+			synthesizedChecks = append(synthesizedChecks, cat.CheckConstraint{
+				Constraint: tree.Serialize(expr),
+				Validated:  c.Validated,
+			})
 		}
+	})
+	if synthesizeErr != nil {
+		return nil, synthesizeErr
 	}
 	// Move all existing and synthesized checks into the opt table.
 	activeChecks := desc.ActiveChecks()
@@ -1038,6 +1224,24 @@ func (ot *optTable) InboundForeignKey(i int) cat.ForeignKeyConstraint {
 	return &ot.inboundFKs[i]
 }
 
+// TriggerCount is part of the cat.Table interface.
+func (ot *optTable) TriggerCount() int {
+	return len(ot.desc.Triggers)
+}
+
+// Trigger is part of the cat.Table interface.
+func (ot *optTable) Trigger(i int) cat.Trigger {
+	tr := &ot.desc.Triggers[i]
+	return cat.Trigger{
+		Name:     tree.Name(tr.Name),
+		Before:   tr.Before,
+		Insert:   tr.Insert,
+		Update:   tr.Update,
+		Delete:   tr.Delete,
+		Function: cat.StableID(tr.FunctionID),
+	}
+}
+
 // lookupColumnOrdinal returns the ordinal of the column with the given ID. A
 // cache makes the lookup O(1).
 func (ot *optTable) lookupColumnOrdinal(colID descpb.ColumnID) (int, error) {
@@ -1154,6 +1358,11 @@ func (oi *optIndex) IsUnique() bool {
 	return oi.desc.Unique
 }
 
+// UniqueDeferrability is part of the cat.Index interface.
+func (oi *optIndex) UniqueDeferrability() tree.ConstraintDeferrability {
+	return oi.desc.UniqueDeferrability()
+}
+
 // IsInverted is part of the cat.Index interface.
 func (oi *optIndex) IsInverted() bool {
 	return oi.desc.Type == descpb.IndexDescriptor_INVERTED
@@ -1164,6 +1373,11 @@ func (oi *optIndex) ColumnCount() int {
 	return oi.numCols
 }
 
+// ExplicitColumnCount is part of the cat.Index interface.
+func (oi *optIndex) ExplicitColumnCount() int {
+	return len(oi.desc.ColumnIDs)
+}
+
 // Predicate is part of the cat.Index interface. It returns the predicate
 // expression and true if the index is a partial index. If the index is not
 // partial, the empty string and false is returned.
@@ -1191,9 +1405,11 @@ func (oi *optIndex) Column(i int) cat.IndexColumn {
 		} else {
 			ord, _ = oi.tab.lookupColumnOrdinal(oi.desc.ColumnIDs[i])
 		}
+		col := oi.tab.Column(ord)
 		return cat.IndexColumn{
-			Column:     oi.tab.Column(ord),
-			Descending: oi.desc.ColumnDirections[i] == descpb.IndexDescriptor_DESC,
+			Column:        col,
+			Descending:    oi.desc.ColumnDirections[i] == descpb.IndexDescriptor_DESC,
+			NullsReversed: col.IsNullable() && oi.desc.ColumnNullsReversedAt(i),
 		}
 	}
 
@@ -1422,6 +1638,9 @@ type optForeignKeyConstraint struct {
 	match        descpb.ForeignKeyReference_Match
 	deleteAction descpb.ForeignKeyReference_Action
 	updateAction descpb.ForeignKeyReference_Action
+
+	deferrable        bool
+	initiallyDeferred bool
 }
 
 var _ cat.ForeignKeyConstraint = &optForeignKeyConstraint{}
@@ -1493,6 +1712,16 @@ func (fk *optForeignKeyConstraint) UpdateReferenceAction() tree.ReferenceAction
 	return descpb.ForeignKeyReferenceActionType[fk.updateAction]
 }
 
+// Deferrable is part of the cat.ForeignKeyConstraint interface.
+func (fk *optForeignKeyConstraint) Deferrable() bool {
+	return fk.deferrable
+}
+
+// InitiallyDeferred is part of the cat.ForeignKeyConstraint interface.
+func (fk *optForeignKeyConstraint) InitiallyDeferred() bool {
+	return fk.initiallyDeferred
+}
+
 // optVirtualTable is similar to optTable but is used with virtual tables.
 type optVirtualTable struct {
 	desc *tabledesc.Immutable
@@ -1778,6 +2007,16 @@ func (ot *optVirtualTable) InboundForeignKey(i int) cat.ForeignKeyConstraint {
 	panic("no FKs")
 }
 
+// TriggerCount is part of the cat.Table interface.
+func (ot *optVirtualTable) TriggerCount() int {
+	return 0
+}
+
+// Trigger is part of the cat.Table interface.
+func (ot *optVirtualTable) Trigger(i int) cat.Trigger {
+	panic("no triggers")
+}
+
 // optVirtualIndex is a dummy implementation of cat.Index for the indexes
 // reported by a virtual table. The index assumes that table column 0 is a dummy
 // PK column.
@@ -1809,6 +2048,11 @@ func (oi *optVirtualIndex) IsUnique() bool {
 	return oi.desc.Unique
 }
 
+// UniqueDeferrability is part of the cat.Index interface.
+func (oi *optVirtualIndex) UniqueDeferrability() tree.ConstraintDeferrability {
+	return tree.ConstraintNotDeferrable
+}
+
 // IsInverted is part of the cat.Index interface.
 func (oi *optVirtualIndex) IsInverted() bool {
 	return false
@@ -1819,6 +2063,12 @@ func (oi *optVirtualIndex) ColumnCount() int {
 	return oi.numCols
 }
 
+// ExplicitColumnCount is part of the cat.Index interface.
+func (oi *optVirtualIndex) ExplicitColumnCount() int {
+	// Virtual indexes are only constructable on a single column.
+	return 1
+}
+
 // Predicate is part of the cat.Index interface.
 func (oi *optVirtualIndex) Predicate() (string, bool) {
 	return "", false
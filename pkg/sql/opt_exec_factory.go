@@ -1671,6 +1671,41 @@ func (ef *execFactory) ConstructCreateView(
 	}, nil
 }
 
+// ConstructCreateFunction is part of the exec.Factory interface.
+func (ef *execFactory) ConstructCreateFunction(
+	schema cat.Schema, cf *tree.CreateFunction, deps opt.ViewDeps,
+) (exec.Node, error) {
+	planDeps := make(planDependencies, len(deps))
+	for _, d := range deps {
+		desc, err := getDescForDataSource(d.DataSource)
+		if err != nil {
+			return nil, err
+		}
+		var ref descpb.TableDescriptor_Reference
+		if d.SpecificIndex {
+			idx := d.DataSource.(cat.Table).Index(d.Index)
+			ref.IndexID = idx.(*optIndex).desc.ID
+		}
+		if !d.ColumnOrdinals.Empty() {
+			ref.ColumnIDs = make([]descpb.ColumnID, 0, d.ColumnOrdinals.Len())
+			d.ColumnOrdinals.ForEach(func(ord int) {
+				ref.ColumnIDs = append(ref.ColumnIDs, desc.Columns[ord].ID)
+			})
+		}
+		entry := planDeps[desc.ID]
+		entry.desc = desc
+		entry.deps = append(entry.deps, ref)
+		planDeps[desc.ID] = entry
+	}
+
+	return &createFunctionNode{
+		n:        cf,
+		dbDesc:   schema.(*optSchema).database,
+		schema:   schema.(*optSchema).schema,
+		planDeps: planDeps,
+	}, nil
+}
+
 // ConstructSequenceSelect is part of the exec.Factory interface.
 func (ef *execFactory) ConstructSequenceSelect(sequence cat.Sequence) (exec.Node, error) {
 	return ef.planner.SequenceSelectNode(sequence.(*optSequence).desc)
@@ -1685,11 +1720,12 @@ func (ef *execFactory) ConstructSaveTable(
 
 // ConstructErrorIfRows is part of the exec.Factory interface.
 func (ef *execFactory) ConstructErrorIfRows(
-	input exec.Node, mkErr exec.MkErrFn,
+	input exec.Node, mkErr exec.MkErrFn, deferrable *exec.DeferrableConstraint,
 ) (exec.Node, error) {
 	return &errorIfRowsNode{
-		plan:  input.(planNode),
-		mkErr: mkErr,
+		plan:       input.(planNode),
+		mkErr:      mkErr,
+		deferrable: deferrable,
 	}, nil
 }
 
@@ -50,6 +50,9 @@ func TestContextualHelp(t *testing.T) {
 		{`ALTER TYPE t SET ??`, `ALTER TYPE`},
 		{`ALTER TYPE t RENAME ??`, `ALTER TYPE`},
 
+		{`ALTER DOMAIN ??`, `ALTER DOMAIN`},
+		{`ALTER DOMAIN d SET ??`, `ALTER DOMAIN`},
+
 		{`ALTER INDEX foo@bar RENAME ??`, `ALTER INDEX`},
 		{`ALTER INDEX foo@bar RENAME TO blih ??`, `ALTER INDEX`},
 		{`ALTER INDEX foo@bar SPLIT ??`, `ALTER INDEX`},
@@ -76,6 +79,8 @@ func TestContextualHelp(t *testing.T) {
 		{`ALTER USER IF ??`, `ALTER ROLE`},
 		{`ALTER USER foo WITH PASSWORD ??`, `ALTER ROLE`},
 
+		{`ALTER EXTERNAL CONNECTION ??`, `ALTER EXTERNAL CONNECTION`},
+
 		{`ALTER ROLE bleh ?? WITH NOCREATEROLE`, `ALTER ROLE`},
 
 		{`ALTER RANGE foo CONFIGURE ??`, `ALTER RANGE`},
@@ -118,6 +123,7 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE DATABASE blih ??`, `CREATE DATABASE`},
 
 		{`CREATE EXTENSION ??`, `CREATE EXTENSION`},
+		{`CREATE EXTERNAL CONNECTION ??`, `CREATE EXTERNAL CONNECTION`},
 
 		{`CREATE USER blih ??`, `CREATE ROLE`},
 		{`CREATE USER blih WITH ??`, `CREATE ROLE`},
@@ -142,8 +148,16 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE TABLE blah AS (SELECT 1) ??`, `CREATE TABLE`},
 		{`CREATE TABLE blah AS SELECT 1 ??`, `SELECT`},
 
+		{`CREATE FUNCTION ??`, `CREATE FUNCTION`},
+		{`CREATE FUNCTION f() RETURNS INT ??`, `CREATE FUNCTION`},
 		{`CREATE TYPE blah AS ENUM ??`, `CREATE TYPE`},
+		{`DROP FUNCTION ??`, `DROP FUNCTION`},
+		{`CREATE TRIGGER ??`, `CREATE TRIGGER`},
+		{`CREATE TRIGGER tr BEFORE INSERT ON t ??`, `CREATE TRIGGER`},
+		{`DROP TRIGGER ??`, `DROP TRIGGER`},
 		{`DROP TYPE ??`, `DROP TYPE`},
+		{`CREATE DOMAIN ??`, `CREATE DOMAIN`},
+		{`DROP DOMAIN ??`, `DROP DOMAIN`},
 
 		{`CREATE SCHEMA IF ??`, `CREATE SCHEMA`},
 		{`CREATE SCHEMA IF NOT ??`, `CREATE SCHEMA`},
@@ -173,6 +187,8 @@ func TestContextualHelp(t *testing.T) {
 		{`DROP ROLE IF ??`, `DROP ROLE`},
 		{`DROP ROLE IF EXISTS bluh ??`, `DROP ROLE`},
 
+		{`DROP EXTERNAL CONNECTION ??`, `DROP EXTERNAL CONNECTION`},
+
 		{`DROP SEQUENCE blah ??`, `DROP SEQUENCE`},
 		{`DROP SEQUENCE IF ??`, `DROP SEQUENCE`},
 		{`DROP SEQUENCE IF EXISTS blih, bloh ??`, `DROP SEQUENCE`},
@@ -214,6 +230,13 @@ func TestContextualHelp(t *testing.T) {
 		{`DEALLOCATE ALL ??`, `DEALLOCATE`},
 		{`DEALLOCATE PREPARE ??`, `DEALLOCATE`},
 
+		{`DECLARE ??`, `DECLARE`},
+		{`DECLARE foo SCROLL ??`, `DECLARE`},
+		{`FETCH ??`, `FETCH`},
+		{`FETCH FORWARD 5 ??`, `FETCH`},
+		{`MOVE ??`, `MOVE`},
+		{`CLOSE ??`, `CLOSE`},
+
 		{`INSERT INTO ??`, `INSERT`},
 		{`INSERT INTO blah (??`, `<SELECTCLAUSE>`},
 		{`INSERT INTO blah VALUES (1) RETURNING ??`, `INSERT`},
@@ -316,6 +339,7 @@ func TestContextualHelp(t *testing.T) {
 		{`SHOW DATABASES ??`, `SHOW DATABASES`},
 
 		{`SHOW ENUMS ??`, `SHOW ENUMS`},
+		{`SHOW EXTERNAL CONNECTIONS ??`, `SHOW EXTERNAL CONNECTIONS`},
 		{`SHOW TYPES ??`, `SHOW TYPES`},
 
 		{`SHOW GRANTS ON ??`, `SHOW GRANTS`},
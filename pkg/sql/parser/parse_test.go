@@ -98,6 +98,7 @@ func TestParse(t *testing.T) {
 		{`CREATE INDEX ON a (b) INTERLEAVE IN PARENT c.d (e)`},
 		{`CREATE INDEX ON a (b ASC, c DESC)`},
 		{`CREATE INDEX ON a (b NULLS FIRST, c ASC NULLS FIRST, d DESC NULLS LAST)`},
+		{`CREATE INDEX ON a (b NULLS LAST, c ASC NULLS LAST, d DESC NULLS FIRST)`},
 		{`CREATE INDEX IF NOT EXISTS i ON a (b) WHERE c > 3`},
 		{`CREATE UNIQUE INDEX a ON b (c)`},
 		{`CREATE UNIQUE INDEX a ON b (c) STORING (d)`},
@@ -111,6 +112,9 @@ func TestParse(t *testing.T) {
 		{`CREATE INVERTED INDEX a ON b (c) WHERE d > 3`},
 		{`CREATE INVERTED INDEX a ON b (c) INTERLEAVE IN PARENT d (e)`},
 		{`CREATE INVERTED INDEX IF NOT EXISTS a ON b (c) WHERE d > 3`},
+		{`CREATE INVERTED INDEX a ON b (c gin_trgm_ops)`},
+		{`CREATE INVERTED INDEX a ON b (c gist_trgm_ops) WHERE d > 3`},
+		{`CREATE TABLE a (b STRING, INVERTED INDEX (b gin_trgm_ops))`},
 		{`CREATE INDEX a ON b (c) WITH (fillfactor = 100, y_bounds = 50)`},
 
 		{`CREATE TABLE a ()`},
@@ -199,9 +203,13 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b INT8, c STRING, CONSTRAINT s FOREIGN KEY (b, c) REFERENCES other (x, y) MATCH FULL ON UPDATE SET NULL)`},
 		{`CREATE TABLE a (b INT8, c STRING, CONSTRAINT s FOREIGN KEY (b, c) REFERENCES other (x, y) MATCH FULL ON DELETE SET DEFAULT)`},
 		{`CREATE TABLE a (b INT8, c STRING, CONSTRAINT s FOREIGN KEY (b, c) REFERENCES other (x, y) MATCH FULL ON DELETE SET DEFAULT ON UPDATE SET NULL)`},
+		{`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b) REFERENCES other (x) DEFERRABLE)`},
+		{`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b) REFERENCES other (x) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED)`},
 		{`CREATE TABLE a (b INT8, c STRING, INDEX (b, c))`},
 		{`CREATE TABLE a (b INT8, c STRING, INDEX d (b, c))`},
 		{`CREATE TABLE a (b INT8, c STRING, CONSTRAINT d UNIQUE (b, c))`},
+		{`CREATE TABLE a (b INT8, c STRING, UNIQUE (b, c) DEFERRABLE)`},
+		{`CREATE TABLE a (b INT8, c STRING, CONSTRAINT d UNIQUE (b) STORING (c) DEFERRABLE INITIALLY DEFERRED)`},
 		{`CREATE TABLE a (b INT8, c STRING, CONSTRAINT d UNIQUE (b, c) INTERLEAVE IN PARENT d (e, f))`},
 		{`CREATE TABLE a (b INT8, UNIQUE (b))`},
 		{`CREATE TABLE a (b INT8, UNIQUE (b) STORING (c))`},
@@ -245,6 +253,8 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a.b (b INT8)`},
 		{`CREATE TABLE IF NOT EXISTS a (b INT8)`},
 		{`CREATE TABLE a (b INT8 AS (a + b) STORED)`},
+		{`CREATE TABLE a (b INT8 AS (a + b) VIRTUAL)`},
+		{`CREATE TABLE a (b INT8 AS (a->>'c') VIRTUAL, INDEX (b))`},
 		{`CREATE TABLE view (view INT8)`},
 
 		{`CREATE TABLE a (b INT8 CONSTRAINT c PRIMARY KEY)`},
@@ -253,6 +263,10 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b INT8 CONSTRAINT c DEFAULT d)`},
 		{`CREATE TABLE a (b INT8 CONSTRAINT c CHECK (d))`},
 		{`CREATE TABLE a (b INT8 CONSTRAINT c REFERENCES d)`},
+		{`CREATE TABLE a (b INT8 REFERENCES d DEFERRABLE)`},
+		{`CREATE TABLE a (b INT8 REFERENCES d (c) MATCH FULL DEFERRABLE INITIALLY DEFERRED)`},
+		{`CREATE TABLE a (b INT8 UNIQUE DEFERRABLE)`},
+		{`CREATE TABLE a (b INT8 CONSTRAINT c UNIQUE DEFERRABLE INITIALLY DEFERRED)`},
 
 		{`CREATE TABLE a (b INT8) PARTITION BY LIST (b) (PARTITION p1 VALUES IN (1, DEFAULT), PARTITION p2 VALUES IN ((1, 2), (3, 4)))`},
 		// This monstrosity was added on the assumption that it's more readable
@@ -353,6 +367,12 @@ func TestParse(t *testing.T) {
 
 		{`CREATE EXTENSION bob`},
 		{`CREATE EXTENSION IF NOT EXISTS bob`},
+		{`CREATE EXTERNAL CONNECTION backups AS 's3://bucket/path'`},
+		{`CREATE EXTERNAL CONNECTION IF NOT EXISTS backups AS 's3://bucket/path'`},
+		{`CREATE EXTERNAL CONNECTION backups AS $1`},
+		{`ALTER EXTERNAL CONNECTION backups AS 'gs://bucket/path'`},
+		{`DROP EXTERNAL CONNECTION backups`},
+		{`DROP EXTERNAL CONNECTION IF EXISTS backups`},
 
 		{`CREATE STATISTICS a ON col1 FROM t`},
 		{`EXPLAIN CREATE STATISTICS a ON col1 FROM t`},
@@ -373,6 +393,14 @@ func TestParse(t *testing.T) {
 		{`CREATE TYPE a AS ENUM ('a', 'b', 'c')`},
 		{`CREATE TYPE a.b AS ENUM ('a', 'b', 'c')`},
 		{`CREATE TYPE a.b.c AS ENUM ('a', 'b', 'c')`},
+		{`CREATE TYPE a AS ()`},
+		{`CREATE TYPE a AS (b INT8)`},
+		{`CREATE TYPE a.b AS (c INT8, d STRING, e DECIMAL(10,2)[])`},
+
+		{`CREATE DOMAIN a AS INT8`},
+		{`CREATE DOMAIN sc.a AS STRING DEFAULT 'x' NOT NULL`},
+		{`CREATE DOMAIN a AS INT8 CHECK (value > 0)`},
+		{`CREATE DOMAIN a AS INT8 DEFAULT 1 CONSTRAINT pos CHECK (value > 0) CONSTRAINT nn NOT NULL`},
 
 		{`DROP SCHEMA a`},
 		{`DROP SCHEMA a, b`},
@@ -389,6 +417,24 @@ func TestParse(t *testing.T) {
 		{`DROP TYPE IF EXISTS db.sc.a, sc.a CASCADE`},
 		{`DROP TYPE IF EXISTS db.sc.a, sc.a RESTRICT`},
 
+		{`DROP DOMAIN a`},
+		{`DROP DOMAIN IF EXISTS db.sc.a, sc.a CASCADE`},
+
+		{`CREATE FUNCTION f() RETURNS INT8 LANGUAGE sql AS 'SELECT 1'`},
+		{`CREATE FUNCTION sc.f(a INT8, b STRING) RETURNS STRING LANGUAGE sql IMMUTABLE STRICT AS 'SELECT $2'`},
+		{`CREATE OR REPLACE FUNCTION db.sc.f(INT8) RETURNS INT8 LANGUAGE sql STABLE AS 'SELECT $1 + 1'`},
+		{`CREATE FUNCTION f() RETURNS SETOF INT8 LANGUAGE sql VOLATILE AS 'SELECT a FROM t'`},
+		{`CREATE FUNCTION f(x INT8) RETURNS TABLE (a INT8, b STRING) LANGUAGE sql AS 'SELECT a, b FROM t WHERE a > x'`},
+		{`DROP FUNCTION f`},
+		{`DROP FUNCTION IF EXISTS db.sc.f, g CASCADE`},
+		{`DROP FUNCTION f RESTRICT`},
+		{`CREATE FUNCTION f() RETURNS TRIGGER LANGUAGE sql AS 'SELECT NEW.*'`},
+
+		{`CREATE TRIGGER tr BEFORE INSERT ON t FOR EACH ROW EXECUTE FUNCTION f()`},
+		{`CREATE TRIGGER tr AFTER INSERT OR UPDATE OR DELETE ON db.sc.t FOR EACH ROW EXECUTE FUNCTION sc.f()`},
+		{`DROP TRIGGER tr ON t`},
+		{`DROP TRIGGER IF EXISTS tr ON db.sc.t CASCADE`},
+
 		{`DELETE FROM a`},
 		{`EXPLAIN DELETE FROM a`},
 		{`DELETE FROM a.b`},
@@ -520,6 +566,7 @@ func TestParse(t *testing.T) {
 		{`EXPLAIN SHOW DATABASES`},
 		{`SHOW ENUMS`},
 		{`EXPLAIN SHOW ENUMS`},
+		{`SHOW EXTERNAL CONNECTIONS`},
 		{`SHOW TYPES`},
 		{`EXPLAIN SHOW TYPES`},
 		{`SHOW SCHEMAS`},
@@ -692,6 +739,22 @@ func TestParse(t *testing.T) {
 		{`DEALLOCATE a`},
 		{`DEALLOCATE ALL`},
 
+		{`DECLARE a CURSOR FOR SELECT 1`},
+		{`DECLARE a BINARY INSENSITIVE NO SCROLL CURSOR WITH HOLD FOR SELECT * FROM t ORDER BY b`},
+		{`DECLARE a SCROLL CURSOR FOR TABLE t`},
+		{`FETCH 1 a`},
+		{`FETCH -1 a`},
+		{`FETCH ALL a`},
+		{`FETCH BACKWARD ALL a`},
+		{`FETCH ABSOLUTE 3 a`},
+		{`FETCH RELATIVE -2 a`},
+		{`FETCH FIRST a`},
+		{`FETCH LAST a`},
+		{`MOVE 5 a`},
+		{`MOVE ALL a`},
+		{`CLOSE a`},
+		{`CLOSE ALL`},
+
 		// Tables are the default, but can also be specified with
 		// GRANT x ON TABLE y. However, the stringer does not output TABLE.
 		{`GRANT SELECT ON TABLE foo TO root`},
@@ -709,10 +772,16 @@ func TestParse(t *testing.T) {
 		{`GRANT USAGE, GRANT ON TYPE foo TO root`},
 		{`GRANT ALL ON TYPE foo TO root`},
 
+		// GRANT ON FUNCTION.
+		{`GRANT EXECUTE ON FUNCTION f TO root`},
+		{`GRANT ALL ON FUNCTION f, sc.g TO foo, bar`},
+
 		// GRANT ON SCHEMA.
 		{`GRANT USAGE ON SCHEMA foo TO root`},
 		{`GRANT USAGE, GRANT, CREATE ON SCHEMA foo TO root`},
 		{`GRANT ALL ON SCHEMA foo, bar, baz TO root`},
+		{`GRANT USAGE ON EXTERNAL CONNECTION backups TO foo`},
+		{`GRANT USAGE, DROP ON EXTERNAL CONNECTION a, b TO foo, bar`},
 
 		// Tables are the default, but can also be specified with
 		// REVOKE x ON TABLE y. However, the stringer does not output TABLE.
@@ -730,10 +799,14 @@ func TestParse(t *testing.T) {
 		{`REVOKE USAGE, GRANT ON TYPE foo FROM root`},
 		{`REVOKE ALL ON TYPE foo FROM root`},
 
+		// REVOKE ON FUNCTION.
+		{`REVOKE EXECUTE ON FUNCTION f FROM root`},
+
 		// REVOKE ON SCHEMA.
 		{`REVOKE USAGE ON SCHEMA foo FROM root`},
 		{`REVOKE USAGE, GRANT, CREATE ON SCHEMA foo FROM root`},
 		{`REVOKE ALL ON SCHEMA foo, bar, baz FROM root`},
+		{`REVOKE USAGE ON EXTERNAL CONNECTION backups FROM foo`},
 
 		{`INSERT INTO a VALUES (1)`},
 		{`EXPLAIN INSERT INTO a VALUES (1)`},
@@ -773,6 +846,7 @@ func TestParse(t *testing.T) {
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a, b) DO UPDATE SET a = 1`},
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a) DO UPDATE SET a = 1, b = excluded.a`},
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a) DO UPDATE SET a = 1 WHERE b > 2`},
+		{`INSERT INTO a VALUES (1) ON CONFLICT (a) DO UPDATE SET b.c = excluded.b`},
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a) DO UPDATE SET a = DEFAULT`},
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a) DO UPDATE SET (a, b) = (SELECT 1, 2)`},
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a) DO UPDATE SET (a, b) = (SELECT 1, 2) RETURNING a, b`},
@@ -1050,10 +1124,22 @@ func TestParse(t *testing.T) {
 		{`SELECT a FROM t ORDER BY a NULLS FIRST`},
 		{`SELECT a FROM t ORDER BY a ASC NULLS FIRST`},
 		{`SELECT a FROM t ORDER BY a DESC NULLS LAST`},
+		{`SELECT a FROM t ORDER BY a NULLS LAST`},
+		{`SELECT a FROM t ORDER BY a ASC NULLS LAST`},
+		{`SELECT a FROM t ORDER BY a DESC NULLS FIRST`},
 
 		{`SELECT 1 FROM t GROUP BY a`},
 		{`SELECT 1 FROM t GROUP BY a, b`},
 		{`SELECT 1 FROM t GROUP BY ()`},
+		{`SELECT 1 FROM t GROUP BY ROLLUP (b)`},
+		{`SELECT 1 FROM t GROUP BY a, ROLLUP (b, (c, d))`},
+		{`SELECT 1 FROM t GROUP BY CUBE (b)`},
+		{`SELECT 1 FROM t GROUP BY CUBE (a, b), c`},
+		{`SELECT 1 FROM t GROUP BY GROUPING SETS (b)`},
+		{`SELECT 1 FROM t GROUP BY GROUPING SETS ((a, b), a, ())`},
+		{`SELECT 1 FROM t GROUP BY GROUPING SETS (ROLLUP (a, b), CUBE (c), GROUPING SETS (d))`},
+		{`SELECT rollup(a), cube(b) FROM t`},
+		{`SELECT GROUPING(a), GROUPING(a, b) FROM t GROUP BY ROLLUP (a, b)`},
 		{`SELECT sum(x ORDER BY y) FROM t`},
 		{`SELECT sum(x ORDER BY y, z) FROM t`},
 
@@ -1072,6 +1158,7 @@ func TestParse(t *testing.T) {
 		{`SELECT avg(1) OVER (w) FROM t`},
 		{`SELECT avg(1) OVER (PARTITION BY b) FROM t`},
 		{`SELECT avg(1) OVER (ORDER BY c) FROM t`},
+		{`SELECT avg(1) OVER (ORDER BY c DESC NULLS FIRST) FROM t`},
 		{`SELECT avg(1) OVER (PARTITION BY b ORDER BY c) FROM t`},
 		{`SELECT avg(1) OVER (w PARTITION BY b ORDER BY c) FROM t`},
 
@@ -1211,6 +1298,9 @@ func TestParse(t *testing.T) {
 		{`SET TRANSACTION NOT DEFERRABLE`},
 		{`SET TRANSACTION ISOLATION LEVEL SERIALIZABLE, PRIORITY HIGH, AS OF SYSTEM TIME '-1s', NOT DEFERRABLE`},
 
+		{`SET CONSTRAINTS ALL DEFERRED`},
+		{`SET CONSTRAINTS ALL IMMEDIATE`},
+
 		{`SET TRACING = off`},
 		{`EXPLAIN SET TRACING = off`},
 		{`SET TRACING = 'cluster', 'kv'`},
@@ -1284,6 +1374,8 @@ func TestParse(t *testing.T) {
 		{`UPDATE a SET b = 3, c = DEFAULT FROM a AS other`},
 		{`UPDATE a SET b = 3, c = DEFAULT FROM a AS other, b`},
 		{`UPDATE a SET b = 3 + 4`},
+		{`UPDATE a SET b.c = 3`},
+		{`UPDATE a SET b.c = 3, b.d = DEFAULT, e = 4`},
 		{`UPDATE a SET (b, c) = (3, DEFAULT)`},
 		{`UPDATE a SET (b, c) = (SELECT 3, 4)`},
 		{`UPDATE a SET b = 3 WHERE a = b`},
@@ -1354,6 +1446,9 @@ func TestParse(t *testing.T) {
 		{`ALTER TABLE a DROP CONSTRAINT b CASCADE`},
 		{`ALTER TABLE a DROP CONSTRAINT IF EXISTS b RESTRICT`},
 		{`ALTER TABLE a VALIDATE CONSTRAINT a`},
+		{`ALTER TABLE a ALTER CONSTRAINT a DEFERRABLE`},
+		{`ALTER TABLE a ALTER CONSTRAINT a DEFERRABLE INITIALLY DEFERRED`},
+		{`ALTER TABLE a ALTER CONSTRAINT a NOT DEFERRABLE`},
 		{`ALTER TABLE a ADD PRIMARY KEY (x, y, z)`},
 		{`ALTER TABLE a ADD PRIMARY KEY (x, y, z) USING HASH WITH BUCKET_COUNT = 10 INTERLEAVE IN PARENT b (x, y)`},
 		{`ALTER TABLE a ADD CONSTRAINT "primary" PRIMARY KEY (x, y, z)`},
@@ -1516,6 +1611,15 @@ func TestParse(t *testing.T) {
 		{`ALTER TYPE t SET SCHEMA newschema`},
 		{`ALTER TYPE t OWNER TO foo`},
 
+		{`ALTER DOMAIN d SET DEFAULT 1`},
+		{`ALTER DOMAIN d DROP DEFAULT`},
+		{`ALTER DOMAIN d SET NOT NULL`},
+		{`ALTER DOMAIN d DROP NOT NULL`},
+		{`ALTER DOMAIN d ADD CHECK (value > 0)`},
+		{`ALTER DOMAIN s.d ADD CONSTRAINT pos CHECK (value > 0)`},
+		{`ALTER DOMAIN d DROP CONSTRAINT pos`},
+		{`ALTER DOMAIN d DROP CONSTRAINT IF EXISTS pos`},
+
 		{`REASSIGN OWNED BY foo TO bar`},
 		{`REASSIGN OWNED BY foo, bar TO third`},
 
@@ -1569,6 +1673,9 @@ func TestParse(t *testing.T) {
 		{`CREATE SCHEDULE FOR BACKUP INTO 'bar' WITH revision_history RECURRING '@daily' FULL BACKUP '@weekly' WITH SCHEDULE OPTIONS foo = 'bar'`},
 		{`EXPLAIN BACKUP TABLE foo TO 'bar'`},
 		{`BACKUP TABLE foo.foo, baz.baz TO 'bar'`},
+		{`BACKUP COMPACT 'bar' TO 'baz'`},
+		{`BACKUP COMPACT $1 TO $2 WITH encryption_passphrase = 'secret'`},
+		{`BACKUP TABLE compact TO 'bar'`},
 
 		{`SHOW BACKUP 'bar'`},
 		{`SHOW BACKUP 'bar' WITH foo = 'bar'`},
@@ -1576,6 +1683,8 @@ func TestParse(t *testing.T) {
 		{`SHOW BACKUP RANGES 'bar'`},
 		{`SHOW BACKUP FILES 'bar'`},
 		{`SHOW BACKUP FILES 'bar' WITH foo = 'bar'`},
+		{`SHOW BACKUP ('foo', 'bar') WITH check_files`},
+		{`SHOW BACKUP SCHEMAS ($1, $2, $3)`},
 
 		{`SHOW BACKUPS IN 'bar'`},
 		{`SHOW BACKUPS IN $1`},
@@ -1686,6 +1795,24 @@ func TestParse2(t *testing.T) {
 	}{
 		{`CREATE DATABASE a WITH ENCODING = 'foo'`,
 			`CREATE DATABASE a ENCODING = 'foo'`},
+
+		{`CREATE DOMAIN a INT NULL CHECK (VALUE > 0) DEFAULT 1`,
+			`CREATE DOMAIN a AS INT8 DEFAULT 1 CHECK (value > 0)`},
+
+		{`CREATE FUNCTION f(a INT) RETURNS INT AS 'SELECT a' LANGUAGE SQL`,
+			`CREATE FUNCTION f(a INT8) RETURNS INT8 LANGUAGE sql AS 'SELECT a'`},
+		{`CREATE FUNCTION f(a INT) RETURNS INT LANGUAGE SQL RETURNS NULL ON NULL INPUT AS 'SELECT a'`,
+			`CREATE FUNCTION f(a INT8) RETURNS INT8 LANGUAGE sql STRICT AS 'SELECT a'`},
+		{`CREATE FUNCTION f(a INT) RETURNS INT LANGUAGE SQL CALLED ON NULL INPUT AS 'SELECT a'`,
+			`CREATE FUNCTION f(a INT8) RETURNS INT8 LANGUAGE sql AS 'SELECT a'`},
+		{`DROP FUNCTION f(INT, STRING), g()`,
+			`DROP FUNCTION f, g`},
+		{`SELECT 1 FROM t GROUP BY rollup(a), cube(b), grouping sets(c)`,
+			`SELECT 1 FROM t GROUP BY ROLLUP (a), CUBE (b), GROUPING SETS (c)`},
+		{`SELECT grouping (a,b) FROM t GROUP BY a, b`,
+			`SELECT GROUPING(a, b) FROM t GROUP BY a, b`},
+		{`CREATE TRIGGER tr BEFORE UPDATE OR DELETE ON t FOR ROW EXECUTE PROCEDURE f()`,
+			`CREATE TRIGGER tr BEFORE UPDATE OR DELETE ON t FOR EACH ROW EXECUTE FUNCTION f()`},
 		{`CREATE DATABASE a TEMPLATE = template0`,
 			`CREATE DATABASE a TEMPLATE = 'template0'`},
 		{`CREATE DATABASE a TEMPLATE = invalid`,
@@ -1717,6 +1844,10 @@ func TestParse2(t *testing.T) {
 			`CREATE INVERTED INDEX a ON b (c)`},
 		{`CREATE UNIQUE INDEX a ON b USING GIN (c)`,
 			`CREATE UNIQUE INVERTED INDEX a ON b (c)`},
+		{`CREATE INDEX a ON b USING GIN (c gin_trgm_ops)`,
+			`CREATE INVERTED INDEX a ON b (c gin_trgm_ops)`},
+		{`CREATE INDEX a ON b USING GIST (c gist_trgm_ops)`,
+			`CREATE INVERTED INDEX a ON b (c gist_trgm_ops)`},
 
 		{`CREATE TABLE a (b BIGSERIAL, c SMALLSERIAL, d SERIAL)`,
 			`CREATE TABLE a (b SERIAL8, c SERIAL2, d SERIAL8)`},
@@ -2166,6 +2297,22 @@ $function$`,
 		{`DEALLOCATE PREPARE ALL`,
 			`DEALLOCATE ALL`},
 
+		{`DECLARE a NO SCROLL CURSOR WITHOUT HOLD FOR SELECT 1`,
+			`DECLARE a NO SCROLL CURSOR FOR SELECT 1`},
+		{`FETCH a`, `FETCH 1 a`},
+		{`FETCH FROM a`, `FETCH 1 a`},
+		{`FETCH NEXT FROM a`, `FETCH 1 a`},
+		{`FETCH PRIOR IN a`, `FETCH -1 a`},
+		{`FETCH FORWARD a`, `FETCH 1 a`},
+		{`FETCH FORWARD 10 FROM a`, `FETCH 10 a`},
+		{`FETCH FORWARD ALL IN a`, `FETCH ALL a`},
+		{`FETCH BACKWARD a`, `FETCH -1 a`},
+		{`FETCH BACKWARD 2 a`, `FETCH -2 a`},
+		{`FETCH next`, `FETCH 1 next`},
+		{`FETCH ABSOLUTE +3 FROM a`, `FETCH ABSOLUTE 3 a`},
+		{`MOVE NEXT IN a`, `MOVE 1 a`},
+		{`MOVE FORWARD ALL FROM a`, `MOVE ALL a`},
+
 		{`CANCEL JOB a`, `CANCEL JOBS VALUES (a)`},
 		{`EXPLAIN CANCEL JOB a`, `EXPLAIN CANCEL JOBS VALUES (a)`},
 		{`CANCEL JOBS FOR SCHEDULE a`, `CANCEL JOBS FOR SCHEDULES VALUES (a)`},
@@ -2343,6 +2490,12 @@ skip_missing_foreign_keys, skip_missing_sequences, skip_missing_sequence_owners,
 		{`RESTORE foo FROM 'bar' WITH ENCRYPTION_PASSPHRASE = 'secret', INTO_DB=baz,
 SKIP_MISSING_FOREIGN_KEYS, SKIP_MISSING_SEQUENCES, SKIP_MISSING_SEQUENCE_OWNERS, SKIP_MISSING_VIEWS`,
 			`RESTORE TABLE foo FROM 'bar' WITH encryption_passphrase='secret', into_db='baz', skip_missing_foreign_keys, skip_missing_sequence_owners, skip_missing_sequences, skip_missing_views`},
+		{`RESTORE DATABASE foo FROM 'bar' WITH NEW_DB_NAME = 'baz', detached`,
+			`RESTORE DATABASE foo FROM 'bar' WITH new_db_name='baz', detached`},
+		{`RESTORE foo FROM 'bar' WITH VERIFY_BACKUP_TABLE_DATA, detached`,
+			`RESTORE TABLE foo FROM 'bar' WITH detached, verify_backup_table_data`},
+		{`RESTORE foo FROM 'bar' AS OF SYSTEM TIME '1' WITH WHERE = 'id BETWEEN 1 AND 10', INTO_DB = 'baz'`,
+			`RESTORE TABLE foo FROM 'bar' AS OF SYSTEM TIME '1' WITH into_db='baz', where='id BETWEEN 1 AND 10'`},
 
 		{`CREATE CHANGEFEED FOR foo INTO 'sink'`, `CREATE CHANGEFEED FOR TABLE foo INTO 'sink'`},
 
@@ -2539,6 +2692,30 @@ SKIP_MISSING_FOREIGN_KEYS, SKIP_MISSING_SEQUENCES, SKIP_MISSING_SEQUENCE_OWNERS,
 			`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b, c) REFERENCES other MATCH SIMPLE)`,
 			`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b, c) REFERENCES other)`,
 		},
+		{
+			`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b) REFERENCES other INITIALLY DEFERRED)`,
+			`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b) REFERENCES other DEFERRABLE INITIALLY DEFERRED)`,
+		},
+		{
+			`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b) REFERENCES other DEFERRABLE INITIALLY IMMEDIATE)`,
+			`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b) REFERENCES other DEFERRABLE)`,
+		},
+		{
+			`CREATE TABLE a (b INT8, UNIQUE (b) INITIALLY DEFERRED)`,
+			`CREATE TABLE a (b INT8, UNIQUE (b) DEFERRABLE INITIALLY DEFERRED)`,
+		},
+		{
+			`CREATE TABLE a (b INT8 UNIQUE INITIALLY DEFERRED NOT NULL)`,
+			`CREATE TABLE a (b INT8 NOT NULL UNIQUE DEFERRABLE INITIALLY DEFERRED)`,
+		},
+		{
+			`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b) REFERENCES other INITIALLY IMMEDIATE)`,
+			`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b) REFERENCES other)`,
+		},
+		{
+			`CREATE TABLE a (b INT8, c STRING, CHECK (b > 0) INITIALLY IMMEDIATE)`,
+			`CREATE TABLE a (b INT8, c STRING, CHECK (b > 0))`,
+		},
 		{
 			`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b, c) REFERENCES other (x, y) MATCH SIMPLE)`,
 			`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b, c) REFERENCES other (x, y))`,
@@ -2556,6 +2733,7 @@ SKIP_MISSING_FOREIGN_KEYS, SKIP_MISSING_SEQUENCES, SKIP_MISSING_SEQUENCE_OWNERS,
 			`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b, c) REFERENCES other (x, y) ON DELETE CASCADE ON UPDATE SET NULL)`,
 		},
 		{`CREATE TABLE a (b INT8 GENERATED ALWAYS AS (a + b) STORED)`, `CREATE TABLE a (b INT8 AS (a + b) STORED)`},
+		{`CREATE TABLE a (b INT8 GENERATED ALWAYS AS (a + b) VIRTUAL)`, `CREATE TABLE a (b INT8 AS (a + b) VIRTUAL)`},
 
 		{`ALTER TABLE a ALTER b DROP STORED`, `ALTER TABLE a ALTER COLUMN b DROP STORED`},
 		{`ALTER TABLE a ADD b INT8`, `ALTER TABLE a ADD COLUMN b INT8`},
@@ -2968,8 +3146,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE DEFAULT CONVERSION a`, 0, `create def conv`, ``},
 		{`CREATE FOREIGN DATA WRAPPER a`, 0, `create fdw`, ``},
 		{`CREATE FOREIGN TABLE a`, 0, `create foreign table`, ``},
-		{`CREATE FUNCTION a`, 17511, `create`, ``},
-		{`CREATE OR REPLACE FUNCTION a`, 17511, `create`, ``},
 		{`CREATE LANGUAGE a`, 17511, `create language a`, ``},
 		{`CREATE OPERATOR a`, 0, `create operator`, ``},
 		{`CREATE PUBLICATION a`, 0, `create publication`, ``},
@@ -2978,18 +3154,15 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE SUBSCRIPTION a`, 0, `create subscription`, ``},
 		{`CREATE TABLESPACE a`, 54113, `create tablespace`, ``},
 		{`CREATE TEXT SEARCH a`, 7821, `create text`, ``},
-		{`CREATE TRIGGER a`, 28296, `create`, ``},
 
 		{`DROP ACCESS METHOD a`, 0, `drop access method`, ``},
 		{`DROP AGGREGATE a`, 0, `drop aggregate`, ``},
 		{`DROP CAST a`, 0, `drop cast`, ``},
 		{`DROP COLLATION a`, 0, `drop collation`, ``},
 		{`DROP CONVERSION a`, 0, `drop conversion`, ``},
-		{`DROP DOMAIN a`, 27796, `drop`, ``},
 		{`DROP EXTENSION a`, 0, `drop extension a`, ``},
 		{`DROP FOREIGN TABLE a`, 0, `drop foreign table`, ``},
 		{`DROP FOREIGN DATA WRAPPER a`, 0, `drop fdw`, ``},
-		{`DROP FUNCTION a`, 17511, `drop `, ``},
 		{`DROP LANGUAGE a`, 17511, `drop language a`, ``},
 		{`DROP OPERATOR a`, 0, `drop operator`, ``},
 		{`DROP PUBLICATION a`, 0, `drop publication`, ``},
@@ -2997,14 +3170,13 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`DROP SERVER a`, 0, `drop server`, ``},
 		{`DROP SUBSCRIPTION a`, 0, `drop subscription`, ``},
 		{`DROP TEXT SEARCH a`, 7821, `drop text`, ``},
-		{`DROP TRIGGER a`, 28296, `drop`, ``},
 
 		{`DISCARD PLANS`, 0, `discard plans`, ``},
 		{`DISCARD SEQUENCES`, 0, `discard sequences`, ``},
 		{`DISCARD TEMP`, 0, `discard temp`, ``},
 		{`DISCARD TEMPORARY`, 0, `discard temp`, ``},
 
-		{`SET CONSTRAINTS foo`, 0, `set constraints`, ``},
+		{`SET CONSTRAINTS foo`, 31632, `set constraints name`, ``},
 		{`SET LOCAL foo = bar`, 32562, ``, ``},
 		{`SET foo FROM CURRENT`, 0, `set from current`, ``},
 
@@ -3016,18 +3188,9 @@ func TestUnimplementedSyntax(t *testing.T) {
 
 		{`CREATE TABLE a AS SELECT b WITH NO DATA`, 0, `create table as with no data`, ``},
 
-		{`CREATE TABLE a(b INT8 AS (123) VIRTUAL)`, 0, `virtual computed columns`, ``},
 		{`CREATE TABLE a(b INT8 REFERENCES c(x) MATCH PARTIAL`, 20305, `match partial`, ``},
 		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) MATCH PARTIAL)`, 20305, `match partial`, ``},
 
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) DEFERRABLE)`, 31632, `deferrable`, ``},
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) INITIALLY DEFERRED)`, 31632, `initially deferred`, ``},
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) INITIALLY IMMEDIATE)`, 31632, `initially immediate`, ``},
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) DEFERRABLE INITIALLY DEFERRED)`, 31632, `initially deferred`, ``},
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) DEFERRABLE INITIALLY IMMEDIATE)`, 31632, `initially immediate`, ``},
-		{`CREATE TABLE a(b INT8, UNIQUE (b) DEFERRABLE)`, 31632, `deferrable`, ``},
-		{`CREATE TABLE a(b INT8, CHECK (b > 0) DEFERRABLE)`, 31632, `deferrable`, ``},
-
 		{`CREATE TABLE a (LIKE b INCLUDING COMMENTS)`, 47071, `like table`, ``},
 		{`CREATE TABLE a (LIKE b INCLUDING IDENTITY)`, 47071, `like table`, ``},
 		{`CREATE TABLE a (LIKE b INCLUDING STATISTICS)`, 47071, `like table`, ``},
@@ -3048,11 +3211,9 @@ func TestUnimplementedSyntax(t *testing.T) {
 
 		{`CREATE RECURSIVE VIEW a AS SELECT b`, 0, `create recursive view`, ``},
 
-		{`CREATE TYPE a AS (b)`, 27792, ``, ``},
 		{`CREATE TYPE a AS RANGE b`, 27791, ``, ``},
 		{`CREATE TYPE a (b)`, 27793, `base`, ``},
 		{`CREATE TYPE a`, 27793, `shell`, ``},
-		{`CREATE DOMAIN a`, 27796, `create`, ``},
 
 		{`ALTER TYPE db.t RENAME ATTRIBUTE foo TO bar`, 48701, `ALTER TYPE ATTRIBUTE`, ``},
 		{`ALTER TYPE db.s.t ADD ATTRIBUTE foo bar`, 48701, `ALTER TYPE ATTRIBUTE`, ``},
@@ -3075,12 +3236,7 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE INDEX a ON b((c + d))`, 9682, ``, ``},
 		{`CREATE INDEX a ON b((c[d]))`, 9682, ``, ``},
 		{`CREATE INDEX a ON b(foo(c))`, 9682, ``, ``},
-		{`CREATE INDEX a ON b(c gin_trgm_ops)`, 41285, `index using gin_trgm_ops`, ``},
-		{`CREATE INDEX a ON b(c gist_trgm_ops)`, 41285, `index using gist_trgm_ops`, ``},
 		{`CREATE INDEX a ON b(c bobby)`, 47420, ``, ``},
-		{`CREATE INDEX a ON b(a NULLS LAST)`, 6224, ``, ``},
-		{`CREATE INDEX a ON b(a ASC NULLS LAST)`, 6224, ``, ``},
-		{`CREATE INDEX a ON b(a DESC NULLS FIRST)`, 6224, ``, ``},
 
 		{`INSERT INTO foo(a, a.b) VALUES (1,2)`, 27792, ``, ``},
 		{`INSERT INTO foo VALUES (1,2) ON CONFLICT ON CONSTRAINT a DO NOTHING`, 28161, ``, ``},
@@ -3090,19 +3246,10 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`SELECT a(b) 'c'`, 0, `a(...) SCONST`, ``},
 		{`SELECT (a,b) OVERLAPS (c,d)`, 0, `overlaps`, ``},
 		{`SELECT UNIQUE (SELECT b)`, 0, `UNIQUE predicate`, ``},
-		{`SELECT GROUPING (a,b,c)`, 0, `d_expr grouping`, ``},
 		{`SELECT a(VARIADIC b)`, 0, `variadic`, ``},
 		{`SELECT a(b, c, VARIADIC b)`, 0, `variadic`, ``},
 		{`SELECT TREAT (a AS INT8)`, 0, `treat`, ``},
 
-		{`SELECT 1 FROM t GROUP BY ROLLUP (b)`, 46280, `rollup`, ``},
-		{`SELECT 1 FROM t GROUP BY a, ROLLUP (b)`, 46280, `rollup`, ``},
-		{`SELECT 1 FROM t GROUP BY CUBE (b)`, 46280, `cube`, ``},
-		{`SELECT 1 FROM t GROUP BY GROUPING SETS (b)`, 46280, `grouping sets`, ``},
-
-		{`SELECT a FROM t ORDER BY a NULLS LAST`, 6224, ``, ``},
-		{`SELECT a FROM t ORDER BY a ASC NULLS LAST`, 6224, ``, ``},
-		{`SELECT a FROM t ORDER BY a DESC NULLS FIRST`, 6224, ``, ``},
 
 		{`CREATE TABLE a(b BOX)`, 21286, `box`, ``},
 		{`CREATE TABLE a(b CIDR)`, 18846, `cidr`, ``},
@@ -3123,8 +3270,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE TABLE a(b XML)`, 0, `xml`, ``},
 
 		{`UPDATE foo SET (a, a.b) = (1, 2)`, 27792, ``, ``},
-		{`UPDATE foo SET a.b = 1`, 27792, ``, ``},
-		{`UPDATE Foo SET x.y = z`, 27792, ``, ``},
 
 		{`REINDEX INDEX a`, 0, `reindex index`, `CockroachDB does not require reindexing.`},
 		{`REINDEX INDEX CONCURRENTLY a`, 0, `reindex index`, `CockroachDB does not require reindexing.`},
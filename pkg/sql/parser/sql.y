@@ -32,6 +32,8 @@ import (
     "github.com/cockroachdb/cockroach/pkg/geo/geopb"
     "github.com/cockroachdb/cockroach/pkg/roachpb"
     "github.com/cockroachdb/cockroach/pkg/sql/lex"
+    "github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+    "github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
     "github.com/cockroachdb/cockroach/pkg/sql/privilege"
     "github.com/cockroachdb/cockroach/pkg/sql/roleoption"
     "github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -498,6 +500,9 @@ func (u *sqlSymUnion) transactionModes() tree.TransactionModes {
 func (u *sqlSymUnion) compositeKeyMatchMethod() tree.CompositeKeyMatchMethod {
   return u.val.(tree.CompositeKeyMatchMethod)
 }
+func (u *sqlSymUnion) constraintDeferrability() tree.ConstraintDeferrability {
+  return u.val.(tree.ConstraintDeferrability)
+}
 func (u *sqlSymUnion) referenceAction() tree.ReferenceAction {
     return u.val.(tree.ReferenceAction)
 }
@@ -543,6 +548,9 @@ func (u *sqlSymUnion) typeReferences() []tree.ResolvableTypeReference {
 func (u *sqlSymUnion) alterTypeAddValuePlacement() *tree.AlterTypeAddValuePlacement {
     return u.val.(*tree.AlterTypeAddValuePlacement)
 }
+func (u *sqlSymUnion) domainConstraint() tree.DomainConstraint {
+    return u.val.(tree.DomainConstraint)
+}
 func (u *sqlSymUnion) scheduleState() tree.ScheduleState {
   return u.val.(tree.ScheduleState)
 }
@@ -552,6 +560,39 @@ func (u *sqlSymUnion) executorType() tree.ScheduledJobExecutorType {
 func (u *sqlSymUnion) refreshDataOption() tree.RefreshDataOption {
   return u.val.(tree.RefreshDataOption)
 }
+func (u *sqlSymUnion) createFunction() *tree.CreateFunction {
+  return u.val.(*tree.CreateFunction)
+}
+func (u *sqlSymUnion) funcParam() tree.FuncParam {
+  return u.val.(tree.FuncParam)
+}
+func (u *sqlSymUnion) funcParams() tree.FuncParams {
+  return u.val.(tree.FuncParams)
+}
+func (u *sqlSymUnion) compositeTypeField() tree.CompositeTypeField {
+  return u.val.(tree.CompositeTypeField)
+}
+func (u *sqlSymUnion) compositeTypeFields() []tree.CompositeTypeField {
+  return u.val.([]tree.CompositeTypeField)
+}
+func (u *sqlSymUnion) functionOption() tree.FunctionOption {
+  return u.val.(tree.FunctionOption)
+}
+func (u *sqlSymUnion) functionOptions() []tree.FunctionOption {
+  return u.val.([]tree.FunctionOption)
+}
+func (u *sqlSymUnion) triggerEvent() tree.TriggerEvent {
+  return u.val.(tree.TriggerEvent)
+}
+func (u *sqlSymUnion) declareCursor() *tree.DeclareCursor {
+    return u.val.(*tree.DeclareCursor)
+}
+func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
+    return u.val.(tree.CursorStmt)
+}
+func (u *sqlSymUnion) triggerEvents() tree.TriggerEvents {
+  return u.val.(tree.TriggerEvents)
+}
 %}
 
 // NB: the %token definitions must come before the %type definitions in this
@@ -571,49 +612,49 @@ func (u *sqlSymUnion) refreshDataOption() tree.RefreshDataOption {
 // below; search this file for "Keyword category lists".
 
 // Ordinary key words in alphabetical order.
-%token <str> ABORT ACCESS ACTION ADD ADMIN AFTER AGGREGATE
+%token <str> ABORT ABSOLUTE ACCESS ACTION ADD ADMIN AFTER AGGREGATE
 %token <str> ALL ALTER ALWAYS ANALYSE ANALYZE AND AND_AND ANY ANNOTATE_TYPE ARRAY AS ASC
 %token <str> ASYMMETRIC AT ATTRIBUTE AUTHORIZATION AUTOMATIC
 
-%token <str> BACKUP BACKUPS BEFORE BEGIN BETWEEN BIGINT BIGSERIAL BINARY BIT
+%token <str> BACKUP BACKUPS BACKWARD BEFORE BEGIN BETWEEN BIGINT BIGSERIAL BINARY BIT
 %token <str> BUCKET_COUNT
 %token <str> BOOLEAN BOTH BOX2D BUNDLE BY
 
-%token <str> CACHE CANCEL CANCELQUERY CASCADE CASE CAST CBRT CHANGEFEED CHAR
+%token <str> CACHE CALLED CANCEL CANCELQUERY CASCADE CASE CAST CBRT CHANGEFEED CHAR
 %token <str> CHARACTER CHARACTERISTICS CHECK CLOSE
 %token <str> CLUSTER COALESCE COLLATE COLLATION COLUMN COLUMNS COMMENT COMMENTS COMMIT
 %token <str> COMMITTED COMPACT COMPLETE CONCAT CONCURRENTLY CONFIGURATION CONFIGURATIONS CONFIGURE
-%token <str> CONFLICT CONNECTION CONSTRAINT CONSTRAINTS CONTAINS CONTROLCHANGEFEED CONTROLJOB
+%token <str> CONFLICT CONNECTION CONNECTIONS CONSTRAINT CONSTRAINTS CONTAINS CONTROLCHANGEFEED CONTROLJOB
 %token <str> CONVERSION CONVERT COPY COVERING CREATE CREATEDB CREATELOGIN CREATEROLE
 %token <str> CROSS CUBE CURRENT CURRENT_CATALOG CURRENT_DATE CURRENT_SCHEMA
 %token <str> CURRENT_ROLE CURRENT_TIME CURRENT_TIMESTAMP
-%token <str> CURRENT_USER CYCLE
+%token <str> CURRENT_USER CURSOR CYCLE
 
 %token <str> DATA DATABASE DATABASES DATE DAY DEC DECIMAL DEFAULT DEFAULTS
 %token <str> DEALLOCATE DECLARE DEFERRABLE DEFERRED DELETE DESC DESTINATION DETACHED
 %token <str> DISCARD DISTINCT DO DOMAIN DOUBLE DROP
 
-%token <str> ELSE ENCODING ENCRYPTION_PASSPHRASE END ENUM ENUMS ESCAPE EXCEPT EXCLUDE EXCLUDING
+%token <str> EACH ELSE ENCODING ENCRYPTION_PASSPHRASE END ENUM ENUMS ESCAPE EXCEPT EXCLUDE EXCLUDING
 %token <str> EXISTS EXECUTE EXECUTION EXPERIMENTAL
 %token <str> EXPERIMENTAL_FINGERPRINTS EXPERIMENTAL_REPLICA
 %token <str> EXPERIMENTAL_AUDIT
-%token <str> EXPIRATION EXPLAIN EXPORT EXTENSION EXTRACT EXTRACT_DURATION
+%token <str> EXPIRATION EXPLAIN EXPORT EXTENSION EXTERNAL EXTRACT EXTRACT_DURATION
 
 %token <str> FALSE FAMILY FETCH FETCHVAL FETCHTEXT FETCHVAL_PATH FETCHTEXT_PATH
 %token <str> FILES FILTER
-%token <str> FIRST FLOAT FLOAT4 FLOAT8 FLOORDIV FOLLOWING FOR FORCE_INDEX FOREIGN FROM FULL FUNCTION
+%token <str> FIRST FLOAT FLOAT4 FLOAT8 FLOORDIV FOLLOWING FOR FORCE_INDEX FOREIGN FORWARD FROM FULL FUNCTION
 
 %token <str> GENERATED GEOGRAPHY GEOMETRY GEOMETRYM GEOMETRYZ GEOMETRYZM
 %token <str> GEOMETRYCOLLECTION GEOMETRYCOLLECTIONM GEOMETRYCOLLECTIONZ GEOMETRYCOLLECTIONZM
 %token <str> GLOBAL GRANT GRANTS GREATEST GROUP GROUPING GROUPS
 
-%token <str> HAVING HASH HIGH HISTOGRAM HOUR
+%token <str> HAVING HASH HIGH HISTOGRAM HOLD HOUR
 
 %token <str> IDENTITY
-%token <str> IF IFERROR IFNULL IGNORE_FOREIGN_KEYS ILIKE IMMEDIATE IMPORT IN INCLUDE INCLUDING INCREMENT INCREMENTAL
+%token <str> IF IFERROR IFNULL IGNORE_FOREIGN_KEYS ILIKE IMMEDIATE IMMUTABLE IMPORT IN INCLUDE INCLUDING INCREMENT INCREMENTAL
 %token <str> INET INET_CONTAINED_BY_OR_EQUALS
 %token <str> INET_CONTAINS_OR_EQUALS INDEX INDEXES INHERITS INJECT INTERLEAVE INITIALLY
-%token <str> INNER INSERT INT INTEGER
+%token <str> INNER INPUT INSENSITIVE INSERT INT INTEGER
 %token <str> INTERSECT INTERVAL INTO INTO_DB INVERTED IS ISERROR ISNULL ISOLATION
 
 %token <str> JOB JOBS JOIN JSON JSONB JSON_SOME_EXISTS JSON_ALL_EXISTS
@@ -625,12 +666,12 @@ func (u *sqlSymUnion) refreshDataOption() tree.RefreshDataOption {
 %token <str> LINESTRING LINESTRINGM LINESTRINGZ LINESTRINGZM
 %token <str> LIST LOCAL LOCALTIME LOCALTIMESTAMP LOCKED LOGIN LOOKUP LOW LSHIFT
 
-%token <str> MATCH MATERIALIZED MERGE MINVALUE MAXVALUE METHOD MINUTE MODIFYCLUSTERSETTING MONTH
+%token <str> MATCH MATERIALIZED MERGE MINVALUE MAXVALUE METHOD MINUTE MODIFYCLUSTERSETTING MONTH MOVE
 %token <str> MULTILINESTRING MULTILINESTRINGM MULTILINESTRINGZ MULTILINESTRINGZM
 %token <str> MULTIPOINT MULTIPOINTM MULTIPOINTZ MULTIPOINTZM
 %token <str> MULTIPOLYGON MULTIPOLYGONM MULTIPOLYGONZ MULTIPOLYGONZM
 
-%token <str> NAN NAME NAMES NATURAL NEVER NEXT NO NOCANCELQUERY NOCONTROLCHANGEFEED NOCONTROLJOB
+%token <str> NAN NAME NAMES NATURAL NEVER NEW_DB_NAME NEXT NO NOCANCELQUERY NOCONTROLCHANGEFEED NOCONTROLJOB
 %token <str> NOCREATEDB NOCREATELOGIN NOCREATEROLE NOLOGIN NOMODIFYCLUSTERSETTING NO_INDEX_JOIN
 %token <str> NONE NORMAL NOT NOTHING NOTNULL NOVIEWACTIVITY NOWAIT NULL NULLIF NULLS NUMERIC
 
@@ -639,23 +680,23 @@ func (u *sqlSymUnion) refreshDataOption() tree.RefreshDataOption {
 
 %token <str> PARENT PARTIAL PARTITION PARTITIONS PASSWORD PAUSE PAUSED PHYSICAL PLACING
 %token <str> PLAN PLANS POINT POINTM POINTZ POINTZM POLYGON POLYGONM POLYGONZ POLYGONZM
-%token <str> POSITION PRECEDING PRECISION PREPARE PRESERVE PRIMARY PRIORITY
-%token <str> PROCEDURAL PUBLIC PUBLICATION
+%token <str> POSITION PRECEDING PRECISION PREPARE PRESERVE PRIMARY PRIOR PRIORITY
+%token <str> PROCEDURAL PROCEDURE PUBLIC PUBLICATION
 
 %token <str> QUERIES QUERY
 
-%token <str> RANGE RANGES READ REAL REASSIGN RECURSIVE RECURRING REF REFERENCES REFRESH
+%token <str> RANGE RANGES READ REAL REASSIGN RECURSIVE RECURRING REF REFERENCES REFRESH RELATIVE
 %token <str> REGCLASS REGPROC REGPROCEDURE REGNAMESPACE REGTYPE REINDEX
 %token <str> REMOVE_PATH RENAME REPEATABLE REPLACE
-%token <str> RELEASE RESET RESTORE RESTRICT RESUME RETURNING RETRY REVISION_HISTORY REVOKE RIGHT
+%token <str> RELEASE RESET RESTORE RESTRICT RESUME RETURNING RETURNS RETRY REVISION_HISTORY REVOKE RIGHT
 %token <str> ROLE ROLES ROLLBACK ROLLUP ROW ROWS RSHIFT RULE RUNNING
 
-%token <str> SAVEPOINT SCATTER SCHEDULE SCHEDULES SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
-%token <str> SERIALIZABLE SERVER SESSION SESSIONS SESSION_USER SET SETS SETTING SETTINGS
+%token <str> SAVEPOINT SCATTER SCHEDULE SCHEDULES SCHEMA SCHEMAS SCROLL SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
+%token <str> SERIALIZABLE SERVER SESSION SESSIONS SESSION_USER SET SETOF SETS SETTING SETTINGS
 %token <str> SHARE SHOW SIMILAR SIMPLE SKIP SKIP_MISSING_FOREIGN_KEYS
 %token <str> SKIP_MISSING_SEQUENCES SKIP_MISSING_SEQUENCE_OWNERS SKIP_MISSING_VIEWS SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
 
-%token <str> START STATISTICS STATUS STDIN STRICT STRING STORAGE STORE STORED STORING SUBSTRING
+%token <str> STABLE START STATISTICS STATUS STDIN STRICT STRING STORAGE STORE STORED STORING SUBSTRING
 %token <str> SYMMETRIC SYNTAX SYSTEM SQRT SUBSCRIPTION
 
 %token <str> TABLE TABLES TABLESPACE TEMP TEMPLATE TEMPORARY TENANT TESTING_RELOCATE EXPERIMENTAL_RELOCATE TEXT THEN
@@ -667,7 +708,7 @@ func (u *sqlSymUnion) refreshDataOption() tree.RefreshDataOption {
 %token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLOGGED UNSPLIT
 %token <str> UPDATE UPSERT UNTIL USE USER USERS USING UUID
 
-%token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VIEW VARYING VIEWACTIVITY VIRTUAL
+%token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VERIFY_BACKUP_TABLE_DATA VIEW VARYING VIEWACTIVITY VIRTUAL VOLATILE
 
 %token <str> WHEN WHERE WINDOW WITH WITHIN WITHOUT WORK WRITE
 
@@ -707,7 +748,9 @@ func (u *sqlSymUnion) refreshDataOption() tree.RefreshDataOption {
 %type <tree.Statement> alter_range_stmt
 %type <tree.Statement> alter_partition_stmt
 %type <tree.Statement> alter_role_stmt
+%type <tree.Statement> alter_external_connection_stmt
 %type <tree.Statement> alter_type_stmt
+%type <tree.Statement> alter_domain_stmt
 %type <tree.Statement> alter_schema_stmt
 
 // ALTER RANGE
@@ -777,6 +820,8 @@ func (u *sqlSymUnion) refreshDataOption() tree.RefreshDataOption {
 %type <tree.Statement> create_ddl_stmt
 %type <tree.Statement> create_database_stmt
 %type <tree.Statement> create_extension_stmt
+%type <tree.Statement> create_external_connection_stmt
+%type <tree.Statement> create_function_stmt
 %type <tree.Statement> create_index_stmt
 %type <tree.Statement> create_role_stmt
 %type <tree.Statement> create_schedule_for_backup_stmt
@@ -792,17 +837,32 @@ func (u *sqlSymUnion) refreshDataOption() tree.RefreshDataOption {
 %type <*tree.CreateStatsOptions> create_stats_option
 
 %type <tree.Statement> create_type_stmt
+%type <tree.Statement> create_domain_stmt
+%type <tree.Statement> create_trigger_stmt
+%type <bool> trigger_action_time
+%type <tree.TriggerEvent> trigger_event
+%type <tree.TriggerEvents> trigger_event_list
+%type <*tree.CreateFunction> func_create_signature
+%type <tree.FuncParam> func_param func_table_column
+%type <tree.FuncParams> opt_func_param_list func_param_list func_table_column_list
+%type <tree.FunctionOption> func_create_opt
+%type <[]tree.FunctionOption> func_create_opt_list
+%type <[]*tree.UnresolvedObjectName> function_with_argtypes_list
+%type <*tree.UnresolvedObjectName> function_with_argtypes
 %type <tree.Statement> delete_stmt
 %type <tree.Statement> discard_stmt
 
 %type <tree.Statement> drop_stmt
 %type <tree.Statement> drop_ddl_stmt
 %type <tree.Statement> drop_database_stmt
+%type <tree.Statement> drop_function_stmt
 %type <tree.Statement> drop_index_stmt
 %type <tree.Statement> drop_role_stmt
 %type <tree.Statement> drop_schema_stmt
 %type <tree.Statement> drop_table_stmt
 %type <tree.Statement> drop_type_stmt
+%type <tree.Statement> drop_domain_stmt
+%type <tree.Statement> drop_trigger_stmt
 %type <tree.Statement> drop_view_stmt
 %type <tree.Statement> drop_sequence_stmt
 
@@ -824,6 +884,7 @@ func (u *sqlSymUnion) refreshDataOption() tree.RefreshDataOption {
 %type <tree.Statement> reset_stmt reset_session_stmt reset_csetting_stmt
 %type <tree.Statement> resume_stmt resume_jobs_stmt resume_schedules_stmt
 %type <tree.Statement> drop_schedule_stmt
+%type <tree.Statement> drop_external_connection_stmt
 %type <tree.Statement> restore_stmt
 %type <tree.StringOrPlaceholderOptList> string_or_placeholder_opt_list
 %type <[]tree.StringOrPlaceholderOptList> list_of_string_or_placeholder_opt_list
@@ -877,6 +938,7 @@ func (u *sqlSymUnion) refreshDataOption() tree.RefreshDataOption {
 %type <tree.Statement> show_users_stmt
 %type <tree.Statement> show_zone_stmt
 %type <tree.Statement> show_schedules_stmt
+%type <tree.Statement> show_external_connections_stmt
 
 %type <str> session_var
 %type <*string> comment_text
@@ -889,6 +951,11 @@ func (u *sqlSymUnion) refreshDataOption() tree.RefreshDataOption {
 
 %type <tree.Statement> close_cursor_stmt
 %type <tree.Statement> declare_cursor_stmt
+%type <*tree.DeclareCursor> cursor_options
+%type <bool> opt_hold
+%type <tree.Statement> fetch_cursor_stmt
+%type <tree.Statement> move_cursor_stmt
+%type <tree.CursorStmt> fetch_args
 %type <tree.Statement> reindex_stmt
 
 %type <[]string> opt_incremental
@@ -1074,10 +1141,16 @@ func (u *sqlSymUnion) refreshDataOption() tree.RefreshDataOption {
 
 %type <str> explain_option_name
 %type <[]string> explain_option_list opt_enum_val_list enum_val_list
+%type <tree.CompositeTypeField> composite_type_field
+%type <[]tree.CompositeTypeField> opt_composite_type_field_list composite_type_field_list
 
 %type <tree.ResolvableTypeReference> typename simple_typename cast_target
 %type <*types.T> const_typename
 %type <*tree.AlterTypeAddValuePlacement> opt_add_val_placement
+%type <[]tree.NamedColumnQualification> domain_qual_list
+%type <tree.NamedColumnQualification> domain_qualification
+%type <tree.ColumnQualification> domain_qualification_elem
+%type <tree.DomainConstraint> domain_constraint
 %type <bool> opt_timezone
 %type <*types.T> numeric opt_numeric_modifiers
 %type <*types.T> opt_float
@@ -1120,6 +1193,7 @@ func (u *sqlSymUnion) refreshDataOption() tree.RefreshDataOption {
 %type <tree.NamedColumnQualification> col_qualification create_as_col_qualification
 %type <tree.ColumnQualification> col_qualification_elem create_as_col_qualification_elem
 %type <tree.CompositeKeyMatchMethod> key_match
+%type <tree.ConstraintDeferrability> opt_deferrable constraint_deferrability
 %type <tree.ReferenceActions> reference_actions
 %type <tree.ReferenceAction> reference_action reference_on_delete reference_on_update
 
@@ -1257,8 +1331,10 @@ stmt:
 | refresh_stmt      // EXTEND WITH HELP: REFRESH
 | nonpreparable_set_stmt // help texts in sub-rule
 | transaction_stmt  // help texts in sub-rule
-| close_cursor_stmt
-| declare_cursor_stmt
+| close_cursor_stmt // EXTEND WITH HELP: CLOSE
+| declare_cursor_stmt // EXTEND WITH HELP: DECLARE
+| fetch_cursor_stmt // EXTEND WITH HELP: FETCH
+| move_cursor_stmt  // EXTEND WITH HELP: MOVE
 | reindex_stmt
 | /* EMPTY */
   {
@@ -1267,10 +1343,12 @@ stmt:
 
 // %Help: ALTER
 // %Category: Group
-// %Text: ALTER TABLE, ALTER INDEX, ALTER VIEW, ALTER SEQUENCE, ALTER DATABASE, ALTER USER, ALTER ROLE
+// %Text: ALTER TABLE, ALTER INDEX, ALTER VIEW, ALTER SEQUENCE, ALTER DATABASE, ALTER USER, ALTER ROLE,
+// ALTER EXTERNAL CONNECTION
 alter_stmt:
   alter_ddl_stmt      // help texts in sub-rule
 | alter_role_stmt     // EXTEND WITH HELP: ALTER ROLE
+| alter_external_connection_stmt // EXTEND WITH HELP: ALTER EXTERNAL CONNECTION
 | ALTER error         // SHOW HELP: ALTER
 
 alter_ddl_stmt:
@@ -1283,6 +1361,7 @@ alter_ddl_stmt:
 | alter_partition_stmt // EXTEND WITH HELP: ALTER PARTITION
 | alter_schema_stmt    // EXTEND WITH HELP: ALTER SCHEMA
 | alter_type_stmt      // EXTEND WITH HELP: ALTER TYPE
+| alter_domain_stmt    // EXTEND WITH HELP: ALTER DOMAIN
 
 // %Help: ALTER TABLE - change the definition of a table
 // %Category: DDL
@@ -1844,6 +1923,20 @@ alter_table_cmd:
     }
   }
   // ALTER TABLE <name> ALTER CONSTRAINT ...
+| ALTER CONSTRAINT constraint_name constraint_deferrability
+  {
+    $$.val = &tree.AlterTableAlterConstraint{
+      Constraint: tree.Name($3),
+      Deferrability: $4.constraintDeferrability(),
+    }
+  }
+| ALTER CONSTRAINT constraint_name NOT DEFERRABLE
+  {
+    $$.val = &tree.AlterTableAlterConstraint{
+      Constraint: tree.Name($3),
+      Deferrability: tree.ConstraintNotDeferrable,
+    }
+  }
 | ALTER CONSTRAINT constraint_name error { return unimplementedWithIssueDetail(sqllex, 31632, "alter constraint") }
   // ALTER TABLE <name> INHERITS ....
 | INHERITS error
@@ -2075,6 +2168,67 @@ alter_type_stmt:
   }
 | ALTER TYPE error // SHOW HELP: ALTER TYPE
 
+// %Help: ALTER DOMAIN - change the definition of a domain
+// %Category: DDL
+// %Text:
+// ALTER DOMAIN <name> {SET DEFAULT <expr> | DROP DEFAULT}
+// ALTER DOMAIN <name> {SET | DROP} NOT NULL
+// ALTER DOMAIN <name> ADD [CONSTRAINT <name>] CHECK (<expr>)
+// ALTER DOMAIN <name> DROP CONSTRAINT [IF EXISTS] <name>
+//
+// %SeeAlso: CREATE DOMAIN, DROP DOMAIN
+alter_domain_stmt:
+  ALTER DOMAIN type_name SET DEFAULT a_expr
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainSetDefault{Default: $6.expr()},
+    }
+  }
+| ALTER DOMAIN type_name DROP DEFAULT
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainSetDefault{},
+    }
+  }
+| ALTER DOMAIN type_name SET NOT NULL
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainSetNotNull{NotNull: true},
+    }
+  }
+| ALTER DOMAIN type_name DROP NOT NULL
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainSetNotNull{NotNull: false},
+    }
+  }
+| ALTER DOMAIN type_name ADD domain_constraint
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainAddConstraint{Constraint: $5.domainConstraint()},
+    }
+  }
+| ALTER DOMAIN type_name DROP CONSTRAINT constraint_name opt_drop_behavior
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainDropConstraint{Constraint: tree.Name($6)},
+    }
+  }
+| ALTER DOMAIN type_name DROP CONSTRAINT IF EXISTS constraint_name opt_drop_behavior
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainDropConstraint{Constraint: tree.Name($8), IfExists: true},
+    }
+  }
+| ALTER DOMAIN error // SHOW HELP: ALTER DOMAIN
+
 opt_add_val_placement:
   BEFORE SCONST
   {
@@ -2171,6 +2325,16 @@ opt_clear_data:
 //    kms="[kms_provider]://[kms_host]/[master_key_identifier]?[parameters]" : encrypt backups using KMS
 //    detached: execute backup job asynchronously, without waiting for its completion
 //
+// BACKUP COMPACT <location> TO <location>
+//        [ WITH <option> [= <value>] [, ...] ]
+//
+// Merges a backup and the incremental backups appended to it into a new full
+// backup, reading only the backup files.
+//
+// Options:
+//    encryption_passphrase="secret": decrypt and encrypt the backups
+//    kms="[kms_provider]://[kms_host]/[master_key_identifier]?[parameters]" : decrypt and encrypt the backups using KMS
+//
 // %SeeAlso: RESTORE, WEBDOCS/backup.html
 backup_stmt:
   BACKUP opt_backup_targets INTO sconst_or_placeholder IN string_or_placeholder_opt_list opt_as_of_clause opt_with_backup_options
@@ -2216,6 +2380,10 @@ backup_stmt:
       Options: *$7.backupOptions(),
     }
   }
+| BACKUP COMPACT string_or_placeholder TO string_or_placeholder opt_with_options
+  {
+    $$.val = &tree.CompactBackup{From: $3.expr(), To: $5.expr(), Options: $6.kvOptions()}
+  }
 | BACKUP error // SHOW HELP: BACKUP
 
 opt_backup_targets:
@@ -2440,6 +2608,7 @@ opt_with_schedule_options:
 //
 // Options:
 //    into_db: specify target database
+//    new_db_name: specify a new name for the restored database
 //    skip_missing_foreign_keys: remove foreign key constraints before restoring
 //    skip_missing_sequences: ignore sequence dependencies
 //    skip_missing_views: skip restoring views because of dependencies that cannot be restored
@@ -2447,6 +2616,8 @@ opt_with_schedule_options:
 //    encryption_passphrase=passphrase: decrypt BACKUP with specified passphrase
 //    kms="[kms_provider]://[kms_host]/[master_key_identifier]?[parameters]" : decrypt backups using KMS
 //    detached: execute restore job asynchronously, without waiting for its completion
+//    verify_backup_table_data: read and check all backed up data without restoring it
+//    where=predicate: restore only the rows of a single table matching a predicate on its primary key
 // %SeeAlso: BACKUP, WEBDOCS/restore.html
 restore_stmt:
   RESTORE FROM list_of_string_or_placeholder_opt_list opt_as_of_clause opt_with_restore_options
@@ -2541,6 +2712,10 @@ restore_options:
   {
     $$.val = &tree.RestoreOptions{IntoDB: $3.expr()}
   }
+| NEW_DB_NAME '=' string_or_placeholder
+  {
+    $$.val = &tree.RestoreOptions{NewDBName: $3.expr()}
+  }
 | SKIP_MISSING_FOREIGN_KEYS
   {
     $$.val = &tree.RestoreOptions{SkipMissingFKs: true}
@@ -2561,6 +2736,14 @@ restore_options:
   {
     $$.val = &tree.RestoreOptions{Detached: true}
   }
+| VERIFY_BACKUP_TABLE_DATA
+  {
+    $$.val = &tree.RestoreOptions{VerifyData: true}
+  }
+| WHERE '=' string_or_placeholder
+  {
+    $$.val = &tree.RestoreOptions{Where: $3.expr()}
+  }
 
 import_format:
   name
@@ -2941,13 +3124,14 @@ comment_text:
 // %Text:
 // CREATE DATABASE, CREATE TABLE, CREATE INDEX, CREATE TABLE AS,
 // CREATE USER, CREATE VIEW, CREATE SEQUENCE, CREATE STATISTICS,
-// CREATE ROLE, CREATE TYPE, CREATE EXTENSION
+// CREATE ROLE, CREATE TYPE, CREATE EXTENSION, CREATE EXTERNAL CONNECTION
 create_stmt:
   create_role_stmt     // EXTEND WITH HELP: CREATE ROLE
 | create_ddl_stmt      // help texts in sub-rule
 | create_stats_stmt    // EXTEND WITH HELP: CREATE STATISTICS
 | create_schedule_for_backup_stmt   // EXTEND WITH HELP: CREATE SCHEDULE FOR BACKUP
 | create_extension_stmt // EXTEND WITH HELP: CREATE EXTENSION
+| create_external_connection_stmt // EXTEND WITH HELP: CREATE EXTERNAL CONNECTION
 | create_unsupported   {}
 | CREATE error         // SHOW HELP: CREATE
 
@@ -2964,6 +3148,51 @@ create_extension_stmt:
   }
 | CREATE EXTENSION error // SHOW HELP: CREATE EXTENSION
 
+// %Help: CREATE EXTERNAL CONNECTION - create a named external connection
+// %Category: Misc
+// %Text:
+// CREATE EXTERNAL CONNECTION [IF NOT EXISTS] <name> AS <uri>
+//
+// The connection can then be referenced as 'external://<name>[/<path>]'
+// wherever an external storage or KMS URI is expected.
+// %SeeAlso: ALTER EXTERNAL CONNECTION, DROP EXTERNAL CONNECTION, SHOW EXTERNAL CONNECTIONS
+create_external_connection_stmt:
+  CREATE EXTERNAL CONNECTION name AS string_or_placeholder
+  {
+    $$.val = &tree.CreateExternalConnection{Name: tree.Name($4), URI: $6.expr()}
+  }
+| CREATE EXTERNAL CONNECTION IF NOT EXISTS name AS string_or_placeholder
+  {
+    $$.val = &tree.CreateExternalConnection{Name: tree.Name($7), IfNotExists: true, URI: $9.expr()}
+  }
+| CREATE EXTERNAL CONNECTION error // SHOW HELP: CREATE EXTERNAL CONNECTION
+
+// %Help: ALTER EXTERNAL CONNECTION - change the URI of an external connection
+// %Category: Misc
+// %Text: ALTER EXTERNAL CONNECTION <name> AS <uri>
+// %SeeAlso: CREATE EXTERNAL CONNECTION
+alter_external_connection_stmt:
+  ALTER EXTERNAL CONNECTION name AS string_or_placeholder
+  {
+    $$.val = &tree.AlterExternalConnection{Name: tree.Name($4), URI: $6.expr()}
+  }
+| ALTER EXTERNAL CONNECTION error // SHOW HELP: ALTER EXTERNAL CONNECTION
+
+// %Help: DROP EXTERNAL CONNECTION - remove an external connection
+// %Category: Misc
+// %Text: DROP EXTERNAL CONNECTION [IF EXISTS] <name>
+// %SeeAlso: CREATE EXTERNAL CONNECTION
+drop_external_connection_stmt:
+  DROP EXTERNAL CONNECTION name
+  {
+    $$.val = &tree.DropExternalConnection{Name: tree.Name($4)}
+  }
+| DROP EXTERNAL CONNECTION IF EXISTS name
+  {
+    $$.val = &tree.DropExternalConnection{Name: tree.Name($6), IfExists: true}
+  }
+| DROP EXTERNAL CONNECTION error // SHOW HELP: DROP EXTERNAL CONNECTION
+
 create_unsupported:
   CREATE ACCESS METHOD error { return unimplemented(sqllex, "create access method") }
 | CREATE AGGREGATE error { return unimplemented(sqllex, "create aggregate") }
@@ -2973,8 +3202,6 @@ create_unsupported:
 | CREATE DEFAULT CONVERSION error { return unimplemented(sqllex, "create def conv") }
 | CREATE FOREIGN TABLE error { return unimplemented(sqllex, "create foreign table") }
 | CREATE FOREIGN DATA error { return unimplemented(sqllex, "create fdw") }
-| CREATE FUNCTION error { return unimplementedWithIssueDetail(sqllex, 17511, "create function") }
-| CREATE OR REPLACE FUNCTION error { return unimplementedWithIssueDetail(sqllex, 17511, "create function") }
 | CREATE opt_or_replace opt_trusted opt_procedural LANGUAGE name error { return unimplementedWithIssueDetail(sqllex, 17511, "create language " + $6) }
 | CREATE OPERATOR error { return unimplemented(sqllex, "create operator") }
 | CREATE PUBLICATION error { return unimplemented(sqllex, "create publication") }
@@ -2983,7 +3210,6 @@ create_unsupported:
 | CREATE SUBSCRIPTION error { return unimplemented(sqllex, "create subscription") }
 | CREATE TABLESPACE error { return unimplementedWithIssueDetail(sqllex, 54113, "create tablespace") }
 | CREATE TEXT error { return unimplementedWithIssueDetail(sqllex, 7821, "create text") }
-| CREATE TRIGGER error { return unimplementedWithIssueDetail(sqllex, 28296, "create") }
 
 opt_or_replace:
   OR REPLACE {}
@@ -3003,12 +3229,10 @@ drop_unsupported:
 | DROP CAST error { return unimplemented(sqllex, "drop cast") }
 | DROP COLLATION error { return unimplemented(sqllex, "drop collation") }
 | DROP CONVERSION error { return unimplemented(sqllex, "drop conversion") }
-| DROP DOMAIN error { return unimplementedWithIssueDetail(sqllex, 27796, "drop") }
 | DROP EXTENSION IF EXISTS name error { return unimplemented(sqllex, "drop extension " + $5) }
 | DROP EXTENSION name error { return unimplemented(sqllex, "drop extension " + $3) }
 | DROP FOREIGN TABLE error { return unimplemented(sqllex, "drop foreign table") }
 | DROP FOREIGN DATA error { return unimplemented(sqllex, "drop fdw") }
-| DROP FUNCTION error { return unimplementedWithIssueDetail(sqllex, 17511, "drop function") }
 | DROP opt_procedural LANGUAGE name error { return unimplementedWithIssueDetail(sqllex, 17511, "drop language " + $4) }
 | DROP OPERATOR error { return unimplemented(sqllex, "drop operator") }
 | DROP PUBLICATION error { return unimplemented(sqllex, "drop publication") }
@@ -3016,7 +3240,6 @@ drop_unsupported:
 | DROP SERVER error { return unimplemented(sqllex, "drop server") }
 | DROP SUBSCRIPTION error { return unimplemented(sqllex, "drop subscription") }
 | DROP TEXT error { return unimplementedWithIssueDetail(sqllex, 7821, "drop text") }
-| DROP TRIGGER error { return unimplementedWithIssueDetail(sqllex, 28296, "drop") }
 
 create_ddl_stmt:
   create_changefeed_stmt
@@ -3028,8 +3251,234 @@ create_ddl_stmt:
 // Error case for both CREATE TABLE and CREATE TABLE ... AS in one
 | CREATE opt_persistence_temp_table TABLE error   // SHOW HELP: CREATE TABLE
 | create_type_stmt     // EXTEND WITH HELP: CREATE TYPE
+| create_domain_stmt   // EXTEND WITH HELP: CREATE DOMAIN
 | create_view_stmt     // EXTEND WITH HELP: CREATE VIEW
 | create_sequence_stmt // EXTEND WITH HELP: CREATE SEQUENCE
+| create_function_stmt // EXTEND WITH HELP: CREATE FUNCTION
+| create_trigger_stmt  // EXTEND WITH HELP: CREATE TRIGGER
+
+// %Help: CREATE FUNCTION - define a new SQL-language function
+// %Category: DDL
+// %Text:
+// CREATE [OR REPLACE] FUNCTION <name> ( [ [<argname>] <argtype> [, ...] ] )
+//   { RETURNS [SETOF] <rettype> | RETURNS TABLE ( <colname> <coltype> [, ...] ) |
+//     RETURNS TRIGGER }
+//   LANGUAGE SQL
+//   [ IMMUTABLE | STABLE | VOLATILE ]
+//   [ CALLED ON NULL INPUT | RETURNS NULL ON NULL INPUT | STRICT ]
+//   AS '<body>'
+//
+// The body is a single SELECT statement. Arguments are referenced by
+// name or positionally as $1, $2, ...
+//
+// Trigger functions take no arguments. Their body can reference the rows
+// being modified as NEW and OLD.
+// %SeeAlso: DROP FUNCTION, CREATE TRIGGER
+create_function_stmt:
+  CREATE FUNCTION func_create_signature func_create_opt_list
+  {
+    n := $3.createFunction()
+    if err := n.SetOptions($4.functionOptions()); err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = n
+  }
+| CREATE OR REPLACE FUNCTION func_create_signature func_create_opt_list
+  {
+    n := $5.createFunction()
+    n.Replace = true
+    if err := n.SetOptions($6.functionOptions()); err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = n
+  }
+| CREATE FUNCTION error // SHOW HELP: CREATE FUNCTION
+| CREATE OR REPLACE FUNCTION error // SHOW HELP: CREATE FUNCTION
+
+func_create_signature:
+  db_object_name '(' opt_func_param_list ')' RETURNS typename
+  {
+    n := &tree.CreateFunction{
+      Name: $1.unresolvedObjectName(),
+      Params: $3.funcParams(),
+    }
+    // TRIGGER is not a reserved keyword, so RETURNS TRIGGER is parsed like
+    // any other type name.
+    if un, ok := $6.typeReference().(*tree.UnresolvedObjectName); ok && un.NumParts == 1 && un.Parts[0] == "trigger" {
+      n.ReturnsTrigger = true
+    } else {
+      n.ReturnType = $6.typeReference()
+    }
+    $$.val = n
+  }
+| db_object_name '(' opt_func_param_list ')' RETURNS SETOF typename
+  {
+    $$.val = &tree.CreateFunction{
+      Name: $1.unresolvedObjectName(),
+      Params: $3.funcParams(),
+      ReturnType: $7.typeReference(),
+      ReturnsSet: true,
+    }
+  }
+| db_object_name '(' opt_func_param_list ')' RETURNS TABLE '(' func_table_column_list ')'
+  {
+    $$.val = &tree.CreateFunction{
+      Name: $1.unresolvedObjectName(),
+      Params: $3.funcParams(),
+      ReturnsSet: true,
+      TableColumns: $8.funcParams(),
+    }
+  }
+
+opt_func_param_list:
+  func_param_list
+| /* EMPTY */
+  {
+    $$.val = tree.FuncParams(nil)
+  }
+
+func_param_list:
+  func_param
+  {
+    $$.val = tree.FuncParams{$1.funcParam()}
+  }
+| func_param_list ',' func_param
+  {
+    $$.val = append($1.funcParams(), $3.funcParam())
+  }
+
+func_param:
+  typename
+  {
+    $$.val = tree.FuncParam{Type: $1.typeReference()}
+  }
+| type_function_name_no_crdb_extra typename
+  {
+    $$.val = tree.FuncParam{Name: tree.Name($1), Type: $2.typeReference()}
+  }
+
+func_table_column_list:
+  func_table_column
+  {
+    $$.val = tree.FuncParams{$1.funcParam()}
+  }
+| func_table_column_list ',' func_table_column
+  {
+    $$.val = append($1.funcParams(), $3.funcParam())
+  }
+
+func_table_column:
+  name typename
+  {
+    $$.val = tree.FuncParam{Name: tree.Name($1), Type: $2.typeReference()}
+  }
+
+func_create_opt_list:
+  func_create_opt
+  {
+    $$.val = []tree.FunctionOption{$1.functionOption()}
+  }
+| func_create_opt_list func_create_opt
+  {
+    $$.val = append($1.functionOptions(), $2.functionOption())
+  }
+
+func_create_opt:
+  LANGUAGE non_reserved_word_or_sconst
+  {
+    $$.val = tree.FunctionLanguage($2)
+  }
+| IMMUTABLE
+  {
+    $$.val = tree.FunctionVolatility(tree.VolatilityImmutable)
+  }
+| STABLE
+  {
+    $$.val = tree.FunctionVolatility(tree.VolatilityStable)
+  }
+| VOLATILE
+  {
+    $$.val = tree.FunctionVolatility(tree.VolatilityVolatile)
+  }
+| STRICT
+  {
+    $$.val = tree.FunctionNullInputBehavior(true)
+  }
+| RETURNS NULL ON NULL INPUT
+  {
+    $$.val = tree.FunctionNullInputBehavior(true)
+  }
+| CALLED ON NULL INPUT
+  {
+    $$.val = tree.FunctionNullInputBehavior(false)
+  }
+| AS SCONST
+  {
+    $$.val = tree.FunctionBody($2)
+  }
+
+// %Help: CREATE TRIGGER - define a new row-level trigger
+// %Category: DDL
+// %Text:
+// CREATE TRIGGER <name> { BEFORE | AFTER } { INSERT | UPDATE | DELETE } [ OR ... ]
+//   ON <tablename> FOR [EACH] ROW EXECUTE { FUNCTION | PROCEDURE } <funcname> ()
+//
+// The function must be declared with RETURNS TRIGGER.
+// %SeeAlso: DROP TRIGGER, CREATE FUNCTION
+create_trigger_stmt:
+  CREATE TRIGGER name trigger_action_time trigger_event_list ON table_name FOR opt_each ROW EXECUTE function_or_procedure db_object_name '(' ')'
+  {
+    $$.val = &tree.CreateTrigger{
+      Name: tree.Name($3),
+      Before: $4.bool(),
+      Events: $5.triggerEvents(),
+      Table: $7.unresolvedObjectName(),
+      FuncName: $13.unresolvedObjectName(),
+    }
+  }
+| CREATE TRIGGER error // SHOW HELP: CREATE TRIGGER
+
+trigger_action_time:
+  BEFORE
+  {
+    $$.val = true
+  }
+| AFTER
+  {
+    $$.val = false
+  }
+
+trigger_event_list:
+  trigger_event
+  {
+    $$.val = tree.TriggerEvents{$1.triggerEvent()}
+  }
+| trigger_event_list OR trigger_event
+  {
+    $$.val = append($1.triggerEvents(), $3.triggerEvent())
+  }
+
+trigger_event:
+  INSERT
+  {
+    $$.val = tree.TriggerEventInsert
+  }
+| UPDATE
+  {
+    $$.val = tree.TriggerEventUpdate
+  }
+| DELETE
+  {
+    $$.val = tree.TriggerEventDelete
+  }
+
+opt_each:
+  EACH {}
+| /* EMPTY */ {}
+
+function_or_procedure:
+  FUNCTION {}
+| PROCEDURE {}
 
 // %Help: CREATE STATISTICS - create a new table statistic
 // %Category: Misc
@@ -3220,11 +3669,12 @@ discard_stmt:
 // %Category: Group
 // %Text:
 // DROP DATABASE, DROP INDEX, DROP TABLE, DROP VIEW, DROP SEQUENCE,
-// DROP USER, DROP ROLE, DROP TYPE
+// DROP USER, DROP ROLE, DROP TYPE, DROP EXTERNAL CONNECTION
 drop_stmt:
   drop_ddl_stmt      // help texts in sub-rule
 | drop_role_stmt     // EXTEND WITH HELP: DROP ROLE
 | drop_schedule_stmt // EXTEND WITH HELP: DROP SCHEDULES
+| drop_external_connection_stmt // EXTEND WITH HELP: DROP EXTERNAL CONNECTION
 | drop_unsupported   {}
 | DROP error         // SHOW HELP: DROP
 
@@ -3236,6 +3686,9 @@ drop_ddl_stmt:
 | drop_sequence_stmt // EXTEND WITH HELP: DROP SEQUENCE
 | drop_schema_stmt   // EXTEND WITH HELP: DROP SCHEMA
 | drop_type_stmt     // EXTEND WITH HELP: DROP TYPE
+| drop_domain_stmt   // EXTEND WITH HELP: DROP DOMAIN
+| drop_function_stmt // EXTEND WITH HELP: DROP FUNCTION
+| drop_trigger_stmt  // EXTEND WITH HELP: DROP TRIGGER
 
 // %Help: DROP VIEW - remove a view
 // %Category: DDL
@@ -3348,6 +3801,73 @@ drop_database_stmt:
   }
 | DROP DATABASE error // SHOW HELP: DROP DATABASE
 
+// %Help: DROP FUNCTION - remove a user-defined function
+// %Category: DDL
+// %Text: DROP FUNCTION [IF EXISTS] <name> [ ( [ <argtype> [, ...] ] ) ] [, ...] [CASCADE | RESTRICT]
+// %SeeAlso: CREATE FUNCTION
+drop_function_stmt:
+  DROP FUNCTION function_with_argtypes_list opt_drop_behavior
+  {
+    $$.val = &tree.DropFunction{
+      Names: $3.unresolvedObjectNames(),
+      IfExists: false,
+      DropBehavior: $4.dropBehavior(),
+    }
+  }
+| DROP FUNCTION IF EXISTS function_with_argtypes_list opt_drop_behavior
+  {
+    $$.val = &tree.DropFunction{
+      Names: $5.unresolvedObjectNames(),
+      IfExists: true,
+      DropBehavior: $6.dropBehavior(),
+    }
+  }
+| DROP FUNCTION error // SHOW HELP: DROP FUNCTION
+
+function_with_argtypes_list:
+  function_with_argtypes
+  {
+    $$.val = []*tree.UnresolvedObjectName{$1.unresolvedObjectName()}
+  }
+| function_with_argtypes_list ',' function_with_argtypes
+  {
+    $$.val = append($1.unresolvedObjectNames(), $3.unresolvedObjectName())
+  }
+
+// Functions cannot be overloaded, so the argument types are accepted for
+// compatibility but otherwise ignored.
+function_with_argtypes:
+  db_object_name
+| db_object_name '(' opt_func_param_list ')'
+  {
+    $$.val = $1.unresolvedObjectName()
+  }
+
+// %Help: DROP TRIGGER - remove a trigger
+// %Category: DDL
+// %Text: DROP TRIGGER [IF EXISTS] <name> ON <tablename> [CASCADE | RESTRICT]
+// %SeeAlso: CREATE TRIGGER
+drop_trigger_stmt:
+  DROP TRIGGER name ON table_name opt_drop_behavior
+  {
+    $$.val = &tree.DropTrigger{
+      Name: tree.Name($3),
+      Table: $5.unresolvedObjectName(),
+      IfExists: false,
+      DropBehavior: $6.dropBehavior(),
+    }
+  }
+| DROP TRIGGER IF EXISTS name ON table_name opt_drop_behavior
+  {
+    $$.val = &tree.DropTrigger{
+      Name: tree.Name($5),
+      Table: $7.unresolvedObjectName(),
+      IfExists: true,
+      DropBehavior: $8.dropBehavior(),
+    }
+  }
+| DROP TRIGGER error // SHOW HELP: DROP TRIGGER
+
 // %Help: DROP TYPE - remove a type
 // %Category: DDL
 // %Text: DROP TYPE [IF EXISTS] <type_name> [, ...] [CASCASE | RESTRICT]
@@ -3370,6 +3890,31 @@ drop_type_stmt:
   }
 | DROP TYPE error // SHOW HELP: DROP TYPE
 
+// %Help: DROP DOMAIN - remove a domain
+// %Category: DDL
+// %Text: DROP DOMAIN [IF EXISTS] <name> [, ...] [CASCADE | RESTRICT]
+// %SeeAlso: CREATE DOMAIN, ALTER DOMAIN
+drop_domain_stmt:
+  DROP DOMAIN type_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropType{
+      Names: $3.unresolvedObjectNames(),
+      IfExists: false,
+      DropBehavior: $4.dropBehavior(),
+      IsDomain: true,
+    }
+  }
+| DROP DOMAIN IF EXISTS type_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropType{
+      Names: $5.unresolvedObjectNames(),
+      IfExists: true,
+      DropBehavior: $6.dropBehavior(),
+      IsDomain: true,
+    }
+  }
+| DROP DOMAIN error // SHOW HELP: DROP DOMAIN
+
 target_types:
   type_name_list
   {
@@ -3694,13 +4239,14 @@ deallocate_stmt:
 //   GRANT <roles...> TO <grantees...> [WITH ADMIN OPTION]
 //
 // Privileges:
-//   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE
+//   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, EXECUTE
 //
 // Targets:
 //   DATABASE <databasename> [, ...]
 //   [TABLE] [<databasename> .] { <tablename> | * } [, ...]
 //   TYPE <typename> [, <typename>]...
 //   SCHEMA <schemaname> [, <schemaname]...
+//   FUNCTION <funcname> [, <funcname>]...
 //
 // %SeeAlso: REVOKE, WEBDOCS/grant.html
 grant_stmt:
@@ -3730,6 +4276,26 @@ grant_stmt:
       Grantees: $7.nameList(),
     }
   }
+| GRANT privileges ON EXTERNAL CONNECTION name_list TO name_list
+  {
+    $$.val = &tree.Grant{
+      Privileges: $2.privilegeList(),
+      Targets: tree.TargetList{
+        ExternalConnections: $6.nameList(),
+      },
+      Grantees: $8.nameList(),
+    }
+  }
+| GRANT privileges ON FUNCTION function_with_argtypes_list TO name_list
+  {
+    $$.val = &tree.Grant{
+      Privileges: $2.privilegeList(),
+      Targets: tree.TargetList{
+        Functions: $5.unresolvedObjectNames(),
+      },
+      Grantees: $7.nameList(),
+    }
+  }
 | GRANT error // SHOW HELP: GRANT
 
 // %Help: REVOKE - remove access privileges and role memberships
@@ -3741,13 +4307,14 @@ grant_stmt:
 //   REVOKE [ADMIN OPTION FOR] <roles...> FROM <grantees...>
 //
 // Privileges:
-//   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE
+//   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, EXECUTE
 //
 // Targets:
 //   DATABASE <databasename> [, <databasename>]...
 //   [TABLE] [<databasename> .] { <tablename> | * } [, ...]
 //   TYPE <typename> [, <typename>]...
 //   SCHEMA <schemaname> [, <schemaname]...
+//   FUNCTION <funcname> [, <funcname>]...
 //
 // %SeeAlso: GRANT, WEBDOCS/revoke.html
 revoke_stmt:
@@ -3777,6 +4344,26 @@ revoke_stmt:
       Grantees: $7.nameList(),
     }
   }
+| REVOKE privileges ON EXTERNAL CONNECTION name_list FROM name_list
+  {
+    $$.val = &tree.Revoke{
+      Privileges: $2.privilegeList(),
+      Targets: tree.TargetList{
+        ExternalConnections: $6.nameList(),
+      },
+      Grantees: $8.nameList(),
+    }
+  }
+| REVOKE privileges ON FUNCTION function_with_argtypes_list FROM name_list
+  {
+    $$.val = &tree.Revoke{
+      Privileges: $2.privilegeList(),
+      Targets: tree.TargetList{
+        Functions: $5.unresolvedObjectNames(),
+      },
+      Grantees: $7.nameList(),
+    }
+  }
 | REVOKE error // SHOW HELP: REVOKE
 
 // ALL is always by itself.
@@ -3861,6 +4448,17 @@ use_stmt:
 nonpreparable_set_stmt:
   set_transaction_stmt // EXTEND WITH HELP: SET TRANSACTION
 | set_exprs_internal   { /* SKIP DOC */ }
+| SET CONSTRAINTS ALL DEFERRED
+  {
+    /* SKIP DOC */
+    $$.val = &tree.SetConstraints{Deferred: true}
+  }
+| SET CONSTRAINTS ALL IMMEDIATE
+  {
+    /* SKIP DOC */
+    $$.val = &tree.SetConstraints{Deferred: false}
+  }
+| SET CONSTRAINTS name_list error { return unimplementedWithIssueDetail(sqllex, 31632, "set constraints name") }
 | SET CONSTRAINTS error { return unimplemented(sqllex, "set constraints") }
 | SET LOCAL error { return unimplementedWithIssue(sqllex, 32562) }
 
@@ -4232,7 +4830,8 @@ zone_value:
 // PARTITIONS, SHOW JOBS, SHOW QUERIES, SHOW RANGE, SHOW RANGES,
 // SHOW ROLES, SHOW SCHEMAS, SHOW SEQUENCES, SHOW SESSION, SHOW SESSIONS,
 // SHOW STATISTICS, SHOW SYNTAX, SHOW TABLES, SHOW TRACE, SHOW TRANSACTION,
-// SHOW TRANSACTIONS, SHOW TYPES, SHOW USERS, SHOW LAST QUERY STATISTICS, SHOW SCHEDULES
+// SHOW TRANSACTIONS, SHOW TYPES, SHOW USERS, SHOW LAST QUERY STATISTICS, SHOW SCHEDULES,
+// SHOW EXTERNAL CONNECTIONS
 show_stmt:
   show_backup_stmt          // EXTEND WITH HELP: SHOW BACKUP
 | show_columns_stmt         // EXTEND WITH HELP: SHOW COLUMNS
@@ -4241,6 +4840,7 @@ show_stmt:
 | show_csettings_stmt       // EXTEND WITH HELP: SHOW CLUSTER SETTING
 | show_databases_stmt       // EXTEND WITH HELP: SHOW DATABASES
 | show_enums_stmt           // EXTEND WITH HELP: SHOW ENUMS
+| show_external_connections_stmt // EXTEND WITH HELP: SHOW EXTERNAL CONNECTIONS
 | show_types_stmt           // EXTEND WITH HELP: SHOW TYPES
 | show_fingerprints_stmt
 | show_grants_stmt          // EXTEND WITH HELP: SHOW GRANTS
@@ -4269,14 +4869,189 @@ show_stmt:
 | SHOW error                // SHOW HELP: SHOW
 | show_last_query_stats_stmt // EXTEND WITH HELP: SHOW LAST QUERY STATISTICS
 
-// Cursors are not yet supported by CockroachDB. CLOSE ALL is safe to no-op
-// since there will be no open cursors.
+// %Help: CLOSE - close a cursor
+// %Category: Misc
+// %Text: CLOSE { <name> | ALL }
+// %SeeAlso: DECLARE, FETCH, MOVE
 close_cursor_stmt:
-	CLOSE ALL { }
-| CLOSE cursor_name { return unimplementedWithIssue(sqllex, 41412) }
+  CLOSE ALL
+  {
+    $$.val = &tree.CloseCursor{All: true}
+  }
+| CLOSE cursor_name
+  {
+    $$.val = &tree.CloseCursor{Name: tree.Name($2)}
+  }
+| CLOSE error // SHOW HELP: CLOSE
 
+// %Help: DECLARE - define a cursor
+// %Category: Misc
+// %Text:
+// DECLARE <name> [ BINARY ] [ INSENSITIVE ] [ [ NO ] SCROLL ]
+//   CURSOR [ { WITH | WITHOUT } HOLD ] FOR <selectclause>
+// %SeeAlso: FETCH, MOVE, CLOSE
 declare_cursor_stmt:
-	DECLARE { return unimplementedWithIssue(sqllex, 41412) }
+  DECLARE cursor_name cursor_options CURSOR opt_hold FOR select_stmt
+  {
+    n := $3.declareCursor()
+    n.Name = tree.Name($2)
+    n.Hold = $5.bool()
+    n.Select = $7.slct()
+    $$.val = n
+  }
+| DECLARE error // SHOW HELP: DECLARE
+
+cursor_options:
+  /* EMPTY */
+  {
+    $$.val = &tree.DeclareCursor{}
+  }
+| cursor_options BINARY
+  {
+    n := $1.declareCursor()
+    n.Binary = true
+    $$.val = n
+  }
+| cursor_options INSENSITIVE
+  {
+    n := $1.declareCursor()
+    n.Insensitive = true
+    $$.val = n
+  }
+| cursor_options SCROLL
+  {
+    n := $1.declareCursor()
+    n.Scroll = tree.Scroll
+    $$.val = n
+  }
+| cursor_options NO SCROLL
+  {
+    n := $1.declareCursor()
+    n.Scroll = tree.NoScroll
+    $$.val = n
+  }
+
+opt_hold:
+  WITH HOLD
+  {
+    $$.val = true
+  }
+| WITHOUT HOLD
+  {
+    $$.val = false
+  }
+| /* EMPTY */
+  {
+    $$.val = false
+  }
+
+// %Help: FETCH - retrieve rows from a cursor
+// %Category: Misc
+// %Text:
+// FETCH [ <direction> ] [ { FROM | IN } ] <name>
+//
+// Direction:
+//   NEXT | FIRST | LAST | ABSOLUTE <count> | RELATIVE <count> | <count> | ALL
+//   FORWARD [ <count> | ALL ]
+//
+// %SeeAlso: DECLARE, MOVE, CLOSE
+fetch_cursor_stmt:
+  FETCH fetch_args
+  {
+    $$.val = &tree.FetchCursor{CursorStmt: $2.cursorStmt()}
+  }
+| FETCH error // SHOW HELP: FETCH
+
+// %Help: MOVE - position a cursor
+// %Category: Misc
+// %Text:
+// MOVE [ <direction> ] [ { FROM | IN } ] <name>
+//
+// Direction:
+//   NEXT | FIRST | LAST | ABSOLUTE <count> | RELATIVE <count> | <count> | ALL
+//   FORWARD [ <count> | ALL ]
+//
+// %SeeAlso: DECLARE, FETCH, CLOSE
+move_cursor_stmt:
+  MOVE fetch_args
+  {
+    $$.val = &tree.MoveCursor{CursorStmt: $2.cursorStmt()}
+  }
+| MOVE error // SHOW HELP: MOVE
+
+fetch_args:
+  cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($1), Count: 1}
+  }
+| from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($2), Count: 1}
+  }
+| NEXT opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Count: 1}
+  }
+| PRIOR opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Count: -1}
+  }
+| FIRST opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), FetchType: tree.FetchFirst}
+  }
+| LAST opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), FetchType: tree.FetchLast}
+  }
+| ABSOLUTE signed_iconst64 opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($4), FetchType: tree.FetchAbsolute, Count: $2.int64()}
+  }
+| RELATIVE signed_iconst64 opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($4), FetchType: tree.FetchRelative, Count: $2.int64()}
+  }
+| signed_iconst64 opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Count: $1.int64()}
+  }
+| ALL opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), FetchType: tree.FetchAll}
+  }
+| FORWARD opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Count: 1}
+  }
+| FORWARD signed_iconst64 opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($4), Count: $2.int64()}
+  }
+| FORWARD ALL opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($4), FetchType: tree.FetchAll}
+  }
+| BACKWARD opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Count: -1}
+  }
+| BACKWARD signed_iconst64 opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($4), Count: -$2.int64()}
+  }
+| BACKWARD ALL opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($4), FetchType: tree.FetchBackwardAll}
+  }
+
+from_in:
+  FROM { }
+| IN { }
+
+opt_from_in:
+  from_in { }
+| /* EMPTY */ { }
 
 reindex_stmt:
   REINDEX TABLE error
@@ -4372,7 +5147,9 @@ show_histogram_stmt:
 
 // %Help: SHOW BACKUP - list backup contents
 // %Category: CCL
-// %Text: SHOW BACKUP [SCHEMAS|FILES|RANGES] <location>
+// %Text:
+// SHOW BACKUP [SCHEMAS|FILES|RANGES] <location>
+// SHOW BACKUP [SCHEMAS] ( <location> [, ...] )
 // %SeeAlso: WEBDOCS/show-backup.html
 show_backup_stmt:
   SHOW BACKUPS IN string_or_placeholder
@@ -4389,6 +5166,15 @@ show_backup_stmt:
       Options: $4.kvOptions(),
     }
   }
+| SHOW BACKUP '(' string_or_placeholder_list ')' opt_with_options
+  {
+    $$.val = &tree.ShowBackup{
+      Details: tree.BackupDefaultDetails,
+      Path:    $4.exprs()[0],
+      LocalityPaths: $4.exprs()[1:],
+      Options: $6.kvOptions(),
+    }
+  }
 | SHOW BACKUP string_or_placeholder IN string_or_placeholder opt_with_options
   {
     $$.val = &tree.ShowBackup{
@@ -4407,6 +5193,16 @@ show_backup_stmt:
       Options: $5.kvOptions(),
     }
   }
+| SHOW BACKUP SCHEMAS '(' string_or_placeholder_list ')' opt_with_options
+  {
+    $$.val = &tree.ShowBackup{
+      Details: tree.BackupDefaultDetails,
+      ShouldIncludeSchemas: true,
+      Path:    $5.exprs()[0],
+      LocalityPaths: $5.exprs()[1:],
+      Options: $7.kvOptions(),
+    }
+  }
 | SHOW BACKUP RANGES string_or_placeholder opt_with_options
   {
     /* SKIP DOC */
@@ -4667,6 +5463,17 @@ show_jobs_stmt:
   }
 | SHOW JOB error // SHOW HELP: SHOW JOBS
 
+// %Help: SHOW EXTERNAL CONNECTIONS - list external connections
+// %Category: Misc
+// %Text: SHOW EXTERNAL CONNECTIONS
+// %SeeAlso: CREATE EXTERNAL CONNECTION
+show_external_connections_stmt:
+  SHOW EXTERNAL CONNECTIONS
+  {
+    $$.val = &tree.ShowExternalConnections{}
+  }
+| SHOW EXTERNAL CONNECTIONS error // SHOW HELP: SHOW EXTERNAL CONNECTIONS
+
 // %Help: SHOW SCHEDULES - list periodic schedules
 // %Category: Misc
 // %Text:
@@ -5441,7 +6248,7 @@ alter_schema_stmt:
 //   FAMILY <familyname>, CREATE [IF NOT EXISTS] FAMILY [<familyname>]
 //   REFERENCES <tablename> [( <colnames...> )] [ON DELETE {NO ACTION | RESTRICT}] [ON UPDATE {NO ACTION | RESTRICT}]
 //   COLLATE <collationname>
-//   AS ( <expr> ) STORED
+//   AS ( <expr> ) { STORED | VIRTUAL }
 //
 // Interleave clause:
 //    INTERLEAVE IN PARENT <tablename> ( <colnames...> ) [CASCADE | RESTRICT]
@@ -5879,6 +6686,10 @@ col_qualification_elem:
   {
     $$.val = tree.UniqueConstraint{}
   }
+| UNIQUE constraint_deferrability
+  {
+    $$.val = tree.UniqueConstraint{Deferrability: $2.constraintDeferrability()}
+  }
 | PRIMARY KEY
   {
     $$.val = tree.PrimaryKeyConstraint{}
@@ -5898,7 +6709,7 @@ col_qualification_elem:
   {
     $$.val = &tree.ColumnDefault{Expr: $2.expr()}
   }
-| REFERENCES table_name opt_name_parens key_match reference_actions
+| REFERENCES table_name opt_name_parens key_match reference_actions opt_deferrable
  {
     name := $2.unresolvedObjectName().ToTableName()
     $$.val = &tree.ColumnFKConstraint{
@@ -5906,6 +6717,7 @@ col_qualification_elem:
       Col: tree.Name($3),
       Actions: $5.referenceActions(),
       Match: $4.compositeKeyMatchMethod(),
+      Deferrability: $6.constraintDeferrability(),
     }
  }
 | generated_as '(' a_expr ')' STORED
@@ -5914,11 +6726,11 @@ col_qualification_elem:
  }
 | generated_as '(' a_expr ')' VIRTUAL
  {
-    return unimplemented(sqllex, "virtual computed columns")
+    $$.val = &tree.ColumnComputedDef{Expr: $3.expr(), Virtual: true}
  }
 | generated_as error
  {
-    sqllex.Error("use AS ( <expr> ) STORED")
+    sqllex.Error("use AS ( <expr> ) STORED or AS ( <expr> ) VIRTUAL")
     return 1
  }
 
@@ -5994,6 +6806,10 @@ table_constraint:
 constraint_elem:
   CHECK '(' a_expr ')' opt_deferrable
   {
+    if $5.constraintDeferrability() != tree.ConstraintNotDeferrable {
+      return setErr(sqllex, pgerror.New(pgcode.FeatureNotSupported,
+        "CHECK constraints cannot be marked DEFERRABLE"))
+    }
     $$.val = &tree.CheckConstraintTableDef{
       Expr: $3.expr(),
     }
@@ -6008,6 +6824,7 @@ constraint_elem:
         PartitionBy: $7.partitionBy(),
         Predicate: $9.expr(),
       },
+      Deferrability: $8.constraintDeferrability(),
     }
   }
 | PRIMARY KEY '(' index_params ')' opt_hash_sharded opt_interleave
@@ -6031,6 +6848,7 @@ constraint_elem:
       ToCols: $8.nameList(),
       Match: $9.compositeKeyMatchMethod(),
       Actions: $10.referenceActions(),
+      Deferrability: $11.constraintDeferrability(),
     }
   }
 | EXCLUDE USING error
@@ -6141,12 +6959,37 @@ create_as_col_qualification_elem:
   }
 
 opt_deferrable:
-  /* EMPTY */ { /* no error */ }
-| DEFERRABLE { return unimplementedWithIssueDetail(sqllex, 31632, "deferrable") }
-| DEFERRABLE INITIALLY DEFERRED { return unimplementedWithIssueDetail(sqllex, 31632, "def initially deferred") }
-| DEFERRABLE INITIALLY IMMEDIATE { return unimplementedWithIssueDetail(sqllex, 31632, "def initially immediate") }
-| INITIALLY DEFERRED { return unimplementedWithIssueDetail(sqllex, 31632, "initially deferred") }
-| INITIALLY IMMEDIATE { return unimplementedWithIssueDetail(sqllex, 31632, "initially immediate") }
+  /* EMPTY */
+  {
+    $$.val = tree.ConstraintNotDeferrable
+  }
+| constraint_deferrability
+  {
+    $$.val = $1.constraintDeferrability()
+  }
+
+constraint_deferrability:
+  DEFERRABLE
+  {
+    $$.val = tree.ConstraintInitiallyImmediate
+  }
+| DEFERRABLE INITIALLY DEFERRED
+  {
+    $$.val = tree.ConstraintInitiallyDeferred
+  }
+| DEFERRABLE INITIALLY IMMEDIATE
+  {
+    $$.val = tree.ConstraintInitiallyImmediate
+  }
+| INITIALLY DEFERRED
+  {
+    // As in Postgres, INITIALLY DEFERRED implies DEFERRABLE.
+    $$.val = tree.ConstraintInitiallyDeferred
+  }
+| INITIALLY IMMEDIATE
+  {
+    $$.val = tree.ConstraintNotDeferrable
+  }
 
 storing:
   COVERING
@@ -6612,7 +7455,9 @@ opt_view_recursive:
 
 // %Help: CREATE TYPE -- create a type
 // %Category: DDL
-// %Text: CREATE TYPE <type_name> AS ENUM (...)
+// %Text:
+// CREATE TYPE <type_name> AS ENUM (...)
+// CREATE TYPE <type_name> AS (<field_name> <type> [, ...])
 create_type_stmt:
   // Enum types.
   CREATE TYPE type_name AS ENUM '(' opt_enum_val_list ')'
@@ -6623,17 +7468,108 @@ create_type_stmt:
       EnumLabels: $7.strs(),
     }
   }
-| CREATE TYPE error // SHOW HELP: CREATE TYPE
   // Record/Composite types.
-| CREATE TYPE type_name AS '(' error      { return unimplementedWithIssue(sqllex, 27792) }
+| CREATE TYPE type_name AS '(' opt_composite_type_field_list ')'
+  {
+    $$.val = &tree.CreateType{
+      TypeName: $3.unresolvedObjectName(),
+      Variety: tree.Composite,
+      CompositeFields: $6.compositeTypeFields(),
+    }
+  }
+| CREATE TYPE error // SHOW HELP: CREATE TYPE
   // Range types.
 | CREATE TYPE type_name AS RANGE error    { return unimplementedWithIssue(sqllex, 27791) }
   // Base (primitive) types.
 | CREATE TYPE type_name '(' error         { return unimplementedWithIssueDetail(sqllex, 27793, "base") }
   // Shell types, gateway to define base types using the previous syntax.
 | CREATE TYPE type_name                   { return unimplementedWithIssueDetail(sqllex, 27793, "shell") }
-  // Domain types.
-| CREATE DOMAIN type_name error           { return unimplementedWithIssueDetail(sqllex, 27796, "create") }
+
+// %Help: CREATE DOMAIN - define a new domain
+// %Category: DDL
+// %Text:
+// CREATE DOMAIN <name> [AS] <type> [DEFAULT <expr>] [<constraint> ...]
+//
+// Constraints:
+//   [CONSTRAINT <name>] NOT NULL
+//   [CONSTRAINT <name>] NULL
+//   [CONSTRAINT <name>] CHECK (<expr>)
+//
+// %SeeAlso: ALTER DOMAIN, DROP DOMAIN
+create_domain_stmt:
+  CREATE DOMAIN type_name typename domain_qual_list
+  {
+    stmt, err := tree.NewCreateDomain($3.unresolvedObjectName(), $4.typeReference(), $5.colQuals())
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = stmt
+  }
+| CREATE DOMAIN type_name AS typename domain_qual_list
+  {
+    stmt, err := tree.NewCreateDomain($3.unresolvedObjectName(), $5.typeReference(), $6.colQuals())
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = stmt
+  }
+| CREATE DOMAIN error // SHOW HELP: CREATE DOMAIN
+
+domain_qual_list:
+  domain_qual_list domain_qualification
+  {
+    $$.val = append($1.colQuals(), $2.colQual())
+  }
+| /* EMPTY */
+  {
+    $$.val = []tree.NamedColumnQualification(nil)
+  }
+
+domain_qualification:
+  CONSTRAINT constraint_name domain_qualification_elem
+  {
+    $$.val = tree.NamedColumnQualification{Name: tree.Name($2), Qualification: $3.colQualElem()}
+  }
+| domain_qualification_elem
+  {
+    $$.val = tree.NamedColumnQualification{Qualification: $1.colQualElem()}
+  }
+
+domain_qualification_elem:
+  NOT NULL
+  {
+    $$.val = tree.NotNullConstraint{}
+  }
+| NULL
+  {
+    $$.val = tree.NullConstraint{}
+  }
+| CHECK '(' a_expr ')'
+  {
+    $$.val = &tree.ColumnCheckConstraint{Expr: $3.expr()}
+  }
+| DEFAULT b_expr
+  {
+    $$.val = &tree.ColumnDefault{Expr: $2.expr()}
+  }
+
+domain_constraint:
+  CONSTRAINT constraint_name CHECK '(' a_expr ')'
+  {
+    $$.val = tree.DomainConstraint{Name: tree.Name($2), Check: $5.expr()}
+  }
+| CHECK '(' a_expr ')'
+  {
+    $$.val = tree.DomainConstraint{Check: $3.expr()}
+  }
+| CONSTRAINT constraint_name NOT NULL
+  {
+    $$.val = tree.DomainConstraint{Name: tree.Name($2), NotNull: true}
+  }
+| NOT NULL
+  {
+    $$.val = tree.DomainConstraint{NotNull: true}
+  }
 
 opt_enum_val_list:
   enum_val_list
@@ -6655,6 +7591,29 @@ enum_val_list:
     $$.val = append($1.strs(), $3)
   }
 
+opt_composite_type_field_list:
+  composite_type_field_list
+| /* EMPTY */
+  {
+    $$.val = []tree.CompositeTypeField(nil)
+  }
+
+composite_type_field_list:
+  composite_type_field
+  {
+    $$.val = []tree.CompositeTypeField{$1.compositeTypeField()}
+  }
+| composite_type_field_list ',' composite_type_field
+  {
+    $$.val = append($1.compositeTypeFields(), $3.compositeTypeField())
+  }
+
+composite_type_field:
+  name typename
+  {
+    $$.val = tree.CompositeTypeField{Label: tree.Name($1), Type: $2.typeReference()}
+  }
+
 // %Help: CREATE INDEX - create a new index
 // %Category: DDL
 // %Text:
@@ -6823,22 +7782,10 @@ index_elem_options:
     opClass := $1
     dir := $2.dir()
     nullsOrder := $3.nullsOrder()
-    if opClass != "" {
-      if opClass == "gin_trgm_ops" || opClass == "gist_trgm_ops" {
-        return unimplementedWithIssueDetail(sqllex, 41285, "index using " + opClass)
-      }
+    if opClass != "" && opClass != "gin_trgm_ops" && opClass != "gist_trgm_ops" {
       return unimplementedWithIssue(sqllex, 47420)
     }
-    // We currently only support the opposite of Postgres defaults.
-    if nullsOrder != tree.DefaultNullsOrder {
-      if dir == tree.Descending && nullsOrder == tree.NullsFirst {
-        return unimplementedWithIssue(sqllex, 6224)
-      }
-      if dir != tree.Descending && nullsOrder == tree.NullsLast {
-        return unimplementedWithIssue(sqllex, 6224)
-      }
-    }
-    $$.val = tree.IndexElem{Direction: dir, NullsOrder: nullsOrder}
+    $$.val = tree.IndexElem{Direction: dir, NullsOrder: nullsOrder, OpClass: tree.Name(opClass)}
   }
 
 opt_class:
@@ -7602,7 +8549,10 @@ single_set_clause:
   {
     $$.val = &tree.UpdateExpr{Names: tree.NameList{tree.Name($1)}, Expr: $3.expr()}
   }
-| column_name '.' error { return unimplementedWithIssue(sqllex, 27792) }
+| column_name '.' name '=' a_expr
+  {
+    $$.val = &tree.UpdateExpr{Names: tree.NameList{tree.Name($1)}, Field: tree.Name($3), Expr: $5.expr()}
+  }
 
 multiple_set_clause:
   '(' insert_column_list ')' '=' in_expr
@@ -8067,15 +9017,6 @@ sortby:
     /* FORCE DOC */
     dir := $2.dir()
     nullsOrder := $3.nullsOrder()
-    // We currently only support the opposite of Postgres defaults.
-    if nullsOrder != tree.DefaultNullsOrder {
-      if dir == tree.Descending && nullsOrder == tree.NullsFirst {
-        return unimplementedWithIssue(sqllex, 6224)
-      }
-      if dir != tree.Descending && nullsOrder == tree.NullsLast {
-        return unimplementedWithIssue(sqllex, 6224)
-      }
-    }
     $$.val = &tree.Order{
       OrderType:  tree.OrderByColumn,
       Expr:       $1.expr(),
@@ -8245,9 +9186,18 @@ group_by_list:
 // rather than reducing the conflicting unreserved_keyword rule.
 group_by_item:
   a_expr { $$.val = $1.expr() }
-| ROLLUP '(' error { return unimplementedWithIssueDetail(sqllex, 46280, "rollup") }
-| CUBE '(' error { return unimplementedWithIssueDetail(sqllex, 46280, "cube") }
-| GROUPING SETS error { return unimplementedWithIssueDetail(sqllex, 46280, "grouping sets") }
+| ROLLUP '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingSet{Type: tree.RollupGroupingSet, Exprs: $3.exprs()}
+  }
+| CUBE '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingSet{Type: tree.CubeGroupingSet, Exprs: $3.exprs()}
+  }
+| GROUPING SETS '(' group_by_list ')'
+  {
+    $$.val = &tree.GroupingSet{Type: tree.ListGroupingSet, Exprs: $4.exprs()}
+  }
 
 having_clause:
   HAVING a_expr
@@ -10082,7 +11032,10 @@ d_expr:
   {
     $$.val = $2.expr()
   }
-| GROUPING '(' expr_list ')' { return unimplemented(sqllex, "d_expr grouping") }
+| GROUPING '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingExpr{Exprs: $3.exprs()}
+  }
 
 func_application:
   func_name '(' ')'
@@ -11517,6 +12470,7 @@ unrestricted_name:
 // "Unreserved" keywords --- available for use as any kind of name.
 unreserved_keyword:
   ABORT
+| ABSOLUTE
 | ACTION
 | ACCESS
 | ADD
@@ -11530,6 +12484,7 @@ unreserved_keyword:
 | AUTOMATIC
 | BACKUP
 | BACKUPS
+| BACKWARD
 | BEFORE
 | BEGIN
 | BINARY
@@ -11537,6 +12492,7 @@ unreserved_keyword:
 | BUNDLE
 | BY
 | CACHE
+| CALLED
 | CANCEL
 | CANCELQUERY
 | CASCADE
@@ -11555,6 +12511,7 @@ unreserved_keyword:
 | CONFIGURATIONS
 | CONFIGURE
 | CONNECTION
+| CONNECTIONS
 | CONSTRAINTS
 | CONTROLCHANGEFEED
 | CONTROLJOB
@@ -11567,6 +12524,7 @@ unreserved_keyword:
 | CREATEROLE
 | CUBE
 | CURRENT
+| CURSOR
 | CYCLE
 | DATA
 | DATABASE
@@ -11583,6 +12541,7 @@ unreserved_keyword:
 | DOMAIN
 | DOUBLE
 | DROP
+| EACH
 | ENCODING
 | ENCRYPTION_PASSPHRASE
 | ENUM
@@ -11601,11 +12560,13 @@ unreserved_keyword:
 | EXPLAIN
 | EXPORT
 | EXTENSION
+| EXTERNAL
 | FILES
 | FILTER
 | FIRST
 | FOLLOWING
 | FORCE_INDEX
+| FORWARD
 | FUNCTION
 | GENERATED
 | GEOMETRYM
@@ -11621,9 +12582,11 @@ unreserved_keyword:
 | HASH
 | HIGH
 | HISTOGRAM
+| HOLD
 | HOUR
 | IDENTITY
 | IMMEDIATE
+| IMMUTABLE
 | IMPORT
 | INCLUDE
 | INCLUDING
@@ -11632,6 +12595,8 @@ unreserved_keyword:
 | INDEXES
 | INHERITS
 | INJECT
+| INPUT
+| INSENSITIVE
 | INSERT
 | INTERLEAVE
 | INTO_DB
@@ -11667,6 +12632,7 @@ unreserved_keyword:
 | MINUTE
 | MINVALUE
 | MODIFYCLUSTERSETTING
+| MOVE
 | MULTILINESTRING
 | MULTILINESTRINGM
 | MULTILINESTRINGZ
@@ -11683,6 +12649,7 @@ unreserved_keyword:
 | NAMES
 | NAN
 | NEVER
+| NEW_DB_NAME
 | NEXT
 | NO
 | NORMAL
@@ -11730,7 +12697,9 @@ unreserved_keyword:
 | PRECEDING
 | PREPARE
 | PRESERVE
+| PRIOR
 | PRIORITY
+| PROCEDURE
 | PUBLIC
 | PUBLICATION
 | QUERIES
@@ -11744,6 +12713,7 @@ unreserved_keyword:
 | REF
 | REFRESH
 | REINDEX
+| RELATIVE
 | RELEASE
 | RENAME
 | REPEATABLE
@@ -11753,6 +12723,7 @@ unreserved_keyword:
 | RESTRICT
 | RESUME
 | RETRY
+| RETURNS
 | REVISION_HISTORY
 | REVOKE
 | ROLE
@@ -11764,6 +12735,7 @@ unreserved_keyword:
 | RUNNING
 | SCHEDULE
 | SCHEDULES
+| SCROLL
 | SETTING
 | SETTINGS
 | STATUS
@@ -11793,6 +12765,7 @@ unreserved_keyword:
 | SNAPSHOT
 | SPLIT
 | SQL
+| STABLE
 | START
 | STATISTICS
 | STDIN
@@ -11836,8 +12809,10 @@ unreserved_keyword:
 | VALIDATE
 | VALUE
 | VARYING
+| VERIFY_BACKUP_TABLE_DATA
 | VIEW
 | VIEWACTIVITY
+| VOLATILE
 | WITHIN
 | WITHOUT
 | WRITE
@@ -11892,6 +12867,7 @@ col_name_keyword:
 | PRECISION
 | REAL
 | ROW
+| SETOF
 | SMALLINT
 | STRING
 | SUBSTRING
@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catconstants"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
@@ -216,6 +217,7 @@ var pgCatalog = virtualSchema{
 		catconstants.PgCatalogCollationTableID:           pgCatalogCollationTable,
 		catconstants.PgCatalogConstraintTableID:          pgCatalogConstraintTable,
 		catconstants.PgCatalogConversionTableID:          pgCatalogConversionTable,
+		catconstants.PgCatalogCursorsTableID:             pgCatalogCursorsTable,
 		catconstants.PgCatalogDatabaseTableID:            pgCatalogDatabaseTable,
 		catconstants.PgCatalogDefaultACLTableID:          pgCatalogDefaultACLTable,
 		catconstants.PgCatalogDependTableID:              pgCatalogDependTable,
@@ -893,6 +895,8 @@ func populateTableConstraints(
 		consrc := tree.DNull
 		conbin := tree.DNull
 		condef := tree.DNull
+		condeferrable := tree.DBoolFalse
+		condeferred := tree.DBoolFalse
 
 		// Determine constraint kind-specific fields.
 		var err error
@@ -933,6 +937,8 @@ func populateTableConstraints(
 			if r, ok := fkMatchMap[con.FK.Match]; ok {
 				confmatchtype = r
 			}
+			condeferrable = tree.MakeDBool(tree.DBool(con.FK.Deferrable))
+			condeferred = tree.MakeDBool(tree.DBool(con.FK.InitiallyDeferred))
 			if conkey, err = colIDArrayToDatum(con.FK.OriginColumnIDs); err != nil {
 				return err
 			}
@@ -958,10 +964,14 @@ func populateTableConstraints(
 			if conkey, err = colIDArrayToDatum(con.Index.ColumnIDs); err != nil {
 				return err
 			}
+			condeferrable = tree.MakeDBool(tree.DBool(con.Index.DeferrableUnique))
+			condeferred = tree.MakeDBool(tree.DBool(con.Index.InitiallyDeferred))
 			f := tree.NewFmtCtx(tree.FmtSimple)
 			f.WriteString("UNIQUE (")
 			con.Index.ColNamesFormat(f)
 			f.WriteByte(')')
+			deferrability := con.Index.UniqueDeferrability()
+			f.FormatNode(&deferrability)
 			if con.Index.IsPartial() {
 				pred, err := schemaexpr.FormatExprForDisplay(ctx, table, con.Index.Predicate, p.SemaCtx(), tree.FmtPGCatalog)
 				if err != nil {
@@ -991,8 +1001,8 @@ func populateTableConstraints(
 			dNameOrNull(conName), // conname
 			namespaceOid,         // connamespace
 			contype,              // contype
-			tree.DBoolFalse,      // condeferrable
-			tree.DBoolFalse,      // condeferred
+			condeferrable,        // condeferrable
+			condeferred,          // condeferred
 			tree.MakeDBool(tree.DBool(!con.Unvalidated)), // convalidated
 			tblOid,         // conrelid
 			oidZero,        // contypid
@@ -1218,6 +1228,43 @@ CREATE TABLE pg_catalog.pg_conversion (
 	},
 }
 
+// pgCatalogCursorsTable implements the pg_cursors table.
+var pgCatalogCursorsTable = virtualSchemaTable{
+	comment: `open cursors
+https://www.postgresql.org/docs/current/view-pg-cursors.html`,
+	schema: `
+CREATE TABLE pg_catalog.pg_cursors (
+	name TEXT,
+	statement TEXT,
+	is_holdable BOOL,
+	is_binary BOOL,
+	is_scrollable BOOL,
+	creation_time TIMESTAMPTZ
+)`,
+	populate: func(ctx context.Context, p *planner, dbContext *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		if p.sqlCursors == nil {
+			return nil
+		}
+		for _, c := range p.sqlCursors.list() {
+			ts, err := tree.MakeDTimestampTZ(c.created, time.Microsecond)
+			if err != nil {
+				return err
+			}
+			if err := addRow(
+				tree.NewDString(c.name),
+				tree.NewDString(c.statement),
+				tree.DBoolFalse, // is_holdable
+				tree.DBoolFalse, // is_binary
+				tree.DBoolFalse, // is_scrollable
+				ts,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 var pgCatalogDatabaseTable = virtualSchemaTable{
 	comment: `available databases (incomplete)
 https://www.postgresql.org/docs/9.5/catalog-pg-database.html`,
@@ -1693,13 +1740,12 @@ CREATE TABLE pg_catalog.pg_index (
 						if err := collationOids.Append(typColl(col.Type, h)); err != nil {
 							return err
 						}
-						// Currently, nulls always appear first if the order is ascending,
-						// and always appear last if the order is descending.
 						var thisIndOption tree.DInt
-						if index.ColumnDirections[i] == descpb.IndexDescriptor_ASC {
-							thisIndOption = indoptionNullsFirst
-						} else {
-							thisIndOption = indoptionDesc
+						if index.ColumnDirections[i] == descpb.IndexDescriptor_DESC {
+							thisIndOption |= indoptionDesc
+						}
+						if index.ColumnNullsOrder(i) == tree.NullsFirst {
+							thisIndOption |= indoptionNullsFirst
 						}
 						if err := indoption.Append(tree.NewDInt(thisIndOption)); err != nil {
 							return err
@@ -1713,11 +1759,12 @@ CREATE TABLE pg_catalog.pg_index (
 					if err != nil {
 						return err
 					}
+					isUnique := index.Unique || index.DeferrableUnique
 					return addRow(
 						h.IndexOid(table.GetID(), index.ID), // indexrelid
 						tableOid,                            // indrelid
 						tree.NewDInt(tree.DInt(len(index.ColumnNames))), // indnatts
-						tree.MakeDBool(tree.DBool(index.Unique)),        // indisunique
+						tree.MakeDBool(tree.DBool(isUnique)),            // indisunique
 						tree.MakeDBool(tree.DBool(isPrimary)),           // indisprimary
 						tree.DBoolFalse,                                 // indisexclusion
 						tree.MakeDBool(tree.DBool(index.Unique)),        // indimmediate
@@ -1791,7 +1838,7 @@ func indexDefFromDescriptor(
 	indexDef := tree.CreateIndex{
 		Name:     tree.Name(index.Name),
 		Table:    tree.MakeTableName(tree.Name(db.GetName()), tree.Name(table.GetName())),
-		Unique:   index.Unique,
+		Unique:   index.Unique || index.DeferrableUnique,
 		Columns:  make(tree.IndexElemList, len(index.ColumnNames)),
 		Storing:  make(tree.NameList, len(index.StoreColumnNames)),
 		Inverted: index.Type == descpb.IndexDescriptor_INVERTED,
@@ -1804,6 +1851,19 @@ func indexDefFromDescriptor(
 		if index.ColumnDirections[i] == descpb.IndexDescriptor_DESC {
 			elem.Direction = tree.Descending
 		}
+		if index.ColumnNullsReversedAt(i) {
+			elem.NullsOrder = index.ColumnNullsOrder(i)
+		}
+		if indexDef.Inverted {
+			// Inverted indexes on string columns are trigram indexes.
+			col, _, err := table.FindColumnByName(elem.Column)
+			if err != nil {
+				return "", err
+			}
+			if colinfo.ColumnTypeIsTrigramIndexable(col.Type) {
+				elem.OpClass = "gin_trgm_ops"
+			}
+		}
 		indexDef.Columns[i] = elem
 	}
 	for i, name := range index.StoreColumnNames {
@@ -2703,8 +2763,6 @@ var (
 	typTypeRange     = tree.NewDString("r")
 
 	// Avoid unused warning for constants.
-	_ = typTypeComposite
-	_ = typTypeDomain
 	_ = typTypePseudo
 	_ = typTypeRange
 
@@ -2726,7 +2784,6 @@ var (
 	typCategoryUnknown     = tree.NewDString("X")
 
 	// Avoid unused warning for constants.
-	_ = typCategoryComposite
 	_ = typCategoryEnum
 	_ = typCategoryGeometric
 	_ = typCategoryRange
@@ -2769,9 +2826,30 @@ func addPGTypeRow(
 		builtinPrefix = "enum_"
 		typType = typTypeEnum
 	}
+	if typ.IsComposite() {
+		builtinPrefix = "record_"
+		typType = typTypeComposite
+	}
 	if cat == typCategoryPseudo {
 		typType = typTypePseudo
 	}
+	typNotNull := tree.DBoolFalse
+	typBaseType := oidZero
+	typDefault := tree.DNull
+	if typ.IsDomain() {
+		typType = typTypeDomain
+		if data := typ.TypeMeta.DomainData; data != nil {
+			typBaseType = tree.NewDOid(tree.DInt(data.BaseType.Oid()))
+			for i := range data.Constraints {
+				if data.Constraints[i].NotNull {
+					typNotNull = tree.DBoolTrue
+				}
+			}
+			if data.DefaultExpr != nil {
+				typDefault = tree.NewDString(*data.DefaultExpr)
+			}
+		}
+	}
 	typname := typ.PGName()
 
 	return addRow(
@@ -2801,13 +2879,13 @@ func addPGTypeRow(
 
 		tree.DNull,      // typalign
 		tree.DNull,      // typstorage
-		tree.DBoolFalse, // typnotnull
-		oidZero,         // typbasetype
+		typNotNull,      // typnotnull
+		typBaseType,     // typbasetype
 		negOneVal,       // typtypmod
 		zeroVal,         // typndims
 		typColl(typ, h), // typcollation
 		tree.DNull,      // typdefaultbin
-		tree.DNull,      // typdefault
+		typDefault,      // typdefault
 		tree.DNull,      // typacl
 	)
 }
@@ -3121,6 +3199,11 @@ func typCategory(typ *types.T) tree.Datum {
 	if typ.Family() == types.ArrayFamily && typ.ArrayContents().Family() == types.AnyFamily {
 		return typCategoryPseudo
 	}
+	// Composite types are tuples, but unlike anonymous tuples they are not
+	// pseudo types.
+	if typ.IsComposite() {
+		return typCategoryComposite
+	}
 	return datumToTypeCategory[typ.Family()]
 }
 
@@ -18,6 +18,8 @@ import (
 	"io"
 	"io/ioutil"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strconv"
 	"strings"
@@ -45,6 +47,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/errors"
 	"github.com/jackc/pgproto3/v2"
 	"github.com/jackc/pgx"
@@ -1252,3 +1255,67 @@ func TestConnCloseCancelsAuth(t *testing.T) {
 	// Check that the auth process indeed noticed the cancelation.
 	<-authBlocked
 }
+
+// Test that the trace context provided by the client through the traceparent
+// option is used for the transactions of the session.
+func TestConnTraceParent(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	// The client's trace ID, as encoded by the Jaeger exporter.
+	const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	traceIDHigh := []byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6}
+	var mu syncutil.Mutex
+	var found bool
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		found = found || bytes.Contains(body, traceIDHigh)
+	}))
+	defer collector.Close()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING trace.opentelemetry.protocol = 'jaeger'`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING trace.opentelemetry.collector = $1`,
+		collector.Listener.Addr().String())
+	defer sqlDB.Exec(t, `RESET CLUSTER SETTING trace.opentelemetry.collector`)
+
+	pgURL, cleanup := sqlutils.PGUrl(t, s.ServingSQLAddr(), t.Name(), url.User(security.RootUser))
+	defer cleanup()
+	q := pgURL.Query()
+
+	// Invalid trace contexts are ignored.
+	q.Add(`traceparent`, `foo`)
+	pgURL.RawQuery = q.Encode()
+	{
+		invalidDB, err := gosql.Open("postgres", pgURL.String())
+		require.NoError(t, err)
+		defer invalidDB.Close()
+		_, err = invalidDB.Exec(`SELECT 1`)
+		require.NoError(t, err)
+	}
+
+	q.Set(`traceparent`, traceParent)
+	pgURL.RawQuery = q.Encode()
+	traceDB, err := gosql.Open("postgres", pgURL.String())
+	require.NoError(t, err)
+	defer traceDB.Close()
+	testutils.SucceedsSoon(t, func() error {
+		if _, err := traceDB.Exec(`SELECT 1`); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if !found {
+			return errors.New("client trace not exported yet")
+		}
+		return nil
+	})
+}
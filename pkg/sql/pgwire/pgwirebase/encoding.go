@@ -803,6 +803,12 @@ func DecodeOidDatum(
 				return nil, err
 			}
 			return tree.MakeDEnumFromLogicalRepresentation(typ, string(b))
+		case types.TupleFamily:
+			if code != FormatBinary {
+				return nil, unimplemented.NewWithIssueDetail(27792, "record text input",
+					"text encoding of composite type values is not supported")
+			}
+			return decodeBinaryComposite(ctx, pCtx, typ, b, res)
 		default:
 			return nil, errors.AssertionFailedf("unsupported user defined type family %s", typ.Family().String())
 		}
@@ -970,6 +976,53 @@ func decodeBinaryArray(
 	return arr, nil
 }
 
+// decodeBinaryComposite decodes the binary record encoding of a value of
+// the composite type typ. Each field carries its own type OID, which must
+// match the type of the corresponding field of typ.
+func decodeBinaryComposite(
+	ctx context.Context,
+	pCtx tree.ParseTimeContext,
+	typ *types.T,
+	b []byte,
+	res tree.TypeReferenceResolver,
+) (tree.Datum, error) {
+	r := bytes.NewBuffer(b)
+	var numFields int32
+	if err := binary.Read(r, binary.BigEndian, &numFields); err != nil {
+		return nil, err
+	}
+	contents := typ.TupleContents()
+	if int(numFields) != len(contents) {
+		return nil, pgerror.Newf(pgcode.DatatypeMismatch,
+			"wrong number of columns: %d, expected %d", numFields, len(contents))
+	}
+	datums := make(tree.Datums, len(contents))
+	var field struct {
+		Oid int32
+		Len int32
+	}
+	for i := range contents {
+		if err := binary.Read(r, binary.BigEndian, &field); err != nil {
+			return nil, err
+		}
+		if oid.Oid(field.Oid) != contents[i].Oid() {
+			return nil, pgerror.Newf(pgcode.DatatypeMismatch,
+				"binary data has type %d instead of expected %d in record column %d",
+				field.Oid, contents[i].Oid(), i+1)
+		}
+		if field.Len < 0 {
+			datums[i] = tree.DNull
+			continue
+		}
+		elem, err := DecodeOidDatum(ctx, pCtx, contents[i].Oid(), FormatBinary, r.Next(int(field.Len)), res)
+		if err != nil {
+			return nil, err
+		}
+		datums[i] = elem
+	}
+	return tree.NewDTuple(typ, datums...), nil
+}
+
 var invalidUTF8Error = pgerror.Newf(pgcode.CharacterNotInRepertoire, "invalid UTF-8 sequence")
 
 var (
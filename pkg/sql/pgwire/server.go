@@ -40,6 +40,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/logtags"
 	"github.com/cockroachdb/redact"
@@ -655,6 +656,15 @@ func parseClientProvidedSessionParameters(
 			}
 			foundBufferSize = true
 
+		case "traceparent":
+			// Invalid trace contexts are ignored, as mandated by the W3C Trace
+			// Context specification.
+			if err := tracing.ValidateTraceParent(value); err != nil {
+				log.Warningf(ctx, "ignoring traceparent option: %v", err)
+				continue
+			}
+			args.TraceParent = value
+
 		default:
 			exists, configurable := sql.IsSessionVariableConfigurable(key)
 
@@ -55,6 +55,7 @@ type pgType struct {
 }
 
 func pgTypeForParserType(t *types.T) pgType {
+	t = domainBaseType(t)
 	size := -1
 	if s, variable := tree.DatumTypeSize(t); !variable {
 		size = int(s)
@@ -65,6 +66,16 @@ func pgTypeForParserType(t *types.T) pgType {
 	}
 }
 
+// domainBaseType returns the base type of t if t is a DOMAIN, and t otherwise.
+// Like in Postgres, the values of a domain are sent to clients as values of
+// its base type.
+func domainBaseType(t *types.T) *types.T {
+	if t != nil && t.IsDomain() && t.TypeMeta.DomainData != nil {
+		return t.TypeMeta.DomainData.BaseType
+	}
+	return t
+}
+
 // resolveBlankPaddedChar pads the given string with spaces if blank padding is
 // required or returns the string unmodified otherwise.
 func resolveBlankPaddedChar(s string, t *types.T) string {
@@ -85,6 +96,7 @@ func (b *writeBuffer) writeTextDatum(
 	if log.V(2) {
 		log.Infof(ctx, "pgwire writing TEXT datum of type: %T, %#v", d, d)
 	}
+	t = domainBaseType(t)
 	if d == tree.DNull {
 		// NULL is encoded as -1; all other values have a length prefix.
 		b.putInt32(-1)
@@ -216,6 +228,7 @@ func (b *writeBuffer) writeBinaryDatum(
 	if log.V(2) {
 		log.Infof(ctx, "pgwire writing BINARY datum of type: %T, %#v", d, d)
 	}
+	t = domainBaseType(t)
 	if d == tree.DNull {
 		// NULL is encoded as -1; all other values have a length prefix.
 		b.putInt32(-1)
@@ -455,10 +468,16 @@ func (b *writeBuffer) writeBinaryDatum(
 		subWriter := newWriteBuffer(nil /* bytecount */)
 		// Put the number of datums.
 		subWriter.putInt32(int32(len(v.D)))
-		for _, elem := range v.D {
-			oid := elem.ResolvedType().Oid()
-			subWriter.putInt32(int32(oid))
-			subWriter.writeBinaryDatum(ctx, elem, sessionLoc, elem.ResolvedType())
+		for i, elem := range v.D {
+			// The fields of a composite type have declared types, which are
+			// reported even for NULL fields and determine the width of the
+			// encoding.
+			elemTyp := elem.ResolvedType()
+			if t != nil && t.IsComposite() {
+				elemTyp = t.TupleContents()[i]
+			}
+			subWriter.putInt32(int32(elemTyp.Oid()))
+			subWriter.writeBinaryDatum(ctx, elem, sessionLoc, elemTyp)
 		}
 		b.writeLengthPrefixedBuffer(&subWriter.wrapped)
 
@@ -491,6 +491,7 @@ func (p *PhysicalPlan) AddProjection(columns []uint32) {
 			}
 			newOrdering[i].ColIdx = uint32(found)
 			newOrdering[i].Direction = c.Direction
+			newOrdering[i].NullsReversed = c.NullsReversed
 		}
 		p.MergeOrdering.Columns = newOrdering
 	}
@@ -645,6 +646,7 @@ func (p *PhysicalPlan) AddRendering(
 			}
 			newOrdering[i].ColIdx = uint32(found)
 			newOrdering[i].Direction = c.Direction
+			newOrdering[i].NullsReversed = c.NullsReversed
 		}
 		p.MergeOrdering.Columns = newOrdering
 	}
@@ -58,20 +58,23 @@ func (p *planner) prepareUsingOptimizer(ctx context.Context) (planFlags, error)
 	case *tree.AlterIndex, *tree.AlterTable, *tree.AlterSequence,
 		*tree.Analyze,
 		*tree.BeginTransaction,
+		*tree.CloseCursor,
 		*tree.CommentOnColumn, *tree.CommentOnDatabase, *tree.CommentOnIndex, *tree.CommentOnTable,
 		*tree.CommitTransaction,
 		*tree.CopyFrom, *tree.CreateDatabase, *tree.CreateIndex, *tree.CreateView,
 		*tree.CreateSequence,
 		*tree.CreateStats,
-		*tree.Deallocate, *tree.Discard, *tree.DropDatabase, *tree.DropIndex,
+		*tree.Deallocate, *tree.DeclareCursor, *tree.Discard, *tree.DropDatabase, *tree.DropIndex,
 		*tree.DropTable, *tree.DropView, *tree.DropSequence,
 		*tree.Execute,
 		*tree.Grant, *tree.GrantRole,
+		*tree.MoveCursor,
 		*tree.Prepare,
 		*tree.ReleaseSavepoint, *tree.RenameColumn, *tree.RenameDatabase,
 		*tree.RenameIndex, *tree.RenameTable, *tree.Revoke, *tree.RevokeRole,
 		*tree.RollbackToSavepoint, *tree.RollbackTransaction,
-		*tree.Savepoint, *tree.SetTransaction, *tree.SetTracing, *tree.SetSessionAuthorizationDefault,
+		*tree.Savepoint, *tree.SetConstraints, *tree.SetTransaction, *tree.SetTracing,
+		*tree.SetSessionAuthorizationDefault,
 		*tree.SetSessionCharacteristics:
 		// These statements do not have result columns and do not support placeholders
 		// so there is no need to do anything during prepare.
@@ -152,6 +152,14 @@ type planner struct {
 
 	preparedStatements preparedStatementsAccessor
 
+	// sqlCursors contains the cursors declared in the current transaction.
+	sqlCursors *cursorMap
+
+	// deferredConstraints keeps track of the deferred constraints of the
+	// current transaction. It is nil for internal executors, which always
+	// check constraints immediately.
+	deferredConstraints *deferredConstraints
+
 	// avoidCachedDescriptors, when true, instructs all code that
 	// accesses table/view descriptors to force reading the descriptors
 	// within the transaction. This is necessary to read descriptors
@@ -115,6 +115,9 @@ func (p *planner) renameColumn(
 			)
 		}
 	}
+	if err := p.canChangeColumnWithFunctions(ctx, tableDesc, col, "rename"); err != nil {
+		return false, err
+	}
 	if *oldName == *newName {
 		// Noop.
 		return false, nil
@@ -81,6 +81,10 @@ func (p *planner) RenameTable(ctx context.Context, n *tree.RenameTable) (planNod
 			tableDesc.ParentID, tableDesc.DependedOnBy[0].ID, "rename",
 		)
 	}
+	// The same applies to functions.
+	if err := p.canDropRelationWithFunctions(ctx, tableDesc, "rename", tree.DropRestrict); err != nil {
+		return nil, err
+	}
 
 	return &renameTableNode{n: n, oldTn: &oldTn, newTn: &newTn, tableDesc: tableDesc}, nil
 }
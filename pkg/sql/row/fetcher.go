@@ -1480,6 +1480,11 @@ func (rf *Fetcher) checkKeyOrdering(ctx context.Context) error {
 	for i, id := range rf.rowReadyTable.index.ColumnIDs {
 		idx := rf.rowReadyTable.colIdxMap[id]
 		result := rf.rowReadyTable.decodedRow[idx].Compare(&evalCtx, rf.rowReadyTable.lastDatums[idx])
+		if rf.rowReadyTable.index.ColumnNullsReversedAt(i) &&
+			(rf.rowReadyTable.decodedRow[idx] == tree.DNull) != (rf.rowReadyTable.lastDatums[idx] == tree.DNull) {
+			// NULLs sort on the other side of the non-NULL values in this column.
+			result = -result
+		}
 		expectedDirection := rf.rowReadyTable.index.ColumnDirections[i]
 		if rf.reverse && expectedDirection == descpb.IndexDescriptor_ASC {
 			expectedDirection = descpb.IndexDescriptor_DESC
@@ -1589,6 +1594,17 @@ func (rf *Fetcher) GetBytesRead() int64 {
 	return f.bytesRead
 }
 
+// GetKVTime returns the time spent waiting for KV batches by the underlying
+// KVFetcher.
+func (rf *Fetcher) GetKVTime() time.Duration {
+	f := rf.kvFetcher
+	if f == nil {
+		// Not yet initialized.
+		return 0
+	}
+	return f.kvTime
+}
+
 // Only unique secondary indexes have extra columns to decode (namely the
 // primary index columns).
 func hasExtraCols(table *tableInfo) bool {
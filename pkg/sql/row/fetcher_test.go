@@ -16,6 +16,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/keys"
@@ -1164,4 +1165,47 @@ func TestFetcherUninitialized(t *testing.T) {
 	// before the fetcher was fully initialized.
 	var fetcher Fetcher
 	assert.Zero(t, fetcher.GetBytesRead())
+	assert.Zero(t, fetcher.GetKVTime())
+}
+
+// slowKVFetcher is a kvBatchFetcher that returns one KV per batch after a
+// delay.
+type slowKVFetcher struct {
+	kvs   []roachpb.KeyValue
+	delay time.Duration
+}
+
+func (f *slowKVFetcher) nextBatch(
+	context.Context,
+) (ok bool, kvs []roachpb.KeyValue, batchResponse []byte, span roachpb.Span, err error) {
+	if len(f.kvs) == 0 {
+		return false, nil, nil, roachpb.Span{}, nil
+	}
+	time.Sleep(f.delay)
+	kvs, f.kvs = f.kvs[:1], f.kvs[1:]
+	return true, kvs, nil, roachpb.Span{}, nil
+}
+
+func (f *slowKVFetcher) close(context.Context) {}
+
+func TestKVFetcherKVTime(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const delay = 10 * time.Millisecond
+	f := newKVFetcher(&slowKVFetcher{
+		kvs:   []roachpb.KeyValue{{Key: roachpb.Key("a")}, {Key: roachpb.Key("b")}},
+		delay: delay,
+	})
+	for {
+		ok, _, _, err := f.NextKV(context.Background(), MVCCDecodingNotRequired)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+	}
+	if kvTime := f.GetKVTime(); kvTime < 2*delay {
+		t.Fatalf("expected at least %s of KV time, got %s", 2*delay, kvTime)
+	}
 }
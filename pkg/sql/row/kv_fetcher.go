@@ -12,6 +12,7 @@ package row
 
 import (
 	"context"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -19,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 // KVFetcher wraps kvBatchFetcher, providing a NextKV interface that returns the
@@ -30,6 +32,7 @@ type KVFetcher struct {
 
 	batchResponse []byte
 	bytesRead     int64
+	kvTime        time.Duration
 	Span          roachpb.Span
 	newSpan       bool
 }
@@ -64,6 +67,11 @@ func (f *KVFetcher) GetBytesRead() int64 {
 	return f.bytesRead
 }
 
+// GetKVTime returns the time spent waiting for the KV batches of this fetcher.
+func (f *KVFetcher) GetKVTime() time.Duration {
+	return f.kvTime
+}
+
 // MVCCDecodingStrategy controls if and how the fetcher should decode MVCC
 // timestamps from returned KV's.
 type MVCCDecodingStrategy int
@@ -112,7 +120,9 @@ func (f *KVFetcher) NextKV(
 			}, newSpan, nil
 		}
 
+		start := timeutil.Now()
 		ok, f.kvs, f.batchResponse, f.Span, err = f.nextBatch(ctx)
+		f.kvTime += timeutil.Since(start)
 		if err != nil {
 			return ok, kv, false, err
 		}
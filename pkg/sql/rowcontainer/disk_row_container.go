@@ -281,6 +281,12 @@ func (d *DiskRowContainer) encodeRow(ctx context.Context, row rowenc.EncDatumRow
 
 	for i, orderInfo := range d.ordering {
 		col := orderInfo.ColIdx
+		if orderInfo.NullsReversed && row[col].IsNull() {
+			// Encode the NULL so that it sorts on the other side of all other
+			// values. It is decoded like any other NULL in keyValToRow.
+			d.scratchKey = encoding.EncodeReversedNull(d.scratchKey, orderInfo.Direction)
+			continue
+		}
 		var err error
 		d.scratchKey, err = row[col].Encode(d.types[col], d.datumAlloc, d.encodings[i], d.scratchKey)
 		if err != nil {
@@ -187,6 +187,22 @@ func EncodeTableKey(b []byte, val tree.Datum, dir encoding.Direction) ([]byte, e
 	return nil, errors.Errorf("unable to encode table key: %T", val)
 }
 
+// EncodeTableKeyWithNullsOrder is like EncodeTableKey, except that if
+// nullsReversed is true, a NULL value is encoded so that it sorts after all
+// other values in an ascending column (NULLS LAST) and before all other values
+// in a descending column (NULLS FIRST).
+func EncodeTableKeyWithNullsOrder(
+	b []byte, val tree.Datum, dir encoding.Direction, nullsReversed bool,
+) ([]byte, error) {
+	if nullsReversed && val == tree.DNull {
+		if (dir != encoding.Ascending) && (dir != encoding.Descending) {
+			return nil, errors.Errorf("invalid direction: %d", dir)
+		}
+		return encoding.EncodeReversedNull(b, dir), nil
+	}
+	return EncodeTableKey(b, val, dir)
+}
+
 // SkipTableKey skips a value of type valType in key, returning the remainder
 // of the key.
 func SkipTableKey(key []byte) ([]byte, error) {
@@ -823,6 +839,15 @@ func MarshalColumnValue(col *descpb.ColumnDescriptor, val tree.Datum) (roachpb.V
 			r.SetBytes(v.PhysicalRep)
 			return r, nil
 		}
+	case types.TupleFamily:
+		if v, ok := val.(*tree.DTuple); ok && col.Type.IsComposite() {
+			b, err := encodeUntaggedTuple(v, nil /* appendTo */, nil /* scratch */)
+			if err != nil {
+				return r, err
+			}
+			r.SetBytes(b)
+			return r, nil
+		}
 	default:
 		return r, errors.AssertionFailedf("unsupported column type: %s", col.Type.Family())
 	}
@@ -1002,6 +1027,13 @@ func UnmarshalColumnValue(a *DatumAlloc, typ *types.T, value roachpb.Value) (tre
 			return nil, err
 		}
 		return a.NewDEnum(tree.DEnum{EnumTyp: typ, PhysicalRep: phys, LogicalRep: log}), nil
+	case types.TupleFamily:
+		v, err := value.GetBytes()
+		if err != nil {
+			return nil, err
+		}
+		datum, _, err := decodeTuple(a, typ, v)
+		return datum, err
 	default:
 		return nil, errors.Errorf("unsupported column type: %s", typ.Family())
 	}
@@ -1010,6 +1042,13 @@ func UnmarshalColumnValue(a *DatumAlloc, typ *types.T, value roachpb.Value) (tre
 // encodeTuple produces the value encoding for a tuple.
 func encodeTuple(t *tree.DTuple, appendTo []byte, colID uint32, scratch []byte) ([]byte, error) {
 	appendTo = encoding.EncodeValueTag(appendTo, colID, encoding.Tuple)
+	return encodeUntaggedTuple(t, appendTo, scratch)
+}
+
+// encodeUntaggedTuple produces the value encoding for a tuple without a value
+// tag. It is used for the elements of arrays of composite types, and for
+// composite values stored alone in a column family.
+func encodeUntaggedTuple(t *tree.DTuple, appendTo []byte, scratch []byte) ([]byte, error) {
 	appendTo = encoding.EncodeNonsortingUvarint(appendTo, uint64(len(t.D)))
 
 	var err error
@@ -1030,9 +1069,9 @@ func decodeTuple(a *DatumAlloc, tupTyp *types.T, b []byte) (tree.Datum, []byte,
 		return nil, nil, err
 	}
 
-	result := tree.DTuple{
-		D: a.NewDatums(len(tupTyp.TupleContents())),
-	}
+	// The type of the result is set so that the values of composite types
+	// have the composite type, rather than an anonymous tuple type.
+	result := tree.NewDTuple(tupTyp, a.NewDatums(len(tupTyp.TupleContents()))...)
 
 	var datum tree.Datum
 	for i := range tupTyp.TupleContents() {
@@ -1042,7 +1081,7 @@ func decodeTuple(a *DatumAlloc, tupTyp *types.T, b []byte) (tree.Datum, []byte,
 		}
 		result.D[i] = datum
 	}
-	return a.NewDTuple(result), b, nil
+	return result, b, nil
 }
 
 // encodeArrayKey generates an ordered key encoding of an array.
@@ -1326,6 +1365,11 @@ func datumTypeToArrayElementEncodingType(t *types.T) (encoding.Type, error) {
 		return encoding.UUID, nil
 	case types.INetFamily:
 		return encoding.IPAddr, nil
+	case types.TupleFamily:
+		if t.IsComposite() {
+			return encoding.Tuple, nil
+		}
+		return 0, errors.AssertionFailedf("no known encoding type for %s", t)
 	default:
 		return 0, errors.AssertionFailedf("no known encoding type for %s", t)
 	}
@@ -1397,6 +1441,8 @@ func encodeArrayElement(b []byte, d tree.Datum) ([]byte, error) {
 		return encodeArrayElement(b, t.Wrapped)
 	case *tree.DEnum:
 		return encoding.EncodeUntaggedBytesValue(b, t.PhysicalRep), nil
+	case *tree.DTuple:
+		return encodeUntaggedTuple(t, b, nil /* scratch */)
 	default:
 		return nil, errors.Errorf("don't know how to encode %s (%T)", d, d)
 	}
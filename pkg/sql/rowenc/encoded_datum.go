@@ -136,6 +136,17 @@ func EncDatumFromBuffer(
 		if err != nil {
 			return EncDatum{}, nil, err
 		}
+		dir := encoding.Ascending
+		if enc == descpb.DatumEncoding_DESCENDING_KEY {
+			dir = encoding.Descending
+		}
+		if encoding.IsReversedNull(buf, dir) {
+			// Index columns with a reversed NULL ordering encode NULLs like the
+			// opposite direction does. Don't keep that encoding around, so that
+			// EncDatums with the same encoding can be compared and hashed by their
+			// bytes.
+			return EncDatum{Datum: tree.DNull}, buf[encLen:], nil
+		}
 		ed := EncDatumFromEncoded(enc, buf[:encLen])
 		return ed, buf[encLen:], nil
 	case descpb.DatumEncoding_VALUE:
@@ -480,6 +491,9 @@ func (r EncDatumRow) Compare(
 			return 0, err
 		}
 		if cmp != 0 {
+			if c.NullsReversed && r[c.ColIdx].IsNull() != rhs[c.ColIdx].IsNull() {
+				cmp = -cmp
+			}
 			if c.Direction == encoding.Descending {
 				cmp = -cmp
 			}
@@ -503,6 +517,9 @@ func (r EncDatumRow) CompareToDatums(
 		}
 		cmp := r[c.ColIdx].Datum.Compare(evalCtx, rhs[c.ColIdx])
 		if cmp != 0 {
+			if c.NullsReversed && (r[c.ColIdx].Datum == tree.DNull) != (rhs[c.ColIdx] == tree.DNull) {
+				cmp = -cmp
+			}
 			if c.Direction == encoding.Descending {
 				cmp = -cmp
 			}
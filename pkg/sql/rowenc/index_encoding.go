@@ -28,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/trigram"
 	"github.com/cockroachdb/cockroach/pkg/util/unique"
 	"github.com/cockroachdb/errors"
 )
@@ -137,6 +138,7 @@ func EncodePartialIndexKey(
 	copy(key, keyPrefix)
 
 	dirs := directions(index.ColumnDirections)
+	nulls := nullsReversed(index.ColumnNullsReversed)
 
 	if len(index.Interleave.Ancestors) > 0 {
 		for i, ancestor := range index.Interleave.Ancestors {
@@ -152,7 +154,7 @@ func EncodePartialIndexKey(
 				partial = true
 			}
 			var n bool
-			key, n, err = EncodeColumns(colIDs[:length], dirs[:length], colMap, values, key)
+			key, n, err = EncodeColumns(colIDs[:length], dirs[:length], nulls, colMap, values, key)
 			if err != nil {
 				return nil, false, err
 			}
@@ -163,7 +165,7 @@ func EncodePartialIndexKey(
 				// that results in a more specific key.
 				return key, containsNull, nil
 			}
-			colIDs, dirs = colIDs[length:], dirs[length:]
+			colIDs, dirs, nulls = colIDs[length:], dirs[length:], nulls.skip(length)
 			// Each ancestor is separated by an interleaved
 			// sentinel (0xfe).
 			key = encoding.EncodeInterleavedSentinel(key)
@@ -173,13 +175,15 @@ func EncodePartialIndexKey(
 	}
 
 	var n bool
-	key, n, err = EncodeColumns(colIDs, dirs, colMap, values, key)
+	key, n, err = EncodeColumns(colIDs, dirs, nulls, colMap, values, key)
 	if err != nil {
 		return nil, false, err
 	}
 	containsNull = containsNull || n
 
-	key, n, err = EncodeColumns(extraColIDs, nil /* directions */, colMap, values, key)
+	key, n, err = EncodeColumns(
+		extraColIDs, nil /* directions */, nil /* nullsReversed */, colMap, values, key,
+	)
 	if err != nil {
 		return nil, false, err
 	}
@@ -196,6 +200,23 @@ func (d directions) get(i int) (encoding.Direction, error) {
 	return encoding.Ascending, nil
 }
 
+// nullsReversed indicates, for each index key column, whether NULLs are
+// encoded in the opposite order of the default for the column's direction.
+// Columns past the end of the slice use the default NULL ordering.
+type nullsReversed []bool
+
+func (n nullsReversed) get(i int) bool {
+	return i < len(n) && n[i]
+}
+
+// skip returns the NULL orderings of the columns after the first i.
+func (n nullsReversed) skip(i int) nullsReversed {
+	if i >= len(n) {
+		return nil
+	}
+	return n[i:]
+}
+
 // MakeSpanFromEncDatums creates a minimal index key span on the input
 // values. A minimal index key span is a span that includes the fewest possible
 // keys after the start key generated by the input values.
@@ -431,6 +452,7 @@ func makeKeyFromEncDatums(
 	// so make it bigger from the get-go.
 	key := make(roachpb.Key, len(keyPrefix), len(keyPrefix)*2)
 	copy(key, keyPrefix)
+	nulls := nullsReversed(index.ColumnNullsReversed)
 
 	if len(index.Interleave.Ancestors) > 0 {
 		for i, ancestor := range index.Interleave.Ancestors {
@@ -449,7 +471,9 @@ func makeKeyFromEncDatums(
 				err error
 				n   bool
 			)
-			key, n, err = appendEncDatumsToKey(key, types[:length], values[:length], dirs[:length], alloc)
+			key, n, err = appendEncDatumsToKey(
+				key, types[:length], values[:length], dirs[:length], nulls, alloc,
+			)
 			if err != nil {
 				return nil, false, false, err
 			}
@@ -460,6 +484,7 @@ func makeKeyFromEncDatums(
 				return key, false, false, nil
 			}
 			types, values, dirs = types[length:], values[length:], dirs[length:]
+			nulls = nulls.skip(length)
 
 			// Each ancestor is separated by an interleaved
 			// sentinel (0xfe).
@@ -472,7 +497,7 @@ func makeKeyFromEncDatums(
 		err error
 		n   bool
 	)
-	key, n, err = appendEncDatumsToKey(key, types, values, dirs, alloc)
+	key, n, err = appendEncDatumsToKey(key, types, values, dirs, nulls, alloc)
 	if err != nil {
 		return key, false, false, err
 	}
@@ -500,18 +525,25 @@ func appendEncDatumsToKey(
 	types []*types.T,
 	values EncDatumRow,
 	dirs []descpb.IndexDescriptor_Direction,
+	nulls nullsReversed,
 	alloc *DatumAlloc,
 ) (_ roachpb.Key, containsNull bool, _ error) {
 	for i, val := range values {
-		encoding := descpb.DatumEncoding_ASCENDING_KEY
+		enc := descpb.DatumEncoding_ASCENDING_KEY
+		dir := encoding.Ascending
 		if dirs[i] == descpb.IndexDescriptor_DESC {
-			encoding = descpb.DatumEncoding_DESCENDING_KEY
+			enc = descpb.DatumEncoding_DESCENDING_KEY
+			dir = encoding.Descending
 		}
 		if val.IsNull() {
 			containsNull = true
+			if nulls.get(i) {
+				key = encoding.EncodeReversedNull(key, dir)
+				continue
+			}
 		}
 		var err error
-		key, err = val.Encode(types[i], alloc, encoding, key)
+		key, err = val.Encode(types[i], alloc, enc, key)
 		if err != nil {
 			return nil, false, err
 		}
@@ -776,8 +808,9 @@ func EncodeInvertedIndexKeys(
 }
 
 // EncodeInvertedIndexTableKeys produces one inverted index key per element in
-// the input datum, which should be a container (either JSON or Array). For
-// JSON, "element" means unique path through the document. Each output key is
+// the input datum, which should be a container (either JSON or Array) or a
+// string. For JSON, "element" means unique path through the document, and for
+// strings it means distinct trigram (see the trigram package). Each output key is
 // prefixed by inKey, and is guaranteed to be lexicographically sortable, but
 // not guaranteed to be round-trippable during decoding. If the input Datum
 // is (SQL) NULL, no inverted index keys will be produced, because inverted
@@ -792,10 +825,32 @@ func EncodeInvertedIndexTableKeys(val tree.Datum, inKey []byte) (key [][]byte, e
 		return json.EncodeInvertedIndexKeys(inKey, val.(*tree.DJSON).JSON)
 	case types.ArrayFamily:
 		return encodeArrayInvertedIndexTableKeys(val.(*tree.DArray), inKey)
+	case types.StringFamily:
+		return encodeTrigramInvertedIndexTableKeys(string(tree.MustBeDString(datum)), inKey), nil
 	}
 	return nil, errors.AssertionFailedf("trying to apply inverted index to unsupported type %s", datum.ResolvedType())
 }
 
+// encodeTrigramInvertedIndexTableKeys returns a list of inverted index keys
+// for the given input string, one per distinct trigram of the string. The
+// input inKey is prefixed to all returned keys.
+func encodeTrigramInvertedIndexTableKeys(val string, inKey []byte) [][]byte {
+	trigrams := trigram.MakeTrigrams(val)
+	outKeys := make([][]byte, len(trigrams))
+	for i := range trigrams {
+		outKey := make([]byte, len(inKey), len(inKey)+len(trigrams[i])+2)
+		copy(outKey, inKey)
+		outKeys[i] = EncodeTrigramInvertedIndexKey(outKey, trigrams[i])
+	}
+	return outKeys
+}
+
+// EncodeTrigramInvertedIndexKey appends the encoding of the given trigram to
+// b, in the form used for the keys of trigram inverted indexes.
+func EncodeTrigramInvertedIndexKey(b []byte, trigram string) []byte {
+	return encoding.EncodeStringAscending(b, trigram)
+}
+
 // encodeArrayInvertedIndexTableKeys returns a list of inverted index keys for
 // the given input array, one per entry in the array. The input inKey is
 // prefixed to all returned keys.
@@ -998,7 +1053,7 @@ func EncodeSecondaryIndex(
 
 	// Add the extra columns - they are encoded in ascending order which is done
 	// by passing nil for the encoding directions.
-	extraKey, _, err := EncodeColumns(secondaryIndex.ExtraColumnIDs, nil,
+	extraKey, _, err := EncodeColumns(secondaryIndex.ExtraColumnIDs, nil, nil,
 		colMap, values, nil)
 	if err != nil {
 		return []IndexEntry{}, err
@@ -1662,12 +1717,13 @@ func AdjustEndKeyForInterleave(
 	return end[:firstNTokenLen+1], nil
 }
 
-// EncodeColumns is a version of EncodePartialIndexKey that takes ColumnIDs and
-// directions explicitly. WARNING: unlike EncodePartialIndexKey, EncodeColumns
-// appends directly to keyPrefix.
+// EncodeColumns is a version of EncodePartialIndexKey that takes ColumnIDs,
+// directions and NULL orderings explicitly. WARNING: unlike
+// EncodePartialIndexKey, EncodeColumns appends directly to keyPrefix.
 func EncodeColumns(
 	columnIDs []descpb.ColumnID,
 	directions directions,
+	nullsReversed nullsReversed,
 	colMap map[descpb.ColumnID]int,
 	values []tree.Datum,
 	keyPrefix []byte,
@@ -1684,7 +1740,8 @@ func EncodeColumns(
 			return nil, containsNull, err
 		}
 
-		if key, err = EncodeTableKey(key, val, dir); err != nil {
+		key, err = EncodeTableKeyWithNullsOrder(key, val, dir, nullsReversed.get(colIdx))
+		if err != nil {
 			return nil, containsNull, err
 		}
 	}
@@ -1232,6 +1232,10 @@ func ColumnFamilyMutator(rng *rand.Rand, stmt tree.Statement) (changed bool) {
 			if def.HasColumnFamily() {
 				return false
 			}
+			// Virtual columns cannot be part of a column family.
+			if def.IsVirtual() {
+				continue
+			}
 			columns = append(columns, def.Name)
 		}
 	}
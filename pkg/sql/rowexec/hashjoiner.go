@@ -187,7 +187,7 @@ func newHashJoiner(
 			limit = 1
 		}
 		h.MemMonitor = execinfra.NewLimitedMonitor(ctx, flowCtx.EvalCtx.Mon, flowCtx.Cfg, "hashjoiner-limited")
-		h.diskMonitor = execinfra.NewMonitor(ctx, flowCtx.Cfg.DiskMonitor, "hashjoiner-disk")
+		h.diskMonitor = execinfra.NewMonitor(ctx, flowCtx.ParentDiskMonitor(), "hashjoiner-disk")
 		// Override initialBufferSize to be half of this processor's memory
 		// limit. We consume up to h.initialBufferSize bytes from each input
 		// stream.
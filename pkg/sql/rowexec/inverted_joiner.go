@@ -300,7 +300,7 @@ func newInvertedJoiner(
 	// Initialize memory monitors and row container for key rows.
 	ctx := flowCtx.EvalCtx.Ctx()
 	ij.MemMonitor = execinfra.NewLimitedMonitor(ctx, flowCtx.EvalCtx.Mon, flowCtx.Cfg, "invertedjoiner-limited")
-	ij.diskMonitor = execinfra.NewMonitor(ctx, flowCtx.Cfg.DiskMonitor, "invertedjoiner-disk")
+	ij.diskMonitor = execinfra.NewMonitor(ctx, flowCtx.ParentDiskMonitor(), "invertedjoiner-disk")
 	ij.keyRows = rowcontainer.NewDiskBackedNumberedRowContainer(
 		true, /* deDup */
 		ij.keyTypes,
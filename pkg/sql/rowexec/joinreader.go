@@ -348,7 +348,7 @@ func (jr *joinReader) initJoinReaderStrategy(
 	}
 	// Initialize memory monitors and row container for looked up rows.
 	jr.MemMonitor = execinfra.NewLimitedMonitor(ctx, flowCtx.EvalCtx.Mon, flowCtx.Cfg, "joinreader-limited")
-	jr.diskMonitor = execinfra.NewMonitor(ctx, flowCtx.Cfg.DiskMonitor, "joinreader-disk")
+	jr.diskMonitor = execinfra.NewMonitor(ctx, flowCtx.ParentDiskMonitor(), "joinreader-disk")
 	drc := rowcontainer.NewDiskBackedNumberedRowContainer(
 		false, /* deDup */
 		typs,
@@ -712,6 +712,9 @@ func (jr *joinReader) generateMeta(ctx context.Context) []execinfrapb.ProducerMe
 	meta.Metrics = execinfrapb.GetMetricsMeta()
 	meta.Metrics.RowsRead = jr.GetRowsRead()
 	meta.Metrics.BytesRead = jr.GetBytesRead()
+	trailingMeta = append(trailingMeta, execinfrapb.ProducerMetadata{
+		ComponentStats: &execinfrapb.ComponentStats{KVTime: jr.fetcher.GetKVTime().Nanoseconds()},
+	})
 	if tfs := execinfra.GetLeafTxnFinalState(ctx, jr.FlowCtx.Txn); tfs != nil {
 		trailingMeta = append(trailingMeta,
 			execinfrapb.ProducerMetadata{LeafTxnFinalState: tfs},
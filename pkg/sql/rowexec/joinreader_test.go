@@ -630,7 +630,7 @@ func TestJoinReader(t *testing.T) {
 					var res rowenc.EncDatumRows
 					for {
 						row, meta := out.Next()
-						if meta != nil && meta.Metrics == nil {
+						if meta != nil && meta.Metrics == nil && meta.ComponentStats == nil {
 							t.Fatalf("unexpected metadata %+v", meta)
 						}
 						if row == nil {
@@ -760,7 +760,7 @@ CREATE TABLE test.t (a INT, s STRING, INDEX (a, s))`); err != nil {
 	count := 0
 	for {
 		row, meta := out.Next()
-		if meta != nil && meta.Metrics == nil {
+		if meta != nil && meta.Metrics == nil && meta.ComponentStats == nil {
 			t.Fatalf("unexpected metadata %+v", meta)
 		}
 		if row == nil {
@@ -42,6 +42,8 @@ func TestSorter(t *testing.T) {
 		v[i] = rowenc.IntEncDatum(i)
 	}
 
+	null := rowenc.NullEncDatum()
+
 	asc := encoding.Ascending
 	desc := encoding.Descending
 
@@ -83,6 +85,35 @@ func TestSorter(t *testing.T) {
 				{v[4], v[4], v[4]},
 				{v[4], v[4], v[5]},
 			},
+		}, {
+			name: "SortAllNullsReversed",
+			// Sort with NULLS LAST on an ascending column and NULLS FIRST on a
+			// descending column.
+			spec: execinfrapb.SorterSpec{
+				OutputOrdering: execinfrapb.ConvertToSpecOrdering(
+					colinfo.ColumnOrdering{
+						{ColIdx: 0, Direction: asc, NullsReversed: true},
+						{ColIdx: 1, Direction: desc, NullsReversed: true},
+						{ColIdx: 2, Direction: asc},
+					}),
+			},
+			types: rowenc.ThreeIntCols,
+			input: rowenc.EncDatumRows{
+				{null, v[1], v[0]},
+				{v[1], null, v[0]},
+				{v[1], v[2], v[0]},
+				{v[0], v[3], v[0]},
+				{null, null, v[0]},
+				{v[1], v[3], v[1]},
+			},
+			expected: rowenc.EncDatumRows{
+				{v[0], v[3], v[0]},
+				{v[1], null, v[0]},
+				{v[1], v[3], v[1]},
+				{v[1], v[2], v[0]},
+				{null, null, v[0]},
+				{null, v[1], v[0]},
+			},
 		}, {
 			name: "SortLimit",
 			// No specified input ordering but specified limit.
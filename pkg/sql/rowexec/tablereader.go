@@ -336,6 +336,9 @@ func (tr *tableReader) generateMeta(ctx context.Context) []execinfrapb.ProducerM
 	meta.Metrics = execinfrapb.GetMetricsMeta()
 	meta.Metrics.BytesRead, meta.Metrics.RowsRead = tr.GetBytesRead(), tr.GetRowsRead()
 	trailingMeta = append(trailingMeta, *meta)
+	trailingMeta = append(trailingMeta, execinfrapb.ProducerMetadata{
+		ComponentStats: &execinfrapb.ComponentStats{KVTime: tr.fetcher.GetKVTime().Nanoseconds()},
+	})
 	return trailingMeta
 }
 
@@ -167,7 +167,7 @@ func TestTableReader(t *testing.T) {
 				var res rowenc.EncDatumRows
 				for {
 					row, meta := results.Next()
-					if meta != nil && meta.LeafTxnFinalState == nil && meta.Metrics == nil {
+					if meta != nil && meta.LeafTxnFinalState == nil && meta.Metrics == nil && meta.ComponentStats == nil {
 						t.Fatalf("unexpected metadata: %+v", meta)
 					}
 					if row == nil {
@@ -282,8 +282,8 @@ ALTER TABLE t EXPERIMENTAL_RELOCATE VALUES (ARRAY[2], 1), (ARRAY[1], 2), (ARRAY[
 		for _, m := range metas {
 			if len(m.Ranges) > 0 {
 				misplannedRanges = m.Ranges
-			} else if m.LeafTxnFinalState == nil && m.Metrics == nil {
-				t.Fatalf("expected only txn coord meta, metrics, or misplanned ranges, got: %+v", metas)
+			} else if m.LeafTxnFinalState == nil && m.Metrics == nil && m.ComponentStats == nil {
+				t.Fatalf("expected only txn coord meta, metrics, component stats, or misplanned ranges, got: %+v", metas)
 			}
 		}
 		if len(misplannedRanges) != 2 {
@@ -461,7 +461,7 @@ func BenchmarkTableReader(b *testing.B) {
 				count := 0
 				for {
 					row, meta := tr.Next()
-					if meta != nil && meta.LeafTxnFinalState == nil && meta.Metrics == nil {
+					if meta != nil && meta.LeafTxnFinalState == nil && meta.Metrics == nil && meta.ComponentStats == nil {
 						b.Fatalf("unexpected metadata: %+v", meta)
 					}
 					if row != nil {
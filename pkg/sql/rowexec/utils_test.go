@@ -70,7 +70,7 @@ func runProcessorTest(
 	var res rowenc.EncDatumRows
 	for {
 		row, meta := out.Next()
-		if meta != nil && meta.Metrics == nil {
+		if meta != nil && meta.Metrics == nil && meta.ComponentStats == nil {
 			t.Fatalf("unexpected metadata %+v", meta)
 		}
 		row = row.Copy()
@@ -184,7 +184,7 @@ func newWindower(
 		return nil, err
 	}
 
-	w.diskMonitor = execinfra.NewMonitor(ctx, flowCtx.Cfg.DiskMonitor, "windower-disk")
+	w.diskMonitor = execinfra.NewMonitor(ctx, flowCtx.ParentDiskMonitor(), "windower-disk")
 	w.allRowsPartitioned = rowcontainer.NewHashDiskBackedRowContainer(
 		nil, /* memRowContainer */
 		evalCtx,
@@ -518,6 +518,7 @@ func (w *windower) processPartition(
 				// We need this +1 because encoding.Direction has extra value "_"
 				// as zeroth "entry" which its proto equivalent doesn't have.
 				frameRun.OrdDirection = encoding.Direction(ordCol.Direction + 1)
+				frameRun.OrdNullsReversed = ordCol.NullsReversed
 
 				colTyp := w.inputTypes[ordCol.ColIdx]
 				// Type of offset depends on the ordering column's type.
@@ -620,6 +620,7 @@ func (z *zigzagJoiner) produceInvertedIndexKey(
 	keyBytes, _, err := rowenc.EncodeColumns(
 		info.index.ExtraColumnIDs[:len(datums)-1],
 		info.indexDirs[1:],
+		nil, /* nullsReversed */
 		colMap,
 		decodedDatums,
 		keys[0],
@@ -608,7 +608,7 @@ func (sc *SchemaChanger) exec(ctx context.Context) error {
 		}
 		// Some descriptors should be deleted if they are in the DROP state.
 		switch desc.(type) {
-		case catalog.SchemaDescriptor, catalog.DatabaseDescriptor:
+		case catalog.SchemaDescriptor, catalog.DatabaseDescriptor, catalog.FunctionDescriptor:
 			if desc.Dropped() {
 				if err := sc.execCfg.DB.Del(ctx, catalogkeys.MakeDescMetadataKey(sc.execCfg.Codec, desc.GetID())); err != nil {
 					return err
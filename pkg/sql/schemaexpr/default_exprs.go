@@ -39,7 +39,7 @@ func MakeDefaultExprs(
 	// defaults map as the defaults are all NULL.
 	haveDefaults := false
 	for i := range cols {
-		if cols[i].DefaultExpr != nil {
+		if ColumnDefaultExpr(&cols[i]) != nil {
 			haveDefaults = true
 			break
 		}
@@ -52,9 +52,8 @@ func MakeDefaultExprs(
 	defaultExprs := make([]tree.TypedExpr, 0, len(cols))
 	exprStrings := make([]string, 0, len(cols))
 	for i := range cols {
-		col := &cols[i]
-		if col.DefaultExpr != nil {
-			exprStrings = append(exprStrings, *col.DefaultExpr)
+		if defaultExpr := ColumnDefaultExpr(&cols[i]); defaultExpr != nil {
+			exprStrings = append(exprStrings, *defaultExpr)
 		}
 	}
 	exprs, err := parser.ParseExprs(exprStrings)
@@ -65,7 +64,7 @@ func MakeDefaultExprs(
 	defExprIdx := 0
 	for i := range cols {
 		col := &cols[i]
-		if col.DefaultExpr == nil {
+		if ColumnDefaultExpr(col) == nil {
 			defaultExprs = append(defaultExprs, tree.DNull)
 			continue
 		}
@@ -83,6 +82,16 @@ func MakeDefaultExprs(
 	return defaultExprs, nil
 }
 
+// ColumnDefaultExpr returns the serialized default expression of the given
+// column. A column without a default expression uses the default expression
+// of its type if it is a DOMAIN, if any.
+func ColumnDefaultExpr(col *descpb.ColumnDescriptor) *string {
+	if col.DefaultExpr == nil && col.Type.IsDomain() && col.Type.TypeMeta.DomainData != nil {
+		return col.Type.TypeMeta.DomainData.DefaultExpr
+	}
+	return col.DefaultExpr
+}
+
 // ProcessColumnSet returns columns in cols, and other writable
 // columns in tableDesc that fulfills a given criteria in inSet.
 func ProcessColumnSet(
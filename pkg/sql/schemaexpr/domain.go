@@ -0,0 +1,112 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package schemaexpr
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+// DomainValueName is the name by which the CHECK constraints of a DOMAIN type
+// refer to the value being checked.
+const DomainValueName tree.Name = "value"
+
+// ValidateDomainCheckExpr verifies that the CHECK constraint expression of a
+// DOMAIN over the given base type results in a boolean, refers to no column
+// other than VALUE, and does not use invalid functions. The serialized
+// type-checked expression is returned.
+func ValidateDomainCheckExpr(
+	ctx context.Context, expr tree.Expr, baseType *types.T, semaCtx *tree.SemaContext,
+) (string, error) {
+	replacedExpr, err := ReplaceDomainValue(expr, &dummyColumn{typ: baseType, name: DomainValueName})
+	if err != nil {
+		return "", err
+	}
+	typedExpr, err := SanitizeVarFreeExpr(
+		ctx, replacedExpr, types.Bool, "DOMAIN CHECK", semaCtx, tree.VolatilityVolatile,
+	)
+	if err != nil {
+		return "", err
+	}
+	return tree.Serialize(typedExpr), nil
+}
+
+// ValidateDomainDefaultExpr verifies that the DEFAULT expression of a DOMAIN
+// over the given base type has the base type and contains no variables. The
+// serialized type-checked expression is returned.
+func ValidateDomainDefaultExpr(
+	ctx context.Context, expr tree.Expr, baseType *types.T, semaCtx *tree.SemaContext,
+) (string, error) {
+	typedExpr, err := SanitizeVarFreeExpr(
+		ctx, expr, baseType, "DEFAULT", semaCtx, tree.VolatilityVolatile,
+	)
+	if err != nil {
+		return "", err
+	}
+	return tree.Serialize(typedExpr), nil
+}
+
+// ParseDomainCheckExpr parses the serialized CHECK constraint expression of a
+// DOMAIN and replaces the references to VALUE in it with the given
+// expression.
+func ParseDomainCheckExpr(checkExpr string, value tree.Expr) (tree.Expr, error) {
+	expr, err := parser.ParseExpr(checkExpr)
+	if err != nil {
+		return nil, err
+	}
+	return ReplaceDomainValue(expr, value)
+}
+
+// ReplaceDomainValue replaces the references to VALUE in the CHECK constraint
+// expression of a DOMAIN with the given expression. It errs with
+// pgcode.UndefinedColumn if the expression references any other column.
+func ReplaceDomainValue(rootExpr tree.Expr, value tree.Expr) (tree.Expr, error) {
+	return tree.SimpleVisit(rootExpr, func(expr tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+		vBase, ok := expr.(tree.VarName)
+		if !ok {
+			return true, expr, nil
+		}
+
+		v, err := vBase.NormalizeVarName()
+		if err != nil {
+			return false, nil, err
+		}
+
+		c, ok := v.(*tree.ColumnItem)
+		if !ok {
+			return true, expr, nil
+		}
+		if c.TableName != nil || c.ColumnName != DomainValueName {
+			return false, nil, pgerror.Newf(pgcode.UndefinedColumn,
+				"column %q does not exist, referenced in %q", c.ColumnName, rootExpr.String())
+		}
+		return false, value, nil
+	})
+}
+
+// DomainCheckViolationError returns the error reported when a value does not
+// satisfy a CHECK constraint of a DOMAIN.
+func DomainCheckViolationError(domain string, constraint string) error {
+	return pgerror.Newf(pgcode.CheckViolation,
+		"value for domain %s violates check constraint %q", domain, constraint)
+}
+
+// DomainNotNullViolationError returns the error reported when a NULL value is
+// assigned to a DOMAIN with a NOT NULL constraint.
+func DomainNotNullViolationError(domain string) error {
+	return pgerror.Newf(pgcode.NotNullViolation,
+		"domain %s does not allow null values", domain)
+}
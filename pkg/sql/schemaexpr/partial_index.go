@@ -17,6 +17,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/geo/geoindex"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/transform"
@@ -94,6 +95,12 @@ func (v *IndexPredicateValidator) Validate(e tree.Expr) (string, error) {
 //
 //   INDEX i ON t (a) WHERE b > 0
 //
+// The index of a deferrable unique constraint is formatted as the constraint,
+// without its deferrability, which the caller must add after the partitioning
+// of the index:
+//
+//   CONSTRAINT i UNIQUE (a)
+//
 func FormatIndexForDisplay(
 	ctx context.Context,
 	table catalog.TableDescriptor,
@@ -102,20 +109,36 @@ func FormatIndexForDisplay(
 	semaCtx *tree.SemaContext,
 ) (string, error) {
 	f := tree.NewFmtCtx(tree.FmtSimple)
-	if index.Unique {
-		f.WriteString("UNIQUE ")
-	}
-	if index.Type == descpb.IndexDescriptor_INVERTED {
-		f.WriteString("INVERTED ")
-	}
-	f.WriteString("INDEX ")
-	f.FormatNameP(&index.Name)
-	if *tableName != descpb.AnonymousTable {
-		f.WriteString(" ON ")
-		f.FormatNode(tableName)
+	if index.DeferrableUnique {
+		f.WriteString("CONSTRAINT ")
+		f.FormatNameP(&index.Name)
+		f.WriteString(" UNIQUE")
+	} else {
+		if index.Unique {
+			f.WriteString("UNIQUE ")
+		}
+		if index.Type == descpb.IndexDescriptor_INVERTED {
+			f.WriteString("INVERTED ")
+		}
+		f.WriteString("INDEX ")
+		f.FormatNameP(&index.Name)
+		if *tableName != descpb.AnonymousTable {
+			f.WriteString(" ON ")
+			f.FormatNode(tableName)
+		}
 	}
 	f.WriteString(" (")
 	index.ColNamesFormat(f)
+	if index.Type == descpb.IndexDescriptor_INVERTED && len(index.ColumnNames) > 0 {
+		// Inverted indexes on string columns are trigram indexes.
+		col, _, err := table.FindColumnByName(tree.Name(index.ColumnNames[0]))
+		if err != nil {
+			return "", err
+		}
+		if colinfo.ColumnTypeIsTrigramIndexable(col.Type) {
+			f.WriteString(" gin_trgm_ops")
+		}
+	}
 	f.WriteByte(')')
 
 	if index.IsSharded() {
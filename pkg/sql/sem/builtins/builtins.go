@@ -61,6 +61,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timetz"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/trigram"
 	"github.com/cockroachdb/cockroach/pkg/util/unaccent"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
@@ -2904,11 +2905,57 @@ may increase either contention or retry errors, or both.`,
 	"tsvector_update_trigger_column": makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 
 	// Trigram functions.
-	"similarity":             makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 41285, Category: categoryTrigram}),
-	"show_trgm":              makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 41285, Category: categoryTrigram}),
-	"word_similarity":        makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 41285, Category: categoryTrigram}),
+	"similarity": makeBuiltin(tree.FunctionProperties{Category: categoryTrigram},
+		stringOverload2(
+			"left",
+			"right",
+			func(_ *tree.EvalContext, a, b string) (tree.Datum, error) {
+				return tree.NewDFloat(tree.DFloat(trigram.Similarity(a, b))), nil
+			},
+			types.Float,
+			"Returns a number between 0 and 1 that indicates how similar the two "+
+				"arguments are, based on the number of trigrams they share.",
+			tree.VolatilityImmutable,
+		)),
+	"show_trgm": makeBuiltin(tree.FunctionProperties{Category: categoryTrigram},
+		stringOverload1(
+			func(_ *tree.EvalContext, s string) (tree.Datum, error) {
+				arr := tree.NewDArray(types.String)
+				for _, t := range trigram.MakeTrigrams(s) {
+					if err := arr.Append(tree.NewDString(t)); err != nil {
+						return nil, err
+					}
+				}
+				return arr, nil
+			},
+			types.StringArray,
+			"Returns an array of all the trigrams in the given string.",
+			tree.VolatilityImmutable,
+		)),
+	"word_similarity": makeBuiltin(tree.FunctionProperties{Category: categoryTrigram},
+		stringOverload2(
+			"left",
+			"right",
+			func(_ *tree.EvalContext, a, b string) (tree.Datum, error) {
+				return tree.NewDFloat(tree.DFloat(trigram.WordSimilarity(a, b))), nil
+			},
+			types.Float,
+			"Returns a number between 0 and 1 that indicates the greatest similarity "+
+				"between the trigrams in `left` and any contiguous extent of the "+
+				"ordered trigrams in `right`.",
+			tree.VolatilityImmutable,
+		)),
+	"show_limit": makeBuiltin(tree.FunctionProperties{Category: categoryTrigram},
+		tree.Overload{
+			Types:      tree.ArgTypes{},
+			ReturnType: tree.FixedReturnType(types.Float),
+			Fn: func(_ *tree.EvalContext, _ tree.Datums) (tree.Datum, error) {
+				return tree.NewDFloat(trigram.DefaultSimilarityThreshold), nil
+			},
+			Info:       "Returns the similarity threshold used by the `%` operator.",
+			Volatility: tree.VolatilityImmutable,
+		}),
 	"strict_word_similarity": makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 41285, Category: categoryTrigram}),
-	"show_limit":             makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 41285, Category: categoryTrigram}),
 	"set_limit":              makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 41285, Category: categoryTrigram}),
 
 	// JSON functions.
@@ -3795,6 +3842,40 @@ may increase either contention or retry errors, or both.`,
 		},
 	),
 
+	// Identity functions with a fixed volatility. Inlined calls to user-defined
+	// functions declared STABLE or VOLATILE wrap their result in these, so that
+	// the optimizer treats the call as having the declared volatility even when
+	// the body of the function is less volatile.
+	"crdb_internal.stable_identity": makeBuiltin(
+		tree.FunctionProperties{
+			Category: categorySystemInfo,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"input", types.Any}},
+			ReturnType: tree.IdentityReturnType(0),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return args[0], nil
+			},
+			Info:       "Returns its argument. The function is marked as stable.",
+			Volatility: tree.VolatilityStable,
+		},
+	),
+
+	"crdb_internal.volatile_identity": makeBuiltin(
+		tree.FunctionProperties{
+			Category: categorySystemInfo,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"input", types.Any}},
+			ReturnType: tree.IdentityReturnType(0),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return args[0], nil
+			},
+			Info:       "Returns its argument. The function is marked as volatile.",
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
 	// Return a pretty key for a given raw key, skipping the specified number of
 	// fields.
 	"crdb_internal.pretty_key": makeBuiltin(
@@ -4083,6 +4164,22 @@ may increase either contention or retry errors, or both.`,
 			},
 			Info:       "This function is used only by CockroachDB's developers for testing purposes.",
 			Volatility: tree.VolatilityStable,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"val", types.String}},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				arg := args[0]
+				if arg == tree.DNull {
+					return tree.DZero, nil
+				}
+				// Trigram inverted indexes have an entry for each distinct trigram
+				// of a string.
+				n := len(trigram.MakeTrigrams(string(tree.MustBeDString(arg))))
+				return tree.NewDInt(tree.DInt(n)), nil
+			},
+			Info:       "This function is used only by CockroachDB's developers for testing purposes.",
+			Volatility: tree.VolatilityStable,
 		}),
 
 	// Returns true iff the current user has admin role.
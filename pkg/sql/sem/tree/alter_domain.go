@@ -0,0 +1,98 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+// AlterDomain represents an ALTER DOMAIN statement.
+type AlterDomain struct {
+	Domain *UnresolvedObjectName
+	Cmd    AlterDomainCmd
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterDomain) Format(ctx *FmtCtx) {
+	ctx.WriteString("ALTER DOMAIN ")
+	ctx.FormatNode(node.Domain)
+	ctx.FormatNode(node.Cmd)
+}
+
+// AlterDomainCmd represents a domain modification operation.
+type AlterDomainCmd interface {
+	NodeFormatter
+	alterDomainCmd()
+}
+
+func (*AlterDomainSetDefault) alterDomainCmd()     {}
+func (*AlterDomainSetNotNull) alterDomainCmd()     {}
+func (*AlterDomainAddConstraint) alterDomainCmd()  {}
+func (*AlterDomainDropConstraint) alterDomainCmd() {}
+
+var _ AlterDomainCmd = &AlterDomainSetDefault{}
+var _ AlterDomainCmd = &AlterDomainSetNotNull{}
+var _ AlterDomainCmd = &AlterDomainAddConstraint{}
+var _ AlterDomainCmd = &AlterDomainDropConstraint{}
+
+// AlterDomainSetDefault represents an ALTER DOMAIN {SET | DROP} DEFAULT
+// command. A nil Default represents DROP DEFAULT.
+type AlterDomainSetDefault struct {
+	Default Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterDomainSetDefault) Format(ctx *FmtCtx) {
+	if node.Default == nil {
+		ctx.WriteString(" DROP DEFAULT")
+	} else {
+		ctx.WriteString(" SET DEFAULT ")
+		ctx.FormatNode(node.Default)
+	}
+}
+
+// AlterDomainSetNotNull represents an ALTER DOMAIN {SET | DROP} NOT NULL
+// command.
+type AlterDomainSetNotNull struct {
+	NotNull bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterDomainSetNotNull) Format(ctx *FmtCtx) {
+	if node.NotNull {
+		ctx.WriteString(" SET NOT NULL")
+	} else {
+		ctx.WriteString(" DROP NOT NULL")
+	}
+}
+
+// AlterDomainAddConstraint represents an ALTER DOMAIN ADD CONSTRAINT command.
+type AlterDomainAddConstraint struct {
+	Constraint DomainConstraint
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterDomainAddConstraint) Format(ctx *FmtCtx) {
+	ctx.WriteString(" ADD ")
+	ctx.FormatNode(&node.Constraint)
+}
+
+// AlterDomainDropConstraint represents an ALTER DOMAIN DROP CONSTRAINT
+// command.
+type AlterDomainDropConstraint struct {
+	Constraint Name
+	IfExists   bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterDomainDropConstraint) Format(ctx *FmtCtx) {
+	ctx.WriteString(" DROP CONSTRAINT ")
+	if node.IfExists {
+		ctx.WriteString("IF EXISTS ")
+	}
+	ctx.FormatNode(&node.Constraint)
+}
@@ -104,16 +104,43 @@ func (node Backup) Coverage() DescriptorCoverage {
 	return RequestedDescriptors
 }
 
+// CompactBackup represents a BACKUP COMPACT statement, which merges a backup
+// and the incremental backups appended to it into a new full backup.
+type CompactBackup struct {
+	From    Expr
+	To      Expr
+	Options KVOptions
+}
+
+var _ Statement = &CompactBackup{}
+
+// Format implements the NodeFormatter interface.
+func (node *CompactBackup) Format(ctx *FmtCtx) {
+	ctx.WriteString("BACKUP COMPACT ")
+	ctx.FormatNode(node.From)
+	ctx.WriteString(" TO ")
+	ctx.FormatNode(node.To)
+	if len(node.Options) > 0 {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
+}
+
 // RestoreOptions describes options for the RESTORE execution.
 type RestoreOptions struct {
 	EncryptionPassphrase      Expr
 	DecryptionKMSURI          StringOrPlaceholderOptList
 	IntoDB                    Expr
+	NewDBName                 Expr
 	SkipMissingFKs            bool
 	SkipMissingSequences      bool
 	SkipMissingSequenceOwners bool
 	SkipMissingViews          bool
 	Detached                  bool
+	VerifyData                bool
+	// Where is a predicate on the primary key of the single restored table
+	// which restricts the rows that are restored.
+	Where Expr
 }
 
 var _ NodeFormatter = &RestoreOptions{}
@@ -297,6 +324,12 @@ func (o *RestoreOptions) Format(ctx *FmtCtx) {
 		o.IntoDB.Format(ctx)
 	}
 
+	if o.NewDBName != nil {
+		maybeAddSep()
+		ctx.WriteString("new_db_name=")
+		o.NewDBName.Format(ctx)
+	}
+
 	if o.SkipMissingFKs {
 		maybeAddSep()
 		ctx.WriteString("skip_missing_foreign_keys")
@@ -321,6 +354,17 @@ func (o *RestoreOptions) Format(ctx *FmtCtx) {
 		maybeAddSep()
 		ctx.WriteString("detached")
 	}
+
+	if o.VerifyData {
+		maybeAddSep()
+		ctx.WriteString("verify_backup_table_data")
+	}
+
+	if o.Where != nil {
+		maybeAddSep()
+		ctx.WriteString("where=")
+		o.Where.Format(ctx)
+	}
 }
 
 // CombineWith merges other backup options into this backup options struct.
@@ -344,6 +388,12 @@ func (o *RestoreOptions) CombineWith(other *RestoreOptions) error {
 		return errors.New("into_db specified multiple times")
 	}
 
+	if o.NewDBName == nil {
+		o.NewDBName = other.NewDBName
+	} else if other.NewDBName != nil {
+		return errors.New("new_db_name specified multiple times")
+	}
+
 	if o.SkipMissingFKs {
 		if other.SkipMissingFKs {
 			return errors.New("skip_missing_foreign_keys specified multiple times")
@@ -384,6 +434,20 @@ func (o *RestoreOptions) CombineWith(other *RestoreOptions) error {
 		o.Detached = other.Detached
 	}
 
+	if o.VerifyData {
+		if other.VerifyData {
+			return errors.New("verify_backup_table_data specified multiple times")
+		}
+	} else {
+		o.VerifyData = other.VerifyData
+	}
+
+	if o.Where == nil {
+		o.Where = other.Where
+	} else if other.Where != nil {
+		return errors.New("where specified multiple times")
+	}
+
 	return nil
 }
 
@@ -397,5 +461,8 @@ func (o RestoreOptions) IsDefault() bool {
 		cmp.Equal(o.DecryptionKMSURI, options.DecryptionKMSURI) &&
 		o.EncryptionPassphrase == options.EncryptionPassphrase &&
 		o.IntoDB == options.IntoDB &&
-		o.Detached == options.Detached
+		o.NewDBName == options.NewDBName &&
+		o.Detached == options.Detached &&
+		o.VerifyData == options.VerifyData &&
+		o.Where == options.Where
 }
@@ -1038,6 +1038,23 @@ func PerformCast(ctx *EvalContext, d Datum, t *types.T) (Datum, error) {
 			}
 			return dcast, nil
 		}
+	case types.TupleFamily:
+		if v, ok := d.(*DTuple); ok && t.IsComposite() && len(v.D) == len(t.TupleContents()) {
+			// Cast each element of the tuple to the type of the corresponding
+			// field of the composite type.
+			dcast := NewDTupleWithLen(t, len(v.D))
+			for i, e := range v.D {
+				dcast.D[i] = DNull
+				if e != DNull {
+					var err error
+					dcast.D[i], err = PerformCast(ctx, e, t.TupleContents()[i])
+					if err != nil {
+						return nil, err
+					}
+				}
+			}
+			return dcast, nil
+		}
 	case types.OidFamily:
 		switch v := d.(type) {
 		case *DOid:
@@ -78,11 +78,19 @@ type IndexElem struct {
 	Column     Name
 	Direction  Direction
 	NullsOrder NullsOrder
+	// OpClass is the operator class of the column, if one was specified. Only
+	// the trigram operator classes gin_trgm_ops and gist_trgm_ops are
+	// accepted by the parser, and only gin_trgm_ops can be used in an index.
+	OpClass Name
 }
 
 // Format implements the NodeFormatter interface.
 func (node *IndexElem) Format(ctx *FmtCtx) {
 	ctx.FormatNode(&node.Column)
+	if node.OpClass != "" {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(&node.OpClass)
+	}
 	if node.Direction != DefaultDirection {
 		ctx.WriteByte(' ')
 		ctx.WriteString(node.Direction.String())
@@ -216,12 +224,102 @@ type CreateType struct {
 	Variety  CreateTypeVariety
 	// EnumLabels is set when this represents a CREATE TYPE ... AS ENUM statement.
 	EnumLabels []string
+	// CompositeFields is set when this represents a CREATE TYPE ... AS (...)
+	// statement.
+	CompositeFields []CompositeTypeField
+	// DomainType, DomainDefault and DomainConstraints are set when this
+	// represents a CREATE DOMAIN statement.
+	DomainType        ResolvableTypeReference
+	DomainDefault     Expr
+	DomainConstraints []DomainConstraint
+}
+
+// NewCreateDomain constructs a CREATE DOMAIN statement from the qualifications
+// that follow the base type. Only DEFAULT, NULL, NOT NULL and CHECK
+// qualifications are accepted by the grammar.
+func NewCreateDomain(
+	name *UnresolvedObjectName, typ ResolvableTypeReference, qualifications []NamedColumnQualification,
+) (*CreateType, error) {
+	n := &CreateType{
+		TypeName:   name,
+		Variety:    Domain,
+		DomainType: typ,
+	}
+	sawNull := false
+	for _, c := range qualifications {
+		switch t := c.Qualification.(type) {
+		case *ColumnDefault:
+			if n.DomainDefault != nil {
+				return nil, pgerror.Newf(pgcode.Syntax,
+					"multiple default expressions")
+			}
+			n.DomainDefault = t.Expr
+		case NotNullConstraint:
+			if sawNull {
+				return nil, pgerror.Newf(pgcode.Syntax,
+					"conflicting NULL/NOT NULL constraints")
+			}
+			n.DomainConstraints = append(n.DomainConstraints, DomainConstraint{Name: c.Name, NotNull: true})
+		case NullConstraint:
+			for i := range n.DomainConstraints {
+				if n.DomainConstraints[i].NotNull {
+					return nil, pgerror.Newf(pgcode.Syntax,
+						"conflicting NULL/NOT NULL constraints")
+				}
+			}
+			sawNull = true
+		case *ColumnCheckConstraint:
+			n.DomainConstraints = append(n.DomainConstraints, DomainConstraint{Name: c.Name, Check: t.Expr})
+		default:
+			return nil, errors.AssertionFailedf("unexpected domain qualification %T", t)
+		}
+	}
+	return n, nil
+}
+
+// DomainConstraint represents a constraint on the values of a DOMAIN type.
+// Exactly one of NotNull and Check is set.
+type DomainConstraint struct {
+	Name    Name
+	NotNull bool
+	Check   Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DomainConstraint) Format(ctx *FmtCtx) {
+	if node.Name != "" {
+		ctx.WriteString("CONSTRAINT ")
+		ctx.FormatNode(&node.Name)
+		ctx.WriteByte(' ')
+	}
+	if node.NotNull {
+		ctx.WriteString("NOT NULL")
+	} else {
+		ctx.WriteString("CHECK (")
+		ctx.FormatNode(node.Check)
+		ctx.WriteByte(')')
+	}
 }
 
 var _ Statement = &CreateType{}
 
 // Format implements the NodeFormatter interface.
 func (node *CreateType) Format(ctx *FmtCtx) {
+	if node.Variety == Domain {
+		ctx.WriteString("CREATE DOMAIN ")
+		ctx.WriteString(node.TypeName.String())
+		ctx.WriteString(" AS ")
+		ctx.FormatTypeReference(node.DomainType)
+		if node.DomainDefault != nil {
+			ctx.WriteString(" DEFAULT ")
+			ctx.FormatNode(node.DomainDefault)
+		}
+		for i := range node.DomainConstraints {
+			ctx.WriteByte(' ')
+			ctx.FormatNode(&node.DomainConstraints[i])
+		}
+		return
+	}
 	ctx.WriteString("CREATE TYPE ")
 	ctx.WriteString(node.TypeName.String())
 	ctx.WriteString(" ")
@@ -235,6 +333,15 @@ func (node *CreateType) Format(ctx *FmtCtx) {
 			lex.EncodeSQLString(&ctx.Buffer, node.EnumLabels[i])
 		}
 		ctx.WriteString(")")
+	case Composite:
+		ctx.WriteString("AS (")
+		for i := range node.CompositeFields {
+			if i > 0 {
+				ctx.WriteString(", ")
+			}
+			ctx.FormatNode(&node.CompositeFields[i])
+		}
+		ctx.WriteString(")")
 	}
 }
 
@@ -242,6 +349,243 @@ func (node *CreateType) String() string {
 	return AsString(node)
 }
 
+// CompositeTypeField is a field of a composite type in a CREATE TYPE
+// statement.
+type CompositeTypeField struct {
+	Label Name
+	Type  ResolvableTypeReference
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CompositeTypeField) Format(ctx *FmtCtx) {
+	ctx.FormatNode(&node.Label)
+	ctx.WriteByte(' ')
+	ctx.FormatTypeReference(node.Type)
+}
+
+// FuncParam is a parameter of a user-defined function. The name is empty
+// for parameters that are only referenced positionally.
+type FuncParam struct {
+	Name Name
+	Type ResolvableTypeReference
+}
+
+// FuncParams is a list of function parameters.
+type FuncParams []FuncParam
+
+// Format implements the NodeFormatter interface.
+func (node *FuncParams) Format(ctx *FmtCtx) {
+	for i := range *node {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		p := &(*node)[i]
+		if p.Name != "" {
+			ctx.FormatNode(&p.Name)
+			ctx.WriteByte(' ')
+		}
+		ctx.FormatTypeReference(p.Type)
+	}
+}
+
+// FunctionOption is an option of a CREATE FUNCTION statement, such as its
+// volatility or its body.
+type FunctionOption interface {
+	functionOption()
+}
+
+// FunctionLanguage is the LANGUAGE option of a function.
+type FunctionLanguage string
+
+// FunctionVolatility is the IMMUTABLE, STABLE or VOLATILE option of a
+// function.
+type FunctionVolatility Volatility
+
+// FunctionNullInputBehavior is the STRICT, RETURNS NULL ON NULL INPUT or
+// CALLED ON NULL INPUT option of a function. It is true for the first two.
+type FunctionNullInputBehavior bool
+
+// FunctionBody is the AS option of a function.
+type FunctionBody string
+
+func (FunctionLanguage) functionOption()          {}
+func (FunctionVolatility) functionOption()        {}
+func (FunctionNullInputBehavior) functionOption() {}
+func (FunctionBody) functionOption()              {}
+
+// CreateFunction represents a CREATE FUNCTION statement.
+type CreateFunction struct {
+	Name    *UnresolvedObjectName
+	Replace bool
+	Params  FuncParams
+	// ReturnType is the declared return type of the function. It is nil for
+	// functions declared with RETURNS TABLE, which use TableColumns instead,
+	// and for trigger functions.
+	ReturnType ResolvableTypeReference
+	// ReturnsSet is set for RETURNS SETOF and RETURNS TABLE.
+	ReturnsSet   bool
+	TableColumns FuncParams
+	// ReturnsTrigger is set for RETURNS TRIGGER.
+	ReturnsTrigger bool
+	Language       string
+	// Volatility is zero if no volatility was specified, in which case the
+	// function is VOLATILE.
+	Volatility Volatility
+	Strict     bool
+	Body       string
+}
+
+var _ Statement = &CreateFunction{}
+
+// SetOptions populates the CREATE FUNCTION statement from the given options,
+// rejecting options that are specified more than once.
+func (node *CreateFunction) SetOptions(opts []FunctionOption) error {
+	var seenLanguage, seenVolatility, seenStrict, seenBody bool
+	for _, opt := range opts {
+		var seen *bool
+		switch t := opt.(type) {
+		case FunctionLanguage:
+			seen = &seenLanguage
+			node.Language = strings.ToLower(string(t))
+		case FunctionVolatility:
+			seen = &seenVolatility
+			node.Volatility = Volatility(t)
+		case FunctionNullInputBehavior:
+			seen = &seenStrict
+			node.Strict = bool(t)
+		case FunctionBody:
+			seen = &seenBody
+			node.Body = string(t)
+		default:
+			return errors.AssertionFailedf("unknown function option %T", t)
+		}
+		if *seen {
+			return pgerror.New(pgcode.Syntax, "conflicting or redundant options")
+		}
+		*seen = true
+	}
+	if !seenLanguage {
+		return pgerror.New(pgcode.InvalidFunctionDefinition, "no language specified")
+	}
+	if !seenBody {
+		return pgerror.New(pgcode.InvalidFunctionDefinition, "no function body specified")
+	}
+	return nil
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateFunction) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE ")
+	if node.Replace {
+		ctx.WriteString("OR REPLACE ")
+	}
+	ctx.WriteString("FUNCTION ")
+	ctx.FormatNode(node.Name)
+	ctx.WriteByte('(')
+	ctx.FormatNode(&node.Params)
+	ctx.WriteString(") RETURNS ")
+	if node.ReturnsTrigger {
+		ctx.WriteString("TRIGGER")
+	} else if node.ReturnType == nil {
+		ctx.WriteString("TABLE (")
+		ctx.FormatNode(&node.TableColumns)
+		ctx.WriteByte(')')
+	} else {
+		if node.ReturnsSet {
+			ctx.WriteString("SETOF ")
+		}
+		ctx.FormatTypeReference(node.ReturnType)
+	}
+	ctx.WriteString(" LANGUAGE ")
+	ctx.WriteString(node.Language)
+	if node.Volatility != 0 {
+		ctx.WriteByte(' ')
+		ctx.WriteString(strings.ToUpper(node.Volatility.String()))
+	}
+	if node.Strict {
+		ctx.WriteString(" STRICT")
+	}
+	ctx.WriteString(" AS ")
+	if ctx.HasFlags(FmtAnonymize) || ctx.HasFlags(FmtHideConstants) {
+		ctx.WriteByte('_')
+	} else {
+		lex.EncodeSQLString(&ctx.Buffer, node.Body)
+	}
+}
+
+// TriggerEvent is an event that fires a trigger.
+type TriggerEvent int
+
+// TriggerEvent values.
+const (
+	TriggerEventInsert TriggerEvent = iota
+	TriggerEventUpdate
+	TriggerEventDelete
+)
+
+var triggerEventName = [...]string{
+	TriggerEventInsert: "INSERT",
+	TriggerEventUpdate: "UPDATE",
+	TriggerEventDelete: "DELETE",
+}
+
+func (e TriggerEvent) String() string {
+	return triggerEventName[e]
+}
+
+// TriggerEvents is a list of events that fire a trigger.
+type TriggerEvents []TriggerEvent
+
+// Contains returns true if the list contains the given event.
+func (node TriggerEvents) Contains(e TriggerEvent) bool {
+	for _, other := range node {
+		if other == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Format implements the NodeFormatter interface.
+func (node *TriggerEvents) Format(ctx *FmtCtx) {
+	for i, e := range *node {
+		if i > 0 {
+			ctx.WriteString(" OR ")
+		}
+		ctx.WriteString(e.String())
+	}
+}
+
+// CreateTrigger represents a CREATE TRIGGER statement. Only row-level
+// triggers are supported.
+type CreateTrigger struct {
+	Name Name
+	// Before is set for BEFORE triggers and unset for AFTER triggers.
+	Before   bool
+	Events   TriggerEvents
+	Table    *UnresolvedObjectName
+	FuncName *UnresolvedObjectName
+}
+
+var _ Statement = &CreateTrigger{}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateTrigger) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE TRIGGER ")
+	ctx.FormatNode(&node.Name)
+	if node.Before {
+		ctx.WriteString(" BEFORE ")
+	} else {
+		ctx.WriteString(" AFTER ")
+	}
+	ctx.FormatNode(&node.Events)
+	ctx.WriteString(" ON ")
+	ctx.FormatNode(node.Table)
+	ctx.WriteString(" FOR EACH ROW EXECUTE FUNCTION ")
+	ctx.FormatNode(node.FuncName)
+	ctx.WriteString("()")
+}
+
 // TableDef represents a column, index or constraint definition within a CREATE
 // TABLE statement.
 type TableDef interface {
@@ -299,6 +643,7 @@ type ColumnTableDef struct {
 	}
 	Unique               bool
 	UniqueConstraintName Name
+	UniqueDeferrability  ConstraintDeferrability
 	DefaultExpr          struct {
 		Expr           Expr
 		ConstraintName Name
@@ -310,10 +655,12 @@ type ColumnTableDef struct {
 		ConstraintName Name
 		Actions        ReferenceActions
 		Match          CompositeKeyMatchMethod
+		Deferrability  ConstraintDeferrability
 	}
 	Computed struct {
 		Computed bool
 		Expr     Expr
+		Virtual  bool
 	}
 	Family struct {
 		Name        Name
@@ -416,6 +763,7 @@ func NewColumnTableDef(
 		case UniqueConstraint:
 			d.Unique = true
 			d.UniqueConstraintName = c.Name
+			d.UniqueDeferrability = t.Deferrability
 		case *ColumnCheckConstraint:
 			d.CheckExprs = append(d.CheckExprs, ColumnTableDefCheckExpr{
 				Expr:           t.Expr,
@@ -431,9 +779,11 @@ func NewColumnTableDef(
 			d.References.ConstraintName = c.Name
 			d.References.Actions = t.Actions
 			d.References.Match = t.Match
+			d.References.Deferrability = t.Deferrability
 		case *ColumnComputedDef:
 			d.Computed.Computed = true
 			d.Computed.Expr = t.Expr
+			d.Computed.Virtual = t.Virtual
 		case *ColumnFamilyConstraint:
 			if d.HasColumnFamily() {
 				return nil, pgerror.Newf(pgcode.InvalidTableDefinition,
@@ -464,6 +814,11 @@ func (node *ColumnTableDef) IsComputed() bool {
 	return node.Computed.Computed
 }
 
+// IsVirtual returns if the ColumnTableDef is a virtual computed column.
+func (node *ColumnTableDef) IsVirtual() bool {
+	return node.Computed.Virtual
+}
+
 // HasColumnFamily returns if the ColumnTableDef has a column family.
 func (node *ColumnTableDef) HasColumnFamily() bool {
 	return node.Family.Name != "" || node.Family.Create
@@ -503,6 +858,7 @@ func (node *ColumnTableDef) Format(ctx *FmtCtx) {
 			}
 		} else if node.Unique {
 			ctx.WriteString(" UNIQUE")
+			ctx.FormatNode(&node.UniqueDeferrability)
 		}
 	}
 	if node.HasDefaultExpr() {
@@ -539,11 +895,16 @@ func (node *ColumnTableDef) Format(ctx *FmtCtx) {
 			ctx.WriteString(node.References.Match.String())
 		}
 		ctx.FormatNode(&node.References.Actions)
+		ctx.FormatNode(&node.References.Deferrability)
 	}
 	if node.IsComputed() {
 		ctx.WriteString(" AS (")
 		ctx.FormatNode(node.Computed.Expr)
-		ctx.WriteString(") STORED")
+		if node.IsVirtual() {
+			ctx.WriteString(") VIRTUAL")
+		} else {
+			ctx.WriteString(") STORED")
+		}
 	}
 	if node.HasColumnFamily() {
 		if node.Family.Create {
@@ -628,7 +989,9 @@ type ShardedPrimaryKeyConstraint struct {
 }
 
 // UniqueConstraint represents UNIQUE on a column.
-type UniqueConstraint struct{}
+type UniqueConstraint struct {
+	Deferrability ConstraintDeferrability
+}
 
 // ColumnCheckConstraint represents either a check on a column.
 type ColumnCheckConstraint struct {
@@ -637,15 +1000,17 @@ type ColumnCheckConstraint struct {
 
 // ColumnFKConstraint represents a FK-constaint on a column.
 type ColumnFKConstraint struct {
-	Table   TableName
-	Col     Name // empty-string means use PK
-	Actions ReferenceActions
-	Match   CompositeKeyMatchMethod
+	Table         TableName
+	Col           Name // empty-string means use PK
+	Actions       ReferenceActions
+	Match         CompositeKeyMatchMethod
+	Deferrability ConstraintDeferrability
 }
 
 // ColumnComputedDef represents the description of a computed column.
 type ColumnComputedDef struct {
-	Expr Expr
+	Expr    Expr
+	Virtual bool
 }
 
 // ColumnFamilyConstraint represents FAMILY on a column.
@@ -727,7 +1092,8 @@ func (*CheckConstraintTableDef) constraintTableDef()      {}
 // TABLE statement.
 type UniqueConstraintTableDef struct {
 	IndexTableDef
-	PrimaryKey bool
+	PrimaryKey    bool
+	Deferrability ConstraintDeferrability
 }
 
 // SetName implements the TableDef interface.
@@ -764,6 +1130,7 @@ func (node *UniqueConstraintTableDef) Format(ctx *FmtCtx) {
 	if node.PartitionBy != nil {
 		ctx.FormatNode(node.PartitionBy)
 	}
+	ctx.FormatNode(&node.Deferrability)
 	if node.Predicate != nil {
 		ctx.WriteString(" WHERE ")
 		ctx.FormatNode(node.Predicate)
@@ -837,14 +1204,37 @@ func (c CompositeKeyMatchMethod) String() string {
 	return compositeKeyMatchMethodName[c]
 }
 
+// ConstraintDeferrability represents whether the checks of a constraint can
+// be deferred until the end of the transaction, and whether they are by
+// default.
+type ConstraintDeferrability int8
+
+// The values for ConstraintDeferrability.
+const (
+	ConstraintNotDeferrable ConstraintDeferrability = iota
+	ConstraintInitiallyImmediate
+	ConstraintInitiallyDeferred
+)
+
+// Format implements the NodeFormatter interface.
+func (node *ConstraintDeferrability) Format(ctx *FmtCtx) {
+	switch *node {
+	case ConstraintInitiallyImmediate:
+		ctx.WriteString(" DEFERRABLE")
+	case ConstraintInitiallyDeferred:
+		ctx.WriteString(" DEFERRABLE INITIALLY DEFERRED")
+	}
+}
+
 // ForeignKeyConstraintTableDef represents a FOREIGN KEY constraint in the AST.
 type ForeignKeyConstraintTableDef struct {
-	Name     Name
-	Table    TableName
-	FromCols NameList
-	ToCols   NameList
-	Actions  ReferenceActions
-	Match    CompositeKeyMatchMethod
+	Name          Name
+	Table         TableName
+	FromCols      NameList
+	ToCols        NameList
+	Actions       ReferenceActions
+	Match         CompositeKeyMatchMethod
+	Deferrability ConstraintDeferrability
 }
 
 // Format implements the NodeFormatter interface.
@@ -872,6 +1262,7 @@ func (node *ForeignKeyConstraintTableDef) Format(ctx *FmtCtx) {
 	}
 
 	ctx.FormatNode(&node.Actions)
+	ctx.FormatNode(&node.Deferrability)
 }
 
 // SetName implements the ConstraintTableDef interface.
@@ -1210,12 +1601,13 @@ func (node *CreateTable) HoistConstraints() {
 					targetCol = append(targetCol, col.References.Col)
 				}
 				node.Defs = append(node.Defs, &ForeignKeyConstraintTableDef{
-					Table:    *col.References.Table,
-					FromCols: NameList{col.Name},
-					ToCols:   targetCol,
-					Name:     col.References.ConstraintName,
-					Actions:  col.References.Actions,
-					Match:    col.References.Match,
+					Table:         *col.References.Table,
+					FromCols:      NameList{col.Name},
+					ToCols:        targetCol,
+					Name:          col.References.ConstraintName,
+					Actions:       col.References.Actions,
+					Match:         col.References.Match,
+					Deferrability: col.References.Deferrability,
 				})
 				col.References.Table = nil
 			}
@@ -0,0 +1,153 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+import "strconv"
+
+// CursorScrollOption represents the scroll option, if one was given, of a
+// DECLARE statement.
+type CursorScrollOption int8
+
+const (
+	// UnspecifiedScroll represents no SCROLL option.
+	UnspecifiedScroll CursorScrollOption = iota
+	// Scroll represents SCROLL.
+	Scroll
+	// NoScroll represents NO SCROLL.
+	NoScroll
+)
+
+// DeclareCursor represents a DECLARE statement.
+type DeclareCursor struct {
+	Name        Name
+	Select      *Select
+	Binary      bool
+	Insensitive bool
+	Scroll      CursorScrollOption
+	Hold        bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DeclareCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("DECLARE ")
+	ctx.FormatNode(&node.Name)
+	if node.Binary {
+		ctx.WriteString(" BINARY")
+	}
+	if node.Insensitive {
+		ctx.WriteString(" INSENSITIVE")
+	}
+	switch node.Scroll {
+	case Scroll:
+		ctx.WriteString(" SCROLL")
+	case NoScroll:
+		ctx.WriteString(" NO SCROLL")
+	}
+	ctx.WriteString(" CURSOR ")
+	if node.Hold {
+		ctx.WriteString("WITH HOLD ")
+	}
+	ctx.WriteString("FOR ")
+	ctx.FormatNode(node.Select)
+}
+
+// FetchType represents the direction of a FETCH or MOVE statement.
+type FetchType int8
+
+const (
+	// FetchNormal moves Count rows forward, or backward if Count is negative.
+	// NEXT, PRIOR, FORWARD n and BACKWARD n are all represented this way.
+	FetchNormal FetchType = iota
+	// FetchRelative moves to the Count'th row after the current one, or
+	// before the current one if Count is negative.
+	FetchRelative
+	// FetchAbsolute moves to the Count'th row of the result, counting from
+	// the end if Count is negative.
+	FetchAbsolute
+	// FetchFirst moves to the first row of the result.
+	FetchFirst
+	// FetchLast moves to the last row of the result.
+	FetchLast
+	// FetchAll moves forward through all the remaining rows.
+	FetchAll
+	// FetchBackwardAll moves backward through all the preceding rows.
+	FetchBackwardAll
+)
+
+// CursorStmt represents the direction and cursor of a FETCH or MOVE
+// statement.
+type CursorStmt struct {
+	Name      Name
+	FetchType FetchType
+	Count     int64
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CursorStmt) Format(ctx *FmtCtx) {
+	switch node.FetchType {
+	case FetchNormal:
+		ctx.WriteString(strconv.FormatInt(node.Count, 10))
+	case FetchRelative:
+		ctx.WriteString("RELATIVE ")
+		ctx.WriteString(strconv.FormatInt(node.Count, 10))
+	case FetchAbsolute:
+		ctx.WriteString("ABSOLUTE ")
+		ctx.WriteString(strconv.FormatInt(node.Count, 10))
+	case FetchFirst:
+		ctx.WriteString("FIRST")
+	case FetchLast:
+		ctx.WriteString("LAST")
+	case FetchAll:
+		ctx.WriteString("ALL")
+	case FetchBackwardAll:
+		ctx.WriteString("BACKWARD ALL")
+	}
+	ctx.WriteByte(' ')
+	ctx.FormatNode(&node.Name)
+}
+
+// FetchCursor represents a FETCH statement.
+type FetchCursor struct {
+	CursorStmt
+}
+
+// Format implements the NodeFormatter interface.
+func (node *FetchCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("FETCH ")
+	ctx.FormatNode(&node.CursorStmt)
+}
+
+// MoveCursor represents a MOVE statement.
+type MoveCursor struct {
+	CursorStmt
+}
+
+// Format implements the NodeFormatter interface.
+func (node *MoveCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("MOVE ")
+	ctx.FormatNode(&node.CursorStmt)
+}
+
+// CloseCursor represents a CLOSE statement.
+type CloseCursor struct {
+	Name Name
+	All  bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CloseCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("CLOSE ")
+	if node.All {
+		ctx.WriteString("ALL")
+	} else {
+		ctx.FormatNode(&node.Name)
+	}
+}
@@ -3463,7 +3463,9 @@ func (d *DTuple) IsMin(ctx *EvalContext) bool {
 }
 
 // AmbiguousFormat implements the Datum interface.
-func (*DTuple) AmbiguousFormat() bool { return false }
+// The values of composite types are formatted as anonymous tuples, which must
+// be annotated with the composite type.
+func (d *DTuple) AmbiguousFormat() bool { return d.ResolvedType().IsComposite() }
 
 // Format implements the NodeFormatter interface.
 func (d *DTuple) Format(ctx *FmtCtx) {
@@ -3473,7 +3475,8 @@ func (d *DTuple) Format(ctx *FmtCtx) {
 	}
 
 	typ := d.ResolvedType()
-	showLabels := len(typ.TupleLabels()) > 0
+	// The labels of a composite type are implied by the type.
+	showLabels := len(typ.TupleLabels()) > 0 && !typ.IsComposite()
 	if showLabels {
 		ctx.WriteByte('(')
 	}
@@ -174,13 +174,19 @@ type DropType struct {
 	Names        []*UnresolvedObjectName
 	IfExists     bool
 	DropBehavior DropBehavior
+	// IsDomain is set when this represents a DROP DOMAIN statement.
+	IsDomain bool
 }
 
 var _ Statement = &DropType{}
 
 // Format implements the NodeFormatter interface.
 func (node *DropType) Format(ctx *FmtCtx) {
-	ctx.WriteString("DROP TYPE ")
+	if node.IsDomain {
+		ctx.WriteString("DROP DOMAIN ")
+	} else {
+		ctx.WriteString("DROP TYPE ")
+	}
 	if node.IfExists {
 		ctx.WriteString("IF EXISTS ")
 	}
@@ -196,6 +202,59 @@ func (node *DropType) Format(ctx *FmtCtx) {
 	}
 }
 
+// DropFunction represents a DROP FUNCTION command. Functions cannot be
+// overloaded, so any argument types given in the statement are ignored.
+type DropFunction struct {
+	Names        []*UnresolvedObjectName
+	IfExists     bool
+	DropBehavior DropBehavior
+}
+
+var _ Statement = &DropFunction{}
+
+// Format implements the NodeFormatter interface.
+func (node *DropFunction) Format(ctx *FmtCtx) {
+	ctx.WriteString("DROP FUNCTION ")
+	if node.IfExists {
+		ctx.WriteString("IF EXISTS ")
+	}
+	for i := range node.Names {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(node.Names[i])
+	}
+	if node.DropBehavior != DropDefault {
+		ctx.WriteByte(' ')
+		ctx.WriteString(node.DropBehavior.String())
+	}
+}
+
+// DropTrigger represents a DROP TRIGGER command.
+type DropTrigger struct {
+	Name         Name
+	Table        *UnresolvedObjectName
+	IfExists     bool
+	DropBehavior DropBehavior
+}
+
+var _ Statement = &DropTrigger{}
+
+// Format implements the NodeFormatter interface.
+func (node *DropTrigger) Format(ctx *FmtCtx) {
+	ctx.WriteString("DROP TRIGGER ")
+	if node.IfExists {
+		ctx.WriteString("IF EXISTS ")
+	}
+	ctx.FormatNode(&node.Name)
+	ctx.WriteString(" ON ")
+	ctx.FormatNode(node.Table)
+	if node.DropBehavior != DropDefault {
+		ctx.WriteByte(' ')
+		ctx.WriteString(node.DropBehavior.String())
+	}
+}
+
 // DropSchema represents a DROP SCHEMA command.
 type DropSchema struct {
 	Names        []string
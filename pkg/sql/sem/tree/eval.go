@@ -45,6 +45,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/trigram"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
 	"github.com/lib/pq/oid"
@@ -1579,6 +1580,18 @@ var BinOps = map[BinaryOperator]binOpOverload{
 			},
 			Volatility: VolatilityImmutable,
 		},
+		// The similarity operator of pg_trgm. It returns whether the two strings
+		// have a trigram similarity of at least trigram.DefaultSimilarityThreshold.
+		&BinOp{
+			LeftType:   types.String,
+			RightType:  types.String,
+			ReturnType: types.Bool,
+			Fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				sim := trigram.Similarity(string(MustBeDString(left)), string(MustBeDString(right)))
+				return MakeDBool(sim >= trigram.DefaultSimilarityThreshold), nil
+			},
+			Volatility: VolatilityImmutable,
+		},
 	},
 
 	Concat: {
@@ -3763,6 +3776,10 @@ func (expr *ColumnAccessExpr) Eval(ctx *EvalContext) (Datum, error) {
 	if err != nil {
 		return nil, err
 	}
+	// A field of a NULL value of a composite type is NULL.
+	if d == DNull {
+		return d, nil
+	}
 	return d.(*DTuple).D[expr.ColIndex], nil
 }
 
@@ -4106,6 +4123,11 @@ func (expr *UnaryExpr) Eval(ctx *EvalContext) (Datum, error) {
 	return res, err
 }
 
+// Eval implements the TypedExpr interface.
+func (expr *GroupingExpr) Eval(ctx *EvalContext) (Datum, error) {
+	return nil, errors.AssertionFailedf("unhandled type %T", expr)
+}
+
 // Eval implements the TypedExpr interface.
 func (expr DefaultVal) Eval(ctx *EvalContext) (Datum, error) {
 	return nil, errors.AssertionFailedf("unhandled type %T", expr)
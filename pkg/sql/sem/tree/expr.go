@@ -824,6 +824,24 @@ func (node *CoalesceExpr) Format(ctx *FmtCtx) {
 	ctx.WriteByte(')')
 }
 
+// GroupingExpr represents a GROUPING(...) expression, which returns a bit
+// mask indicating which of its arguments are not part of the grouping set of
+// the current row. The arguments must be grouping expressions of the
+// enclosing query.
+type GroupingExpr struct {
+	Exprs Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *GroupingExpr) Format(ctx *FmtCtx) {
+	ctx.WriteString("GROUPING(")
+	ctx.FormatNode(&node.Exprs)
+	ctx.WriteByte(')')
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (*GroupingExpr) ResolvedType() *types.T { return types.Int }
+
 // DefaultVal represents the DEFAULT expression.
 type DefaultVal struct{}
 
@@ -1787,6 +1805,7 @@ func (node *Exprs) String() string            { return AsString(node) }
 func (node *ArrayFlatten) String() string     { return AsString(node) }
 func (node *FuncExpr) String() string         { return AsString(node) }
 func (node *IfExpr) String() string           { return AsString(node) }
+func (node *GroupingExpr) String() string     { return AsString(node) }
 func (node *IfErrExpr) String() string        { return AsString(node) }
 func (node *IndexedVar) String() string       { return AsString(node) }
 func (node *IndirectionExpr) String() string  { return AsString(node) }
@@ -0,0 +1,74 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+// CreateExternalConnection represents a CREATE EXTERNAL CONNECTION statement.
+type CreateExternalConnection struct {
+	Name        Name
+	IfNotExists bool
+	URI         Expr
+}
+
+var _ Statement = &CreateExternalConnection{}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateExternalConnection) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE EXTERNAL CONNECTION ")
+	if node.IfNotExists {
+		ctx.WriteString("IF NOT EXISTS ")
+	}
+	ctx.FormatNode(&node.Name)
+	ctx.WriteString(" AS ")
+	ctx.FormatNode(node.URI)
+}
+
+// AlterExternalConnection represents an ALTER EXTERNAL CONNECTION statement.
+type AlterExternalConnection struct {
+	Name Name
+	URI  Expr
+}
+
+var _ Statement = &AlterExternalConnection{}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterExternalConnection) Format(ctx *FmtCtx) {
+	ctx.WriteString("ALTER EXTERNAL CONNECTION ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteString(" AS ")
+	ctx.FormatNode(node.URI)
+}
+
+// DropExternalConnection represents a DROP EXTERNAL CONNECTION statement.
+type DropExternalConnection struct {
+	Name     Name
+	IfExists bool
+}
+
+var _ Statement = &DropExternalConnection{}
+
+// Format implements the NodeFormatter interface.
+func (node *DropExternalConnection) Format(ctx *FmtCtx) {
+	ctx.WriteString("DROP EXTERNAL CONNECTION ")
+	if node.IfExists {
+		ctx.WriteString("IF EXISTS ")
+	}
+	ctx.FormatNode(&node.Name)
+}
+
+// ShowExternalConnections represents a SHOW EXTERNAL CONNECTIONS statement.
+type ShowExternalConnections struct{}
+
+var _ Statement = &ShowExternalConnections{}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowExternalConnections) Format(ctx *FmtCtx) {
+	ctx.WriteString("SHOW EXTERNAL CONNECTIONS")
+}
@@ -41,6 +41,10 @@ type TargetList struct {
 	Tables    TablePatterns
 	Tenant    roachpb.TenantID
 	Types     []*UnresolvedObjectName
+	// ExternalConnections is set for GRANT/REVOKE ON EXTERNAL CONNECTION.
+	ExternalConnections NameList
+	// Functions is set for GRANT/REVOKE ON FUNCTION.
+	Functions []*UnresolvedObjectName
 
 	// ForRoles and Roles are used internally in the parser and not used
 	// in the AST. Therefore they do not participate in pretty-printing,
@@ -72,6 +76,17 @@ func (tl *TargetList) Format(ctx *FmtCtx) {
 			}
 			ctx.FormatNode(typ)
 		}
+	} else if tl.ExternalConnections != nil {
+		ctx.WriteString("EXTERNAL CONNECTION ")
+		ctx.FormatNode(&tl.ExternalConnections)
+	} else if tl.Functions != nil {
+		ctx.WriteString("FUNCTION ")
+		for i, fn := range tl.Functions {
+			if i != 0 {
+				ctx.WriteString(", ")
+			}
+			ctx.FormatNode(fn)
+		}
 	} else {
 		ctx.WriteString("TABLE ")
 		ctx.FormatNode(&tl.Tables)
@@ -1190,6 +1190,9 @@ func (node *UpdateExpr) doc(p *PrettyCfg) pretty.Doc {
 	if node.Tuple {
 		d = p.bracket("(", d, ")")
 	}
+	if node.Field != "" {
+		d = pretty.Concat(d, pretty.Concat(pretty.Text("."), p.Doc(&node.Field)))
+	}
 	e := node.Expr
 	if p.Simplify {
 		e = StripParens(e)
@@ -1602,6 +1605,9 @@ func (node *LikeTableDef) doc(p *PrettyCfg) pretty.Doc {
 
 func (node *IndexElem) doc(p *PrettyCfg) pretty.Doc {
 	d := p.Doc(&node.Column)
+	if node.OpClass != "" {
+		d = pretty.ConcatSpace(d, p.Doc(&node.OpClass))
+	}
 	if node.Direction != DefaultDirection {
 		d = pretty.ConcatSpace(d, pretty.Keyword(node.Direction.String()))
 	}
@@ -1678,6 +1684,7 @@ func (node *UniqueConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 	//    [STORING ( ... )]
 	//    [INTERLEAVE ...]
 	//    [PARTITION BY ...]
+	//    [DEFERRABLE ...]
 	//    [WHERE ...]
 	//
 	// or (no constraint name):
@@ -1686,6 +1693,7 @@ func (node *UniqueConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 	//    [STORING ( ... )]
 	//    [INTERLEAVE ...]
 	//    [PARTITION BY ...]
+	//    [DEFERRABLE ...]
 	//    [WHERE ...]
 	//
 	clauses := make([]pretty.Doc, 0, 5)
@@ -1715,6 +1723,9 @@ func (node *UniqueConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 	if node.PartitionBy != nil {
 		clauses = append(clauses, p.Doc(node.PartitionBy))
 	}
+	if node.Deferrability != ConstraintNotDeferrable {
+		clauses = append(clauses, p.Doc(&node.Deferrability))
+	}
 	if node.Predicate != nil {
 		clauses = append(clauses, p.nestUnder(pretty.Keyword("WHERE"), p.Doc(node.Predicate)))
 	}
@@ -1732,6 +1743,7 @@ func (node *ForeignKeyConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 	//    REFERENCES tbl (...)
 	//    [MATCH ...]
 	//    [ACTIONS ...]
+	//    [DEFERRABLE ...]
 	//
 	// or (no constraint name):
 	//
@@ -1739,6 +1751,7 @@ func (node *ForeignKeyConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 	//    REFERENCES tbl [(...)]
 	//    [MATCH ...]
 	//    [ACTIONS ...]
+	//    [DEFERRABLE ...]
 	//
 	clauses := make([]pretty.Doc, 0, 4)
 	title := pretty.ConcatSpace(
@@ -1765,6 +1778,10 @@ func (node *ForeignKeyConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 		clauses = append(clauses, actions)
 	}
 
+	if node.Deferrability != ConstraintNotDeferrable {
+		clauses = append(clauses, p.Doc(&node.Deferrability))
+	}
+
 	return p.nestUnder(title, pretty.Group(pretty.Stack(clauses...)))
 }
 
@@ -1786,7 +1803,7 @@ func (node *ColumnTableDef) docRow(p *PrettyCfg) pretty.TableRow {
 	// Final layout:
 	// colname
 	//   type
-	//   [AS ( ... ) STORED]
+	//   [AS ( ... ) {STORED|VIRTUAL}]
 	//   [[CREATE [IF NOT EXISTS]] FAMILY [name]]
 	//   [[CONSTRAINT name] DEFAULT expr]
 	//   [[CONSTRAINT name] {NULL|NOT NULL}]
@@ -1808,8 +1825,12 @@ func (node *ColumnTableDef) docRow(p *PrettyCfg) pretty.TableRow {
 
 	// Compute expression (for computed columns).
 	if node.IsComputed() {
+		storage := ") STORED"
+		if node.IsVirtual() {
+			storage = ") VIRTUAL"
+		}
 		clauses = append(clauses, pretty.ConcatSpace(pretty.Keyword("AS"),
-			p.bracket("(", p.Doc(node.Computed.Expr), ") STORED"),
+			p.bracket("(", p.Doc(node.Computed.Expr), storage),
 		))
 	}
 
@@ -1853,6 +1874,9 @@ func (node *ColumnTableDef) docRow(p *PrettyCfg) pretty.TableRow {
 		pkConstraint = pretty.Keyword("PRIMARY KEY")
 	} else if node.Unique {
 		pkConstraint = pretty.Keyword("UNIQUE")
+		if node.UniqueDeferrability != ConstraintNotDeferrable {
+			pkConstraint = pretty.ConcatSpace(pkConstraint, p.Doc(&node.UniqueDeferrability))
+		}
 	}
 	if pkConstraint != pretty.Nil {
 		clauses = append(clauses, p.maybePrependConstraintName(&node.UniqueConstraintName, pkConstraint))
@@ -1874,7 +1898,7 @@ func (node *ColumnTableDef) docRow(p *PrettyCfg) pretty.TableRow {
 		if node.References.Col != "" {
 			fkHead = pretty.ConcatSpace(fkHead, p.bracket("(", p.Doc(&node.References.Col), ")"))
 		}
-		fkDetails := make([]pretty.Doc, 0, 2)
+		fkDetails := make([]pretty.Doc, 0, 3)
 		// We omit MATCH SIMPLE because it is the default.
 		if node.References.Match != MatchSimple {
 			fkDetails = append(fkDetails, pretty.Keyword(node.References.Match.String()))
@@ -1882,6 +1906,9 @@ func (node *ColumnTableDef) docRow(p *PrettyCfg) pretty.TableRow {
 		if ref := p.Doc(&node.References.Actions); ref != pretty.Nil {
 			fkDetails = append(fkDetails, ref)
 		}
+		if node.References.Deferrability != ConstraintNotDeferrable {
+			fkDetails = append(fkDetails, p.Doc(&node.References.Deferrability))
+		}
 		fk := fkHead
 		if len(fkDetails) > 0 {
 			fk = p.nestUnder(fk, pretty.Group(pretty.Stack(fkDetails...)))
@@ -1953,6 +1980,16 @@ func (node *ReferenceActions) doc(p *PrettyCfg) pretty.Doc {
 	return pretty.Fold(pretty.ConcatSpace, docs...)
 }
 
+func (node *ConstraintDeferrability) doc(p *PrettyCfg) pretty.Doc {
+	switch *node {
+	case ConstraintInitiallyImmediate:
+		return pretty.Keyword("DEFERRABLE")
+	case ConstraintInitiallyDeferred:
+		return pretty.ConcatSpace(pretty.Keyword("DEFERRABLE"), pretty.Keyword("INITIALLY DEFERRED"))
+	}
+	return pretty.Nil
+}
+
 func (node *Backup) doc(p *PrettyCfg) pretty.Doc {
 	items := make([]pretty.TableRow, 0, 6)
 
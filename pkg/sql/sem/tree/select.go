@@ -569,6 +569,48 @@ func (node *GroupBy) Format(ctx *FmtCtx) {
 	}
 }
 
+// GroupingSetType represents the type of a GroupingSet.
+type GroupingSetType int8
+
+const (
+	// RollupGroupingSet represents ROLLUP (...).
+	RollupGroupingSet GroupingSetType = iota
+	// CubeGroupingSet represents CUBE (...).
+	CubeGroupingSet
+	// ListGroupingSet represents GROUPING SETS (...).
+	ListGroupingSet
+)
+
+var groupingSetTypeName = [...]string{
+	RollupGroupingSet: "ROLLUP",
+	CubeGroupingSet:   "CUBE",
+	ListGroupingSet:   "GROUPING SETS",
+}
+
+func (t GroupingSetType) String() string {
+	return groupingSetTypeName[t]
+}
+
+// GroupingSet represents a ROLLUP, CUBE or GROUPING SETS element of a GROUP
+// BY clause. The elements of a ROLLUP or CUBE are expressions, where a tuple
+// stands for a group of columns that are added to or removed from the
+// grouping sets together. The elements of GROUPING SETS are themselves
+// GROUP BY elements, and may be GroupingSets.
+type GroupingSet struct {
+	Type  GroupingSetType
+	Exprs Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *GroupingSet) Format(ctx *FmtCtx) {
+	ctx.WriteString(node.Type.String())
+	ctx.WriteString(" (")
+	ctx.FormatNode(&node.Exprs)
+	ctx.WriteByte(')')
+}
+
+func (node *GroupingSet) String() string { return AsString(node) }
+
 // DistinctOn represents a DISTINCT ON clause.
 type DistinctOn []Expr
 
@@ -638,6 +680,16 @@ func (n NullsOrder) String() string {
 	return nullsOrderName[n]
 }
 
+// IsReversed returns whether the NULL ordering is the opposite of the default
+// one for the given direction. By default, NULLs sort first in ascending order
+// and last in descending order.
+func (n NullsOrder) IsReversed(dir Direction) bool {
+	if dir == Descending {
+		return n == NullsFirst
+	}
+	return n == NullsLast
+}
+
 // OrderType indicates which type of expression is used in ORDER BY.
 type OrderType int
 
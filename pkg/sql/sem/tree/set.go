@@ -74,6 +74,21 @@ func (node *SetTransaction) Format(ctx *FmtCtx) {
 	node.Modes.Format(ctx)
 }
 
+// SetConstraints represents a SET CONSTRAINTS ALL statement.
+type SetConstraints struct {
+	Deferred bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *SetConstraints) Format(ctx *FmtCtx) {
+	ctx.WriteString("SET CONSTRAINTS ALL ")
+	if node.Deferred {
+		ctx.WriteString("DEFERRED")
+	} else {
+		ctx.WriteString("IMMEDIATE")
+	}
+}
+
 // SetSessionAuthorizationDefault represents a SET SESSION AUTHORIZATION DEFAULT
 // statement. This can be extended (and renamed) if we ever support names in the
 // last position.
@@ -88,6 +88,10 @@ type ShowBackup struct {
 	Details              BackupDetails
 	ShouldIncludeSchemas bool
 	Options              KVOptions
+
+	// LocalityPaths are the locations of the other localities of a
+	// locality-aware backup whose default locality is at Path.
+	LocalityPaths Exprs
 }
 
 // Format implements the NodeFormatter interface.
@@ -106,7 +110,15 @@ func (node *ShowBackup) Format(ctx *FmtCtx) {
 	if node.ShouldIncludeSchemas {
 		ctx.WriteString("SCHEMAS ")
 	}
-	ctx.FormatNode(node.Path)
+	if len(node.LocalityPaths) > 0 {
+		ctx.WriteByte('(')
+		ctx.FormatNode(node.Path)
+		ctx.WriteString(", ")
+		ctx.FormatNode(&node.LocalityPaths)
+		ctx.WriteByte(')')
+	} else {
+		ctx.FormatNode(node.Path)
+	}
 	if node.InCollection != nil {
 		ctx.WriteString(" IN ")
 		ctx.FormatNode(node.InCollection)
@@ -163,6 +163,7 @@ type CCLOnlyStatement interface {
 }
 
 var _ CCLOnlyStatement = &Backup{}
+var _ CCLOnlyStatement = &CompactBackup{}
 var _ CCLOnlyStatement = &ShowBackup{}
 var _ CCLOnlyStatement = &Restore{}
 var _ CCLOnlyStatement = &CreateChangefeed{}
@@ -218,6 +219,22 @@ func (*AlterType) StatementTag() string { return "ALTER TYPE" }
 
 func (*AlterType) hiddenFromShowQueries() {}
 
+// StatementType implements the Statement interface.
+func (*AlterDomain) StatementType() StatementType { return DDL }
+
+// StatementTag implements the Statement interface.
+func (*AlterDomain) StatementTag() string { return "ALTER DOMAIN" }
+
+func (*AlterDomain) hiddenFromShowQueries() {}
+
+// StatementType implements the Statement interface.
+func (*AlterExternalConnection) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterExternalConnection) StatementTag() string { return "ALTER EXTERNAL CONNECTION" }
+
+func (*AlterExternalConnection) hiddenFromShowQueries() {}
+
 // StatementType implements the Statement interface.
 func (*AlterSequence) StatementType() StatementType { return DDL }
 
@@ -250,6 +267,16 @@ func (*Backup) cclOnlyStatement() {}
 
 func (*Backup) hiddenFromShowQueries() {}
 
+// StatementType implements the Statement interface.
+func (*CompactBackup) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CompactBackup) StatementTag() string { return "BACKUP COMPACT" }
+
+func (*CompactBackup) cclOnlyStatement() {}
+
+func (*CompactBackup) hiddenFromShowQueries() {}
+
 // StatementType implements the Statement interface.
 func (*ScheduledBackup) StatementType() StatementType { return Rows }
 
@@ -308,6 +335,17 @@ func (*CannedOptPlan) StatementType() StatementType { return Rows }
 // StatementTag returns a short string identifying the type of statement.
 func (*CannedOptPlan) StatementTag() string { return "PREPARE AS OPT PLAN" }
 
+// StatementType implements the Statement interface.
+func (*CloseCursor) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (n *CloseCursor) StatementTag() string {
+	if n.All {
+		return "CLOSE CURSOR ALL"
+	}
+	return "CLOSE CURSOR"
+}
+
 // StatementType implements the Statement interface.
 func (*CommentOnColumn) StatementType() StatementType { return DDL }
 
@@ -369,6 +407,14 @@ func (*CreateExtension) StatementType() StatementType { return Ack }
 // StatementTag returns a short string identifying the type of statement.
 func (*CreateExtension) StatementTag() string { return "CREATE EXTENSION" }
 
+// StatementType implements the Statement interface.
+func (*CreateExternalConnection) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateExternalConnection) StatementTag() string { return "CREATE EXTERNAL CONNECTION" }
+
+func (*CreateExternalConnection) hiddenFromShowQueries() {}
+
 // StatementType implements the Statement interface.
 func (*CreateIndex) StatementType() StatementType { return DDL }
 
@@ -404,10 +450,29 @@ func (*CreateTable) modifiesSchema() bool { return true }
 func (*CreateType) StatementType() StatementType { return DDL }
 
 // StatementTag implements the Statement interface.
-func (*CreateType) StatementTag() string { return "CREATE TYPE" }
+func (n *CreateType) StatementTag() string {
+	if n.Variety == Domain {
+		return "CREATE DOMAIN"
+	}
+	return "CREATE TYPE"
+}
 
 func (*CreateType) modifiesSchema() bool { return true }
 
+// StatementType implements the Statement interface.
+func (*CreateFunction) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateFunction) StatementTag() string { return "CREATE FUNCTION" }
+
+func (*CreateFunction) modifiesSchema() bool { return true }
+
+// StatementType implements the Statement interface.
+func (*CreateTrigger) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateTrigger) StatementTag() string { return "CREATE TRIGGER" }
+
 // StatementType implements the Statement interface.
 func (*CreateRole) StatementType() StatementType { return Ack }
 
@@ -446,6 +511,12 @@ func (n *Deallocate) StatementTag() string {
 	return "DEALLOCATE"
 }
 
+// StatementType implements the Statement interface.
+func (*DeclareCursor) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DeclareCursor) StatementTag() string { return "DECLARE CURSOR" }
+
 // StatementType implements the Statement interface.
 func (*Discard) StatementType() StatementType { return Ack }
 
@@ -502,7 +573,30 @@ func (*DropRole) hiddenFromShowQueries() {}
 func (*DropType) StatementType() StatementType { return DDL }
 
 // StatementTag returns a short string identifying the type of statement.
-func (*DropType) StatementTag() string { return "DROP TYPE" }
+func (n *DropType) StatementTag() string {
+	if n.IsDomain {
+		return "DROP DOMAIN"
+	}
+	return "DROP TYPE"
+}
+
+// StatementType implements the Statement interface.
+func (*DropFunction) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropFunction) StatementTag() string { return "DROP FUNCTION" }
+
+// StatementType implements the Statement interface.
+func (*DropTrigger) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropTrigger) StatementTag() string { return "DROP TRIGGER" }
+
+// StatementType implements the Statement interface.
+func (*DropExternalConnection) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropExternalConnection) StatementTag() string { return "DROP EXTERNAL CONNECTION" }
 
 // StatementType implements the Statement interface.
 func (*DropSchema) StatementType() StatementType { return DDL }
@@ -536,6 +630,12 @@ func (*Export) cclOnlyStatement() {}
 // StatementTag returns a short string identifying the type of statement.
 func (*Export) StatementTag() string { return "EXPORT" }
 
+// StatementType implements the Statement interface.
+func (*FetchCursor) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*FetchCursor) StatementTag() string { return "FETCH" }
+
 // StatementType implements the Statement interface.
 func (*Grant) StatementType() StatementType { return DDL }
 
@@ -562,6 +662,12 @@ func (*Import) StatementTag() string { return "IMPORT" }
 
 func (*Import) cclOnlyStatement() {}
 
+// StatementType implements the Statement interface.
+func (*MoveCursor) StatementType() StatementType { return RowsAffected }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*MoveCursor) StatementTag() string { return "MOVE" }
+
 // StatementType implements the Statement interface.
 func (*ParenSelect) StatementType() StatementType { return Rows }
 
@@ -716,6 +822,12 @@ func (*SetClusterSetting) StatementType() StatementType { return Ack }
 // StatementTag returns a short string identifying the type of statement.
 func (*SetClusterSetting) StatementTag() string { return "SET CLUSTER SETTING" }
 
+// StatementType implements the Statement interface.
+func (*SetConstraints) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*SetConstraints) StatementTag() string { return "SET CONSTRAINTS" }
+
 // StatementType implements the Statement interface.
 func (*SetTransaction) StatementType() StatementType { return Ack }
 
@@ -877,6 +989,12 @@ func (*ShowSchedules) StatementType() StatementType { return Rows }
 // StatementTag returns a short string identifying the type of statement.
 func (*ShowSchedules) StatementTag() string { return "SHOW SCHEDULES" }
 
+// StatementType implements the Statement interface.
+func (*ShowExternalConnections) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowExternalConnections) StatementTag() string { return "SHOW EXTERNAL CONNECTIONS" }
+
 // StatementType implements the Statement interface.
 func (*ShowSyntax) StatementType() StatementType { return Rows }
 
@@ -1030,17 +1148,21 @@ func (n *AlterTableSetDefault) String() string           { return AsString(n) }
 func (n *AlterTableSetNotNull) String() string           { return AsString(n) }
 func (n *AlterTableSetSchema) String() string            { return AsString(n) }
 func (n *AlterType) String() string                      { return AsString(n) }
+func (n *AlterDomain) String() string                    { return AsString(n) }
+func (n *AlterExternalConnection) String() string        { return AsString(n) }
 func (n *AlterRole) String() string                      { return AsString(n) }
 func (n *AlterSequence) String() string                  { return AsString(n) }
 func (n *Analyze) String() string                        { return AsString(n) }
 func (n *Backup) String() string                         { return AsString(n) }
 func (n *BeginTransaction) String() string               { return AsString(n) }
+func (n *CompactBackup) String() string                  { return AsString(n) }
 func (n *ControlJobs) String() string                    { return AsString(n) }
 func (n *ControlSchedules) String() string               { return AsString(n) }
 func (n *ControlJobsForSchedules) String() string        { return AsString(n) }
 func (n *CancelQueries) String() string                  { return AsString(n) }
 func (n *CancelSessions) String() string                 { return AsString(n) }
 func (n *CannedOptPlan) String() string                  { return AsString(n) }
+func (n *CloseCursor) String() string                    { return AsString(n) }
 func (n *CommentOnColumn) String() string                { return AsString(n) }
 func (n *CommentOnDatabase) String() string              { return AsString(n) }
 func (n *CommentOnIndex) String() string                 { return AsString(n) }
@@ -1050,20 +1172,27 @@ func (n *CopyFrom) String() string                       { return AsString(n) }
 func (n *CreateChangefeed) String() string               { return AsString(n) }
 func (n *CreateDatabase) String() string                 { return AsString(n) }
 func (n *CreateExtension) String() string                { return AsString(n) }
+func (n *CreateExternalConnection) String() string       { return AsString(n) }
+func (n *CreateFunction) String() string                 { return AsString(n) }
 func (n *CreateIndex) String() string                    { return AsString(n) }
 func (n *CreateRole) String() string                     { return AsString(n) }
 func (n *CreateTable) String() string                    { return AsString(n) }
+func (n *CreateTrigger) String() string                  { return AsString(n) }
 func (n *CreateSchema) String() string                   { return AsString(n) }
 func (n *CreateSequence) String() string                 { return AsString(n) }
 func (n *CreateStats) String() string                    { return AsString(n) }
 func (n *CreateView) String() string                     { return AsString(n) }
 func (n *Deallocate) String() string                     { return AsString(n) }
+func (n *DeclareCursor) String() string                  { return AsString(n) }
 func (n *Delete) String() string                         { return AsString(n) }
 func (n *DropDatabase) String() string                   { return AsString(n) }
+func (n *DropFunction) String() string                   { return AsString(n) }
 func (n *DropIndex) String() string                      { return AsString(n) }
 func (n *DropSchema) String() string                     { return AsString(n) }
 func (n *DropTable) String() string                      { return AsString(n) }
+func (n *DropTrigger) String() string                    { return AsString(n) }
 func (n *DropType) String() string                       { return AsString(n) }
+func (n *DropExternalConnection) String() string         { return AsString(n) }
 func (n *DropView) String() string                       { return AsString(n) }
 func (n *DropSequence) String() string                   { return AsString(n) }
 func (n *DropRole) String() string                       { return AsString(n) }
@@ -1071,10 +1200,12 @@ func (n *Execute) String() string                        { return AsString(n) }
 func (n *Explain) String() string                        { return AsString(n) }
 func (n *ExplainAnalyzeDebug) String() string            { return AsString(n) }
 func (n *Export) String() string                         { return AsString(n) }
+func (n *FetchCursor) String() string                    { return AsString(n) }
 func (n *Grant) String() string                          { return AsString(n) }
 func (n *GrantRole) String() string                      { return AsString(n) }
 func (n *Insert) String() string                         { return AsString(n) }
 func (n *Import) String() string                         { return AsString(n) }
+func (n *MoveCursor) String() string                     { return AsString(n) }
 func (n *ParenSelect) String() string                    { return AsString(n) }
 func (n *Prepare) String() string                        { return AsString(n) }
 func (n *ReassignOwnedBy) String() string                { return AsString(n) }
@@ -1098,6 +1229,7 @@ func (n *Scrub) String() string                          { return AsString(n) }
 func (n *Select) String() string                         { return AsString(n) }
 func (n *SelectClause) String() string                   { return AsString(n) }
 func (n *SetClusterSetting) String() string              { return AsString(n) }
+func (n *SetConstraints) String() string                 { return AsString(n) }
 func (n *SetZoneConfig) String() string                  { return AsString(n) }
 func (n *SetSessionAuthorizationDefault) String() string { return AsString(n) }
 func (n *SetSessionCharacteristics) String() string      { return AsString(n) }
@@ -1116,6 +1248,7 @@ func (n *ShowEnums) String() string                      { return AsString(n) }
 func (n *ShowGrants) String() string                     { return AsString(n) }
 func (n *ShowHistogram) String() string                  { return AsString(n) }
 func (n *ShowSchedules) String() string                  { return AsString(n) }
+func (n *ShowExternalConnections) String() string        { return AsString(n) }
 func (n *ShowIndexes) String() string                    { return AsString(n) }
 func (n *ShowPartitions) String() string                 { return AsString(n) }
 func (n *ShowJobs) String() string                       { return AsString(n) }
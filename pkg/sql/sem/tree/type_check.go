@@ -422,6 +422,28 @@ func isCastDeepValid(castFrom, castTo *types.T) (bool, telemetry.Counter, Volati
 	case toFamily == types.EnumFamily && fromFamily == types.EnumFamily:
 		// Casts from ENUM to ENUM type can only succeed if the two enums
 		return castFrom.Equivalent(castTo), sqltelemetry.EnumCastCounter, VolatilityImmutable
+	case toFamily == types.TupleFamily && fromFamily == types.TupleFamily && castTo.IsComposite():
+		// A tuple can be cast to a composite type with the same number of
+		// fields if each of its elements can be cast to the type of the
+		// corresponding field.
+		fromTypes, toTypes := castFrom.TupleContents(), castTo.TupleContents()
+		if len(fromTypes) != len(toTypes) {
+			return false, nil, 0
+		}
+		volatility := VolatilityLeakProof
+		for i := range fromTypes {
+			if fromTypes[i].Family() == types.UnknownFamily {
+				continue
+			}
+			ok, _, v := isCastDeepValid(fromTypes[i], toTypes[i])
+			if !ok {
+				return false, nil, 0
+			}
+			if v > volatility {
+				volatility = v
+			}
+		}
+		return true, sqltelemetry.CompositeCastCounter, volatility
 	}
 
 	cast := lookupCast(fromFamily, toFamily)
@@ -1354,6 +1376,31 @@ func (expr *UnaryExpr) TypeCheck(
 	return expr, nil
 }
 
+// TypeCheck implements the Expr interface.
+func (expr *GroupingExpr) TypeCheck(
+	ctx context.Context, semaCtx *SemaContext, desired *types.T,
+) (TypedExpr, error) {
+	if semaCtx != nil && semaCtx.Properties.required.rejectFlags&RejectAggregates != 0 {
+		return nil, pgerror.Newf(pgcode.Grouping,
+			"grouping operations are not allowed in %s", semaCtx.Properties.required.context)
+	}
+	for i, subExpr := range expr.Exprs {
+		typedExpr, err := subExpr.TypeCheck(ctx, semaCtx, types.Any)
+		if err != nil {
+			return nil, err
+		}
+		expr.Exprs[i] = typedExpr
+	}
+	return expr, nil
+}
+
+// TypeCheck implements the Expr interface.
+func (node *GroupingSet) TypeCheck(
+	_ context.Context, _ *SemaContext, desired *types.T,
+) (TypedExpr, error) {
+	return nil, pgerror.Newf(pgcode.Syntax, "%s can only appear in GROUP BY", node.Type)
+}
+
 // TypeCheck implements the Expr interface.
 func (expr DefaultVal) TypeCheck(
 	_ context.Context, _ *SemaContext, desired *types.T,
@@ -1422,10 +1469,32 @@ func (expr *Tuple) TypeCheck(
 			labels[i] = lex.NormalizeName(expr.Labels[i])
 		}
 	}
+	// An unlabeled tuple whose elements have the types of the fields of a
+	// desired composite type takes on the composite type, so that the tuple
+	// can be used as a value of the composite type without a cast.
+	if desired.IsComposite() && labels == nil && tupleContentsMatch(contents, desired.TupleContents()) {
+		expr.typ = desired
+		return expr, nil
+	}
 	expr.typ = types.MakeLabeledTuple(contents, labels)
 	return expr, nil
 }
 
+// tupleContentsMatch returns whether the given element types are equivalent to
+// the given field types, treating NULL elements as equivalent to any field
+// type.
+func tupleContentsMatch(contents, fields []*types.T) bool {
+	if len(contents) != len(fields) {
+		return false
+	}
+	for i := range contents {
+		if contents[i].Family() != types.UnknownFamily && !contents[i].Equivalent(fields[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 var errAmbiguousArrayType = pgerror.Newf(pgcode.IndeterminateDatatype, "cannot determine type of empty array. "+
 	"Consider annotating with the desired type, for example ARRAY[]:::int[]")
 
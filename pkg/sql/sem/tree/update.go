@@ -77,6 +77,9 @@ func (node *UpdateExprs) Format(ctx *FmtCtx) {
 type UpdateExpr struct {
 	Tuple bool
 	Names NameList
+	// Field, if set, is the field of the composite-typed column in Names that
+	// is assigned by this expression.
+	Field Name
 	Expr  Expr
 }
 
@@ -89,6 +92,10 @@ func (node *UpdateExpr) Format(ctx *FmtCtx) {
 	ctx.WriteString(open)
 	ctx.FormatNode(&node.Names)
 	ctx.WriteString(close)
+	if node.Field != "" {
+		ctx.WriteByte('.')
+		ctx.FormatNode(&node.Field)
+	}
 	ctx.WriteString(" = ")
 	ctx.FormatNode(node.Expr)
 }
@@ -572,6 +572,26 @@ func (expr *Tuple) Walk(v Visitor) Expr {
 	return expr
 }
 
+// Walk implements the Expr interface.
+func (expr *GroupingExpr) Walk(v Visitor) Expr {
+	if exprs, changed := walkExprSlice(v, expr.Exprs); changed {
+		exprCopy := *expr
+		exprCopy.Exprs = exprs
+		return &exprCopy
+	}
+	return expr
+}
+
+// Walk implements the Expr interface.
+func (node *GroupingSet) Walk(v Visitor) Expr {
+	if exprs, changed := walkExprSlice(v, node.Exprs); changed {
+		nodeCopy := *node
+		nodeCopy.Exprs = exprs
+		return &nodeCopy
+	}
+	return node
+}
+
 // Walk implements the Expr interface.
 func (expr *Array) Walk(v Visitor) Expr {
 	if exprs, changed := walkExprSlice(v, expr.Exprs); changed {
@@ -1081,6 +1101,16 @@ func (stmt *Restore) walkStmt(v Visitor) Statement {
 			ret.Options.IntoDB = intoDB
 		}
 	}
+
+	if stmt.Options.NewDBName != nil {
+		newDBName, changed := WalkExpr(v, stmt.Options.NewDBName)
+		if changed {
+			if ret == stmt {
+				ret = stmt.copyNode()
+			}
+			ret.Options.NewDBName = newDBName
+		}
+	}
 	return ret
 }
 
@@ -44,6 +44,7 @@ type WindowFrameRun struct {
 	FilterColIdx     int
 	OrdColIdx        int                // Column over which rows are ordered within the partition. It is only required in RANGE mode.
 	OrdDirection     encoding.Direction // Direction of the ordering over OrdColIdx.
+	OrdNullsReversed bool               // Whether NULLs are ordered opposite to OrdDirection's default.
 	PlusOp, MinusOp  *BinOp             // Binary operators for addition and subtraction required only in RANGE mode.
 	PeerHelper       PeerGroupsIndicesHelper
 
@@ -105,6 +106,16 @@ func (wfr *WindowFrameRun) getValueByOffset(
 	return binOp.Fn(evalCtx, value, offset)
 }
 
+// compareOrdValues compares two values of the column over which rows are
+// ordered, taking into account the NULL ordering of that column.
+func (wfr *WindowFrameRun) compareOrdValues(evalCtx *EvalContext, a, b Datum) int {
+	cmp := a.Compare(evalCtx, b)
+	if wfr.OrdNullsReversed && (a == DNull) != (b == DNull) {
+		cmp = -cmp
+	}
+	return cmp
+}
+
 // FrameStartIdx returns the index of starting row in the frame (which is the first to be included).
 func (wfr *WindowFrameRun) FrameStartIdx(ctx context.Context, evalCtx *EvalContext) (int, error) {
 	if wfr.Frame == nil {
@@ -133,7 +144,7 @@ func (wfr *WindowFrameRun) FrameStartIdx(ctx context.Context, evalCtx *EvalConte
 						wfr.err = err
 						return false
 					}
-					return valueAt.Compare(evalCtx, value) <= 0
+					return wfr.compareOrdValues(evalCtx, valueAt, value) <= 0
 				}), wfr.err
 			}
 			// We use binary search on [0, wfr.RowIdx) interval to find the first row
@@ -148,7 +159,7 @@ func (wfr *WindowFrameRun) FrameStartIdx(ctx context.Context, evalCtx *EvalConte
 					wfr.err = err
 					return false
 				}
-				return valueAt.Compare(evalCtx, value) >= 0
+				return wfr.compareOrdValues(evalCtx, valueAt, value) >= 0
 			}), wfr.err
 		case CurrentRow:
 			// Spec: in RANGE mode CURRENT ROW means that the frame starts with the current row's first peer.
@@ -170,7 +181,7 @@ func (wfr *WindowFrameRun) FrameStartIdx(ctx context.Context, evalCtx *EvalConte
 						wfr.err = err
 						return false
 					}
-					return valueAt.Compare(evalCtx, value) <= 0
+					return wfr.compareOrdValues(evalCtx, valueAt, value) <= 0
 				}), wfr.err
 			}
 			// We use binary search on [0, wfr.PartitionSize()) interval to find the
@@ -184,7 +195,7 @@ func (wfr *WindowFrameRun) FrameStartIdx(ctx context.Context, evalCtx *EvalConte
 					wfr.err = err
 					return false
 				}
-				return valueAt.Compare(evalCtx, value) >= 0
+				return wfr.compareOrdValues(evalCtx, valueAt, value) >= 0
 			}), wfr.err
 		default:
 			return 0, errors.AssertionFailedf(
@@ -301,7 +312,7 @@ func (wfr *WindowFrameRun) FrameEndIdx(ctx context.Context, evalCtx *EvalContext
 						wfr.err = err
 						return false
 					}
-					return valueAt.Compare(evalCtx, value) < 0
+					return wfr.compareOrdValues(evalCtx, valueAt, value) < 0
 				}), wfr.err
 			}
 			// We use binary search on [0, wfr.PartitionSize()) interval to find
@@ -318,7 +329,7 @@ func (wfr *WindowFrameRun) FrameEndIdx(ctx context.Context, evalCtx *EvalContext
 					wfr.err = err
 					return false
 				}
-				return valueAt.Compare(evalCtx, value) > 0
+				return wfr.compareOrdValues(evalCtx, valueAt, value) > 0
 			}), wfr.err
 		case CurrentRow:
 			// Spec: in RANGE mode CURRENT ROW means that the frame end with the current row's last peer.
@@ -340,7 +351,7 @@ func (wfr *WindowFrameRun) FrameEndIdx(ctx context.Context, evalCtx *EvalContext
 						wfr.err = err
 						return false
 					}
-					return valueAt.Compare(evalCtx, value) < 0
+					return wfr.compareOrdValues(evalCtx, valueAt, value) < 0
 				}), wfr.err
 			}
 			// We use binary search on [0, wfr.PartitionSize()) interval to find
@@ -354,7 +365,7 @@ func (wfr *WindowFrameRun) FrameEndIdx(ctx context.Context, evalCtx *EvalContext
 					wfr.err = err
 					return false
 				}
-				return valueAt.Compare(evalCtx, value) > 0
+				return wfr.compareOrdValues(evalCtx, valueAt, value) > 0
 			}), wfr.err
 		case UnboundedFollowing:
 			return wfr.unboundedFollowing(), nil
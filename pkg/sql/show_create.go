@@ -180,6 +180,8 @@ func ShowCreateTable(
 			); err != nil {
 				return "", err
 			}
+			deferrability := idx.UniqueDeferrability()
+			f.FormatNode(&deferrability)
 		}
 	}
 
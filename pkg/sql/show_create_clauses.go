@@ -214,6 +214,12 @@ func showForeignKeyConstraint(
 		buf.WriteString(" ON UPDATE ")
 		buf.WriteString(fk.OnUpdate.String())
 	}
+	if fk.Deferrable {
+		buf.WriteString(" DEFERRABLE")
+		if fk.InitiallyDeferred {
+			buf.WriteString(" INITIALLY DEFERRED")
+		}
+	}
 	return nil
 }
 
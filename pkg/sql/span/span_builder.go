@@ -318,7 +318,9 @@ func (s *Builder) encodeConstraintKey(
 			}
 			key = keys[0]
 		} else {
-			key, err = rowenc.EncodeTableKey(key, val, dir)
+			key, err = rowenc.EncodeTableKeyWithNullsOrder(
+				key, val, dir, s.index.ColumnNullsReversedAt(i),
+			)
 			if err != nil {
 				return nil, false, err
 			}
@@ -0,0 +1,647 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/cancelchecker"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// sqlCursor is a cursor created by a DECLARE statement. The query of the
+// cursor is planned when the cursor is declared, but only runs as FETCH and
+// MOVE statements need its rows: it runs in its own goroutine, which is
+// suspended after each row until the next row is pulled, the same way a flow
+// is suspended between the executions of a pgwire portal. The query runs in a
+// leaf of the session's transaction created by the DECLARE statement, so the
+// cursor only sees the rows visible to the DECLARE statement and is
+// insensitive to the changes made by the transaction afterwards.
+type sqlCursor struct {
+	name      string
+	statement string
+	created   time.Time
+
+	cols colinfo.ResultColumns
+
+	// stmt is the query of the cursor and p is the planner it was planned with.
+	stmt Statement
+	p    *planner
+
+	// txn is the leaf transaction the query runs in. readTS is its read
+	// timestamp; rootTxn is the session's transaction it was created from.
+	txn     *kv.Txn
+	rootTxn *kv.Txn
+	readTS  hlc.Timestamp
+	tracing *SessionTracing
+
+	// ctx is the context the query runs with; cancel cancels it. The memory
+	// used by the query and by the rows buffered in ahead is accounted for by
+	// mon.
+	ctx    context.Context
+	cancel context.CancelFunc
+	mon    *mon.BytesMonitor
+	acc    mon.BoundAccount
+
+	// started is set once the goroutine running the query is started, and done
+	// once all the rows produced by the query have been read. err is the error
+	// of the query, valid once done is set.
+	started bool
+	done    bool
+	err     error
+	// pullCh resumes the suspended query: true asks it for another row, false
+	// stops it. rowCh carries the rows it produces and is closed once it
+	// finishes.
+	pullCh chan bool
+	rowCh  chan tree.Datums
+
+	// numRows is the number of rows produced by the query, or -1 if the query
+	// has not produced all of its rows yet.
+	numRows int64
+
+	// pos is the position of the cursor: 0 before the first row, n when the
+	// cursor is on the n'th row, and the number of rows plus one after the
+	// last row.
+	pos int64
+	// cur is the row the cursor is on, or nil if it is before the first row or
+	// after the last row, or if the row was skipped.
+	cur tree.Datums
+	// skipped and ahead are the rows past the position of the cursor that were
+	// already pulled from the query to find out its number of rows. The first
+	// skipped rows were dropped, so the cursor can only move over them; they
+	// are followed by the rows in ahead.
+	skipped int64
+	ahead   []tree.Datums
+}
+
+// cursorRowSize returns the memory accounted for a row buffered by a cursor.
+func cursorRowSize(row tree.Datums) int64 {
+	var sz int64
+	for _, d := range row {
+		sz += int64(d.Size())
+	}
+	return sz
+}
+
+// next moves the cursor to the next row. It returns false if the cursor moved
+// past the last row.
+func (c *sqlCursor) next(ctx context.Context) (bool, error) {
+	if c.numRows >= 0 && c.pos > c.numRows {
+		return false, nil
+	}
+	c.pos++
+	c.cur = nil
+	if c.numRows >= 0 && c.pos > c.numRows {
+		return false, nil
+	}
+	if c.skipped > 0 {
+		c.skipped--
+		return true, nil
+	}
+	if len(c.ahead) > 0 {
+		c.cur = c.ahead[0]
+		c.ahead[0] = nil
+		c.ahead = c.ahead[1:]
+		c.acc.Shrink(ctx, cursorRowSize(c.cur))
+		return true, nil
+	}
+	row, ok, err := c.pull(ctx)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		c.numRows = c.pos - 1
+		return false, nil
+	}
+	c.cur = row
+	return true, nil
+}
+
+// readToEnd pulls the remaining rows of the query so that its number of rows
+// is known. Only the last k rows are kept for the cursor to move onto.
+func (c *sqlCursor) readToEnd(ctx context.Context, k int64) error {
+	for c.numRows < 0 {
+		row, ok, err := c.pull(ctx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			c.numRows = c.pos + c.skipped + int64(len(c.ahead))
+			return nil
+		}
+		if err := c.acc.Grow(ctx, cursorRowSize(row)); err != nil {
+			return err
+		}
+		c.ahead = append(c.ahead, row)
+		if int64(len(c.ahead)) > k {
+			c.acc.Shrink(ctx, cursorRowSize(c.ahead[0]))
+			c.ahead[0] = nil
+			c.ahead = c.ahead[1:]
+			c.skipped++
+		}
+	}
+	return nil
+}
+
+// pull returns the next row produced by the query of the cursor, starting the
+// query if it is not running yet. It returns false once the query has produced
+// all of its rows.
+func (c *sqlCursor) pull(ctx context.Context) (tree.Datums, bool, error) {
+	if c.done {
+		return nil, false, c.err
+	}
+	if c.readTS.Less(c.rootTxn.ReadTimestamp()) {
+		// The transaction now reads at a later timestamp than the query of the
+		// cursor, whose remaining rows might not be consistent with it.
+		return nil, false, c.rootTxn.GenerateForcedRetryableError(
+			ctx, "cursor read timestamp is behind the transaction read timestamp")
+	}
+	if !c.started {
+		c.started = true
+		go c.runQuery()
+	} else {
+		c.pullCh <- true
+	}
+	select {
+	case row, ok := <-c.rowCh:
+		if !ok {
+			c.done = true
+			return nil, false, c.err
+		}
+		return row, true, nil
+	case <-ctx.Done():
+		c.stop(true /* cancel */)
+		c.err = cancelchecker.QueryCanceledError
+		return nil, false, c.err
+	}
+}
+
+// runQuery runs the query of the cursor, sending the rows it produces on
+// rowCh. It runs in its own goroutine and closes rowCh when it returns.
+func (c *sqlCursor) runQuery() {
+	defer close(c.rowCh)
+	ctx, p := c.ctx, c.p
+	defer p.curPlan.close(ctx)
+
+	w := &cursorRowWriter{c: c}
+	defer func() { c.err = w.err }()
+	recv := MakeDistSQLReceiver(
+		ctx, w, tree.Rows,
+		p.execCfg.RangeDescriptorCache,
+		c.rootTxn,
+		func(ts hlc.Timestamp) {
+			p.execCfg.Clock.Update(ts)
+		},
+		c.tracing,
+	)
+	defer recv.Release()
+
+	// The query runs on the gateway only, so that it can be suspended
+	// between rows.
+	dsp := p.execCfg.DistSQLPlanner
+	evalCtx := p.ExtendedEvalContext()
+	planCtx := dsp.NewPlanningCtx(ctx, evalCtx, p, p.txn, false /* distribute */)
+	planCtx.stmtType = recv.stmtType
+	if len(p.curPlan.subqueryPlans) != 0 {
+		evalCtxFactory := func() *extendedEvalContext {
+			return p.ExtendedEvalContextCopy()
+		}
+		if !dsp.PlanAndRunSubqueries(
+			ctx, p, evalCtxFactory, p.curPlan.subqueryPlans, recv, false, /* maybeDistribute */
+		) {
+			return
+		}
+	}
+	dsp.PlanAndRun(ctx, evalCtx, planCtx, p.txn, p.curPlan.main, recv)()
+}
+
+// stop stops the query of the cursor if it is running. If cancel is set, its
+// context is canceled; otherwise it is asked to stop once it produces its next
+// row, which it only does after it was pulled.
+func (c *sqlCursor) stop(cancel bool) {
+	if !c.started || c.done {
+		return
+	}
+	if cancel {
+		c.cancel()
+	} else {
+		c.pullCh <- false
+	}
+	for range c.rowCh {
+	}
+	c.done = true
+}
+
+// updateRootTxn imports the state of the leaf transaction of the cursor into
+// the session's transaction, so that the reads performed by the query so far
+// are refreshed along with the other reads of the transaction.
+func (c *sqlCursor) updateRootTxn(ctx context.Context) error {
+	if !c.started {
+		return nil
+	}
+	tfs, err := c.txn.GetLeafTxnFinalState(ctx)
+	if err != nil {
+		return err
+	}
+	return c.rootTxn.UpdateRootWithLeafFinalState(ctx, &tfs)
+}
+
+// close releases the resources held by the cursor, stopping its query.
+func (c *sqlCursor) close(ctx context.Context) {
+	if c.started {
+		c.stop(false /* cancel */)
+	} else if c.p != nil {
+		c.p.curPlan.close(ctx)
+	}
+	c.cancel()
+	c.ahead = nil
+	c.acc.Close(ctx)
+	c.mon.Stop(ctx)
+}
+
+// movement returns how a FETCH or MOVE statement in the given direction moves
+// the cursor: it moves over skip rows, then returns the next count rows, or all
+// the remaining rows if count is negative. If current is set, the statement
+// instead returns the row the cursor is on without moving it.
+func (c *sqlCursor) movement(
+	ctx context.Context, s *tree.CursorStmt,
+) (skip, count int64, current bool, err error) {
+	target := c.pos
+	switch s.FetchType {
+	case tree.FetchNormal:
+		if s.Count >= 0 {
+			return 0, s.Count, s.Count == 0, nil
+		}
+		target = -1
+	case tree.FetchAll:
+		return 0, -1, false, nil
+	case tree.FetchBackwardAll:
+		target = -1
+	case tree.FetchRelative:
+		target = c.pos + s.Count
+	case tree.FetchAbsolute:
+		target = s.Count
+		if s.Count < 0 {
+			if err := c.readToEnd(ctx, -s.Count); err != nil {
+				return 0, 0, false, err
+			}
+			target = c.numRows + 1 + s.Count
+		}
+	case tree.FetchFirst:
+		target = 1
+	case tree.FetchLast:
+		if err := c.readToEnd(ctx, 1); err != nil {
+			return 0, 0, false, err
+		}
+		target = c.numRows
+	default:
+		return 0, 0, false, errors.AssertionFailedf("unknown fetch type %d", s.FetchType)
+	}
+	switch {
+	case target < c.pos:
+		return 0, 0, false, errors.WithHint(
+			pgerror.New(pgcode.ObjectNotInPrerequisiteState, "cursor can only scan forward"),
+			"Declare it with SCROLL option to enable backward scan.",
+		)
+	case target == c.pos:
+		return 0, 0, true, nil
+	default:
+		return target - c.pos - 1, 1, false, nil
+	}
+}
+
+// cursorMap contains the cursors declared in a transaction. Cursors are
+// closed when the transaction finishes.
+type cursorMap struct {
+	cursors map[string]*sqlCursor
+}
+
+// get returns the cursor with the given name, or an error if there is no such
+// cursor.
+func (m *cursorMap) get(name tree.Name) (*sqlCursor, error) {
+	c, ok := m.cursors[string(name)]
+	if !ok {
+		return nil, pgerror.Newf(pgcode.InvalidCursorName, "cursor %q does not exist", name)
+	}
+	return c, nil
+}
+
+// add adds a cursor to the map.
+func (m *cursorMap) add(c *sqlCursor) {
+	if m.cursors == nil {
+		m.cursors = make(map[string]*sqlCursor)
+	}
+	m.cursors[c.name] = c
+}
+
+// list returns the cursors in the map, sorted by name.
+func (m *cursorMap) list() []*sqlCursor {
+	ret := make([]*sqlCursor, 0, len(m.cursors))
+	for _, c := range m.cursors {
+		ret = append(ret, c)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].name < ret[j].name })
+	return ret
+}
+
+// closeCursor closes the cursor with the given name.
+func (m *cursorMap) closeCursor(ctx context.Context, name tree.Name) error {
+	c, err := m.get(name)
+	if err != nil {
+		return err
+	}
+	c.close(ctx)
+	delete(m.cursors, c.name)
+	return nil
+}
+
+// updateRootTxn imports the state of the leaf transactions of all the cursors
+// in the map into the session's transaction (see sqlCursor.updateRootTxn).
+func (m *cursorMap) updateRootTxn(ctx context.Context) error {
+	for _, c := range m.cursors {
+		if err := c.updateRootTxn(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// closeAll closes all the cursors in the map.
+func (m *cursorMap) closeAll(ctx context.Context) {
+	for name, c := range m.cursors {
+		c.close(ctx)
+		delete(m.cursors, name)
+	}
+}
+
+// cursorRowWriter is the rowResultWriter the query of a cursor writes its
+// rows to. Each row is handed to the statement that pulled it, after which the
+// query is suspended until the next row is pulled.
+type cursorRowWriter struct {
+	c   *sqlCursor
+	err error
+}
+
+var _ rowResultWriter = &cursorRowWriter{}
+
+// AddRow is part of the rowResultWriter interface.
+func (w *cursorRowWriter) AddRow(ctx context.Context, row tree.Datums) error {
+	rowCopy := make(tree.Datums, len(row))
+	copy(rowCopy, row)
+	select {
+	case w.c.rowCh <- rowCopy:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case more := <-w.c.pullCh:
+		if !more {
+			return ErrLimitedResultClosed
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// IncrementRowsAffected is part of the rowResultWriter interface.
+func (w *cursorRowWriter) IncrementRowsAffected(n int) {
+	panic(errors.AssertionFailedf("IncrementRowsAffected called for a cursor"))
+}
+
+// SetError is part of the rowResultWriter interface.
+func (w *cursorRowWriter) SetError(err error) {
+	w.err = err
+}
+
+// Err is part of the rowResultWriter interface.
+func (w *cursorRowWriter) Err() error {
+	return w.err
+}
+
+// checkDeclareCursor returns an error if the given DECLARE statement cannot be
+// executed.
+func (ex *connExecutor) checkDeclareCursor(s *tree.DeclareCursor, implicitTxn bool) error {
+	if implicitTxn {
+		return pgerror.New(pgcode.NoActiveSQLTransaction,
+			"DECLARE CURSOR can only be used in transaction blocks")
+	}
+	if s.Hold {
+		return unimplemented.NewWithIssue(41412, "DECLARE CURSOR WITH HOLD")
+	}
+	if s.Scroll == tree.Scroll {
+		return unimplemented.NewWithIssue(41412, "DECLARE SCROLL CURSOR")
+	}
+	if s.Binary {
+		return unimplemented.NewWithIssue(41412, "DECLARE BINARY CURSOR")
+	}
+	if _, ok := ex.extraTxnState.sqlCursors.cursors[string(s.Name)]; ok {
+		return pgerror.Newf(pgcode.DuplicateCursor, "cursor %q already exists", s.Name)
+	}
+	return nil
+}
+
+// declareCursor declares the cursor of the given DECLARE statement, whose
+// query is stmt. The query is planned right away, so that planning errors are
+// reported by the DECLARE statement, with the placeholders and the statement
+// timestamp of the planner p of the DECLARE statement.
+func (ex *connExecutor) declareCursor(
+	ctx context.Context, p *planner, s *tree.DeclareCursor, stmt Statement,
+) (retErr error) {
+	txn := ex.state.mu.txn
+	tis, err := txn.GetLeafTxnInputStateOrRejectClient(ctx)
+	if err != nil {
+		return err
+	}
+	leaf := kv.NewLeafTxn(ctx, ex.server.cfg.DB, ex.server.cfg.DistSQLPlanner.gatewayNodeID, &tis)
+	c := &sqlCursor{
+		name:      string(s.Name),
+		statement: tree.AsString(s.Select),
+		created:   timeutil.Now(),
+		stmt:      stmt,
+		txn:       leaf,
+		rootTxn:   txn,
+		readTS:    leaf.ReadTimestamp(),
+		tracing:   &ex.sessionTracing,
+		numRows:   -1,
+		pullCh:    make(chan bool),
+		rowCh:     make(chan tree.Datums),
+	}
+	c.ctx, c.cancel = context.WithCancel(ex.state.Ctx)
+	c.mon = mon.NewMonitor(
+		"cursor",
+		mon.MemoryResource,
+		nil /* curCount */, nil, /* maxHist */
+		-1 /* increment */, noteworthyMemoryUsageBytes, ex.server.cfg.Settings,
+	)
+	c.mon.Start(ctx, ex.sessionMon, mon.BoundAccount{} /* reserved */)
+	c.acc = c.mon.MakeBoundAccount()
+	defer func() {
+		if retErr != nil {
+			c.close(ctx)
+		}
+	}()
+
+	// The cursor gets its own planner, which outlives the DECLARE statement.
+	// The query is planned in the root transaction, since leasing descriptors
+	// may lower its deadline, and then runs in the leaf.
+	cp := &planner{execCfg: ex.server.cfg, alloc: &rowenc.DatumAlloc{}}
+	ex.initPlanner(c.ctx, cp)
+	ex.resetPlanner(c.ctx, cp, txn, p.extendedEvalCtx.StmtTimestamp)
+	cp.extendedEvalCtx.setSessionID(ex.sessionID)
+	cp.semaCtx.Placeholders = p.semaCtx.Placeholders
+	cp.semaCtx.AsOfTimestamp = p.semaCtx.AsOfTimestamp
+	cp.semaCtx.Annotations = tree.MakeAnnotations(stmt.NumAnnotations)
+	cp.extendedEvalCtx.Placeholders = &cp.semaCtx.Placeholders
+	cp.extendedEvalCtx.Annotations = &cp.semaCtx.Annotations
+	cp.extendedEvalCtx.Context = c.ctx
+	cp.extendedEvalCtx.Mon = c.mon
+	cp.stmt = &c.stmt
+	c.p = cp
+
+	if err := ex.makeExecPlan(ctx, cp); err != nil {
+		return err
+	}
+	if err := checkCursorQuery(cp.curPlan.mem.RootExpr()); err != nil {
+		return err
+	}
+	cp.txn = leaf
+	cp.extendedEvalCtx.Txn = leaf
+	c.cols = cp.curPlan.main.planColumns()
+	ex.extraTxnState.sqlCursors.add(c)
+	return nil
+}
+
+// checkCursorQuery returns an error if the given optimized query cannot be run
+// by a cursor, whose query only reads.
+func checkCursorQuery(e opt.Expr) error {
+	if rel, ok := e.(memo.RelExpr); ok && rel.Relational().CanMutate {
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"DECLARE CURSOR must not contain data-modifying statements in WITH")
+	}
+	var check func(e opt.Expr) error
+	check = func(e opt.Expr) error {
+		if scan, ok := e.(*memo.ScanExpr); ok && scan.IsLocking() {
+			return unimplemented.NewWithIssue(41412, "DECLARE CURSOR with row-level locking")
+		}
+		for i, n := 0, e.ChildCount(); i < n; i++ {
+			if err := check(e.Child(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return check(e)
+}
+
+// FetchCursor implements the FETCH statement.
+// See https://www.postgresql.org/docs/current/sql-fetch.html for details.
+func (p *planner) FetchCursor(ctx context.Context, s *tree.FetchCursor) (planNode, error) {
+	c, err := p.sqlCursors.get(s.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &fetchNode{n: &s.CursorStmt, cursor: c, columns: c.cols}, nil
+}
+
+// MoveCursor implements the MOVE statement.
+// See https://www.postgresql.org/docs/current/sql-move.html for details.
+func (p *planner) MoveCursor(ctx context.Context, s *tree.MoveCursor) (planNode, error) {
+	c, err := p.sqlCursors.get(s.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &fetchNode{n: &s.CursorStmt, cursor: c}, nil
+}
+
+// CloseCursor implements the CLOSE statement.
+// See https://www.postgresql.org/docs/current/sql-close.html for details.
+func (p *planner) CloseCursor(ctx context.Context, s *tree.CloseCursor) (planNode, error) {
+	if s.All {
+		p.sqlCursors.closeAll(ctx)
+	} else if err := p.sqlCursors.closeCursor(ctx, s.Name); err != nil {
+		return nil, err
+	}
+	return newZeroNode(nil /* columns */), nil
+}
+
+// fetchNode moves a cursor and returns the rows it moves onto. It implements
+// FETCH, and also MOVE, in which case it has no columns and only the number of
+// rows is returned to the client.
+type fetchNode struct {
+	n       *tree.CursorStmt
+	cursor  *sqlCursor
+	columns colinfo.ResultColumns
+
+	// skip, count and current are initialized by startExec; see
+	// sqlCursor.movement.
+	skip    int64
+	count   int64
+	current bool
+}
+
+func (n *fetchNode) startExec(params runParams) error {
+	var err error
+	n.skip, n.count, n.current, err = n.cursor.movement(params.ctx, n.n)
+	return err
+}
+
+func (n *fetchNode) Next(params runParams) (bool, error) {
+	ok, err := n.next(params)
+	if err == nil && !ok {
+		err = n.cursor.updateRootTxn(params.ctx)
+	}
+	return ok, err
+}
+
+func (n *fetchNode) next(params runParams) (bool, error) {
+	if n.current {
+		n.current = false
+		return n.cursor.cur != nil, nil
+	}
+	for ; n.skip > 0; n.skip-- {
+		if ok, err := n.cursor.next(params.ctx); err != nil || !ok {
+			n.count = 0
+			return false, err
+		}
+	}
+	if n.count == 0 {
+		return false, nil
+	}
+	if err := params.p.cancelChecker.Check(); err != nil {
+		return false, err
+	}
+	n.count--
+	return n.cursor.next(params.ctx)
+}
+
+func (n *fetchNode) Values() tree.Datums {
+	if n.columns == nil {
+		return nil
+	}
+	return n.cursor.cur
+}
+
+func (*fetchNode) Close(context.Context) {}
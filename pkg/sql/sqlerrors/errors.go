@@ -105,6 +105,8 @@ func NewUndefinedObjectError(name tree.NodeFormatter, kind tree.DesiredObjectKin
 		return NewUndefinedRelationError(name)
 	case tree.TypeObject:
 		return NewUndefinedTypeError(name)
+	case tree.FunctionObject:
+		return NewUndefinedFunctionError(name)
 	default:
 		return errors.AssertionFailedf("unknown object kind %d", kind)
 	}
@@ -115,6 +117,12 @@ func NewUndefinedTypeError(name tree.NodeFormatter) error {
 	return pgerror.Newf(pgcode.UndefinedObject, "type %q does not exist", tree.ErrString(name))
 }
 
+// NewUndefinedFunctionError creates an error that represents a missing
+// user-defined function.
+func NewUndefinedFunctionError(name tree.NodeFormatter) error {
+	return pgerror.Newf(pgcode.UndefinedFunction, "function %q does not exist", tree.ErrString(name))
+}
+
 // NewUndefinedRelationError creates an error that represents a missing database table or view.
 func NewUndefinedRelationError(name tree.NodeFormatter) error {
 	return pgerror.Newf(pgcode.UndefinedTable,
@@ -148,6 +156,8 @@ func MakeObjectAlreadyExistsError(collidingObject *descpb.Descriptor, name strin
 		return NewTypeAlreadyExistsError(name)
 	case *descpb.Descriptor_Database:
 		return NewDatabaseAlreadyExistsError(name)
+	case *descpb.Descriptor_Function:
+		return NewFunctionAlreadyExistsError(name)
 	case *descpb.Descriptor_Schema:
 		// TODO(ajwerner): Add a case for an existing schema object.
 		return errors.AssertionFailedf("schema exists with name %v", name)
@@ -166,6 +176,11 @@ func NewTypeAlreadyExistsError(name string) error {
 	return pgerror.Newf(pgcode.DuplicateObject, "type %q already exists", name)
 }
 
+// NewFunctionAlreadyExistsError creates an error for a preexisting function.
+func NewFunctionAlreadyExistsError(name string) error {
+	return pgerror.Newf(pgcode.DuplicateFunction, "function %q already exists", name)
+}
+
 // IsRelationAlreadyExistsError checks whether this is an error for a preexisting relation.
 func IsRelationAlreadyExistsError(err error) bool {
 	return errHasCode(err, pgcode.DuplicateRelation)
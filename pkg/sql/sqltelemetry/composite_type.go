@@ -0,0 +1,57 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sqltelemetry
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+)
+
+// CompositeTypeTelemetryType represents a type of composite type related
+// operation to record telemetry for.
+type CompositeTypeTelemetryType int
+
+const (
+	_ CompositeTypeTelemetryType = iota
+	// CompositeTypeCreate represents a CREATE TYPE ... AS (...) command.
+	CompositeTypeCreate
+	// CompositeTypeDrop represents dropping a composite type.
+	CompositeTypeDrop
+	// CompositeTypeFieldUpdate represents an UPDATE of a single field of a
+	// column of a composite type.
+	CompositeTypeFieldUpdate
+)
+
+var compositeTypeTelemetryMap = map[CompositeTypeTelemetryType]string{
+	CompositeTypeCreate:      "create_composite_type",
+	CompositeTypeDrop:        "drop_composite_type",
+	CompositeTypeFieldUpdate: "composite_type_field_update",
+}
+
+func (c CompositeTypeTelemetryType) String() string {
+	return compositeTypeTelemetryMap[c]
+}
+
+var compositeTypeTelemetryCounters map[CompositeTypeTelemetryType]telemetry.Counter
+
+func init() {
+	compositeTypeTelemetryCounters = make(map[CompositeTypeTelemetryType]telemetry.Counter)
+	for ty, s := range compositeTypeTelemetryMap {
+		compositeTypeTelemetryCounters[ty] = telemetry.GetCounterOnce(fmt.Sprintf("sql.udts.%s", s))
+	}
+}
+
+// IncrementCompositeTypeCounter is used to increment the telemetry counter
+// for a particular usage of composite types.
+func IncrementCompositeTypeCounter(compositeType CompositeTypeTelemetryType) {
+	telemetry.Inc(compositeTypeTelemetryCounters[compositeType])
+}
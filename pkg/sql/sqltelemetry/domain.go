@@ -0,0 +1,59 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sqltelemetry
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+)
+
+// DomainTelemetryType represents a type of DOMAIN related operation to record
+// telemetry for.
+type DomainTelemetryType int
+
+const (
+	_ DomainTelemetryType = iota
+	// DomainCreate represents a CREATE DOMAIN command.
+	DomainCreate
+	// DomainAlter represents an ALTER DOMAIN command.
+	DomainAlter
+	// DomainDrop represents a DROP DOMAIN command.
+	DomainDrop
+	// DomainInTable tracks when a domain type is used in a table.
+	DomainInTable
+)
+
+var domainTelemetryMap = map[DomainTelemetryType]string{
+	DomainCreate:  "create_domain",
+	DomainAlter:   "alter_domain",
+	DomainDrop:    "drop_domain",
+	DomainInTable: "domain_used_in_table",
+}
+
+func (d DomainTelemetryType) String() string {
+	return domainTelemetryMap[d]
+}
+
+var domainTelemetryCounters map[DomainTelemetryType]telemetry.Counter
+
+func init() {
+	domainTelemetryCounters = make(map[DomainTelemetryType]telemetry.Counter)
+	for ty, s := range domainTelemetryMap {
+		domainTelemetryCounters[ty] = telemetry.GetCounterOnce(fmt.Sprintf("sql.udts.%s", s))
+	}
+}
+
+// IncrementDomainCounter is used to increment the telemetry counter for a
+// particular usage of domains.
+func IncrementDomainCounter(domainType DomainTelemetryType) {
+	telemetry.Inc(domainTelemetryCounters[domainType])
+}
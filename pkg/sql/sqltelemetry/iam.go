@@ -28,6 +28,11 @@ const (
 	CreateRole = "create"
 	// OnDatabase is used when a GRANT/REVOKE is happening on a database.
 	OnDatabase = "on_database"
+	// OnExternalConnection is used when a GRANT/REVOKE is happening on an
+	// external connection.
+	OnExternalConnection = "on_external_connection"
+	// OnFunction is used when a GRANT/REVOKE is happening on a function.
+	OnFunction = "on_function"
 	// OnSchema is used when a GRANT/REVOKE is happening on a schema.
 	OnSchema = "on_schema"
 	// OnTable is used when a GRANT/REVOKE is happening on a table.
@@ -56,6 +56,10 @@ var ArrayCastCounter = telemetry.GetCounterOnce("sql.plan.ops.cast.arrays")
 // are between enums.
 var EnumCastCounter = telemetry.GetCounterOnce("sql.plan.ops.cast.enums")
 
+// CompositeCastCounter is to be incremented when typechecking casts of
+// tuples to composite types.
+var CompositeCastCounter = telemetry.GetCounterOnce("sql.plan.ops.cast.composites")
+
 // ArrayConstructorCounter is to be incremented upon type checking
 // of ARRAY[...] expressions/
 var ArrayConstructorCounter = telemetry.GetCounterOnce("sql.plan.ops.array.cons")
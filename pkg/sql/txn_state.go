@@ -18,6 +18,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -174,10 +175,26 @@ func (ts *txnState) resetForNewSQLTxn(
 			opentracing.ChildOf(parentSp.Context()), tracing.Recordable,
 			tracing.LogTagsFromCtx(connCtx),
 		)
+	} else if tr := tranCtx.tracer.(*tracing.Tracer); tranCtx.traceParent != "" || tr.ExportsSpans() {
+		// Create a root span for this SQL txn, part of the client's trace if
+		// one was provided. The application name is used by the trace exporter
+		// to decide whether the trace is sampled.
+		opts := []opentracing.StartSpanOption{tracing.Recordable, tracing.LogTagsFromCtx(connCtx)}
+		if tranCtx.sessionData != nil {
+			opts = append(opts, opentracing.Tag{
+				Key: tracing.TagApplicationName, Value: tranCtx.sessionData.ApplicationName,
+			})
+		}
+		if tranCtx.traceParent != "" {
+			// The traceparent value was validated when the session was created.
+			if remoteCtx, err := tr.ExtractTraceParent(tranCtx.traceParent); err == nil {
+				opts = append(opts, opentracing.ChildOf(remoteCtx))
+			}
+		}
+		sp = tr.StartSpan(opName, opts...)
 	} else {
 		// Create a root span for this SQL txn.
-		sp = tranCtx.tracer.(*tracing.Tracer).StartRootSpan(
-			opName, logtags.FromContext(connCtx), tracing.RecordableSpan)
+		sp = tr.StartRootSpan(opName, logtags.FromContext(connCtx), tracing.RecordableSpan)
 	}
 
 	if txnType == implicitTxn {
@@ -425,6 +442,12 @@ type transitionCtx struct {
 	// state machine needs to see if session tracing is enabled.
 	sessionTracing *SessionTracing
 	settings       *cluster.Settings
+	// sessionData is used to look up the application name, which determines
+	// the sampling rate of root spans exported to a trace collector. Can be nil.
+	sessionData *sessiondata.SessionData
+	// traceParent is the W3C Trace Context traceparent value provided by the
+	// client, if any. Root spans for new txns are children of that context.
+	traceParent string
 }
 
 var noRewind = rewindCapability{}
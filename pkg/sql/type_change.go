@@ -12,6 +12,7 @@ package sql
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/jobs"
@@ -24,6 +25,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
@@ -193,6 +197,15 @@ func (t *typeSchemaChanger) exec(ctx context.Context) error {
 		return err
 	}
 
+	// If there are any domain constraints being validated, validate them
+	// against the existing data now that all nodes enforce them for new data.
+	if typeDesc.Kind == descpb.TypeDescriptor_DOMAIN && !typeDesc.Dropped() &&
+		typeDesc.HasPendingSchemaChanges() {
+		if err := t.validateDomainConstraints(ctx, typeDesc); err != nil {
+			return err
+		}
+	}
+
 	// If the type is being dropped, remove the descriptor here.
 	if typeDesc.Dropped() {
 		if err := t.execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
@@ -207,6 +220,131 @@ func (t *typeSchemaChanger) exec(ctx context.Context) error {
 	return nil
 }
 
+// validateDomainConstraints validates the constraints of the given DOMAIN
+// that are being added against the values of the columns of that type in
+// all the tables that use it. The constraints that hold are marked as
+// validated, and the ones that do not are removed from the domain, in which
+// case an error is returned.
+func (t *typeSchemaChanger) validateDomainConstraints(
+	ctx context.Context, typeDesc *typedesc.Immutable,
+) error {
+	var violation error
+	violated := make(map[string]bool)
+	if err := t.execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		violation = nil
+		for k := range violated {
+			delete(violated, k)
+		}
+		for i := range typeDesc.DomainConstraints {
+			c := &typeDesc.DomainConstraints[i]
+			if c.Validity != descpb.ConstraintValidity_Validating {
+				continue
+			}
+			found, err := t.validateDomainConstraint(ctx, txn, typeDesc, c)
+			if err != nil {
+				return err
+			}
+			if found != nil {
+				violated[c.Name] = true
+				if violation == nil {
+					violation = found
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	run := func(ctx context.Context, txn *kv.Txn, descsCol *descs.Collection) error {
+		typeDesc, err := descsCol.GetMutableTypeVersionByID(ctx, txn, t.typeID)
+		if err != nil {
+			return err
+		}
+		constraints := typeDesc.DomainConstraints[:0]
+		for _, c := range typeDesc.DomainConstraints {
+			if c.Validity == descpb.ConstraintValidity_Validating {
+				if violated[c.Name] {
+					continue
+				}
+				c.Validity = descpb.ConstraintValidity_Validated
+			}
+			constraints = append(constraints, c)
+		}
+		typeDesc.DomainConstraints = constraints
+		b := txn.NewBatch()
+		if err := descsCol.WriteDescToBatch(
+			ctx, true /* kvTrace */, typeDesc, b,
+		); err != nil {
+			return err
+		}
+		return txn.Run(ctx, b)
+	}
+	if err := descs.Txn(
+		ctx, t.execCfg.Settings, t.execCfg.LeaseManager,
+		t.execCfg.InternalExecutor, t.execCfg.DB, run,
+	); err != nil {
+		return err
+	}
+	if err := WaitToUpdateLeases(ctx, t.execCfg.LeaseManager, t.typeID); err != nil {
+		return err
+	}
+	return violation
+}
+
+// validateDomainConstraint checks whether any value of a column of the given
+// DOMAIN type violates the given constraint of the domain. It returns the
+// error to report to the user if one does.
+func (t *typeSchemaChanger) validateDomainConstraint(
+	ctx context.Context,
+	txn *kv.Txn,
+	typeDesc *typedesc.Immutable,
+	c *descpb.TypeDescriptor_DomainConstraint,
+) (violation error, err error) {
+	domainOID := typedesc.TypeIDToOID(typeDesc.ID)
+	for _, id := range typeDesc.ReferencingDescriptorIDs {
+		desc, err := catalogkv.GetAnyDescriptorByID(ctx, txn, t.execCfg.Codec, id, catalogkv.Immutable)
+		if err != nil {
+			return nil, err
+		}
+		tableDesc, ok := desc.(catalog.TableDescriptor)
+		if !ok || tableDesc.IsView() || tableDesc.Dropped() {
+			continue
+		}
+		for _, col := range tableDesc.GetPublicColumns() {
+			if col.Type.Oid() != domainOID {
+				continue
+			}
+			colItem := &tree.ColumnItem{ColumnName: tree.Name(col.Name)}
+			var cond tree.Expr = &tree.IsNullExpr{Expr: colItem}
+			if !c.NotNull {
+				check, err := schemaexpr.ParseDomainCheckExpr(c.CheckExpr, colItem)
+				if err != nil {
+					return nil, err
+				}
+				cond = &tree.NotExpr{Expr: check}
+			}
+			queryStr := fmt.Sprintf(`SELECT 1 FROM [%d AS t] WHERE %s LIMIT 1`,
+				tableDesc.GetID(), tree.Serialize(cond))
+			log.Infof(ctx, "validating domain constraint %q with query %q", c.Name, queryStr)
+			rows, err := t.execCfg.InternalExecutor.QueryRow(ctx, "validate domain constraint", txn, queryStr)
+			if err != nil {
+				return nil, err
+			}
+			if rows.Len() > 0 {
+				if c.NotNull {
+					return pgerror.Newf(pgcode.NotNullViolation,
+						"column %q of table %q contains null values", col.Name, tableDesc.GetName()), nil
+				}
+				return pgerror.Newf(pgcode.CheckViolation,
+					"column %q of table %q contains values that violate the new constraint",
+					col.Name, tableDesc.GetName()), nil
+			}
+		}
+	}
+	return nil, nil
+}
+
 func enumHasNonPublic(typeDesc *typedesc.Immutable) bool {
 	hasNonPublic := false
 	for _, member := range typeDesc.EnumMembers {
@@ -183,6 +183,9 @@ func init() {
 // calcArrayOid returns the OID of the array type having elements of the given
 // type.
 func calcArrayOid(elemTyp *T) oid.Oid {
+	if elemTyp.IsDomain() {
+		return elemTyp.UserDefinedArrayOID()
+	}
 	o := elemTyp.Oid()
 	switch elemTyp.Family() {
 	case ArrayFamily:
@@ -205,6 +208,11 @@ func calcArrayOid(elemTyp *T) oid.Oid {
 
 	case EnumFamily:
 		return elemTyp.UserDefinedArrayOID()
+
+	case TupleFamily:
+		if elemTyp.IsComposite() {
+			return elemTyp.UserDefinedArrayOID()
+		}
 	}
 
 	// Map the OID of the array element type to the corresponding array OID.
@@ -166,6 +166,14 @@ import (
 // | Family        | EnumFamily                                 |
 // | Oid           | A unique OID generated upon enum creation  |
 //
+// * Domains
+// | Field         | Description                                |
+// |---------------|--------------------------------------------|
+// | Family        | The family of the base type                |
+// | Oid           | A unique OID generated on domain creation  |
+//
+// All other fields of a domain are the ones of its base type.
+//
 // See types.proto for the corresponding proto definition. Its automatic
 // type declaration is suppressed in the proto so that it is possible to
 // add additional fields to T without serializing them.
@@ -194,6 +202,9 @@ type UserDefinedTypeMetadata struct {
 
 	// enumData is non-nil iff the metadata is for an ENUM type.
 	EnumData *EnumMetadata
+
+	// DomainData is non-nil iff the metadata is for a DOMAIN type.
+	DomainData *DomainMetadata
 }
 
 // EnumMetadata is metadata about an ENUM needed for evaluation.
@@ -220,6 +231,29 @@ func (e *EnumMetadata) debugString() string {
 	)
 }
 
+// DomainMetadata is metadata about a DOMAIN needed for evaluation.
+type DomainMetadata struct {
+	// BaseType is the type that the domain is defined over.
+	BaseType *T
+	// DefaultExpr is the serialized default expression of the domain, if any.
+	DefaultExpr *string
+	// Constraints are the NOT NULL and CHECK constraints of the domain.
+	Constraints []DomainConstraint
+}
+
+// DomainConstraint is a NOT NULL or CHECK constraint of a DOMAIN.
+type DomainConstraint struct {
+	Name string
+	// NotNull is set if this is the NOT NULL constraint of the domain.
+	NotNull bool
+	// CheckExpr is the serialized expression of a CHECK constraint, which
+	// refers to the value of the domain as VALUE.
+	CheckExpr string
+	// Validated is false while the constraint is being validated against
+	// existing data. It is still enforced for new values.
+	Validated bool
+}
+
 // UserDefinedTypeName is a struct representing a qualified user defined
 // type name. We redefine a common struct from higher level packages. We
 // do so because proto will panic if any members of a proto struct are
@@ -1053,6 +1087,31 @@ func MakeEnum(typeOID, arrayTypeOID oid.Oid) *T {
 	}}
 }
 
+// MakeDomain constructs a new instance of a DOMAIN type over the given base
+// type with the given stable type ID. Note that it does not hydrate cached
+// fields on the type.
+func MakeDomain(typeOID, arrayTypeOID oid.Oid, baseType *T) *T {
+	typ := &T{InternalType: baseType.InternalType}
+	typ.InternalType.Oid = typeOID
+	typ.InternalType.UDTMetadata = &PersistentUserDefinedTypeMetadata{
+		ArrayTypeOID: arrayTypeOID,
+	}
+	return typ
+}
+
+// MakeComposite constructs a new instance of a user defined composite type,
+// which is a TupleFamily type with the given field types and labels, and the
+// given stable type ID. Note that it does not hydrate cached fields on the
+// type.
+func MakeComposite(typeOID, arrayTypeOID oid.Oid, contents []*T, labels []string) *T {
+	typ := MakeLabeledTuple(contents, labels)
+	typ.InternalType.Oid = typeOID
+	typ.InternalType.UDTMetadata = &PersistentUserDefinedTypeMetadata{
+		ArrayTypeOID: arrayTypeOID,
+	}
+	return typ
+}
+
 // MakeArray constructs a new instance of an ArrayFamily type with the given
 // element type (which may itself be an ArrayFamily type).
 func MakeArray(typ *T) *T {
@@ -1262,6 +1321,23 @@ func (t *T) UserDefined() bool {
 	return IsOIDUserDefinedType(t.Oid())
 }
 
+// IsDomain returns whether or not t is a user defined DOMAIN type. A domain
+// has the family and attributes of its base type, which is never a user
+// defined type, but a user defined OID.
+func (t *T) IsDomain() bool {
+	switch t.Family() {
+	case EnumFamily, ArrayFamily, TupleFamily:
+		return false
+	}
+	return t.UserDefined()
+}
+
+// IsComposite returns whether or not t is a user defined composite type,
+// which is a labeled tuple with a user defined OID.
+func (t *T) IsComposite() bool {
+	return t.Family() == TupleFamily && t.UserDefined()
+}
+
 // IsOIDUserDefinedType returns whether or not o corresponds to a user
 // defined type.
 func IsOIDUserDefinedType(o oid.Oid) bool {
@@ -1317,6 +1393,13 @@ func (f Family) Name() string {
 //
 // TODO(andyk): Should these be changed to be the same as SQLStandardName?
 func (t *T) Name() string {
+	if t.IsDomain() {
+		// This can be nil during unit testing.
+		if t.TypeMeta.Name == nil {
+			return "unknown_domain"
+		}
+		return t.TypeMeta.Name.Basename()
+	}
 	switch fam := t.Family(); fam {
 	case AnyFamily:
 		return "anyelement"
@@ -1378,7 +1461,14 @@ func (t *T) Name() string {
 		panic(errors.AssertionFailedf("unexpected OID: %d", t.Oid()))
 
 	case TupleFamily:
-		// Tuple types are currently anonymous, with no name.
+		if t.IsComposite() {
+			// This can be nil during unit testing.
+			if t.TypeMeta.Name == nil {
+				return "unknown_composite"
+			}
+			return t.TypeMeta.Name.Basename()
+		}
+		// Other tuple types are anonymous, with no name.
 		return ""
 
 	case EnumFamily:
@@ -1457,6 +1547,9 @@ func (t *T) TelemetryName() string {
 // This function is full of special cases. See backend/utils/adt/format_type.c
 // in Postgres.
 func (t *T) SQLStandardNameWithTypmod(haveTypmod bool, typmod int) string {
+	if t.IsDomain() {
+		return t.TypeMeta.Name.Basename()
+	}
 	var buf strings.Builder
 	switch t.Family() {
 	case AnyFamily:
@@ -1610,6 +1703,9 @@ func (t *T) SQLStandardNameWithTypmod(haveTypmod bool, typmod int) string {
 		}
 		return fmt.Sprintf("timestamp(%d) with time zone", typmod)
 	case TupleFamily:
+		if t.IsComposite() {
+			return t.TypeMeta.Name.Basename()
+		}
 		return "record"
 	case UnknownFamily:
 		return "unknown"
@@ -1639,6 +1735,9 @@ func (t *T) InformationSchemaName() string {
 // reproduce the type via parsing the string as a type. It is used in error
 // messages and also to produce the output of SHOW CREATE.
 func (t *T) SQLString() string {
+	if t.IsDomain() {
+		return t.TypeMeta.Name.FQName()
+	}
 	switch t.Family() {
 	case BitFamily:
 		o := t.Oid()
@@ -1733,6 +1832,10 @@ func (t *T) SQLString() string {
 			return "anyenum"
 		}
 		return t.TypeMeta.Name.FQName()
+	case TupleFamily:
+		if t.IsComposite() {
+			return t.TypeMeta.Name.FQName()
+		}
 	}
 	return strings.ToUpper(t.Name())
 }
@@ -1769,6 +1872,12 @@ func (t *T) Equivalent(other *T) bool {
 		if IsWildcardTupleType(t) || IsWildcardTupleType(other) {
 			return true
 		}
+		// Distinct composite types are never equivalent, even if they have the
+		// same fields. A composite type is equivalent to an anonymous tuple type
+		// with equivalent fields.
+		if t.IsComposite() && other.IsComposite() && t.Oid() != other.Oid() {
+			return false
+		}
 		if len(t.TupleContents()) != len(other.TupleContents()) {
 			return false
 		}
@@ -1973,6 +2082,16 @@ func (t *T) Unmarshal(data []byte) error {
 // setting required values. This is necessary to preserve backwards-
 // compatibility with older formats (e.g. restoring database from old backup).
 func (t *T) upgradeType() error {
+	// Domains are never serialized in a previous format, since they can only be
+	// created once all nodes are running the current version. Their OID must
+	// not be remapped from the attributes of their base type.
+	if t.IsDomain() {
+		if t.InternalType.Locale == nil {
+			t.InternalType.Locale = &emptyLocale
+		}
+		return nil
+	}
+
 	switch t.Family() {
 	case IntFamily:
 		// Check VisibleType field that was populated in previous versions.
@@ -2198,6 +2317,11 @@ func (t *T) MarshalTo(data []byte) (int, error) {
 // CRDB. This is necessary to preserve backwards-compatibility in mixed-version
 // scenarios, such as during upgrade.
 func (t *T) downgradeType() error {
+	// Domains are never read by previous versions, see upgradeType.
+	if t.IsDomain() {
+		return nil
+	}
+
 	// Set Family and VisibleType for 19.1 backwards-compatibility.
 	switch t.Family() {
 	case BitFamily:
@@ -2268,6 +2392,9 @@ func (t *T) downgradeType() error {
 // TODO(andyk): It'd be nice to have this return SqlString() method output,
 // since that is more descriptive.
 func (t *T) String() string {
+	if t.IsDomain() {
+		return t.Name()
+	}
 	switch t.Family() {
 	case CollatedStringFamily:
 		if t.Locale() == "" {
@@ -2284,6 +2411,9 @@ func (t *T) String() string {
 		return t.ArrayContents().String() + "[]"
 
 	case TupleFamily:
+		if t.IsComposite() {
+			return t.Name()
+		}
 		var buf bytes.Buffer
 		buf.WriteString("tuple")
 		if len(t.TupleContents()) != 0 && !IsWildcardTupleType(t) {
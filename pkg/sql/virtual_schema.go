@@ -365,6 +365,10 @@ func (v virtualSchemaEntry) GetObjectByName(
 			desc:    typedesc.MakeSimpleAlias(typ),
 			mutable: flags.RequireMutable,
 		}, nil
+	case tree.FunctionObject:
+		// Virtual schemas do not contain user-defined functions; their
+		// functions are builtins, which are resolved separately.
+		return nil, nil
 	default:
 		return nil, errors.AssertionFailedf("unknown desired object kind %d", flags.DesiredObjectKind)
 	}
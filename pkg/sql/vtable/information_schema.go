@@ -79,6 +79,40 @@ CREATE TABLE information_schema.columns (
 	CRDB_SQL_TYPE            STRING NOT NULL  -- CockroachDB extension for SHOW COLUMNS / dump.
 )`
 
+// InformationSchemaDomains describes the schema of the
+// information_schema.domains table.
+// Postgres: https://www.postgresql.org/docs/current/infoschema-domains.html
+const InformationSchemaDomains = `
+CREATE TABLE information_schema.domains (
+	DOMAIN_CATALOG           STRING NOT NULL,
+	DOMAIN_SCHEMA            STRING NOT NULL,
+	DOMAIN_NAME              STRING NOT NULL,
+	DATA_TYPE                STRING NOT NULL,
+	CHARACTER_MAXIMUM_LENGTH INT,
+	CHARACTER_OCTET_LENGTH   INT,
+	CHARACTER_SET_CATALOG    STRING,
+	CHARACTER_SET_SCHEMA     STRING,
+	CHARACTER_SET_NAME       STRING,
+	COLLATION_CATALOG        STRING,
+	COLLATION_SCHEMA         STRING,
+	COLLATION_NAME           STRING,
+	NUMERIC_PRECISION        INT,
+	NUMERIC_PRECISION_RADIX  INT,
+	NUMERIC_SCALE            INT,
+	DATETIME_PRECISION       INT,
+	INTERVAL_TYPE            STRING,
+	INTERVAL_PRECISION       INT,
+	DOMAIN_DEFAULT           STRING,
+	UDT_CATALOG              STRING,
+	UDT_SCHEMA               STRING,
+	UDT_NAME                 STRING,
+	SCOPE_CATALOG            STRING,
+	SCOPE_SCHEMA             STRING,
+	SCOPE_NAME               STRING,
+	MAXIMUM_CARDINALITY      INT,
+	DTD_IDENTIFIER           STRING
+)`
+
 // InformationSchemaAdministrableRoleAuthorizations describes the schema of the
 // information_schema.administrable_role_authorizations table.
 // Postgres: https://www.postgresql.org/docs/9.6/static/infoschema-administrable-role-authorizations.html
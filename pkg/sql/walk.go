@@ -340,7 +340,9 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&alterTableNode{}):              "alter table",
 	reflect.TypeOf(&alterTableSetSchemaNode{}):     "alter table set schema",
 	reflect.TypeOf(&alterTypeNode{}):               "alter type",
+	reflect.TypeOf(&alterDomainNode{}):             "alter domain",
 	reflect.TypeOf(&alterRoleNode{}):               "alter role",
+	reflect.TypeOf(&alterExternalConnNode{}):       "alter external connection",
 	reflect.TypeOf(&applyJoinNode{}):               "apply join",
 	reflect.TypeOf(&bufferNode{}):                  "buffer",
 	reflect.TypeOf(&cancelQueriesNode{}):           "cancel queries",
@@ -354,11 +356,14 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&controlSchedulesNode{}):        "control schedules",
 	reflect.TypeOf(&createDatabaseNode{}):          "create database",
 	reflect.TypeOf(&createExtensionNode{}):         "create extension",
+	reflect.TypeOf(&createExternalConnNode{}):      "create external connection",
+	reflect.TypeOf(&createFunctionNode{}):          "create function",
 	reflect.TypeOf(&createIndexNode{}):             "create index",
 	reflect.TypeOf(&createSequenceNode{}):          "create sequence",
 	reflect.TypeOf(&createSchemaNode{}):            "create schema",
 	reflect.TypeOf(&createStatsNode{}):             "create statistics",
 	reflect.TypeOf(&createTableNode{}):             "create table",
+	reflect.TypeOf(&createTriggerNode{}):           "create trigger",
 	reflect.TypeOf(&createTypeNode{}):              "create type",
 	reflect.TypeOf(&CreateRoleNode{}):              "create user/role",
 	reflect.TypeOf(&createViewNode{}):              "create view",
@@ -367,10 +372,13 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&deleteRangeNode{}):             "delete range",
 	reflect.TypeOf(&distinctNode{}):                "distinct",
 	reflect.TypeOf(&dropDatabaseNode{}):            "drop database",
+	reflect.TypeOf(&dropExternalConnNode{}):        "drop external connection",
+	reflect.TypeOf(&dropFunctionNode{}):            "drop function",
 	reflect.TypeOf(&dropIndexNode{}):               "drop index",
 	reflect.TypeOf(&dropSequenceNode{}):            "drop sequence",
 	reflect.TypeOf(&dropSchemaNode{}):              "drop schema",
 	reflect.TypeOf(&dropTableNode{}):               "drop table",
+	reflect.TypeOf(&dropTriggerNode{}):             "drop trigger",
 	reflect.TypeOf(&dropTypeNode{}):                "drop type",
 	reflect.TypeOf(&DropRoleNode{}):                "drop user/role",
 	reflect.TypeOf(&dropViewNode{}):                "drop view",
@@ -379,6 +387,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&explainPlanNode{}):             "explain plan",
 	reflect.TypeOf(&explainVecNode{}):              "explain vectorized",
 	reflect.TypeOf(&exportNode{}):                  "export",
+	reflect.TypeOf(&fetchNode{}):                   "fetch",
 	reflect.TypeOf(&filterNode{}):                  "filter",
 	reflect.TypeOf(&GrantRoleNode{}):               "grant role",
 	reflect.TypeOf(&groupNode{}):                   "group",
@@ -373,6 +373,13 @@ var backwardCompatibleMigrations = []migrationDescriptor{
 		workFn:              markDeprecatedSchemaChangeJobsFailed,
 		includedInBootstrap: clusterversion.VersionByKey(clusterversion.VersionLeasedDatabaseDescriptors),
 	},
+	{
+		// Introduced in v20.2.
+		name:                "create new system.external_connections table",
+		workFn:              createExternalConnectionsTable,
+		includedInBootstrap: clusterversion.VersionByKey(clusterversion.VersionExternalConnections),
+		newDescriptorIDs:    staticIDs(keys.ExternalConnectionsTableID),
+	},
 }
 
 func staticIDs(
@@ -1438,6 +1445,10 @@ ADD COLUMN IF NOT EXISTS claim_instance_id INT8 FAMILY claim
 	return createSystemTable(ctx, r, systemschema.SqllivenessTable)
 }
 
+func createExternalConnectionsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, systemschema.ExternalConnectionsTable)
+}
+
 func createTenantsTable(ctx context.Context, r runner) error {
 	return createSystemTable(ctx, r, systemschema.TenantsTable)
 }
@@ -0,0 +1,271 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cloudimpltests
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/errors"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// testSFTPServer is an in-process SSH server which serves the sftp subsystem
+// on the local filesystem.
+type testSFTPServer struct {
+	t       *testing.T
+	ln      net.Listener
+	hostKey ssh.PublicKey
+	config  *ssh.ServerConfig
+	wg      sync.WaitGroup
+
+	mu struct {
+		sync.Mutex
+		conns []net.Conn
+	}
+}
+
+func newTestSFTPServer(
+	t *testing.T, password string, authorizedKey ssh.PublicKey,
+) *testSFTPServer {
+	hostSigner, _ := newTestSFTPKey(t)
+	s := &testSFTPServer{t: t, hostKey: hostSigner.PublicKey()}
+	s.config = &ssh.ServerConfig{
+		PasswordCallback: func(_ ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if password != "" && string(pass) == password {
+				return nil, nil
+			}
+			return nil, errors.New("password rejected")
+		},
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if authorizedKey != nil && bytes.Equal(key.Marshal(), authorizedKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("public key rejected")
+		},
+	}
+	s.config.AddHostKey(hostSigner)
+
+	var err error
+	s.ln, err = net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+func (s *testSFTPServer) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.mu.conns = append(s.mu.conns, conn)
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handleConn(conn)
+		}()
+	}
+}
+
+func (s *testSFTPServer) handleConn(conn net.Conn) {
+	defer conn.Close()
+	_, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for newChan := range chans {
+		if newChan.ChannelType() != "session" {
+			_ = newChan.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		ch, chReqs, err := newChan.Accept()
+		if err != nil {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer ch.Close()
+			for req := range chReqs {
+				// The payload of a subsystem request is the length-prefixed
+				// subsystem name.
+				ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(ok, nil)
+				if !ok {
+					continue
+				}
+				server, err := sftp.NewServer(ch)
+				if err != nil {
+					return
+				}
+				_ = server.Serve()
+				return
+			}
+		}()
+	}
+}
+
+// dropConnections abruptly closes every connection accepted so far.
+func (s *testSFTPServer) dropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.mu.conns {
+		_ = conn.Close()
+	}
+	s.mu.conns = nil
+}
+
+func (s *testSFTPServer) knownHosts() string {
+	return knownhosts.Line([]string{knownhosts.Normalize(s.ln.Addr().String())}, s.hostKey)
+}
+
+func (s *testSFTPServer) Close() {
+	_ = s.ln.Close()
+	s.dropConnections()
+	s.wg.Wait()
+}
+
+func newTestSFTPKey(t *testing.T) (ssh.Signer, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	return signer, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+func sftpURI(userinfo *url.Userinfo, addr, dir, privateKey, knownHosts string) string {
+	q := make(url.Values)
+	if privateKey != "" {
+		q.Set(cloudimpl.SFTPPrivateKeyParam, base64.StdEncoding.EncodeToString([]byte(privateKey)))
+	}
+	q.Set(cloudimpl.SFTPKnownHostsParam, base64.StdEncoding.EncodeToString([]byte(knownHosts)))
+	u := url.URL{Scheme: "sftp", User: userinfo, Host: addr, Path: dir, RawQuery: q.Encode()}
+	return u.String()
+}
+
+func TestPutSFTP(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, dirCleanup := testutils.TempDir(t)
+	defer dirCleanup()
+
+	const password = "hunter2"
+	clientSigner, clientKey := newTestSFTPKey(t)
+	srv := newTestSFTPServer(t, password, clientSigner.PublicKey())
+	defer srv.Close()
+	addr := srv.ln.Addr().String()
+	user := security.RootUser
+
+	t.Run("password", func(t *testing.T) {
+		uri := sftpURI(url.UserPassword("roach", password), addr, dir, "", srv.knownHosts())
+		testExportStore(t, uri, false, user, nil, nil)
+		testListFiles(t, uri, user, nil, nil)
+	})
+
+	t.Run("private-key", func(t *testing.T) {
+		uri := sftpURI(url.User("roach"), addr, dir, string(clientKey), srv.knownHosts())
+		testExportStore(t, uri, false, user, nil, nil)
+	})
+
+	t.Run("wrong-password", func(t *testing.T) {
+		uri := sftpURI(url.UserPassword("roach", "wrong"), addr, dir, "", srv.knownHosts())
+		s := storeFromURI(context.Background(), t, uri, nil, user, nil, nil)
+		defer s.Close()
+		err := s.WriteFile(context.Background(), "a", bytes.NewReader([]byte("aaa")))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unable to authenticate")
+	})
+
+	t.Run("unknown-host-key", func(t *testing.T) {
+		otherHost, _ := newTestSFTPKey(t)
+		knownHosts := knownhosts.Line([]string{knownhosts.Normalize(addr)}, otherHost.PublicKey())
+		uri := sftpURI(url.UserPassword("roach", password), addr, dir, "", knownHosts)
+		s := storeFromURI(context.Background(), t, uri, nil, user, nil, nil)
+		defer s.Close()
+		err := s.WriteFile(context.Background(), "a", bytes.NewReader([]byte("aaa")))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "key mismatch")
+	})
+
+	t.Run("missing-known-hosts", func(t *testing.T) {
+		_, err := cloudimpl.ExternalStorageConfFromURI(
+			fmt.Sprintf("sftp://roach:%s@%s%s", password, addr, dir), user)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), cloudimpl.SFTPKnownHostsParam)
+	})
+
+	t.Run("resume-read", func(t *testing.T) {
+		ctx := context.Background()
+		uri := sftpURI(url.UserPassword("roach", password), addr, dir, "", srv.knownHosts())
+		s := storeFromURI(ctx, t, uri, nil, user, nil, nil)
+		defer s.Close()
+
+		payload := make([]byte, 1<<20)
+		_, err := rand.Read(payload)
+		require.NoError(t, err)
+		require.NoError(t, s.WriteFile(ctx, "resume", bytes.NewReader(payload)))
+
+		r, err := s.ReadFile(ctx, "resume")
+		require.NoError(t, err)
+		defer r.Close()
+		head := make([]byte, 1024)
+		_, err = io.ReadFull(r, head)
+		require.NoError(t, err)
+
+		// Kill the connection mid-read; the reader should reconnect and pick up
+		// where it left off.
+		srv.dropConnections()
+		tail, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, payload, append(head, tail...))
+		require.NoError(t, s.Delete(ctx, "resume"))
+	})
+
+	t.Run("sanitize", func(t *testing.T) {
+		uri := sftpURI(url.UserPassword("roach", password), addr, dir, string(clientKey), srv.knownHosts())
+		sanitized, err := cloudimpl.SanitizeExternalStorageURI(uri, nil)
+		require.NoError(t, err)
+		require.NotContains(t, sanitized, password)
+		u, err := url.Parse(sanitized)
+		require.NoError(t, err)
+		require.Equal(t, "redacted", u.Query().Get(cloudimpl.SFTPPrivateKeyParam))
+	})
+}
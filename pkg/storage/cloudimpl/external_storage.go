@@ -102,6 +102,7 @@ var redactedQueryParams = map[string]struct{}{
 	AWSTempTokenParam:    {},
 	AzureAccountKeyParam: {},
 	CredentialsParam:     {},
+	SFTPPrivateKeyParam:  {},
 }
 
 // ErrListingUnsupported is a marker for indicating listing is unsupported.
@@ -172,6 +173,11 @@ func ExternalStorageConfFromURI(path, user string) (roachpb.ExternalStorage, err
 	case "http", "https":
 		conf.Provider = roachpb.ExternalStorageProvider_Http
 		conf.HttpPath.BaseUri = path
+	case "sftp":
+		conf.Provider = roachpb.ExternalStorageProvider_Sftp
+		if conf.SftpConfig, err = parseSFTPURI(uri); err != nil {
+			return conf, err
+		}
 	case "nodelocal":
 		if uri.Host == "" {
 			return conf, errors.Errorf(
@@ -211,6 +217,16 @@ func ExternalStorageConfFromURI(path, user string) (roachpb.ExternalStorage, err
 		conf.FileTableConfig.User = user
 		conf.FileTableConfig.QualifiedTableName = qualifiedTableName
 		conf.FileTableConfig.Path = uri.Path
+	case sql.ExternalConnectionScheme:
+		if uri.Host == "" {
+			return conf, errors.Errorf("host component of external URI must name an external connection: %s", path)
+		}
+		conf.Provider = roachpb.ExternalStorageProvider_External
+		conf.ExternalConfig = &roachpb.ExternalStorage_External{
+			User: user,
+			Name: uri.Host,
+			Path: uri.Path,
+		}
 	default:
 		// TODO(adityamaru): Link dedicated ExternalStorage scheme docs once ready.
 		return conf, errors.Errorf("unsupported storage scheme: %q - refer to docs to find supported"+
@@ -254,6 +270,10 @@ func SanitizeExternalStorageURI(path string, extraParams []string) (string, erro
 		return path, nil
 	}
 
+	if _, ok := uri.User.Password(); ok {
+		uri.User = url.UserPassword(uri.User.Username(), "redacted")
+	}
+
 	params := uri.Query()
 	for param := range params {
 		if _, ok := redactedQueryParams[param]; ok {
@@ -271,6 +291,37 @@ func SanitizeExternalStorageURI(path string, extraParams []string) (string, erro
 	return uri.String(), nil
 }
 
+// makeExternalConnectionStorage resolves the named external connection and
+// returns an ExternalStorage for the URI it refers to.
+func makeExternalConnectionStorage(
+	ctx context.Context,
+	dest *roachpb.ExternalStorage_External,
+	conf base.ExternalIODirConfig,
+	settings *cluster.Settings,
+	blobClientFactory blobs.BlobClientFactory,
+	ie *sql.InternalExecutor,
+	kvDB *kv.DB,
+) (cloud.ExternalStorage, error) {
+	if ie == nil || kvDB == nil {
+		return nil, errors.New("cannot resolve external connections without access to the cluster")
+	}
+	uri, err := sql.ResolveExternalConnection(ctx, ie, kvDB, dest.User, dest.Name)
+	if err != nil {
+		return nil, err
+	}
+	if uri, err = sql.JoinExternalConnectionPath(uri, dest.Path); err != nil {
+		return nil, err
+	}
+	resolved, err := ExternalStorageConfFromURI(uri, dest.User)
+	if err != nil {
+		return nil, err
+	}
+	if resolved.Provider == roachpb.ExternalStorageProvider_External {
+		return nil, errors.New("an external connection cannot reference another external connection")
+	}
+	return MakeExternalStorage(ctx, resolved, conf, settings, blobClientFactory, ie, kvDB)
+}
+
 // MakeExternalStorage creates an ExternalStorage from the given config.
 func MakeExternalStorage(
 	ctx context.Context,
@@ -300,12 +351,18 @@ func MakeExternalStorage(
 	case roachpb.ExternalStorageProvider_Azure:
 		telemetry.Count("external-io.azure")
 		return makeAzureStorage(dest.AzureConfig, settings, conf)
+	case roachpb.ExternalStorageProvider_Sftp:
+		telemetry.Count("external-io.sftp")
+		return makeSFTPStorage(dest.SftpConfig, settings, conf)
 	case roachpb.ExternalStorageProvider_Workload:
 		telemetry.Count("external-io.workload")
 		return makeWorkloadStorage(dest.WorkloadConfig, settings, conf)
 	case roachpb.ExternalStorageProvider_FileTable:
 		telemetry.Count("external-io.filetable")
 		return makeFileTableStorage(ctx, dest.FileTableConfig, ie, kvDB, settings, conf)
+	case roachpb.ExternalStorageProvider_External:
+		telemetry.Count("external-io.external_connection")
+		return makeExternalConnectionStorage(ctx, dest.ExternalConfig, conf, settings, blobClientFactory, ie, kvDB)
 	}
 	return nil, errors.Errorf("unsupported external destination type: %s", dest.Provider.String())
 }
@@ -336,7 +393,7 @@ func URINeedsGlobExpansion(uri string) bool {
 // - implicit AUTH: access will use the node's machine account and only a
 // super user should have the authority to use these credentials.
 //
-// - HTTP/HTTPS/Custom endpoint: requests are made by the server, in the
+// - HTTP/HTTPS/SFTP/Custom endpoint: requests are made by the server, in the
 // server's network, potentially behind a firewall and only a super user should
 // be able to do this.
 //
@@ -363,10 +420,14 @@ func AccessIsWithExplicitAuth(path string) (bool, string, error) {
 		// Azure does not support implicit authentication i.e. all credentials have
 		// to be specified as part of the URI.
 		hasExplicitAuth = true
-	case "http", "https", "nodelocal":
+	case "http", "https", "sftp", "nodelocal":
 		hasExplicitAuth = false
 	case "experimental-workload", "workload", "userfile":
 		hasExplicitAuth = true
+	case sql.ExternalConnectionScheme:
+		// Access to an external connection is governed by the USAGE privilege
+		// on the connection, so it does not require super user privileges.
+		hasExplicitAuth = true
 	default:
 		return hasExplicitAuth, "", nil
 	}
@@ -0,0 +1,465 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cloudimpl
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	// SFTPPrivateKeyParam is the query parameter for the base64-encoded PEM
+	// private key used for public key authentication in an sftp URI.
+	SFTPPrivateKeyParam = "SFTP_PRIVATE_KEY"
+	// SFTPKnownHostsParam is the query parameter for the base64-encoded
+	// known_hosts entries used to verify the server's host key in an sftp URI.
+	SFTPKnownHostsParam = "SFTP_KNOWN_HOSTS"
+
+	defaultSFTPPort = "22"
+)
+
+// parseSFTPURI fills in an sftp ExternalStorage config from uri.
+func parseSFTPURI(uri *url.URL) (*roachpb.ExternalStorage_Sftp, error) {
+	conf := &roachpb.ExternalStorage_Sftp{
+		Host:   uri.Host,
+		Prefix: uri.Path,
+		/* NB: additions here should also update sftpQueryParams() serializer */
+	}
+	if conf.Host == "" {
+		return nil, errors.New("sftp uri missing host")
+	}
+	if uri.Port() == "" {
+		conf.Host = net.JoinHostPort(uri.Hostname(), defaultSFTPPort)
+	}
+	if uri.User != nil {
+		conf.User = uri.User.Username()
+		conf.Password, _ = uri.User.Password()
+	}
+	if conf.User == "" {
+		return nil, errors.New("sftp uri missing user")
+	}
+	if key := uri.Query().Get(SFTPPrivateKeyParam); key != "" {
+		decoded, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding value of %s", SFTPPrivateKeyParam)
+		}
+		conf.PrivateKey = string(decoded)
+	}
+	if conf.Password == "" && conf.PrivateKey == "" {
+		return nil, errors.Errorf(
+			"sftp uri must specify a password or a %q parameter", SFTPPrivateKeyParam)
+	}
+	knownHosts := uri.Query().Get(SFTPKnownHostsParam)
+	if knownHosts == "" {
+		return nil, errors.Errorf("sftp uri missing %q parameter", SFTPKnownHostsParam)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(knownHosts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding value of %s", SFTPKnownHostsParam)
+	}
+	conf.KnownHosts = string(decoded)
+	return conf, nil
+}
+
+func sftpQueryParams(conf *roachpb.ExternalStorage_Sftp) string {
+	q := make(url.Values)
+	if conf.PrivateKey != "" {
+		q.Set(SFTPPrivateKeyParam, base64.StdEncoding.EncodeToString([]byte(conf.PrivateKey)))
+	}
+	if conf.KnownHosts != "" {
+		q.Set(SFTPKnownHostsParam, base64.StdEncoding.EncodeToString([]byte(conf.KnownHosts)))
+	}
+	return q.Encode()
+}
+
+func sftpUserInfo(conf *roachpb.ExternalStorage_Sftp) *url.Userinfo {
+	if conf.Password != "" {
+		return url.UserPassword(conf.User, conf.Password)
+	}
+	return url.User(conf.User)
+}
+
+type sftpStorage struct {
+	conf     *roachpb.ExternalStorage_Sftp
+	ioConf   base.ExternalIODirConfig
+	prefix   string
+	settings *cluster.Settings
+	config   *ssh.ClientConfig
+
+	mu struct {
+		syncutil.Mutex
+		conn   *ssh.Client
+		client *sftp.Client
+	}
+}
+
+var _ cloud.ExternalStorage = &sftpStorage{}
+
+func makeSFTPStorage(
+	conf *roachpb.ExternalStorage_Sftp, settings *cluster.Settings, ioConf base.ExternalIODirConfig,
+) (cloud.ExternalStorage, error) {
+	if conf == nil {
+		return nil, errors.Errorf("sftp upload requested but info missing")
+	}
+	var auth []ssh.AuthMethod
+	if conf.PrivateKey != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(conf.PrivateKey))
+		if err != nil {
+			return nil, errors.Wrap(err, "sftp: parsing private key")
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if conf.Password != "" {
+		auth = append(auth, ssh.Password(conf.Password))
+	}
+	hostKeyCallback, err := makeSFTPHostKeyCallback(conf.KnownHosts)
+	if err != nil {
+		return nil, err
+	}
+	return &sftpStorage{
+		conf:     conf,
+		ioConf:   ioConf,
+		prefix:   conf.Prefix,
+		settings: settings,
+		config: &ssh.ClientConfig{
+			User:            conf.User,
+			Auth:            auth,
+			HostKeyCallback: hostKeyCallback,
+		},
+	}, nil
+}
+
+// makeSFTPHostKeyCallback returns a callback which verifies the server's host
+// key against the given known_hosts formatted entries.
+func makeSFTPHostKeyCallback(knownHosts string) (ssh.HostKeyCallback, error) {
+	if knownHosts == "" {
+		return nil, errors.New("sftp: known hosts must be specified")
+	}
+	// The knownhosts package only reads files, but it loads them eagerly so the
+	// file does not need to outlive the callback.
+	f, err := ioutil.TempFile("", "sftp-known-hosts")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+	if _, err := f.WriteString(knownHosts); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	cb, err := knownhosts.New(f.Name())
+	if err != nil {
+		return nil, errors.Wrap(err, "sftp: parsing known hosts")
+	}
+	return cb, nil
+}
+
+// getClient returns the current sftp client, connecting to the server first if
+// there is no open connection.
+func (s *sftpStorage) getClient(ctx context.Context) (*sftp.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mu.client != nil {
+		return s.mu.client, nil
+	}
+	var d net.Dialer
+	netConn, err := d.DialContext(ctx, "tcp", s.conf.Host)
+	if err != nil {
+		return nil, errors.Wrapf(err, "sftp: dialing %s", s.conf.Host)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(netConn, s.conf.Host, s.config)
+	if err != nil {
+		_ = netConn.Close()
+		return nil, errors.Wrapf(err, "sftp: connecting to %s", s.conf.Host)
+	}
+	conn := ssh.NewClient(sshConn, chans, reqs)
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, errors.Wrap(err, "sftp: starting session")
+	}
+	s.mu.conn, s.mu.client = conn, client
+	return client, nil
+}
+
+// resetClient closes the connection backing client, if it is still the current
+// one, so that the next call to getClient reconnects.
+func (s *sftpStorage) resetClient(client *sftp.Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mu.client != client {
+		return
+	}
+	_ = s.closeLocked()
+}
+
+func (s *sftpStorage) closeLocked() error {
+	if s.mu.client == nil {
+		return nil
+	}
+	_ = s.mu.client.Close()
+	err := s.mu.conn.Close()
+	s.mu.conn, s.mu.client = nil, nil
+	return err
+}
+
+func (s *sftpStorage) Conf() roachpb.ExternalStorage {
+	return roachpb.ExternalStorage{
+		Provider:   roachpb.ExternalStorageProvider_Sftp,
+		SftpConfig: s.conf,
+	}
+}
+
+func (s *sftpStorage) ExternalIOConf() base.ExternalIODirConfig {
+	return s.ioConf
+}
+
+func (s *sftpStorage) Settings() *cluster.Settings {
+	return s.settings
+}
+
+func (s *sftpStorage) WriteFile(ctx context.Context, basename string, content io.ReadSeeker) error {
+	err := contextutil.RunWithTimeout(ctx, "write sftp file", timeoutSetting.Get(&s.settings.SV),
+		func(ctx context.Context) error {
+			client, err := s.getClient(ctx)
+			if err != nil {
+				return err
+			}
+			dest := path.Join(s.prefix, basename)
+			if err := client.MkdirAll(path.Dir(dest)); err != nil {
+				return err
+			}
+			// Write to a temporary file and rename it into place so that readers
+			// never observe a partially written file.
+			tmp := dest + ".tmp"
+			f, err := client.Create(tmp)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, content); err != nil {
+				_ = f.Close()
+				_ = client.Remove(tmp)
+				return err
+			}
+			if err := f.Close(); err != nil {
+				_ = client.Remove(tmp)
+				return err
+			}
+			return client.PosixRename(tmp, dest)
+		})
+	return errors.Wrapf(err, "write file: %s", basename)
+}
+
+// resumingSFTPReader is an io.ReadCloser which, if the connection to the
+// server is lost before the whole file has been read, reconnects and resumes
+// reading from the last offset it returned.
+type resumingSFTPReader struct {
+	ctx    context.Context
+	s      *sftpStorage
+	name   string
+	client *sftp.Client
+	data   *sftp.File
+	size   int64
+	pos    int64
+}
+
+var _ io.ReadCloser = &resumingSFTPReader{}
+
+func (r *resumingSFTPReader) openStream() error {
+	return delayedRetry(r.ctx, func() error {
+		client, err := r.s.getClient(r.ctx)
+		if err != nil {
+			return err
+		}
+		f, err := client.Open(r.name)
+		if err != nil {
+			return err
+		}
+		if r.pos == 0 {
+			fi, err := f.Stat()
+			if err != nil {
+				_ = f.Close()
+				return err
+			}
+			r.size = fi.Size()
+		} else if _, err := f.Seek(r.pos, io.SeekStart); err != nil {
+			_ = f.Close()
+			return err
+		}
+		r.client, r.data = client, f
+		return nil
+	})
+}
+
+func (r *resumingSFTPReader) Read(p []byte) (int, error) {
+	var lastErr error
+	for retries := 0; lastErr == nil; retries++ {
+		if r.data == nil {
+			lastErr = r.openStream()
+		}
+
+		if lastErr == nil {
+			n, readErr := r.data.Read(p)
+			r.pos += int64(n)
+			if readErr == nil || (errors.Is(readErr, io.EOF) && r.pos >= r.size) {
+				return n, readErr
+			}
+			if n > 0 {
+				// Return what we have; the error resurfaces on the next call if
+				// it persists.
+				return n, nil
+			}
+			lastErr = readErr
+		}
+
+		if !errors.Is(lastErr, os.ErrNotExist) {
+			// The sftp client reports a lost connection as an EOF, so an EOF
+			// before the end of the file is treated as resumable.
+			if retries >= maxNoProgressReads {
+				return 0, errors.Wrap(lastErr, "multiple Read calls return no data")
+			}
+			log.Errorf(r.ctx, "SFTP:Retry: error %s", lastErr)
+			lastErr = nil
+			if r.data != nil {
+				_ = r.data.Close()
+				r.data = nil
+				r.s.resetClient(r.client)
+			}
+		}
+	}
+
+	return 0, lastErr
+}
+
+func (r *resumingSFTPReader) Close() error {
+	if r.data != nil {
+		return r.data.Close()
+	}
+	return nil
+}
+
+func (s *sftpStorage) ReadFile(ctx context.Context, basename string) (io.ReadCloser, error) {
+	reader := &resumingSFTPReader{
+		ctx:  ctx,
+		s:    s,
+		name: path.Join(s.prefix, basename),
+	}
+	if err := reader.openStream(); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, errors.Wrapf(ErrFileDoesNotExist, "sftp file does not exist: %s", err.Error())
+		}
+		return nil, errors.Wrap(err, "failed to create sftp reader")
+	}
+	return reader, nil
+}
+
+func (s *sftpStorage) ListFiles(ctx context.Context, patternSuffix string) ([]string, error) {
+	pattern := s.prefix
+	if patternSuffix != "" {
+		if containsGlob(s.prefix) {
+			return nil, errors.New("prefix cannot contain globs pattern when passing an explicit pattern")
+		}
+		pattern = path.Join(pattern, patternSuffix)
+	}
+	client, err := s.getClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := client.Glob(path.Clean(pattern))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list files in sftp directory")
+	}
+	sort.Strings(matches)
+
+	var fileList []string
+	for _, match := range matches {
+		if fi, err := client.Stat(match); err != nil || fi.IsDir() {
+			continue
+		}
+		if patternSuffix != "" {
+			if !strings.HasPrefix(match, s.prefix) {
+				// TODO(dt): return a nice rel-path instead of erroring out.
+				return nil, errors.New("pattern matched file outside of path")
+			}
+			fileList = append(fileList, strings.TrimPrefix(strings.TrimPrefix(match, s.prefix), "/"))
+		} else {
+			sftpURL := url.URL{
+				Scheme:   "sftp",
+				User:     sftpUserInfo(s.conf),
+				Host:     s.conf.Host,
+				Path:     match,
+				RawQuery: sftpQueryParams(s.conf),
+			}
+			fileList = append(fileList, sftpURL.String())
+		}
+	}
+
+	return fileList, nil
+}
+
+func (s *sftpStorage) Delete(ctx context.Context, basename string) error {
+	err := contextutil.RunWithTimeout(ctx, "delete sftp file", timeoutSetting.Get(&s.settings.SV),
+		func(ctx context.Context) error {
+			client, err := s.getClient(ctx)
+			if err != nil {
+				return err
+			}
+			return client.Remove(path.Join(s.prefix, basename))
+		})
+	return errors.Wrap(err, "delete file")
+}
+
+func (s *sftpStorage) Size(ctx context.Context, basename string) (int64, error) {
+	var fi os.FileInfo
+	err := contextutil.RunWithTimeout(ctx, "size sftp file", timeoutSetting.Get(&s.settings.SV),
+		func(ctx context.Context) error {
+			client, err := s.getClient(ctx)
+			if err != nil {
+				return err
+			}
+			fi, err = client.Stat(path.Join(s.prefix, basename))
+			return err
+		})
+	if err != nil {
+		return 0, errors.Wrap(err, "get file properties")
+	}
+	return fi.Size(), nil
+}
+
+func (s *sftpStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeLocked()
+}
@@ -927,6 +927,31 @@ func EncodeNullDescending(b []byte) []byte {
 	return append(b, encodedNullDesc)
 }
 
+// EncodeReversedNull encodes a NULL value for a key column whose NULL ordering
+// is reversed. In an ascending column the encoded NULL sorts after all
+// ascendingly encoded values (NULLS LAST), and in a descending column it sorts
+// before all descendingly encoded values (NULLS FIRST). The result is the
+// encoding of a NULL in the opposite direction, so it is decoded by
+// DecodeIfNull like any other NULL.
+func EncodeReversedNull(b []byte, dir Direction) []byte {
+	if dir == Ascending {
+		return EncodeNullDescending(b)
+	}
+	return EncodeNullAscending(b)
+}
+
+// IsReversedNull returns whether the input buffer starts with a NULL encoded
+// by EncodeReversedNull for the given direction.
+func IsReversedNull(b []byte, dir Direction) bool {
+	if len(b) == 0 {
+		return false
+	}
+	if dir == Ascending {
+		return b[0] == encodedNullDesc
+	}
+	return b[0] == encodedNull
+}
+
 // EncodeNotNullAscending encodes a value that is larger than the NULL marker encoded by
 // EncodeNull but less than any encoded value returned by EncodeVarint,
 // EncodeFloat, EncodeBytes or EncodeString.
@@ -957,6 +957,54 @@ func TestEncodeDecodeNull(t *testing.T) {
 	}
 }
 
+func TestEncodeReversedNull(t *testing.T) {
+	ascending := [][]byte{
+		EncodeNotNullAscending(nil),
+		EncodeVarintAscending(nil, math.MinInt64),
+		EncodeVarintAscending(nil, math.MaxInt64),
+		EncodeUvarintAscending(nil, math.MaxUint64),
+		EncodeFloatAscending(nil, math.NaN()),
+		EncodeFloatAscending(nil, math.Inf(1)),
+		EncodeStringAscending(nil, "\xff\xff"),
+		EncodeBytesAscending(nil, []byte("\xff\xff")),
+		EncodeDecimalAscending(nil, apd.New(1, 1000)),
+		EncodeTimeAscending(nil, timeutil.Unix(math.MaxInt32, 0)),
+	}
+	descending := [][]byte{
+		EncodeNotNullDescending(nil),
+		EncodeVarintDescending(nil, math.MinInt64),
+		EncodeVarintDescending(nil, math.MaxInt64),
+		EncodeUvarintDescending(nil, math.MaxUint64),
+		EncodeFloatDescending(nil, math.NaN()),
+		EncodeFloatDescending(nil, math.Inf(-1)),
+		EncodeStringDescending(nil, ""),
+		EncodeBytesDescending(nil, nil),
+		EncodeDecimalDescending(nil, apd.New(-1, 1000)),
+		EncodeTimeDescending(nil, timeutil.Unix(math.MinInt32, 0)),
+	}
+
+	nullsLast := EncodeReversedNull(nil, Ascending)
+	for _, b := range ascending {
+		if bytes.Compare(nullsLast, b) <= 0 {
+			t.Errorf("expected ascending NULLS LAST %x to sort after %x", nullsLast, b)
+		}
+	}
+	nullsFirst := EncodeReversedNull(nil, Descending)
+	for _, b := range descending {
+		if bytes.Compare(nullsFirst, b) >= 0 {
+			t.Errorf("expected descending NULLS FIRST %x to sort before %x", nullsFirst, b)
+		}
+	}
+
+	for _, b := range [][]byte{nullsLast, nullsFirst} {
+		if remaining, isNull := DecodeIfNull(b); !isNull {
+			t.Errorf("expected %x to decode as NULL", b)
+		} else if len(remaining) != 0 {
+			t.Errorf("unexpected remaining bytes: %x", remaining)
+		}
+	}
+}
+
 func TestEncodeDecodeInterleavedSentinel(t *testing.T) {
 	const hello = "hello"
 
@@ -0,0 +1,1316 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/bits"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/golang/snappy"
+)
+
+// Parquet enums only needed by the reader.
+const (
+	repetitionRequired = 0
+
+	encodingPlainDictionary    = 2
+	encodingDeltaBinaryPacked  = 5
+	encodingDeltaLengthByteArr = 6
+	encodingDeltaByteArray     = 7
+	encodingRLEDictionary      = 8
+
+	pageTypeDictionary = 2
+	pageTypeDataV2     = 3
+
+	convertedTimeMillis      = 7
+	convertedTimeMicros      = 8
+	convertedTimestampMillis = 9
+	convertedUint8           = 11
+	convertedUint64          = 14
+
+	timeUnitMillis = 1
+	timeUnitNanos  = 3
+)
+
+const (
+	// int96Length is the length of Int96 values.
+	int96Length = 12
+	// julianDayOfUnixEpoch is the Julian day number of the Unix epoch.
+	julianDayOfUnixEpoch = 2440588
+	// maxIndexBitWidth is the maximum bit width of dictionary indexes.
+	maxIndexBitWidth = 32
+)
+
+// schemaElement is a node of the schema tree, as stored in the footer.
+type schemaElement struct {
+	typ         PhysicalType
+	typeLength  int32
+	repetition  int32
+	name        string
+	numChildren int32
+	converted   int32
+	scale       int32
+	precision   int32
+	logical     LogicalType
+	// isList and isGroup are set for LIST annotated groups and for other
+	// groups respectively.
+	isList, isGroup bool
+
+	children []*schemaElement
+}
+
+// chunkInfo describes a column chunk of a row group.
+type chunkInfo struct {
+	typ            PhysicalType
+	codec          Codec
+	numValues      int64
+	dataPageOffset int64
+	dictPageOffset int64
+	// compressedSize is the size of the chunk's pages in the file.
+	compressedSize int64
+}
+
+type rowGroupInfo struct {
+	numRows int64
+	// byteSize is the uncompressed size of the row group's data.
+	byteSize int64
+	chunks   []chunkInfo
+}
+
+// fieldInfo describes how the values of a top-level field of the schema are
+// assembled from the levels and values of its column chunk.
+type fieldInfo struct {
+	// leaf is the index of the field's column chunk in each row group.
+	leaf int
+	// err is set if the field can't be read.
+	err error
+	// maxDef and maxRep are the maximum definition and repetition levels of
+	// the field's values. A value is non-NULL if its definition level is
+	// maxDef.
+	maxDef, maxRep int32
+	// listDef is the definition level of empty lists. Lists with a lower
+	// definition level are NULL.
+	listDef int32
+}
+
+// Reader reads Parquet files. Only the file's metadata is held in memory: the
+// column chunks of a row group are read from the file when the row group is
+// decoded, which allows the row groups of a file to be decoded concurrently.
+type Reader struct {
+	file      io.ReaderAt
+	size      int64
+	cols      []Column
+	fields    []fieldInfo
+	numRows   int64
+	rowGroups []rowGroupInfo
+}
+
+// NewReader returns a Reader for the Parquet file of the given size read
+// through file. file must allow concurrent calls to ReadAt.
+func NewReader(file io.ReaderAt, size int64) (*Reader, error) {
+	if size < int64(2*len(magic)+4) {
+		return nil, errors.New("not a parquet file")
+	}
+	head := make([]byte, len(magic))
+	tail := make([]byte, 4+len(magic))
+	if err := readAt(file, head, 0); err != nil {
+		return nil, err
+	}
+	if err := readAt(file, tail, size-int64(len(tail))); err != nil {
+		return nil, err
+	}
+	if string(head) != magic || string(tail[4:]) != magic {
+		return nil, errors.New("not a parquet file")
+	}
+	footerLenPos := size - int64(len(tail))
+	footerLen := int64(binary.LittleEndian.Uint32(tail))
+	if footerLen > footerLenPos-int64(len(magic)) {
+		return nil, errCorrupt
+	}
+	footer := make([]byte, footerLen)
+	if err := readAt(file, footer, footerLenPos-footerLen); err != nil {
+		return nil, err
+	}
+	r := &Reader{file: file, size: size}
+	t := &compactReader{buf: footer}
+	var schema []*schemaElement
+	if err := t.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch id {
+		case 2:
+			err = t.readList(func(byte) error {
+				el, err := decodeSchemaElement(t)
+				schema = append(schema, el)
+				return err
+			})
+		case 3:
+			r.numRows, err = t.readVarint()
+		case 4:
+			err = t.readList(func(byte) error {
+				rg, err := decodeRowGroup(t)
+				r.rowGroups = append(r.rowGroups, rg)
+				return err
+			})
+		default:
+			err = t.skip(typ)
+		}
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if err := r.initColumns(schema); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// readAt fills buf with the bytes of file at the given offset.
+func readAt(file io.ReaderAt, buf []byte, off int64) error {
+	n, err := file.ReadAt(buf, off)
+	if n == len(buf) {
+		// ReadAt may return io.EOF along with the last bytes of the file.
+		return nil
+	}
+	if err == nil || err == io.EOF {
+		err = errCorrupt
+	}
+	return err
+}
+
+func decodeSchemaElement(t *compactReader) (*schemaElement, error) {
+	el := &schemaElement{converted: -1}
+	hasType := false
+	err := t.readStruct(func(id int16, typ byte) error {
+		var v int32
+		var err error
+		switch id {
+		case 1:
+			v, err = t.readI32()
+			el.typ, hasType = PhysicalType(v), true
+		case 2:
+			el.typeLength, err = t.readI32()
+		case 3:
+			el.repetition, err = t.readI32()
+		case 4:
+			el.name, err = t.readString()
+		case 5:
+			el.numChildren, err = t.readI32()
+		case 6:
+			el.converted, err = t.readI32()
+		case 7:
+			el.scale, err = t.readI32()
+		case 8:
+			el.precision, err = t.readI32()
+		case 10:
+			err = decodeLogicalType(t, el)
+		default:
+			err = t.skip(typ)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	el.isGroup = !hasType
+	if el.logical == LogicalNone {
+		convertedToLogical(el)
+	}
+	return el, nil
+}
+
+// decodeLogicalType decodes the LogicalType union of a schema element.
+func decodeLogicalType(t *compactReader, el *schemaElement) error {
+	return t.readStruct(func(member int16, typ byte) error {
+		switch member {
+		case logicalString:
+			el.logical = LogicalString
+		case logicalList:
+			el.isList = true
+		case logicalEnum:
+			el.logical = LogicalEnum
+		case logicalJSON:
+			el.logical = LogicalJSON
+		case logicalUUID:
+			el.logical = LogicalUUID
+		case logicalDate:
+			el.logical = LogicalDate
+		case logicalDecimal:
+			el.logical = LogicalDecimal
+			return t.readStruct(func(id int16, typ byte) error {
+				var err error
+				switch id {
+				case 1:
+					el.scale, err = t.readI32()
+				case 2:
+					el.precision, err = t.readI32()
+				default:
+					err = t.skip(typ)
+				}
+				return err
+			})
+		case logicalTime, logicalTimestamp:
+			utc, unit := false, int16(0)
+			if err := t.readStruct(func(id int16, typ byte) error {
+				switch id {
+				case 1:
+					utc = typ == compactBoolTrue
+					return nil
+				case 2:
+					return t.readStruct(func(id int16, typ byte) error {
+						unit = id
+						return t.skip(typ)
+					})
+				default:
+					return t.skip(typ)
+				}
+			}); err != nil {
+				return err
+			}
+			el.logical = timeLogicalType(member == logicalTimestamp, utc, unit)
+			return nil
+		case logicalInteger:
+			var width int8
+			signed := false
+			if err := t.readStruct(func(id int16, typ byte) error {
+				switch id {
+				case 1:
+					b, err := t.readByte()
+					width = int8(b)
+					return err
+				case 2:
+					signed = typ == compactBoolTrue
+					return nil
+				default:
+					return t.skip(typ)
+				}
+			}); err != nil {
+				return err
+			}
+			switch {
+			case !signed:
+				el.logical = LogicalUnsigned
+			case width == 16:
+				el.logical = LogicalInt16
+			}
+			return nil
+		}
+		return t.skip(typ)
+	})
+}
+
+func timeLogicalType(timestamp, utc bool, unit int16) LogicalType {
+	if !timestamp {
+		switch unit {
+		case timeUnitMillis:
+			return LogicalTimeMillis
+		case timeUnitNanos:
+			return LogicalTimeNanos
+		default:
+			return LogicalTimeMicros
+		}
+	}
+	switch unit {
+	case timeUnitMillis:
+		if utc {
+			return LogicalTimestampMillisUTC
+		}
+		return LogicalTimestampMillis
+	case timeUnitNanos:
+		if utc {
+			return LogicalTimestampNanosUTC
+		}
+		return LogicalTimestampNanos
+	default:
+		if utc {
+			return LogicalTimestampMicrosUTC
+		}
+		return LogicalTimestampMicros
+	}
+}
+
+// convertedToLogical sets the logical type of a schema element written by an
+// implementation which only sets the legacy converted type.
+func convertedToLogical(el *schemaElement) {
+	switch c := el.converted; {
+	case c == convertedUTF8:
+		el.logical = LogicalString
+	case c == convertedList:
+		el.isList = true
+	case c == convertedEnum:
+		el.logical = LogicalEnum
+	case c == convertedDecimal:
+		el.logical = LogicalDecimal
+	case c == convertedDate:
+		el.logical = LogicalDate
+	case c == convertedTimeMillis:
+		el.logical = LogicalTimeMillis
+	case c == convertedTimeMicros:
+		el.logical = LogicalTimeMicros
+	// Timestamps annotated by converted types are in UTC.
+	case c == convertedTimestampMillis:
+		el.logical = LogicalTimestampMillisUTC
+	case c == convertedTimestampMicros:
+		el.logical = LogicalTimestampMicrosUTC
+	case c >= convertedUint8 && c <= convertedUint64:
+		el.logical = LogicalUnsigned
+	case c == convertedInt16:
+		el.logical = LogicalInt16
+	case c == convertedJSON:
+		el.logical = LogicalJSON
+	}
+}
+
+func decodeRowGroup(t *compactReader) (rowGroupInfo, error) {
+	var rg rowGroupInfo
+	err := t.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch id {
+		case 1:
+			err = t.readList(func(byte) error {
+				c, err := decodeColumnChunk(t)
+				rg.chunks = append(rg.chunks, c)
+				return err
+			})
+		case 2:
+			rg.byteSize, err = t.readVarint()
+		case 3:
+			rg.numRows, err = t.readVarint()
+		default:
+			err = t.skip(typ)
+		}
+		return err
+	})
+	return rg, err
+}
+
+func decodeColumnChunk(t *compactReader) (chunkInfo, error) {
+	var c chunkInfo
+	hasMeta := false
+	err := t.readStruct(func(id int16, typ byte) error {
+		if id != 3 {
+			return t.skip(typ)
+		}
+		hasMeta = true
+		return t.readStruct(func(id int16, typ byte) error {
+			var v int32
+			var err error
+			switch id {
+			case 1:
+				v, err = t.readI32()
+				c.typ = PhysicalType(v)
+			case 4:
+				v, err = t.readI32()
+				c.codec = Codec(v)
+			case 5:
+				c.numValues, err = t.readVarint()
+			case 7:
+				c.compressedSize, err = t.readVarint()
+			case 9:
+				c.dataPageOffset, err = t.readVarint()
+			case 11:
+				c.dictPageOffset, err = t.readVarint()
+			default:
+				err = t.skip(typ)
+			}
+			return err
+		})
+	})
+	if err == nil && !hasMeta {
+		err = errors.New("parquet files with external column chunks are not supported")
+	}
+	return c, err
+}
+
+// initColumns sets up the top-level columns of the file from the flattened
+// schema tree.
+func (r *Reader) initColumns(schema []*schemaElement) error {
+	if len(schema) == 0 {
+		return errCorrupt
+	}
+	pos := 1
+	var build func(el *schemaElement) error
+	build = func(el *schemaElement) error {
+		for i := int32(0); i < el.numChildren; i++ {
+			if pos >= len(schema) {
+				return errCorrupt
+			}
+			child := schema[pos]
+			pos++
+			if err := build(child); err != nil {
+				return err
+			}
+			el.children = append(el.children, child)
+		}
+		return nil
+	}
+	root := schema[0]
+	if err := build(root); err != nil {
+		return err
+	}
+
+	leaf := 0
+	for _, el := range root.children {
+		col, field := newField(el)
+		field.leaf = leaf
+		leaf += countLeaves(el)
+		r.cols = append(r.cols, col)
+		r.fields = append(r.fields, field)
+	}
+	for i := range r.rowGroups {
+		if len(r.rowGroups[i].chunks) != leaf {
+			return errCorrupt
+		}
+	}
+	return nil
+}
+
+func countLeaves(el *schemaElement) int {
+	if !el.isGroup {
+		return 1
+	}
+	n := 0
+	for _, child := range el.children {
+		n += countLeaves(child)
+	}
+	return n
+}
+
+// newField returns the column for a top-level field of the schema, and how
+// its values are assembled.
+func newField(el *schemaElement) (Column, fieldInfo) {
+	var field fieldInfo
+	if !el.isGroup {
+		col := leafColumn(el.name, el)
+		switch el.repetition {
+		case repetitionRequired:
+		case repetitionRepeated:
+			// A repeated primitive field is a list which is never NULL, and
+			// whose elements are never NULL.
+			col.List = true
+			field.maxDef, field.maxRep = 1, 1
+		default:
+			field.maxDef = 1
+		}
+		return col, field
+	}
+
+	col := Column{Name: el.name}
+	if !el.isList {
+		field.err = errors.Errorf("column %q: nested groups are not supported", el.name)
+		return col, field
+	}
+	if el.repetition == repetitionOptional {
+		field.listDef = 1
+	}
+	if len(el.children) != 1 || el.children[0].repetition != repetitionRepeated {
+		field.err = errors.Errorf("column %q: malformed list", el.name)
+		return col, field
+	}
+	field.maxDef, field.maxRep = field.listDef+1, 1
+	repeated := el.children[0]
+	elem := repeated
+	if repeated.isGroup {
+		// The repeated group of the standard three-level structure holds the
+		// element. For backward compatibility, a repeated group with several
+		// fields, or named "array" or "<list>_tuple", is the element itself.
+		if len(repeated.children) != 1 || repeated.name == "array" ||
+			repeated.name == el.name+"_tuple" {
+			field.err = errors.Errorf("column %q: lists of groups are not supported", el.name)
+			return col, field
+		}
+		elem = repeated.children[0]
+		if elem.isGroup || elem.repetition == repetitionRepeated {
+			field.err = errors.Errorf("column %q: nested lists are not supported", el.name)
+			return col, field
+		}
+		if elem.repetition == repetitionOptional {
+			field.maxDef++
+		}
+	}
+	col = leafColumn(el.name, elem)
+	col.List = true
+	return col, field
+}
+
+func leafColumn(name string, el *schemaElement) Column {
+	col := Column{
+		Name:      name,
+		Type:      el.typ,
+		Logical:   el.logical,
+		Precision: el.precision,
+		Scale:     el.scale,
+	}
+	// Some implementations set the type length of all columns.
+	if el.typ == FixedLenByteArray {
+		col.TypeLength = el.typeLength
+	}
+	return col
+}
+
+// Columns returns the top-level columns of the file. Columns holding nested
+// groups are included, but can't be read.
+func (r *Reader) Columns() []Column {
+	return r.cols
+}
+
+// NumRows returns the number of rows in the file.
+func (r *Reader) NumRows() int64 {
+	return r.numRows
+}
+
+// NumRowGroups returns the number of row groups in the file.
+func (r *Reader) NumRowGroups() int {
+	return len(r.rowGroups)
+}
+
+// RowGroupSize returns the uncompressed size of the data of a row group, as
+// recorded in the file's metadata. It approximates the memory needed to decode
+// the row group.
+func (r *Reader) RowGroupSize(idx int) int64 {
+	if idx < 0 || idx >= len(r.rowGroups) || r.rowGroups[idx].byteSize < 0 {
+		return 0
+	}
+	return r.rowGroups[idx].byteSize
+}
+
+// ReadRowGroup decodes the given columns of a row group and returns its rows.
+// Each row holds the values of the requested columns, in order, using the Go
+// types documented for Writer.AddRow. ReadRowGroup can be called concurrently.
+func (r *Reader) ReadRowGroup(idx int, cols []int) ([][]interface{}, error) {
+	if idx < 0 || idx >= len(r.rowGroups) {
+		return nil, errors.Errorf("row group %d out of range", idx)
+	}
+	rg := &r.rowGroups[idx]
+	if rg.numRows < 0 || rg.numRows > r.size {
+		return nil, errCorrupt
+	}
+	rows := make([][]interface{}, rg.numRows)
+	flat := make([]interface{}, int(rg.numRows)*len(cols))
+	for i := range rows {
+		rows[i] = flat[i*len(cols) : (i+1)*len(cols)]
+	}
+	for i, c := range cols {
+		if c < 0 || c >= len(r.cols) {
+			return nil, errors.Errorf("column %d out of range", c)
+		}
+		field := &r.fields[c]
+		if field.err != nil {
+			return nil, field.err
+		}
+		chunk := &rg.chunks[field.leaf]
+		if chunk.typ != r.cols[c].Type {
+			return nil, errCorrupt
+		}
+		d, err := r.readChunk(&r.cols[c], field, chunk)
+		if err != nil {
+			return nil, errors.Wrapf(err, "column %q", r.cols[c].Name)
+		}
+		if err := d.assemble(field, rows, i); err != nil {
+			return nil, errors.Wrapf(err, "column %q", r.cols[c].Name)
+		}
+	}
+	return rows, nil
+}
+
+// chunkData holds the decoded levels and non-NULL values of a column chunk.
+type chunkData struct {
+	defLevels []int32
+	repLevels []int32
+	values    []interface{}
+}
+
+// assemble sets the values of the column at position pos of the given rows.
+func (d *chunkData) assemble(field *fieldInfo, rows [][]interface{}, pos int) error {
+	row, next := -1, 0
+	for i := range d.defLevels {
+		def := d.defLevels[i]
+		if field.maxRep == 0 || d.repLevels[i] == 0 {
+			row++
+			if row >= len(rows) {
+				return errCorrupt
+			}
+		}
+		if field.maxRep == 0 {
+			if def == field.maxDef {
+				rows[row][pos] = d.values[next]
+				next++
+			}
+			continue
+		}
+		if def < field.listDef {
+			continue
+		}
+		list, _ := rows[row][pos].([]interface{})
+		if list == nil {
+			list = []interface{}{}
+		}
+		if def > field.listDef {
+			var v interface{}
+			if def == field.maxDef {
+				v = d.values[next]
+				next++
+			}
+			list = append(list, v)
+		}
+		rows[row][pos] = list
+	}
+	if row != len(rows)-1 {
+		return errCorrupt
+	}
+	return nil
+}
+
+// numValues returns the number of non-NULL values with the given definition
+// levels.
+func (field *fieldInfo) numValues(defLevels []int32) int {
+	n := 0
+	for _, l := range defLevels {
+		if l == field.maxDef {
+			n++
+		}
+	}
+	return n
+}
+
+// pageHeader holds the fields of a page header used by the reader.
+type pageHeader struct {
+	typ              int32
+	uncompressedSize int32
+	compressedSize   int32
+	numValues        int32
+	encoding         int32
+	defEncoding      int32
+	repEncoding      int32
+	// Set for version 2 data pages, whose levels are never compressed.
+	defLength, repLength int32
+	isCompressed         bool
+}
+
+func decodePageHeader(t *compactReader) (pageHeader, error) {
+	h := pageHeader{isCompressed: true}
+	err := t.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch id {
+		case 1:
+			h.typ, err = t.readI32()
+		case 2:
+			h.uncompressedSize, err = t.readI32()
+		case 3:
+			h.compressedSize, err = t.readI32()
+		case 5:
+			err = t.readStruct(func(id int16, typ byte) error {
+				var err error
+				switch id {
+				case 1:
+					h.numValues, err = t.readI32()
+				case 2:
+					h.encoding, err = t.readI32()
+				case 3:
+					h.defEncoding, err = t.readI32()
+				case 4:
+					h.repEncoding, err = t.readI32()
+				default:
+					err = t.skip(typ)
+				}
+				return err
+			})
+		case 7:
+			err = t.readStruct(func(id int16, typ byte) error {
+				var err error
+				switch id {
+				case 1:
+					h.numValues, err = t.readI32()
+				case 2:
+					h.encoding, err = t.readI32()
+				default:
+					err = t.skip(typ)
+				}
+				return err
+			})
+		case 8:
+			err = t.readStruct(func(id int16, typ byte) error {
+				var err error
+				switch id {
+				case 1:
+					h.numValues, err = t.readI32()
+				case 4:
+					h.encoding, err = t.readI32()
+				case 5:
+					h.defLength, err = t.readI32()
+				case 6:
+					h.repLength, err = t.readI32()
+				case 7:
+					h.isCompressed = typ == compactBoolTrue
+				default:
+					err = t.skip(typ)
+				}
+				return err
+			})
+		default:
+			err = t.skip(typ)
+		}
+		return err
+	})
+	if err == nil && (h.compressedSize < 0 || h.uncompressedSize < 0 || h.numValues < 0 ||
+		h.defLength < 0 || h.repLength < 0) {
+		err = errCorrupt
+	}
+	return h, err
+}
+
+// readChunk reads the pages of a column chunk from the file and decodes them.
+func (r *Reader) readChunk(col *Column, field *fieldInfo, chunk *chunkInfo) (*chunkData, error) {
+	start := chunk.dataPageOffset
+	if chunk.dictPageOffset > 0 && chunk.dictPageOffset < start {
+		start = chunk.dictPageOffset
+	}
+	if start < 0 || chunk.compressedSize < 0 || chunk.compressedSize > r.size-start ||
+		chunk.numValues < 0 || chunk.numValues > r.size {
+		return nil, errCorrupt
+	}
+	data := make([]byte, chunk.compressedSize)
+	if err := readAt(r.file, data, start); err != nil {
+		return nil, err
+	}
+	var pos int64
+	d := &chunkData{
+		defLevels: make([]int32, 0, chunk.numValues),
+		repLevels: make([]int32, 0, chunk.numValues),
+	}
+	var dict []interface{}
+	for int64(len(d.defLevels)) < chunk.numValues {
+		if pos >= int64(len(data)) {
+			return nil, errCorrupt
+		}
+		t := &compactReader{buf: data[pos:]}
+		h, err := decodePageHeader(t)
+		if err != nil {
+			return nil, err
+		}
+		pos += int64(t.pos)
+		if int64(h.compressedSize) > int64(len(data))-pos {
+			return nil, errCorrupt
+		}
+		page := data[pos : pos+int64(h.compressedSize)]
+		pos += int64(h.compressedSize)
+
+		switch h.typ {
+		case pageTypeDictionary:
+			body, err := decompress(chunk.codec, page, h.uncompressedSize)
+			if err != nil {
+				return nil, err
+			}
+			if h.encoding != encodingPlain && h.encoding != encodingPlainDictionary {
+				return nil, errors.Errorf("unsupported dictionary encoding %d", h.encoding)
+			}
+			if dict, _, err = decodePlain(col, body, int(h.numValues)); err != nil {
+				return nil, err
+			}
+		case pageTypeData:
+			body, err := decompress(chunk.codec, page, h.uncompressedSize)
+			if err != nil {
+				return nil, err
+			}
+			n := int(h.numValues)
+			if field.maxRep > 0 {
+				if body, err = readV1Levels(&d.repLevels, body, h.repEncoding, field.maxRep, n); err != nil {
+					return nil, err
+				}
+			}
+			start := len(d.defLevels)
+			if body, err = readV1Levels(&d.defLevels, body, h.defEncoding, field.maxDef, n); err != nil {
+				return nil, err
+			}
+			if err := d.readValues(col, h.encoding, body, dict, field.numValues(d.defLevels[start:])); err != nil {
+				return nil, err
+			}
+		case pageTypeDataV2:
+			if int(h.repLength)+int(h.defLength) > len(page) {
+				return nil, errCorrupt
+			}
+			n := int(h.numValues)
+			levels, values := page[:h.repLength+h.defLength], page[h.repLength+h.defLength:]
+			if h.isCompressed {
+				if values, err = decompress(
+					chunk.codec, values, h.uncompressedSize-h.repLength-h.defLength,
+				); err != nil {
+					return nil, err
+				}
+			}
+			if err := readLevels(&d.repLevels, levels[:h.repLength], field.maxRep, n); err != nil {
+				return nil, err
+			}
+			start := len(d.defLevels)
+			if err := readLevels(&d.defLevels, levels[h.repLength:], field.maxDef, n); err != nil {
+				return nil, err
+			}
+			if err := d.readValues(col, h.encoding, values, dict, field.numValues(d.defLevels[start:])); err != nil {
+				return nil, err
+			}
+		default:
+			// Index pages are skipped.
+		}
+	}
+	if field.maxRep == 0 {
+		d.repLevels = nil
+	}
+	return d, nil
+}
+
+func decompress(codec Codec, page []byte, uncompressedSize int32) ([]byte, error) {
+	var out []byte
+	var err error
+	switch codec {
+	case Uncompressed:
+		return page, nil
+	case Snappy:
+		out, err = snappy.Decode(nil, page)
+	case Gzip:
+		var gz *gzip.Reader
+		if gz, err = gzip.NewReader(bytes.NewReader(page)); err == nil {
+			out, err = ioutil.ReadAll(gz)
+		}
+	default:
+		return nil, errors.Errorf("unsupported parquet compression codec %d", codec)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(out) != int(uncompressedSize) {
+		return nil, errCorrupt
+	}
+	return out, nil
+}
+
+// readV1Levels reads the levels of a version 1 data page, which are prefixed
+// by their length, and returns the remainder of the page.
+func readV1Levels(
+	levels *[]int32, page []byte, encoding int32, maxLevel int32, n int,
+) ([]byte, error) {
+	if maxLevel == 0 {
+		return page, readLevels(levels, nil, 0, n)
+	}
+	if encoding != encodingRLE {
+		return nil, errors.Errorf("unsupported level encoding %d", encoding)
+	}
+	if len(page) < 4 {
+		return nil, errCorrupt
+	}
+	length := binary.LittleEndian.Uint32(page)
+	if uint64(length) > uint64(len(page)-4) {
+		return nil, errCorrupt
+	}
+	return page[4+length:], readLevels(levels, page[4:4+length], maxLevel, n)
+}
+
+// readLevels appends n levels encoded using the RLE/bit-packing hybrid
+// encoding to levels.
+func readLevels(levels *[]int32, buf []byte, maxLevel int32, n int) error {
+	if maxLevel == 0 {
+		for i := 0; i < n; i++ {
+			*levels = append(*levels, 0)
+		}
+		return nil
+	}
+	start := len(*levels)
+	var err error
+	*levels, _, err = decodeHybrid(*levels, buf, bits.Len32(uint32(maxLevel)), n)
+	if err != nil {
+		return err
+	}
+	for _, l := range (*levels)[start:] {
+		if l > maxLevel {
+			return errCorrupt
+		}
+	}
+	return nil
+}
+
+// readValues decodes the n non-NULL values of a data page.
+func (d *chunkData) readValues(
+	col *Column, encoding int32, buf []byte, dict []interface{}, n int,
+) error {
+	var values []interface{}
+	var err error
+	switch encoding {
+	case encodingPlain:
+		values, _, err = decodePlain(col, buf, n)
+	case encodingPlainDictionary, encodingRLEDictionary:
+		values, err = decodeDictionaryIndexes(buf, dict, n)
+	case encodingRLE:
+		if col.Type != Boolean {
+			return errors.Errorf("unsupported encoding %d for physical type %d", encoding, col.Type)
+		}
+		values, err = decodeRLEBools(buf, n)
+	case encodingDeltaBinaryPacked:
+		var ints []int64
+		if ints, _, err = decodeDeltaBinaryPacked(buf, n); err != nil {
+			break
+		}
+		values = make([]interface{}, n)
+		for i, v := range ints {
+			switch col.Type {
+			case Int32:
+				values[i] = int32(v)
+			case Int64:
+				values[i] = v
+			default:
+				return errors.Errorf("unsupported encoding %d for physical type %d", encoding, col.Type)
+			}
+		}
+	case encodingDeltaLengthByteArr:
+		values, _, err = decodeDeltaLengthByteArray(buf, n)
+	case encodingDeltaByteArray:
+		values, err = decodeDeltaByteArray(buf, n)
+	default:
+		return errors.Errorf("unsupported encoding %d", encoding)
+	}
+	if err != nil {
+		return err
+	}
+	d.values = append(d.values, values...)
+	return nil
+}
+
+// decodePlain decodes n PLAIN encoded values, returning them and the number
+// of bytes consumed.
+func decodePlain(col *Column, buf []byte, n int) ([]interface{}, int, error) {
+	if n > len(buf)*8 {
+		return nil, 0, errCorrupt
+	}
+	values := make([]interface{}, n)
+	pos := 0
+	fixed := func(size int) ([]byte, error) {
+		if size > len(buf)-pos {
+			return nil, errCorrupt
+		}
+		b := buf[pos : pos+size]
+		pos += size
+		return b, nil
+	}
+	for i := range values {
+		switch col.Type {
+		case Boolean:
+			if i/8 >= len(buf) {
+				return nil, 0, errCorrupt
+			}
+			values[i] = buf[i/8]&(1<<uint(i%8)) != 0
+			pos = (i + 8) / 8
+			continue
+		case Int32:
+			b, err := fixed(4)
+			if err != nil {
+				return nil, 0, err
+			}
+			values[i] = int32(binary.LittleEndian.Uint32(b))
+		case Int64:
+			b, err := fixed(8)
+			if err != nil {
+				return nil, 0, err
+			}
+			values[i] = int64(binary.LittleEndian.Uint64(b))
+		case Int96:
+			b, err := fixed(int96Length)
+			if err != nil {
+				return nil, 0, err
+			}
+			values[i] = b
+		case Float:
+			b, err := fixed(4)
+			if err != nil {
+				return nil, 0, err
+			}
+			values[i] = math.Float32frombits(binary.LittleEndian.Uint32(b))
+		case Double:
+			b, err := fixed(8)
+			if err != nil {
+				return nil, 0, err
+			}
+			values[i] = math.Float64frombits(binary.LittleEndian.Uint64(b))
+		case ByteArray:
+			b, err := fixed(4)
+			if err != nil {
+				return nil, 0, err
+			}
+			if b, err = fixed(int(binary.LittleEndian.Uint32(b))); err != nil {
+				return nil, 0, err
+			}
+			values[i] = b
+		case FixedLenByteArray:
+			if col.TypeLength <= 0 {
+				return nil, 0, errCorrupt
+			}
+			b, err := fixed(int(col.TypeLength))
+			if err != nil {
+				return nil, 0, err
+			}
+			values[i] = b
+		default:
+			return nil, 0, errors.Errorf("unsupported physical type %d", col.Type)
+		}
+	}
+	return values, pos, nil
+}
+
+// decodeDictionaryIndexes decodes n values encoded as indexes into the
+// dictionary of the column chunk. The indexes are encoded using the
+// RLE/bit-packing hybrid encoding, prefixed by their bit width.
+func decodeDictionaryIndexes(buf []byte, dict []interface{}, n int) ([]interface{}, error) {
+	if n == 0 {
+		return nil, nil
+	}
+	if len(buf) == 0 {
+		return nil, errCorrupt
+	}
+	if dict == nil {
+		return nil, errors.New("dictionary encoded page without a dictionary")
+	}
+	if int(buf[0]) > maxIndexBitWidth {
+		return nil, errCorrupt
+	}
+	indexes, _, err := decodeHybrid(make([]int32, 0, n), buf[1:], int(buf[0]), n)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, n)
+	for i, idx := range indexes {
+		if idx < 0 || int(idx) >= len(dict) {
+			return nil, errCorrupt
+		}
+		values[i] = dict[idx]
+	}
+	return values, nil
+}
+
+// decodeRLEBools decodes n booleans encoded using the RLE/bit-packing hybrid
+// encoding, prefixed by their length.
+func decodeRLEBools(buf []byte, n int) ([]interface{}, error) {
+	if len(buf) < 4 {
+		return nil, errCorrupt
+	}
+	length := binary.LittleEndian.Uint32(buf)
+	if uint64(length) > uint64(len(buf)-4) {
+		return nil, errCorrupt
+	}
+	ints, _, err := decodeHybrid(make([]int32, 0, n), buf[4:4+length], 1, n)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, n)
+	for i, v := range ints {
+		values[i] = v != 0
+	}
+	return values, nil
+}
+
+// decodeDeltaBinaryPacked decodes n integers using the DELTA_BINARY_PACKED
+// encoding, returning them and the number of bytes consumed. The deltas are
+// added with wraparound, so that values of Int32 columns are correct once
+// truncated to 32 bits.
+func decodeDeltaBinaryPacked(buf []byte, n int) ([]int64, int, error) {
+	pos := 0
+	uvarint := func() (uint64, error) {
+		v, k := binary.Uvarint(buf[pos:])
+		if k <= 0 {
+			return 0, errCorrupt
+		}
+		pos += k
+		return v, nil
+	}
+	blockSize, err := uvarint()
+	if err != nil {
+		return nil, 0, err
+	}
+	miniblocks, err := uvarint()
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := uvarint()
+	if err != nil {
+		return nil, 0, err
+	}
+	first, k := binary.Varint(buf[pos:])
+	if k <= 0 {
+		return nil, 0, errCorrupt
+	}
+	pos += k
+	if blockSize == 0 || miniblocks == 0 || blockSize%miniblocks != 0 ||
+		(blockSize/miniblocks)%8 != 0 || total != uint64(n) {
+		return nil, 0, errCorrupt
+	}
+	perMiniblock := int(blockSize / miniblocks)
+
+	values := make([]int64, 0, n)
+	if n > 0 {
+		values = append(values, first)
+	}
+	prev := first
+	for len(values) < n {
+		minDelta, k := binary.Varint(buf[pos:])
+		if k <= 0 || miniblocks > uint64(len(buf)-pos-k) {
+			return nil, 0, errCorrupt
+		}
+		pos += k
+		widths := buf[pos : pos+int(miniblocks)]
+		pos += len(widths)
+		for _, width := range widths {
+			if len(values) == n {
+				break
+			}
+			if width > 64 {
+				return nil, 0, errCorrupt
+			}
+			size := perMiniblock * int(width) / 8
+			if size > len(buf)-pos {
+				return nil, 0, errCorrupt
+			}
+			packed := buf[pos : pos+size]
+			pos += size
+			for i := 0; i < perMiniblock && len(values) < n; i++ {
+				prev += minDelta + int64(unpackBits(packed, uint(width), i))
+				values = append(values, prev)
+			}
+		}
+	}
+	return values, pos, nil
+}
+
+// decodeDeltaLengthByteArray decodes n byte arrays using the
+// DELTA_LENGTH_BYTE_ARRAY encoding, returning them and the number of bytes
+// consumed.
+func decodeDeltaLengthByteArray(buf []byte, n int) ([]interface{}, int, error) {
+	lengths, pos, err := decodeDeltaBinaryPacked(buf, n)
+	if err != nil {
+		return nil, 0, err
+	}
+	values := make([]interface{}, n)
+	for i, l := range lengths {
+		if l < 0 || l > int64(len(buf)-pos) {
+			return nil, 0, errCorrupt
+		}
+		values[i] = buf[pos : pos+int(l)]
+		pos += int(l)
+	}
+	return values, pos, nil
+}
+
+// decodeDeltaByteArray decodes n byte arrays using the DELTA_BYTE_ARRAY
+// encoding, which stores the length of the prefix each value shares with the
+// previous one, followed by the remaining suffixes.
+func decodeDeltaByteArray(buf []byte, n int) ([]interface{}, error) {
+	prefixes, pos, err := decodeDeltaBinaryPacked(buf, n)
+	if err != nil {
+		return nil, err
+	}
+	suffixes, _, err := decodeDeltaLengthByteArray(buf[pos:], n)
+	if err != nil {
+		return nil, err
+	}
+	var prev []byte
+	for i, p := range prefixes {
+		if p < 0 || p > int64(len(prev)) {
+			return nil, errCorrupt
+		}
+		suffix := suffixes[i].([]byte)
+		v := make([]byte, int(p)+len(suffix))
+		copy(v, prev[:p])
+		copy(v[p:], suffix)
+		suffixes[i], prev = v, v
+	}
+	return suffixes, nil
+}
+
+// decodeHybrid appends n values encoded with the RLE/bit-packing hybrid
+// encoding to out, returning the number of bytes consumed.
+func decodeHybrid(out []int32, buf []byte, bitWidth int, n int) ([]int32, int, error) {
+	if bitWidth > 32 {
+		return nil, 0, errCorrupt
+	}
+	byteWidth := (bitWidth + 7) / 8
+	pos := 0
+	for n > 0 {
+		header, k := binary.Uvarint(buf[pos:])
+		if k <= 0 {
+			return nil, 0, errCorrupt
+		}
+		pos += k
+		if header&1 == 0 {
+			// An RLE run of a single value.
+			count := header >> 1
+			if byteWidth > len(buf)-pos || count == 0 {
+				return nil, 0, errCorrupt
+			}
+			var v uint32
+			for i := 0; i < byteWidth; i++ {
+				v |= uint32(buf[pos+i]) << (8 * uint(i))
+			}
+			pos += byteWidth
+			for ; count > 0 && n > 0; count-- {
+				out = append(out, int32(v))
+				n--
+			}
+			continue
+		}
+		// A run of bit-packed groups of 8 values.
+		groups := header >> 1
+		if groups == 0 || groups*uint64(bitWidth) > uint64(len(buf)-pos) {
+			return nil, 0, errCorrupt
+		}
+		packed := buf[pos : pos+int(groups)*bitWidth]
+		pos += len(packed)
+		for i := 0; i < int(groups)*8 && n > 0; i++ {
+			out = append(out, int32(unpackBits(packed, uint(bitWidth), i)))
+			n--
+		}
+	}
+	return out, pos, nil
+}
+
+// unpackBits returns the i-th value of a sequence of bit-packed values of the
+// given width, packed starting from the least significant bit. The caller
+// must ensure buf is large enough.
+func unpackBits(buf []byte, width uint, i int) uint64 {
+	if width == 0 {
+		return 0
+	}
+	bit := uint64(i) * uint64(width)
+	start := bit / 8
+	shift := uint(bit % 8)
+	var v uint64
+	for j := uint(0); j < 9 && start+uint64(j) < uint64(len(buf)) && 8*j < width+shift; j++ {
+		b := uint64(buf[start+uint64(j)])
+		if 8*j >= shift {
+			v |= b << (8*j - shift)
+		} else {
+			v |= b >> (shift - 8*j)
+		}
+	}
+	if width < 64 {
+		v &= 1<<width - 1
+	}
+	return v
+}
+
+// Int96Time returns the time held by an Int96 value, which is how some
+// implementations store timestamps with nanosecond precision: the nanoseconds
+// of the day followed by the Julian day number, both little endian.
+func Int96Time(b []byte) time.Time {
+	nanos := int64(binary.LittleEndian.Uint64(b[:8]))
+	days := int64(binary.LittleEndian.Uint32(b[8:12])) - julianDayOfUnixEpoch
+	return time.Unix(days*86400, nanos).UTC()
+}
@@ -0,0 +1,356 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactReader(t *testing.T) {
+	var w compactWriter
+	w.structBegin()
+	w.i32Field(1, -7)
+	// Fields of every type, which are skipped.
+	w.boolField(2, true)
+	w.byteField(3, 5)
+	w.stringField(4, "skipped")
+	w.structField(5, func() {
+		w.i64Field(1, 1<<40)
+		w.structField(2, func() {})
+	})
+	w.fieldBegin(6, compactList)
+	w.listBegin(compactBoolTrue, 2)
+	w.buf = append(w.buf, 1, 0)
+	w.fieldBegin(7, compactMap)
+	w.writeUvarint(1)
+	w.buf = append(w.buf, compactBinary<<4|compactI32)
+	w.writeString("k")
+	w.writeVarint(3)
+	w.fieldBegin(8, compactDouble)
+	w.buf = append(w.buf, make([]byte, 8)...)
+	w.stringField(100, "long form")
+	w.fieldBegin(101, compactList)
+	w.listBegin(compactI32, 20)
+	for i := 0; i < 20; i++ {
+		w.writeVarint(int64(i))
+	}
+	w.structEnd()
+
+	r := &compactReader{buf: w.buf}
+	var i32 int32
+	var s string
+	var list []int32
+	require.NoError(t, r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch id {
+		case 1:
+			i32, err = r.readI32()
+		case 100:
+			s, err = r.readString()
+		case 101:
+			err = r.readList(func(byte) error {
+				v, err := r.readI32()
+				list = append(list, v)
+				return err
+			})
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	}))
+	require.Equal(t, len(w.buf), r.pos)
+	require.Equal(t, int32(-7), i32)
+	require.Equal(t, "long form", s)
+	require.Len(t, list, 20)
+	require.Equal(t, int32(19), list[19])
+
+	for i := 0; i < len(w.buf)-1; i++ {
+		r := &compactReader{buf: w.buf[:i]}
+		require.Error(t, r.skip(compactStruct), "truncated at %d", i)
+	}
+}
+
+func TestDecodeHybrid(t *testing.T) {
+	buf := []byte{
+		3 << 1, 5, // RLE run of three 5s
+		1<<1 | 1, 0x88, 0xc6, 0xfa, // bit-packed group of 0..7, 3 bits each
+		60 << 1, 2, // RLE run of sixty 2s, of which only one is read
+	}
+	values, n, err := decodeHybrid(nil, buf, 3, 12)
+	require.NoError(t, err)
+	require.Equal(t, len(buf), n)
+	require.Equal(t, []int32{5, 5, 5, 0, 1, 2, 3, 4, 5, 6, 7, 2}, values)
+
+	_, _, err = decodeHybrid(nil, buf, 3, 100)
+	require.Error(t, err)
+	_, _, err = decodeHybrid(nil, buf[:4], 3, 12)
+	require.Error(t, err)
+}
+
+func TestUnpackBits(t *testing.T) {
+	buf := []byte{0xff, 0x00, 0xff, 0x00, 0xff, 0x00, 0xff, 0x00, 0xff, 0x01}
+	require.Equal(t, uint64(0x00ff), unpackBits(buf, 16, 0))
+	require.Equal(t, uint64(0x01fe), unpackBits(buf, 15, 1))
+	require.Equal(t, uint64(0x00ff00ff00ff00ff), unpackBits(buf, 64, 0))
+	require.Equal(t, uint64(0x01ff), unpackBits(buf[8:], 9, 0))
+	require.Equal(t, uint64(0), unpackBits(nil, 0, 5))
+}
+
+func TestDecodeDelta(t *testing.T) {
+	// The values 7, 5, 3, 8, 8 have the deltas -2, -2, 5, 0. Relative to the
+	// minimum delta they are 0, 0, 7, 2, and fit in 3 bits.
+	ints := []byte{
+		8, 1, 5, 7 << 1, // block size, miniblocks per block, count, first value
+		3, 3, // minimum delta of -2, bit width of the miniblock
+		0xc0, 0x05, 0x00, // miniblock
+	}
+	values, n, err := decodeDeltaBinaryPacked(ints, 5)
+	require.NoError(t, err)
+	require.Equal(t, len(ints), n)
+	require.Equal(t, []int64{7, 5, 3, 8, 8}, values)
+	_, _, err = decodeDeltaBinaryPacked(ints, 4)
+	require.Error(t, err)
+	_, _, err = decodeDeltaBinaryPacked(ints[:len(ints)-1], 5)
+	require.Error(t, err)
+
+	// Lengths of 2, 0, 1.
+	lengths := []byte{8, 1, 3, 2 << 1, 3, 2, 0x0c, 0x00}
+	arrays, n, err := decodeDeltaLengthByteArray(append(lengths, "abc"...), 3)
+	require.NoError(t, err)
+	require.Equal(t, len(lengths)+3, n)
+	require.Equal(t, []interface{}{[]byte("ab"), []byte{}, []byte("c")}, arrays)
+
+	// Prefix lengths of 0, 2, 1, followed by the suffixes.
+	prefixes := []byte{8, 1, 3, 0, 1, 2, 0x03, 0x00}
+	arrays, err = decodeDeltaByteArray(append(append(prefixes, lengths...), "abc"...), 3)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{[]byte("ab"), []byte("ab"), []byte("ac")}, arrays)
+}
+
+func TestDecodeRLEBools(t *testing.T) {
+	values, err := decodeRLEBools([]byte{4, 0, 0, 0, 2 << 1, 1, 1<<1 | 1, 0x05}, 6)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{true, true, true, false, true, false}, values)
+}
+
+func TestReader(t *testing.T) {
+	cols := []Column{
+		{Name: "b", Type: Boolean},
+		{Name: "i32", Type: Int32, Logical: LogicalInt16},
+		{Name: "i64", Type: Int64, Logical: LogicalTimestampMicrosUTC},
+		{Name: "f", Type: Float},
+		{Name: "d", Type: Double},
+		{Name: "s", Type: ByteArray, Logical: LogicalString},
+		{Name: "dec", Type: FixedLenByteArray, Logical: LogicalDecimal,
+			TypeLength: DecimalLength(10), Precision: 10, Scale: 2},
+		{Name: "l", Type: ByteArray, Logical: LogicalJSON, List: true},
+	}
+	makeRow := func(i int) []interface{} {
+		if i%5 == 0 {
+			return make([]interface{}, len(cols))
+		}
+		dec, err := AppendDecimal(nil, big.NewInt(int64(i-50)), 5)
+		require.NoError(t, err)
+		list := []interface{}{[]byte(fmt.Sprint(i)), nil, []byte("[]")}[:i%5-1]
+		return []interface{}{
+			i%2 == 0, int32(i), int64(i) << 40, float32(i) / 2, float64(i) / 4,
+			[]byte(fmt.Sprint("s", i)), dec, list,
+		}
+	}
+
+	for _, codec := range []Codec{Uncompressed, Snappy, Gzip} {
+		t.Run(fmt.Sprint(codec), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, cols, codec)
+			require.NoError(t, err)
+			for i := 0; i < 100; i++ {
+				require.NoError(t, w.AddRow(makeRow(i)))
+				if i == 30 {
+					require.NoError(t, w.Flush())
+				}
+			}
+			require.NoError(t, w.Close())
+
+			r, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			require.NoError(t, err)
+			require.Equal(t, cols, r.Columns())
+			require.Equal(t, int64(100), r.NumRows())
+			require.Equal(t, 2, r.NumRowGroups())
+
+			// Read the columns in reverse order.
+			order := make([]int, len(cols))
+			for i := range order {
+				order[i] = len(cols) - 1 - i
+			}
+			var i int
+			for rg := 0; rg < r.NumRowGroups(); rg++ {
+				rows, err := r.ReadRowGroup(rg, order)
+				require.NoError(t, err)
+				for _, row := range rows {
+					expected := makeRow(i)
+					for j, c := range order {
+						require.Equal(t, expected[c], row[j], "row %d column %s", i, cols[c].Name)
+					}
+					i++
+				}
+			}
+			require.Equal(t, 100, i)
+
+			_, err = r.ReadRowGroup(2, order)
+			require.Error(t, err)
+			_, err = r.ReadRowGroup(0, []int{len(cols)})
+			require.Error(t, err)
+		})
+	}
+}
+
+// TestReaderDataPageV2 reads a file written by another implementation, see
+// testdata/README.md.
+func TestReaderDataPageV2(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "data_page_v2.parquet"))
+	require.NoError(t, err)
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Equal(t, []Column{
+		{Name: "id", Type: Int64},
+		{Name: "small", Type: Int32},
+		{Name: "name", Type: ByteArray, Logical: LogicalString},
+		{Name: "tag", Type: ByteArray, Logical: LogicalString},
+		{Name: "flag", Type: Boolean},
+		{Name: "f32", Type: Float},
+		{Name: "f64", Type: Double},
+		{Name: "ts", Type: Int64, Logical: LogicalTimestampMillisUTC},
+		{Name: "date", Type: Int32, Logical: LogicalDate},
+		{Name: "dec", Type: Int64, Logical: LogicalDecimal, Precision: 12, Scale: 2},
+		{Name: "uuid", Type: FixedLenByteArray, TypeLength: 16},
+		{Name: "old", Type: Int96},
+		{Name: "tags", Type: ByteArray, Logical: LogicalString, List: true},
+		{Name: "nums", Type: Int64, List: true},
+	}, r.Columns())
+	require.Equal(t, 3, r.NumRowGroups())
+
+	cols := make([]int, len(r.Columns()))
+	for i := range cols {
+		cols[i] = i
+	}
+	var i int
+	for rg := 0; rg < r.NumRowGroups(); rg++ {
+		rows, err := r.ReadRowGroup(rg, cols)
+		require.NoError(t, err)
+		for _, row := range rows {
+			uuid := make([]byte, 16)
+			uuid[15] = byte(i)
+			expected := []interface{}{
+				int64(i) * 1000, nil, nil, []byte(fmt.Sprintf("tag-%03d", i/3)), nil,
+				float32(i) / 4, nil, 1577836800000 + int64(i)*1001, int32(18262 + i),
+				int64(i*12345 - 500000), uuid, nil, []interface{}{}, []interface{}{},
+			}
+			if i%4 != 0 {
+				expected[1] = int32(i - 100)
+				expected[2] = []byte(fmt.Sprintf("name%d", i%5))
+				expected[4] = i%3 == 0
+				expected[6] = float64(i % 7)
+			}
+			for j := 0; j < i%4; j++ {
+				expected[12] = append(expected[12].([]interface{}), []byte(fmt.Sprint("t", j)))
+				expected[13] = append(expected[13].([]interface{}), int64(i+j))
+			}
+			require.Equal(t, time.Date(2020, 1, 1, 0, 0, 0, i*1000, time.UTC), Int96Time(row[11].([]byte)))
+			row[11] = nil
+			require.Equal(t, expected, row, "row %d", i)
+			i++
+		}
+	}
+	require.Equal(t, 60, i)
+}
+
+func TestReaderErrors(t *testing.T) {
+	newReader := func(data []byte) (*Reader, error) {
+		return NewReader(bytes.NewReader(data), int64(len(data)))
+	}
+	_, err := newReader([]byte("PAR1"))
+	require.EqualError(t, err, "not a parquet file")
+	_, err = newReader([]byte("PAR1\xff\xff\xff\xffPAR1"))
+	require.Error(t, err)
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, []Column{{Name: "i", Type: Int64}}, Snappy)
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, w.AddRow([]interface{}{int64(i)}))
+	}
+	require.NoError(t, w.Close())
+	data := buf.Bytes()
+
+	// Corrupting the pages or the footer results in errors, not panics.
+	for i := len(magic); i < len(data)-len(magic); i++ {
+		corrupt := append([]byte(nil), data...)
+		corrupt[i] ^= 0xff
+		r, err := newReader(corrupt)
+		if err != nil {
+			continue
+		}
+		_, _ = r.ReadRowGroup(0, []int{0})
+	}
+}
+
+// countingReaderAt counts the bytes read from a file.
+type countingReaderAt struct {
+	r *bytes.Reader
+	n int64
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.n += int64(n)
+	return n, err
+}
+
+// TestReaderReadsChunks checks that only the footer and the requested column
+// chunks are read from the file.
+func TestReaderReadsChunks(t *testing.T) {
+	cols := []Column{{Name: "a", Type: ByteArray}, {Name: "b", Type: ByteArray}}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, cols, Uncompressed)
+	require.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, w.AddRow([]interface{}{[]byte(fmt.Sprint("a", i)), []byte(fmt.Sprint("b", i))}))
+		if i == 499 {
+			require.NoError(t, w.Flush())
+		}
+	}
+	require.NoError(t, w.Close())
+
+	file := &countingReaderAt{r: bytes.NewReader(buf.Bytes())}
+	r, err := NewReader(file, int64(buf.Len()))
+	require.NoError(t, err)
+	footer := file.n
+	require.Less(t, footer, int64(buf.Len())/10)
+	require.Greater(t, r.RowGroupSize(0), int64(0))
+
+	rows, err := r.ReadRowGroup(1, []int{1})
+	require.NoError(t, err)
+	require.Len(t, rows, 500)
+	require.Equal(t, []byte("b500"), rows[0][0])
+	// The chunk of a single column of one of two row groups is a quarter of
+	// the file.
+	read := file.n - footer
+	require.Greater(t, read, int64(buf.Len())/5)
+	require.Less(t, read, int64(buf.Len())/3)
+}
@@ -0,0 +1,333 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var flagRewriteWritten = flag.Bool(
+	"rewrite-written", false, "rewrite the files of testdata written by the writer")
+
+// The reference files of testdata are written and dumped by the Apache Arrow
+// implementation, see testdata/README.md. Each file.parquet has a file.txt
+// holding the values Arrow reads from it, in the format of dumpFile.
+
+// TestReaderArrowFiles checks that the reader reads the values Arrow wrote.
+func TestReaderArrowFiles(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "arrow_*.parquet"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			data, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			expected, err := ioutil.ReadFile(strings.TrimSuffix(path, ".parquet") + ".txt")
+			require.NoError(t, err)
+			require.Equal(t, string(expected), dumpFile(t, data))
+		})
+	}
+}
+
+// writtenColumns are the columns of the files written by
+// TestWriterArrowFiles, which cover the columns written by EXPORT.
+var writtenColumns = []Column{
+	{Name: "b", Type: Boolean},
+	{Name: "i16", Type: Int32, Logical: LogicalInt16},
+	{Name: "i32", Type: Int32},
+	{Name: "i64", Type: Int64},
+	{Name: "f32", Type: Float},
+	{Name: "f64", Type: Double},
+	{Name: "dec", Type: FixedLenByteArray, Logical: LogicalDecimal,
+		TypeLength: DecimalLength(9), Precision: 9, Scale: 2},
+	{Name: "dec_large", Type: FixedLenByteArray, Logical: LogicalDecimal,
+		TypeLength: DecimalLength(30), Precision: 30, Scale: 5},
+	{Name: "s", Type: ByteArray, Logical: LogicalString},
+	{Name: "bin", Type: ByteArray},
+	{Name: "date", Type: Int32, Logical: LogicalDate},
+	{Name: "time", Type: Int64, Logical: LogicalTimeMicros},
+	{Name: "ts", Type: Int64, Logical: LogicalTimestampMicros},
+	{Name: "ts_utc", Type: Int64, Logical: LogicalTimestampMicrosUTC},
+	{Name: "uuid", Type: FixedLenByteArray, Logical: LogicalUUID, TypeLength: 16},
+	{Name: "json", Type: ByteArray, Logical: LogicalJSON},
+	{Name: "enum", Type: ByteArray, Logical: LogicalEnum},
+	{Name: "ints", Type: Int64, List: true},
+	{Name: "strs", Type: ByteArray, Logical: LogicalString, List: true},
+}
+
+// writtenRow returns the ith row of the files written by TestWriterArrowFiles.
+// Every value is NULL in one row out of seven, at a different row for each
+// column.
+func writtenRow(t *testing.T, i int) []interface{} {
+	n := int64(i) - 150
+	dec, err := AppendDecimal(nil, big.NewInt(n*1234567%999999999), int(DecimalLength(9)))
+	require.NoError(t, err)
+	decLarge := new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18))
+	decLarge.Add(decLarge, big.NewInt(int64(i)))
+	decLargeBytes, err := AppendDecimal(nil, decLarge, int(DecimalLength(30)))
+	require.NoError(t, err)
+	var u uuid.UUID
+	u[0], u[15] = byte(i), byte(i%11)
+	ints := make([]interface{}, i%4)
+	for j := range ints {
+		if j != 2 {
+			ints[j] = n + int64(j)
+		}
+	}
+	strs := make([]interface{}, i%3)
+	for j := range strs {
+		strs[j] = []byte(fmt.Sprint("e", (i+j)%5))
+	}
+	row := []interface{}{
+		i%3 == 0,
+		int32(n * 97),
+		int32(n * 123457),
+		n << 40,
+		float32(n) / 3,
+		float64(n) / 7,
+		dec,
+		decLargeBytes,
+		[]byte(fmt.Sprintf("string %d\t\"%s\"", i%13, strings.Repeat("é", i%4))),
+		[]byte{byte(i), 0, byte(i % 5), 0xff}[:i%5],
+		int32(n * 37),
+		int64(i) * 287999999,
+		n * 86400123457,
+		n * 86400123457,
+		u.GetBytes(),
+		[]byte(fmt.Sprintf(`{"a": [%d, null], "b": "%d"}`, i%6, n)),
+		[]byte(fmt.Sprint("v", i%3)),
+		ints,
+		strs,
+	}
+	for c := range row {
+		if (i+c)%7 == 0 {
+			row[c] = nil
+		}
+	}
+	return row
+}
+
+// writeFile writes 300 rows of writtenColumns in row groups of 128 rows.
+func writeFile(t *testing.T, codec Codec) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, writtenColumns, codec)
+	require.NoError(t, err)
+	for i := 0; i < 300; i++ {
+		require.NoError(t, w.AddRow(writtenRow(t, i)))
+		if w.BufferedRows() == 128 {
+			require.NoError(t, w.Flush())
+		}
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// TestWriterArrowFiles checks that the writer writes the files of testdata
+// that Arrow was checked to read, and that both Arrow and the reader read the
+// values that were written. After changing the writer, rewrite the files with
+// -rewrite-written and dump them with Arrow again.
+func TestWriterArrowFiles(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		codec Codec
+	}{
+		{"uncompressed", Uncompressed},
+		{"snappy", Snappy},
+		{"gzip", Gzip},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join("testdata", "written_"+tc.name+".parquet")
+			data := writeFile(t, tc.codec)
+			if *flagRewriteWritten {
+				require.NoError(t, ioutil.WriteFile(path, data, 0644))
+				return
+			}
+			expected, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			// The output of compress/gzip may change between Go releases, so
+			// only the values of the gzip file are compared.
+			if tc.codec != Gzip && !bytes.Equal(expected, data) {
+				t.Fatalf("%s differs from the written file; rewrite it with -rewrite-written "+
+					"and check it with Arrow, see testdata/README.md", path)
+			}
+			dump, err := ioutil.ReadFile(strings.TrimSuffix(path, ".parquet") + ".txt")
+			require.NoError(t, err)
+			require.Equal(t, string(dump), dumpFile(t, data))
+
+			// Check the dump against the written values as well, so that the
+			// values Arrow reads are those which were written.
+			r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+			require.NoError(t, err)
+			var expectedDump strings.Builder
+			writeDumpHeader(&expectedDump, r.Columns())
+			for i := 0; i < 300; i++ {
+				writeDumpRow(&expectedDump, writtenColumns, writtenRow(t, i))
+			}
+			require.Equal(t, expectedDump.String(), string(dump))
+		})
+	}
+}
+
+// dumpFile reads a file and formats its values: a line with the names of the
+// columns, and a line per row, with tabs between the values.
+func dumpFile(t *testing.T, data []byte) string {
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	cols := r.Columns()
+	all := make([]int, len(cols))
+	for i := range all {
+		all[i] = i
+	}
+	var out strings.Builder
+	writeDumpHeader(&out, cols)
+	for rg := 0; rg < r.NumRowGroups(); rg++ {
+		rows, err := r.ReadRowGroup(rg, all)
+		require.NoError(t, err)
+		for _, row := range rows {
+			writeDumpRow(&out, cols, row)
+		}
+	}
+	return out.String()
+}
+
+func writeDumpHeader(out *strings.Builder, cols []Column) {
+	names := make([]string, len(cols))
+	for i := range cols {
+		names[i] = cols[i].Name
+	}
+	out.WriteString(strings.Join(names, "\t") + "\n")
+}
+
+func writeDumpRow(out *strings.Builder, cols []Column, row []interface{}) {
+	vals := make([]string, len(cols))
+	for i := range cols {
+		vals[i] = formatValue(&cols[i], row[i])
+	}
+	out.WriteString(strings.Join(vals, "\t") + "\n")
+}
+
+func formatValue(col *Column, v interface{}) string {
+	if v == nil {
+		return "NULL"
+	}
+	if col.List {
+		elemCol := *col
+		elemCol.List = false
+		elems := v.([]interface{})
+		strs := make([]string, len(elems))
+		for i, e := range elems {
+			strs[i] = formatValue(&elemCol, e)
+		}
+		return "[" + strings.Join(strs, ", ") + "]"
+	}
+	switch col.Logical {
+	case LogicalString, LogicalEnum, LogicalJSON:
+		return strconv.Quote(string(v.([]byte)))
+	case LogicalUUID:
+		return uuid.FromBytesOrNil(v.([]byte)).String()
+	case LogicalDate:
+		return time.Unix(int64(v.(int32))*24*60*60, 0).UTC().Format("2006-01-02")
+	case LogicalTimeMillis:
+		return formatTimeOfDay(time.Duration(v.(int32)) * time.Millisecond)
+	case LogicalTimeMicros:
+		return formatTimeOfDay(time.Duration(v.(int64)) * time.Microsecond)
+	case LogicalTimeNanos:
+		return formatTimeOfDay(time.Duration(v.(int64)))
+	case LogicalTimestampMillis, LogicalTimestampMillisUTC:
+		return formatTimestamp(time.Unix(0, v.(int64)*int64(time.Millisecond)),
+			col.Logical == LogicalTimestampMillisUTC)
+	case LogicalTimestampMicros, LogicalTimestampMicrosUTC:
+		return formatTimestamp(time.Unix(0, v.(int64)*int64(time.Microsecond)),
+			col.Logical == LogicalTimestampMicrosUTC)
+	case LogicalTimestampNanos, LogicalTimestampNanosUTC:
+		return formatTimestamp(time.Unix(0, v.(int64)), col.Logical == LogicalTimestampNanosUTC)
+	case LogicalDecimal:
+		var unscaled *big.Int
+		switch v := v.(type) {
+		case int32:
+			unscaled = big.NewInt(int64(v))
+		case int64:
+			unscaled = big.NewInt(v)
+		case []byte:
+			// Big-endian two's complement.
+			unscaled = new(big.Int).SetBytes(v)
+			if len(v) > 0 && v[0]&0x80 != 0 {
+				unscaled.Sub(unscaled, new(big.Int).Lsh(big.NewInt(1), uint(len(v)*8)))
+			}
+		}
+		return formatDecimal(unscaled, col.Scale)
+	case LogicalUnsigned:
+		switch v := v.(type) {
+		case int32:
+			return strconv.FormatUint(uint64(uint32(v)), 10)
+		case int64:
+			return strconv.FormatUint(uint64(v), 10)
+		}
+	}
+	switch v := v.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case []byte:
+		if col.Type == Int96 {
+			return formatTimestamp(Int96Time(v), true /* utc */)
+		}
+		return "x'" + hex.EncodeToString(v) + "'"
+	}
+	panic(fmt.Sprintf("unexpected value %T of column %s", v, col.Name))
+}
+
+func formatTimeOfDay(d time.Duration) string {
+	return time.Unix(0, int64(d)).UTC().Format("15:04:05.999999999")
+}
+
+func formatTimestamp(t time.Time, utc bool) string {
+	s := t.UTC().Format("2006-01-02T15:04:05.999999999")
+	if utc {
+		s += "Z"
+	}
+	return s
+}
+
+// formatDecimal formats an unscaled value with scale digits after the point.
+func formatDecimal(unscaled *big.Int, scale int32) string {
+	s := new(big.Int).Abs(unscaled).String()
+	if scale > 0 {
+		if pad := int(scale) + 1 - len(s); pad > 0 {
+			s = strings.Repeat("0", pad) + s
+		}
+		s = s[:len(s)-int(scale)] + "." + s[len(s)-int(scale):]
+	}
+	if unscaled.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
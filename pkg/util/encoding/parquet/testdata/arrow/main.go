@@ -0,0 +1,423 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Command arrow writes Parquet files with the Apache Arrow implementation and
+// dumps the values it reads from Parquet files, in the format of the dumps
+// in testdata.
+//
+//   go run . write <dir>           writes the reference files into dir
+//   go run . dump <file.parquet>   prints the values read from the file
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/decimal128"
+	"github.com/apache/arrow-go/v18/arrow/extensions"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/apache/arrow-go/v18/parquet/schema"
+	"github.com/google/uuid"
+)
+
+const numRows = 200
+
+func fields() []arrow.Field {
+	return []arrow.Field{
+		{Name: "b", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+		{Name: "i8", Type: arrow.PrimitiveTypes.Int8, Nullable: true},
+		{Name: "i16", Type: arrow.PrimitiveTypes.Int16, Nullable: true},
+		{Name: "i32", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		{Name: "i64", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "u32", Type: arrow.PrimitiveTypes.Uint32, Nullable: true},
+		{Name: "u64", Type: arrow.PrimitiveTypes.Uint64, Nullable: true},
+		{Name: "f32", Type: arrow.PrimitiveTypes.Float32, Nullable: true},
+		{Name: "f64", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "s", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "bin", Type: arrow.BinaryTypes.Binary, Nullable: true},
+		{Name: "fixed", Type: &arrow.FixedSizeBinaryType{ByteWidth: 3}, Nullable: true},
+		{Name: "uuid", Type: extensions.NewUUIDType(), Nullable: true},
+		{Name: "date", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+		{Name: "time_ms", Type: arrow.FixedWidthTypes.Time32ms, Nullable: true},
+		{Name: "time_us", Type: arrow.FixedWidthTypes.Time64us, Nullable: true},
+		{Name: "time_ns", Type: arrow.FixedWidthTypes.Time64ns, Nullable: true},
+		{Name: "ts_ms", Type: &arrow.TimestampType{Unit: arrow.Millisecond}, Nullable: true},
+		{Name: "ts_us_utc", Type: &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}, Nullable: true},
+		{Name: "ts_ns_utc", Type: &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}, Nullable: true},
+		{Name: "dec9", Type: &arrow.Decimal128Type{Precision: 9, Scale: 2}, Nullable: true},
+		{Name: "dec18", Type: &arrow.Decimal128Type{Precision: 18, Scale: 4}, Nullable: true},
+		{Name: "dec30", Type: &arrow.Decimal128Type{Precision: 30, Scale: 5}, Nullable: true},
+		{Name: "json", Type: jsonType(), Nullable: true},
+		{Name: "ints", Type: arrow.ListOf(arrow.PrimitiveTypes.Int64), Nullable: true},
+		{Name: "strs", Type: arrow.ListOf(arrow.BinaryTypes.String), Nullable: true},
+	}
+}
+
+func jsonType() arrow.DataType {
+	t, err := extensions.NewJSONType(arrow.BinaryTypes.String)
+	check(err)
+	return t
+}
+
+// buildRecord builds numRows rows of values. Every value is NULL in one row
+// out of seven, at a different row for each column, and strings repeat so
+// that dictionaries are useful.
+func buildRecord(mem memory.Allocator, schema *arrow.Schema) arrow.Record {
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+	for c, f := range schema.Fields() {
+		fb := b.Field(c)
+		for i := 0; i < numRows; i++ {
+			if (i+c)%7 == 0 {
+				fb.AppendNull()
+				continue
+			}
+			n := int64(i)*int64(c+1) - 100
+			switch f.Name {
+			case "b":
+				fb.(*array.BooleanBuilder).Append(i%3 == 0)
+			case "i8":
+				fb.(*array.Int8Builder).Append(int8(i - 128))
+			case "i16":
+				fb.(*array.Int16Builder).Append(int16(n * 97))
+			case "i32":
+				fb.(*array.Int32Builder).Append(int32(n * 123457))
+			case "i64":
+				fb.(*array.Int64Builder).Append(n << 40)
+			case "u32":
+				fb.(*array.Uint32Builder).Append(uint32(4294967295 - i*1000))
+			case "u64":
+				fb.(*array.Uint64Builder).Append(uint64(18446744073709551615) - uint64(i))
+			case "f32":
+				fb.(*array.Float32Builder).Append(float32(n) / 3)
+			case "f64":
+				fb.(*array.Float64Builder).Append(float64(n) / 7)
+			case "s":
+				fb.(*array.StringBuilder).Append(fmt.Sprintf("string %d\t\"%s\"", i%13, strings.Repeat("é", i%4)))
+			case "bin":
+				fb.(*array.BinaryBuilder).Append([]byte{byte(i), 0, byte(i % 5), 0xff}[:i%5])
+			case "fixed":
+				fb.(*array.FixedSizeBinaryBuilder).Append([]byte{byte(i), byte(i >> 8), 0x80})
+			case "uuid":
+				u := uuid.UUID{}
+				u[0], u[15] = byte(i), byte(i%11)
+				fb.(*extensions.UUIDBuilder).Append(u)
+			case "date":
+				fb.(*array.Date32Builder).Append(arrow.Date32(n * 37))
+			case "time_ms":
+				fb.(*array.Time32Builder).Append(arrow.Time32(int64(i) * 287999))
+			case "time_us":
+				fb.(*array.Time64Builder).Append(arrow.Time64(int64(i) * 287999999))
+			case "time_ns":
+				fb.(*array.Time64Builder).Append(arrow.Time64(int64(i) * 287999999999))
+			case "ts_ms":
+				fb.(*array.TimestampBuilder).Append(arrow.Timestamp(n * 86400123))
+			case "ts_us_utc":
+				fb.(*array.TimestampBuilder).Append(arrow.Timestamp(n * 86400123457))
+			case "ts_ns_utc":
+				fb.(*array.TimestampBuilder).Append(arrow.Timestamp(n * 86400123456789))
+			case "dec9":
+				fb.(*array.Decimal128Builder).Append(decimal128.FromI64(n * 1234567 % 999999999))
+			case "dec18":
+				fb.(*array.Decimal128Builder).Append(decimal128.FromI64(n * 12345678901234))
+			case "dec30":
+				v := new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18))
+				v.Add(v, big.NewInt(int64(i)))
+				fb.(*array.Decimal128Builder).Append(decimal128.FromBigInt(v))
+			case "json":
+				fb.(*array.ExtensionBuilder).StorageBuilder().(*array.StringBuilder).Append(fmt.Sprintf(`{"a": [%d, null], "b": "%d"}`, i%6, n))
+			case "ints":
+				lb := fb.(*array.ListBuilder)
+				lb.Append(true)
+				vb := lb.ValueBuilder().(*array.Int64Builder)
+				for j := 0; j < i%4; j++ {
+					if j == 2 {
+						vb.AppendNull()
+					} else {
+						vb.Append(n + int64(j))
+					}
+				}
+			case "strs":
+				lb := fb.(*array.ListBuilder)
+				lb.Append(true)
+				vb := lb.ValueBuilder().(*array.StringBuilder)
+				for j := 0; j < i%3; j++ {
+					vb.Append(fmt.Sprint("e", (i+j)%5))
+				}
+			default:
+				panic(f.Name)
+			}
+		}
+	}
+	return b.NewRecord()
+}
+
+func writeFile(
+	path string, schema *arrow.Schema, rec arrow.Record, props *parquet.WriterProperties,
+	arrowProps pqarrow.ArrowWriterProperties,
+) {
+	f, err := os.Create(path)
+	check(err)
+	w, err := pqarrow.NewFileWriter(schema, f, props, arrowProps)
+	check(err)
+	// Write the rows in batches, so that the row groups are split across
+	// them.
+	for off := int64(0); off < rec.NumRows(); off += 50 {
+		end := off + 50
+		if end > rec.NumRows() {
+			end = rec.NumRows()
+		}
+		check(w.WriteBuffered(rec.NewSlice(off, end)))
+	}
+	check(w.Close())
+}
+
+func write(dir string) {
+	mem := memory.DefaultAllocator
+	schema := arrow.NewSchema(fields(), nil)
+	rec := buildRecord(mem, schema)
+	defer rec.Release()
+
+	common := []parquet.WriterProperty{
+		parquet.WithMaxRowGroupLength(128),
+		parquet.WithDataPageSize(512),
+		parquet.WithStats(false),
+	}
+	with := func(props ...parquet.WriterProperty) *parquet.WriterProperties {
+		return parquet.NewWriterProperties(append(append([]parquet.WriterProperty(nil), common...), props...)...)
+	}
+	arrowProps := pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema())
+
+	// Version 1 data pages with PLAIN values and gzip compression.
+	writeFile(filepath.Join(dir, "arrow_v1_plain_gzip.parquet"), schema, rec, with(
+		parquet.WithDataPageVersion(parquet.DataPageV1),
+		parquet.WithDictionaryDefault(false),
+		parquet.WithCompression(compress.Codecs.Gzip),
+	), arrowProps)
+
+	// Version 1 data pages with dictionary encoded values and Snappy
+	// compression. The dictionaries are limited in size, so that the columns
+	// with many distinct values fall back to PLAIN values in the middle of
+	// their column chunks.
+	writeFile(filepath.Join(dir, "arrow_v1_dict_snappy.parquet"), schema, rec, with(
+		parquet.WithDataPageVersion(parquet.DataPageV1),
+		parquet.WithDictionaryDefault(true),
+		parquet.WithDictionaryPageSizeLimit(1024),
+		parquet.WithCompression(compress.Codecs.Snappy),
+	), arrowProps)
+
+	// Version 2 data pages with the DELTA and RLE encodings, uncompressed, and
+	// decimals stored as integers where they fit.
+	deltaInts := []string{"i8", "i16", "i32", "i64", "u32", "u64", "date", "time_ms",
+		"time_us", "time_ns", "ts_ms", "ts_us_utc", "ts_ns_utc", "dec9", "dec18", "ints.list.element"}
+	v2 := []parquet.WriterProperty{
+		parquet.WithDataPageVersion(parquet.DataPageV2),
+		parquet.WithDictionaryDefault(false),
+		parquet.WithCompression(compress.Codecs.Uncompressed),
+		parquet.WithStoreDecimalAsInteger(true),
+		parquet.WithEncodingFor("b", parquet.Encodings.RLE),
+		parquet.WithEncodingFor("s", parquet.Encodings.DeltaByteArray),
+		parquet.WithEncodingFor("strs.list.element", parquet.Encodings.DeltaByteArray),
+		parquet.WithEncodingFor("bin", parquet.Encodings.DeltaLengthByteArray),
+		parquet.WithEncodingFor("json", parquet.Encodings.DeltaLengthByteArray),
+	}
+	for _, c := range deltaInts {
+		v2 = append(v2, parquet.WithEncodingFor(c, parquet.Encodings.DeltaBinaryPacked))
+	}
+	writeFile(filepath.Join(dir, "arrow_v2_delta.parquet"), schema, rec, with(v2...), arrowProps)
+
+	// Legacy INT96 timestamps.
+	int96Schema := arrow.NewSchema([]arrow.Field{
+		{Name: "i64", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "ts_ns", Type: &arrow.TimestampType{Unit: arrow.Nanosecond}, Nullable: true},
+	}, nil)
+	b := array.NewRecordBuilder(mem, int96Schema)
+	for i := 0; i < numRows; i++ {
+		b.Field(0).(*array.Int64Builder).Append(int64(i))
+		if i%7 == 3 {
+			b.Field(1).AppendNull()
+		} else {
+			b.Field(1).(*array.TimestampBuilder).Append(arrow.Timestamp((int64(i) - 100) * 86400123456789))
+		}
+	}
+	int96Rec := b.NewRecord()
+	b.Release()
+	writeFile(filepath.Join(dir, "arrow_int96.parquet"), int96Schema, int96Rec, with(
+		parquet.WithDataPageVersion(parquet.DataPageV1),
+		parquet.WithCompression(compress.Codecs.Snappy),
+	), pqarrow.NewArrowWriterProperties(pqarrow.WithDeprecatedInt96Timestamps(true)))
+	int96Rec.Release()
+}
+
+func dump(path string) {
+	f, err := os.Open(path)
+	check(err)
+	r, err := file.NewParquetReader(f)
+	check(err)
+	mem := memory.DefaultAllocator
+	fr, err := pqarrow.NewFileReader(r, pqarrow.ArrowReadProperties{}, mem)
+	check(err)
+	tbl, err := fr.ReadTable(context.Background())
+	check(err)
+
+	var out strings.Builder
+	names := make([]string, tbl.NumCols())
+	for c := range names {
+		names[c] = tbl.Column(c).Name()
+	}
+	out.WriteString(strings.Join(names, "\t") + "\n")
+	// Byte arrays are formatted as strings or UUIDs if they are annotated as
+	// such, whatever the type Arrow reads them as.
+	logical := make([]schema.LogicalType, tbl.NumCols())
+	for c := range logical {
+		if n, ok := r.MetaData().Schema.Root().Field(c).(*schema.PrimitiveNode); ok {
+			logical[c] = n.LogicalType()
+		}
+	}
+	rdr := array.NewTableReader(tbl, 1<<20)
+	for rdr.Next() {
+		rec := rdr.Record()
+		for i := 0; i < int(rec.NumRows()); i++ {
+			vals := make([]string, rec.NumCols())
+			for c := range vals {
+				vals[c] = format(rec.Column(c), i)
+				switch a := rec.Column(c).(type) {
+				case *array.Binary:
+					switch logical[c].(type) {
+					case schema.StringLogicalType, schema.JSONLogicalType, schema.EnumLogicalType:
+						if a.IsValid(i) {
+							vals[c] = strconv.Quote(a.ValueString(i))
+						}
+					}
+				case *array.FixedSizeBinary:
+					if _, ok := logical[c].(schema.UUIDLogicalType); ok && a.IsValid(i) {
+						vals[c] = uuid.Must(uuid.FromBytes(a.Value(i))).String()
+					}
+				}
+			}
+			out.WriteString(strings.Join(vals, "\t") + "\n")
+		}
+	}
+	fmt.Print(out.String())
+}
+
+// format formats a value the way the tests of the parquet package do.
+func format(arr arrow.Array, i int) string {
+	if arr.IsNull(i) {
+		return "NULL"
+	}
+	switch a := arr.(type) {
+	case *array.Boolean:
+		return strconv.FormatBool(a.Value(i))
+	case *array.Int8:
+		return strconv.FormatInt(int64(a.Value(i)), 10)
+	case *array.Int16:
+		return strconv.FormatInt(int64(a.Value(i)), 10)
+	case *array.Int32:
+		return strconv.FormatInt(int64(a.Value(i)), 10)
+	case *array.Int64:
+		return strconv.FormatInt(a.Value(i), 10)
+	case *array.Uint32:
+		return strconv.FormatUint(uint64(a.Value(i)), 10)
+	case *array.Uint64:
+		return strconv.FormatUint(a.Value(i), 10)
+	case *array.Float32:
+		return strconv.FormatFloat(float64(a.Value(i)), 'g', -1, 32)
+	case *array.Float64:
+		return strconv.FormatFloat(a.Value(i), 'g', -1, 64)
+	case *array.String:
+		return strconv.Quote(a.Value(i))
+	case *array.Binary:
+		return "x'" + hex.EncodeToString(a.Value(i)) + "'"
+	case *array.FixedSizeBinary:
+		return "x'" + hex.EncodeToString(a.Value(i)) + "'"
+	case *extensions.UUIDArray:
+		return a.Value(i).String()
+	case *extensions.JSONArray:
+		return strconv.Quote(string(a.ValueBytes(i)))
+	case *array.Date32:
+		return a.Value(i).ToTime().Format("2006-01-02")
+	case *array.Time32:
+		unit := a.DataType().(*arrow.Time32Type).Unit
+		return formatTimeOfDay(time.Duration(a.Value(i)) * unit.Multiplier())
+	case *array.Time64:
+		unit := a.DataType().(*arrow.Time64Type).Unit
+		return formatTimeOfDay(time.Duration(a.Value(i)) * unit.Multiplier())
+	case *array.Timestamp:
+		typ := a.DataType().(*arrow.TimestampType)
+		ns := int64(a.Value(i)) * int64(typ.Unit.Multiplier())
+		return formatTimestamp(time.Unix(0, ns).UTC(), typ.TimeZone != "")
+	case *array.Decimal128:
+		typ := a.DataType().(*arrow.Decimal128Type)
+		return formatDecimal(a.Value(i).BigInt(), typ.Scale)
+	case *array.List:
+		start, end := a.ValueOffsets(i)
+		elems := make([]string, 0, end-start)
+		for j := start; j < end; j++ {
+			elems = append(elems, format(a.ListValues(), int(j)))
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	}
+	panic(fmt.Sprintf("unsupported array %T", arr))
+}
+
+func formatTimeOfDay(d time.Duration) string {
+	return time.Unix(0, int64(d)).UTC().Format("15:04:05.999999999")
+}
+
+func formatTimestamp(t time.Time, utc bool) string {
+	s := t.Format("2006-01-02T15:04:05.999999999")
+	if utc {
+		s += "Z"
+	}
+	return s
+}
+
+func formatDecimal(unscaled *big.Int, scale int32) string {
+	s := new(big.Int).Abs(unscaled).String()
+	if scale > 0 {
+		if pad := int(scale) + 1 - len(s); pad > 0 {
+			s = strings.Repeat("0", pad) + s
+		}
+		s = s[:len(s)-int(scale)] + "." + s[len(s)-int(scale):]
+	}
+	if unscaled.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+func check(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+func main() {
+	switch os.Args[1] {
+	case "write":
+		write(os.Args[2])
+	case "dump":
+		dump(os.Args[2])
+	}
+}
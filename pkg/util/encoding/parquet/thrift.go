@@ -0,0 +1,317 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"encoding/binary"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Field types of the Thrift compact protocol.
+const (
+	compactBoolTrue  = 1
+	compactBoolFalse = 2
+	compactByte      = 3
+	compactI16       = 4
+	compactI32       = 5
+	compactI64       = 6
+	compactDouble    = 7
+	compactBinary    = 8
+	compactList      = 9
+	compactSet       = 10
+	compactMap       = 11
+	compactStruct    = 12
+)
+
+// compactWriter encodes Thrift structs using the compact protocol, which is
+// the encoding used by Parquet for page headers and the file footer. Only the
+// subset of the protocol needed to write Parquet metadata is implemented.
+type compactWriter struct {
+	buf []byte
+	// lastField is a stack of the last field ID written in each of the
+	// currently open structs; field headers are delta-encoded against it.
+	lastField []int16
+}
+
+func (w *compactWriter) reset() {
+	w.buf = w.buf[:0]
+	w.lastField = w.lastField[:0]
+}
+
+func (w *compactWriter) structBegin() {
+	w.lastField = append(w.lastField, 0)
+}
+
+func (w *compactWriter) structEnd() {
+	w.buf = append(w.buf, 0 /* stop */)
+	w.lastField = w.lastField[:len(w.lastField)-1]
+}
+
+func (w *compactWriter) fieldBegin(id int16, typ byte) {
+	last := &w.lastField[len(w.lastField)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta<<4)|typ)
+	} else {
+		w.buf = append(w.buf, typ)
+		w.writeVarint(int64(id))
+	}
+	*last = id
+}
+
+// writeVarint writes a zigzag encoded varint, which is how the compact
+// protocol encodes all signed integers wider than a byte.
+func (w *compactWriter) writeVarint(v int64) {
+	var scratch [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(scratch[:], v)
+	w.buf = append(w.buf, scratch[:n]...)
+}
+
+func (w *compactWriter) writeUvarint(v uint64) {
+	var scratch [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(scratch[:], v)
+	w.buf = append(w.buf, scratch[:n]...)
+}
+
+func (w *compactWriter) listBegin(elemType byte, size int) {
+	if size < 15 {
+		w.buf = append(w.buf, byte(size<<4)|elemType)
+		return
+	}
+	w.buf = append(w.buf, 0xf0|elemType)
+	w.writeUvarint(uint64(size))
+}
+
+func (w *compactWriter) boolField(id int16, v bool) {
+	if v {
+		w.fieldBegin(id, compactBoolTrue)
+	} else {
+		w.fieldBegin(id, compactBoolFalse)
+	}
+}
+
+func (w *compactWriter) byteField(id int16, v int8) {
+	w.fieldBegin(id, compactByte)
+	w.buf = append(w.buf, byte(v))
+}
+
+func (w *compactWriter) i32Field(id int16, v int32) {
+	w.fieldBegin(id, compactI32)
+	w.writeVarint(int64(v))
+}
+
+func (w *compactWriter) i64Field(id int16, v int64) {
+	w.fieldBegin(id, compactI64)
+	w.writeVarint(v)
+}
+
+func (w *compactWriter) stringField(id int16, v string) {
+	w.fieldBegin(id, compactBinary)
+	w.writeString(v)
+}
+
+func (w *compactWriter) writeString(v string) {
+	w.writeUvarint(uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// structField writes a nested struct field whose fields are written by fn.
+func (w *compactWriter) structField(id int16, fn func()) {
+	w.fieldBegin(id, compactStruct)
+	w.structBegin()
+	fn()
+	w.structEnd()
+}
+
+// emptyStructField writes a nested struct field with no fields, which is how
+// the members of the Parquet LogicalType union without parameters are set.
+func (w *compactWriter) emptyStructField(id int16) {
+	w.structField(id, func() {})
+}
+
+// errCorrupt is returned when decoding metadata which isn't valid Thrift.
+var errCorrupt = errors.New("corrupt parquet metadata")
+
+// compactReader decodes Thrift structs encoded using the compact protocol.
+// Fields which aren't needed to read Parquet files are skipped.
+type compactReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *compactReader) readByte() (byte, error) {
+	if r.pos >= len(r.buf) {
+		return 0, errCorrupt
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *compactReader) readUvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		return 0, errCorrupt
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *compactReader) readVarint() (int64, error) {
+	v, n := binary.Varint(r.buf[r.pos:])
+	if n <= 0 {
+		return 0, errCorrupt
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *compactReader) readI32() (int32, error) {
+	v, err := r.readVarint()
+	return int32(v), err
+}
+
+func (r *compactReader) readBinary() ([]byte, error) {
+	n, err := r.readUvarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(r.buf)-r.pos) {
+		return nil, errCorrupt
+	}
+	b := r.buf[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return b, nil
+}
+
+func (r *compactReader) readString() (string, error) {
+	b, err := r.readBinary()
+	return string(b), err
+}
+
+// readListBegin reads the header of a list or set, returning the type and the
+// number of its elements.
+func (r *compactReader) readListBegin() (elemType byte, size int, err error) {
+	b, err := r.readByte()
+	if err != nil {
+		return 0, 0, err
+	}
+	elemType, n := b&0x0f, uint64(b>>4)
+	if n == 15 {
+		if n, err = r.readUvarint(); err != nil {
+			return 0, 0, err
+		}
+	}
+	// Every element takes at least a byte, except for empty structs.
+	if n > uint64(len(r.buf)-r.pos) && elemType != compactStruct {
+		return 0, 0, errCorrupt
+	}
+	return elemType, int(n), nil
+}
+
+// readList reads a list, calling fn to read each of its elements.
+func (r *compactReader) readList(fn func(elemType byte) error) error {
+	elemType, size, err := r.readListBegin()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < size; i++ {
+		if err := fn(elemType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readStruct reads a struct, calling fn with the ID and type of each of its
+// fields. fn must read the value of the field, or skip it. The values of
+// boolean fields are encoded in their type.
+func (r *compactReader) readStruct(fn func(id int16, typ byte) error) error {
+	var last int16
+	for {
+		b, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		if b == 0 /* stop */ {
+			return nil
+		}
+		id, typ := last+int16(b>>4), b&0x0f
+		if b>>4 == 0 {
+			v, err := r.readVarint()
+			if err != nil {
+				return err
+			}
+			id = int16(v)
+		}
+		if err := fn(id, typ); err != nil {
+			return err
+		}
+		last = id
+	}
+}
+
+// skip skips over the value of a field of the given type.
+func (r *compactReader) skip(typ byte) error {
+	switch typ {
+	case compactBoolTrue, compactBoolFalse:
+		return nil
+	case compactByte:
+		_, err := r.readByte()
+		return err
+	case compactI16, compactI32, compactI64:
+		_, err := r.readVarint()
+		return err
+	case compactDouble:
+		if len(r.buf)-r.pos < 8 {
+			return errCorrupt
+		}
+		r.pos += 8
+		return nil
+	case compactBinary:
+		_, err := r.readBinary()
+		return err
+	case compactList, compactSet:
+		return r.readList(r.skipElem)
+	case compactMap:
+		n, err := r.readUvarint()
+		if err != nil || n == 0 {
+			return err
+		}
+		types, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < n; i++ {
+			if err := r.skipElem(types >> 4); err != nil {
+				return err
+			}
+			if err := r.skipElem(types & 0x0f); err != nil {
+				return err
+			}
+		}
+		return nil
+	case compactStruct:
+		return r.readStruct(func(_ int16, typ byte) error { return r.skip(typ) })
+	default:
+		return errCorrupt
+	}
+}
+
+// skipElem skips over an element of a list, set or map. Unlike the values of
+// boolean fields, boolean elements take a byte.
+func (r *compactReader) skipElem(typ byte) error {
+	if typ == compactBoolTrue || typ == compactBoolFalse {
+		_, err := r.readByte()
+		return err
+	}
+	return r.skip(typ)
+}
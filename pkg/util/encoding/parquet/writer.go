@@ -0,0 +1,764 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package parquet implements a minimal writer and reader for the Apache
+// Parquet columnar file format.
+//
+// The writer supports flat schemas of optional columns, optionally wrapped in
+// a single level of LIST, and writes PLAIN encoded version 1 data pages with
+// one page per column chunk. This is enough to export the results of a query
+// in a form readable by the common Parquet implementations, without pulling
+// in a full Thrift and Parquet stack.
+//
+// The reader supports the same shapes of columns, required or optional, as
+// written by other implementations: version 1 and 2 data pages, the PLAIN,
+// dictionary, RLE and delta encodings and the Snappy and Gzip codecs. Columns
+// of nested groups, such as structs and maps, can't be read.
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/big"
+
+	"github.com/cockroachdb/errors"
+	"github.com/golang/snappy"
+)
+
+// magic is written at the start and at the end of every Parquet file.
+const magic = "PAR1"
+
+// createdBy is recorded in the footer of written files.
+const createdBy = "cockroachdb"
+
+// PhysicalType is the primitive type used to store the values of a column.
+type PhysicalType int32
+
+// Physical types, numbered as in the Parquet format specification.
+const (
+	Boolean           PhysicalType = 0
+	Int32             PhysicalType = 1
+	Int64             PhysicalType = 2
+	Int96             PhysicalType = 3
+	Float             PhysicalType = 4
+	Double            PhysicalType = 5
+	ByteArray         PhysicalType = 6
+	FixedLenByteArray PhysicalType = 7
+)
+
+// LogicalType annotates a physical type with how its values are interpreted.
+type LogicalType int
+
+// Logical types supported by the writer.
+const (
+	// LogicalNone leaves the physical type unannotated.
+	LogicalNone LogicalType = iota
+	// LogicalString annotates UTF-8 encoded ByteArray values.
+	LogicalString
+	// LogicalEnum annotates ByteArray values holding enum labels.
+	LogicalEnum
+	// LogicalJSON annotates UTF-8 encoded JSON ByteArray values.
+	LogicalJSON
+	// LogicalUUID annotates 16 byte FixedLenByteArray values.
+	LogicalUUID
+	// LogicalDate annotates Int32 days since the Unix epoch.
+	LogicalDate
+	// LogicalTimeMicros annotates Int64 microseconds since midnight.
+	LogicalTimeMicros
+	// LogicalTimestampMicros annotates Int64 microseconds since the Unix epoch
+	// in an unspecified time zone.
+	LogicalTimestampMicros
+	// LogicalTimestampMicrosUTC annotates Int64 microseconds since the Unix
+	// epoch in UTC.
+	LogicalTimestampMicrosUTC
+	// LogicalDecimal annotates FixedLenByteArray values holding the unscaled
+	// value of a decimal with the column's Precision and Scale, as encoded by
+	// AppendDecimal. Files written by other implementations may also hold
+	// decimals as Int32, Int64 or ByteArray values.
+	LogicalDecimal
+	// LogicalInt16 annotates Int32 values which fit in 16 bits.
+	LogicalInt16
+
+	// The following logical types are only produced by the reader.
+
+	// LogicalUnsigned annotates Int32 and Int64 values holding unsigned
+	// integers.
+	LogicalUnsigned
+	// LogicalTimeMillis annotates Int32 milliseconds since midnight.
+	LogicalTimeMillis
+	// LogicalTimeNanos annotates Int64 nanoseconds since midnight.
+	LogicalTimeNanos
+	// LogicalTimestampMillis annotates Int64 milliseconds since the Unix epoch
+	// in an unspecified time zone.
+	LogicalTimestampMillis
+	// LogicalTimestampMillisUTC annotates Int64 milliseconds since the Unix
+	// epoch in UTC.
+	LogicalTimestampMillisUTC
+	// LogicalTimestampNanos annotates Int64 nanoseconds since the Unix epoch in
+	// an unspecified time zone.
+	LogicalTimestampNanos
+	// LogicalTimestampNanosUTC annotates Int64 nanoseconds since the Unix
+	// epoch in UTC.
+	LogicalTimestampNanosUTC
+)
+
+// Codec is a compression codec applied to the data pages of a file.
+type Codec int32
+
+// Compression codecs, numbered as in the Parquet format specification.
+const (
+	Uncompressed Codec = 0
+	Snappy       Codec = 1
+	Gzip         Codec = 2
+)
+
+// Column describes a column of a Parquet file. All columns are nullable.
+type Column struct {
+	Name    string
+	Type    PhysicalType
+	Logical LogicalType
+	// TypeLength is the length of FixedLenByteArray values.
+	TypeLength int32
+	// Precision and Scale of LogicalDecimal values.
+	Precision, Scale int32
+	// List is set if the values of the column are lists of nullable elements
+	// of the column's type.
+	List bool
+}
+
+// Values passed to Writer.AddRow for each physical type.
+//
+//   Boolean           bool
+//   Int32             int32
+//   Int64             int64
+//   Int96             []byte
+//   Float             float32
+//   Double            float64
+//   ByteArray         []byte
+//   FixedLenByteArray []byte
+//
+// Nil values are NULL. The values of List columns are []interface{} holding
+// elements of the above types. Int96 values, which hold legacy timestamps, can
+// only be read; see Int96Time.
+
+// Parquet enums used in the metadata.
+const (
+	repetitionOptional = 1
+	repetitionRepeated = 2
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	pageTypeData = 0
+
+	convertedUTF8            = 0
+	convertedList            = 3
+	convertedEnum            = 4
+	convertedDecimal         = 5
+	convertedDate            = 6
+	convertedTimestampMicros = 10
+	convertedInt16           = 16
+	convertedJSON            = 19
+)
+
+// Members of the Parquet LogicalType union.
+const (
+	logicalString    = 1
+	logicalList      = 3
+	logicalEnum      = 4
+	logicalDecimal   = 5
+	logicalDate      = 6
+	logicalTime      = 7
+	logicalTimestamp = 8
+	logicalInteger   = 10
+	logicalJSON      = 12
+	logicalUUID      = 14
+
+	timeUnitMicros = 2
+)
+
+// columnData accumulates the values of a column until they are flushed.
+type columnData struct {
+	values    bytes.Buffer
+	bools     []bool
+	defLevels []uint8
+	repLevels []uint8
+}
+
+// chunkMeta describes a column chunk written to the file.
+type chunkMeta struct {
+	offset           int64
+	uncompressedSize int64
+	compressedSize   int64
+	numValues        int64
+}
+
+type rowGroup struct {
+	offset  int64
+	numRows int64
+	chunks  []chunkMeta
+}
+
+// Writer writes rows to a Parquet file. Rows are buffered in memory until
+// Flush is called, which writes them out as a row group. Close must be called
+// to write the file footer.
+type Writer struct {
+	w     io.Writer
+	codec Codec
+	cols  []Column
+
+	data      []columnData
+	numRows   int64
+	rowGroups []rowGroup
+	totalRows int64
+	offset    int64
+
+	thrift compactWriter
+	page   bytes.Buffer
+	gz     *gzip.Writer
+}
+
+// NewWriter returns a Writer that writes a file with the given columns to w.
+func NewWriter(w io.Writer, cols []Column, codec Codec) (*Writer, error) {
+	switch codec {
+	case Uncompressed, Snappy, Gzip:
+	default:
+		return nil, errors.Errorf("unsupported parquet compression codec %d", codec)
+	}
+	for _, col := range cols {
+		if col.Type == Int96 || col.Logical > LogicalInt16 {
+			return nil, errors.Errorf("column %q has a type which can't be written", col.Name)
+		}
+		if col.Type == FixedLenByteArray && col.TypeLength <= 0 {
+			return nil, errors.Errorf("column %q requires a positive type length", col.Name)
+		}
+	}
+	return &Writer{
+		w:     w,
+		codec: codec,
+		cols:  cols,
+		data:  make([]columnData, len(cols)),
+	}, nil
+}
+
+// AddRow buffers a row. See the Values comment for the expected Go types of
+// the values of each column. The writer must not be used after AddRow returns
+// an error.
+func (w *Writer) AddRow(row []interface{}) error {
+	if len(row) != len(w.cols) {
+		return errors.Errorf("expected %d values, got %d", len(w.cols), len(row))
+	}
+	for i := range w.cols {
+		col, d, v := &w.cols[i], &w.data[i], row[i]
+		if !col.List {
+			if v == nil {
+				d.defLevels = append(d.defLevels, 0)
+				continue
+			}
+			d.defLevels = append(d.defLevels, 1)
+			if err := d.addValue(col, v); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// List columns use the three-level LIST structure: an optional list,
+		// a repeated group and an optional element, for a maximum definition
+		// level of 3 and a maximum repetition level of 1.
+		if v == nil {
+			d.defLevels = append(d.defLevels, 0)
+			d.repLevels = append(d.repLevels, 0)
+			continue
+		}
+		elems, ok := v.([]interface{})
+		if !ok {
+			return errors.Errorf("column %q: expected list value, got %T", col.Name, v)
+		}
+		if len(elems) == 0 {
+			d.defLevels = append(d.defLevels, 1)
+			d.repLevels = append(d.repLevels, 0)
+			continue
+		}
+		for j, elem := range elems {
+			rep := uint8(1)
+			if j == 0 {
+				rep = 0
+			}
+			d.repLevels = append(d.repLevels, rep)
+			if elem == nil {
+				d.defLevels = append(d.defLevels, 2)
+				continue
+			}
+			d.defLevels = append(d.defLevels, 3)
+			if err := d.addValue(col, elem); err != nil {
+				return err
+			}
+		}
+	}
+	w.numRows++
+	return nil
+}
+
+func (d *columnData) addValue(col *Column, v interface{}) error {
+	var scratch [8]byte
+	ok := true
+	switch col.Type {
+	case Boolean:
+		var b bool
+		if b, ok = v.(bool); ok {
+			d.bools = append(d.bools, b)
+		}
+	case Int32:
+		var i int32
+		if i, ok = v.(int32); ok {
+			binary.LittleEndian.PutUint32(scratch[:4], uint32(i))
+			d.values.Write(scratch[:4])
+		}
+	case Int64:
+		var i int64
+		if i, ok = v.(int64); ok {
+			binary.LittleEndian.PutUint64(scratch[:], uint64(i))
+			d.values.Write(scratch[:])
+		}
+	case Float:
+		var f float32
+		if f, ok = v.(float32); ok {
+			binary.LittleEndian.PutUint32(scratch[:4], math.Float32bits(f))
+			d.values.Write(scratch[:4])
+		}
+	case Double:
+		var f float64
+		if f, ok = v.(float64); ok {
+			binary.LittleEndian.PutUint64(scratch[:], math.Float64bits(f))
+			d.values.Write(scratch[:])
+		}
+	case ByteArray:
+		var b []byte
+		if b, ok = v.([]byte); ok {
+			binary.LittleEndian.PutUint32(scratch[:4], uint32(len(b)))
+			d.values.Write(scratch[:4])
+			d.values.Write(b)
+		}
+	case FixedLenByteArray:
+		var b []byte
+		if b, ok = v.([]byte); ok {
+			if len(b) != int(col.TypeLength) {
+				return errors.Errorf("column %q: expected %d bytes, got %d",
+					col.Name, col.TypeLength, len(b))
+			}
+			d.values.Write(b)
+		}
+	default:
+		return errors.Errorf("column %q: unsupported physical type %d", col.Name, col.Type)
+	}
+	if !ok {
+		return errors.Errorf("column %q: unexpected value of type %T", col.Name, v)
+	}
+	return nil
+}
+
+// BufferedRows returns the number of rows buffered since the last Flush.
+func (w *Writer) BufferedRows() int64 {
+	return w.numRows
+}
+
+// BufferedSize returns the approximate uncompressed size in bytes of the rows
+// buffered since the last Flush.
+func (w *Writer) BufferedSize() int64 {
+	var size int64
+	for i := range w.data {
+		d := &w.data[i]
+		size += int64(d.values.Len() + len(d.bools)/8 + len(d.defLevels)/8 + len(d.repLevels)/8)
+	}
+	return size
+}
+
+func (w *Writer) write(b []byte) error {
+	if w.offset == 0 {
+		if _, err := io.WriteString(w.w, magic); err != nil {
+			return err
+		}
+		w.offset += int64(len(magic))
+	}
+	n, err := w.w.Write(b)
+	w.offset += int64(n)
+	return err
+}
+
+// Flush writes the buffered rows to the underlying writer as a row group.
+func (w *Writer) Flush() error {
+	if w.numRows == 0 {
+		return nil
+	}
+	// Make sure the header magic is written so that the offset of the first
+	// row group accounts for it.
+	if err := w.write(nil); err != nil {
+		return err
+	}
+	rg := rowGroup{offset: w.offset, numRows: w.numRows, chunks: make([]chunkMeta, len(w.cols))}
+	for i := range w.cols {
+		meta, err := w.writeColumnChunk(&w.cols[i], &w.data[i])
+		if err != nil {
+			return err
+		}
+		rg.chunks[i] = meta
+	}
+	w.rowGroups = append(w.rowGroups, rg)
+	w.totalRows += w.numRows
+	w.numRows = 0
+	return nil
+}
+
+func (w *Writer) writeColumnChunk(col *Column, d *columnData) (chunkMeta, error) {
+	// A version 1 data page holds the repetition levels, the definition levels
+	// and the values, and is compressed as a whole.
+	w.page.Reset()
+	if col.List {
+		writeLevels(&w.page, d.repLevels)
+		writeLevels(&w.page, d.defLevels)
+	} else {
+		writeLevels(&w.page, d.defLevels)
+	}
+	if col.Type == Boolean {
+		writeBools(&w.page, d.bools)
+	} else {
+		w.page.Write(d.values.Bytes())
+	}
+	uncompressed := w.page.Len()
+	page, err := w.compress(w.page.Bytes())
+	if err != nil {
+		return chunkMeta{}, err
+	}
+
+	meta := chunkMeta{offset: w.offset, numValues: int64(len(d.defLevels))}
+	t := &w.thrift
+	t.reset()
+	t.structBegin()
+	t.i32Field(1, pageTypeData)
+	t.i32Field(2, int32(uncompressed))
+	t.i32Field(3, int32(len(page)))
+	t.structField(5, func() {
+		t.i32Field(1, int32(len(d.defLevels)))
+		t.i32Field(2, encodingPlain)
+		t.i32Field(3, encodingRLE)
+		t.i32Field(4, encodingRLE)
+	})
+	t.structEnd()
+	meta.uncompressedSize = int64(len(t.buf) + uncompressed)
+	meta.compressedSize = int64(len(t.buf) + len(page))
+	if err := w.write(t.buf); err != nil {
+		return chunkMeta{}, err
+	}
+	if err := w.write(page); err != nil {
+		return chunkMeta{}, err
+	}
+
+	d.values.Reset()
+	d.bools = d.bools[:0]
+	d.defLevels = d.defLevels[:0]
+	d.repLevels = d.repLevels[:0]
+	return meta, nil
+}
+
+func (w *Writer) compress(b []byte) ([]byte, error) {
+	switch w.codec {
+	case Snappy:
+		return snappy.Encode(nil, b), nil
+	case Gzip:
+		var buf bytes.Buffer
+		if w.gz == nil {
+			w.gz = gzip.NewWriter(&buf)
+		} else {
+			w.gz.Reset(&buf)
+		}
+		if _, err := w.gz.Write(b); err != nil {
+			return nil, err
+		}
+		if err := w.gz.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return b, nil
+	}
+}
+
+// writeLevels writes levels using the RLE/bit-packing hybrid encoding,
+// prefixed by their length. Only RLE runs are used.
+func writeLevels(buf *bytes.Buffer, levels []uint8) {
+	var scratch [binary.MaxVarintLen64]byte
+	lenPos := buf.Len()
+	buf.Write(scratch[:4])
+	for i := 0; i < len(levels); {
+		j := i + 1
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		n := binary.PutUvarint(scratch[:], uint64(j-i)<<1)
+		buf.Write(scratch[:n])
+		// Levels are at most 3 and so fit in the single byte used to encode
+		// the repeated value of a run.
+		buf.WriteByte(levels[i])
+		i = j
+	}
+	binary.LittleEndian.PutUint32(buf.Bytes()[lenPos:], uint32(buf.Len()-lenPos-4))
+}
+
+// writeBools writes PLAIN encoded booleans, which are bit-packed starting
+// from the least significant bit.
+func writeBools(buf *bytes.Buffer, bools []bool) {
+	var b byte
+	for i, v := range bools {
+		if v {
+			b |= 1 << uint(i%8)
+		}
+		if i%8 == 7 {
+			buf.WriteByte(b)
+			b = 0
+		}
+	}
+	if len(bools)%8 != 0 {
+		buf.WriteByte(b)
+	}
+}
+
+// Close flushes any buffered rows and writes the file footer. It does not
+// close the underlying writer.
+func (w *Writer) Close() error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := w.write(nil); err != nil {
+		return err
+	}
+	t := &w.thrift
+	t.reset()
+	w.encodeFileMetadata(t)
+	var footerLen [4]byte
+	binary.LittleEndian.PutUint32(footerLen[:], uint32(len(t.buf)))
+	if err := w.write(t.buf); err != nil {
+		return err
+	}
+	if err := w.write(footerLen[:]); err != nil {
+		return err
+	}
+	return w.write([]byte(magic))
+}
+
+func (w *Writer) encodeFileMetadata(t *compactWriter) {
+	t.structBegin()
+	t.i32Field(1, 1 /* version */)
+
+	// The schema is the depth-first flattening of the schema tree, starting
+	// with the root.
+	numElements := 1
+	for i := range w.cols {
+		numElements++
+		if w.cols[i].List {
+			numElements += 2
+		}
+	}
+	t.fieldBegin(2, compactList)
+	t.listBegin(compactStruct, numElements)
+	t.structBegin()
+	t.stringField(4, "schema")
+	t.i32Field(5, int32(len(w.cols)))
+	t.structEnd()
+	for i := range w.cols {
+		col := &w.cols[i]
+		if !col.List {
+			encodeLeafSchema(t, col, col.Name)
+			continue
+		}
+		t.structBegin()
+		t.i32Field(3, repetitionOptional)
+		t.stringField(4, col.Name)
+		t.i32Field(5, 1 /* numChildren */)
+		t.i32Field(6, convertedList)
+		t.structField(10, func() { t.emptyStructField(logicalList) })
+		t.structEnd()
+		t.structBegin()
+		t.i32Field(3, repetitionRepeated)
+		t.stringField(4, "list")
+		t.i32Field(5, 1 /* numChildren */)
+		t.structEnd()
+		encodeLeafSchema(t, col, "element")
+	}
+
+	t.i64Field(3, w.totalRows)
+
+	t.fieldBegin(4, compactList)
+	t.listBegin(compactStruct, len(w.rowGroups))
+	for i := range w.rowGroups {
+		rg := &w.rowGroups[i]
+		var uncompressed, compressed int64
+		t.structBegin()
+		t.fieldBegin(1, compactList)
+		t.listBegin(compactStruct, len(rg.chunks))
+		for j := range rg.chunks {
+			col, c := &w.cols[j], &rg.chunks[j]
+			uncompressed += c.uncompressedSize
+			compressed += c.compressedSize
+			t.structBegin()
+			t.i64Field(2, c.offset)
+			t.structField(3, func() {
+				t.i32Field(1, int32(col.Type))
+				t.fieldBegin(2, compactList)
+				t.listBegin(compactI32, 2)
+				t.writeVarint(encodingPlain)
+				t.writeVarint(encodingRLE)
+				t.fieldBegin(3, compactList)
+				if col.List {
+					t.listBegin(compactBinary, 3)
+					t.writeString(col.Name)
+					t.writeString("list")
+					t.writeString("element")
+				} else {
+					t.listBegin(compactBinary, 1)
+					t.writeString(col.Name)
+				}
+				t.i32Field(4, int32(w.codec))
+				t.i64Field(5, c.numValues)
+				t.i64Field(6, c.uncompressedSize)
+				t.i64Field(7, c.compressedSize)
+				t.i64Field(9, c.offset)
+			})
+			t.structEnd()
+		}
+		t.i64Field(2, uncompressed)
+		t.i64Field(3, rg.numRows)
+		t.i64Field(5, rg.offset)
+		t.i64Field(6, compressed)
+		t.structEnd()
+	}
+
+	t.stringField(6, createdBy)
+	t.structEnd()
+}
+
+func encodeLeafSchema(t *compactWriter, col *Column, name string) {
+	t.structBegin()
+	t.i32Field(1, int32(col.Type))
+	if col.Type == FixedLenByteArray {
+		t.i32Field(2, col.TypeLength)
+	}
+	t.i32Field(3, repetitionOptional)
+	t.stringField(4, name)
+
+	// Converted types are the legacy equivalent of logical types. They are
+	// still written for the benefit of older readers, except for logical types
+	// which have no equivalent.
+	switch col.Logical {
+	case LogicalString:
+		t.i32Field(6, convertedUTF8)
+	case LogicalEnum:
+		t.i32Field(6, convertedEnum)
+	case LogicalJSON:
+		t.i32Field(6, convertedJSON)
+	case LogicalDate:
+		t.i32Field(6, convertedDate)
+	case LogicalTimestampMicrosUTC:
+		t.i32Field(6, convertedTimestampMicros)
+	case LogicalDecimal:
+		t.i32Field(6, convertedDecimal)
+		t.i32Field(7, col.Scale)
+		t.i32Field(8, col.Precision)
+	case LogicalInt16:
+		t.i32Field(6, convertedInt16)
+	}
+
+	if col.Logical != LogicalNone {
+		t.structField(10, func() {
+			switch col.Logical {
+			case LogicalString:
+				t.emptyStructField(logicalString)
+			case LogicalEnum:
+				t.emptyStructField(logicalEnum)
+			case LogicalJSON:
+				t.emptyStructField(logicalJSON)
+			case LogicalUUID:
+				t.emptyStructField(logicalUUID)
+			case LogicalDate:
+				t.emptyStructField(logicalDate)
+			case LogicalTimeMicros:
+				encodeTimeType(t, logicalTime, false /* adjustedToUTC */)
+			case LogicalTimestampMicros:
+				encodeTimeType(t, logicalTimestamp, false /* adjustedToUTC */)
+			case LogicalTimestampMicrosUTC:
+				encodeTimeType(t, logicalTimestamp, true /* adjustedToUTC */)
+			case LogicalDecimal:
+				t.structField(logicalDecimal, func() {
+					t.i32Field(1, col.Scale)
+					t.i32Field(2, col.Precision)
+				})
+			case LogicalInt16:
+				t.structField(logicalInteger, func() {
+					t.byteField(1, 16 /* bitWidth */)
+					t.boolField(2, true /* isSigned */)
+				})
+			}
+		})
+	}
+	t.structEnd()
+}
+
+func encodeTimeType(t *compactWriter, member int16, adjustedToUTC bool) {
+	t.structField(member, func() {
+		t.boolField(1, adjustedToUTC)
+		t.structField(2, func() { t.emptyStructField(timeUnitMicros) })
+	})
+}
+
+// DecimalLength returns the minimum length of FixedLenByteArray values able
+// to hold the unscaled values of decimals with the given precision.
+func DecimalLength(precision int32) int32 {
+	// Values of precision p need p*log2(10) bits, plus a sign bit.
+	return int32(math.Ceil((float64(precision)*math.Log2(10) + 1) / 8))
+}
+
+// AppendDecimal appends the big-endian two's complement representation of
+// the unscaled value of a decimal to buf, sign extended to length bytes.
+func AppendDecimal(buf []byte, unscaled *big.Int, length int) ([]byte, error) {
+	var b []byte
+	switch unscaled.Sign() {
+	case 0:
+	case 1:
+		b = unscaled.Bytes()
+		if b[0]&0x80 != 0 {
+			b = append([]byte{0}, b...)
+		}
+	default:
+		// The two's complement of -x is the complement of x-1.
+		var x big.Int
+		x.Neg(unscaled)
+		x.Sub(&x, big.NewInt(1))
+		b = x.Bytes()
+		for i := range b {
+			b[i] = ^b[i]
+		}
+		if len(b) == 0 || b[0]&0x80 == 0 {
+			b = append([]byte{0xff}, b...)
+		}
+	}
+	if len(b) > length {
+		return nil, errors.Errorf("decimal value %s does not fit in %d bytes", unscaled, length)
+	}
+	pad := byte(0)
+	if unscaled.Sign() < 0 {
+		pad = 0xff
+	}
+	for i := len(b); i < length; i++ {
+		buf = append(buf, pad)
+	}
+	return append(buf, b...), nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactWriter(t *testing.T) {
+	var w compactWriter
+	w.structBegin()
+	w.i32Field(1, 1)
+	w.i64Field(2, -2)
+	w.stringField(20, "ab")
+	w.structField(21, func() { w.boolField(1, true) })
+	w.fieldBegin(22, compactList)
+	w.listBegin(compactI32, 16)
+	w.structEnd()
+	require.Equal(t, []byte{
+		0x15, 0x02, // field 1, i32 1
+		0x16, 0x03, // field 2, i64 -2
+		0x08, 0x28, 0x02, 'a', 'b', // field 20 (long form), binary "ab"
+		0x1c, 0x11, 0x00, // field 21, struct with field 1 true
+		0x19, 0xf5, 0x10, // field 22, list of 16 i32s
+		0x00, // stop
+	}, w.buf)
+}
+
+func TestWriteLevels(t *testing.T) {
+	var buf bytes.Buffer
+	writeLevels(&buf, []uint8{1, 1, 1, 0, 3, 3})
+	require.Equal(t, []byte{
+		6, 0, 0, 0, // length
+		3 << 1, 1,
+		1 << 1, 0,
+		2 << 1, 3,
+	}, buf.Bytes())
+}
+
+func TestWriteBools(t *testing.T) {
+	var buf bytes.Buffer
+	writeBools(&buf, []bool{true, false, true, true, false, false, false, false, true})
+	require.Equal(t, []byte{0x0d, 0x01}, buf.Bytes())
+}
+
+func TestAppendDecimal(t *testing.T) {
+	for _, tc := range []struct {
+		unscaled int64
+		length   int
+		expected []byte
+	}{
+		{0, 2, []byte{0x00, 0x00}},
+		{1, 2, []byte{0x00, 0x01}},
+		{127, 1, []byte{0x7f}},
+		{128, 2, []byte{0x00, 0x80}},
+		{-1, 2, []byte{0xff, 0xff}},
+		{-128, 1, []byte{0x80}},
+		{-129, 3, []byte{0xff, 0xff, 0x7f}},
+		{-256, 2, []byte{0xff, 0x00}},
+	} {
+		b, err := AppendDecimal(nil, big.NewInt(tc.unscaled), tc.length)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, b, "%d", tc.unscaled)
+	}
+
+	_, err := AppendDecimal(nil, big.NewInt(128), 1)
+	require.Error(t, err)
+
+	require.Equal(t, int32(1), DecimalLength(2))
+	require.Equal(t, int32(4), DecimalLength(9))
+	require.Equal(t, int32(5), DecimalLength(10))
+	require.Equal(t, int32(8), DecimalLength(18))
+}
+
+func TestWriter(t *testing.T) {
+	cols := []Column{
+		{Name: "b", Type: Boolean},
+		{Name: "i", Type: Int64},
+		{Name: "s", Type: ByteArray, Logical: LogicalString},
+		{Name: "l", Type: Int32, List: true},
+	}
+	rows := [][]interface{}{
+		{true, int64(1), []byte("a"), []interface{}{int32(1), nil}},
+		{nil, nil, nil, nil},
+		{false, int64(3), []byte("c"), []interface{}{}},
+	}
+
+	for _, codec := range []Codec{Uncompressed, Snappy, Gzip} {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, cols, codec)
+		require.NoError(t, err)
+		for _, row := range rows {
+			require.NoError(t, w.AddRow(row))
+		}
+		require.Equal(t, int64(3), w.BufferedRows())
+		require.NoError(t, w.Close())
+		require.Zero(t, w.BufferedRows())
+
+		b := buf.Bytes()
+		require.Equal(t, magic, string(b[:4]))
+		require.Equal(t, magic, string(b[len(b)-4:]))
+		footerLen := int(binary.LittleEndian.Uint32(b[len(b)-8:]))
+		require.Less(t, footerLen, len(b)-12)
+
+		// The first page follows the magic and holds the definition levels and
+		// values of the boolean column.
+		var header compactWriter
+		header.structBegin()
+		header.i32Field(1, pageTypeData)
+		header.i32Field(2, 11)
+		require.Equal(t, header.buf, b[4:4+len(header.buf)])
+		page := []byte{
+			6, 0, 0, 0, 1 << 1, 1, 1 << 1, 0, 1 << 1, 1, // definition levels
+			0x01, // values
+		}
+		var compressed []byte
+		switch codec {
+		case Uncompressed:
+			compressed = page
+		case Snappy:
+			compressed = snappy.Encode(nil, page)
+		case Gzip:
+			var gz bytes.Buffer
+			gw := gzip.NewWriter(&gz)
+			_, err := gw.Write(page)
+			require.NoError(t, err)
+			require.NoError(t, gw.Close())
+			compressed = gz.Bytes()
+		}
+		require.True(t, bytes.Contains(b, compressed))
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	_, err := NewWriter(&bytes.Buffer{}, nil, Codec(10))
+	require.Error(t, err)
+	_, err = NewWriter(&bytes.Buffer{}, []Column{{Name: "u", Type: FixedLenByteArray}}, Uncompressed)
+	require.Error(t, err)
+
+	w, err := NewWriter(&bytes.Buffer{}, []Column{
+		{Name: "u", Type: FixedLenByteArray, TypeLength: 2},
+		{Name: "i", Type: Int32},
+	}, Uncompressed)
+	require.NoError(t, err)
+	require.Error(t, w.AddRow([]interface{}{nil}))
+	require.Error(t, w.AddRow([]interface{}{[]byte{1}, nil}))
+	require.Error(t, w.AddRow([]interface{}{nil, int64(1)}))
+	require.NoError(t, w.AddRow([]interface{}{[]byte{1, 2}, int32(1)}))
+}
@@ -36,6 +36,11 @@ type loggingT struct {
 	// the --no-color flag.
 	noColor bool
 
+	// format is the format of the entries written to log files, set
+	// by the --log-format flag. Entries written to stderr always use
+	// the crdb-v1 format.
+	format logFormat
+
 	// pool for entry formatting buffers.
 	bufPool sync.Pool
 
@@ -83,6 +88,10 @@ type loggerT struct {
 	// Name prefix for log files.
 	prefix string
 
+	// channel identifies the logger for the purpose of routing its
+	// entries to network sinks. See MainChannel.
+	channel string
+
 	// Level beyond which entries submitted to this logger are written
 	// to the output file. This acts as a filter between the log entry
 	// producers and the file sink.
@@ -160,6 +169,7 @@ func init() {
 	logging.bufPool.New = newBuffer
 	logging.mu.fatalCh = make(chan struct{})
 	mainLog.prefix = program
+	mainLog.channel = MainChannel
 	// Default stderrThreshold and fileThreshold to log everything
 	// both to the output file and to the process' external stderr
 	// (OrigStderr).
@@ -319,7 +329,7 @@ func (l *loggerT) outputLogEntry(entry Entry) {
 			return        // unreachable except in tests
 		}
 
-		buf := logging.processForFile(entry, stacks)
+		buf := logging.processForFile(entry, l.channel, stacks)
 		data := buf.Bytes()
 
 		if err := l.writeToFileLocked(data); err != nil {
@@ -331,9 +341,21 @@ func (l *loggerT) outputLogEntry(entry Entry) {
 
 		putBuffer(buf)
 	}
+	if len(stacks) > 0 {
+		entry.Message += "\n" + string(stacks)
+	}
 	// Flush and exit on fatal logging.
 	if entry.Severity == Severity_FATAL {
 		l.flushAndSyncLocked(true /*doSync*/)
+	}
+	l.mu.Unlock()
+
+	// The entry is handed off to the network sinks outside of the
+	// logger's lock: a sink configured to block when its buffer is full
+	// must not prevent other goroutines from writing to the log files.
+	outputToNetSinks(l.channel, entry)
+	if entry.Severity == Severity_FATAL {
+		flushNetSinks()
 		close(fatalTrigger)
 		// Note: although it seems like the function is allowed to return
 		// below when s == Severity_FATAL, this is not so, because the
@@ -345,7 +367,6 @@ func (l *loggerT) outputLogEntry(entry Entry) {
 		// overridden, then the client that has overridden the exit
 		// function is expecting log.Fatal to return and all is well too.
 	}
-	l.mu.Unlock()
 }
 
 // DumpStacks produces a dump of the stack traces in the logging output.
@@ -81,33 +81,33 @@ func (l *loggerT) reportErrorEverywhereLocked(ctx context.Context, err error) {
 		"logging error: %v", err)
 
 	// Format the entry for output below. Note how this formatting is
-	// done just once here for both the stderr and file outputs below,
-	// and thus misses out on TTY colors if configured. We afford this
-	// simplification because we only arrive here in case of
-	// likely-unrecoverable error, and there's not much incentive to be
-	// overly aesthetic in this case.
+	// done just once here for the stderr output, and thus misses out
+	// on TTY colors if configured. We afford this simplification
+	// because we only arrive here in case of likely-unrecoverable
+	// error, and there's not much incentive to be overly aesthetic in
+	// this case. The file output uses the configured log format, so
+	// that the file remains readable by the log parser.
 	buf := logging.formatLogEntry(entry, nil /*stacks*/, nil /*color profile*/)
 	defer putBuffer(buf)
+	fileBuf := logging.processForFile(entry, l.channel, nil /*stacks*/)
+	defer putBuffer(fileBuf)
 
 	// Either stderr or our log file is broken. Try writing the error to both
 	// streams in the hope that one still works or else the user will have no idea
 	// why we crashed.
-	outputs := make([]io.Writer, 2)
-	outputs[0] = OrigStderr
+	_, _ = OrigStderr.Write(buf.Bytes())
+	var fileOut io.Writer
 	if f, ok := l.mu.file.(*syncBuffer); ok {
 		// Don't call syncBuffer's Write method, because it can call back into
 		// exitLocked. Go directly to syncBuffer's underlying writer.
-		outputs[1] = f.Writer
-	} else {
-		outputs[1] = l.mu.file
+		fileOut = f.Writer
+	} else if l.mu.file != nil {
+		fileOut = l.mu.file
 	}
-	for _, w := range outputs {
-		if w == nil {
-			continue
-		}
+	if fileOut != nil {
 		// We're already in error. If an additional error is encountered
 		// here, we can't do anything but raise our hands in the air.
-		_, _ = w.Write(buf.Bytes())
+		_, _ = fileOut.Write(fileBuf.Bytes())
 	}
 	l.flushAndSyncLocked(true /*doSync*/)
 }
@@ -34,8 +34,13 @@ func init() {
 		logflags.LogToStderrName, "logs at or above this threshold go to stderr")
 	flag.Var(&mainLog.fileThreshold,
 		logflags.LogFileVerbosityThresholdName, "minimum verbosity of messages written to the log file")
+	flag.Var(&netSinkFlag, logflags.LogSinkName, "send log entries to a network collector (syslog, fluentd or HTTP)")
+	flag.Var(&logging.format, logflags.LogFormatName, "format of the entries written to log files (crdb-v1, json, json-compact)")
 }
 
+// netSinkFlag is the value of the --log-sink flag.
+var netSinkFlag networkSinkFlag
+
 // IsActive returns true iff the main logger already has some events
 // logged, or some secondary logger was created with configuration
 // taken from the main logger.
@@ -63,8 +68,8 @@ func SetupRedactionAndStderrRedirects() (cleanupForTestingOnly func(), err error
 	// The general goal of this function is to set up a secondary logger
 	// to capture internal Go writes to os.Stderr / fd 2 and redirect
 	// them to a separate (non-redactable) log file, This is, of course,
-	// only possible if there is a log directory to work with -- until
-	// we extend the log package to use e.g. network sinks.
+	// only possible if there is a log directory to work with. Network
+	// sinks (--log-sink) do not receive direct writes to fd 2.
 	//
 	// In case there is no log directory, we must be careful to not
 	// enable log redaction whatsoever.
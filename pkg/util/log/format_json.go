@@ -0,0 +1,455 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/redact"
+)
+
+// logFormat is the format of the entries written to log files.
+type logFormat int32
+
+const (
+	// formatCrdbV1 is the traditional text format, documented on
+	// formatLogEntryInternal().
+	formatCrdbV1 logFormat = iota
+	// formatJSON produces one JSON object per line, with descriptive
+	// field names.
+	formatJSON
+	// formatJSONCompact produces one JSON object per line, with
+	// abbreviated field names.
+	formatJSONCompact
+)
+
+var logFormatNames = [...]string{
+	formatCrdbV1:      "crdb-v1",
+	formatJSON:        "json",
+	formatJSONCompact: "json-compact",
+}
+
+// get returns the value of the logFormat.
+func (f *logFormat) get() logFormat {
+	return logFormat(atomic.LoadInt32((*int32)(f)))
+}
+
+// set sets the value of the logFormat.
+func (f *logFormat) set(val logFormat) {
+	atomic.StoreInt32((*int32)(f), int32(val))
+}
+
+// String is part of the flag.Value interface.
+func (f *logFormat) String() string {
+	return logFormatNames[f.get()]
+}
+
+// Set is part of the flag.Value interface.
+func (f *logFormat) Set(value string) error {
+	for i, name := range logFormatNames {
+		if value == name {
+			f.set(logFormat(i))
+			return nil
+		}
+	}
+	return errors.Newf("unknown log format: %q (supported: %s)",
+		value, strings.Join(logFormatNames[:], ", "))
+}
+
+// Type is part of the pflag.Value interface.
+func (f *logFormat) Type() string {
+	return "<format>"
+}
+
+// jsonKeys lists the names of the JSON fields, in their descriptive
+// and compact forms.
+var jsonKeys = struct {
+	channel, timestamp, severity, goroutine, file, line,
+	counter, redactable, tenantID, tags, message [2]string
+}{
+	channel:    [2]string{"channel", "c"},
+	timestamp:  [2]string{"timestamp", "t"},
+	severity:   [2]string{"severity", "s"},
+	goroutine:  [2]string{"goroutine", "g"},
+	file:       [2]string{"file", "f"},
+	line:       [2]string{"line", "l"},
+	counter:    [2]string{"counter", "n"},
+	redactable: [2]string{"redactable", "r"},
+	tenantID:   [2]string{"tenant_id", "T"},
+	tags:       [2]string{"tags", "x"},
+	message:    [2]string{"message", "m"},
+}
+
+// tenantTagKey is the logging tag that identifies the tenant on whose
+// behalf an operation is performed.
+const tenantTagKey = "tenant"
+
+// formatJSONEntry renders a log entry as a single-line JSON object,
+// terminated by a newline. It uses a newly allocated *buffer. The
+// caller is responsible for calling putBuffer() afterwards.
+//
+// The object has the following fields, in this order. The compact
+// field name is indicated in parentheses.
+//
+//	channel (c)      The logging channel, e.g. "main" or "sql-audit".
+//	timestamp (t)    The time of the event, in seconds since the Unix
+//	                 epoch with nanosecond precision, as a string.
+//	severity (s)     The severity, e.g. "INFO". In compact form, a single
+//	                 character as in the crdb-v1 format, e.g. "I".
+//	goroutine (g)    The goroutine ID, omitted if zero.
+//	file (f)         The file name.
+//	line (l)         The line number.
+//	counter (n)      The log entry counter, omitted if zero.
+//	redactable (r)   Whether the message and tags contain redaction
+//	                 markers.
+//	tenant_id (T)    The tenant ID, if the entry carries a "tenant" tag.
+//	tags (x)         The logging tags as an object, omitted if empty.
+//	                 Tags without a value are mapped to the empty string.
+//	message (m)      The message, followed by the goroutine stacks for
+//	                 fatal errors.
+func formatJSONEntry(entry Entry, channel string, stacks []byte, compact bool) *buffer {
+	k := 0
+	if compact {
+		k = 1
+	}
+	if entry.Severity > Severity_FATAL || entry.Severity <= Severity_UNKNOWN {
+		entry.Severity = Severity_INFO // for safety.
+	}
+
+	buf := getBuffer()
+	buf.Grow(len(entry.Tags) + len(entry.Message) + len(stacks) + 200)
+	buf.WriteByte('{')
+	writeJSONKey(buf, jsonKeys.channel[k], true /* first */)
+	writeJSONString(buf, channel)
+	writeJSONKey(buf, jsonKeys.timestamp[k], false)
+	buf.WriteByte('"')
+	buf.WriteString(strconv.FormatInt(entry.Time/1e9, 10))
+	buf.WriteByte('.')
+	n := buf.nDigits(9, 0, int(entry.Time%1e9), '0')
+	buf.Write(buf.tmp[:n])
+	buf.WriteByte('"')
+	writeJSONKey(buf, jsonKeys.severity[k], false)
+	if compact {
+		buf.WriteByte('"')
+		buf.WriteByte(severityChar[entry.Severity-1])
+		buf.WriteByte('"')
+	} else {
+		writeJSONString(buf, entry.Severity.String())
+	}
+	if entry.Goroutine > 0 {
+		writeJSONKey(buf, jsonKeys.goroutine[k], false)
+		buf.WriteString(strconv.FormatInt(entry.Goroutine, 10))
+	}
+	writeJSONKey(buf, jsonKeys.file[k], false)
+	writeJSONString(buf, entry.File)
+	writeJSONKey(buf, jsonKeys.line[k], false)
+	if entry.Line < 0 {
+		entry.Line = 0
+	}
+	buf.WriteString(strconv.FormatInt(entry.Line, 10))
+	if entry.Counter > 0 {
+		writeJSONKey(buf, jsonKeys.counter[k], false)
+		buf.WriteString(strconv.FormatUint(entry.Counter, 10))
+	}
+	writeJSONKey(buf, jsonKeys.redactable[k], false)
+	buf.WriteString(strconv.FormatBool(entry.Redactable))
+	if entry.Tags != "" {
+		tags := splitTags(entry.Tags)
+		for _, t := range tags {
+			if t.key == tenantTagKey && t.value != "" {
+				v := t.value
+				if entry.Redactable {
+					v = redact.RedactableString(v).StripMarkers()
+				}
+				writeJSONKey(buf, jsonKeys.tenantID[k], false)
+				writeJSONString(buf, v)
+				break
+			}
+		}
+		writeJSONKey(buf, jsonKeys.tags[k], false)
+		buf.WriteByte('{')
+		for i, t := range tags {
+			writeJSONKey(buf, t.key, i == 0)
+			writeJSONString(buf, t.value)
+		}
+		buf.WriteByte('}')
+	}
+	writeJSONKey(buf, jsonKeys.message[k], false)
+	msg := entry.Message
+	if len(stacks) > 0 {
+		msg = strings.TrimRight(msg, "\n") + "\n" + string(stacks)
+	}
+	writeJSONString(buf, msg)
+	buf.WriteString("}\n")
+	return buf
+}
+
+func writeJSONKey(buf *buffer, key string, first bool) {
+	if !first {
+		buf.WriteByte(',')
+	}
+	writeJSONString(buf, key)
+	buf.WriteByte(':')
+}
+
+const hexDigits = "0123456789abcdef"
+
+// writeJSONString writes s as a JSON string. Invalid UTF-8 sequences
+// are replaced by U+FFFD, like encoding/json does.
+func writeJSONString(buf *buffer, s string) {
+	buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			buf.WriteString(s[start:i])
+			switch c {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[c>>4])
+				buf.WriteByte(hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`�`)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+}
+
+// logTag is a key/value pair extracted from the rendered tags of an
+// entry.
+type logTag struct {
+	key, value string
+}
+
+// splitTags splits the tags of an entry, as rendered by formatTags()
+// or redactTags(), back into key/value pairs. Tags are separated by
+// commas; commas enclosed in redaction markers are part of a value.
+// Following the logtags convention, the value of a tag with a
+// single-character key immediately follows the key, whereas longer
+// keys are separated from their value by an equal sign. This is
+// ambiguous: "n1" could be a tag "n1" without a value. In practice
+// single-character keys are used for numeric IDs, so such tags are
+// only split when the value is a number or a redactable string.
+func splitTags(s string) []logTag {
+	start, end := string(redact.StartMarker()), string(redact.EndMarker())
+	var res []logTag
+	for len(s) > 0 {
+		// Find the end of the current tag.
+		i, inMarkers := 0, false
+		for ; i < len(s); i++ {
+			switch {
+			case strings.HasPrefix(s[i:], start):
+				inMarkers = true
+			case strings.HasPrefix(s[i:], end):
+				inMarkers = false
+			}
+			if s[i] == ',' && !inMarkers {
+				break
+			}
+		}
+		tag := s[:i]
+		if i < len(s) {
+			i++
+		}
+		s = s[i:]
+		if tag == "" {
+			continue
+		}
+		var t logTag
+		if eq := strings.IndexByte(tag, '='); eq > 0 {
+			t = logTag{key: tag[:eq], value: tag[eq+1:]}
+		} else if _, size := utf8.DecodeRuneInString(tag); size < len(tag) &&
+			(isDigit(tag[size]) || strings.HasPrefix(tag[size:], start)) {
+			t = logTag{key: tag[:size], value: tag[size:]}
+		} else {
+			t = logTag{key: tag}
+		}
+		res = append(res, t)
+	}
+	return res
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// joinTags is the inverse of splitTags.
+func joinTags(tags []logTag) string {
+	var buf strings.Builder
+	for i, t := range tags {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(t.key)
+		if t.value != "" {
+			if utf8.RuneCountInString(t.key) > 1 {
+				buf.WriteByte('=')
+			}
+			buf.WriteString(t.value)
+		}
+	}
+	return buf.String()
+}
+
+// jsonEntry is used to decode entries in the json and json-compact
+// formats. Both sets of field names are accepted.
+type jsonEntry struct {
+	Channel    string          `json:"channel"`
+	Timestamp  string          `json:"timestamp"`
+	Severity   string          `json:"severity"`
+	Goroutine  int64           `json:"goroutine"`
+	File       string          `json:"file"`
+	Line       int64           `json:"line"`
+	Counter    uint64          `json:"counter"`
+	Redactable bool            `json:"redactable"`
+	TenantID   string          `json:"tenant_id"`
+	Tags       json.RawMessage `json:"tags"`
+	Message    string          `json:"message"`
+
+	CChannel    string          `json:"c"`
+	CTimestamp  string          `json:"t"`
+	CSeverity   string          `json:"s"`
+	CGoroutine  int64           `json:"g"`
+	CFile       string          `json:"f"`
+	CLine       int64           `json:"l"`
+	CCounter    uint64          `json:"n"`
+	CRedactable bool            `json:"r"`
+	CTenantID   string          `json:"T"`
+	CTags       json.RawMessage `json:"x"`
+	CMessage    string          `json:"m"`
+}
+
+// decodeJSONEntry parses an entry in the json or json-compact format.
+// The redaction markers are not processed here; this is the
+// responsibility of the caller.
+func decodeJSONEntry(b []byte, entry *Entry) error {
+	var je jsonEntry
+	if err := json.Unmarshal(b, &je); err != nil {
+		return err
+	}
+	// Merge the compact fields into the descriptive ones. Only one set
+	// is populated in practice.
+	if je.CTimestamp != "" {
+		je.Channel, je.Timestamp, je.Severity = je.CChannel, je.CTimestamp, je.CSeverity
+		je.Goroutine, je.File, je.Line = je.CGoroutine, je.CFile, je.CLine
+		je.Counter, je.Redactable, je.TenantID = je.CCounter, je.CRedactable, je.CTenantID
+		je.Tags, je.Message = je.CTags, je.CMessage
+	}
+	if je.Timestamp == "" {
+		return errors.New("log entry without timestamp")
+	}
+
+	*entry = Entry{
+		Goroutine:  je.Goroutine,
+		File:       je.File,
+		Line:       je.Line,
+		Counter:    je.Counter,
+		Redactable: je.Redactable,
+		Message:    je.Message,
+	}
+
+	// Process the severity.
+	if len(je.Severity) == 1 {
+		entry.Severity = Severity(strings.IndexByte(severityChar, je.Severity[0]) + 1)
+	} else if s, ok := Severity_value[je.Severity]; ok {
+		entry.Severity = Severity(s)
+	}
+	if entry.Severity == Severity_UNKNOWN {
+		return errors.Newf("unknown severity: %q", je.Severity)
+	}
+
+	// Process the timestamp.
+	secs, nanos := je.Timestamp, "0"
+	if i := strings.IndexByte(secs, '.'); i >= 0 {
+		secs, nanos = secs[:i], secs[i+1:]
+		// Right-pad the fractional part to nanoseconds.
+		if len(nanos) > 9 {
+			nanos = nanos[:9]
+		}
+		nanos += strings.Repeat("0", 9-len(nanos))
+	}
+	s, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "invalid timestamp")
+	}
+	ns, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "invalid timestamp")
+	}
+	entry.Time = s*1e9 + ns
+
+	// Process the tags. The keys must be read in order, which
+	// json.Unmarshal into a map would not preserve.
+	if len(je.Tags) > 0 {
+		tags, err := decodeJSONTags(je.Tags)
+		if err != nil {
+			return err
+		}
+		entry.Tags = joinTags(tags)
+	}
+	return nil
+}
+
+func decodeJSONTags(raw json.RawMessage) ([]logTag, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok == nil {
+		return nil, nil
+	} else if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return nil, errors.New("log entry tags must be an object")
+	}
+	var tags []logTag
+	for dec.More() {
+		var t logTag
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		t.key, _ = tok.(string)
+		if err := dec.Decode(&t.value); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, nil
+}
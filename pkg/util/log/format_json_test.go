@@ -0,0 +1,253 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/logtags"
+	"github.com/cockroachdb/redact"
+)
+
+func TestLogFormatFlag(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var f logFormat
+	if f.String() != "crdb-v1" {
+		t.Fatalf("unexpected default format: %s", f.String())
+	}
+	for _, name := range []string{"json", "json-compact", "crdb-v1"} {
+		if err := f.Set(name); err != nil {
+			t.Fatal(err)
+		}
+		if f.String() != name {
+			t.Errorf("expected %s, got %s", name, f.String())
+		}
+	}
+	if err := f.Set("xml"); err == nil || !strings.Contains(err.Error(), `unknown log format: "xml"`) {
+		t.Fatalf("expected error, got %v", err)
+	}
+}
+
+func TestSplitTags(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, e := string(redact.StartMarker()), string(redact.EndMarker())
+	testCases := []struct {
+		tags string
+		exp  []logTag
+	}{
+		{"", nil},
+		{"n1", []logTag{{"n", "1"}}},
+		{"n1,s2,r3/4", []logTag{{"n", "1"}, {"s", "2"}, {"r", "3/4"}}},
+		{"n" + s + "x" + e + ",config,intExec", []logTag{{"n", s + "x" + e}, {"config", ""}, {"intExec", ""}}},
+		{"client=127.0.0.1:1234,user=root,x",
+			[]logTag{{"client", "127.0.0.1:1234"}, {"user", "root"}, {"x", ""}}},
+		{"n1,tenant=" + s + "5" + e + ",stmt=" + s + "SELECT 1, 2" + e + ",noval",
+			[]logTag{{"n", "1"}, {"tenant", s + "5" + e}, {"stmt", s + "SELECT 1, 2" + e}, {"noval", ""}}},
+	}
+	for _, tc := range testCases {
+		tags := splitTags(tc.tags)
+		if !reflect.DeepEqual(tc.exp, tags) {
+			t.Errorf("%q: expected %+v, got %+v", tc.tags, tc.exp, tags)
+		}
+		if rt := joinTags(tags); rt != tc.tags {
+			t.Errorf("%q: round-trip produced %q", tc.tags, rt)
+		}
+	}
+}
+
+func TestFormatJSONEntry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, e := string(redact.StartMarker()), string(redact.EndMarker())
+	entry := Entry{
+		Severity:   Severity_WARNING,
+		Time:       1600000000000012345,
+		Goroutine:  12,
+		File:       "server/node.go",
+		Line:       42,
+		Tags:       "n1,tenant=" + s + "5" + e + ",client=" + s + "1.2.3.4" + e,
+		Counter:    3,
+		Redactable: true,
+		Message:    "hello \"world\"\n\ttab " + s + "secret" + e + " \x01",
+	}
+
+	testCases := []struct {
+		compact bool
+		exp     string
+	}{
+		{false, `{"channel":"sql-audit","timestamp":"1600000000.000012345","severity":"WARNING",` +
+			`"goroutine":12,"file":"server/node.go","line":42,"counter":3,"redactable":true,` +
+			`"tenant_id":"5","tags":{"n":"1","tenant":"‹5›","client":"‹1.2.3.4›"},` +
+			`"message":"hello \"world\"\n\ttab ‹secret› \u0001"}` + "\n"},
+		{true, `{"c":"sql-audit","t":"1600000000.000012345","s":"W",` +
+			`"g":12,"f":"server/node.go","l":42,"n":3,"r":true,` +
+			`"T":"5","x":{"n":"1","tenant":"‹5›","client":"‹1.2.3.4›"},` +
+			`"m":"hello \"world\"\n\ttab ‹secret› \u0001"}` + "\n"},
+	}
+	for _, tc := range testCases {
+		buf := formatJSONEntry(entry, "sql-audit", nil /* stacks */, tc.compact)
+		out := buf.String()
+		putBuffer(buf)
+		if out != tc.exp {
+			t.Errorf("compact=%v: expected:\n%s\ngot:\n%s", tc.compact, tc.exp, out)
+		}
+		if !json.Valid([]byte(out)) {
+			t.Errorf("compact=%v: invalid JSON: %s", tc.compact, out)
+		}
+
+		// Check that the entry survives a round-trip through the decoder.
+		var decoded Entry
+		if err := decodeJSONEntry([]byte(out), &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(entry, decoded) {
+			t.Errorf("compact=%v: expected %+v, got %+v", tc.compact, entry, decoded)
+		}
+	}
+
+	// Invalid UTF-8 is replaced, and fatal stacks are appended to the
+	// message.
+	buf := formatJSONEntry(Entry{Severity: Severity_FATAL, Message: "bad \xff\n"},
+		MainChannel, []byte("goroutine 1 [running]:\n"), false /* compact */)
+	defer putBuffer(buf)
+	var decoded Entry
+	if err := decodeJSONEntry(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if exp := "bad �\ngoroutine 1 [running]:\n"; decoded.Message != exp {
+		t.Errorf("expected %q, got %q", exp, decoded.Message)
+	}
+}
+
+func TestEntryDecoderJSON(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, e := string(redact.StartMarker()), string(redact.EndMarker())
+	entries := []Entry{
+		{Severity: Severity_INFO, Time: 1600000000000000001, Goroutine: 1, File: "a.go", Line: 1,
+			Message: "info", Redactable: true},
+		{Severity: Severity_ERROR, Time: 1600000000000000002, Goroutine: 2, File: "b.go", Line: 2,
+			Tags: "n1,user=" + s + "bob" + e, Message: "multi-\nline " + s + "secret" + e, Redactable: true},
+		{Severity: Severity_FATAL, Time: 1600000000000000003, Goroutine: 3, File: "c.go", Line: 3,
+			Message: "fatal"},
+	}
+	for _, compact := range []bool{false, true} {
+		var contents strings.Builder
+		for i, entry := range entries {
+			buf := formatJSONEntry(entry, MainChannel, nil /* stacks */, compact)
+			contents.Write(buf.Bytes())
+			putBuffer(buf)
+			if i == 0 {
+				// Direct writes to stderr, when redirected to the log file, do
+				// not prevent the entries from being decoded.
+				contents.WriteString("panic: boom\n\ngoroutine 1 [running]:\n")
+			}
+		}
+
+		readAll := func(in io.Reader, editMode EditSensitiveData) []Entry {
+			d := NewEntryDecoder(in, editMode)
+			if !d.json {
+				t.Fatal("expected JSON input to be detected")
+			}
+			var res []Entry
+			for {
+				var entry Entry
+				if err := d.Decode(&entry); err != nil {
+					if err == io.EOF {
+						return res
+					}
+					t.Fatal(err)
+				}
+				res = append(res, entry)
+			}
+		}
+
+		if actual := readAll(strings.NewReader(contents.String()), WithMarkedSensitiveData); !reflect.DeepEqual(entries[:2], actual[:2]) || len(actual) != 3 {
+			t.Errorf("compact=%v: expected %+v, got %+v", compact, entries, actual)
+		}
+		// Redaction is applied while decoding.
+		actual := readAll(strings.NewReader(contents.String()), WithoutSensitiveData)
+		if exp := "multi-\nline " + string(redact.RedactedMarker()); actual[1].Message != exp {
+			t.Errorf("compact=%v: expected %q, got %q", compact, exp, actual[1].Message)
+		}
+		if exp := "n1,user=" + string(redact.RedactedMarker()); actual[1].Tags != exp {
+			t.Errorf("compact=%v: expected %q, got %q", compact, exp, actual[1].Tags)
+		}
+		// The format is detected even when reading from the middle of an
+		// entry, as done by 'debug merge-logs --from'.
+		actual = readAll(strings.NewReader(contents.String()[10:]), WithMarkedSensitiveData)
+		if len(actual) != 2 || actual[0].Message != entries[1].Message {
+			t.Errorf("compact=%v: unexpected entries after seek: %+v", compact, actual)
+		}
+	}
+}
+
+func TestJSONLogFile(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+
+	defer func(prev logFormat) { logging.format.set(prev) }(logging.format.get())
+	if err := logging.format.Set("json"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := logtags.AddTag(context.Background(), "n", 1)
+	Infof(ctx, "hello %s", "world")
+	Flush()
+
+	sb, ok := mainLog.mu.file.(*syncBuffer)
+	if !ok {
+		t.Fatalf("buffer wasn't created")
+	}
+	contents, err := ioutil.ReadFile(sb.file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every line, including the file header, is a JSON object.
+	lines := strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n")
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("invalid JSON line: %s", line)
+		}
+	}
+	if !strings.Contains(lines[len(lines)-2], `"message":"line format: json`) {
+		t.Errorf("expected line format header, got %s", lines[len(lines)-2])
+	}
+	last := lines[len(lines)-1]
+	if !strings.HasPrefix(last, `{"channel":"main",`) || !strings.Contains(last, `"tags":{"n":"1"}`) {
+		t.Errorf("unexpected entry: %s", last)
+	}
+
+	entries, err := FetchEntriesFromFiles(0, 1<<62, 100, nil /* pattern */, WithFlattenedSensitiveData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, e := range entries {
+		if e.Message == "hello world" && e.Tags == "n1" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("entry not found: %+v", entries)
+	}
+}
@@ -12,6 +12,7 @@ package log
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -174,9 +175,17 @@ func (l *loggingT) processForStderr(entry Entry, stacks []byte) *buffer {
 	return l.formatLogEntry(entry, stacks, ttycolor.StderrProfile)
 }
 
-// processForFile formats a log entry for output to a file.
-func (l *loggingT) processForFile(entry Entry, stacks []byte) *buffer {
-	return l.formatLogEntry(entry, stacks, nil)
+// processForFile formats a log entry for output to a file, using the
+// format configured with --log-format.
+func (l *loggingT) processForFile(entry Entry, channel string, stacks []byte) *buffer {
+	switch l.format.get() {
+	case formatJSON:
+		return formatJSONEntry(entry, channel, stacks, false /* compact */)
+	case formatJSONCompact:
+		return formatJSONEntry(entry, channel, stacks, true /* compact */)
+	default:
+		return l.formatLogEntry(entry, stacks, nil)
+	}
 }
 
 // MakeEntry creates an Entry.
@@ -271,16 +280,29 @@ type EntryDecoder struct {
 	scanner            *bufio.Scanner
 	sensitiveEditor    redactEditor
 	truncatedLastEntry bool
+	// json is set when the input uses the json or json-compact format.
+	json bool
 }
 
 // NewEntryDecoder creates a new instance of EntryDecoder.
+// The format of the input (crdb-v1, json or json-compact) is detected
+// automatically.
 func NewEntryDecoder(in io.Reader, editMode EditSensitiveData) *EntryDecoder {
+	br := bufio.NewReaderSize(in, bufio.MaxScanTokenSize)
+	// Peek returns a short buffer and an error for inputs shorter than
+	// the buffer size. This is fine.
+	head, _ := br.Peek(bufio.MaxScanTokenSize)
 	d := &EntryDecoder{
 		re:              entryRE,
-		scanner:         bufio.NewScanner(in),
+		scanner:         bufio.NewScanner(br),
 		sensitiveEditor: getEditor(editMode),
+		json:            isJSONInput(head),
+	}
+	if d.json {
+		d.scanner.Split(d.splitJSON)
+	} else {
+		d.scanner.Split(d.split)
 	}
-	d.scanner.Split(d.split)
 	return d
 }
 
@@ -298,6 +320,29 @@ func (d *EntryDecoder) Decode(entry *Entry) error {
 			return io.EOF
 		}
 		b := d.scanner.Bytes()
+		if d.json {
+			if len(b) == 0 || b[0] != '{' {
+				// Not a log entry, e.g. a direct write to stderr.
+				continue
+			}
+			if err := decodeJSONEntry(b, entry); err != nil {
+				return err
+			}
+			if entry.Tags != "" {
+				r := d.sensitiveEditor(redactablePackage{
+					msg:        []byte(entry.Tags),
+					redactable: entry.Redactable,
+				})
+				entry.Tags = string(r.msg)
+			}
+			r := d.sensitiveEditor(redactablePackage{
+				msg:        trimFinalNewLines([]byte(entry.Message)),
+				redactable: entry.Redactable,
+			})
+			entry.Message = string(r.msg)
+			entry.Redactable = r.redactable
+			return nil
+		}
 		m := d.re.FindSubmatch(b)
 		if m == nil {
 			continue
@@ -407,3 +452,47 @@ func (d *EntryDecoder) split(data []byte, atEOF bool) (advance int, token []byte
 	i[0]++
 	return i[0], data[:i[0]], nil
 }
+
+// isJSONInput determines whether the given prefix of a log file uses
+// the json or json-compact format. The prefix may start in the middle
+// of an entry, for example when the reader has seeked into the file.
+// Since JSON entries cannot span multiple lines, a JSON file cannot
+// contain a match for the crdb-v1 entry regexp.
+func isJSONInput(head []byte) bool {
+	if entryRE.Match(head) {
+		return false
+	}
+	return bytes.HasPrefix(head, []byte(`{"`)) || bytes.Contains(head, []byte("\n{\""))
+}
+
+// splitJSON is the bufio.SplitFunc for the json and json-compact
+// formats, where every entry occupies a single line. Entries that do
+// not fit in the scanner's buffer are skipped.
+func (d *EntryDecoder) splitJSON(
+	data []byte, atEOF bool,
+) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	i := bytes.IndexByte(data, '\n')
+	if d.truncatedLastEntry {
+		if i < 0 {
+			return len(data), nil, nil
+		}
+		d.truncatedLastEntry = false
+		return i + 1, nil, nil
+	}
+	if i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	if len(data) >= bufio.MaxScanTokenSize {
+		// The entry is too large. Skip it.
+		d.truncatedLastEntry = true
+		return len(data), nil, nil
+	}
+	// Ask for more data.
+	return 0, nil, nil
+}
@@ -26,18 +26,20 @@ type flushSyncWriter interface {
 	io.Writer
 }
 
-// Flush explicitly flushes all pending log I/O.
+// Flush explicitly flushes all pending log I/O, including the entries
+// buffered by network sinks.
 // See also flushDaemon() that manages background (asynchronous)
 // flushes, and signalFlusher() that manages flushes in reaction to a
 // user signal.
 func Flush() {
 	mainLog.lockAndFlushAndSync(true /*doSync*/)
 	secondaryLogRegistry.mu.Lock()
-	defer secondaryLogRegistry.mu.Unlock()
 	for _, l := range secondaryLogRegistry.mu.loggers {
 		// Some loggers (e.g. the audit log) want to keep all the files.
 		l.logger.lockAndFlushAndSync(true /*doSync*/)
 	}
+	secondaryLogRegistry.mu.Unlock()
+	flushNetSinks()
 }
 
 func init() {
@@ -67,6 +67,8 @@ const (
 	LogFileMaxSizeName            = "log-file-max-size"
 	LogFilesCombinedMaxSizeName   = "log-group-max-size"
 	LogFileVerbosityThresholdName = "log-file-verbosity"
+	LogSinkName                   = "log-sink"
+	LogFormatName                 = "log-format"
 
 	DeprecatedLogFilesCombinedMaxSizeName = "log-dir-max-size"
 )
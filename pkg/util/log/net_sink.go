@@ -0,0 +1,663 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+)
+
+// This file implements network log sinks. A network sink receives a
+// copy of the log entries emitted on a subset of the logging channels
+// (the main logger and the secondary loggers, identified by their file
+// name prefix) and ships them asynchronously to a remote collector.
+//
+// Each sink buffers entries in memory and a background goroutine
+// sends them in batches. When the buffer is full, the sink's
+// backpressure policy determines whether new entries are dropped or
+// whether the logging call blocks until space becomes available.
+//
+// Sinks are configured with a URL, for example:
+//
+//   syslog+udp://collector:514?channels=main,sql-audit&threshold=WARNING
+//   fluent://collector:24224?tag=crdb&buffer-size=10000
+//   https://collector/ingest?on-full=block&redact=true
+//
+// See ParseNetworkSinkConfig for the list of accepted parameters.
+
+// MainChannel is the name of the logging channel served by the main
+// logger. Secondary loggers use their file name prefix as channel name.
+const MainChannel = "main"
+
+// BackpressurePolicy determines what happens to new log entries when
+// the buffer of a network sink is full.
+type BackpressurePolicy int
+
+const (
+	// DropOnFull discards new entries while the buffer is full. The
+	// number of dropped entries is reported to stderr once the sink
+	// recovers.
+	DropOnFull BackpressurePolicy = iota
+	// BlockOnFull blocks the logging call until the sink has made room
+	// in its buffer. This guarantees that no entry is lost, at the
+	// expense of stalling the process if the collector is unavailable.
+	BlockOnFull
+)
+
+// NetworkSinkConfig describes a network log sink.
+type NetworkSinkConfig struct {
+	// URL identifies the collector. The scheme selects the protocol:
+	// syslog+udp, syslog+tcp, fluent, http or https.
+	URL url.URL
+	// Channels restricts the sink to the given logging channels. An
+	// empty list means all channels.
+	Channels []string
+	// Threshold is the minimum severity of the entries sent to the sink.
+	Threshold Severity
+	// BufferSize is the maximum number of entries held in memory.
+	BufferSize int
+	// MaxBatchSize is the maximum number of entries sent in one batch.
+	MaxBatchSize int
+	// FlushInterval is the maximum delay before a buffered entry is sent.
+	FlushInterval time.Duration
+	// OnFull is the backpressure policy.
+	OnFull BackpressurePolicy
+	// EditMode determines how sensitive information is edited before
+	// the entries leave the process.
+	EditMode EditSensitiveData
+
+	// Tag is the fluentd tag, or the syslog APP-NAME.
+	Tag string
+	// Facility is the syslog facility.
+	Facility int
+}
+
+// Default values for the network sink parameters.
+const (
+	defaultNetSinkBufferSize    = 10000
+	defaultNetSinkMaxBatchSize  = 100
+	defaultNetSinkFlushInterval = time.Second
+	defaultSyslogFacility       = 16 // local0
+)
+
+// ParseNetworkSinkConfig parses the specification of a network sink.
+// The specification is a URL with the following optional query
+// parameters, which are removed from the URL before it is used to reach
+// the collector:
+//
+//	channels:       comma-separated list of channels (default: all)
+//	threshold:      minimum severity (default: INFO)
+//	buffer-size:    maximum number of buffered entries (default: 10000)
+//	max-batch-size: maximum number of entries per batch (default: 100)
+//	flush-interval: maximum buffering delay (default: 1s)
+//	on-full:        "drop" or "block" (default: drop)
+//	redact:         remove sensitive information (default: false)
+//	redactable:     keep redaction markers (default: false)
+//	tag:            fluentd tag or syslog app name (default: program name)
+//	facility:       syslog facility number (default: 16, i.e. local0)
+func ParseNetworkSinkConfig(spec string) (NetworkSinkConfig, error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return NetworkSinkConfig{}, errors.Wrap(err, "invalid log sink URL")
+	}
+	cfg := NetworkSinkConfig{
+		Threshold:     Severity_INFO,
+		BufferSize:    defaultNetSinkBufferSize,
+		MaxBatchSize:  defaultNetSinkMaxBatchSize,
+		FlushInterval: defaultNetSinkFlushInterval,
+		Tag:           program,
+		Facility:      defaultSyslogFacility,
+	}
+	switch u.Scheme {
+	case "syslog+udp", "syslog+tcp", "fluent":
+		if u.Host == "" {
+			return NetworkSinkConfig{}, errors.Newf("log sink %q: missing host", u.Scheme)
+		}
+	case "http", "https":
+	default:
+		return NetworkSinkConfig{}, errors.Newf("unsupported log sink scheme: %q", u.Scheme)
+	}
+
+	q := u.Query()
+	var redact, keepRedactable bool
+	for param, vals := range q {
+		val := vals[len(vals)-1]
+		var err error
+		switch param {
+		case "channels":
+			for _, c := range strings.Split(val, ",") {
+				if c = strings.TrimSpace(c); c != "" {
+					cfg.Channels = append(cfg.Channels, c)
+				}
+			}
+		case "threshold":
+			err = cfg.Threshold.Set(val)
+		case "buffer-size":
+			cfg.BufferSize, err = parsePositiveInt(val)
+		case "max-batch-size":
+			cfg.MaxBatchSize, err = parsePositiveInt(val)
+		case "flush-interval":
+			cfg.FlushInterval, err = time.ParseDuration(val)
+			if err == nil && cfg.FlushInterval <= 0 {
+				err = errors.New("must be positive")
+			}
+		case "on-full":
+			switch val {
+			case "drop":
+				cfg.OnFull = DropOnFull
+			case "block":
+				cfg.OnFull = BlockOnFull
+			default:
+				err = errors.New(`expected "drop" or "block"`)
+			}
+		case "redact":
+			redact, err = strconv.ParseBool(val)
+		case "redactable":
+			keepRedactable, err = strconv.ParseBool(val)
+		case "tag":
+			cfg.Tag = val
+		case "facility":
+			cfg.Facility, err = strconv.Atoi(val)
+			if err == nil && (cfg.Facility < 0 || cfg.Facility > 23) {
+				err = errors.New("must be between 0 and 23")
+			}
+		default:
+			// Unknown parameters are left in the URL, for example to be
+			// passed to an HTTP collector.
+			continue
+		}
+		if err != nil {
+			return NetworkSinkConfig{}, errors.Wrapf(err, "log sink parameter %q", param)
+		}
+		q.Del(param)
+	}
+	if cfg.MaxBatchSize > cfg.BufferSize {
+		cfg.MaxBatchSize = cfg.BufferSize
+	}
+	cfg.EditMode = SelectEditMode(redact, keepRedactable)
+	u.RawQuery = q.Encode()
+	cfg.URL = *u
+	return cfg, nil
+}
+
+func parsePositiveInt(s string) (int, error) {
+	i, err := strconv.Atoi(s)
+	if err == nil && i <= 0 {
+		err = errors.New("must be positive")
+	}
+	return i, err
+}
+
+// netSinkEntry is a log entry queued for a network sink.
+type netSinkEntry struct {
+	Entry
+	channel string
+}
+
+// netSinkWriter is implemented by the network protocols supported by
+// network sinks. The methods are only called from the sink's
+// background goroutine.
+type netSinkWriter interface {
+	// send delivers a batch of entries to the collector.
+	send(ctx context.Context, entries []netSinkEntry) error
+	// close releases the resources held by the writer.
+	close()
+}
+
+func newNetSinkWriter(cfg *NetworkSinkConfig) (netSinkWriter, error) {
+	switch cfg.URL.Scheme {
+	case "syslog+udp":
+		return newSyslogWriter("udp", cfg), nil
+	case "syslog+tcp":
+		return newSyslogWriter("tcp", cfg), nil
+	case "fluent":
+		return newFluentWriter(cfg), nil
+	case "http", "https":
+		return newHTTPWriter(cfg), nil
+	default:
+		return nil, errors.AssertionFailedf("unexpected log sink scheme: %q", cfg.URL.Scheme)
+	}
+}
+
+// networkSink buffers log entries and ships them to a collector.
+type networkSink struct {
+	cfg      NetworkSinkConfig
+	w        netSinkWriter
+	channels map[string]struct{}
+	editor   redactEditor
+
+	// wakeCh is signaled when the buffer becomes non-empty or when a
+	// flush is requested.
+	wakeCh chan struct{}
+	// stopCh is closed to stop the background goroutine; doneCh is
+	// closed when it has terminated.
+	stopCh, doneCh chan struct{}
+
+	mu struct {
+		syncutil.Mutex
+		// notFull is signaled when room is made in the buffer.
+		notFull *sync.Cond
+		// drainedCh, if non-nil, is closed when the buffer is emptied.
+		// It is allocated by flush() and is used instead of a condition
+		// variable so that waiters can give up.
+		drainedCh chan struct{}
+		buf       []netSinkEntry
+		// sending is true while a batch taken from the buffer is being
+		// sent.
+		sending bool
+		// dropped counts the entries dropped since the last report.
+		dropped uint64
+		// failing is true while the collector is unreachable.
+		failing bool
+		stopped bool
+	}
+}
+
+func newNetworkSink(cfg NetworkSinkConfig, w netSinkWriter) *networkSink {
+	s := &networkSink{
+		cfg:    cfg,
+		w:      w,
+		editor: getEditor(cfg.EditMode),
+		wakeCh: make(chan struct{}, 1),
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	if len(cfg.Channels) > 0 {
+		s.channels = make(map[string]struct{}, len(cfg.Channels))
+		for _, c := range cfg.Channels {
+			s.channels[c] = struct{}{}
+		}
+	}
+	s.mu.notFull = sync.NewCond(&s.mu.Mutex)
+	return s
+}
+
+// accepts returns true if the sink is interested in entries with the
+// given channel and severity.
+func (s *networkSink) accepts(channel string, sev Severity) bool {
+	if sev < s.cfg.Threshold {
+		return false
+	}
+	if s.channels == nil {
+		return true
+	}
+	_, ok := s.channels[channel]
+	return ok
+}
+
+// edit applies the sink's redaction policy to an entry.
+func (s *networkSink) edit(entry Entry) Entry {
+	msg := s.editor(redactablePackage{msg: []byte(entry.Message), redactable: entry.Redactable})
+	tags := s.editor(redactablePackage{msg: []byte(entry.Tags), redactable: entry.Redactable})
+	entry.Message = string(msg.msg)
+	if entry.Tags != "" {
+		entry.Tags = string(tags.msg)
+	}
+	entry.Redactable = msg.redactable
+	return entry
+}
+
+// enqueue adds an entry to the buffer, applying the backpressure policy
+// if the buffer is full.
+func (s *networkSink) enqueue(channel string, entry Entry) {
+	e := netSinkEntry{Entry: s.edit(entry), channel: channel}
+	s.mu.Lock()
+	for len(s.mu.buf) >= s.cfg.BufferSize && !s.mu.stopped {
+		if s.cfg.OnFull == DropOnFull {
+			s.mu.dropped++
+			s.mu.Unlock()
+			return
+		}
+		s.mu.notFull.Wait()
+	}
+	if s.mu.stopped {
+		s.mu.Unlock()
+		return
+	}
+	s.mu.buf = append(s.mu.buf, e)
+	full := len(s.mu.buf) >= s.cfg.MaxBatchSize
+	s.mu.Unlock()
+	if full {
+		s.wake()
+	}
+}
+
+func (s *networkSink) wake() {
+	select {
+	case s.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+// flush waits until all the entries buffered so far have been sent, or
+// until ctx is canceled. It returns false in the latter case.
+func (s *networkSink) flush(ctx context.Context) bool {
+	s.mu.Lock()
+	if s.drainedLocked() {
+		s.mu.Unlock()
+		return true
+	}
+	if s.mu.drainedCh == nil {
+		s.mu.drainedCh = make(chan struct{})
+	}
+	drainedCh := s.mu.drainedCh
+	s.mu.Unlock()
+	s.wake()
+	select {
+	case <-drainedCh:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// drainedLocked returns true if there are no entries left to send.
+func (s *networkSink) drainedLocked() bool {
+	return (len(s.mu.buf) == 0 && !s.mu.sending) || s.mu.stopped
+}
+
+// signalDrainedLocked wakes up the callers of flush() if there are no
+// entries left to send.
+func (s *networkSink) signalDrainedLocked() {
+	if s.mu.drainedCh != nil && s.drainedLocked() {
+		close(s.mu.drainedCh)
+		s.mu.drainedCh = nil
+	}
+}
+
+// stop terminates the background goroutine. Entries that have not been
+// sent yet are discarded.
+func (s *networkSink) stop() {
+	s.mu.Lock()
+	s.mu.stopped = true
+	s.mu.notFull.Broadcast()
+	s.signalDrainedLocked()
+	s.mu.Unlock()
+	close(s.stopCh)
+	<-s.doneCh
+	s.w.close()
+}
+
+// Retry delays used when the collector is unavailable.
+const (
+	netSinkMinRetryDelay = 100 * time.Millisecond
+	netSinkMaxRetryDelay = 5 * time.Second
+)
+
+// run is the background goroutine that sends the buffered entries.
+func (s *networkSink) run(ctx context.Context) {
+	defer close(s.doneCh)
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+	retryDelay := netSinkMinRetryDelay
+	var batch []netSinkEntry
+	for {
+		if batch == nil {
+			select {
+			case <-s.stopCh:
+				return
+			case <-s.wakeCh:
+			case <-ticker.C:
+			}
+			s.mu.Lock()
+			n := len(s.mu.buf)
+			if n > s.cfg.MaxBatchSize {
+				n = s.cfg.MaxBatchSize
+			}
+			if n > 0 {
+				batch = append([]netSinkEntry(nil), s.mu.buf[:n]...)
+				s.mu.buf = append(s.mu.buf[:0], s.mu.buf[n:]...)
+				s.mu.sending = true
+				s.mu.notFull.Broadcast()
+			}
+			s.mu.Unlock()
+			if batch == nil {
+				continue
+			}
+		}
+
+		err := s.w.send(ctx, batch)
+
+		s.mu.Lock()
+		wasFailing, dropped := s.mu.failing, s.mu.dropped
+		s.mu.failing = err != nil
+		if err == nil {
+			s.mu.dropped = 0
+			s.mu.sending = false
+			s.signalDrainedLocked()
+		}
+		more := len(s.mu.buf) > 0
+		s.mu.Unlock()
+
+		if err != nil {
+			// Keep the batch and retry it after a delay. New entries
+			// accumulate in the buffer in the meantime, subject to the
+			// backpressure policy.
+			if !wasFailing {
+				s.report("unable to send log entries to %s: %v", s.redactedURL(), err)
+			}
+			select {
+			case <-s.stopCh:
+				return
+			case <-time.After(retryDelay):
+			}
+			if retryDelay *= 2; retryDelay > netSinkMaxRetryDelay {
+				retryDelay = netSinkMaxRetryDelay
+			}
+			continue
+		}
+		batch = nil
+		retryDelay = netSinkMinRetryDelay
+		if wasFailing {
+			s.report("log sink %s recovered", s.redactedURL())
+		}
+		if dropped > 0 {
+			s.report("log sink %s: %d entries dropped", s.redactedURL(), dropped)
+		}
+		if more {
+			s.wake()
+		}
+	}
+}
+
+// report prints a message about the health of the sink. This cannot go
+// through the logging system itself, lest the sink report its own
+// failures to itself.
+func (s *networkSink) report(format string, args ...interface{}) {
+	fmt.Fprintf(OrigStderr, "log: "+format+"\n", args...)
+}
+
+// redactedURL returns the URL of the collector without credentials.
+func (s *networkSink) redactedURL() string {
+	return redactURL(s.cfg.URL)
+}
+
+// redactURL returns the given URL with its user information, which may
+// carry credentials, redacted.
+func redactURL(u url.URL) string {
+	if u.User != nil {
+		u.User = url.User("redacted")
+	}
+	return u.String()
+}
+
+// netSinks is the registry of network sinks.
+var netSinks struct {
+	// active is non-zero when at least one sink is registered. It
+	// avoids taking the lock on the logging hot path in the common
+	// case.
+	active int32
+
+	mu struct {
+		syncutil.RWMutex
+		// sinks is never modified in place, so that it can be used
+		// after the lock is released.
+		sinks []*networkSink
+	}
+}
+
+// AddNetworkSink starts a network sink with the given configuration.
+// The returned function stops the sink after attempting to flush the
+// buffered entries.
+func AddNetworkSink(cfg NetworkSinkConfig) (cleanup func(), _ error) {
+	w, err := newNetSinkWriter(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	s := newNetworkSink(cfg, w)
+	go s.run(context.Background())
+	return registerNetworkSink(s), nil
+}
+
+// registerNetworkSink adds a running sink to the registry. The returned
+// function removes it, then stops it after attempting to flush the
+// buffered entries.
+func registerNetworkSink(s *networkSink) (cleanup func()) {
+	netSinks.mu.Lock()
+	sinks := make([]*networkSink, 0, len(netSinks.mu.sinks)+1)
+	netSinks.mu.sinks = append(append(sinks, netSinks.mu.sinks...), s)
+	atomic.StoreInt32(&netSinks.active, 1)
+	netSinks.mu.Unlock()
+
+	return func() {
+		netSinks.mu.Lock()
+		sinks := make([]*networkSink, 0, len(netSinks.mu.sinks))
+		for _, other := range netSinks.mu.sinks {
+			if other != s {
+				sinks = append(sinks, other)
+			}
+		}
+		netSinks.mu.sinks = sinks
+		if len(sinks) == 0 {
+			atomic.StoreInt32(&netSinks.active, 0)
+		}
+		netSinks.mu.Unlock()
+		ctx, cancel := context.WithTimeout(context.Background(), netSinkFlushTimeout)
+		defer cancel()
+		s.flush(ctx)
+		s.stop()
+	}
+}
+
+// registeredNetSinks returns the registered sinks. The caller must not
+// hold the registry lock while using them: enqueuing on a sink or
+// flushing it can block for a long time, and a writer waiting for the
+// lock would then block every subsequent log call.
+func registeredNetSinks() []*networkSink {
+	netSinks.mu.RLock()
+	defer netSinks.mu.RUnlock()
+	return netSinks.mu.sinks
+}
+
+// netSinkFlushTimeout bounds the time spent flushing network sinks when
+// the log is flushed, e.g. upon a fatal error or upon shutdown.
+const netSinkFlushTimeout = 5 * time.Second
+
+// outputToNetSinks queues an entry on every network sink interested in
+// it. It must not be called with a logger's lock held, since it may
+// block when a sink's buffer is full.
+func outputToNetSinks(channel string, entry Entry) {
+	if atomic.LoadInt32(&netSinks.active) == 0 {
+		return
+	}
+	for _, s := range registeredNetSinks() {
+		if s.accepts(channel, entry.Severity) {
+			s.enqueue(channel, entry)
+		}
+	}
+}
+
+// flushNetSinks waits until the entries buffered by the network sinks
+// have been sent, up to netSinkFlushTimeout.
+func flushNetSinks() {
+	if atomic.LoadInt32(&netSinks.active) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), netSinkFlushTimeout)
+	defer cancel()
+	for _, s := range registeredNetSinks() {
+		if !s.flush(ctx) {
+			return
+		}
+	}
+}
+
+// networkSinkFlag is the flag.Value for --log-sink. Every occurrence of
+// the flag adds a network sink. The sinks are only started by
+// StartNetworkSinks(), once the command-line flags have been parsed.
+type networkSinkFlag struct {
+	syncutil.Mutex
+	// specs are the specifications of the sinks, with their credentials
+	// redacted since they are printed along with the other flags.
+	specs []string
+	cfgs  []NetworkSinkConfig
+}
+
+// String implements the flag.Value interface.
+func (f *networkSinkFlag) String() string {
+	f.Lock()
+	defer f.Unlock()
+	return strings.Join(f.specs, ",")
+}
+
+// Set implements the flag.Value interface.
+func (f *networkSinkFlag) Set(spec string) error {
+	cfg, err := ParseNetworkSinkConfig(spec)
+	if err != nil {
+		return err
+	}
+	// The spec was parsed successfully above.
+	u, _ := url.Parse(spec)
+	f.Lock()
+	defer f.Unlock()
+	f.specs = append(f.specs, redactURL(*u))
+	f.cfgs = append(f.cfgs, cfg)
+	return nil
+}
+
+// Type implements the pflag.Value interface.
+func (f *networkSinkFlag) Type() string {
+	return "<url>"
+}
+
+// StartNetworkSinks starts the network sinks configured with --log-sink.
+// It should be called once, after the command-line flags have been
+// parsed. The returned function flushes and stops the sinks.
+func StartNetworkSinks() (cleanup func(), _ error) {
+	netSinkFlag.Lock()
+	cfgs := netSinkFlag.cfgs
+	netSinkFlag.Unlock()
+
+	var cleanups []func()
+	cleanup = func() {
+		for _, fn := range cleanups {
+			fn()
+		}
+	}
+	for _, cfg := range cfgs {
+		fn, err := AddNetworkSink(cfg)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		cleanups = append(cleanups, fn)
+	}
+	return cleanup, nil
+}
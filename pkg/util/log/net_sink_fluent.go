@@ -0,0 +1,185 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// fluentWriter sends log entries to a fluentd (or fluent-bit) server
+// using the "Forward" mode of the Fluentd Forward Protocol v1:
+//
+//	[tag, [[time, record], [time, record], ...]]
+//
+// encoded with MessagePack. The time is encoded with the EventTime
+// extension type, to preserve nanosecond precision.
+//
+// See: https://github.com/fluent/fluentd/wiki/Forward-Protocol-Specification-v1
+type fluentWriter struct {
+	addr string
+	tag  string
+
+	conn net.Conn
+	buf  bytes.Buffer
+}
+
+func newFluentWriter(cfg *NetworkSinkConfig) *fluentWriter {
+	return &fluentWriter{addr: cfg.URL.Host, tag: cfg.Tag}
+}
+
+// send implements the netSinkWriter interface.
+func (w *fluentWriter) send(ctx context.Context, entries []netSinkEntry) error {
+	if w.conn == nil {
+		d := net.Dialer{Timeout: netSinkDialTimeout}
+		conn, err := d.DialContext(ctx, "tcp", w.addr)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+	}
+	w.buf.Reset()
+	w.encode(&w.buf, entries)
+	_ = w.conn.SetWriteDeadline(timeutil.Now().Add(netSinkDialTimeout))
+	if _, err := w.conn.Write(w.buf.Bytes()); err != nil {
+		w.close()
+		return err
+	}
+	return nil
+}
+
+// close implements the netSinkWriter interface.
+func (w *fluentWriter) close() {
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+}
+
+func (w *fluentWriter) encode(buf *bytes.Buffer, entries []netSinkEntry) {
+	msgpackArrayHeader(buf, 2)
+	msgpackString(buf, w.tag)
+	msgpackArrayHeader(buf, len(entries))
+	for i := range entries {
+		e := &entries[i]
+		msgpackArrayHeader(buf, 2)
+		msgpackEventTime(buf, e.Time)
+
+		n := 8
+		if e.Tags != "" {
+			n++
+		}
+		if e.Counter > 0 {
+			n++
+		}
+		msgpackMapHeader(buf, n)
+		msgpackString(buf, "channel")
+		msgpackString(buf, e.channel)
+		msgpackString(buf, "severity")
+		msgpackString(buf, e.Severity.String())
+		msgpackString(buf, "time")
+		msgpackInt(buf, e.Time)
+		msgpackString(buf, "goroutine")
+		msgpackInt(buf, e.Goroutine)
+		msgpackString(buf, "file")
+		msgpackString(buf, e.File)
+		msgpackString(buf, "line")
+		msgpackInt(buf, e.Line)
+		if e.Tags != "" {
+			msgpackString(buf, "tags")
+			msgpackString(buf, e.Tags)
+		}
+		if e.Counter > 0 {
+			msgpackString(buf, "counter")
+			msgpackInt(buf, int64(e.Counter))
+		}
+		msgpackString(buf, "redactable")
+		msgpackBool(buf, e.Redactable)
+		msgpackString(buf, "message")
+		msgpackString(buf, e.Message)
+	}
+}
+
+// The functions below implement the subset of the MessagePack encoding
+// needed by the fluent writer.
+// See: https://github.com/msgpack/msgpack/blob/master/spec.md
+
+func msgpackArrayHeader(buf *bytes.Buffer, n int) {
+	msgpackContainerHeader(buf, n, 0x90, 0xdc)
+}
+
+func msgpackMapHeader(buf *bytes.Buffer, n int) {
+	msgpackContainerHeader(buf, n, 0x80, 0xde)
+}
+
+// msgpackContainerHeader encodes the header of an array or a map, given
+// the "fix" prefix and the 16-bit prefix (the 32-bit prefix follows it).
+func msgpackContainerHeader(buf *bytes.Buffer, n int, fix, prefix16 byte) {
+	switch {
+	case n < 16:
+		buf.WriteByte(fix | byte(n))
+	case n <= 0xffff:
+		buf.WriteByte(prefix16)
+		_ = binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(prefix16 + 1)
+		_ = binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+func msgpackString(buf *bytes.Buffer, s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		buf.WriteByte(0xa0 | byte(n))
+	case n <= 0xff:
+		buf.WriteByte(0xd9)
+		buf.WriteByte(byte(n))
+	case n <= 0xffff:
+		buf.WriteByte(0xda)
+		_ = binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdb)
+		_ = binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+	buf.WriteString(s)
+}
+
+func msgpackInt(buf *bytes.Buffer, i int64) {
+	if i >= 0 && i < 128 {
+		buf.WriteByte(byte(i))
+		return
+	}
+	buf.WriteByte(0xd3)
+	_ = binary.Write(buf, binary.BigEndian, i)
+}
+
+func msgpackBool(buf *bytes.Buffer, b bool) {
+	if b {
+		buf.WriteByte(0xc3)
+	} else {
+		buf.WriteByte(0xc2)
+	}
+}
+
+// msgpackEventTime encodes a timestamp in nanoseconds since the epoch
+// as a fluentd EventTime: extension type 0 with a 32-bit seconds and a
+// 32-bit nanoseconds big-endian payload.
+func msgpackEventTime(buf *bytes.Buffer, nanos int64) {
+	buf.WriteByte(0xd7) // fixext 8
+	buf.WriteByte(0x00)
+	_ = binary.Write(buf, binary.BigEndian, uint32(nanos/1e9))
+	_ = binary.Write(buf, binary.BigEndian, uint32(nanos%1e9))
+}
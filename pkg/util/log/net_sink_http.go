@@ -0,0 +1,81 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// httpWriter sends batches of log entries as a JSON array in the body
+// of a POST request. The entries use the same schema as the "json" log
+// file format, see formatJSONEntry(). Any response status other than
+// 2xx is considered a failure, causing the batch to be retried.
+type httpWriter struct {
+	url    string
+	client http.Client
+	buf    bytes.Buffer
+}
+
+// httpSinkTimeout bounds the duration of a request to the collector.
+const httpSinkTimeout = 10 * time.Second
+
+func newHTTPWriter(cfg *NetworkSinkConfig) *httpWriter {
+	return &httpWriter{
+		url:    cfg.URL.String(),
+		client: http.Client{Timeout: httpSinkTimeout},
+	}
+}
+
+// send implements the netSinkWriter interface.
+func (w *httpWriter) send(ctx context.Context, entries []netSinkEntry) error {
+	w.buf.Reset()
+	w.buf.WriteByte('[')
+	for i := range entries {
+		if i > 0 {
+			w.buf.WriteByte(',')
+		}
+		// The goroutine stacks of fatal entries have already been
+		// appended to the message.
+		e := formatJSONEntry(entries[i].Entry, entries[i].channel, nil /* stacks */, false /* compact */)
+		w.buf.Write(bytes.TrimSuffix(e.Bytes(), []byte{'\n'}))
+		putBuffer(e)
+	}
+	w.buf.WriteByte(']')
+	req, err := http.NewRequest("POST", w.url, bytes.NewReader(w.buf.Bytes()))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	// Drain the body so that the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Newf("unexpected HTTP response: %s", resp.Status)
+	}
+	return nil
+}
+
+// close implements the netSinkWriter interface.
+func (w *httpWriter) close() {
+	w.client.CloseIdleConnections()
+}
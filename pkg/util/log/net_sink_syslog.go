@@ -0,0 +1,172 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// syslogWriter sends log entries as RFC 5424 syslog messages, over UDP
+// (one message per datagram, RFC 5426) or TCP (with octet-counting
+// framing, RFC 6587).
+type syslogWriter struct {
+	network  string
+	addr     string
+	facility int
+	hostname string
+	appName  string
+	procID   string
+
+	conn net.Conn
+	buf  bytes.Buffer
+}
+
+// netSinkDialTimeout bounds the time spent connecting to a collector.
+const netSinkDialTimeout = 5 * time.Second
+
+func newSyslogWriter(network string, cfg *NetworkSinkConfig) *syslogWriter {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	return &syslogWriter{
+		network:  network,
+		addr:     cfg.URL.Host,
+		facility: cfg.Facility,
+		hostname: syslogHeaderField(hostname, 255),
+		appName:  syslogHeaderField(cfg.Tag, 48),
+		procID:   strconv.Itoa(os.Getpid()),
+	}
+}
+
+// syslogHeaderField sanitizes a value for use in the header of a syslog
+// message, where only printable US-ASCII characters are allowed.
+func syslogHeaderField(s string, maxLen int) string {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s) && len(b) < maxLen; i++ {
+		if c := s[i]; c > ' ' && c < 0x7f {
+			b = append(b, c)
+		}
+	}
+	if len(b) == 0 {
+		return "-"
+	}
+	return string(b)
+}
+
+// syslogSeverity maps log severities onto syslog severities.
+func syslogSeverity(sev Severity) int {
+	switch sev {
+	case Severity_FATAL:
+		return 2 // critical
+	case Severity_ERROR:
+		return 3 // error
+	case Severity_WARNING:
+		return 4 // warning
+	default:
+		return 6 // informational
+	}
+}
+
+// format renders an entry as a syslog message:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID - MSG
+//
+// The MSGID is the logging channel. The MSG part contains the source
+// location, goroutine, tags and counter in the same format as log files,
+// followed by the message.
+func (w *syslogWriter) format(buf *bytes.Buffer, e *netSinkEntry) {
+	buf.WriteByte('<')
+	buf.WriteString(strconv.Itoa(w.facility*8 + syslogSeverity(e.Severity)))
+	buf.WriteString(">1 ")
+	buf.WriteString(timeutil.Unix(0, e.Time).UTC().Format("2006-01-02T15:04:05.000000Z07:00"))
+	buf.WriteByte(' ')
+	buf.WriteString(w.hostname)
+	buf.WriteByte(' ')
+	buf.WriteString(w.appName)
+	buf.WriteByte(' ')
+	buf.WriteString(w.procID)
+	buf.WriteByte(' ')
+	buf.WriteString(syslogHeaderField(e.channel, 32))
+	buf.WriteString(" - ")
+	buf.WriteString(e.File)
+	buf.WriteByte(':')
+	buf.WriteString(strconv.FormatInt(e.Line, 10))
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.FormatInt(e.Goroutine, 10))
+	if e.Redactable {
+		buf.WriteByte(' ')
+		buf.WriteString(redactableIndicator)
+	}
+	if e.Tags != "" {
+		buf.WriteString(" [")
+		buf.WriteString(e.Tags)
+		buf.WriteByte(']')
+	}
+	if e.Counter > 0 {
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatUint(e.Counter, 10))
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(e.Message)
+}
+
+// send implements the netSinkWriter interface.
+func (w *syslogWriter) send(ctx context.Context, entries []netSinkEntry) error {
+	if w.conn == nil {
+		d := net.Dialer{Timeout: netSinkDialTimeout}
+		conn, err := d.DialContext(ctx, w.network, w.addr)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+	}
+	var msg bytes.Buffer
+	for i := range entries {
+		msg.Reset()
+		w.format(&msg, &entries[i])
+		if w.network == "tcp" {
+			w.buf.WriteString(strconv.Itoa(msg.Len()))
+			w.buf.WriteByte(' ')
+			w.buf.Write(msg.Bytes())
+			continue
+		}
+		if _, err := w.conn.Write(msg.Bytes()); err != nil {
+			w.close()
+			return err
+		}
+	}
+	if w.buf.Len() > 0 {
+		_ = w.conn.SetWriteDeadline(timeutil.Now().Add(netSinkDialTimeout))
+		_, err := w.conn.Write(w.buf.Bytes())
+		w.buf.Reset()
+		if err != nil {
+			w.close()
+			return err
+		}
+	}
+	return nil
+}
+
+// close implements the netSinkWriter interface.
+func (w *syslogWriter) close() {
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+}
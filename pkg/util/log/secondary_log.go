@@ -68,6 +68,7 @@ func NewSecondaryLogger(
 		logger: loggerT{
 			logDir:          DirName{name: dir},
 			prefix:          program + "-" + fileNamePrefix,
+			channel:         fileNamePrefix,
 			fileThreshold:   Severity_INFO,
 			stderrThreshold: mainLog.stderrThreshold.get(),
 			logCounter:      EntryCounter{EnableMsgCount: enableMsgCount},
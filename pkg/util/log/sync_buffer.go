@@ -260,11 +260,16 @@ func (l *loggerT) initializeNewOutputFile(
 
 	// Including a non-ascii character in the first 1024 bytes of the log helps
 	// viewers that attempt to guess the character encoding.
-	messages = append(messages,
-		l.makeStartLine("line format: [IWEF]yymmdd hh:mm:ss.uuuuuu goid file:line msg utf8=\u2713"))
+	if f := logging.format.get(); f == formatCrdbV1 {
+		messages = append(messages,
+			l.makeStartLine("line format: [IWEF]yymmdd hh:mm:ss.uuuuuu goid file:line msg utf8=\u2713"))
+	} else {
+		messages = append(messages,
+			l.makeStartLine("line format: %s utf8=\u2713", Safe(f.String())))
+	}
 
 	for _, entry := range messages {
-		buf := logging.formatLogEntry(entry, nil, nil)
+		buf := logging.processForFile(entry, l.channel, nil /* stacks */)
 		var n int
 		n, err = file.Write(buf.Bytes())
 		putBuffer(buf)
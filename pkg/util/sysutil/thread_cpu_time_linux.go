@@ -0,0 +1,33 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// +build linux
+
+package sysutil
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// ThreadCPUTime returns the CPU time consumed so far by the calling OS thread.
+// The boolean is false if the platform doesn't support measuring it. Callers
+// measuring the CPU time of a goroutine must lock it to its thread (see
+// runtime.LockOSThread) for the duration of the measurement.
+func ThreadCPUTime() (time.Duration, bool) {
+	// Unlike getrusage(RUSAGE_THREAD), the thread CPU clock includes the time
+	// the thread has been running since it was last scheduled.
+	var ts unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_THREAD_CPUTIME_ID, &ts); err != nil {
+		return 0, false
+	}
+	return time.Duration(ts.Nano()), true
+}
@@ -0,0 +1,22 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// +build !linux
+
+package sysutil
+
+import "time"
+
+// ThreadCPUTime returns the CPU time consumed so far by the calling OS thread.
+// The boolean is false if the platform doesn't support measuring it, which is
+// the case on all platforms but Linux.
+func ThreadCPUTime() (time.Duration, bool) {
+	return 0, false
+}
@@ -0,0 +1,47 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sysutil
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils/skip"
+)
+
+func TestThreadCPUTime(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	start, ok := ThreadCPUTime()
+	if !ok {
+		skip.IgnoreLint(t, "thread CPU time is not supported on this platform")
+	}
+	// Spin until the thread has consumed some CPU time, which is reported with
+	// the granularity of the scheduler tick.
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		cur, ok := ThreadCPUTime()
+		if !ok {
+			t.Fatal("thread CPU time is no longer supported")
+		}
+		if cur < start {
+			t.Fatalf("thread CPU time went backwards: %s < %s", cur, start)
+		}
+		if cur > start {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("thread CPU time didn't increase")
+		}
+	}
+}
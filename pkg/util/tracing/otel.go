@@ -0,0 +1,513 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tracing
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	opentracing "github.com/opentracing/opentracing-go"
+	otlog "github.com/opentracing/opentracing-go/log"
+)
+
+// TagApplicationName is the span tag holding the application name of the SQL
+// session that created a root span. Trace exporters use it to pick the
+// sampling rate of the trace (see trace.opentelemetry.app_sample_rates).
+const TagApplicationName = "application_name"
+
+// otelTracer is the shadow tracer used to export spans to an OpenTelemetry
+// (or Jaeger) collector. Its spans use 128-bit trace IDs and propagate their
+// context in the W3C Trace Context format, so that traces started by clients
+// can be continued by CockroachDB.
+//
+// Sampling is head-based: the decision is made when a root span is created,
+// and is inherited by all the spans in the trace, including those created on
+// other nodes. Spans that are not sampled are not exported.
+type otelTracer struct {
+	sampler  *otelSampler
+	exporter *otelExporter
+}
+
+var _ opentracing.Tracer = &otelTracer{}
+
+// otelTraceID is a 128-bit trace ID.
+type otelTraceID struct {
+	hi, lo uint64
+}
+
+type otelSpanContext struct {
+	traceID otelTraceID
+	spanID  uint64
+	sampled bool
+}
+
+var _ opentracing.SpanContext = &otelSpanContext{}
+
+// ForeachBaggageItem is part of the opentracing.SpanContext interface.
+//
+// Baggage is propagated by our tracer, not by the shadow context.
+func (*otelSpanContext) ForeachBaggageItem(func(k, v string) bool) {}
+
+const (
+	// traceParentKey is the key used to propagate an otelSpanContext through
+	// opentracing carriers. This is the name of the HTTP header defined by the
+	// W3C Trace Context specification.
+	traceParentKey = "traceparent"
+
+	traceParentVersion = "00"
+	traceParentSampled = 0x01
+	traceParentLen     = len("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+)
+
+// String formats the context as the value of a traceparent header.
+func (sc *otelSpanContext) String() string {
+	var b strings.Builder
+	b.Grow(traceParentLen)
+	b.WriteString(traceParentVersion)
+	b.WriteByte('-')
+	writeHex64(&b, sc.traceID.hi)
+	writeHex64(&b, sc.traceID.lo)
+	b.WriteByte('-')
+	writeHex64(&b, sc.spanID)
+	if sc.sampled {
+		b.WriteString("-01")
+	} else {
+		b.WriteString("-00")
+	}
+	return b.String()
+}
+
+func writeHex64(b *strings.Builder, v uint64) {
+	s := strconv.FormatUint(v, 16)
+	for i := len(s); i < 16; i++ {
+		b.WriteByte('0')
+	}
+	b.WriteString(s)
+}
+
+// parseTraceParent parses the value of a W3C Trace Context traceparent header
+// (https://www.w3.org/TR/trace-context/#traceparent-header).
+func parseTraceParent(s string) (*otelSpanContext, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) < 4 {
+		return nil, errors.Newf("invalid traceparent %q: expected version-traceid-spanid-flags", s)
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	if !isLowerHex(version, 2) || version == "ff" {
+		return nil, errors.Newf("invalid traceparent %q: invalid version", s)
+	}
+	// Future versions may append fields, but version 00 has exactly four.
+	if version == traceParentVersion && len(parts) != 4 {
+		return nil, errors.Newf("invalid traceparent %q: unexpected trailing fields", s)
+	}
+	if !isLowerHex(traceID, 32) {
+		return nil, errors.Newf("invalid traceparent %q: invalid trace ID", s)
+	}
+	if !isLowerHex(spanID, 16) {
+		return nil, errors.Newf("invalid traceparent %q: invalid parent ID", s)
+	}
+	if !isLowerHex(flags, 2) {
+		return nil, errors.Newf("invalid traceparent %q: invalid flags", s)
+	}
+	var sc otelSpanContext
+	// The parsing can't fail after the checks above.
+	sc.traceID.hi, _ = strconv.ParseUint(traceID[:16], 16, 64)
+	sc.traceID.lo, _ = strconv.ParseUint(traceID[16:], 16, 64)
+	sc.spanID, _ = strconv.ParseUint(spanID, 16, 64)
+	f, _ := strconv.ParseUint(flags, 16, 8)
+	sc.sampled = f&traceParentSampled != 0
+	if sc.traceID == (otelTraceID{}) {
+		return nil, errors.Newf("invalid traceparent %q: all-zero trace ID", s)
+	}
+	if sc.spanID == 0 {
+		return nil, errors.Newf("invalid traceparent %q: all-zero parent ID", s)
+	}
+	return &sc, nil
+}
+
+func isLowerHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !(c >= '0' && c <= '9') && !(c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateTraceParent checks that the given string is a valid W3C Trace
+// Context traceparent value.
+func ValidateTraceParent(traceParent string) error {
+	_, err := parseTraceParent(traceParent)
+	return err
+}
+
+// ExtractTraceParent returns a span context corresponding to the given W3C
+// Trace Context traceparent value, usually provided by a client. Spans created
+// as children of the returned context are part of the client's trace when
+// spans are exported through trace.opentelemetry.collector, and follow the
+// client's sampling decision.
+func (t *Tracer) ExtractTraceParent(traceParent string) (opentracing.SpanContext, error) {
+	otelCtx, err := parseTraceParent(traceParent)
+	if err != nil {
+		return noopSpanContext{}, err
+	}
+	sc := &spanContext{
+		spanMeta: spanMeta{
+			TraceID: otelCtx.traceID.lo,
+			SpanID:  otelCtx.spanID,
+		},
+	}
+	if shadowTr := t.getShadowTracer(); shadowTr != nil {
+		if _, ok := shadowTr.Tracer.(*otelTracer); ok {
+			sc.shadowTr = shadowTr
+			sc.shadowCtx = otelCtx
+		}
+	}
+	return sc, nil
+}
+
+// ExportsSpans returns true if spans are exported to an OpenTelemetry or
+// Jaeger collector.
+func (t *Tracer) ExportsSpans() bool {
+	shadowTr := t.getShadowTracer()
+	if shadowTr == nil {
+		return false
+	}
+	_, ok := shadowTr.Tracer.(*otelTracer)
+	return ok
+}
+
+// StartSpan is part of the opentracing.Tracer interface.
+func (t *otelTracer) StartSpan(
+	operationName string, opts ...opentracing.StartSpanOption,
+) opentracing.Span {
+	var sso opentracing.StartSpanOptions
+	for _, o := range opts {
+		o.Apply(&sso)
+	}
+
+	s := &otelSpan{
+		tracer:    t,
+		startTime: sso.StartTime,
+	}
+	s.mu.operation = operationName
+	if s.startTime.IsZero() {
+		s.startTime = timeutil.Now()
+	}
+
+	var parentCtx *otelSpanContext
+	for _, r := range sso.References {
+		if r.Type != opentracing.ChildOfRef && r.Type != opentracing.FollowsFromRef {
+			continue
+		}
+		if sc, ok := r.ReferencedContext.(*otelSpanContext); ok {
+			parentCtx = sc
+			break
+		}
+	}
+	if parentCtx != nil {
+		s.ctx.traceID = parentCtx.traceID
+		s.ctx.sampled = parentCtx.sampled
+		s.parentSpanID = parentCtx.spanID
+	} else {
+		// This is a root span; allocate a new trace ID and decide whether the
+		// trace is sampled.
+		s.ctx.traceID = otelTraceID{hi: uint64(rand.Int63()), lo: uint64(rand.Int63())}
+		appName, _ := sso.Tags[TagApplicationName].(string)
+		s.ctx.sampled = t.sampler.shouldSample(appName)
+	}
+	s.ctx.spanID = uint64(rand.Int63())
+
+	if s.ctx.sampled && len(sso.Tags) > 0 {
+		s.mu.tags = make(opentracing.Tags, len(sso.Tags))
+		for k, v := range sso.Tags {
+			s.mu.tags[k] = v
+		}
+	}
+	return s
+}
+
+// Inject is part of the opentracing.Tracer interface.
+func (t *otelTracer) Inject(
+	osc opentracing.SpanContext, format interface{}, carrier interface{},
+) error {
+	if format != opentracing.HTTPHeaders && format != opentracing.TextMap {
+		return opentracing.ErrUnsupportedFormat
+	}
+	mapWriter, ok := carrier.(opentracing.TextMapWriter)
+	if !ok {
+		return opentracing.ErrInvalidCarrier
+	}
+	sc, ok := osc.(*otelSpanContext)
+	if !ok {
+		return opentracing.ErrInvalidSpanContext
+	}
+	mapWriter.Set(traceParentKey, sc.String())
+	return nil
+}
+
+// Extract is part of the opentracing.Tracer interface.
+func (t *otelTracer) Extract(
+	format interface{}, carrier interface{},
+) (opentracing.SpanContext, error) {
+	if format != opentracing.HTTPHeaders && format != opentracing.TextMap {
+		return nil, opentracing.ErrUnsupportedFormat
+	}
+	mapReader, ok := carrier.(opentracing.TextMapReader)
+	if !ok {
+		return nil, opentracing.ErrInvalidCarrier
+	}
+	var sc *otelSpanContext
+	err := mapReader.ForeachKey(func(k, v string) error {
+		if strings.ToLower(k) != traceParentKey {
+			return nil
+		}
+		var err error
+		sc, err = parseTraceParent(v)
+		if err != nil {
+			return opentracing.ErrSpanContextCorrupted
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if sc == nil {
+		return nil, opentracing.ErrSpanContextNotFound
+	}
+	return sc, nil
+}
+
+type otelEvent struct {
+	time   time.Time
+	fields []otlog.Field
+}
+
+// otelSpan is the span implementation of otelTracer. Spans that are not
+// sampled only carry their context; everything else is ignored.
+type otelSpan struct {
+	tracer       *otelTracer
+	ctx          otelSpanContext
+	parentSpanID uint64
+	startTime    time.Time
+
+	mu struct {
+		syncutil.Mutex
+		operation string
+		finished  bool
+		tags      opentracing.Tags
+		events    []otelEvent
+	}
+}
+
+var _ opentracing.Span = &otelSpan{}
+
+// otelSpanData is the information about a finished span that is sent to the
+// collector.
+type otelSpanData struct {
+	traceID      otelTraceID
+	spanID       uint64
+	parentSpanID uint64
+	operation    string
+	startTime    time.Time
+	finishTime   time.Time
+	tags         opentracing.Tags
+	events       []otelEvent
+}
+
+// Finish is part of the opentracing.Span interface.
+func (s *otelSpan) Finish() {
+	s.FinishWithOptions(opentracing.FinishOptions{})
+}
+
+// FinishWithOptions is part of the opentracing.Span interface.
+func (s *otelSpan) FinishWithOptions(opts opentracing.FinishOptions) {
+	if !s.ctx.sampled {
+		return
+	}
+	finishTime := opts.FinishTime
+	if finishTime.IsZero() {
+		finishTime = timeutil.Now()
+	}
+	s.mu.Lock()
+	if s.mu.finished {
+		s.mu.Unlock()
+		return
+	}
+	s.mu.finished = true
+	data := &otelSpanData{
+		traceID:      s.ctx.traceID,
+		spanID:       s.ctx.spanID,
+		parentSpanID: s.parentSpanID,
+		operation:    s.mu.operation,
+		startTime:    s.startTime,
+		finishTime:   finishTime,
+		tags:         s.mu.tags,
+		events:       s.mu.events,
+	}
+	s.mu.Unlock()
+	s.tracer.exporter.add(data)
+}
+
+// Context is part of the opentracing.Span interface.
+func (s *otelSpan) Context() opentracing.SpanContext {
+	return &s.ctx
+}
+
+// SetOperationName is part of the opentracing.Span interface.
+func (s *otelSpan) SetOperationName(operationName string) opentracing.Span {
+	s.mu.Lock()
+	s.mu.operation = operationName
+	s.mu.Unlock()
+	return s
+}
+
+// SetTag is part of the opentracing.Span interface.
+func (s *otelSpan) SetTag(key string, value interface{}) opentracing.Span {
+	if !s.ctx.sampled {
+		return s
+	}
+	s.mu.Lock()
+	if !s.mu.finished {
+		if s.mu.tags == nil {
+			s.mu.tags = make(opentracing.Tags)
+		}
+		s.mu.tags[key] = value
+	}
+	s.mu.Unlock()
+	return s
+}
+
+// LogFields is part of the opentracing.Span interface.
+func (s *otelSpan) LogFields(fields ...otlog.Field) {
+	if !s.ctx.sampled {
+		return
+	}
+	s.mu.Lock()
+	if !s.mu.finished && len(s.mu.events) < maxLogsPerSpan {
+		s.mu.events = append(s.mu.events, otelEvent{time: timeutil.Now(), fields: fields})
+	}
+	s.mu.Unlock()
+}
+
+// LogKV is part of the opentracing.Span interface.
+func (s *otelSpan) LogKV(alternatingKeyValues ...interface{}) {
+	fields, err := otlog.InterleavedKVToFields(alternatingKeyValues...)
+	if err != nil {
+		s.LogFields(otlog.Error(err), otlog.String("function", "LogKV"))
+		return
+	}
+	s.LogFields(fields...)
+}
+
+// SetBaggageItem is part of the opentracing.Span interface.
+//
+// Baggage is propagated by our tracer, not by the shadow span.
+func (s *otelSpan) SetBaggageItem(restrictedKey, value string) opentracing.Span {
+	return s
+}
+
+// BaggageItem is part of the opentracing.Span interface.
+func (s *otelSpan) BaggageItem(restrictedKey string) string {
+	return ""
+}
+
+// Tracer is part of the opentracing.Span interface.
+func (s *otelSpan) Tracer() opentracing.Tracer {
+	return s.tracer
+}
+
+// LogEvent is part of the opentracing.Span interface. Deprecated.
+func (s *otelSpan) LogEvent(event string) {
+	s.LogFields(otlog.String(tagNameEvent, event))
+}
+
+// LogEventWithPayload is part of the opentracing.Span interface. Deprecated.
+func (s *otelSpan) LogEventWithPayload(event string, payload interface{}) {
+	s.LogFields(otlog.String(tagNameEvent, event), otlog.Object("payload", payload))
+}
+
+// Log is part of the opentracing.Span interface. Deprecated.
+func (s *otelSpan) Log(data opentracing.LogData) {
+	s.LogFields(data.ToLogRecord().Fields...)
+}
+
+// tagNameEvent is the field used by opentracing for the event name of a log
+// record.
+const tagNameEvent = "event"
+
+// otelSampler decides whether new traces are sampled, according to the
+// trace.opentelemetry.sample_rate and trace.opentelemetry.app_sample_rates
+// cluster settings.
+type otelSampler struct {
+	sv *settings.Values
+
+	mu struct {
+		syncutil.Mutex
+		// appRates caches the parsed value of appRatesRaw.
+		appRatesRaw string
+		appRates    map[string]float64
+	}
+}
+
+func (s *otelSampler) shouldSample(appName string) bool {
+	rate := s.rate(appName)
+	return rate >= 1 || (rate > 0 && rand.Float64() < rate)
+}
+
+// rate returns the sampling rate for traces started by the given application.
+func (s *otelSampler) rate(appName string) float64 {
+	if raw := otelAppSampleRates.Get(s.sv); raw != "" {
+		s.mu.Lock()
+		if raw != s.mu.appRatesRaw {
+			// The setting is validated, so parsing can't fail.
+			s.mu.appRates, _ = parseAppSampleRates(raw)
+			s.mu.appRatesRaw = raw
+		}
+		rate, ok := s.mu.appRates[appName]
+		s.mu.Unlock()
+		if ok {
+			return rate
+		}
+	}
+	return otelSampleRate.Get(s.sv)
+}
+
+// parseAppSampleRates parses a comma-separated list of
+// <application_name>=<rate> pairs.
+func parseAppSampleRates(s string) (map[string]float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	rates := make(map[string]float64)
+	for _, pair := range strings.Split(s, ",") {
+		eq := strings.LastIndexByte(pair, '=')
+		if eq < 0 {
+			return nil, errors.Newf("invalid sample rate %q: expected <application_name>=<rate>", pair)
+		}
+		appName := strings.TrimSpace(pair[:eq])
+		rate, err := strconv.ParseFloat(strings.TrimSpace(pair[eq+1:]), 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, errors.Newf("invalid sample rate %q: rate must be between 0 and 1", pair)
+		}
+		rates[appName] = rate
+	}
+	return rates, nil
+}
@@ -0,0 +1,646 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	opentracing "github.com/opentracing/opentracing-go"
+	"google.golang.org/grpc"
+)
+
+const (
+	// otelMaxBufferedSpans is the number of finished spans that can wait to be
+	// exported. Spans finished when the buffer is full are dropped.
+	otelMaxBufferedSpans = 10000
+	// otelBatchSize is the maximum number of spans sent in a single request.
+	// The exporter flushes early when that many spans are buffered.
+	otelBatchSize = 512
+	// otelFlushInterval is how often buffered spans are exported.
+	otelFlushInterval = time.Second
+	// otelExportTimeout bounds the duration of a single request.
+	otelExportTimeout = 10 * time.Second
+
+	// otelServiceName is the service name attached to exported spans.
+	otelServiceName = "cockroach"
+)
+
+// otelSender sends batches of spans to a collector.
+type otelSender interface {
+	send(ctx context.Context, spans []*otelSpanData) error
+	close()
+}
+
+// otelExporter buffers finished spans and exports them asynchronously, in
+// batches.
+type otelExporter struct {
+	sender otelSender
+
+	// flushC is signaled when a full batch is buffered.
+	flushC chan struct{}
+	stopC  chan struct{}
+	doneC  chan struct{}
+
+	mu struct {
+		syncutil.Mutex
+		spans   []*otelSpanData
+		dropped int
+	}
+}
+
+func newOTelExporter(sender otelSender) *otelExporter {
+	e := &otelExporter{
+		sender: sender,
+		flushC: make(chan struct{}, 1),
+		stopC:  make(chan struct{}),
+		doneC:  make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// add queues a finished span for export.
+func (e *otelExporter) add(s *otelSpanData) {
+	e.mu.Lock()
+	if len(e.mu.spans) >= otelMaxBufferedSpans {
+		e.mu.dropped++
+		e.mu.Unlock()
+		return
+	}
+	e.mu.spans = append(e.mu.spans, s)
+	full := len(e.mu.spans) >= otelBatchSize
+	e.mu.Unlock()
+	if full {
+		select {
+		case e.flushC <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (e *otelExporter) run() {
+	defer close(e.doneC)
+	ticker := time.NewTicker(otelFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-e.flushC:
+		case <-e.stopC:
+			e.flush()
+			return
+		}
+		e.flush()
+	}
+}
+
+// flush exports all the buffered spans.
+func (e *otelExporter) flush() {
+	e.mu.Lock()
+	spans, dropped := e.mu.spans, e.mu.dropped
+	e.mu.spans, e.mu.dropped = nil, 0
+	e.mu.Unlock()
+
+	if dropped > 0 {
+		e.reportError(errors.Newf("%d spans dropped because the export buffer was full", dropped))
+	}
+	for len(spans) > 0 {
+		n := len(spans)
+		if n > otelBatchSize {
+			n = otelBatchSize
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), otelExportTimeout)
+		err := e.sender.send(ctx, spans[:n])
+		cancel()
+		if err != nil {
+			e.reportError(errors.Wrapf(err, "exporting %d spans", n))
+		}
+		spans = spans[n:]
+	}
+}
+
+func (e *otelExporter) reportError(err error) {
+	if otelLogEveryN.ShouldProcess(timeutil.Now()) {
+		// We can't use `log` from this package so print errors to stderr.
+		fmt.Fprintf(os.Stderr, "OpenTelemetry exporter: %v\n", err)
+	}
+}
+
+// close exports the remaining spans and releases the resources of the
+// exporter.
+func (e *otelExporter) close() {
+	close(e.stopC)
+	<-e.doneC
+	e.sender.close()
+}
+
+// otelAttrValue converts the value of a tag or log field to one of the types
+// supported by the collectors: string, bool, int64 or float64.
+func otelAttrValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string, bool, int64, float64:
+		return v
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint:
+		if v <= math.MaxInt64 {
+			return int64(v)
+		}
+	case uint64:
+		if v <= math.MaxInt64 {
+			return int64(v)
+		}
+	case float32:
+		return float64(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// sortedTagKeys returns the keys of the tags in sorted order, so that exported
+// spans are deterministic.
+func sortedTagKeys(tags opentracing.Tags) []string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// name returns the name of the event logged by the given fields.
+func (ev *otelEvent) name() string {
+	for _, f := range ev.fields {
+		if f.Key() == tagNameEvent {
+			return fmt.Sprint(f.Value())
+		}
+	}
+	return "log"
+}
+
+// otlpExportMethod is the gRPC method of the OTLP trace service.
+const otlpExportMethod = "/opentelemetry.proto.collector.trace.v1.TraceService/Export"
+
+// otlpSender sends spans to an OpenTelemetry collector using OTLP over gRPC.
+//
+// The requests are encoded directly in the protobuf wire format to avoid a
+// dependency on the OpenTelemetry protos; see encodeOTLPRequest.
+type otlpSender struct {
+	conn *grpc.ClientConn
+}
+
+var _ otelSender = &otlpSender{}
+
+func newOTLPSender(addr string) (*otlpSender, error) {
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	return &otlpSender{conn: conn}, nil
+}
+
+func (s *otlpSender) send(ctx context.Context, spans []*otelSpanData) error {
+	var resp []byte
+	return s.conn.Invoke(
+		ctx, otlpExportMethod, encodeOTLPRequest(spans), &resp, grpc.ForceCodec(otlpCodec{}))
+}
+
+func (s *otlpSender) close() {
+	_ = s.conn.Close()
+}
+
+// otlpCodec is a gRPC codec for pre-encoded messages. Requests are passed as
+// []byte and responses are returned as *[]byte.
+type otlpCodec struct{}
+
+func (otlpCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, errors.AssertionFailedf("unexpected message type %T", v)
+	}
+	return b, nil
+}
+
+func (otlpCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return errors.AssertionFailedf("unexpected message type %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (otlpCodec) Name() string {
+	return "proto"
+}
+
+// Protobuf wire types.
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+)
+
+func appendProtoVarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func appendProtoKey(b []byte, field int, wireType int) []byte {
+	return appendProtoVarint(b, uint64(field)<<3|uint64(wireType))
+}
+
+func appendProtoBytes(b []byte, field int, v []byte) []byte {
+	b = appendProtoKey(b, field, protoBytes)
+	b = appendProtoVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendProtoString(b []byte, field int, v string) []byte {
+	b = appendProtoKey(b, field, protoBytes)
+	b = appendProtoVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendProtoFixed64(b []byte, field int, v uint64) []byte {
+	b = appendProtoKey(b, field, protoFixed64)
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+func appendProtoUint64(b []byte, field int, v uint64) []byte {
+	b = appendProtoKey(b, field, protoVarint)
+	return appendProtoVarint(b, v)
+}
+
+func bigEndian64(v uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	return buf[:]
+}
+
+// otlpSpanKindInternal is the SPAN_KIND_INTERNAL value of the Span.SpanKind
+// enum.
+const otlpSpanKindInternal = 1
+
+// encodeOTLPRequest encodes an ExportTraceServiceRequest message
+// (opentelemetry/proto/collector/trace/v1/trace_service.proto) containing the
+// given spans.
+//
+//	message ExportTraceServiceRequest {
+//	  repeated ResourceSpans resource_spans = 1;
+//	}
+//	message ResourceSpans {
+//	  Resource resource = 1;
+//	  repeated InstrumentationLibrarySpans instrumentation_library_spans = 2;
+//	}
+//	message Resource {
+//	  repeated KeyValue attributes = 1;
+//	}
+//	message InstrumentationLibrarySpans {
+//	  InstrumentationLibrary instrumentation_library = 1;
+//	  repeated Span spans = 2;
+//	}
+//	message InstrumentationLibrary {
+//	  string name = 1;
+//	}
+func encodeOTLPRequest(spans []*otelSpanData) []byte {
+	var resource []byte
+	resource = appendProtoBytes(resource, 1, encodeOTLPKeyValue("service.name", otelServiceName))
+
+	var library []byte
+	library = appendProtoString(library, 1, "github.com/cockroachdb/cockroach/pkg/util/tracing")
+	var librarySpans []byte
+	librarySpans = appendProtoBytes(librarySpans, 1, library)
+	for _, s := range spans {
+		librarySpans = appendProtoBytes(librarySpans, 2, encodeOTLPSpan(s))
+	}
+
+	var resourceSpans []byte
+	resourceSpans = appendProtoBytes(resourceSpans, 1, resource)
+	resourceSpans = appendProtoBytes(resourceSpans, 2, librarySpans)
+
+	return appendProtoBytes(nil, 1, resourceSpans)
+}
+
+// encodeOTLPSpan encodes a Span message
+// (opentelemetry/proto/trace/v1/trace.proto).
+//
+//	message Span {
+//	  bytes trace_id = 1;
+//	  bytes span_id = 2;
+//	  bytes parent_span_id = 4;
+//	  string name = 5;
+//	  SpanKind kind = 6;
+//	  fixed64 start_time_unix_nano = 7;
+//	  fixed64 end_time_unix_nano = 8;
+//	  repeated KeyValue attributes = 9;
+//	  repeated Event events = 11;
+//	}
+//	message Event {
+//	  fixed64 time_unix_nano = 1;
+//	  string name = 2;
+//	  repeated KeyValue attributes = 3;
+//	}
+func encodeOTLPSpan(s *otelSpanData) []byte {
+	var b []byte
+	traceID := append(bigEndian64(s.traceID.hi), bigEndian64(s.traceID.lo)...)
+	b = appendProtoBytes(b, 1, traceID)
+	b = appendProtoBytes(b, 2, bigEndian64(s.spanID))
+	if s.parentSpanID != 0 {
+		b = appendProtoBytes(b, 4, bigEndian64(s.parentSpanID))
+	}
+	b = appendProtoString(b, 5, s.operation)
+	b = appendProtoUint64(b, 6, otlpSpanKindInternal)
+	b = appendProtoFixed64(b, 7, uint64(s.startTime.UnixNano()))
+	b = appendProtoFixed64(b, 8, uint64(s.finishTime.UnixNano()))
+	for _, k := range sortedTagKeys(s.tags) {
+		b = appendProtoBytes(b, 9, encodeOTLPKeyValue(k, s.tags[k]))
+	}
+	for i := range s.events {
+		ev := &s.events[i]
+		var e []byte
+		e = appendProtoFixed64(e, 1, uint64(ev.time.UnixNano()))
+		e = appendProtoString(e, 2, ev.name())
+		for _, f := range ev.fields {
+			e = appendProtoBytes(e, 3, encodeOTLPKeyValue(f.Key(), f.Value()))
+		}
+		b = appendProtoBytes(b, 11, e)
+	}
+	return b
+}
+
+// encodeOTLPKeyValue encodes a KeyValue message
+// (opentelemetry/proto/common/v1/common.proto).
+//
+//	message KeyValue {
+//	  string key = 1;
+//	  AnyValue value = 2;
+//	}
+//	message AnyValue {
+//	  oneof value {
+//	    string string_value = 1;
+//	    bool bool_value = 2;
+//	    int64 int_value = 3;
+//	    double double_value = 4;
+//	  }
+//	}
+func encodeOTLPKeyValue(key string, value interface{}) []byte {
+	var v []byte
+	switch value := otelAttrValue(value).(type) {
+	case string:
+		v = appendProtoString(v, 1, value)
+	case bool:
+		var i uint64
+		if value {
+			i = 1
+		}
+		v = appendProtoUint64(v, 2, i)
+	case int64:
+		v = appendProtoUint64(v, 3, uint64(value))
+	case float64:
+		v = appendProtoFixed64(v, 4, math.Float64bits(value))
+	}
+	var b []byte
+	b = appendProtoString(b, 1, key)
+	return appendProtoBytes(b, 2, v)
+}
+
+// jaegerSender sends spans to a Jaeger collector using Thrift over HTTP.
+//
+// The requests are encoded directly in the Thrift binary protocol to avoid a
+// dependency on the Jaeger client; see encodeJaegerBatch.
+type jaegerSender struct {
+	url    string
+	client *http.Client
+}
+
+var _ otelSender = &jaegerSender{}
+
+func newJaegerSender(addr string) *jaegerSender {
+	return &jaegerSender{
+		url:    fmt.Sprintf("http://%s/api/traces", addr),
+		client: &http.Client{},
+	}
+}
+
+func (s *jaegerSender) send(ctx context.Context, spans []*otelSpanData) error {
+	req, err := http.NewRequest("POST", s.url, bytes.NewReader(encodeJaegerBatch(spans)))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-thrift")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body so that the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Newf("unexpected response from %s: %s", s.url, resp.Status)
+	}
+	return nil
+}
+
+func (s *jaegerSender) close() {
+	s.client.CloseIdleConnections()
+}
+
+// Thrift types.
+const (
+	thriftStop   = 0
+	thriftBool   = 2
+	thriftDouble = 4
+	thriftI32    = 8
+	thriftI64    = 10
+	thriftString = 11
+	thriftStruct = 12
+	thriftList   = 15
+)
+
+func appendThriftField(b []byte, typ byte, id int16) []byte {
+	return append(b, typ, byte(id>>8), byte(id))
+}
+
+func appendThriftI32(b []byte, v int32) []byte {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], uint32(v))
+	return append(b, buf[:]...)
+}
+
+func appendThriftI64(b []byte, v int64) []byte {
+	return append(b, bigEndian64(uint64(v))...)
+}
+
+func appendThriftString(b []byte, v string) []byte {
+	b = appendThriftI32(b, int32(len(v)))
+	return append(b, v...)
+}
+
+func appendThriftList(b []byte, elemType byte, n int) []byte {
+	b = append(b, elemType)
+	return appendThriftI32(b, int32(n))
+}
+
+// jaegerFlagSampled is the Span.flags bit indicating that a span is sampled.
+const jaegerFlagSampled = 1
+
+// encodeJaegerBatch encodes a Batch struct (jaeger-idl/thrift/jaeger.thrift)
+// containing the given spans.
+//
+//	struct Batch {
+//	  1: required Process process
+//	  2: required list<Span> spans
+//	}
+//	struct Process {
+//	  1: required string serviceName
+//	}
+//	struct Span {
+//	  1: required i64 traceIdLow
+//	  2: required i64 traceIdHigh
+//	  3: required i64 spanId
+//	  4: required i64 parentSpanId
+//	  5: required string operationName
+//	  7: required i32 flags
+//	  8: required i64 startTime
+//	  9: required i64 duration
+//	  10: optional list<Tag> tags
+//	  11: optional list<Log> logs
+//	}
+//	struct Log {
+//	  1: required i64 timestamp
+//	  2: required list<Tag> fields
+//	}
+func encodeJaegerBatch(spans []*otelSpanData) []byte {
+	var b []byte
+	b = appendThriftField(b, thriftStruct, 1)
+	b = appendThriftField(b, thriftString, 1)
+	b = appendThriftString(b, otelServiceName)
+	b = append(b, thriftStop)
+
+	b = appendThriftField(b, thriftList, 2)
+	b = appendThriftList(b, thriftStruct, len(spans))
+	for _, s := range spans {
+		b = appendThriftField(b, thriftI64, 1)
+		b = appendThriftI64(b, int64(s.traceID.lo))
+		b = appendThriftField(b, thriftI64, 2)
+		b = appendThriftI64(b, int64(s.traceID.hi))
+		b = appendThriftField(b, thriftI64, 3)
+		b = appendThriftI64(b, int64(s.spanID))
+		b = appendThriftField(b, thriftI64, 4)
+		b = appendThriftI64(b, int64(s.parentSpanID))
+		b = appendThriftField(b, thriftString, 5)
+		b = appendThriftString(b, s.operation)
+		b = appendThriftField(b, thriftI32, 7)
+		b = appendThriftI32(b, jaegerFlagSampled)
+		b = appendThriftField(b, thriftI64, 8)
+		b = appendThriftI64(b, s.startTime.UnixNano()/1000)
+		b = appendThriftField(b, thriftI64, 9)
+		b = appendThriftI64(b, int64(s.finishTime.Sub(s.startTime)/time.Microsecond))
+		if len(s.tags) > 0 {
+			b = appendThriftField(b, thriftList, 10)
+			b = appendThriftList(b, thriftStruct, len(s.tags))
+			for _, k := range sortedTagKeys(s.tags) {
+				b = appendJaegerTag(b, k, s.tags[k])
+			}
+		}
+		if len(s.events) > 0 {
+			b = appendThriftField(b, thriftList, 11)
+			b = appendThriftList(b, thriftStruct, len(s.events))
+			for _, ev := range s.events {
+				b = appendThriftField(b, thriftI64, 1)
+				b = appendThriftI64(b, ev.time.UnixNano()/1000)
+				b = appendThriftField(b, thriftList, 2)
+				b = appendThriftList(b, thriftStruct, len(ev.fields))
+				for _, f := range ev.fields {
+					b = appendJaegerTag(b, f.Key(), f.Value())
+				}
+				b = append(b, thriftStop)
+			}
+		}
+		b = append(b, thriftStop)
+	}
+	return append(b, thriftStop)
+}
+
+// Values of the jaeger.TagType enum.
+const (
+	jaegerTagString = 0
+	jaegerTagDouble = 1
+	jaegerTagBool   = 2
+	jaegerTagLong   = 3
+)
+
+// appendJaegerTag encodes a Tag struct.
+//
+//	struct Tag {
+//	  1: required string key
+//	  2: required TagType vType
+//	  3: optional string vStr
+//	  4: optional double vDouble
+//	  5: optional bool vBool
+//	  6: optional i64 vLong
+//	}
+func appendJaegerTag(b []byte, key string, value interface{}) []byte {
+	b = appendThriftField(b, thriftString, 1)
+	b = appendThriftString(b, key)
+	b = appendThriftField(b, thriftI32, 2)
+	switch value := otelAttrValue(value).(type) {
+	case string:
+		b = appendThriftI32(b, jaegerTagString)
+		b = appendThriftField(b, thriftString, 3)
+		b = appendThriftString(b, value)
+	case float64:
+		b = appendThriftI32(b, jaegerTagDouble)
+		b = appendThriftField(b, thriftDouble, 4)
+		b = append(b, bigEndian64(math.Float64bits(value))...)
+	case bool:
+		b = appendThriftI32(b, jaegerTagBool)
+		b = appendThriftField(b, thriftBool, 5)
+		if value {
+			b = append(b, 1)
+		} else {
+			b = append(b, 0)
+		}
+	case int64:
+		b = appendThriftI32(b, jaegerTagLong)
+		b = appendThriftField(b, thriftI64, 6)
+		b = appendThriftI64(b, value)
+	}
+	return append(b, thriftStop)
+}
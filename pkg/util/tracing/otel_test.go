@@ -0,0 +1,427 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestTraceParent(t *testing.T) {
+	const valid = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := parseTraceParent(valid)
+	require.NoError(t, err)
+	require.Equal(t, otelTraceID{hi: 0x4bf92f3577b34da6, lo: 0xa3ce929d0e0e4736}, sc.traceID)
+	require.Equal(t, uint64(0x00f067aa0ba902b7), sc.spanID)
+	require.True(t, sc.sampled)
+	require.Equal(t, valid, sc.String())
+
+	sc, err = parseTraceParent("00-00000000000000000000000000000001-0000000000000002-00")
+	require.NoError(t, err)
+	require.False(t, sc.sampled)
+	require.Equal(t, "00-00000000000000000000000000000001-0000000000000002-00", sc.String())
+
+	// Future versions can have more fields.
+	sc, err = parseTraceParent("cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-09-extra")
+	require.NoError(t, err)
+	require.True(t, sc.sampled)
+
+	for _, tc := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1",
+	} {
+		if err := ValidateTraceParent(tc); err == nil {
+			t.Errorf("%q: expected error", tc)
+		}
+	}
+}
+
+func TestParseAppSampleRates(t *testing.T) {
+	rates, err := parseAppSampleRates("myapp=0.5, other = 1,=0,a=b=0")
+	require.NoError(t, err)
+	require.Equal(t, map[string]float64{"myapp": 0.5, "other": 1, "": 0, "a=b": 0}, rates)
+
+	for _, tc := range []string{"myapp", "myapp=", "myapp=2", "myapp=-1", "myapp=x", "a=1,"} {
+		if _, err := parseAppSampleRates(tc); err == nil {
+			t.Errorf("%q: expected error", tc)
+		}
+	}
+}
+
+func TestOTelSampler(t *testing.T) {
+	var sv settings.Values
+	sv.Init(nil)
+	s := &otelSampler{sv: &sv}
+
+	require.True(t, s.shouldSample("app"))
+
+	otelSampleRate.Override(&sv, 0)
+	require.False(t, s.shouldSample("app"))
+
+	u := settings.NewUpdater(&sv)
+	require.NoError(t, u.Set("trace.opentelemetry.app_sample_rates", "app=1,other=0.25", "s"))
+	require.True(t, s.shouldSample("app"))
+	require.False(t, s.shouldSample(""))
+	require.Equal(t, 0.25, s.rate("other"))
+
+	require.NoError(t, u.Set("trace.opentelemetry.app_sample_rates", "app=0", "s"))
+	require.False(t, s.shouldSample("app"))
+
+	otelSampleRate.Override(&sv, 0.5)
+	sampled := 0
+	for i := 0; i < 1000; i++ {
+		if s.shouldSample("") {
+			sampled++
+		}
+	}
+	if sampled < 350 || sampled > 650 {
+		t.Errorf("expected about half the traces to be sampled, got %d/1000", sampled)
+	}
+}
+
+// fakeOTelSender records the spans that are sent to it.
+type fakeOTelSender struct {
+	mu struct {
+		syncutil.Mutex
+		spans  []*otelSpanData
+		closed bool
+	}
+}
+
+func (f *fakeOTelSender) send(_ context.Context, spans []*otelSpanData) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mu.spans = append(f.mu.spans, spans...)
+	return nil
+}
+
+func (f *fakeOTelSender) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mu.closed = true
+}
+
+func (f *fakeOTelSender) spans() map[string]*otelSpanData {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	res := make(map[string]*otelSpanData)
+	for _, s := range f.mu.spans {
+		res[s.operation] = s
+	}
+	return res
+}
+
+func TestOTelTracer(t *testing.T) {
+	var sv settings.Values
+	sv.Init(nil)
+	sender := &fakeOTelSender{}
+	exporter := newOTelExporter(sender)
+
+	tr := NewTracer()
+	tr.setShadowTracer(&otelManager{exporter: exporter}, &otelTracer{
+		sampler:  &otelSampler{sv: &sv},
+		exporter: exporter,
+	})
+	require.True(t, tr.ExportsSpans())
+
+	// A trace spanning two nodes.
+	root := tr.StartSpan("root", opentracing.Tag{Key: TagApplicationName, Value: "app"})
+	root.SetTag("x", 1)
+	root.LogKV("event", "hello", "n", 2)
+	child := StartChildSpan("child", root, nil /* logTags */, false /* separateRecording */)
+	carrier := make(opentracing.HTTPHeadersCarrier)
+	require.NoError(t, tr.Inject(child.Context(), opentracing.HTTPHeaders, carrier))
+	require.Equal(t, "otel", http.Header(carrier).Get(fieldNameShadowType))
+	require.NotEmpty(t, http.Header(carrier).Get(prefixShadow+traceParentKey))
+	remoteCtx, err := tr.Extract(opentracing.HTTPHeaders, carrier)
+	require.NoError(t, err)
+	remote := tr.StartSpan("remote", opentracing.ChildOf(remoteCtx))
+	remote.Finish()
+	child.Finish()
+	root.Finish()
+
+	// A trace started by a client.
+	const clientTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	clientCtx, err := tr.ExtractTraceParent(clientTraceParent)
+	require.NoError(t, err)
+	fromClient := tr.StartSpan("from-client", opentracing.ChildOf(clientCtx))
+	fromClient.Finish()
+
+	// Traces that are not sampled are not exported, whether the decision was
+	// made by the client or by us.
+	otelSampleRate.Override(&sv, 0)
+	tr.StartSpan("not-sampled").Finish()
+	clientCtx, err = tr.ExtractTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	require.NoError(t, err)
+	tr.StartSpan("not-sampled-by-client", opentracing.ChildOf(clientCtx)).Finish()
+
+	// Closing the tracer flushes the spans.
+	tr.Close()
+	require.True(t, sender.mu.closed)
+
+	spans := sender.spans()
+	require.Len(t, spans, 4)
+	rootData, childData, remoteData := spans["root"], spans["child"], spans["remote"]
+	require.Equal(t, rootData.traceID, childData.traceID)
+	require.Equal(t, rootData.traceID, remoteData.traceID)
+	require.Zero(t, rootData.parentSpanID)
+	require.Equal(t, rootData.spanID, childData.parentSpanID)
+	require.Equal(t, childData.spanID, remoteData.parentSpanID)
+	require.Equal(t, opentracing.Tags{TagApplicationName: "app", "x": 1}, rootData.tags)
+	require.Len(t, rootData.events, 1)
+	require.Equal(t, "hello", rootData.events[0].name())
+	require.False(t, rootData.finishTime.Before(rootData.startTime))
+
+	fromClientData := spans["from-client"]
+	require.Equal(t, otelTraceID{hi: 0x4bf92f3577b34da6, lo: 0xa3ce929d0e0e4736}, fromClientData.traceID)
+	require.Equal(t, uint64(0x00f067aa0ba902b7), fromClientData.parentSpanID)
+}
+
+// decodeProto decodes a protobuf message into a map from field number to the
+// raw values of the field, in order. Varint and fixed64 values are returned
+// as uint64, length-delimited values as []byte.
+func decodeProto(t *testing.T, b []byte) map[int][]interface{} {
+	res := make(map[int][]interface{})
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		require.True(t, n > 0)
+		b = b[n:]
+		field := int(key >> 3)
+		switch key & 7 {
+		case protoVarint:
+			v, n := binary.Uvarint(b)
+			require.True(t, n > 0)
+			b = b[n:]
+			res[field] = append(res[field], v)
+		case protoFixed64:
+			require.True(t, len(b) >= 8)
+			res[field] = append(res[field], binary.LittleEndian.Uint64(b))
+			b = b[8:]
+		case protoBytes:
+			l, n := binary.Uvarint(b)
+			require.True(t, n > 0 && uint64(len(b)-n) >= l)
+			res[field] = append(res[field], b[n:n+int(l)])
+			b = b[n+int(l):]
+		default:
+			t.Fatalf("unexpected wire type %d", key&7)
+		}
+	}
+	return res
+}
+
+func testSpanData() *otelSpanData {
+	start := time.Unix(1600000000, 123456789)
+	return &otelSpanData{
+		traceID:      otelTraceID{hi: 1, lo: 2},
+		spanID:       3,
+		parentSpanID: 4,
+		operation:    "op",
+		startTime:    start,
+		finishTime:   start.Add(1500 * time.Microsecond),
+		tags:         opentracing.Tags{"b": true, "i": 42, "f": 1.5, "s": "str"},
+	}
+}
+
+// rawCodec is the server side of otlpCodec.
+type rawCodec struct{ otlpCodec }
+
+func (rawCodec) String() string { return "proto" }
+
+func TestOTLPSender(t *testing.T) {
+	received := make(chan []byte, 1)
+	srv := grpc.NewServer(
+		grpc.CustomCodec(rawCodec{}),
+		grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+			method, _ := grpc.MethodFromServerStream(stream)
+			if method != otlpExportMethod {
+				return errors.Newf("unexpected method %s", method)
+			}
+			var req []byte
+			if err := stream.RecvMsg(&req); err != nil {
+				return err
+			}
+			received <- req
+			return stream.SendMsg([]byte{})
+		}),
+	)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = srv.Serve(ln) }()
+	defer srv.Stop()
+
+	sender, err := newOTLPSender(ln.Addr().String())
+	require.NoError(t, err)
+	defer sender.close()
+	span := testSpanData()
+	require.NoError(t, sender.send(context.Background(), []*otelSpanData{span}))
+	req := <-received
+
+	resourceSpans := decodeProto(t, decodeProto(t, req)[1][0].([]byte))
+	resource := decodeProto(t, resourceSpans[1][0].([]byte))
+	serviceName := decodeProto(t, resource[1][0].([]byte))
+	require.Equal(t, "service.name", string(serviceName[1][0].([]byte)))
+	librarySpans := decodeProto(t, resourceSpans[2][0].([]byte))
+	require.Len(t, librarySpans[2], 1)
+
+	s := decodeProto(t, librarySpans[2][0].([]byte))
+	require.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 2}, s[1][0])
+	require.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 3}, s[2][0])
+	require.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 4}, s[4][0])
+	require.Equal(t, "op", string(s[5][0].([]byte)))
+	require.Equal(t, uint64(otlpSpanKindInternal), s[6][0])
+	require.Equal(t, uint64(span.startTime.UnixNano()), s[7][0])
+	require.Equal(t, uint64(span.finishTime.UnixNano()), s[8][0])
+
+	// Attributes are sorted by key.
+	require.Len(t, s[9], 4)
+	expValues := []struct {
+		key   string
+		field int
+		value interface{}
+	}{
+		{"b", 2, uint64(1)},
+		{"f", 4, math.Float64bits(1.5)},
+		{"i", 3, uint64(42)},
+		{"s", 1, []byte("str")},
+	}
+	for i, exp := range expValues {
+		kv := decodeProto(t, s[9][i].([]byte))
+		require.Equal(t, exp.key, string(kv[1][0].([]byte)))
+		require.Equal(t, exp.value, decodeProto(t, kv[2][0].([]byte))[exp.field][0])
+	}
+}
+
+func TestOTelTracerBadCollector(t *testing.T) {
+	var sv settings.Values
+	sv.Init(nil)
+	// The address can't be resolved, so the export is disabled rather than
+	// failing the node.
+	manager, tr := createOTelTracer("dns:///", otelProtocolOTLP, &sv)
+	require.Nil(t, manager)
+	require.Nil(t, tr)
+}
+
+// decodeThriftStruct decodes a struct encoded with the Thrift binary protocol
+// into a map from field ID to value, and returns the remaining bytes. Structs
+// are returned as maps, and lists as slices.
+func decodeThriftStruct(t *testing.T, b []byte) (map[int16]interface{}, []byte) {
+	res := make(map[int16]interface{})
+	for {
+		require.NotEmpty(t, b)
+		typ := b[0]
+		if typ == thriftStop {
+			return res, b[1:]
+		}
+		id := int16(binary.BigEndian.Uint16(b[1:]))
+		res[id], b = decodeThriftValue(t, typ, b[3:])
+	}
+}
+
+func decodeThriftValue(t *testing.T, typ byte, b []byte) (interface{}, []byte) {
+	switch typ {
+	case thriftBool:
+		return b[0] != 0, b[1:]
+	case thriftDouble:
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), b[8:]
+	case thriftI32:
+		return int32(binary.BigEndian.Uint32(b)), b[4:]
+	case thriftI64:
+		return int64(binary.BigEndian.Uint64(b)), b[8:]
+	case thriftString:
+		l := int(binary.BigEndian.Uint32(b))
+		return string(b[4 : 4+l]), b[4+l:]
+	case thriftStruct:
+		return decodeThriftStruct(t, b)
+	case thriftList:
+		elemType, n := b[0], int(binary.BigEndian.Uint32(b[1:]))
+		b = b[5:]
+		list := make([]interface{}, n)
+		for i := range list {
+			list[i], b = decodeThriftValue(t, elemType, b)
+		}
+		return list, b
+	}
+	t.Fatalf("unexpected thrift type %d", typ)
+	return nil, nil
+}
+
+func TestJaegerSender(t *testing.T) {
+	received := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/traces" || r.Header.Get("Content-Type") != "application/x-thrift" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		received <- body
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	sender := newJaegerSender(srv.Listener.Addr().String())
+	defer sender.close()
+	span := testSpanData()
+	span.events = []otelEvent{{time: span.startTime, fields: nil}}
+	require.NoError(t, sender.send(context.Background(), []*otelSpanData{span}))
+
+	batch, rest := decodeThriftStruct(t, <-received)
+	require.Empty(t, rest)
+	require.Equal(t, map[int16]interface{}{1: otelServiceName}, batch[1])
+	spans := batch[2].([]interface{})
+	require.Len(t, spans, 1)
+	s := spans[0].(map[int16]interface{})
+	require.Equal(t, int64(2), s[1])
+	require.Equal(t, int64(1), s[2])
+	require.Equal(t, int64(3), s[3])
+	require.Equal(t, int64(4), s[4])
+	require.Equal(t, "op", s[5])
+	require.Equal(t, int32(jaegerFlagSampled), s[7])
+	require.Equal(t, span.startTime.UnixNano()/1000, s[8])
+	require.Equal(t, int64(1500), s[9])
+	require.Equal(t, []interface{}{
+		map[int16]interface{}{1: "b", 2: int32(jaegerTagBool), 5: true},
+		map[int16]interface{}{1: "f", 2: int32(jaegerTagDouble), 4: 1.5},
+		map[int16]interface{}{1: "i", 2: int32(jaegerTagLong), 6: int64(42)},
+		map[int16]interface{}{1: "s", 2: int32(jaegerTagString), 3: "str"},
+	}, s[10])
+	require.Equal(t, []interface{}{
+		map[int16]interface{}{1: span.startTime.UnixNano() / 1000, 2: []interface{}{}},
+	}, s[11])
+
+	// Errors from the collector are reported.
+	sender = newJaegerSender(srv.Listener.Addr().String() + "/bad")
+	require.Error(t, sender.send(context.Background(), []*otelSpanData{span}))
+}
@@ -22,6 +22,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	lightstep "github.com/lightstep/lightstep-tracer-go"
@@ -56,6 +57,18 @@ func (m *zipkinManager) Close(tr opentracing.Tracer) {
 	_ = m.collector.Close()
 }
 
+type otelManager struct {
+	exporter *otelExporter
+}
+
+func (*otelManager) Name() string {
+	return "otel"
+}
+
+func (m *otelManager) Close(tr opentracing.Tracer) {
+	m.exporter.close()
+}
+
 type shadowTracer struct {
 	opentracing.Tracer
 	manager shadowTracerManager
@@ -142,3 +155,31 @@ func createZipkinTracer(collectorAddr string) (shadowTracerManager, opentracing.
 	}
 	return &zipkinManager{collector: collector}, zipkinTr
 }
+
+var otelLogEveryN = util.Every(5 * time.Second)
+
+// createOTelTracer returns a tracer exporting spans to the given collector.
+// If the collector address is unusable, the error is reported and no tracer
+// is returned, which disables the export.
+func createOTelTracer(
+	collectorAddr string, protocol int64, sv *settings.Values,
+) (shadowTracerManager, opentracing.Tracer) {
+	var sender otelSender
+	switch protocol {
+	case otelProtocolJaeger:
+		sender = newJaegerSender(collectorAddr)
+	default:
+		var err error
+		sender, err = newOTLPSender(collectorAddr)
+		if err != nil {
+			// We can't use `log` from this package so print errors to stderr.
+			fmt.Fprintf(os.Stderr, "OpenTelemetry exporter: connecting to %s: %v\n", collectorAddr, err)
+			return nil, nil
+		}
+	}
+	exporter := newOTelExporter(sender)
+	return &otelManager{exporter: exporter}, &otelTracer{
+		sampler:  &otelSampler{sv: sv},
+		exporter: exporter,
+	}
+}
@@ -70,6 +70,58 @@ var zipkinCollector = settings.RegisterPublicStringSetting(
 	envutil.EnvOrDefaultString("COCKROACH_TEST_ZIPKIN_COLLECTOR", ""),
 )
 
+var otelCollector = settings.RegisterPublicStringSetting(
+	"trace.opentelemetry.collector",
+	"if set, traces are exported to the given OpenTelemetry or Jaeger collector (example: '127.0.0.1:4317'); "+
+		"ignored if trace.lightstep.token or trace.zipkin.collector is set",
+	envutil.EnvOrDefaultString("COCKROACH_TEST_OTEL_COLLECTOR", ""),
+)
+
+const (
+	otelProtocolOTLP = iota
+	otelProtocolJaeger
+)
+
+var otelProtocol = settings.RegisterPublicEnumSetting(
+	"trace.opentelemetry.protocol",
+	"the protocol used to send traces to trace.opentelemetry.collector: "+
+		"OTLP over gRPC, or Jaeger Thrift over HTTP",
+	"otlp",
+	map[int64]string{
+		otelProtocolOTLP:   "otlp",
+		otelProtocolJaeger: "jaeger",
+	},
+)
+
+var otelSampleRate = func() *settings.FloatSetting {
+	s := settings.RegisterValidatedFloatSetting(
+		"trace.opentelemetry.sample_rate",
+		"the fraction of new traces that are exported to trace.opentelemetry.collector",
+		1,
+		func(v float64) error {
+			if v < 0 || v > 1 {
+				return errors.Errorf("sample rate must be between 0 and 1")
+			}
+			return nil
+		})
+	s.SetVisibility(settings.Public)
+	return s
+}()
+
+var otelAppSampleRates = func() *settings.StringSetting {
+	s := settings.RegisterValidatedStringSetting(
+		"trace.opentelemetry.app_sample_rates",
+		"comma-separated list of <application_name>=<rate> pairs overriding "+
+			"trace.opentelemetry.sample_rate for SQL sessions with the given application name",
+		"",
+		func(_ *settings.Values, v string) error {
+			_, err := parseAppSampleRates(v)
+			return err
+		})
+	s.SetVisibility(settings.Public)
+	return s
+}()
+
 // Tracer is our own custom implementation of opentracing.Tracer. It supports:
 //
 //  - forwarding events to x/net/trace instances
@@ -81,6 +133,9 @@ var zipkinCollector = settings.RegisterPublicStringSetting(
 //  - lightstep traces. This is implemented by maintaining a "shadow" lightstep
 //    span inside each of our spans.
 //
+//  - exporting traces to an OpenTelemetry or Jaeger collector. This uses a
+//    shadow tracer as well (see otelTracer).
+//
 // Even when tracing is disabled, we still use this Tracer (with x/net/trace and
 // lightstep disabled) because of its recording capability (snowball
 // tracing needs to work in all cases).
@@ -125,6 +180,8 @@ func (t *Tracer) Configure(sv *settings.Values) {
 			t.setShadowTracer(createLightStepTracer(lsToken))
 		} else if zipkinAddr := zipkinCollector.Get(sv); zipkinAddr != "" {
 			t.setShadowTracer(createZipkinTracer(zipkinAddr))
+		} else if otelAddr := otelCollector.Get(sv); otelAddr != "" {
+			t.setShadowTracer(createOTelTracer(otelAddr, otelProtocol.Get(sv), sv))
 		} else {
 			t.setShadowTracer(nil, nil)
 		}
@@ -140,6 +197,8 @@ func (t *Tracer) Configure(sv *settings.Values) {
 	enableNetTrace.SetOnChange(sv, reconfigure)
 	lightstepToken.SetOnChange(sv, reconfigure)
 	zipkinCollector.SetOnChange(sv, reconfigure)
+	otelCollector.SetOnChange(sv, reconfigure)
+	otelProtocol.SetOnChange(sv, reconfigure)
 }
 
 func (t *Tracer) useNetTrace() bool {
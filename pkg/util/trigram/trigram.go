@@ -0,0 +1,199 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package trigram implements the trigram decomposition and similarity
+// measures of the PostgreSQL pg_trgm extension.
+//
+// A string is split into words, which are maximal runs of letters and digits.
+// Each word is lowercased and padded with two spaces in front and one space
+// behind, and the trigrams of the string are the distinct three-character
+// substrings of the padded words. For example, the trigrams of "Cat" are
+// "  c", " ca", "at " and "cat".
+//
+// Unlike PostgreSQL, characters that are equal under Unicode case folding are
+// always lowercased to the same character (for example, both "s" and "ſ" are
+// lowercased to "s"). This guarantees that a string matching a pattern
+// case-insensitively contains all of the trigrams of the pattern's literals.
+package trigram
+
+import (
+	"sort"
+	"unicode"
+)
+
+// DefaultSimilarityThreshold is the similarity at or above which the % operator
+// considers two strings to be similar. It matches the default value of
+// pg_trgm.similarity_threshold in PostgreSQL.
+const DefaultSimilarityThreshold = 0.3
+
+// MakeTrigrams returns the sorted, distinct trigrams of the given string.
+func MakeTrigrams(s string) []string {
+	return sortAndDedup(appendTrigrams(nil, s, true /* startsWord */, true /* endsWord */))
+}
+
+// MakeFragmentTrigrams returns the sorted, distinct trigrams that any string
+// containing the given literal fragment must have. startsWord indicates that
+// the fragment is known to be preceded by the start of the string or a
+// non-word character, and endsWord indicates that the fragment is known to be
+// followed by the end of the string or a non-word character. Words that are
+// not known to be complete are not padded on the unknown side, so that only
+// trigrams that occur in every matching string are returned.
+func MakeFragmentTrigrams(fragment string, startsWord, endsWord bool) []string {
+	return sortAndDedup(appendTrigrams(nil, fragment, startsWord, endsWord))
+}
+
+// Similarity returns the ratio of the number of trigrams the two strings have
+// in common to the number of distinct trigrams in both strings. It is 0 if
+// either string has no trigrams.
+func Similarity(a, b string) float64 {
+	return similarity(MakeTrigrams(a), MakeTrigrams(b))
+}
+
+// WordSimilarity returns the greatest similarity between the trigrams of a
+// and any contiguous extent of the ordered trigrams of b. It is useful for
+// finding a word or phrase within a longer string.
+func WordSimilarity(a, b string) float64 {
+	aTrigrams := MakeTrigrams(a)
+	if len(aTrigrams) == 0 {
+		return 0
+	}
+	inA := make(map[string]struct{}, len(aTrigrams))
+	for _, t := range aTrigrams {
+		inA[t] = struct{}{}
+	}
+	// The trigrams of b are considered in the order in which they occur, so
+	// that only contiguous extents of b are compared.
+	bTrigrams := appendTrigrams(nil, b, true /* startsWord */, true /* endsWord */)
+	var best float64
+	seen := make(map[string]struct{}, len(bTrigrams))
+	for start := range bTrigrams {
+		if _, ok := inA[bTrigrams[start]]; !ok {
+			// An extent that starts with a trigram not in a can always be
+			// improved by dropping that trigram.
+			continue
+		}
+		for k := range seen {
+			delete(seen, k)
+		}
+		common := 0
+		for end := start; end < len(bTrigrams); end++ {
+			t := bTrigrams[end]
+			if _, ok := seen[t]; ok {
+				continue
+			}
+			seen[t] = struct{}{}
+			if _, ok := inA[t]; !ok {
+				continue
+			}
+			common++
+			sim := float64(common) / float64(len(aTrigrams)+len(seen)-common)
+			if sim > best {
+				best = sim
+			}
+		}
+	}
+	return best
+}
+
+// similarity returns the similarity of two sorted sets of distinct trigrams.
+func similarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			common++
+			i++
+			j++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// isWordChar returns whether the rune is part of a word.
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// foldRune returns the lowercase form of the smallest rune that is equal to r
+// under simple Unicode case folding.
+func foldRune(r rune) rune {
+	min := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < min {
+			min = f
+		}
+	}
+	return unicode.ToLower(min)
+}
+
+// appendTrigrams appends the trigrams of the lowercased words in s to res, in
+// the order in which they occur. The first and last words are only padded on
+// the outside if startsWord and endsWord are set, respectively.
+func appendTrigrams(res []string, s string, startsWord, endsWord bool) []string {
+	runes := []rune(s)
+	for i := range runes {
+		runes[i] = foldRune(runes[i])
+	}
+	for i := 0; i < len(runes); {
+		if !isWordChar(runes[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && isWordChar(runes[j]) {
+			j++
+		}
+		padStart := i > 0 || startsWord
+		padEnd := j < len(runes) || endsWord
+		res = appendWordTrigrams(res, runes[i:j], padStart, padEnd)
+		i = j
+	}
+	return res
+}
+
+// appendWordTrigrams appends the trigrams of the given word to res, padding
+// the word on either side as requested.
+func appendWordTrigrams(res []string, word []rune, padStart, padEnd bool) []string {
+	padded := make([]rune, 0, len(word)+3)
+	if padStart {
+		padded = append(padded, ' ', ' ')
+	}
+	padded = append(padded, word...)
+	if padEnd {
+		padded = append(padded, ' ')
+	}
+	for i := 0; i+3 <= len(padded); i++ {
+		res = append(res, string(padded[i:i+3]))
+	}
+	return res
+}
+
+// sortAndDedup sorts the trigrams and removes duplicates in place.
+func sortAndDedup(trigrams []string) []string {
+	if len(trigrams) == 0 {
+		return nil
+	}
+	sort.Strings(trigrams)
+	n := 1
+	for i := 1; i < len(trigrams); i++ {
+		if trigrams[i] != trigrams[n-1] {
+			trigrams[n] = trigrams[i]
+			n++
+		}
+	}
+	return trigrams[:n]
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package trigram
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeTrigrams(t *testing.T) {
+	testCases := []struct {
+		s        string
+		expected []string
+	}{
+		{s: "", expected: nil},
+		{s: "!?", expected: nil},
+		{s: "a", expected: []string{"  a", " a "}},
+		{s: "Cat", expected: []string{"  c", " ca", "at ", "cat"}},
+		{s: "cat cat", expected: []string{"  c", " ca", "at ", "cat"}},
+		{s: "foo|bar", expected: []string{
+			"  b", "  f", " ba", " fo", "ar ", "bar", "foo", "oo ",
+		}},
+		{s: "éte", expected: []string{"  é", " ét", "te ", "éte"}},
+		{s: "ſıſ", expected: []string{"  s", " sı", "sıs", "ıs "}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.s, func(t *testing.T) {
+			require.Equal(t, tc.expected, MakeTrigrams(tc.s))
+		})
+	}
+}
+
+func TestMakeFragmentTrigrams(t *testing.T) {
+	testCases := []struct {
+		fragment             string
+		startsWord, endsWord bool
+		expected             []string
+	}{
+		{fragment: "ab", expected: nil},
+		{fragment: "abc", expected: []string{"abc"}},
+		{fragment: "abc", startsWord: true, expected: []string{"  a", " ab", "abc"}},
+		{fragment: "abc", endsWord: true, expected: []string{"abc", "bc "}},
+		{fragment: "ab cd", expected: []string{"ab ", "  c", " cd"}},
+		{fragment: "ABCD", expected: []string{"abc", "bcd"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.fragment, func(t *testing.T) {
+			require.ElementsMatch(t, tc.expected, MakeFragmentTrigrams(tc.fragment, tc.startsWord, tc.endsWord))
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected float64
+	}{
+		{a: "", b: "", expected: 0},
+		{a: "word", b: "", expected: 0},
+		{a: "word", b: "word", expected: 1},
+		{a: "word", b: "WORD!", expected: 1},
+		{a: "word", b: "two words", expected: 4.0 / 11},
+		{a: "cat", b: "dog", expected: 0},
+	}
+	for _, tc := range testCases {
+		require.InDelta(t, tc.expected, Similarity(tc.a, tc.b), 1e-9, "similarity(%q, %q)", tc.a, tc.b)
+		require.InDelta(t, tc.expected, Similarity(tc.b, tc.a), 1e-9, "similarity(%q, %q)", tc.b, tc.a)
+	}
+}
+
+func TestWordSimilarity(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected float64
+	}{
+		{a: "", b: "word", expected: 0},
+		{a: "word", b: "", expected: 0},
+		{a: "word", b: "word", expected: 1},
+		{a: "word", b: "two words", expected: 0.8},
+		{a: "two words", b: "word", expected: 0.4},
+		{a: "cat", b: "dog", expected: 0},
+	}
+	for _, tc := range testCases {
+		require.InDelta(t, tc.expected, WordSimilarity(tc.a, tc.b), 1e-9, "word_similarity(%q, %q)", tc.a, tc.b)
+	}
+}